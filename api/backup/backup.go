@@ -0,0 +1,439 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/crypto"
+	"github.com/chainid-io/dashboard/filesystem"
+)
+
+const (
+	// encryptedArchiveHeader is written in front of password protected archives, the archive
+	// is encrypted as a stream of frames.
+	encryptedArchiveHeader = "CHAINID ENCRYPTED BACKUP 2\n"
+	// legacyEncryptedArchiveHeader is written in front of the password protected archives
+	// created by the previous versions, the archive is encrypted as a whole.
+	legacyEncryptedArchiveHeader = "CHAINID ENCRYPTED BACKUP 1\n"
+	// databaseFileName is the name of the database file inside an archive.
+	databaseFileName = "chainid.db"
+	// restoreDirectoryPrefix is the prefix of the temporary directory used when restoring
+	// an archive. It is created inside the data directory so that files can be moved atomically.
+	restoreDirectoryPrefix = ".restore-"
+	// previousDirectoryPrefix is the prefix of the directory keeping the entries of the data
+	// directory replaced when restoring an archive, until the restoration succeeds.
+	previousDirectoryPrefix = ".previous-"
+	// snapshotFilePrefix is the prefix of the temporary file used to store the database snapshot.
+	snapshotFilePrefix = ".snapshot-"
+)
+
+// dataEntries lists the files and directories of the data directory stored in an archive
+// in addition to the database.
+var dataEntries = []string{
+	filesystem.TLSStorePath,
+	filesystem.ComposeStorePath,
+	filesystem.PrivateKeyFile,
+	filesystem.PublicKeyFile,
+}
+
+// Service represents a service used to backup and restore the data directory.
+type Service struct {
+	dataStorePath string
+	dataStore     chainid.DataStore
+	mu            sync.Mutex
+}
+
+// NewService initializes a new service.
+func NewService(dataStorePath string, dataStore chainid.DataStore) *Service {
+	return &Service{
+		dataStorePath: dataStorePath,
+		dataStore:     dataStore,
+	}
+}
+
+// CreateBackup writes a gzipped tar archive containing a snapshot of the database and the content
+// of the data directory to w. When password is not empty, the archive is encrypted while it is
+// written so that it is never kept in memory.
+func (service *Service) CreateBackup(w io.Writer, password string) error {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	if password == "" {
+		return service.writeArchive(w)
+	}
+
+	_, err := io.WriteString(w, encryptedArchiveHeader)
+	if err != nil {
+		return err
+	}
+
+	encryptedWriter, err := crypto.NewPassphraseWriter(w, password)
+	if err != nil {
+		return err
+	}
+
+	err = service.writeArchive(encryptedWriter)
+	if err != nil {
+		return err
+	}
+
+	return encryptedWriter.Close()
+}
+
+// RestoreBackup restores an archive created with CreateBackup. The database is migrated
+// if it was created with a previous version and the data is then swapped in. The archive is
+// fully extracted before anything is replaced and the previous data is put back if any
+// entry cannot be swapped in.
+func (service *Service) RestoreBackup(r io.Reader, password string) error {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	archive, err := decryptArchive(r, password)
+	if err != nil {
+		return err
+	}
+
+	restorePath, err := ioutil.TempDir(service.dataStorePath, restoreDirectoryPrefix)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(restorePath)
+
+	err = extractArchive(archive, restorePath)
+	if err != nil {
+		return err
+	}
+
+	// The end of an encrypted archive is read to make sure that it has not been truncated.
+	_, err = io.Copy(ioutil.Discard, archive)
+	if err != nil {
+		return err
+	}
+
+	previousPath, err := ioutil.TempDir(service.dataStorePath, previousDirectoryPrefix)
+	if err != nil {
+		return err
+	}
+
+	swapped := make([]string, 0, len(dataEntries))
+	for _, entry := range dataEntries {
+		source := path.Join(restorePath, entry)
+		if _, statErr := os.Stat(source); os.IsNotExist(statErr) {
+			continue
+		}
+
+		err = swapDataEntry(source, path.Join(service.dataStorePath, entry), path.Join(previousPath, entry))
+		if err != nil {
+			break
+		}
+		swapped = append(swapped, entry)
+	}
+
+	// The database is swapped in last as it cannot be put back once replaced.
+	if err == nil {
+		err = service.dataStore.RestoreDatabase(restorePath)
+	}
+
+	if err != nil {
+		rollbackErr := service.rollbackDataEntries(swapped, previousPath)
+		if rollbackErr != nil {
+			return fmt.Errorf("%s, unable to put back the previous data kept in %s: %s", err, previousPath, rollbackErr)
+		}
+		os.RemoveAll(previousPath)
+		return err
+	}
+
+	return os.RemoveAll(previousPath)
+}
+
+// rollbackDataEntries puts back the entries of the data directory replaced by swapDataEntry.
+func (service *Service) rollbackDataEntries(entries []string, previousPath string) error {
+	for _, entry := range entries {
+		destination := path.Join(service.dataStorePath, entry)
+		err := os.RemoveAll(destination)
+		if err != nil {
+			return err
+		}
+
+		previous := path.Join(previousPath, entry)
+		if _, err := os.Stat(previous); os.IsNotExist(err) {
+			continue
+		}
+
+		err = os.Rename(previous, destination)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (service *Service) writeArchive(w io.Writer) error {
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	err := service.writeDatabase(tarWriter)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range dataEntries {
+		err = writeDataEntry(tarWriter, service.dataStorePath, entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tarWriter.Close()
+	if err != nil {
+		return err
+	}
+
+	return gzipWriter.Close()
+}
+
+// writeDatabase adds a snapshot of the database to the archive. The snapshot is first written
+// to a temporary file as the size of each entry must be known before writing it.
+func (service *Service) writeDatabase(tarWriter *tar.Writer) error {
+	snapshot, err := ioutil.TempFile(service.dataStorePath, snapshotFilePrefix)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(snapshot.Name())
+	defer snapshot.Close()
+
+	err = service.dataStore.BackupDatabase(snapshot)
+	if err != nil {
+		return err
+	}
+
+	info, err := snapshot.Stat()
+	if err != nil {
+		return err
+	}
+
+	_, err = snapshot.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	return writeFile(tarWriter, snapshot, info, databaseFileName)
+}
+
+func writeDataEntry(tarWriter *tar.Writer, dataStorePath, entry string) error {
+	entryPath := path.Join(dataStorePath, entry)
+	if _, err := os.Stat(entryPath); os.IsNotExist(err) {
+		return nil
+	}
+
+	return filepath.Walk(entryPath, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		name, err := filepath.Rel(dataStorePath, filePath)
+		if err != nil {
+			return err
+		}
+		name = filepath.ToSlash(name)
+
+		if info.IsDir() {
+			header, err := tar.FileInfoHeader(info, "")
+			if err != nil {
+				return err
+			}
+			header.Name = name + "/"
+			return tarWriter.WriteHeader(header)
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		return writeFile(tarWriter, file, info, name)
+	})
+}
+
+func writeFile(tarWriter *tar.Writer, r io.Reader, info os.FileInfo, name string) error {
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = name
+
+	err = tarWriter.WriteHeader(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.CopyN(tarWriter, r, info.Size())
+	return err
+}
+
+// decryptArchive returns a reader on the gzipped tar archive, decrypting it while it is read
+// if required. The first frame of an encrypted archive is decrypted before returning, so that
+// an invalid password is reported as chainid.ErrDecryptionFailure.
+func decryptArchive(r io.Reader, password string) (io.Reader, error) {
+	reader := bufio.NewReader(r)
+	header, err := reader.Peek(len(encryptedArchiveHeader))
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+
+	if string(header) != encryptedArchiveHeader && string(header) != legacyEncryptedArchiveHeader {
+		return reader, nil
+	}
+
+	if password == "" {
+		return nil, chainid.ErrBackupPasswordRequired
+	}
+
+	_, err = reader.Discard(len(encryptedArchiveHeader))
+	if err != nil {
+		return nil, err
+	}
+
+	if string(header) == encryptedArchiveHeader {
+		decryptedReader, err := crypto.NewPassphraseReader(reader, password)
+		if err != nil {
+			return nil, err
+		}
+
+		archive := bufio.NewReader(decryptedReader)
+		_, err = archive.Peek(1)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		return archive, nil
+	}
+
+	encryptedArchive, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := crypto.DecryptWithPassphrase(encryptedArchive, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewReader(archive), nil
+}
+
+// extractArchive extracts the archive in the destination directory. Only the database and
+// the entries listed in dataEntries are accepted.
+func extractArchive(r io.Reader, destination string) error {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return chainid.ErrInvalidBackupArchive
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	databaseFound := false
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return chainid.ErrInvalidBackupArchive
+		}
+
+		name := path.Clean(header.Name)
+		if !validArchiveEntry(name) {
+			return chainid.ErrInvalidBackupArchive
+		}
+
+		target := filepath.Join(destination, filepath.FromSlash(name))
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0755)
+		case tar.TypeReg, tar.TypeRegA:
+			err = extractFile(tarReader, target, header.FileInfo().Mode().Perm())
+			if name == databaseFileName {
+				databaseFound = true
+			}
+		default:
+			err = chainid.ErrInvalidBackupArchive
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if !databaseFound {
+		return chainid.ErrInvalidBackupArchive
+	}
+	return nil
+}
+
+func validArchiveEntry(name string) bool {
+	if name == databaseFileName {
+		return true
+	}
+
+	if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+		return false
+	}
+
+	root := strings.SplitN(name, "/", 2)[0]
+	for _, entry := range dataEntries {
+		if root == entry {
+			return true
+		}
+	}
+	return false
+}
+
+func extractFile(r io.Reader, target string, mode os.FileMode) error {
+	err := os.MkdirAll(filepath.Dir(target), 0755)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(file, r)
+	return err
+}
+
+// swapDataEntry replaces destination with source. The previous content of destination
+// is moved to previous and is put back if source cannot be moved.
+func swapDataEntry(source, destination, previous string) error {
+	if _, err := os.Stat(destination); err == nil {
+		err = os.Rename(destination, previous)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := os.Rename(source, destination)
+	if err != nil {
+		if _, statErr := os.Stat(previous); statErr == nil {
+			os.Rename(previous, destination)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/bolt"
+	"github.com/chainid-io/dashboard/crypto"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	dataStorePath, err := ioutil.TempDir("", "chainid-backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dataStorePath)
+
+	store, err := bolt.NewStore(dataStorePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	err = store.MigrateData()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = store.TeamService.CreateTeam(&chainid.Team{Name: "before"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stackFilePath := path.Join(dataStorePath, "compose", "stack", "docker-compose.yml")
+	err = os.MkdirAll(path.Dir(stackFilePath), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(stackFilePath, []byte("before"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service := NewService(dataStorePath, store)
+
+	testCases := []struct {
+		password string
+		legacy   bool
+	}{
+		{"", false},
+		{"secret", false},
+		{"secret", true},
+	}
+
+	for _, tc := range testCases {
+		password := tc.password
+
+		var archive bytes.Buffer
+		if tc.legacy {
+			var plainArchive bytes.Buffer
+			err = service.CreateBackup(&plainArchive, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			encryptedArchive, err := crypto.EncryptWithPassphrase(plainArchive.Bytes(), password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			archive.WriteString(legacyEncryptedArchiveHeader)
+			archive.Write(encryptedArchive)
+		} else {
+			err = service.CreateBackup(&archive, password)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		err = store.TeamService.CreateTeam(&chainid.Team{Name: "after"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(stackFilePath, []byte("after"), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if password != "" {
+			err = service.RestoreBackup(bytes.NewReader(archive.Bytes()), "")
+			if err != chainid.ErrBackupPasswordRequired {
+				t.Errorf("expected %v when restoring without password, got %v", chainid.ErrBackupPasswordRequired, err)
+			}
+
+			err = service.RestoreBackup(bytes.NewReader(archive.Bytes()), "invalid")
+			if err != chainid.ErrDecryptionFailure {
+				t.Errorf("expected %v when restoring with an invalid password, got %v", chainid.ErrDecryptionFailure, err)
+			}
+
+			if !tc.legacy {
+				truncated := archive.Bytes()[:archive.Len()-1]
+				err = service.RestoreBackup(bytes.NewReader(truncated), password)
+				if err == nil {
+					t.Error("expected an error when restoring a truncated archive")
+				}
+			}
+		}
+
+		err = service.RestoreBackup(&archive, password)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		teams, err := store.TeamService.Teams()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(teams) != 1 || teams[0].Name != "before" {
+			t.Errorf("unexpected teams after restore: %v", teams)
+		}
+
+		content, err := ioutil.ReadFile(stackFilePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != "before" {
+			t.Errorf("unexpected stack file content after restore: %s", content)
+		}
+	}
+}
+
+func TestRestoreBackupRollback(t *testing.T) {
+	dataStorePath, err := ioutil.TempDir("", "chainid-backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dataStorePath)
+
+	store, err := bolt.NewStore(dataStorePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	err = store.MigrateData()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stackFilePath := path.Join(dataStorePath, "compose", "stack", "docker-compose.yml")
+	err = os.MkdirAll(path.Dir(stackFilePath), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(stackFilePath, []byte("current"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The database of the archive has no version and is rejected once the files are swapped in.
+	invalidStorePath, err := ioutil.TempDir("", "chainid-backup-invalid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(invalidStorePath)
+
+	invalidStore, err := bolt.NewStore(invalidStorePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = invalidStore.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	invalidStore.Close()
+
+	var archive bytes.Buffer
+	gzipWriter := gzip.NewWriter(&archive)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for name, source := range map[string]string{
+		"chainid.db":                       filepath.Join(invalidStorePath, "chainid.db"),
+		"compose/stack/docker-compose.yml": "",
+		"tls/1/ca.pem":                     "",
+	} {
+		content := []byte("restored")
+		if source != "" {
+			content, err = ioutil.ReadFile(source)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		err = tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = tarWriter.Write(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	tarWriter.Close()
+	gzipWriter.Close()
+
+	service := NewService(dataStorePath, store)
+	err = service.RestoreBackup(&archive, "")
+	if err != chainid.ErrInvalidBackupArchive {
+		t.Fatalf("expected %v, got %v", chainid.ErrInvalidBackupArchive, err)
+	}
+
+	content, err := ioutil.ReadFile(stackFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "current" {
+		t.Errorf("expected the stack file to be put back, got %s", content)
+	}
+	if _, err := os.Stat(path.Join(dataStorePath, "tls")); !os.IsNotExist(err) {
+		t.Errorf("expected the restored TLS files to be removed, got %v", err)
+	}
+
+	files, err := ioutil.ReadDir(dataStorePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		if strings.HasPrefix(file.Name(), restoreDirectoryPrefix) || strings.HasPrefix(file.Name(), previousDirectoryPrefix) {
+			t.Errorf("expected the temporary directory %s to be removed", file.Name())
+		}
+	}
+
+	err = store.TeamService.CreateTeam(&chainid.Team{Name: "after"})
+	if err != nil {
+		t.Errorf("expected the database to remain usable, got %v", err)
+	}
+}
+
+func TestValidArchiveEntry(t *testing.T) {
+	valid := []string{"chainid.db", "tls", "tls/1/ca.pem", "compose/stack/docker-compose.yml", "chainid.key"}
+	for _, name := range valid {
+		if !validArchiveEntry(name) {
+			t.Errorf("expected %s to be a valid entry", name)
+		}
+	}
+
+	invalid := []string{"/etc/passwd", "..", "../chainid.db", "other", "tlsx/ca.pem"}
+	for _, name := range invalid {
+		if validArchiveEntry(name) {
+			t.Errorf("expected %s to be an invalid entry", name)
+		}
+	}
+}
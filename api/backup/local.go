@@ -0,0 +1,66 @@
+package backup
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// localTarget stores archives in a directory of the local filesystem.
+type localTarget struct {
+	path string
+}
+
+func newLocalTarget(path string) *localTarget {
+	return &localTarget{path: path}
+}
+
+// Store writes the archive to a temporary file before renaming it so that
+// a partially written archive is never listed.
+func (target *localTarget) Store(name string, r io.ReadSeeker) error {
+	err := os.MkdirAll(target.path, 0700)
+	if err != nil {
+		return err
+	}
+
+	file, err := ioutil.TempFile(target.path, "."+name)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = io.Copy(file, r)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), filepath.Join(target.path, name))
+}
+
+func (target *localTarget) List() ([]string, error) {
+	files, err := ioutil.ReadDir(target.path)
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0)
+	for _, file := range files {
+		if file.Mode().IsRegular() {
+			names = append(names, file.Name())
+		}
+	}
+	return names, nil
+}
+
+func (target *localTarget) Delete(name string) error {
+	return os.Remove(filepath.Join(target.path, filepath.Base(name)))
+}
@@ -0,0 +1,271 @@
+package backup
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+)
+
+const (
+	s3DefaultRegion = "us-east-1"
+	s3Service       = "s3"
+	s3Algorithm     = "AWS4-HMAC-SHA256"
+	s3DateFormat    = "20060102T150405Z"
+)
+
+// s3Target stores archives in a bucket of an S3-compatible storage. Requests use
+// path-style addressing and are signed with AWS Signature Version 4.
+type s3Target struct {
+	settings *chainid.S3Settings
+	client   *http.Client
+}
+
+type s3ListBucketResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func newS3Target(settings *chainid.S3Settings) *s3Target {
+	return &s3Target{
+		settings: settings,
+		client:   &http.Client{Timeout: 10 * time.Minute},
+	}
+}
+
+func (target *s3Target) Store(name string, r io.ReadSeeker) error {
+	hash := sha256.New()
+	size, err := io.Copy(hash, r)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	request, err := target.newRequest(http.MethodPut, target.objectKey(name), nil, ioutil.NopCloser(r), hex.EncodeToString(hash.Sum(nil)))
+	if err != nil {
+		return err
+	}
+	request.ContentLength = size
+
+	_, err = target.do(request)
+	return err
+}
+
+func (target *s3Target) List() ([]string, error) {
+	names := make([]string, 0)
+	prefix := target.objectKey("")
+
+	continuationToken := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", prefix)
+		if continuationToken != "" {
+			query.Set("continuation-token", continuationToken)
+		}
+
+		request, err := target.newRequest(http.MethodGet, "", query, nil, emptyPayloadHash())
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := target.do(request)
+		if err != nil {
+			return nil, err
+		}
+
+		var result s3ListBucketResult
+		err = xml.Unmarshal(body, &result)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, object := range result.Contents {
+			names = append(names, strings.TrimPrefix(object.Key, prefix))
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return names, nil
+		}
+		continuationToken = result.NextContinuationToken
+	}
+}
+
+func (target *s3Target) Delete(name string) error {
+	request, err := target.newRequest(http.MethodDelete, target.objectKey(name), nil, nil, emptyPayloadHash())
+	if err != nil {
+		return err
+	}
+
+	_, err = target.do(request)
+	return err
+}
+
+func (target *s3Target) objectKey(name string) string {
+	prefix := strings.Trim(target.settings.Prefix, "/")
+	if prefix == "" {
+		return name
+	}
+	return prefix + "/" + name
+}
+
+func (target *s3Target) region() string {
+	if target.settings.Region == "" {
+		return s3DefaultRegion
+	}
+	return target.settings.Region
+}
+
+func (target *s3Target) newRequest(method, key string, query url.Values, body io.ReadCloser, payloadHash string) (*http.Request, error) {
+	endpoint, err := url.Parse(strings.TrimSuffix(target.settings.Endpoint, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	objectPath := "/" + target.settings.Bucket
+	if key != "" {
+		objectPath += "/" + key
+	}
+	endpoint.Path += objectPath
+	endpoint.RawPath = uriEncodePath(endpoint.Path)
+	if query != nil {
+		endpoint.RawQuery = canonicalQueryString(query)
+	}
+
+	request, err := http.NewRequest(method, endpoint.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Body = body
+
+	signS3Request(request, target.settings.AccessKeyID, target.settings.SecretAccessKey, target.region(), payloadHash, time.Now())
+	return request, nil
+}
+
+func (target *s3Target) do(request *http.Request) ([]byte, error) {
+	response, err := target.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("S3 request %s %s failed with status %d: %s", request.Method, request.URL.Path, response.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+// signS3Request adds the AWS Signature Version 4 headers to the request.
+func signS3Request(request *http.Request, accessKeyID, secretAccessKey, region, payloadHash string, t time.Time) {
+	amzDate := t.UTC().Format(s3DateFormat)
+	date := amzDate[:8]
+
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + request.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		request.URL.EscapedPath(),
+		request.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, region, s3Service, "aws4_request"}, "/")
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		amzDate,
+		scope,
+		hex.EncodeToString(canonicalRequestHash[:]),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, s3Service)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, accessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func emptyPayloadHash() string {
+	hash := sha256.Sum256(nil)
+	return hex.EncodeToString(hash[:])
+}
+
+// uriEncodePath encodes each segment of the path as required by Signature Version 4.
+func uriEncodePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// canonicalQueryString returns the query parameters sorted by name and encoded as required
+// by Signature Version 4.
+func canonicalQueryString(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parameters := make([]string, 0, len(keys))
+	for _, key := range keys {
+		for _, value := range query[key] {
+			parameters = append(parameters, uriEncode(key)+"="+uriEncode(value))
+		}
+	}
+	return strings.Join(parameters, "&")
+}
+
+// uriEncode encodes every byte except the unreserved characters (A-Z, a-z, 0-9, '-', '.', '_' and '~').
+func uriEncode(s string) string {
+	var builder strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '.' || c == '_' || c == '~' {
+			builder.WriteByte(c)
+		} else {
+			fmt.Fprintf(&builder, "%%%02X", c)
+		}
+	}
+	return builder.String()
+}
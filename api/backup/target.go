@@ -0,0 +1,84 @@
+package backup
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+)
+
+// archivePrefix is the prefix of the name of the archives created by the scheduled backups.
+const archivePrefix = "chainid-backup-"
+
+// Target represents a location where the archives of the scheduled backups are stored.
+type Target interface {
+	// Store stores the archive under the specified name.
+	Store(name string, r io.ReadSeeker) error
+	// List returns the names of the archives stored on the target.
+	List() ([]string, error)
+	// Delete removes the archive with the specified name.
+	Delete(name string) error
+}
+
+// NewTarget creates the target defined in the settings.
+func NewTarget(settings *chainid.BackupSettings) (Target, error) {
+	switch settings.TargetType {
+	case chainid.LocalBackupTarget:
+		if settings.LocalPath == "" {
+			return nil, chainid.ErrInvalidBackupTarget
+		}
+		return newLocalTarget(settings.LocalPath), nil
+	case chainid.S3BackupTarget:
+		if settings.S3.Endpoint == "" || settings.S3.Bucket == "" || settings.S3.AccessKeyID == "" || settings.S3.SecretAccessKey == "" {
+			return nil, chainid.ErrInvalidBackupTarget
+		}
+		return newS3Target(&settings.S3), nil
+	}
+	return nil, chainid.ErrInvalidBackupTarget
+}
+
+// ArchiveName returns the name of an archive created at the specified time.
+// Names sort in chronological order.
+func ArchiveName(t time.Time, encrypted bool) string {
+	name := fmt.Sprintf("%s%s.tar.gz", archivePrefix, t.UTC().Format("20060102T150405Z"))
+	if encrypted {
+		name += ".enc"
+	}
+	return name
+}
+
+// Rotate removes the oldest archives stored on the target so that at most retention archives are kept.
+// A retention of 0 keeps every archive.
+func Rotate(target Target, retention int) error {
+	if retention <= 0 {
+		return nil
+	}
+
+	names, err := target.List()
+	if err != nil {
+		return err
+	}
+
+	archives := make([]string, 0)
+	for _, name := range names {
+		if strings.HasPrefix(name, archivePrefix) {
+			archives = append(archives, name)
+		}
+	}
+
+	if len(archives) <= retention {
+		return nil
+	}
+
+	sort.Strings(archives)
+	for _, name := range archives[:len(archives)-retention] {
+		err = target.Delete(name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+)
+
+// fakeS3Server is a minimal S3-compatible server storing objects in memory.
+type fakeS3Server struct {
+	mu      sync.Mutex
+	bucket  string
+	objects map[string][]byte
+}
+
+type fakeS3ListResult struct {
+	XMLName               xml.Name `xml:"ListBucketResult"`
+	Contents              []fakeS3Object
+	IsTruncated           bool
+	NextContinuationToken string `xml:",omitempty"`
+}
+
+type fakeS3Object struct {
+	Key string
+}
+
+func (server *fakeS3Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access/") || r.Header.Get("X-Amz-Date") == "" {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+		return
+	}
+
+	if !strings.HasPrefix(r.URL.Path, "/"+server.bucket) {
+		http.Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+	key := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/"+server.bucket), "/")
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		hash := sha256.Sum256(body)
+		if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(hash[:]) {
+			http.Error(w, "XAmzContentSHA256Mismatch", http.StatusBadRequest)
+			return
+		}
+		server.objects[key] = body
+	case http.MethodDelete:
+		delete(server.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodGet:
+		if r.URL.Query().Get("list-type") != "2" {
+			http.Error(w, "NotImplemented", http.StatusNotImplemented)
+			return
+		}
+		keys := make([]string, 0)
+		for objectKey := range server.objects {
+			if strings.HasPrefix(objectKey, r.URL.Query().Get("prefix")) {
+				keys = append(keys, objectKey)
+			}
+		}
+		sort.Strings(keys)
+
+		// Objects are returned one page at a time to exercise the pagination.
+		start := 0
+		if token := r.URL.Query().Get("continuation-token"); token != "" {
+			start = sort.SearchStrings(keys, token)
+		}
+		result := fakeS3ListResult{}
+		if start < len(keys) {
+			result.Contents = []fakeS3Object{{Key: keys[start]}}
+		}
+		if start+1 < len(keys) {
+			result.IsTruncated = true
+			result.NextContinuationToken = keys[start+1]
+		}
+		xml.NewEncoder(w).Encode(result)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func testTargetRotation(t *testing.T, target Target) {
+	start := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		err := target.Store(ArchiveName(start.Add(time.Duration(i)*time.Hour), false), bytes.NewReader([]byte("archive")))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := Rotate(target, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := target.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(names)
+
+	expected := []string{
+		ArchiveName(start.Add(2*time.Hour), false),
+		ArchiveName(start.Add(3*time.Hour), false),
+		ArchiveName(start.Add(4*time.Hour), false),
+	}
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expected archives %v after rotation, got %v", expected, names)
+	}
+}
+
+func TestS3TargetRotation(t *testing.T) {
+	s3 := &fakeS3Server{bucket: "backups", objects: make(map[string][]byte)}
+	server := httptest.NewServer(s3)
+	defer server.Close()
+
+	foreignKey := "other/" + ArchiveName(time.Now(), false)
+	s3.objects[foreignKey] = []byte("foreign")
+
+	target, err := NewTarget(&chainid.BackupSettings{
+		TargetType: chainid.S3BackupTarget,
+		S3: chainid.S3Settings{
+			Endpoint:        server.URL,
+			Bucket:          "backups",
+			Prefix:          "/chainid/",
+			AccessKeyID:     "access",
+			SecretAccessKey: "secret",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testTargetRotation(t, target)
+
+	if _, ok := s3.objects[foreignKey]; !ok {
+		t.Fatal("objects outside of the prefix must not be rotated")
+	}
+	if len(s3.objects) != 4 {
+		t.Fatalf("expected 4 objects in the bucket, got %d", len(s3.objects))
+	}
+}
+
+func TestLocalTargetRotation(t *testing.T) {
+	path, err := ioutil.TempDir("", "chainid-backup-target")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+
+	target, err := NewTarget(&chainid.BackupSettings{TargetType: chainid.LocalBackupTarget, LocalPath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testTargetRotation(t, target)
+}
+
+func TestNewTargetInvalidSettings(t *testing.T) {
+	settings := []chainid.BackupSettings{
+		{},
+		{TargetType: chainid.LocalBackupTarget},
+		{TargetType: chainid.S3BackupTarget, S3: chainid.S3Settings{Endpoint: "http://localhost:9000"}},
+	}
+
+	for _, s := range settings {
+		_, err := NewTarget(&s)
+		if err != chainid.ErrInvalidBackupTarget {
+			t.Errorf("expected ErrInvalidBackupTarget for %+v, got %v", s, err)
+		}
+	}
+}
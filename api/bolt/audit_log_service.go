@@ -0,0 +1,114 @@
+package bolt
+
+import (
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/bolt/internal"
+
+	"github.com/boltdb/bolt"
+)
+
+// auditLogMaxEntries is the maximum number of entries kept in the audit log.
+// The oldest entries are removed when the limit is reached.
+const auditLogMaxEntries = 10000
+
+// AuditLogService represents a service for managing the audit log.
+type AuditLogService struct {
+	store *Store
+}
+
+// CreateAuditLogEntry appends a new entry to the audit log and removes the entries
+// exceeding the capacity of the log.
+func (service *AuditLogService) CreateAuditLogEntry(entry *chainid.AuditLogEntry) error {
+	return service.store.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(auditLogBucketName))
+
+		id, _ := bucket.NextSequence()
+		entry.ID = chainid.AuditLogEntryID(id)
+
+		data, err := internal.MarshalAuditLogEntry(entry)
+		if err != nil {
+			return err
+		}
+
+		err = bucket.Put(internal.Itob(int(entry.ID)), data)
+		if err != nil {
+			return err
+		}
+
+		oldestID := int(id) - auditLogMaxEntries
+		if oldestID <= 0 {
+			return nil
+		}
+
+		keys := make([][]byte, 0)
+		cursor := bucket.Cursor()
+		for k, _ := cursor.First(); k != nil && internal.Btoi(k) <= oldestID; k, _ = cursor.Next() {
+			key := make([]byte, len(k))
+			copy(key, k)
+			keys = append(keys, key)
+		}
+
+		for _, k := range keys {
+			err = bucket.Delete(k)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// AuditLogEntries returns the entries matching the filter, most recent first.
+func (service *AuditLogService) AuditLogEntries(filter *chainid.AuditLogFilter) ([]chainid.AuditLogEntry, error) {
+	var entries = make([]chainid.AuditLogEntry, 0)
+	err := service.store.view(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(auditLogBucketName))
+
+		cursor := bucket.Cursor()
+		for k, v := cursor.Last(); k != nil; k, v = cursor.Prev() {
+			var entry chainid.AuditLogEntry
+			err := internal.UnmarshalAuditLogEntry(v, &entry)
+			if err != nil {
+				return err
+			}
+
+			if !auditLogEntryMatches(&entry, filter) {
+				continue
+			}
+
+			entries = append(entries, entry)
+			if filter.Limit > 0 && len(entries) >= filter.Limit {
+				break
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func auditLogEntryMatches(entry *chainid.AuditLogEntry, filter *chainid.AuditLogFilter) bool {
+	if filter.UserID != 0 && entry.UserID != filter.UserID {
+		return false
+	}
+	if filter.EndpointID != 0 && entry.EndpointID != filter.EndpointID {
+		return false
+	}
+	if filter.Method != "" && entry.Method != filter.Method {
+		return false
+	}
+	if filter.ResourceID != "" && entry.ResourceID != filter.ResourceID {
+		return false
+	}
+	if filter.From != 0 && entry.Timestamp < filter.From {
+		return false
+	}
+	if filter.To != 0 && entry.Timestamp > filter.To {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package bolt
+
+import (
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/bolt/internal"
+
+	"github.com/boltdb/bolt"
+)
+
+// BackupStatusService represents a service to manage the status of the scheduled backups.
+type BackupStatusService struct {
+	store *Store
+}
+
+const (
+	dbBackupStatusKey = "BACKUP_STATUS"
+)
+
+// BackupStatus retrieves the status of the scheduled backups.
+func (service *BackupStatusService) BackupStatus() (*chainid.BackupStatus, error) {
+	var data []byte
+	err := service.store.view(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(backupStatusBucketName))
+		value := bucket.Get([]byte(dbBackupStatusKey))
+		if value == nil {
+			return chainid.ErrBackupStatusNotFound
+		}
+
+		data = make([]byte, len(value))
+		copy(data, value)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var status chainid.BackupStatus
+	err = internal.UnmarshalBackupStatus(data, &status)
+	if err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// StoreBackupStatus persists the status of the scheduled backups.
+func (service *BackupStatusService) StoreBackupStatus(status *chainid.BackupStatus) error {
+	return service.store.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(backupStatusBucketName))
+
+		data, err := internal.MarshalBackupStatus(status)
+		if err != nil {
+			return err
+		}
+
+		err = bucket.Put([]byte(dbBackupStatusKey), data)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+}
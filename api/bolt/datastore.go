@@ -1,174 +1,442 @@
-package bolt
-
-import (
-	"log"
-	"os"
-	"time"
-
-	"github.com/boltdb/bolt"
-	"github.com/chainid-io/dashboard"
-)
-
-// Store defines the implementation of chainid.DataStore using
-// BoltDB as the storage system.
-type Store struct {
-	// Path where is stored the BoltDB database.
-	Path string
-
-	// Services
-	UserService            *UserService
-	TeamService            *TeamService
-	TeamMembershipService  *TeamMembershipService
-	EndpointService        *EndpointService
-	EndpointGroupService   *EndpointGroupService
-	ResourceControlService *ResourceControlService
-	VersionService         *VersionService
-	SettingsService        *SettingsService
-	RegistryService        *RegistryService
-	DockerHubService       *DockerHubService
-	StackService           *StackService
-
-	db                    *bolt.DB
-	checkForDataMigration bool
-}
-
-const (
-	databaseFileName          = "chainid.db"
-	versionBucketName         = "version"
-	userBucketName            = "users"
-	teamBucketName            = "teams"
-	teamMembershipBucketName  = "team_membership"
-	endpointBucketName        = "endpoints"
-	endpointGroupBucketName   = "endpoint_groups"
-	resourceControlBucketName = "resource_control"
-	settingsBucketName        = "settings"
-	registryBucketName        = "registries"
-	dockerhubBucketName       = "dockerhub"
-	stackBucketName           = "stacks"
-)
-
-// NewStore initializes a new Store and the associated services
-func NewStore(storePath string) (*Store, error) {
-	store := &Store{
-		Path:                   storePath,
-		UserService:            &UserService{},
-		TeamService:            &TeamService{},
-		TeamMembershipService:  &TeamMembershipService{},
-		EndpointService:        &EndpointService{},
-		EndpointGroupService:   &EndpointGroupService{},
-		ResourceControlService: &ResourceControlService{},
-		VersionService:         &VersionService{},
-		SettingsService:        &SettingsService{},
-		RegistryService:        &RegistryService{},
-		DockerHubService:       &DockerHubService{},
-		StackService:           &StackService{},
-	}
-	store.UserService.store = store
-	store.TeamService.store = store
-	store.TeamMembershipService.store = store
-	store.EndpointService.store = store
-	store.EndpointGroupService.store = store
-	store.ResourceControlService.store = store
-	store.VersionService.store = store
-	store.SettingsService.store = store
-	store.RegistryService.store = store
-	store.DockerHubService.store = store
-	store.StackService.store = store
-
-	_, err := os.Stat(storePath + "/" + databaseFileName)
-	if err != nil && os.IsNotExist(err) {
-		store.checkForDataMigration = false
-	} else if err != nil {
-		return nil, err
-	} else {
-		store.checkForDataMigration = true
-	}
-
-	return store, nil
-}
-
-// Open opens and initializes the BoltDB database.
-func (store *Store) Open() error {
-	path := store.Path + "/" + databaseFileName
-
-	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 1 * time.Second})
-	if err != nil {
-		return err
-	}
-	store.db = db
-
-	bucketsToCreate := []string{versionBucketName, userBucketName, teamBucketName, endpointBucketName,
-		endpointGroupBucketName, resourceControlBucketName, teamMembershipBucketName, settingsBucketName,
-		registryBucketName, dockerhubBucketName, stackBucketName}
-
-	return db.Update(func(tx *bolt.Tx) error {
-
-		for _, bucket := range bucketsToCreate {
-			_, err := tx.CreateBucketIfNotExists([]byte(bucket))
-			if err != nil {
-				return err
-			}
-		}
-
-		return nil
-	})
-}
-
-// Init creates the default data set.
-func (store *Store) Init() error {
-	groups, err := store.EndpointGroupService.EndpointGroups()
-	if err != nil {
-		return err
-	}
-
-	if len(groups) == 0 {
-		unassignedGroup := &chainid.EndpointGroup{
-			Name:            "Unassigned",
-			Description:     "Unassigned endpoints",
-			Labels:          []chainid.Pair{},
-			AuthorizedUsers: []chainid.UserID{},
-			AuthorizedTeams: []chainid.TeamID{},
-		}
-
-		return store.EndpointGroupService.CreateEndpointGroup(unassignedGroup)
-	}
-
-	return nil
-}
-
-// Close closes the BoltDB database.
-func (store *Store) Close() error {
-	if store.db != nil {
-		return store.db.Close()
-	}
-	return nil
-}
-
-// MigrateData automatically migrate the data based on the DBVersion.
-func (store *Store) MigrateData() error {
-	if !store.checkForDataMigration {
-		err := store.VersionService.StoreDBVersion(chainid.DBVersion)
-		if err != nil {
-			return err
-		}
-		return nil
-	}
-
-	version, err := store.VersionService.DBVersion()
-	if err == chainid.ErrDBVersionNotFound {
-		version = 0
-	} else if err != nil {
-		return err
-	}
-
-	if version < chainid.DBVersion {
-		log.Printf("Migrating database from version %v to %v.\n", version, chainid.DBVersion)
-		migrator := NewMigrator(store, version)
-		err = migrator.Migrate()
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
+package bolt
+
+import (
+	"io"
+	"log"
+	"os"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/chainid-io/dashboard"
+)
+
+// Store defines the implementation of chainid.DataStore using
+// BoltDB as the storage system.
+type Store struct {
+	// Path where is stored the BoltDB database.
+	Path string
+
+	// Services
+	UserService            *UserService
+	TeamService            *TeamService
+	TeamMembershipService  *TeamMembershipService
+	EndpointService        *EndpointService
+	EndpointGroupService   *EndpointGroupService
+	ResourceControlService *ResourceControlService
+	VersionService         *VersionService
+	SettingsService        *SettingsService
+	RegistryService        *RegistryService
+	DockerHubService       *DockerHubService
+	StackService           *StackService
+	RoleService            *RoleService
+	RoleAssignmentService  *RoleAssignmentService
+	AuditLogService        *AuditLogService
+	BackupStatusService    *BackupStatusService
+
+	// The transactions hold mu for reading, the database file and the data key are swapped
+	// while it is held for writing. secretCipher must only be used inside a transaction.
+	mu                    sync.RWMutex
+	db                    *bolt.DB
+	tx                    *bolt.Tx
+	secretCipher          chainid.EncryptionService
+	checkForDataMigration bool
+	masterKey             []byte
+}
+
+const (
+	databaseFileName          = "chainid.db"
+	migrationSnapshotFileName = "chainid.db.pre-migration"
+	versionBucketName         = "version"
+	userBucketName            = "users"
+	teamBucketName            = "teams"
+	teamMembershipBucketName  = "team_membership"
+	endpointBucketName        = "endpoints"
+	endpointGroupBucketName   = "endpoint_groups"
+	resourceControlBucketName = "resource_control"
+	settingsBucketName        = "settings"
+	registryBucketName        = "registries"
+	dockerhubBucketName       = "dockerhub"
+	stackBucketName           = "stacks"
+	roleBucketName            = "roles"
+	roleAssignmentBucketName  = "role_assignments"
+	auditLogBucketName        = "audit_log"
+	backupStatusBucketName    = "backup_status"
+)
+
+// NewStore initializes a new Store and the associated services
+func NewStore(storePath string) (*Store, error) {
+	store := &Store{
+		Path:                   storePath,
+		UserService:            &UserService{},
+		TeamService:            &TeamService{},
+		TeamMembershipService:  &TeamMembershipService{},
+		EndpointService:        &EndpointService{},
+		EndpointGroupService:   &EndpointGroupService{},
+		ResourceControlService: &ResourceControlService{},
+		VersionService:         &VersionService{},
+		SettingsService:        &SettingsService{},
+		RegistryService:        &RegistryService{},
+		DockerHubService:       &DockerHubService{},
+		StackService:           &StackService{},
+		RoleService:            &RoleService{},
+		RoleAssignmentService:  &RoleAssignmentService{},
+		AuditLogService:        &AuditLogService{},
+		BackupStatusService:    &BackupStatusService{},
+	}
+	store.UserService.store = store
+	store.TeamService.store = store
+	store.TeamMembershipService.store = store
+	store.EndpointService.store = store
+	store.EndpointGroupService.store = store
+	store.ResourceControlService.store = store
+	store.VersionService.store = store
+	store.SettingsService.store = store
+	store.RegistryService.store = store
+	store.DockerHubService.store = store
+	store.StackService.store = store
+	store.RoleService.store = store
+	store.RoleAssignmentService.store = store
+	store.AuditLogService.store = store
+	store.BackupStatusService.store = store
+
+	_, err := os.Stat(storePath + "/" + databaseFileName)
+	if err != nil && os.IsNotExist(err) {
+		store.checkForDataMigration = false
+	} else if err != nil {
+		return nil, err
+	} else {
+		store.checkForDataMigration = true
+	}
+
+	return store, nil
+}
+
+// Open opens and initializes the BoltDB database.
+func (store *Store) Open() error {
+	path := store.Path + "/" + databaseFileName
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return err
+	}
+	store.db = db
+
+	bucketsToCreate := []string{versionBucketName, userBucketName, teamBucketName, endpointBucketName,
+		endpointGroupBucketName, resourceControlBucketName, teamMembershipBucketName, settingsBucketName,
+		registryBucketName, dockerhubBucketName, stackBucketName, roleBucketName, roleAssignmentBucketName, auditLogBucketName,
+		backupStatusBucketName, encryptionBucketName}
+
+	return db.Update(func(tx *bolt.Tx) error {
+
+		for _, bucket := range bucketsToCreate {
+			_, err := tx.CreateBucketIfNotExists([]byte(bucket))
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// update runs fn in a read-write transaction. While the data is migrated, fn runs in
+// the transaction of the migration so that the whole migration is applied atomically.
+func (store *Store) update(fn func(tx *bolt.Tx) error) error {
+	if store.tx != nil {
+		return fn(store.tx)
+	}
+
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	return store.db.Update(fn)
+}
+
+// view runs fn in a read-only transaction, or in the transaction of the migration
+// while the data is migrated.
+func (store *Store) view(fn func(tx *bolt.Tx) error) error {
+	if store.tx != nil {
+		return fn(store.tx)
+	}
+
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	return store.db.View(fn)
+}
+
+// Init creates the default data set.
+func (store *Store) Init() error {
+	groups, err := store.EndpointGroupService.EndpointGroups()
+	if err != nil {
+		return err
+	}
+
+	if len(groups) == 0 {
+		unassignedGroup := &chainid.EndpointGroup{
+			Name:            "Unassigned",
+			Description:     "Unassigned endpoints",
+			Labels:          []chainid.Pair{},
+			AuthorizedUsers: []chainid.UserID{},
+			AuthorizedTeams: []chainid.TeamID{},
+		}
+
+		err = store.EndpointGroupService.CreateEndpointGroup(unassignedGroup)
+		if err != nil {
+			return err
+		}
+	}
+
+	roles, err := store.RoleService.Roles()
+	if err != nil {
+		return err
+	}
+
+	if len(roles) == 0 {
+		return store.createBuiltInRoles()
+	}
+
+	return nil
+}
+
+// BuiltInRoles returns the roles created with the database, in the order matching
+// chainid.AdministratorRoleID and chainid.StandardUserRoleID.
+func BuiltInRoles() []chainid.Role {
+	return []chainid.Role{
+		{
+			Name:        "Administrator",
+			Description: "Full control over the platform",
+			Permissions: []chainid.Permission{
+				chainid.EndpointCreatePermission,
+				chainid.EndpointUpdatePermission,
+				chainid.EndpointDeletePermission,
+				chainid.EndpointGroupManagePermission,
+				chainid.RegistryManagePermission,
+				chainid.UserManagePermission,
+				chainid.TeamManagePermission,
+				chainid.SettingsManagePermission,
+				chainid.StackDeployPermission,
+				chainid.StackDeletePermission,
+				chainid.ContainerExecPermission,
+				chainid.ContainerPrunePermission,
+				chainid.VolumePrunePermission,
+				chainid.NodeUpdatePermission,
+				chainid.SwarmManagePermission,
+			},
+			BuiltIn: true,
+		},
+		{
+			Name:        "Standard user",
+			Description: "Permissions granted to every non-administrator user",
+			Permissions: []chainid.Permission{
+				chainid.StackDeployPermission,
+				chainid.StackDeletePermission,
+				chainid.ContainerExecPermission,
+			},
+			BuiltIn: true,
+		},
+	}
+}
+
+// createBuiltInRoles creates the administrator and standard user roles. They must be
+// created in this order on an empty bucket so that they match chainid.AdministratorRoleID
+// and chainid.StandardUserRoleID.
+func (store *Store) createBuiltInRoles() error {
+	for _, role := range BuiltInRoles() {
+		err := store.RoleService.CreateRole(&role)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the BoltDB database.
+func (store *Store) Close() error {
+	if store.db != nil {
+		return store.db.Close()
+	}
+	return nil
+}
+
+// MigrateData automatically migrate the data based on the DBVersion.
+func (store *Store) MigrateData() error {
+	if !store.checkForDataMigration {
+		err := store.VersionService.StoreDBVersion(chainid.DBVersion)
+		if err != nil {
+			return err
+		}
+		return nil
+	}
+
+	version, err := store.VersionService.DBVersion()
+	if err == chainid.ErrDBVersionNotFound {
+		version = 0
+	} else if err != nil {
+		return err
+	}
+
+	if version < chainid.DBVersion {
+		err = store.createMigrationSnapshot()
+		if err != nil {
+			return err
+		}
+
+		log.Printf("Migrating database from version %v to %v.\n", version, chainid.DBVersion)
+		migrator := NewMigrator(store, version)
+		err = migrator.Migrate()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createMigrationSnapshot copies the database next to it before a migration, replacing
+// the copy made before the previous migration.
+func (store *Store) createMigrationSnapshot() error {
+	snapshotPath := path.Join(store.Path, migrationSnapshotFileName)
+
+	file, err := os.OpenFile(snapshotPath+".tmp", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	err = store.BackupDatabase(file)
+	if err == nil {
+		err = file.Sync()
+	}
+	file.Close()
+	if err != nil {
+		os.Remove(snapshotPath + ".tmp")
+		return err
+	}
+
+	return os.Rename(snapshotPath+".tmp", snapshotPath)
+}
+
+// RollbackMigration replaces the database with the copy made before the last migration
+// and returns the version of the restored database. It must be called on a store that
+// has not been opened, while no other instance is using the database.
+func (store *Store) RollbackMigration() (int, error) {
+	snapshotPath := path.Join(store.Path, migrationSnapshotFileName)
+	databasePath := path.Join(store.Path, databaseFileName)
+
+	if _, err := os.Stat(snapshotPath); err != nil {
+		if os.IsNotExist(err) {
+			return 0, chainid.ErrMigrationSnapshotNotFound
+		}
+		return 0, err
+	}
+
+	// Opening the database makes sure that it is not locked by a running instance.
+	db, err := bolt.Open(databasePath, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return 0, err
+	}
+	db.Close()
+
+	snapshot, err := bolt.Open(snapshotPath, 0600, &bolt.Options{Timeout: 1 * time.Second, ReadOnly: true})
+	if err != nil {
+		return 0, err
+	}
+
+	var data []byte
+	err = snapshot.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(versionBucketName))
+		if bucket != nil {
+			data = append(data, bucket.Get([]byte(dBVersionKey))...)
+		}
+		return nil
+	})
+	snapshot.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	version := 0
+	if data != nil {
+		version, err = strconv.Atoi(string(data))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return version, os.Rename(snapshotPath, databasePath)
+}
+
+// BackupDatabase writes a consistent snapshot of the database to w.
+func (store *Store) BackupDatabase(w io.Writer) error {
+	return store.view(func(tx *bolt.Tx) error {
+		_, err := tx.WriteTo(w)
+		return err
+	})
+}
+
+// RestoreDatabase replaces the database with the one stored in the specified directory.
+// The restored database is migrated to the current DBVersion before being swapped in,
+// the transactions started in the meantime wait for the swap to complete.
+func (store *Store) RestoreDatabase(restorePath string) error {
+	restoredStore, err := NewStore(restorePath)
+	if err != nil {
+		return err
+	}
+
+	if !restoredStore.checkForDataMigration {
+		return chainid.ErrInvalidBackupArchive
+	}
+
+	err = restoredStore.Open()
+	if err != nil {
+		return err
+	}
+
+	err = restoredStore.InitEncryption(store.masterKey, nil)
+	if err == nil {
+		err = restoredStore.migrateRestoredData()
+	}
+	restoredStore.Close()
+	if err != nil {
+		return err
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	err = store.db.Close()
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(path.Join(restorePath, databaseFileName), path.Join(store.Path, databaseFileName))
+	if err != nil {
+		store.Open()
+		return err
+	}
+
+	// The restored database uses its own data key.
+	store.secretCipher = restoredStore.secretCipher
+	return store.Open()
+}
+
+func (store *Store) migrateRestoredData() error {
+	version, err := store.VersionService.DBVersion()
+	if err == chainid.ErrDBVersionNotFound {
+		return chainid.ErrInvalidBackupArchive
+	} else if err != nil {
+		return err
+	}
+
+	if version > chainid.DBVersion {
+		return chainid.ErrUnsupportedBackupVersion
+	}
+
+	err = store.Init()
+	if err != nil {
+		return err
+	}
+
+	return store.MigrateData()
+}
@@ -0,0 +1,43 @@
+package bolt
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/chainid-io/dashboard/datastoretest"
+)
+
+func TestDataStoreConformance(t *testing.T) {
+	datastoretest.Run(t, func(t *testing.T) (*datastoretest.Services, func()) {
+		dataStorePath, err := ioutil.TempDir("", "chainid-conformance")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		store := openTestStore(t, dataStorePath)
+		services := &datastoretest.Services{
+			DataStore:              store,
+			UserService:            store.UserService,
+			TeamService:            store.TeamService,
+			TeamMembershipService:  store.TeamMembershipService,
+			EndpointService:        store.EndpointService,
+			EndpointGroupService:   store.EndpointGroupService,
+			ResourceControlService: store.ResourceControlService,
+			VersionService:         store.VersionService,
+			SettingsService:        store.SettingsService,
+			RegistryService:        store.RegistryService,
+			DockerHubService:       store.DockerHubService,
+			StackService:           store.StackService,
+			RoleService:            store.RoleService,
+			RoleAssignmentService:  store.RoleAssignmentService,
+			AuditLogService:        store.AuditLogService,
+			BackupStatusService:    store.BackupStatusService,
+		}
+
+		return services, func() {
+			store.Close()
+			os.RemoveAll(dataStorePath)
+		}
+	})
+}
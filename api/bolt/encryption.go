@@ -0,0 +1,286 @@
+package bolt
+
+import (
+	"github.com/boltdb/bolt"
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/internal/secrets"
+)
+
+const (
+	encryptionBucketName = "encryption"
+	dbDataKeyKey         = "DATA_KEY"
+)
+
+// InitEncryption loads the data key used to encrypt the secrets stored in the database.
+// The data key is stored in the database, encrypted with the master key (envelope encryption).
+// When the database does not contain a data key yet, a new one is generated and the existing
+// secrets are encrypted. If the data key cannot be decrypted with the master key, the previous
+// master key is used and the data key is encrypted again with the new master key.
+// It must be called after Open and before any data is read.
+func (store *Store) InitEncryption(masterKey, previousMasterKey []byte) error {
+	wrappedDataKey, err := store.dataKey()
+	if err != nil {
+		return err
+	}
+
+	if masterKey == nil {
+		if wrappedDataKey != "" {
+			return chainid.ErrMasterKeyRequired
+		}
+		store.setSecretCipher(nil)
+		return nil
+	}
+
+	masterKeyService, err := secrets.NewMasterKeyService(masterKey)
+	if err != nil {
+		return err
+	}
+	store.masterKey = masterKey
+
+	if wrappedDataKey == "" {
+		return store.createDataKey(masterKeyService)
+	}
+
+	dataKeyService, err := secrets.UnwrapDataKey(masterKeyService, wrappedDataKey)
+	if err == chainid.ErrInvalidMasterKey && previousMasterKey != nil {
+		return store.rotateMasterKey(masterKeyService, previousMasterKey, wrappedDataKey)
+	} else if err != nil {
+		return err
+	}
+
+	store.setSecretCipher(dataKeyService)
+	return nil
+}
+
+// RotateDataKey generates a new data key and encrypts every secret with it.
+func (store *Store) RotateDataKey() error {
+	if store.masterKey == nil {
+		return chainid.ErrMasterKeyRequired
+	}
+
+	masterKeyService, err := secrets.NewMasterKeyService(store.masterKey)
+	if err != nil {
+		return err
+	}
+
+	dataKeyService, wrappedDataKey, err := secrets.GenerateDataKey(masterKeyService)
+	if err != nil {
+		return err
+	}
+
+	return store.replaceDataKey(dataKeyService, wrappedDataKey)
+}
+
+// createDataKey generates the first data key of the database. The existing secrets are
+// encrypted right away when the data model is up to date, otherwise they are encrypted
+// by the data migration.
+func (store *Store) createDataKey(masterKeyService chainid.EncryptionService) error {
+	dataKeyService, wrappedDataKey, err := secrets.GenerateDataKey(masterKeyService)
+	if err != nil {
+		return err
+	}
+
+	version, err := store.VersionService.DBVersion()
+	if err == chainid.ErrDBVersionNotFound {
+		version = 0
+	} else if err != nil {
+		return err
+	}
+
+	if version < chainid.DBVersion {
+		err = store.storeDataKey(wrappedDataKey)
+		if err != nil {
+			return err
+		}
+		store.setSecretCipher(dataKeyService)
+		return nil
+	}
+
+	return store.replaceDataKey(dataKeyService, wrappedDataKey)
+}
+
+// rotateMasterKey decrypts the data key with the previous master key and stores it
+// encrypted with the new master key.
+func (store *Store) rotateMasterKey(masterKeyService chainid.EncryptionService, previousMasterKey []byte, wrappedDataKey string) error {
+	previousMasterKeyService, err := secrets.NewMasterKeyService(previousMasterKey)
+	if err != nil {
+		return err
+	}
+
+	dataKeyService, wrappedDataKey, err := secrets.RewrapDataKey(masterKeyService, previousMasterKeyService, wrappedDataKey)
+	if err != nil {
+		return err
+	}
+
+	err = store.storeDataKey(wrappedDataKey)
+	if err != nil {
+		return err
+	}
+
+	store.setSecretCipher(dataKeyService)
+	return nil
+}
+
+// setSecretCipher replaces the service used to encrypt and decrypt the secrets once the
+// pending transactions are completed.
+func (store *Store) setSecretCipher(dataKeyService chainid.EncryptionService) {
+	store.mu.Lock()
+	store.secretCipher = dataKeyService
+	store.mu.Unlock()
+}
+
+func (store *Store) dataKey() (string, error) {
+	var wrappedDataKey string
+	err := store.view(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(encryptionBucketName))
+		wrappedDataKey = string(bucket.Get([]byte(dbDataKeyKey)))
+		return nil
+	})
+	return wrappedDataKey, err
+}
+
+func (store *Store) storeDataKey(wrappedDataKey string) error {
+	return store.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(encryptionBucketName))
+		return bucket.Put([]byte(dbDataKeyKey), []byte(wrappedDataKey))
+	})
+}
+
+// replaceDataKey encrypts every secret with a new data key and stores the new data key in a
+// single transaction. The transactions started in the meantime wait for the new data key to be
+// committed and used by the store.
+func (store *Store) replaceDataKey(dataKeyService chainid.EncryptionService, wrappedDataKey string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	err := store.db.Update(func(tx *bolt.Tx) error {
+		err := encryptSecrets(tx, store.secretCipher, dataKeyService)
+		if err != nil {
+			return err
+		}
+		return tx.Bucket([]byte(encryptionBucketName)).Put([]byte(dbDataKeyKey), []byte(wrappedDataKey))
+	})
+	if err != nil {
+		return err
+	}
+
+	store.secretCipher = dataKeyService
+	return nil
+}
+
+// encryptSecrets decodes every record containing secrets with the current data key and encodes
+// it again with the new one.
+func encryptSecrets(tx *bolt.Tx, currentDataKeyService, dataKeyService chainid.EncryptionService) error {
+	records, err := decodeSecretRecords(tx, currentDataKeyService)
+	if err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		data, err := record.encode(dataKeyService)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Bucket([]byte(record.bucket)).Put(record.key, data)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// secretRecord is a decoded record containing secrets.
+type secretRecord struct {
+	bucket string
+	key    []byte
+	encode func(cipher chainid.EncryptionService) ([]byte, error)
+}
+
+func decodeSecretRecords(tx *bolt.Tx, cipher chainid.EncryptionService) ([]secretRecord, error) {
+	records := []secretRecord{}
+
+	err := tx.Bucket([]byte(userBucketName)).ForEach(func(k []byte, v []byte) error {
+		var user chainid.User
+		err := secrets.UnmarshalUser(v, &user, cipher)
+		if err != nil {
+			return err
+		}
+		records = append(records, secretRecord{userBucketName, k, func(cipher chainid.EncryptionService) ([]byte, error) {
+			return secrets.MarshalUser(&user, cipher)
+		}})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Bucket([]byte(endpointBucketName)).ForEach(func(k []byte, v []byte) error {
+		var endpoint chainid.Endpoint
+		err := secrets.UnmarshalEndpoint(v, &endpoint, cipher)
+		if err != nil {
+			return err
+		}
+		records = append(records, secretRecord{endpointBucketName, k, func(cipher chainid.EncryptionService) ([]byte, error) {
+			return secrets.MarshalEndpoint(&endpoint, cipher)
+		}})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Bucket([]byte(registryBucketName)).ForEach(func(k []byte, v []byte) error {
+		var registry chainid.Registry
+		err := secrets.UnmarshalRegistry(v, &registry, cipher)
+		if err != nil {
+			return err
+		}
+		records = append(records, secretRecord{registryBucketName, k, func(cipher chainid.EncryptionService) ([]byte, error) {
+			return secrets.MarshalRegistry(&registry, cipher)
+		}})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Bucket([]byte(stackBucketName)).ForEach(func(k []byte, v []byte) error {
+		var stack chainid.Stack
+		err := secrets.UnmarshalStack(v, &stack, cipher)
+		if err != nil {
+			return err
+		}
+		records = append(records, secretRecord{stackBucketName, k, func(cipher chainid.EncryptionService) ([]byte, error) {
+			return secrets.MarshalStack(&stack, cipher)
+		}})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if data := tx.Bucket([]byte(settingsBucketName)).Get([]byte(dbSettingsKey)); data != nil {
+		var settings chainid.Settings
+		err = secrets.UnmarshalSettings(data, &settings, cipher)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, secretRecord{settingsBucketName, []byte(dbSettingsKey), func(cipher chainid.EncryptionService) ([]byte, error) {
+			return secrets.MarshalSettings(&settings, cipher)
+		}})
+	}
+
+	if data := tx.Bucket([]byte(dockerhubBucketName)).Get([]byte(dbDockerHubKey)); data != nil {
+		var dockerhub chainid.DockerHub
+		err = secrets.UnmarshalDockerHub(data, &dockerhub, cipher)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, secretRecord{dockerhubBucketName, []byte(dbDockerHubKey), func(cipher chainid.EncryptionService) ([]byte, error) {
+			return secrets.MarshalDockerHub(&dockerhub, cipher)
+		}})
+	}
+
+	return records, nil
+}
@@ -0,0 +1,156 @@
+package bolt
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/chainid-io/dashboard"
+)
+
+func openTestStore(t *testing.T, dataStorePath string) *Store {
+	store, err := NewStore(dataStorePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func rawRecords(t *testing.T, store *Store, bucketName string) []byte {
+	var data []byte
+	err := store.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(bucketName)).ForEach(func(k []byte, v []byte) error {
+			data = append(data, v...)
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestSecretsEncryption(t *testing.T) {
+	dataStorePath, err := ioutil.TempDir("", "chainid-encryption")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dataStorePath)
+
+	store := openTestStore(t, dataStorePath)
+	err = store.InitEncryption(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.MigrateData()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = store.RegistryService.CreateRegistry(&chainid.Registry{Name: "private", Password: "registry-password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A secret looking like an encrypted value is stored as any other secret.
+	err = store.DockerHubService.StoreDockerHub(&chainid.DockerHub{Username: "user", Password: "enc:v1:dockerhub-password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.UserService.CreateUser(&chainid.User{Username: "user", TOTPSecret: "totp-secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.StackService.CreateStack(&chainid.Stack{ID: "stack", Env: []chainid.Pair{{Name: "TOKEN", Value: "stack-token"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	// Existing secrets are encrypted when a master key is specified for the first time.
+	store = openTestStore(t, dataStorePath)
+	err = store.InitEncryption([]byte("first-key"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(rawRecords(t, store, registryBucketName), []byte("registry-password")) ||
+		bytes.Contains(rawRecords(t, store, stackBucketName), []byte("stack-token")) ||
+		bytes.Contains(rawRecords(t, store, dockerhubBucketName), []byte("dockerhub-password")) ||
+		bytes.Contains(rawRecords(t, store, userBucketName), []byte("totp-secret")) {
+		t.Fatal("secrets must not be stored in plain text")
+	}
+
+	assertSecrets := func(store *Store) {
+		registry, err := store.RegistryService.Registry(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stack, err := store.StackService.Stack("stack")
+		if err != nil {
+			t.Fatal(err)
+		}
+		dockerhub, err := store.DockerHubService.DockerHub()
+		if err != nil {
+			t.Fatal(err)
+		}
+		user, err := store.UserService.UserByUsername("user")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if registry.Password != "registry-password" || stack.Env[0].Value != "stack-token" ||
+			dockerhub.Password != "enc:v1:dockerhub-password" || user.TOTPSecret != "totp-secret" {
+			t.Fatalf("unexpected secrets: %q, %q, %q, %q", registry.Password, stack.Env[0].Value, dockerhub.Password, user.TOTPSecret)
+		}
+	}
+	assertSecrets(store)
+
+	// Every store uses its own data key.
+	otherDataStorePath, err := ioutil.TempDir("", "chainid-encryption")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(otherDataStorePath)
+
+	other := openTestStore(t, otherDataStorePath)
+	err = other.InitEncryption([]byte("other-key"), nil)
+	other.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSecrets(store)
+
+	err = store.RotateDataKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSecrets(store)
+	store.Close()
+
+	store = openTestStore(t, dataStorePath)
+	defer store.Close()
+
+	if err = store.InitEncryption(nil, nil); err != chainid.ErrMasterKeyRequired {
+		t.Errorf("expected %v without master key, got %v", chainid.ErrMasterKeyRequired, err)
+	}
+	if err = store.InitEncryption([]byte("second-key"), nil); err != chainid.ErrInvalidMasterKey {
+		t.Errorf("expected %v with an invalid master key, got %v", chainid.ErrInvalidMasterKey, err)
+	}
+
+	err = store.InitEncryption([]byte("second-key"), []byte("first-key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSecrets(store)
+
+	err = store.InitEncryption([]byte("second-key"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSecrets(store)
+}
@@ -1,154 +1,245 @@
-package bolt
-
-import (
-	"github.com/chainid-io/dashboard"
-	"github.com/chainid-io/dashboard/bolt/internal"
-
-	"github.com/boltdb/bolt"
-)
-
-// EndpointService represents a service for managing endpoints.
-type EndpointService struct {
-	store *Store
-}
-
-// Endpoint returns an endpoint by ID.
-func (service *EndpointService) Endpoint(ID chainid.EndpointID) (*chainid.Endpoint, error) {
-	var data []byte
-	err := service.store.db.View(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte(endpointBucketName))
-		value := bucket.Get(internal.Itob(int(ID)))
-		if value == nil {
-			return chainid.ErrEndpointNotFound
-		}
-
-		data = make([]byte, len(value))
-		copy(data, value)
-		return nil
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	var endpoint chainid.Endpoint
-	err = internal.UnmarshalEndpoint(data, &endpoint)
-	if err != nil {
-		return nil, err
-	}
-	return &endpoint, nil
-}
-
-// Endpoints return an array containing all the endpoints.
-func (service *EndpointService) Endpoints() ([]chainid.Endpoint, error) {
-	var endpoints = make([]chainid.Endpoint, 0)
-	err := service.store.db.View(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte(endpointBucketName))
-
-		cursor := bucket.Cursor()
-		for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
-			var endpoint chainid.Endpoint
-			err := internal.UnmarshalEndpoint(v, &endpoint)
-			if err != nil {
-				return err
-			}
-			endpoints = append(endpoints, endpoint)
-		}
-
-		return nil
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	return endpoints, nil
-}
-
-// Synchronize creates, updates and deletes endpoints inside a single transaction.
-func (service *EndpointService) Synchronize(toCreate, toUpdate, toDelete []*chainid.Endpoint) error {
-	return service.store.db.Update(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte(endpointBucketName))
-
-		for _, endpoint := range toCreate {
-			err := storeNewEndpoint(endpoint, bucket)
-			if err != nil {
-				return err
-			}
-		}
-
-		for _, endpoint := range toUpdate {
-			err := marshalAndStoreEndpoint(endpoint, bucket)
-			if err != nil {
-				return err
-			}
-		}
-
-		for _, endpoint := range toDelete {
-			err := bucket.Delete(internal.Itob(int(endpoint.ID)))
-			if err != nil {
-				return err
-			}
-		}
-
-		return nil
-	})
-}
-
-// CreateEndpoint assign an ID to a new endpoint and saves it.
-func (service *EndpointService) CreateEndpoint(endpoint *chainid.Endpoint) error {
-	return service.store.db.Update(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte(endpointBucketName))
-		err := storeNewEndpoint(endpoint, bucket)
-		if err != nil {
-			return err
-		}
-		return nil
-	})
-}
-
-// UpdateEndpoint updates an endpoint.
-func (service *EndpointService) UpdateEndpoint(ID chainid.EndpointID, endpoint *chainid.Endpoint) error {
-	data, err := internal.MarshalEndpoint(endpoint)
-	if err != nil {
-		return err
-	}
-
-	return service.store.db.Update(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte(endpointBucketName))
-		err = bucket.Put(internal.Itob(int(ID)), data)
-		if err != nil {
-			return err
-		}
-		return nil
-	})
-}
-
-// DeleteEndpoint deletes an endpoint.
-func (service *EndpointService) DeleteEndpoint(ID chainid.EndpointID) error {
-	return service.store.db.Update(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte(endpointBucketName))
-		err := bucket.Delete(internal.Itob(int(ID)))
-		if err != nil {
-			return err
-		}
-		return nil
-	})
-}
-
-func marshalAndStoreEndpoint(endpoint *chainid.Endpoint, bucket *bolt.Bucket) error {
-	data, err := internal.MarshalEndpoint(endpoint)
-	if err != nil {
-		return err
-	}
-
-	err = bucket.Put(internal.Itob(int(endpoint.ID)), data)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-func storeNewEndpoint(endpoint *chainid.Endpoint, bucket *bolt.Bucket) error {
-	id, _ := bucket.NextSequence()
-	endpoint.ID = chainid.EndpointID(id)
-	return marshalAndStoreEndpoint(endpoint, bucket)
-}
+package bolt
+
+import (
+	"sync"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/bolt/internal"
+	"github.com/chainid-io/dashboard/internal/secrets"
+
+	"github.com/boltdb/bolt"
+)
+
+// EndpointService represents a service for managing endpoints.
+type EndpointService struct {
+	store *Store
+
+	mu        sync.RWMutex
+	observers []chainid.EndpointObserver
+}
+
+// RegisterObserver registers an observer notified after each change committed by the service.
+func (service *EndpointService) RegisterObserver(observer chainid.EndpointObserver) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	service.observers = append(service.observers, observer)
+}
+
+func (service *EndpointService) notifyChanged(endpoints ...*chainid.Endpoint) {
+	service.mu.RLock()
+	defer service.mu.RUnlock()
+
+	for _, observer := range service.observers {
+		for _, endpoint := range endpoints {
+			observer.EndpointChanged(endpoint)
+		}
+	}
+}
+
+func (service *EndpointService) notifyDeleted(ID chainid.EndpointID) {
+	service.mu.RLock()
+	defer service.mu.RUnlock()
+
+	for _, observer := range service.observers {
+		observer.EndpointDeleted(ID)
+	}
+}
+
+// Endpoint returns an endpoint by ID.
+func (service *EndpointService) Endpoint(ID chainid.EndpointID) (*chainid.Endpoint, error) {
+	var data []byte
+	var cipher chainid.EncryptionService
+	err := service.store.view(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(endpointBucketName))
+		value := bucket.Get(internal.Itob(int(ID)))
+		if value == nil {
+			return chainid.ErrEndpointNotFound
+		}
+
+		data = make([]byte, len(value))
+		copy(data, value)
+		cipher = service.store.secretCipher
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoint chainid.Endpoint
+	err = secrets.UnmarshalEndpoint(data, &endpoint, cipher)
+	if err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+// Endpoints return an array containing all the endpoints.
+func (service *EndpointService) Endpoints() ([]chainid.Endpoint, error) {
+	var endpoints = make([]chainid.Endpoint, 0)
+	err := service.store.view(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(endpointBucketName))
+
+		cursor := bucket.Cursor()
+		for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
+			var endpoint chainid.Endpoint
+			err := secrets.UnmarshalEndpoint(v, &endpoint, service.store.secretCipher)
+			if err != nil {
+				return err
+			}
+			endpoints = append(endpoints, endpoint)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return endpoints, nil
+}
+
+// Synchronize creates, updates and deletes endpoints inside a single transaction.
+func (service *EndpointService) Synchronize(toCreate, toUpdate, toDelete []*chainid.Endpoint) error {
+	err := service.store.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(endpointBucketName))
+
+		for _, endpoint := range toCreate {
+			err := service.storeNewEndpoint(endpoint, bucket)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, endpoint := range toUpdate {
+			err := service.marshalAndStoreEndpoint(endpoint, bucket)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, endpoint := range toDelete {
+			err := bucket.Delete(internal.Itob(int(endpoint.ID)))
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	service.notifyChanged(toCreate...)
+	service.notifyChanged(toUpdate...)
+	for _, endpoint := range toDelete {
+		service.notifyDeleted(endpoint.ID)
+	}
+	return nil
+}
+
+// CreateEndpoint assign an ID to a new endpoint and saves it.
+func (service *EndpointService) CreateEndpoint(endpoint *chainid.Endpoint) error {
+	err := service.store.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(endpointBucketName))
+		err := service.storeNewEndpoint(endpoint, bucket)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	service.notifyChanged(endpoint)
+	return nil
+}
+
+// UpdateEndpoint updates an endpoint.
+func (service *EndpointService) UpdateEndpoint(ID chainid.EndpointID, endpoint *chainid.Endpoint) error {
+	err := service.store.update(func(tx *bolt.Tx) error {
+		data, err := secrets.MarshalEndpoint(endpoint, service.store.secretCipher)
+		if err != nil {
+			return err
+		}
+
+		bucket := tx.Bucket([]byte(endpointBucketName))
+		err = bucket.Put(internal.Itob(int(ID)), data)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	service.notifyChanged(endpoint)
+	return nil
+}
+
+// UpdateEndpointFunc applies updateFunc to an endpoint and saves it inside a single transaction,
+// so that the changes made concurrently to the other fields of the endpoint are not overwritten.
+func (service *EndpointService) UpdateEndpointFunc(ID chainid.EndpointID, updateFunc func(endpoint *chainid.Endpoint)) error {
+	var endpoint chainid.Endpoint
+	err := service.store.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(endpointBucketName))
+		value := bucket.Get(internal.Itob(int(ID)))
+		if value == nil {
+			return chainid.ErrEndpointNotFound
+		}
+
+		err := secrets.UnmarshalEndpoint(value, &endpoint, service.store.secretCipher)
+		if err != nil {
+			return err
+		}
+
+		updateFunc(&endpoint)
+		return service.marshalAndStoreEndpoint(&endpoint, bucket)
+	})
+	if err != nil {
+		return err
+	}
+
+	service.notifyChanged(&endpoint)
+	return nil
+}
+
+// DeleteEndpoint deletes an endpoint.
+func (service *EndpointService) DeleteEndpoint(ID chainid.EndpointID) error {
+	err := service.store.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(endpointBucketName))
+		err := bucket.Delete(internal.Itob(int(ID)))
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	service.notifyDeleted(ID)
+	return nil
+}
+
+func (service *EndpointService) marshalAndStoreEndpoint(endpoint *chainid.Endpoint, bucket *bolt.Bucket) error {
+	data, err := secrets.MarshalEndpoint(endpoint, service.store.secretCipher)
+	if err != nil {
+		return err
+	}
+
+	err = bucket.Put(internal.Itob(int(endpoint.ID)), data)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (service *EndpointService) storeNewEndpoint(endpoint *chainid.Endpoint, bucket *bolt.Bucket) error {
+	id, _ := bucket.NextSequence()
+	endpoint.ID = chainid.EndpointID(id)
+	return service.marshalAndStoreEndpoint(endpoint, bucket)
+}
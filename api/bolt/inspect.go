@@ -0,0 +1,135 @@
+package bolt
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/boltdb/bolt"
+	"github.com/chainid-io/dashboard"
+)
+
+// Record represents a raw key/value pair stored in a bucket. Identifiers are
+// decoded as decimal numbers, values that are not JSON documents are encoded
+// as JSON strings. Secrets encrypted at rest are not decrypted.
+type Record struct {
+	Key   string          `json:"Key"`
+	Value json.RawMessage `json:"Value"`
+}
+
+// IsNew returns true if the database file did not exist when the store was created.
+func (store *Store) IsNew() bool {
+	return !store.checkForDataMigration
+}
+
+// Buckets returns the names of the buckets of the database.
+func (store *Store) Buckets() ([]string, error) {
+	buckets := make([]string, 0)
+	err := store.db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+			buckets = append(buckets, string(name))
+			return nil
+		})
+	})
+	return buckets, err
+}
+
+// BucketRecords returns the raw records stored in a bucket.
+func (store *Store) BucketRecords(name string) ([]Record, error) {
+	records := make([]Record, 0)
+	err := store.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(name))
+		if bucket == nil {
+			return chainid.ErrDBBucketNotFound
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			records = append(records, Record{Key: formatKey(k), Value: formatValue(v)})
+			return nil
+		})
+	})
+	return records, err
+}
+
+// formatKey returns the identifier encoded by internal.Itob, or the key itself
+// when it is a string.
+func formatKey(key []byte) string {
+	if len(key) == 8 && !printable(key) {
+		return strconv.FormatUint(binary.BigEndian.Uint64(key), 10)
+	}
+	return string(key)
+}
+
+func formatValue(value []byte) json.RawMessage {
+	if json.Valid(value) {
+		data := make([]byte, len(value))
+		copy(data, value)
+		return data
+	}
+
+	data, _ := json.Marshal(string(value))
+	return data
+}
+
+func printable(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	for _, r := range string(data) {
+		if !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// MigrateDataDryRun migrates a copy of the database to the current DBVersion, the database
+// itself is not modified. It returns the version of the database before the migration.
+func (store *Store) MigrateDataDryRun() (int, error) {
+	version, err := store.VersionService.DBVersion()
+	if err == chainid.ErrDBVersionNotFound {
+		version = 0
+	} else if err != nil {
+		return 0, err
+	}
+
+	copyPath, err := ioutil.TempDir("", "chainid-migration")
+	if err != nil {
+		return version, err
+	}
+	defer os.RemoveAll(copyPath)
+
+	file, err := os.Create(path.Join(copyPath, databaseFileName))
+	if err != nil {
+		return version, err
+	}
+
+	err = store.BackupDatabase(file)
+	file.Close()
+	if err != nil {
+		return version, err
+	}
+
+	copyStore, err := NewStore(copyPath)
+	if err != nil {
+		return version, err
+	}
+
+	err = copyStore.Open()
+	if err != nil {
+		return version, err
+	}
+	defer copyStore.Close()
+
+	err = copyStore.InitEncryption(store.masterKey, nil)
+	if err != nil {
+		return version, err
+	}
+
+	return version, copyStore.MigrateData()
+}
@@ -0,0 +1,129 @@
+package bolt
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/chainid-io/dashboard"
+)
+
+func TestBucketRecords(t *testing.T) {
+	dataStorePath, err := ioutil.TempDir("", "chainid-inspect")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dataStorePath)
+
+	store := openTestStore(t, dataStorePath)
+	defer store.Close()
+
+	err = store.MigrateData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.UserService.CreateUser(&chainid.User{Username: "admin", Role: chainid.AdministratorRole})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := store.BucketRecords(userBucketName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Key != "1" {
+		t.Fatalf("expected the user to be listed with its identifier, got %+v", records)
+	}
+
+	records, err = store.BucketRecords(versionBucketName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Key != dBVersionKey {
+		t.Fatalf("expected the version to be listed with its key, got %+v", records)
+	}
+
+	if _, err = store.BucketRecords("unknown"); err != chainid.ErrDBBucketNotFound {
+		t.Errorf("expected %v for an unknown bucket, got %v", chainid.ErrDBBucketNotFound, err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	dataStorePath, err := ioutil.TempDir("", "chainid-verify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dataStorePath)
+
+	store := openTestStore(t, dataStorePath)
+	defer store.Close()
+
+	for _, step := range []func() error{store.Init, store.MigrateData} {
+		err = step()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = store.SettingsService.StoreSettings(&chainid.Settings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.UserService.CreateUser(&chainid.User{Username: "admin", Role: chainid.AdministratorRole})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	problems, err := store.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 0 {
+		t.Fatalf("expected no problem, got %v", problems)
+	}
+
+	err = store.TeamMembershipService.CreateTeamMembership(&chainid.TeamMembership{UserID: 1, TeamID: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.VersionService.StoreDBVersion(chainid.DBVersion - 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	problems, err = store.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 2 {
+		t.Fatalf("expected the missing team and the outdated version to be reported, got %v", problems)
+	}
+}
+
+func TestMigrateDataDryRun(t *testing.T) {
+	dataStorePath, err := ioutil.TempDir("", "chainid-dry-run")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dataStorePath)
+
+	store := openTestStore(t, dataStorePath)
+	defer store.Close()
+
+	err = store.VersionService.StoreDBVersion(chainid.DBVersion - 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	version, err := store.MigrateDataDryRun()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != chainid.DBVersion-1 {
+		t.Errorf("expected the version before the migration to be %d, got %d", chainid.DBVersion-1, version)
+	}
+
+	version, err = store.VersionService.DBVersion()
+	if err != nil || version != chainid.DBVersion-1 {
+		t.Errorf("expected the database to be left untouched, got version %d, %v", version, err)
+	}
+}
@@ -0,0 +1,21 @@
+package bolt
+
+import "github.com/chainid-io/dashboard"
+
+func (m *Migrator) updateSettingsToVersion12() error {
+	legacySettings, err := m.SettingsService.Settings()
+	if err != nil {
+		return err
+	}
+	legacySettings.LockoutSettings = chainid.LockoutSettings{
+		MaxFailedAttempts: 5,
+		LockoutDuration:   15,
+	}
+
+	err = m.SettingsService.StoreSettings(legacySettings)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
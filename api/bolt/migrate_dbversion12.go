@@ -0,0 +1,42 @@
+package bolt
+
+import (
+	"time"
+
+	"github.com/chainid-io/dashboard"
+)
+
+func (m *Migrator) updateSettingsToVersion13() error {
+	legacySettings, err := m.SettingsService.Settings()
+	if err != nil {
+		return err
+	}
+	legacySettings.PasswordPolicy = chainid.PasswordPolicy{
+		MinLength: 8,
+	}
+
+	err = m.SettingsService.StoreSettings(legacySettings)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *Migrator) updateUsersToVersion13() error {
+	legacyUsers, err := m.UserService.Users()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+	for _, user := range legacyUsers {
+		user.PasswordChangedAt = now
+		err = m.UserService.UpdateUser(user.ID, &user)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
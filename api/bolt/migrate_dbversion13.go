@@ -0,0 +1,14 @@
+package bolt
+
+func (m *Migrator) updateRolesToVersion14() error {
+	roles, err := m.RoleService.Roles()
+	if err != nil {
+		return err
+	}
+
+	if len(roles) == 0 {
+		return m.store.createBuiltInRoles()
+	}
+
+	return nil
+}
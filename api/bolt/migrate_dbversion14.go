@@ -0,0 +1,9 @@
+package bolt
+
+func (m *Migrator) updateSecretsToVersion15() error {
+	if m.store.secretCipher == nil {
+		return nil
+	}
+
+	return encryptSecrets(m.store.tx, m.store.secretCipher, m.store.secretCipher)
+}
@@ -0,0 +1,263 @@
+package bolt
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/bolt/internal"
+)
+
+// loadFixture creates a database from testdata/migrations/v<version>.json. The fixture maps
+// the bucket names to the records of each bucket, numeric keys are encoded with internal.Itob.
+func loadFixture(t *testing.T, dataStorePath string, version int) {
+	data, err := ioutil.ReadFile(path.Join("testdata", "migrations", fmt.Sprintf("v%d.json", version)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fixture map[string]map[string]json.RawMessage
+	err = json.Unmarshal(data, &fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := bolt.Open(path.Join(dataStorePath, databaseFileName), 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for bucketName, records := range fixture {
+			bucket, err := tx.CreateBucketIfNotExists([]byte(bucketName))
+			if err != nil {
+				return err
+			}
+
+			for key, value := range records {
+				var k []byte
+				if ID, err := strconv.Atoi(key); err == nil {
+					k = internal.Itob(ID)
+				} else {
+					k = []byte(key)
+				}
+
+				err = bucket.Put(k, value)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func openFixtureStore(t *testing.T, version int) (*Store, string) {
+	dataStorePath, err := ioutil.TempDir("", "chainid-migration")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loadFixture(t, dataStorePath, version)
+
+	store := openTestStore(t, dataStorePath)
+	for _, step := range []func() error{
+		func() error { return store.InitEncryption(nil, nil) },
+		store.Init,
+	} {
+		err = step()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return store, dataStorePath
+}
+
+func TestMigrateFixtures(t *testing.T) {
+	for version := 0; version < chainid.DBVersion; version++ {
+		t.Run(fmt.Sprintf("v%d", version), func(t *testing.T) {
+			store, dataStorePath := openFixtureStore(t, version)
+			defer os.RemoveAll(dataStorePath)
+			defer store.Close()
+
+			err := store.MigrateData()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			problems, err := store.Verify()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(problems) != 0 {
+				t.Fatalf("expected no problem after the migration, got %v", problems)
+			}
+
+			admin, err := store.UserService.UserByUsername("admin")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if admin.ID != 1 || admin.Role != chainid.AdministratorRole || admin.PasswordChangedAt == 0 {
+				t.Errorf("unexpected administrator: %+v", admin)
+			}
+
+			endpoint, err := store.EndpointService.Endpoint(1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if endpoint.Type != chainid.DockerEnvironment || endpoint.GroupID != 1 || endpoint.AuthorizedTeams == nil {
+				t.Errorf("unexpected endpoint: %+v", endpoint)
+			}
+			if !endpoint.TLSConfig.TLS || endpoint.TLSConfig.TLSSkipVerify || endpoint.TLSConfig.TLSCACertPath != "/data/tls/1/ca.pem" {
+				t.Errorf("unexpected endpoint TLS configuration: %+v", endpoint.TLSConfig)
+			}
+
+			resourceControls, err := store.ResourceControlService.ResourceControls()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(resourceControls) != 2 {
+				t.Fatalf("expected the resource controls to be kept, got %+v", resourceControls)
+			}
+
+			settings, err := store.SettingsService.Settings()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if settings.LockoutSettings.MaxFailedAttempts != 5 || settings.PasswordPolicy.MinLength != 8 {
+				t.Errorf("unexpected settings: %+v", settings)
+			}
+
+			snapshot, err := bolt.Open(path.Join(dataStorePath, migrationSnapshotFileName), 0600, &bolt.Options{ReadOnly: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer snapshot.Close()
+
+			var snapshotVersion []byte
+			snapshot.View(func(tx *bolt.Tx) error {
+				if bucket := tx.Bucket([]byte(versionBucketName)); bucket != nil {
+					snapshotVersion = append(snapshotVersion, bucket.Get([]byte(dBVersionKey))...)
+				}
+				return nil
+			})
+			if version > 0 && string(snapshotVersion) != strconv.Itoa(version) {
+				t.Errorf("expected the snapshot to contain version %d, got %q", version, snapshotVersion)
+			}
+		})
+	}
+}
+
+func TestMigrateLegacyResourceControls(t *testing.T) {
+	store, dataStorePath := openFixtureStore(t, 1)
+	defer os.RemoveAll(dataStorePath)
+	defer store.Close()
+
+	err := store.MigrateData()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resourceControls, err := store.ResourceControlService.ResourceControls()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, resourceControl := range resourceControls {
+		switch resourceControl.Type {
+		case chainid.ContainerResourceControl:
+			if !resourceControl.AdministratorsOnly {
+				t.Errorf("expected the resource owned by the administrator to be restricted to administrators, got %+v", resourceControl)
+			}
+		case chainid.ServiceResourceControl:
+			if resourceControl.AdministratorsOnly || len(resourceControl.UserAccesses) != 1 || resourceControl.UserAccesses[0].UserID != 2 {
+				t.Errorf("expected the resource owned by the user to be shared with the user, got %+v", resourceControl)
+			}
+		}
+	}
+}
+
+func TestMigrateDataFailure(t *testing.T) {
+	dataStorePath, err := ioutil.TempDir("", "chainid-migration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dataStorePath)
+
+	// The resource control references an unknown owner, the migration fails after the
+	// administrator has been migrated.
+	loadFixture(t, dataStorePath, 0)
+	db, err := bolt.Open(path.Join(dataStorePath, databaseFileName), 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte("containerResourceControl")).Put(internal.Itob(2), []byte(`{"ResourceId":"4e5f6a","OwnerId":42,"AccessLevel":1}`))
+	})
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := openTestStore(t, dataStorePath)
+	defer store.Close()
+
+	if err = store.MigrateData(); err != chainid.ErrUserNotFound {
+		t.Fatalf("expected the migration to fail with %v, got %v", chainid.ErrUserNotFound, err)
+	}
+
+	if _, err = store.VersionService.DBVersion(); err != chainid.ErrDBVersionNotFound {
+		t.Errorf("expected the version not to be stored, got %v", err)
+	}
+	records, err := store.BucketRecords(userBucketName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Key != "admin" {
+		t.Errorf("expected the legacy administrator to be left untouched, got %+v", records)
+	}
+}
+
+func TestRollbackMigration(t *testing.T) {
+	store, dataStorePath := openFixtureStore(t, 10)
+	defer os.RemoveAll(dataStorePath)
+
+	err := store.MigrateData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	store, err = NewStore(dataStorePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	version, err := store.RollbackMigration()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 10 {
+		t.Errorf("expected version 10 to be restored, got %d", version)
+	}
+
+	if _, err = store.RollbackMigration(); err != chainid.ErrMigrationSnapshotNotFound {
+		t.Errorf("expected %v once the snapshot is restored, got %v", chainid.ErrMigrationSnapshotNotFound, err)
+	}
+
+	store = openTestStore(t, dataStorePath)
+	defer store.Close()
+
+	version, err = store.VersionService.DBVersion()
+	if err != nil || version != 10 {
+		t.Errorf("expected the database to be at version 10, got %d, %v", version, err)
+	}
+}
@@ -0,0 +1,149 @@
+package bolt
+
+import (
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/bolt/internal"
+
+	"github.com/boltdb/bolt"
+)
+
+// RoleAssignmentService represents a service for managing role assignments.
+type RoleAssignmentService struct {
+	store *Store
+}
+
+// RoleAssignment returns a RoleAssignment by ID
+func (service *RoleAssignmentService) RoleAssignment(ID chainid.RoleAssignmentID) (*chainid.RoleAssignment, error) {
+	var data []byte
+	err := service.store.view(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(roleAssignmentBucketName))
+		value := bucket.Get(internal.Itob(int(ID)))
+		if value == nil {
+			return chainid.ErrRoleAssignmentNotFound
+		}
+
+		data = make([]byte, len(value))
+		copy(data, value)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var assignment chainid.RoleAssignment
+	err = internal.UnmarshalRoleAssignment(data, &assignment)
+	if err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+// RoleAssignments return an array containing all the role assignments.
+func (service *RoleAssignmentService) RoleAssignments() ([]chainid.RoleAssignment, error) {
+	var assignments = make([]chainid.RoleAssignment, 0)
+	err := service.store.view(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(roleAssignmentBucketName))
+
+		cursor := bucket.Cursor()
+		for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
+			var assignment chainid.RoleAssignment
+			err := internal.UnmarshalRoleAssignment(v, &assignment)
+			if err != nil {
+				return err
+			}
+			assignments = append(assignments, assignment)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return assignments, nil
+}
+
+// CreateRoleAssignment creates a new RoleAssignment.
+func (service *RoleAssignmentService) CreateRoleAssignment(assignment *chainid.RoleAssignment) error {
+	return service.store.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(roleAssignmentBucketName))
+
+		id, _ := bucket.NextSequence()
+		assignment.ID = chainid.RoleAssignmentID(id)
+
+		data, err := internal.MarshalRoleAssignment(assignment)
+		if err != nil {
+			return err
+		}
+
+		err = bucket.Put(internal.Itob(int(assignment.ID)), data)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+// DeleteRoleAssignment deletes a RoleAssignment.
+func (service *RoleAssignmentService) DeleteRoleAssignment(ID chainid.RoleAssignmentID) error {
+	return service.store.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(roleAssignmentBucketName))
+		err := bucket.Delete(internal.Itob(int(ID)))
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+// DeleteRoleAssignmentsByUserID deletes all the RoleAssignment objects associated to a UserID.
+func (service *RoleAssignmentService) DeleteRoleAssignmentsByUserID(userID chainid.UserID) error {
+	return service.deleteRoleAssignments(func(assignment *chainid.RoleAssignment) bool {
+		return assignment.UserID == userID
+	})
+}
+
+// DeleteRoleAssignmentsByTeamID deletes all the RoleAssignment objects associated to a TeamID.
+func (service *RoleAssignmentService) DeleteRoleAssignmentsByTeamID(teamID chainid.TeamID) error {
+	return service.deleteRoleAssignments(func(assignment *chainid.RoleAssignment) bool {
+		return assignment.TeamID == teamID
+	})
+}
+
+// DeleteRoleAssignmentsByRoleID deletes all the RoleAssignment objects associated to a RoleID.
+func (service *RoleAssignmentService) DeleteRoleAssignmentsByRoleID(roleID chainid.RoleID) error {
+	return service.deleteRoleAssignments(func(assignment *chainid.RoleAssignment) bool {
+		return assignment.RoleID == roleID
+	})
+}
+
+// deleteRoleAssignments removes every assignment matching the filter in a single transaction.
+// Keys are collected first as deleting while iterating a bolt cursor skips entries.
+func (service *RoleAssignmentService) deleteRoleAssignments(match func(assignment *chainid.RoleAssignment) bool) error {
+	return service.store.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(roleAssignmentBucketName))
+
+		keys := make([][]byte, 0)
+		cursor := bucket.Cursor()
+		for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
+			var assignment chainid.RoleAssignment
+			err := internal.UnmarshalRoleAssignment(v, &assignment)
+			if err != nil {
+				return err
+			}
+			if match(&assignment) {
+				key := make([]byte, len(k))
+				copy(key, k)
+				keys = append(keys, key)
+			}
+		}
+
+		for _, k := range keys {
+			err := bucket.Delete(k)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
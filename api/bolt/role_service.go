@@ -0,0 +1,145 @@
+package bolt
+
+import (
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/bolt/internal"
+
+	"github.com/boltdb/bolt"
+)
+
+// RoleService represents a service for managing roles.
+type RoleService struct {
+	store *Store
+}
+
+// Role returns a Role by ID
+func (service *RoleService) Role(ID chainid.RoleID) (*chainid.Role, error) {
+	var data []byte
+	err := service.store.view(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(roleBucketName))
+		value := bucket.Get(internal.Itob(int(ID)))
+		if value == nil {
+			return chainid.ErrRoleNotFound
+		}
+
+		data = make([]byte, len(value))
+		copy(data, value)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var role chainid.Role
+	err = internal.UnmarshalRole(data, &role)
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// RoleByName returns a role by name.
+func (service *RoleService) RoleByName(name string) (*chainid.Role, error) {
+	var role *chainid.Role
+
+	err := service.store.view(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(roleBucketName))
+		cursor := bucket.Cursor()
+		for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
+			var r chainid.Role
+			err := internal.UnmarshalRole(v, &r)
+			if err != nil {
+				return err
+			}
+			if r.Name == name {
+				role = &r
+				break
+			}
+		}
+
+		if role == nil {
+			return chainid.ErrRoleNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// Roles return an array containing all the roles.
+func (service *RoleService) Roles() ([]chainid.Role, error) {
+	var roles = make([]chainid.Role, 0)
+	err := service.store.view(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(roleBucketName))
+
+		cursor := bucket.Cursor()
+		for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
+			var role chainid.Role
+			err := internal.UnmarshalRole(v, &role)
+			if err != nil {
+				return err
+			}
+			roles = append(roles, role)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// UpdateRole saves a Role.
+func (service *RoleService) UpdateRole(ID chainid.RoleID, role *chainid.Role) error {
+	data, err := internal.MarshalRole(role)
+	if err != nil {
+		return err
+	}
+
+	return service.store.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(roleBucketName))
+		err = bucket.Put(internal.Itob(int(ID)), data)
+
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+// CreateRole creates a new Role.
+func (service *RoleService) CreateRole(role *chainid.Role) error {
+	return service.store.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(roleBucketName))
+
+		id, _ := bucket.NextSequence()
+		role.ID = chainid.RoleID(id)
+
+		data, err := internal.MarshalRole(role)
+		if err != nil {
+			return err
+		}
+
+		err = bucket.Put(internal.Itob(int(role.ID)), data)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+// DeleteRole deletes a Role.
+func (service *RoleService) DeleteRole(ID chainid.RoleID) error {
+	return service.store.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(roleBucketName))
+		err := bucket.Delete(internal.Itob(int(ID)))
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+}
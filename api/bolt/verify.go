@@ -0,0 +1,169 @@
+package bolt
+
+import (
+	"fmt"
+
+	"github.com/chainid-io/dashboard"
+)
+
+// Verify checks the consistency of the data and returns the problems found. It returns
+// an error only when the database cannot be read.
+func (store *Store) Verify() ([]string, error) {
+	problems := make([]string, 0)
+	report := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	version, err := store.VersionService.DBVersion()
+	if err == chainid.ErrDBVersionNotFound {
+		report("the database version is missing")
+	} else if err != nil {
+		return nil, err
+	} else if version > chainid.DBVersion {
+		report("the database version %d is more recent than the supported version %d", version, chainid.DBVersion)
+	} else if version < chainid.DBVersion {
+		report("the database version %d must be migrated to version %d", version, chainid.DBVersion)
+	}
+
+	users, err := store.UserService.Users()
+	if err != nil {
+		report("unable to read the users: %s", err)
+	}
+	userIDs := make(map[chainid.UserID]bool)
+	administrators := 0
+	for _, user := range users {
+		userIDs[user.ID] = true
+		if user.Role == chainid.AdministratorRole {
+			administrators++
+		}
+	}
+	if len(users) > 0 && administrators == 0 {
+		report("no user has the administrator role")
+	}
+
+	teams, err := store.TeamService.Teams()
+	if err != nil {
+		report("unable to read the teams: %s", err)
+	}
+	teamIDs := make(map[chainid.TeamID]bool)
+	for _, team := range teams {
+		teamIDs[team.ID] = true
+	}
+
+	groups, err := store.EndpointGroupService.EndpointGroups()
+	if err != nil {
+		report("unable to read the endpoint groups: %s", err)
+	}
+	groupIDs := make(map[chainid.EndpointGroupID]bool)
+	for _, group := range groups {
+		groupIDs[group.ID] = true
+		verifyAuthorizations(report, fmt.Sprintf("endpoint group %d", group.ID), group.AuthorizedUsers, group.AuthorizedTeams, userIDs, teamIDs)
+	}
+	if !groupIDs[chainid.EndpointGroupID(1)] {
+		report("the Unassigned endpoint group (1) is missing")
+	}
+
+	endpoints, err := store.EndpointService.Endpoints()
+	if err != nil {
+		report("unable to read the endpoints: %s", err)
+	}
+	for _, endpoint := range endpoints {
+		if !groupIDs[endpoint.GroupID] {
+			report("endpoint %d references the missing endpoint group %d", endpoint.ID, endpoint.GroupID)
+		}
+		verifyAuthorizations(report, fmt.Sprintf("endpoint %d", endpoint.ID), endpoint.AuthorizedUsers, endpoint.AuthorizedTeams, userIDs, teamIDs)
+	}
+
+	memberships, err := store.TeamMembershipService.TeamMemberships()
+	if err != nil {
+		report("unable to read the team memberships: %s", err)
+	}
+	for _, membership := range memberships {
+		if !userIDs[membership.UserID] {
+			report("team membership %d references the missing user %d", membership.ID, membership.UserID)
+		}
+		if !teamIDs[membership.TeamID] {
+			report("team membership %d references the missing team %d", membership.ID, membership.TeamID)
+		}
+	}
+
+	roles, err := store.RoleService.Roles()
+	if err != nil {
+		report("unable to read the roles: %s", err)
+	}
+	roleIDs := make(map[chainid.RoleID]bool)
+	for _, role := range roles {
+		roleIDs[role.ID] = true
+	}
+	if !roleIDs[chainid.AdministratorRoleID] || !roleIDs[chainid.StandardUserRoleID] {
+		report("the built-in roles are missing")
+	}
+
+	assignments, err := store.RoleAssignmentService.RoleAssignments()
+	if err != nil {
+		report("unable to read the role assignments: %s", err)
+	}
+	for _, assignment := range assignments {
+		if !roleIDs[assignment.RoleID] {
+			report("role assignment %d references the missing role %d", assignment.ID, assignment.RoleID)
+		}
+		if assignment.UserID != 0 && !userIDs[assignment.UserID] {
+			report("role assignment %d references the missing user %d", assignment.ID, assignment.UserID)
+		}
+		if assignment.TeamID != 0 && !teamIDs[assignment.TeamID] {
+			report("role assignment %d references the missing team %d", assignment.ID, assignment.TeamID)
+		}
+		if assignment.EndpointGroupID != 0 && !groupIDs[assignment.EndpointGroupID] {
+			report("role assignment %d references the missing endpoint group %d", assignment.ID, assignment.EndpointGroupID)
+		}
+	}
+
+	resourceControls, err := store.ResourceControlService.ResourceControls()
+	if err != nil {
+		report("unable to read the resource controls: %s", err)
+	}
+	for _, resourceControl := range resourceControls {
+		for _, access := range resourceControl.UserAccesses {
+			if !userIDs[access.UserID] {
+				report("resource control %d references the missing user %d", resourceControl.ID, access.UserID)
+			}
+		}
+		for _, access := range resourceControl.TeamAccesses {
+			if !teamIDs[access.TeamID] {
+				report("resource control %d references the missing team %d", resourceControl.ID, access.TeamID)
+			}
+		}
+	}
+
+	_, err = store.RegistryService.Registries()
+	if err != nil {
+		report("unable to read the registries: %s", err)
+	}
+
+	_, err = store.StackService.Stacks()
+	if err != nil {
+		report("unable to read the stacks: %s", err)
+	}
+
+	_, err = store.SettingsService.Settings()
+	if err == chainid.ErrSettingsNotFound {
+		report("the settings are missing")
+	} else if err != nil {
+		report("unable to read the settings: %s", err)
+	}
+
+	return problems, nil
+}
+
+func verifyAuthorizations(report func(string, ...interface{}), owner string, authorizedUsers []chainid.UserID, authorizedTeams []chainid.TeamID, userIDs map[chainid.UserID]bool, teamIDs map[chainid.TeamID]bool) {
+	for _, ID := range authorizedUsers {
+		if !userIDs[ID] {
+			report("%s authorizes the missing user %d", owner, ID)
+		}
+	}
+	for _, ID := range authorizedTeams {
+		if !teamIDs[ID] {
+			report("%s authorizes the missing team %d", owner, ID)
+		}
+	}
+}
@@ -0,0 +1,449 @@
+package certificate
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	acmeRequestTimeout = 30 * time.Second
+	acmePollInterval   = 2 * time.Second
+	acmePollAttempts   = 60
+
+	acmeChallengePath = "/.well-known/acme-challenge/"
+	badNonceError     = "urn:ietf:params:acme:error:badNonce"
+)
+
+type (
+	// ACMEClient obtains certificates from an ACME server (RFC 8555), such as Let's Encrypt, using
+	// the HTTP-01 challenge. The account is registered with the first order.
+	ACMEClient struct {
+		directoryURL string
+		email        string
+		key          *ecdsa.PrivateKey
+		client       *http.Client
+		pollInterval time.Duration
+
+		mu         sync.Mutex
+		directory  *acmeDirectory
+		accountURL string
+		nonce      string
+
+		challengesMu sync.RWMutex
+		challenges   map[string]string
+	}
+
+	acmeDirectory struct {
+		NewNonce   string `json:"newNonce"`
+		NewAccount string `json:"newAccount"`
+		NewOrder   string `json:"newOrder"`
+	}
+
+	acmeIdentifier struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	}
+
+	acmeOrder struct {
+		Status         string       `json:"status"`
+		Authorizations []string     `json:"authorizations"`
+		Finalize       string       `json:"finalize"`
+		Certificate    string       `json:"certificate"`
+		Error          *acmeProblem `json:"error"`
+	}
+
+	acmeAuthorization struct {
+		Status     string          `json:"status"`
+		Identifier acmeIdentifier  `json:"identifier"`
+		Challenges []acmeChallenge `json:"challenges"`
+	}
+
+	acmeChallenge struct {
+		Type   string       `json:"type"`
+		URL    string       `json:"url"`
+		Token  string       `json:"token"`
+		Status string       `json:"status"`
+		Error  *acmeProblem `json:"error"`
+	}
+
+	// acmeProblem is an error returned by the ACME server (RFC 7807).
+	acmeProblem struct {
+		Type   string `json:"type"`
+		Detail string `json:"detail"`
+		Status int    `json:"status"`
+	}
+)
+
+func (problem *acmeProblem) Error() string {
+	return fmt.Sprintf("ACME server error (%s): %s", problem.Type, problem.Detail)
+}
+
+// NewACMEClient initializes a new client using the directory of an ACME server. The account key
+// identifies the ACME account, the email address is optional.
+func NewACMEClient(directoryURL, email string, accountKey *ecdsa.PrivateKey) *ACMEClient {
+	return &ACMEClient{
+		directoryURL: directoryURL,
+		email:        email,
+		key:          accountKey,
+		client:       &http.Client{Timeout: acmeRequestTimeout},
+		pollInterval: acmePollInterval,
+		challenges:   make(map[string]string),
+	}
+}
+
+// LoadACMEAccountKey reads the key of the ACME account from a file, a new key is generated and written
+// to the file when it does not exist.
+func LoadACMEAccountKey(path string) (*ecdsa.PrivateKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+
+		keyPEM, err := encodeKey(key)
+		if err != nil {
+			return nil, err
+		}
+		return key, writeFileAtomically(path, keyPEM, 0600)
+	} else if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("invalid ACME account key file %s", path)
+	}
+	return x509.ParseECPrivateKey(block.Bytes)
+}
+
+// HTTPHandler returns a handler answering the HTTP-01 challenges of the pending orders and passing
+// the other requests to next.
+func (client *ACMEClient) HTTPHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, acmeChallengePath) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		client.challengesMu.RLock()
+		keyAuthorization, ok := client.challenges[strings.TrimPrefix(r.URL.Path, acmeChallengePath)]
+		client.challengesMu.RUnlock()
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(keyAuthorization))
+	})
+}
+
+// ObtainCertificate orders a certificate valid for the domains and returns the PEM encoded
+// certificate chain along with the PEM encoded key of the certificate.
+func (client *ACMEClient) ObtainCertificate(domains []string) ([]byte, []byte, error) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	err := client.register()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	identifiers := make([]acmeIdentifier, 0, len(domains))
+	for _, domain := range domains {
+		identifiers = append(identifiers, acmeIdentifier{Type: "dns", Value: domain})
+	}
+
+	var order acmeOrder
+	resp, err := client.post(client.directory.NewOrder, map[string]interface{}{"identifiers": identifiers}, &order)
+	if err != nil {
+		return nil, nil, err
+	}
+	orderURL := resp.Header.Get("Location")
+
+	for _, authorizationURL := range order.Authorizations {
+		err = client.authorize(authorizationURL)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: domains[0]},
+		DNSNames: domains,
+	}, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, err = client.post(order.Finalize, map[string]string{"csr": base64.RawURLEncoding.EncodeToString(csr)}, &order)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for attempt := 0; order.Status != "valid"; attempt++ {
+		if order.Status == "invalid" || attempt == acmePollAttempts {
+			if order.Error != nil {
+				return nil, nil, order.Error
+			}
+			return nil, nil, fmt.Errorf("the ACME order of %v is %s", domains, order.Status)
+		}
+		time.Sleep(client.pollInterval)
+
+		_, err = client.post(orderURL, nil, &order)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	_, certificate, err := client.request(order.Certificate, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyPEM, err := encodeKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return certificate, keyPEM, nil
+}
+
+// register retrieves the directory and creates the account, or retrieves the account
+// when the key is already registered.
+func (client *ACMEClient) register() error {
+	if client.accountURL != "" {
+		return nil
+	}
+
+	resp, err := client.client.Get(client.directoryURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d when retrieving the ACME directory", resp.StatusCode)
+	}
+
+	client.directory = &acmeDirectory{}
+	err = json.NewDecoder(resp.Body).Decode(client.directory)
+	if err != nil {
+		return err
+	}
+
+	account := map[string]interface{}{"termsOfServiceAgreed": true}
+	if client.email != "" {
+		account["contact"] = []string{"mailto:" + client.email}
+	}
+
+	resp, err = client.post(client.directory.NewAccount, account, nil)
+	if err != nil {
+		return err
+	}
+	client.accountURL = resp.Header.Get("Location")
+	return nil
+}
+
+// authorize completes the HTTP-01 challenge of an authorization, unless it is already valid.
+func (client *ACMEClient) authorize(authorizationURL string) error {
+	var authorization acmeAuthorization
+	_, err := client.post(authorizationURL, nil, &authorization)
+	if err != nil {
+		return err
+	}
+	if authorization.Status == "valid" {
+		return nil
+	}
+
+	var challenge *acmeChallenge
+	for idx := range authorization.Challenges {
+		if authorization.Challenges[idx].Type == "http-01" {
+			challenge = &authorization.Challenges[idx]
+		}
+	}
+	if challenge == nil {
+		return fmt.Errorf("the ACME server does not offer the http-01 challenge for %s", authorization.Identifier.Value)
+	}
+
+	client.challengesMu.Lock()
+	client.challenges[challenge.Token] = challenge.Token + "." + client.thumbprint()
+	client.challengesMu.Unlock()
+
+	defer func() {
+		client.challengesMu.Lock()
+		delete(client.challenges, challenge.Token)
+		client.challengesMu.Unlock()
+	}()
+
+	_, err = client.post(challenge.URL, struct{}{}, nil)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 0; authorization.Status != "valid"; attempt++ {
+		if authorization.Status == "invalid" || attempt == acmePollAttempts {
+			for _, challenge := range authorization.Challenges {
+				if challenge.Error != nil {
+					return challenge.Error
+				}
+			}
+			return fmt.Errorf("the ACME authorization of %s is %s", authorization.Identifier.Value, authorization.Status)
+		}
+		time.Sleep(client.pollInterval)
+
+		_, err = client.post(authorizationURL, nil, &authorization)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// post sends a signed request and decodes the JSON response into result, when it is not nil.
+// A nil payload sends a POST-as-GET request.
+func (client *ACMEClient) post(url string, payload, result interface{}) (*http.Response, error) {
+	resp, body, err := client.request(url, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if result != nil {
+		err = json.Unmarshal(body, result)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// request sends a signed request and returns the response along with its body. The request is
+// sent again once with a new nonce when the server rejects the nonce.
+func (client *ACMEClient) request(url string, payload interface{}) (*http.Response, []byte, error) {
+	for attempt := 0; ; attempt++ {
+		body, err := client.sign(url, payload)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		resp, err := client.client.Post(url, "application/jose+json", bytes.NewReader(body))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		data, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+		client.nonce = resp.Header.Get("Replay-Nonce")
+
+		if resp.StatusCode >= http.StatusBadRequest {
+			problem := &acmeProblem{Status: resp.StatusCode}
+			json.Unmarshal(data, problem)
+			if problem.Type == badNonceError && attempt == 0 {
+				continue
+			}
+			return nil, nil, problem
+		}
+		return resp, data, nil
+	}
+}
+
+// sign returns the JWS (RFC 7515) of a request, signed with the account key. The key is
+// identified by the account URL once the account is registered.
+func (client *ACMEClient) sign(url string, payload interface{}) ([]byte, error) {
+	if client.nonce == "" {
+		resp, err := client.client.Head(client.directory.NewNonce)
+		if err != nil {
+			return nil, err
+		}
+		resp.Body.Close()
+		client.nonce = resp.Header.Get("Replay-Nonce")
+	}
+
+	protected := map[string]interface{}{"alg": "ES256", "nonce": client.nonce, "url": url}
+	if client.accountURL != "" {
+		protected["kid"] = client.accountURL
+	} else {
+		protected["jwk"] = client.jwk()
+	}
+	client.nonce = ""
+
+	protectedJSON, err := json.Marshal(protected)
+	if err != nil {
+		return nil, err
+	}
+
+	payloadJSON := []byte{}
+	if payload != nil {
+		payloadJSON, err = json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	encodedProtected := base64.RawURLEncoding.EncodeToString(protectedJSON)
+	encodedPayload := base64.RawURLEncoding.EncodeToString(payloadJSON)
+
+	digest := sha256.Sum256([]byte(encodedProtected + "." + encodedPayload))
+	r, s, err := ecdsa.Sign(rand.Reader, client.key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+	signature := append(padBytes(r, 32), padBytes(s, 32)...)
+
+	return json.Marshal(map[string]string{
+		"protected": encodedProtected,
+		"payload":   encodedPayload,
+		"signature": base64.RawURLEncoding.EncodeToString(signature),
+	})
+}
+
+// jwk returns the JSON Web Key (RFC 7517) of the account key, its members are sorted
+// as required to compute its thumbprint.
+func (client *ACMEClient) jwk() map[string]string {
+	return map[string]string{
+		"crv": "P-256",
+		"kty": "EC",
+		"x":   base64.RawURLEncoding.EncodeToString(padBytes(client.key.X, 32)),
+		"y":   base64.RawURLEncoding.EncodeToString(padBytes(client.key.Y, 32)),
+	}
+}
+
+// thumbprint returns the JWK thumbprint (RFC 7638) of the account key, used in the key authorizations.
+func (client *ACMEClient) thumbprint() string {
+	jwk, _ := json.Marshal(client.jwk())
+	digest := sha256.Sum256(jwk)
+	return base64.RawURLEncoding.EncodeToString(digest[:])
+}
+
+// padBytes returns the big-endian representation of n, left padded with zeros to size bytes.
+func padBytes(n *big.Int, size int) []byte {
+	b := n.Bytes()
+	if len(b) >= size {
+		return b
+	}
+	return append(make([]byte, size-len(b)), b...)
+}
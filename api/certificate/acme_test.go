@@ -0,0 +1,423 @@
+package certificate
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type (
+	// testACMEServer is a minimal ACME server, it validates the HTTP-01 challenges by querying
+	// the challenge server and issues the certificates with its own CA.
+	testACMEServer struct {
+		*httptest.Server
+		ca              *x509.Certificate
+		caKey           *ecdsa.PrivateKey
+		challengeServer string
+
+		mu          sync.Mutex
+		nonces      map[string]bool
+		nonceCount  int
+		rejectNonce bool
+		accounts    map[string]*ecdsa.PublicKey
+		orders      []*testACMEOrder
+	}
+
+	testACMEOrder struct {
+		domains     []string
+		authorized  []string
+		certificate []byte
+	}
+
+	testJWS struct {
+		Protected string `json:"protected"`
+		Payload   string `json:"payload"`
+		Signature string `json:"signature"`
+	}
+
+	testJWSHeader struct {
+		Alg   string            `json:"alg"`
+		Nonce string            `json:"nonce"`
+		URL   string            `json:"url"`
+		Kid   string            `json:"kid"`
+		JWK   map[string]string `json:"jwk"`
+	}
+)
+
+func newTestACMEServer(t *testing.T) *testACMEServer {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test ACME CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := &testACMEServer{
+		ca:          ca,
+		caKey:       caKey,
+		nonces:      make(map[string]bool),
+		rejectNonce: true,
+		accounts:    make(map[string]*ecdsa.PublicKey),
+	}
+	server.Server = httptest.NewServer(http.HandlerFunc(server.serveHTTP))
+	return server
+}
+
+func (server *testACMEServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	server.nonceCount++
+	nonce := fmt.Sprintf("nonce-%d", server.nonceCount)
+	server.nonces[nonce] = true
+	w.Header().Set("Replay-Nonce", nonce)
+
+	switch {
+	case r.URL.Path == "/directory":
+		json.NewEncoder(w).Encode(map[string]string{
+			"newNonce":   server.URL + "/nonce",
+			"newAccount": server.URL + "/account",
+			"newOrder":   server.URL + "/new-order",
+		})
+		return
+	case r.URL.Path == "/nonce":
+		return
+	}
+
+	payload, key, err := server.verify(r)
+	if err != nil {
+		writeProblem(w, err.Error())
+		return
+	}
+
+	var orderID, idx int
+	switch {
+	case r.URL.Path == "/account":
+		accountURL := fmt.Sprintf("%s/account/%d", server.URL, len(server.accounts))
+		server.accounts[accountURL] = key
+		w.Header().Set("Location", accountURL)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("{}"))
+	case r.URL.Path == "/new-order":
+		var request struct {
+			Identifiers []acmeIdentifier `json:"identifiers"`
+		}
+		json.Unmarshal(payload, &request)
+
+		order := &testACMEOrder{}
+		for _, identifier := range request.Identifiers {
+			order.domains = append(order.domains, identifier.Value)
+			order.authorized = append(order.authorized, "pending")
+		}
+		server.orders = append(server.orders, order)
+
+		w.Header().Set("Location", fmt.Sprintf("%s/order/%d", server.URL, len(server.orders)-1))
+		w.WriteHeader(http.StatusCreated)
+		server.writeOrder(w, len(server.orders)-1)
+	case scanPath(r.URL.Path, "/order/%d", &orderID):
+		server.writeOrder(w, orderID)
+	case scanPath(r.URL.Path, "/authz/%d/%d", &orderID, &idx):
+		server.writeAuthorization(w, orderID, idx)
+	case scanPath(r.URL.Path, "/challenge/%d/%d", &orderID, &idx):
+		order := server.orders[orderID]
+		order.authorized[idx] = "invalid"
+		if server.validateChallenge(order.domains[idx], fmt.Sprintf("token-%d-%d", orderID, idx), key) {
+			order.authorized[idx] = "valid"
+		}
+		w.Write([]byte("{}"))
+	case scanPath(r.URL.Path, "/finalize/%d", &orderID):
+		err = server.finalize(orderID, payload)
+		if err != nil {
+			writeProblem(w, err.Error())
+			return
+		}
+		server.writeOrder(w, orderID)
+	case scanPath(r.URL.Path, "/certificate/%d", &orderID):
+		w.Header().Set("Content-Type", "application/pem-certificate-chain")
+		w.Write(server.orders[orderID].certificate)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// verify checks the nonce and the signature of a request, it returns the payload along with the account key.
+func (server *testACMEServer) verify(r *http.Request) ([]byte, *ecdsa.PublicKey, error) {
+	var jws testJWS
+	err := json.NewDecoder(r.Body).Decode(&jws)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	protected, _ := base64.RawURLEncoding.DecodeString(jws.Protected)
+	var header testJWSHeader
+	err = json.Unmarshal(protected, &header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !server.nonces[header.Nonce] {
+		return nil, nil, fmt.Errorf("unknown nonce %s", header.Nonce)
+	}
+	delete(server.nonces, header.Nonce)
+	if server.rejectNonce {
+		server.rejectNonce = false
+		return nil, nil, fmt.Errorf(badNonceError)
+	}
+	if header.Alg != "ES256" || header.URL != server.URL+r.URL.Path {
+		return nil, nil, fmt.Errorf("invalid protected header %s", protected)
+	}
+
+	key := server.accounts[header.Kid]
+	if header.JWK != nil {
+		x, _ := base64.RawURLEncoding.DecodeString(header.JWK["x"])
+		y, _ := base64.RawURLEncoding.DecodeString(header.JWK["y"])
+		key = &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	}
+	if key == nil {
+		return nil, nil, fmt.Errorf("unknown account %s", header.Kid)
+	}
+
+	signature, _ := base64.RawURLEncoding.DecodeString(jws.Signature)
+	digest := sha256.Sum256([]byte(jws.Protected + "." + jws.Payload))
+	if len(signature) != 64 || !ecdsa.Verify(key, digest[:], new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])) {
+		return nil, nil, fmt.Errorf("invalid signature")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(jws.Payload)
+	return payload, key, err
+}
+
+func (server *testACMEServer) validateChallenge(domain, token string, key *ecdsa.PublicKey) bool {
+	req, _ := http.NewRequest(http.MethodGet, server.challengeServer+acmeChallengePath+token, nil)
+	req.Host = domain
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	jwk, _ := json.Marshal(map[string]string{
+		"crv": "P-256",
+		"kty": "EC",
+		"x":   base64.RawURLEncoding.EncodeToString(padBytes(key.X, 32)),
+		"y":   base64.RawURLEncoding.EncodeToString(padBytes(key.Y, 32)),
+	})
+	thumbprint := sha256.Sum256(jwk)
+
+	keyAuthorization, _ := ioutil.ReadAll(resp.Body)
+	return string(keyAuthorization) == token+"."+base64.RawURLEncoding.EncodeToString(thumbprint[:])
+}
+
+func (server *testACMEServer) finalize(orderID int, payload []byte) error {
+	order := server.orders[orderID]
+	for _, status := range order.authorized {
+		if status != "valid" {
+			return fmt.Errorf("the order is not authorized")
+		}
+	}
+
+	var request struct {
+		CSR string `json:"csr"`
+	}
+	json.Unmarshal(payload, &request)
+	der, _ := base64.RawURLEncoding.DecodeString(request.CSR)
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(csr.DNSNames, order.domains) {
+		return fmt.Errorf("unexpected domains %v", csr.DNSNames)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(int64(orderID + 2)),
+		Subject:      pkix.Name{CommonName: order.domains[0]},
+		DNSNames:     csr.DNSNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(90 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err = x509.CreateCertificate(rand.Reader, template, server.ca, csr.PublicKey, server.caKey)
+	if err != nil {
+		return err
+	}
+
+	order.certificate = append(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.ca.Raw})...)
+	return nil
+}
+
+func (server *testACMEServer) writeOrder(w http.ResponseWriter, orderID int) {
+	order := server.orders[orderID]
+	response := acmeOrder{
+		Status:   "pending",
+		Finalize: fmt.Sprintf("%s/finalize/%d", server.URL, orderID),
+	}
+	for idx := range order.domains {
+		response.Authorizations = append(response.Authorizations, fmt.Sprintf("%s/authz/%d/%d", server.URL, orderID, idx))
+	}
+	if order.certificate != nil {
+		response.Status = "valid"
+		response.Certificate = fmt.Sprintf("%s/certificate/%d", server.URL, orderID)
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (server *testACMEServer) writeAuthorization(w http.ResponseWriter, orderID, idx int) {
+	order := server.orders[orderID]
+	challenge := acmeChallenge{
+		Type:   "http-01",
+		URL:    fmt.Sprintf("%s/challenge/%d/%d", server.URL, orderID, idx),
+		Token:  fmt.Sprintf("token-%d-%d", orderID, idx),
+		Status: order.authorized[idx],
+	}
+	if challenge.Status == "invalid" {
+		challenge.Error = &acmeProblem{Type: "urn:ietf:params:acme:error:unauthorized", Detail: "invalid key authorization"}
+	}
+
+	json.NewEncoder(w).Encode(acmeAuthorization{
+		Status:     order.authorized[idx],
+		Identifier: acmeIdentifier{Type: "dns", Value: order.domains[idx]},
+		Challenges: []acmeChallenge{{Type: "dns-01", URL: server.URL + "/unsupported", Token: "dns"}, challenge},
+	})
+}
+
+func writeProblem(w http.ResponseWriter, detail string) {
+	problemType := "urn:ietf:params:acme:error:malformed"
+	if detail == badNonceError {
+		problemType = badNonceError
+	}
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(acmeProblem{Type: problemType, Detail: detail, Status: http.StatusBadRequest})
+}
+
+func scanPath(path, format string, args ...interface{}) bool {
+	n, err := fmt.Sscanf(path, format, args...)
+	return err == nil && n == len(args) && strings.Count(path, "/") == strings.Count(format, "/")
+}
+
+func TestManagerACME(t *testing.T) {
+	dir, err := ioutil.TempDir("", "certificate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	acmeServer := newTestACMEServer(t)
+	defer acmeServer.Close()
+
+	accountKey, err := LoadACMEAccountKey(filepath.Join(dir, "account.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewACMEClient(acmeServer.URL+"/directory", "admin@example.com", accountKey)
+	client.pollInterval = time.Millisecond
+
+	domains := []string{"chainid.example.com", "www.example.com"}
+	manager := NewManager(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"))
+	_, err = manager.BootstrapSelfSigned(domains)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = manager.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager.EnableACME(client, domains)
+
+	challengeServer := httptest.NewServer(manager.HTTPHandler(http.NotFoundHandler()))
+	defer challengeServer.Close()
+	acmeServer.challengeServer = challengeServer.URL
+
+	if !manager.needsRenewal() {
+		t.Fatal("expected the self-signed certificate to be renewed")
+	}
+
+	err = manager.renew()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certificate := manager.Certificate()
+	if certificate.Issuer.CommonName != "Test ACME CA" || !reflect.DeepEqual(certificate.DNSNames, domains) {
+		t.Fatalf("expected the certificate to be issued by the ACME server, got %+v", certificate)
+	}
+	if manager.needsRenewal() {
+		t.Error("expected the certificate issued by the ACME server not to be renewed")
+	}
+
+	// The account key is kept and the certificate issued by the ACME server is loaded from the files.
+	reloadedKey, err := LoadACMEAccountKey(filepath.Join(dir, "account.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloadedKey.D.Cmp(accountKey.D) != 0 {
+		t.Error("expected the account key to be read from its file")
+	}
+
+	reloaded := NewManager(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"))
+	err = reloaded.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Certificate().SerialNumber.Cmp(certificate.SerialNumber) != 0 {
+		t.Error("expected the certificate issued by the ACME server to be written to the files")
+	}
+}
+
+func TestACMEClientInvalidChallenge(t *testing.T) {
+	acmeServer := newTestACMEServer(t)
+	defer acmeServer.Close()
+
+	challengeServer := httptest.NewServer(http.NotFoundHandler())
+	defer challengeServer.Close()
+	acmeServer.challengeServer = challengeServer.URL
+
+	accountKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewACMEClient(acmeServer.URL+"/directory", "", accountKey)
+	client.pollInterval = time.Millisecond
+
+	_, _, err = client.ObtainCertificate([]string{"chainid.example.com"})
+	if problem, ok := err.(*acmeProblem); !ok || problem.Detail != "invalid key authorization" {
+		t.Errorf("expected the challenge error to be returned, got %v", err)
+	}
+}
@@ -0,0 +1,279 @@
+package certificate
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+)
+
+const (
+	// ErrCertificateNotLoaded is an error raised when a TLS handshake happens before a certificate is loaded.
+	ErrCertificateNotLoaded = chainid.Error("No SSL certificate loaded")
+)
+
+const (
+	watchInterval = 10 * time.Second
+	// renewBefore is the remaining validity under which an ACME certificate is renewed.
+	renewBefore          = 30 * 24 * time.Hour
+	renewalCheckInterval = 12 * time.Hour
+	renewalRetryInterval = time.Hour
+)
+
+// Manager provides the certificate of the TLS listener. The certificate is reloaded when its files are
+// modified or when the process receives SIGHUP, the previous certificate is kept when the files are invalid.
+// When ACME is enabled, the certificate is obtained from an ACME server and written to the same files.
+type Manager struct {
+	certPath string
+	keyPath  string
+	logger   *log.Logger
+	acme     *ACMEClient
+	domains  []string
+
+	mu          sync.RWMutex
+	certificate *tls.Certificate
+	modTime     time.Time
+}
+
+// NewManager initializes a new manager, the certificate is read by Load.
+func NewManager(certPath, keyPath string) *Manager {
+	return &Manager{
+		certPath: certPath,
+		keyPath:  keyPath,
+		logger:   log.New(os.Stderr, "", log.LstdFlags),
+	}
+}
+
+// Load reads the certificate and its key from their files.
+func (manager *Manager) Load() error {
+	modTime, err := manager.filesModTime()
+	if err != nil {
+		return err
+	}
+
+	certificate, err := tls.LoadX509KeyPair(manager.certPath, manager.keyPath)
+	if err != nil {
+		return err
+	}
+
+	certificate.Leaf, err = x509.ParseCertificate(certificate.Certificate[0])
+	if err != nil {
+		return err
+	}
+
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+
+	manager.certificate = &certificate
+	manager.modTime = modTime
+	return nil
+}
+
+// GetCertificate returns the loaded certificate, it is used as the GetCertificate function of a tls.Config.
+func (manager *Manager) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	manager.mu.RLock()
+	defer manager.mu.RUnlock()
+
+	if manager.certificate == nil {
+		return nil, ErrCertificateNotLoaded
+	}
+	return manager.certificate, nil
+}
+
+// Certificate returns the loaded certificate, or nil when no certificate is loaded.
+func (manager *Manager) Certificate() *x509.Certificate {
+	manager.mu.RLock()
+	defer manager.mu.RUnlock()
+
+	if manager.certificate == nil {
+		return nil
+	}
+	return manager.certificate.Leaf
+}
+
+// BootstrapSelfSigned writes a self-signed certificate valid for the specified hosts and its key when
+// the certificate file does not exist. It returns true when the certificate has been generated.
+func (manager *Manager) BootstrapSelfSigned(hosts []string) (bool, error) {
+	_, err := os.Stat(manager.certPath)
+	if err == nil || !os.IsNotExist(err) {
+		return false, err
+	}
+
+	certPEM, keyPEM, err := generateSelfSigned(hosts)
+	if err != nil {
+		return false, err
+	}
+	return true, manager.writeFiles(certPEM, keyPEM)
+}
+
+// Watch starts to reload the certificate when its files are modified or when the process receives SIGHUP.
+func (manager *Manager) Watch() {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	ticker := time.NewTicker(watchInterval)
+
+	go func() {
+		for {
+			select {
+			case <-hangup:
+				manager.reload(true)
+			case <-ticker.C:
+				manager.reload(false)
+			}
+		}
+	}()
+}
+
+// reload loads the certificate again when its files have been modified since the last load, or when force is set.
+func (manager *Manager) reload(force bool) {
+	if !force {
+		modTime, err := manager.filesModTime()
+		if err != nil {
+			return
+		}
+
+		manager.mu.RLock()
+		modified := modTime.After(manager.modTime)
+		manager.mu.RUnlock()
+		if !modified {
+			return
+		}
+	}
+
+	err := manager.Load()
+	if err != nil {
+		manager.logger.Printf("Unable to reload the SSL certificate, the previous certificate is kept: %s", err)
+		return
+	}
+
+	certificate := manager.Certificate()
+	manager.logger.Printf("SSL certificate reloaded. [subject: %s] [expiration: %s]", certificate.Subject, certificate.NotAfter.Format(time.RFC3339))
+}
+
+// EnableACME configures the manager to obtain the certificate of the domains from an ACME server.
+// The HTTP-01 challenges are answered by the handler returned by HTTPHandler.
+func (manager *Manager) EnableACME(client *ACMEClient, domains []string) {
+	manager.acme = client
+	manager.domains = domains
+}
+
+// StartRenewal starts to obtain the certificate from the ACME server, then to renew it before it expires.
+// It must be called once the handler returned by HTTPHandler is served. It does nothing when ACME is not enabled.
+func (manager *Manager) StartRenewal() {
+	if manager.acme == nil {
+		return
+	}
+
+	go func() {
+		for {
+			delay := renewalCheckInterval
+			err := manager.renew()
+			if err != nil {
+				manager.logger.Printf("Unable to obtain the SSL certificate from the ACME server: %s", err)
+				delay = renewalRetryInterval
+			}
+			time.Sleep(delay)
+		}
+	}()
+}
+
+// HTTPHandler returns a handler answering the ACME HTTP-01 challenges and passing the other requests to next.
+func (manager *Manager) HTTPHandler(next http.Handler) http.Handler {
+	if manager.acme == nil {
+		return next
+	}
+	return manager.acme.HTTPHandler(next)
+}
+
+// renew obtains a new certificate when the loaded certificate is self-signed, does not cover every
+// domain or expires soon.
+func (manager *Manager) renew() error {
+	if !manager.needsRenewal() {
+		return nil
+	}
+
+	certPEM, keyPEM, err := manager.acme.ObtainCertificate(manager.domains)
+	if err != nil {
+		return err
+	}
+
+	err = manager.writeFiles(certPEM, keyPEM)
+	if err != nil {
+		return err
+	}
+
+	err = manager.Load()
+	if err != nil {
+		return err
+	}
+
+	manager.logger.Printf("SSL certificate obtained from the ACME server. [domains: %v] [expiration: %s]", manager.domains, manager.Certificate().NotAfter.Format(time.RFC3339))
+	return nil
+}
+
+func (manager *Manager) needsRenewal() bool {
+	certificate := manager.Certificate()
+	if certificate == nil || bytes.Equal(certificate.RawIssuer, certificate.RawSubject) {
+		return true
+	}
+
+	if time.Until(certificate.NotAfter) < renewBefore {
+		return true
+	}
+
+	for _, domain := range manager.domains {
+		if certificate.VerifyHostname(domain) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// filesModTime returns the most recent modification time of the certificate and key files.
+func (manager *Manager) filesModTime() (time.Time, error) {
+	var modTime time.Time
+	for _, path := range []string{manager.certPath, manager.keyPath} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return modTime, err
+		}
+		if info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+	}
+	return modTime, nil
+}
+
+// writeFiles replaces the certificate and key files, the key is written first so that a reload
+// triggered by the modification of the certificate file reads the matching key.
+func (manager *Manager) writeFiles(certPEM, keyPEM []byte) error {
+	err := writeFileAtomically(manager.keyPath, keyPEM, 0600)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(manager.certPath, certPEM, 0644)
+}
+
+func writeFileAtomically(path string, data []byte, perm os.FileMode) error {
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	err = ioutil.WriteFile(tmpPath, data, perm)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
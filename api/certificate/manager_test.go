@@ -0,0 +1,78 @@
+package certificate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestManagerReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "certificate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	manager := NewManager(filepath.Join(dir, "tls", "cert.pem"), filepath.Join(dir, "tls", "key.pem"))
+	_, err = manager.GetCertificate(nil)
+	if err != ErrCertificateNotLoaded {
+		t.Errorf("expected %v before the certificate is loaded, got %v", ErrCertificateNotLoaded, err)
+	}
+
+	generated, err := manager.BootstrapSelfSigned([]string{"localhost", "127.0.0.1"})
+	if err != nil || !generated {
+		t.Fatalf("expected the self-signed certificate to be generated, got %v", err)
+	}
+	generated, err = manager.BootstrapSelfSigned([]string{"localhost"})
+	if err != nil || generated {
+		t.Fatalf("expected the existing certificate to be kept, got %v", err)
+	}
+
+	err = manager.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate := manager.Certificate()
+	if certificate.VerifyHostname("localhost") != nil || certificate.VerifyHostname("127.0.0.1") != nil {
+		t.Fatalf("unexpected self-signed certificate %+v", certificate)
+	}
+
+	// The certificate is only reloaded once its files are modified.
+	certPEM, keyPEM, err := generateSelfSigned([]string{"chainid.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = manager.writeFiles(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setModTime(t, manager, time.Now().Add(time.Minute))
+
+	manager.reload(false)
+	if manager.Certificate().VerifyHostname("chainid.example.com") != nil {
+		t.Fatal("expected the modified certificate to be reloaded")
+	}
+
+	// An invalid certificate is not loaded.
+	err = ioutil.WriteFile(manager.certPath, []byte("invalid"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setModTime(t, manager, time.Now().Add(2*time.Minute))
+
+	manager.reload(true)
+	if manager.Certificate().VerifyHostname("chainid.example.com") != nil {
+		t.Error("expected the previous certificate to be kept")
+	}
+}
+
+func setModTime(t *testing.T, manager *Manager, modTime time.Time) {
+	for _, path := range []string{manager.certPath, manager.keyPath} {
+		err := os.Chtimes(path, modTime, modTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
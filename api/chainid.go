@@ -1,545 +1,1133 @@
-package chainid
-
-import "io"
-
-type (
-	// Pair defines a key/value string pair
-	Pair struct {
-		Name  string `json:"name"`
-		Value string `json:"value"`
-	}
-
-	// CLIFlags represents the available flags on the CLI.
-	CLIFlags struct {
-		Addr              *string
-		AdminPassword     *string
-		AdminPasswordFile *string
-		Assets            *string
-		Data              *string
-		EndpointURL       *string
-		ExternalEndpoints *string
-		Labels            *[]Pair
-		Logo              *string
-		NoAuth            *bool
-		NoAnalytics       *bool
-		Templates         *string
-		TLS               *bool
-		TLSSkipVerify     *bool
-		TLSCacert         *string
-		TLSCert           *string
-		TLSKey            *string
-		SSL               *bool
-		SSLCert           *string
-		SSLKey            *string
-		SyncInterval      *string
-	}
-
-	// Status represents the application status.
-	Status struct {
-		Authentication     bool   `json:"Authentication"`
-		EndpointManagement bool   `json:"EndpointManagement"`
-		Analytics          bool   `json:"Analytics"`
-		Version            string `json:"Version"`
-	}
-
-	// LDAPSettings represents the settings used to connect to a LDAP server.
-	LDAPSettings struct {
-		ReaderDN       string               `json:"ReaderDN"`
-		Password       string               `json:"Password"`
-		URL            string               `json:"URL"`
-		TLSConfig      TLSConfiguration     `json:"TLSConfig"`
-		StartTLS       bool                 `json:"StartTLS"`
-		SearchSettings []LDAPSearchSettings `json:"SearchSettings"`
-	}
-
-	// TLSConfiguration represents a TLS configuration.
-	TLSConfiguration struct {
-		TLS           bool   `json:"TLS"`
-		TLSSkipVerify bool   `json:"TLSSkipVerify"`
-		TLSCACertPath string `json:"TLSCACert,omitempty"`
-		TLSCertPath   string `json:"TLSCert,omitempty"`
-		TLSKeyPath    string `json:"TLSKey,omitempty"`
-	}
-
-	// LDAPSearchSettings represents settings used to search for users in a LDAP server.
-	LDAPSearchSettings struct {
-		BaseDN            string `json:"BaseDN"`
-		Filter            string `json:"Filter"`
-		UserNameAttribute string `json:"UserNameAttribute"`
-	}
-
-	// Settings represents the application settings.
-	Settings struct {
-		TemplatesURL                       string               `json:"TemplatesURL"`
-		LogoURL                            string               `json:"LogoURL"`
-		BlackListedLabels                  []Pair               `json:"BlackListedLabels"`
-		DisplayExternalContributors        bool                 `json:"DisplayExternalContributors"`
-		AuthenticationMethod               AuthenticationMethod `json:"AuthenticationMethod"`
-		LDAPSettings                       LDAPSettings         `json:"LDAPSettings"`
-		AllowBindMountsForRegularUsers     bool                 `json:"AllowBindMountsForRegularUsers"`
-		AllowPrivilegedModeForRegularUsers bool                 `json:"AllowPrivilegedModeForRegularUsers"`
-		// Deprecated fields
-		DisplayDonationHeader bool
-	}
-
-	// User represents a user account.
-	User struct {
-		ID       UserID   `json:"Id"`
-		Username string   `json:"Username"`
-		Password string   `json:"Password,omitempty"`
-		Role     UserRole `json:"Role"`
-	}
-
-	// UserID represents a user identifier
-	UserID int
-
-	// UserRole represents the role of a user. It can be either an administrator
-	// or a regular user
-	UserRole int
-
-	// AuthenticationMethod represents the authentication method used to authenticate a user.
-	AuthenticationMethod int
-
-	// Team represents a list of user accounts.
-	Team struct {
-		ID   TeamID `json:"Id"`
-		Name string `json:"Name"`
-	}
-
-	// TeamID represents a team identifier
-	TeamID int
-
-	// TeamMembership represents a membership association between a user and a team
-	TeamMembership struct {
-		ID     TeamMembershipID `json:"Id"`
-		UserID UserID           `json:"UserID"`
-		TeamID TeamID           `json:"TeamID"`
-		Role   MembershipRole   `json:"Role"`
-	}
-
-	// TeamMembershipID represents a team membership identifier
-	TeamMembershipID int
-
-	// MembershipRole represents the role of a user within a team
-	MembershipRole int
-
-	// TokenData represents the data embedded in a JWT token.
-	TokenData struct {
-		ID       UserID
-		Username string
-		Role     UserRole
-	}
-
-	// StackID represents a stack identifier (it must be composed of Name + "_" + SwarmID to create a unique identifier).
-	StackID string
-
-	// Stack represents a Docker stack created via docker stack deploy.
-	Stack struct {
-		ID          StackID `json:"Id"`
-		Name        string  `json:"Name"`
-		EntryPoint  string  `json:"EntryPoint"`
-		SwarmID     string  `json:"SwarmId"`
-		ProjectPath string
-		Env         []Pair `json:"Env"`
-	}
-
-	// RegistryID represents a registry identifier.
-	RegistryID int
-
-	// Registry represents a Docker registry with all the info required
-	// to connect to it.
-	Registry struct {
-		ID              RegistryID `json:"Id"`
-		Name            string     `json:"Name"`
-		URL             string     `json:"URL"`
-		Authentication  bool       `json:"Authentication"`
-		Username        string     `json:"Username"`
-		Password        string     `json:"Password,omitempty"`
-		AuthorizedUsers []UserID   `json:"AuthorizedUsers"`
-		AuthorizedTeams []TeamID   `json:"AuthorizedTeams"`
-	}
-
-	// DockerHub represents all the required information to connect and use the
-	// Docker Hub.
-	DockerHub struct {
-		Authentication bool   `json:"Authentication"`
-		Username       string `json:"Username"`
-		Password       string `json:"Password,omitempty"`
-	}
-
-	// EndpointID represents an endpoint identifier.
-	EndpointID int
-
-	// EndpointType represents the type of an endpoint.
-	EndpointType int
-
-	// Endpoint represents a Docker endpoint with all the info required
-	// to connect to it.
-	Endpoint struct {
-		ID               EndpointID          `json:"Id"`
-		Name             string              `json:"Name"`
-		Type             EndpointType        `json:"Type"`
-		URL              string              `json:"URL"`
-		GroupID          EndpointGroupID     `json:"GroupId"`
-		PublicURL        string              `json:"PublicURL"`
-		TLSConfig        TLSConfiguration    `json:"TLSConfig"`
-		AuthorizedUsers  []UserID            `json:"AuthorizedUsers"`
-		AuthorizedTeams  []TeamID            `json:"AuthorizedTeams"`
-		Extensions       []EndpointExtension `json:"Extensions"`
-		AzureCredentials AzureCredentials    `json:"AzureCredentials,omitempty"`
-
-		// Deprecated fields
-		// Deprecated in DBVersion == 4
-		TLS           bool   `json:"TLS,omitempty"`
-		TLSCACertPath string `json:"TLSCACert,omitempty"`
-		TLSCertPath   string `json:"TLSCert,omitempty"`
-		TLSKeyPath    string `json:"TLSKey,omitempty"`
-	}
-
-	// AzureCredentials represents the credentials used to connect to an Azure
-	// environment.
-	AzureCredentials struct {
-		ApplicationID     string `json:"ApplicationID"`
-		TenantID          string `json:"TenantID"`
-		AuthenticationKey string `json:"AuthenticationKey"`
-	}
-
-	// EndpointGroupID represents an endpoint group identifier.
-	EndpointGroupID int
-
-	// EndpointGroup represents a group of endpoints.
-	EndpointGroup struct {
-		ID              EndpointGroupID `json:"Id"`
-		Name            string          `json:"Name"`
-		Description     string          `json:"Description"`
-		AuthorizedUsers []UserID        `json:"AuthorizedUsers"`
-		AuthorizedTeams []TeamID        `json:"AuthorizedTeams"`
-		Labels          []Pair          `json:"Labels"`
-	}
-
-	// EndpointExtension represents a extension associated to an endpoint.
-	EndpointExtension struct {
-		Type EndpointExtensionType `json:"Type"`
-		URL  string                `json:"URL"`
-	}
-
-	// EndpointExtensionType represents the type of an endpoint extension. Only
-	// one extension of each type can be associated to an endpoint.
-	EndpointExtensionType int
-
-	// ResourceControlID represents a resource control identifier.
-	ResourceControlID int
-
-	// ResourceControl represent a reference to a Docker resource with specific access controls
-	ResourceControl struct {
-		ID                 ResourceControlID    `json:"Id"`
-		ResourceID         string               `json:"ResourceId"`
-		SubResourceIDs     []string             `json:"SubResourceIds"`
-		Type               ResourceControlType  `json:"Type"`
-		AdministratorsOnly bool                 `json:"AdministratorsOnly"`
-		UserAccesses       []UserResourceAccess `json:"UserAccesses"`
-		TeamAccesses       []TeamResourceAccess `json:"TeamAccesses"`
-
-		// Deprecated fields
-		// Deprecated in DBVersion == 2
-		OwnerID     UserID              `json:"OwnerId,omitempty"`
-		AccessLevel ResourceAccessLevel `json:"AccessLevel,omitempty"`
-	}
-
-	// ResourceControlType represents the type of resource associated to the resource control (volume, container, service...).
-	ResourceControlType int
-
-	// UserResourceAccess represents the level of control on a resource for a specific user.
-	UserResourceAccess struct {
-		UserID      UserID              `json:"UserId"`
-		AccessLevel ResourceAccessLevel `json:"AccessLevel"`
-	}
-
-	// TeamResourceAccess represents the level of control on a resource for a specific team.
-	TeamResourceAccess struct {
-		TeamID      TeamID              `json:"TeamId"`
-		AccessLevel ResourceAccessLevel `json:"AccessLevel"`
-	}
-
-	// ResourceAccessLevel represents the level of control associated to a resource.
-	ResourceAccessLevel int
-
-	// TLSFileType represents a type of TLS file required to connect to a Docker endpoint.
-	// It can be either a TLS CA file, a TLS certificate file or a TLS key file.
-	TLSFileType int
-
-	// CLIService represents a service for managing CLI.
-	CLIService interface {
-		ParseFlags(version string) (*CLIFlags, error)
-		ValidateFlags(flags *CLIFlags) error
-	}
-
-	// DataStore defines the interface to manage the data.
-	DataStore interface {
-		Open() error
-		Init() error
-		Close() error
-		MigrateData() error
-	}
-
-	// Server defines the interface to serve the API.
-	Server interface {
-		Start() error
-	}
-
-	// UserService represents a service for managing user data.
-	UserService interface {
-		User(ID UserID) (*User, error)
-		UserByUsername(username string) (*User, error)
-		Users() ([]User, error)
-		UsersByRole(role UserRole) ([]User, error)
-		CreateUser(user *User) error
-		UpdateUser(ID UserID, user *User) error
-		DeleteUser(ID UserID) error
-	}
-
-	// TeamService represents a service for managing user data.
-	TeamService interface {
-		Team(ID TeamID) (*Team, error)
-		TeamByName(name string) (*Team, error)
-		Teams() ([]Team, error)
-		CreateTeam(team *Team) error
-		UpdateTeam(ID TeamID, team *Team) error
-		DeleteTeam(ID TeamID) error
-	}
-
-	// TeamMembershipService represents a service for managing team membership data.
-	TeamMembershipService interface {
-		TeamMembership(ID TeamMembershipID) (*TeamMembership, error)
-		TeamMemberships() ([]TeamMembership, error)
-		TeamMembershipsByUserID(userID UserID) ([]TeamMembership, error)
-		TeamMembershipsByTeamID(teamID TeamID) ([]TeamMembership, error)
-		CreateTeamMembership(membership *TeamMembership) error
-		UpdateTeamMembership(ID TeamMembershipID, membership *TeamMembership) error
-		DeleteTeamMembership(ID TeamMembershipID) error
-		DeleteTeamMembershipByUserID(userID UserID) error
-		DeleteTeamMembershipByTeamID(teamID TeamID) error
-	}
-
-	// EndpointService represents a service for managing endpoint data.
-	EndpointService interface {
-		Endpoint(ID EndpointID) (*Endpoint, error)
-		Endpoints() ([]Endpoint, error)
-		CreateEndpoint(endpoint *Endpoint) error
-		UpdateEndpoint(ID EndpointID, endpoint *Endpoint) error
-		DeleteEndpoint(ID EndpointID) error
-		Synchronize(toCreate, toUpdate, toDelete []*Endpoint) error
-	}
-
-	// EndpointGroupService represents a service for managing endpoint group data.
-	EndpointGroupService interface {
-		EndpointGroup(ID EndpointGroupID) (*EndpointGroup, error)
-		EndpointGroups() ([]EndpointGroup, error)
-		CreateEndpointGroup(group *EndpointGroup) error
-		UpdateEndpointGroup(ID EndpointGroupID, group *EndpointGroup) error
-		DeleteEndpointGroup(ID EndpointGroupID) error
-	}
-
-	// RegistryService represents a service for managing registry data.
-	RegistryService interface {
-		Registry(ID RegistryID) (*Registry, error)
-		Registries() ([]Registry, error)
-		CreateRegistry(registry *Registry) error
-		UpdateRegistry(ID RegistryID, registry *Registry) error
-		DeleteRegistry(ID RegistryID) error
-	}
-
-	// StackService represents a service for managing stack data.
-	StackService interface {
-		Stack(ID StackID) (*Stack, error)
-		Stacks() ([]Stack, error)
-		StacksBySwarmID(ID string) ([]Stack, error)
-		CreateStack(stack *Stack) error
-		UpdateStack(ID StackID, stack *Stack) error
-		DeleteStack(ID StackID) error
-	}
-
-	// DockerHubService represents a service for managing the DockerHub object.
-	DockerHubService interface {
-		DockerHub() (*DockerHub, error)
-		StoreDockerHub(registry *DockerHub) error
-	}
-
-	// SettingsService represents a service for managing application settings.
-	SettingsService interface {
-		Settings() (*Settings, error)
-		StoreSettings(settings *Settings) error
-	}
-
-	// VersionService represents a service for managing version data.
-	VersionService interface {
-		DBVersion() (int, error)
-		StoreDBVersion(version int) error
-	}
-
-	// ResourceControlService represents a service for managing resource control data.
-	ResourceControlService interface {
-		ResourceControl(ID ResourceControlID) (*ResourceControl, error)
-		ResourceControlByResourceID(resourceID string) (*ResourceControl, error)
-		ResourceControls() ([]ResourceControl, error)
-		CreateResourceControl(rc *ResourceControl) error
-		UpdateResourceControl(ID ResourceControlID, resourceControl *ResourceControl) error
-		DeleteResourceControl(ID ResourceControlID) error
-	}
-
-	// CryptoService represents a service for encrypting/hashing data.
-	CryptoService interface {
-		Hash(data string) (string, error)
-		CompareHashAndData(hash string, data string) error
-	}
-
-	// DigitalSignatureService represents a service to manage digital signatures.
-	DigitalSignatureService interface {
-		ParseKeyPair(private, public []byte) error
-		GenerateKeyPair() ([]byte, []byte, error)
-		EncodedPublicKey() string
-		PEMHeaders() (string, string)
-		Sign(message string) (string, error)
-	}
-
-	// JWTService represents a service for managing JWT tokens.
-	JWTService interface {
-		GenerateToken(data *TokenData) (string, error)
-		ParseAndVerifyToken(token string) (*TokenData, error)
-	}
-
-	// FileService represents a service for managing files.
-	FileService interface {
-		GetFileContent(filePath string) (string, error)
-		RemoveDirectory(directoryPath string) error
-		StoreTLSFile(folder string, fileType TLSFileType, r io.Reader) error
-		GetPathForTLSFile(folder string, fileType TLSFileType) (string, error)
-		DeleteTLSFile(folder string, fileType TLSFileType) error
-		DeleteTLSFiles(folder string) error
-		GetStackProjectPath(stackIdentifier string) string
-		StoreStackFileFromString(stackIdentifier, fileName, stackFileContent string) (string, error)
-		StoreStackFileFromReader(stackIdentifier, fileName string, r io.Reader) (string, error)
-		KeyPairFilesExist() (bool, error)
-		StoreKeyPair(private, public []byte, privatePEMHeader, publicPEMHeader string) error
-		LoadKeyPair() ([]byte, []byte, error)
-		WriteJSONToFile(path string, content interface{}) error
-	}
-
-	// GitService represents a service for managing Git.
-	GitService interface {
-		ClonePublicRepository(repositoryURL, destination string) error
-		ClonePrivateRepositoryWithBasicAuth(repositoryURL, destination, username, password string) error
-	}
-
-	// EndpointWatcher represents a service to synchronize the endpoints via an external source.
-	EndpointWatcher interface {
-		WatchEndpointFile(endpointFilePath string) error
-	}
-
-	// LDAPService represents a service used to authenticate users against a LDAP/AD.
-	LDAPService interface {
-		AuthenticateUser(username, password string, settings *LDAPSettings) error
-		TestConnectivity(settings *LDAPSettings) error
-	}
-
-	// StackManager represents a service to manage stacks.
-	StackManager interface {
-		Login(dockerhub *DockerHub, registries []Registry, endpoint *Endpoint)
-		Logout(endpoint *Endpoint) error
-		Deploy(stack *Stack, prune bool, endpoint *Endpoint) error
-		Remove(stack *Stack, endpoint *Endpoint) error
-	}
-)
-
-const (
-	// APIVersion is the version number of the Chain Platform API.
-	APIVersion = "1.17.1-dev"
-	// DBVersion is the version number of the Chain Platform database.
-	DBVersion = 11
-	// DefaultTemplatesURL represents the default URL for the templates definitions.
-	DefaultTemplatesURL = "https://raw.githubusercontent.com/chainid/templates/master/templates.json"
-	// PortainerAgentHeader represents the name of the header available in any agent response
-	PortainerAgentHeader = "Chain Platform-Agent"
-	// PortainerAgentTargetHeader represent the name of the header containing the target node name.
-	PortainerAgentTargetHeader = "X-PortainerAgent-Target"
-	// PortainerAgentSignatureHeader represent the name of the header containing the digital signature
-	PortainerAgentSignatureHeader = "X-PortainerAgent-Signature"
-	// PortainerAgentPublicKeyHeader represent the name of the header containing the public key
-	PortainerAgentPublicKeyHeader = "X-PortainerAgent-PublicKey"
-	// PortainerAgentSignatureMessage represents the message used to create a digital signature
-	// to be used when communicating with an agent
-	PortainerAgentSignatureMessage = "Chain Platform-App"
-)
-
-const (
-	// TLSFileCA represents a TLS CA certificate file.
-	TLSFileCA TLSFileType = iota
-	// TLSFileCert represents a TLS certificate file.
-	TLSFileCert
-	// TLSFileKey represents a TLS key file.
-	TLSFileKey
-)
-
-const (
-	_ MembershipRole = iota
-	// TeamLeader represents a leader role inside a team
-	TeamLeader
-	// TeamMember represents a member role inside a team
-	TeamMember
-)
-
-const (
-	_ UserRole = iota
-	// AdministratorRole represents an administrator user role
-	AdministratorRole
-	// StandardUserRole represents a regular user role
-	StandardUserRole
-)
-
-const (
-	_ AuthenticationMethod = iota
-	// AuthenticationInternal represents the internal authentication method (authentication against Chain Platform API)
-	AuthenticationInternal
-	// AuthenticationLDAP represents the LDAP authentication method (authentication against a LDAP server)
-	AuthenticationLDAP
-)
-
-const (
-	_ ResourceAccessLevel = iota
-	// ReadWriteAccessLevel represents an access level with read-write permissions on a resource
-	ReadWriteAccessLevel
-)
-
-const (
-	_ ResourceControlType = iota
-	// ContainerResourceControl represents a resource control associated to a Docker container
-	ContainerResourceControl
-	// ServiceResourceControl represents a resource control associated to a Docker service
-	ServiceResourceControl
-	// VolumeResourceControl represents a resource control associated to a Docker volume
-	VolumeResourceControl
-	// NetworkResourceControl represents a resource control associated to a Docker network
-	NetworkResourceControl
-	// SecretResourceControl represents a resource control associated to a Docker secret
-	SecretResourceControl
-	// StackResourceControl represents a resource control associated to a stack composed of Docker services
-	StackResourceControl
-	// ConfigResourceControl represents a resource control associated to a Docker config
-	ConfigResourceControl
-)
-
-const (
-	_ EndpointExtensionType = iota
-	// StoridgeEndpointExtension represents the Storidge extension
-	StoridgeEndpointExtension
-)
-
-const (
-	_ EndpointType = iota
-	// DockerEnvironment represents an endpoint connected to a Docker environment
-	DockerEnvironment
-	// AgentOnDockerEnvironment represents an endpoint connected to a Chain Platform agent deployed on a Docker environment
-	AgentOnDockerEnvironment
-	// AzureEnvironment represents an endpoint connected to an Azure environment
-	AzureEnvironment
-)
+package chainid
+
+import (
+	"context"
+	"io"
+)
+
+type (
+	// Pair defines a key/value string pair
+	Pair struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+
+	// CLIFlags represents the available flags on the CLI.
+	CLIFlags struct {
+		Addr                  *string
+		AdminPassword         *string
+		AdminPasswordFile     *string
+		Assets                *string
+		Data                  *string
+		EndpointURL           *string
+		ExternalEndpoints     *string
+		ExternalEndpointsMode *string
+		Discovery             *[]string
+		DiscoveryToken        *string
+		Labels                *[]Pair
+		Logo                  *string
+		NoAuth                *bool
+		NoAnalytics           *bool
+		Templates             *string
+		TLS                   *bool
+		TLSSkipVerify         *bool
+		TLSCacert             *string
+		TLSCert               *string
+		TLSKey                *string
+		SSHKey                *string
+		SSHKnownHosts         *string
+		SSHSkipVerify         *bool
+		SSL                   *bool
+		SSLCert               *string
+		SSLKey                *string
+		SSLSelfSigned         *bool
+		SSLRedirectAddr       *string
+		HSTSMaxAge            *string
+		ACMEDomains           *[]string
+		ACMEEmail             *string
+		ACMEDirectory         *string
+		SyncInterval          *string
+		HealthCheckInterval   *string
+		SnapshotInterval      *string
+		TrustedProxies        *[]string
+		ConfigFile            *string
+		MasterKey             *string
+		MasterKeyFile         *string
+		PreviousMasterKeyFile *string
+		RotateDataKey         *bool
+		RollbackMigration     *bool
+		Datastore             *string
+		DatastoreEndpoint     *string
+		DatastorePrefix       *string
+		DatastoreToken        *string
+		DB                    *DBFlags
+	}
+
+	// DBFlags represents the flags of the db subcommands, used to inspect and repair
+	// the database of a stopped instance. Command is empty when the server is started.
+	DBFlags struct {
+		Command  string
+		JSON     *bool
+		Bucket   *string
+		Version  *int
+		DryRun   *bool
+		Username *string
+		Password *string
+	}
+
+	// ClusterMember represents an instance of the application sharing the datastore with other instances.
+	ClusterMember struct {
+		ID       string `json:"ID"`
+		Address  string `json:"Address"`
+		LastSeen int64  `json:"LastSeen"`
+	}
+
+	// ClusterStatus represents the members of the cluster and the member running the scheduled jobs.
+	ClusterStatus struct {
+		MemberID string          `json:"MemberID"`
+		Leader   string          `json:"Leader"`
+		Members  []ClusterMember `json:"Members"`
+	}
+
+	// Status represents the application status.
+	Status struct {
+		Authentication     bool   `json:"Authentication"`
+		EndpointManagement bool   `json:"EndpointManagement"`
+		Analytics          bool   `json:"Analytics"`
+		Version            string `json:"Version"`
+	}
+
+	// LDAPSettings represents the settings used to connect to a LDAP server.
+	LDAPSettings struct {
+		ReaderDN       string               `json:"ReaderDN"`
+		Password       string               `json:"Password"`
+		URL            string               `json:"URL"`
+		TLSConfig      TLSConfiguration     `json:"TLSConfig"`
+		StartTLS       bool                 `json:"StartTLS"`
+		SearchSettings []LDAPSearchSettings `json:"SearchSettings"`
+	}
+
+	// TLSConfiguration represents a TLS configuration.
+	TLSConfiguration struct {
+		TLS           bool   `json:"TLS"`
+		TLSSkipVerify bool   `json:"TLSSkipVerify"`
+		TLSCACertPath string `json:"TLSCACert,omitempty"`
+		TLSCertPath   string `json:"TLSCert,omitempty"`
+		TLSKeyPath    string `json:"TLSKey,omitempty"`
+	}
+
+	// DiscoveredEndpoint represents a Docker host found by an endpoint discovery provider.
+	// Group is the name of the endpoint group of the endpoint, the default group is used when it is empty.
+	DiscoveredEndpoint struct {
+		Name      string
+		URL       string
+		PublicURL string
+		Group     string
+		Agent     bool
+		TLSConfig TLSConfiguration
+	}
+
+	// SSHConfiguration represents the configuration used to reach the Docker socket of an endpoint
+	// over SSH. The host keys listed in the known hosts file are the only ones accepted.
+	SSHConfiguration struct {
+		SSHSkipVerify     bool   `json:"SSHSkipVerify"`
+		SSHKeyPath        string `json:"SSHKey,omitempty"`
+		SSHKnownHostsPath string `json:"SSHKnownHosts,omitempty"`
+	}
+
+	// LDAPSearchSettings represents settings used to search for users in a LDAP server.
+	LDAPSearchSettings struct {
+		BaseDN            string `json:"BaseDN"`
+		Filter            string `json:"Filter"`
+		UserNameAttribute string `json:"UserNameAttribute"`
+	}
+
+	// LockoutSettings represents the settings used to lock user accounts after
+	// too many failed login attempts.
+	LockoutSettings struct {
+		// MaxFailedAttempts is the number of consecutive failed login attempts
+		// after which an account is locked. 0 disables account lockout.
+		MaxFailedAttempts int `json:"MaxFailedAttempts"`
+		// LockoutDuration is the duration of a lockout in minutes.
+		// 0 keeps the account locked until it is unlocked by an administrator.
+		LockoutDuration int `json:"LockoutDuration"`
+	}
+
+	// PasswordPolicy represents the rules applied to the passwords of internal user accounts.
+	PasswordPolicy struct {
+		MinLength               int  `json:"MinLength"`
+		RequireUppercase        bool `json:"RequireUppercase"`
+		RequireLowercase        bool `json:"RequireLowercase"`
+		RequireDigit            bool `json:"RequireDigit"`
+		RequireSpecialCharacter bool `json:"RequireSpecialCharacter"`
+		// HistorySize is the number of previous passwords that cannot be reused.
+		HistorySize int `json:"HistorySize"`
+		// MaxAge is the number of days after which a password expires. 0 disables password expiry.
+		MaxAge int `json:"MaxAge"`
+	}
+
+	// BackupTargetType represents the type of location where scheduled backups are stored.
+	BackupTargetType int
+
+	// BackupSettings represents the settings of the scheduled backups.
+	BackupSettings struct {
+		Enabled bool `json:"Enabled"`
+		// Schedule is a cron expression (e.g. "0 2 * * *" or "@daily").
+		Schedule string `json:"Schedule"`
+		// Retention is the number of archives kept on the target. 0 keeps every archive.
+		Retention int `json:"Retention"`
+		// Password is used to encrypt the archives when it is not empty.
+		Password   string           `json:"Password,omitempty"`
+		TargetType BackupTargetType `json:"TargetType"`
+		LocalPath  string           `json:"LocalPath"`
+		S3         S3Settings       `json:"S3"`
+	}
+
+	// S3Settings represents the settings used to store backups in an S3-compatible bucket.
+	S3Settings struct {
+		Endpoint        string `json:"Endpoint"`
+		Region          string `json:"Region"`
+		Bucket          string `json:"Bucket"`
+		Prefix          string `json:"Prefix"`
+		AccessKeyID     string `json:"AccessKeyID"`
+		SecretAccessKey string `json:"SecretAccessKey,omitempty"`
+	}
+
+	// BackupStatus represents the outcome of the scheduled backups.
+	BackupStatus struct {
+		LastRunAt     int64  `json:"LastRunAt"`
+		LastSuccessAt int64  `json:"LastSuccessAt"`
+		LastArchive   string `json:"LastArchive"`
+		LastError     string `json:"LastError"`
+	}
+
+	// ConfigChange represents a modification made, or planned in dry-run mode, when
+	// applying a configuration document.
+	ConfigChange struct {
+		Kind   string             `json:"Kind"`
+		Name   string             `json:"Name"`
+		Action ConfigChangeAction `json:"Action"`
+	}
+
+	// ConfigChangeAction represents the type of modification made on a resource when applying
+	// a configuration document.
+	ConfigChangeAction string
+
+	// Settings represents the application settings.
+	Settings struct {
+		TemplatesURL                       string               `json:"TemplatesURL"`
+		LogoURL                            string               `json:"LogoURL"`
+		BlackListedLabels                  []Pair               `json:"BlackListedLabels"`
+		DisplayExternalContributors        bool                 `json:"DisplayExternalContributors"`
+		AuthenticationMethod               AuthenticationMethod `json:"AuthenticationMethod"`
+		LDAPSettings                       LDAPSettings         `json:"LDAPSettings"`
+		AllowBindMountsForRegularUsers     bool                 `json:"AllowBindMountsForRegularUsers"`
+		AllowPrivilegedModeForRegularUsers bool                 `json:"AllowPrivilegedModeForRegularUsers"`
+		EnforceTwoFactorAuthentication     bool                 `json:"EnforceTwoFactorAuthentication"`
+		LockoutSettings                    LockoutSettings      `json:"LockoutSettings"`
+		PasswordPolicy                     PasswordPolicy       `json:"PasswordPolicy"`
+		BackupSettings                     BackupSettings       `json:"BackupSettings"`
+		// Deprecated fields
+		DisplayDonationHeader bool
+	}
+
+	// User represents a user account.
+	User struct {
+		ID                 UserID   `json:"Id"`
+		Username           string   `json:"Username"`
+		Password           string   `json:"Password,omitempty"`
+		Role               UserRole `json:"Role"`
+		TOTPSecret         string   `json:"TOTPSecret,omitempty"`
+		TOTPEnabled        bool     `json:"TOTPEnabled"`
+		TOTPRecoveryCodes  []string `json:"TOTPRecoveryCodes,omitempty"`
+		TOTPLastTimeStep   int64    `json:"TOTPLastTimeStep,omitempty"`
+		FailedLogins       int      `json:"FailedLogins"`
+		LastFailedLogin    int64    `json:"LastFailedLogin,omitempty"`
+		Locked             bool     `json:"Locked"`
+		LockedAt           int64    `json:"LockedAt,omitempty"`
+		PasswordHistory    []string `json:"PasswordHistory,omitempty"`
+		PasswordChangedAt  int64    `json:"PasswordChangedAt,omitempty"`
+		MustChangePassword bool     `json:"MustChangePassword"`
+	}
+
+	// UserID represents a user identifier
+	UserID int
+
+	// UserRole represents the role of a user. It can be either an administrator
+	// or a regular user
+	UserRole int
+
+	// AuthenticationMethod represents the authentication method used to authenticate a user.
+	AuthenticationMethod int
+
+	// Team represents a list of user accounts.
+	Team struct {
+		ID   TeamID `json:"Id"`
+		Name string `json:"Name"`
+	}
+
+	// TeamID represents a team identifier
+	TeamID int
+
+	// TeamMembership represents a membership association between a user and a team
+	TeamMembership struct {
+		ID     TeamMembershipID `json:"Id"`
+		UserID UserID           `json:"UserID"`
+		TeamID TeamID           `json:"TeamID"`
+		Role   MembershipRole   `json:"Role"`
+	}
+
+	// TeamMembershipID represents a team membership identifier
+	TeamMembershipID int
+
+	// MembershipRole represents the role of a user within a team
+	MembershipRole int
+
+	// RoleID represents a role identifier.
+	RoleID int
+
+	// Permission represents a single operation that can be granted to a user through a role.
+	Permission string
+
+	// Role represents a named set of permissions.
+	Role struct {
+		ID          RoleID       `json:"Id"`
+		Name        string       `json:"Name"`
+		Description string       `json:"Description"`
+		Permissions []Permission `json:"Permissions"`
+		BuiltIn     bool         `json:"BuiltIn"`
+	}
+
+	// RoleAssignmentID represents a role assignment identifier.
+	RoleAssignmentID int
+
+	// RoleAssignment represents the association of a role to a user or a team.
+	// When EndpointGroupID is set, the role only applies to the endpoints of that group,
+	// otherwise it applies globally.
+	RoleAssignment struct {
+		ID              RoleAssignmentID `json:"Id"`
+		RoleID          RoleID           `json:"RoleId"`
+		UserID          UserID           `json:"UserId,omitempty"`
+		TeamID          TeamID           `json:"TeamId,omitempty"`
+		EndpointGroupID EndpointGroupID  `json:"EndpointGroupId,omitempty"`
+	}
+
+	// AuditLogEntryID represents an audit log entry identifier.
+	AuditLogEntryID int
+
+	// AuditLogEntry represents a mutating operation performed through the API or the Docker proxy.
+	AuditLogEntry struct {
+		ID         AuditLogEntryID `json:"Id"`
+		Timestamp  int64           `json:"Timestamp"`
+		UserID     UserID          `json:"UserId"`
+		Username   string          `json:"Username"`
+		EndpointID EndpointID      `json:"EndpointId,omitempty"`
+		Method     string          `json:"Method"`
+		Path       string          `json:"Path"`
+		ResourceID string          `json:"ResourceId,omitempty"`
+		StatusCode int             `json:"StatusCode"`
+		Success    bool            `json:"Success"`
+		ClientIP   string          `json:"ClientIP"`
+	}
+
+	// AuditLogFilter represents the criteria used to query the audit log.
+	// Zero values are ignored.
+	AuditLogFilter struct {
+		UserID     UserID
+		EndpointID EndpointID
+		Method     string
+		ResourceID string
+		From       int64
+		To         int64
+		Limit      int
+	}
+
+	// TokenData represents the data embedded in a JWT token.
+	TokenData struct {
+		ID       UserID
+		Username string
+		Role     UserRole
+	}
+
+	// StackID represents a stack identifier (it must be composed of Name + "_" + SwarmID to create a unique identifier).
+	StackID string
+
+	// Stack represents a Docker stack created via docker stack deploy.
+	Stack struct {
+		ID          StackID `json:"Id"`
+		Name        string  `json:"Name"`
+		EntryPoint  string  `json:"EntryPoint"`
+		SwarmID     string  `json:"SwarmId"`
+		ProjectPath string
+		Env         []Pair `json:"Env"`
+	}
+
+	// RegistryID represents a registry identifier.
+	RegistryID int
+
+	// Registry represents a Docker registry with all the info required
+	// to connect to it.
+	Registry struct {
+		ID              RegistryID `json:"Id"`
+		Name            string     `json:"Name"`
+		URL             string     `json:"URL"`
+		Authentication  bool       `json:"Authentication"`
+		Username        string     `json:"Username"`
+		Password        string     `json:"Password,omitempty"`
+		AuthorizedUsers []UserID   `json:"AuthorizedUsers"`
+		AuthorizedTeams []TeamID   `json:"AuthorizedTeams"`
+	}
+
+	// DockerHub represents all the required information to connect and use the
+	// Docker Hub.
+	DockerHub struct {
+		Authentication bool   `json:"Authentication"`
+		Username       string `json:"Username"`
+		Password       string `json:"Password,omitempty"`
+	}
+
+	// EndpointID represents an endpoint identifier.
+	EndpointID int
+
+	// EndpointType represents the type of an endpoint.
+	EndpointType int
+
+	// Endpoint represents a Docker endpoint with all the info required
+	// to connect to it.
+	Endpoint struct {
+		ID                    EndpointID                  `json:"Id"`
+		Name                  string                      `json:"Name"`
+		Type                  EndpointType                `json:"Type"`
+		URL                   string                      `json:"URL"`
+		GroupID               EndpointGroupID             `json:"GroupId"`
+		PublicURL             string                      `json:"PublicURL"`
+		TLSConfig             TLSConfiguration            `json:"TLSConfig"`
+		SSHConfig             SSHConfiguration            `json:"SSHConfig"`
+		TransportSettings     EndpointTransportSettings   `json:"TransportSettings"`
+		AuthorizedUsers       []UserID                    `json:"AuthorizedUsers"`
+		AuthorizedTeams       []TeamID                    `json:"AuthorizedTeams"`
+		Extensions            []EndpointExtension         `json:"Extensions"`
+		AzureCredentials      AzureCredentials            `json:"AzureCredentials,omitempty"`
+		KubernetesCredentials KubernetesCredentials       `json:"KubernetesCredentials,omitempty"`
+		KubernetesNamespaces  []KubernetesNamespaceAccess `json:"KubernetesNamespaces,omitempty"`
+		Status                EndpointStatus              `json:"Status"`
+		Latency               int64                       `json:"Latency"`
+		DockerVersion         string                      `json:"DockerVersion"`
+		StatusHistory         []EndpointStatusCheck       `json:"StatusHistory"`
+		Snapshots             []Snapshot                  `json:"Snapshots"`
+		EdgeJoinToken         string                      `json:"EdgeJoinToken,omitempty"`
+		// ExternallyManaged is set on the endpoints created by the synchronization of the external
+		// endpoints source or by a discovery provider, these endpoints cannot be updated or removed via the API.
+		ExternallyManaged bool `json:"ExternallyManaged"`
+		// DiscoverySource is the name of the discovery provider which created the endpoint.
+		DiscoverySource string `json:"DiscoverySource,omitempty"`
+
+		// Deprecated fields
+		// Deprecated in DBVersion == 4
+		TLS           bool   `json:"TLS,omitempty"`
+		TLSCACertPath string `json:"TLSCACert,omitempty"`
+		TLSCertPath   string `json:"TLSCert,omitempty"`
+		TLSKeyPath    string `json:"TLSKey,omitempty"`
+	}
+
+	// EndpointStatus represents the status of an endpoint.
+	EndpointStatus int
+
+	// EndpointTransportSettings represents the settings of the connections opened to the Docker API
+	// of an endpoint. The durations are expressed in seconds and a zero value selects the default
+	// setting. A negative CircuitBreakerThreshold disables the circuit breaker.
+	EndpointTransportSettings struct {
+		DialTimeout             int  `json:"DialTimeout"`
+		TLSHandshakeTimeout     int  `json:"TLSHandshakeTimeout"`
+		ResponseHeaderTimeout   int  `json:"ResponseHeaderTimeout"`
+		IdleConnTimeout         int  `json:"IdleConnTimeout"`
+		KeepAlive               int  `json:"KeepAlive"`
+		MaxIdleConns            int  `json:"MaxIdleConns"`
+		DisableKeepAlives       bool `json:"DisableKeepAlives"`
+		CircuitBreakerThreshold int  `json:"CircuitBreakerThreshold"`
+		CircuitBreakerTimeout   int  `json:"CircuitBreakerTimeout"`
+	}
+
+	// CircuitBreakerState represents the state of the circuit breaker of an endpoint.
+	CircuitBreakerState int
+
+	// CircuitBreakerStatus represents the status of the circuit breaker of an endpoint. The requests
+	// sent to the endpoint fail fast while the circuit breaker is open.
+	CircuitBreakerStatus struct {
+		State               CircuitBreakerState `json:"State"`
+		ConsecutiveFailures int                 `json:"ConsecutiveFailures"`
+		OpenedAt            int64               `json:"OpenedAt,omitempty"`
+		RetryAt             int64               `json:"RetryAt,omitempty"`
+	}
+
+	// EndpointStatusCheck represents the result of a health check of an endpoint.
+	// The latency is expressed in milliseconds.
+	EndpointStatusCheck struct {
+		Status        EndpointStatus `json:"Status"`
+		CheckedAt     int64          `json:"CheckedAt"`
+		Latency       int64          `json:"Latency"`
+		DockerVersion string         `json:"DockerVersion,omitempty"`
+		Error         string         `json:"Error,omitempty"`
+	}
+
+	// TunnelStatus represents the status of the reverse tunnel opened by the agent of an
+	// edge endpoint.
+	TunnelStatus struct {
+		Active        bool   `json:"Active"`
+		ConnectedAt   int64  `json:"ConnectedAt,omitempty"`
+		RemoteAddress string `json:"RemoteAddress,omitempty"`
+	}
+
+	// ProxyInfo represents a proxy cached by the server for an endpoint, along with the
+	// types of the extensions of the endpoint that are also proxied.
+	ProxyInfo struct {
+		EndpointID     EndpointID              `json:"EndpointId"`
+		EndpointName   string                  `json:"EndpointName"`
+		EndpointType   EndpointType            `json:"EndpointType"`
+		URL            string                  `json:"URL"`
+		CreatedAt      int64                   `json:"CreatedAt"`
+		Extensions     []EndpointExtensionType `json:"Extensions"`
+		CircuitBreaker *CircuitBreakerStatus   `json:"CircuitBreaker,omitempty"`
+	}
+
+	// Snapshot represents a summary of the Docker environment of an endpoint at a given time.
+	// The memory is expressed in bytes.
+	Snapshot struct {
+		Time                  int64              `json:"Time"`
+		DockerVersion         string             `json:"DockerVersion"`
+		Swarm                 bool               `json:"Swarm"`
+		SwarmID               string             `json:"SwarmId,omitempty"`
+		NodeCount             int                `json:"NodeCount"`
+		TotalCPU              int                `json:"TotalCPU"`
+		TotalMemory           int64              `json:"TotalMemory"`
+		ContainerCount        int                `json:"ContainerCount"`
+		RunningContainerCount int                `json:"RunningContainerCount"`
+		StoppedContainerCount int                `json:"StoppedContainerCount"`
+		ServiceCount          int                `json:"ServiceCount"`
+		VolumeCount           int                `json:"VolumeCount"`
+		ImageCount            int                `json:"ImageCount"`
+		Resources             []SnapshotResource `json:"Resources,omitempty"`
+	}
+
+	// SnapshotResource represents a Docker resource recorded in a snapshot. The resources
+	// are used to search the endpoints without querying their Docker API.
+	SnapshotResource struct {
+		Type   SearchResultType  `json:"Type"`
+		ID     string            `json:"Id"`
+		Name   string            `json:"Name"`
+		Image  string            `json:"Image,omitempty"`
+		Labels map[string]string `json:"Labels,omitempty"`
+	}
+
+	// SearchResultType represents the type of a resource returned by a search.
+	SearchResultType string
+
+	// SearchResult represents a resource matching a search query, the results with
+	// the highest score are the most relevant.
+	SearchResult struct {
+		Type         SearchResultType  `json:"Type"`
+		ID           string            `json:"Id"`
+		Name         string            `json:"Name"`
+		EndpointID   EndpointID        `json:"EndpointId,omitempty"`
+		EndpointName string            `json:"EndpointName,omitempty"`
+		URL          string            `json:"URL,omitempty"`
+		Image        string            `json:"Image,omitempty"`
+		Labels       map[string]string `json:"Labels,omitempty"`
+		Score        int               `json:"Score"`
+	}
+
+	// AzureCredentials represents the credentials used to connect to an Azure
+	// environment.
+	AzureCredentials struct {
+		ApplicationID     string `json:"ApplicationID"`
+		TenantID          string `json:"TenantID"`
+		AuthenticationKey string `json:"AuthenticationKey"`
+	}
+
+	// KubernetesCredentials represents the credentials used to connect to the API server
+	// of a Kubernetes environment. The certificates are stored in the TLS configuration
+	// of the endpoint.
+	KubernetesCredentials struct {
+		Token string `json:"Token"`
+	}
+
+	// KubernetesNamespaceAccess represents the users and teams allowed to access a namespace
+	// of a Kubernetes environment. The namespaces without access are restricted to the administrators.
+	KubernetesNamespaceAccess struct {
+		Namespace       string   `json:"Namespace"`
+		AuthorizedUsers []UserID `json:"AuthorizedUsers"`
+		AuthorizedTeams []TeamID `json:"AuthorizedTeams"`
+	}
+
+	// EndpointGroupID represents an endpoint group identifier.
+	EndpointGroupID int
+
+	// EndpointGroup represents a group of endpoints.
+	EndpointGroup struct {
+		ID              EndpointGroupID `json:"Id"`
+		Name            string          `json:"Name"`
+		Description     string          `json:"Description"`
+		AuthorizedUsers []UserID        `json:"AuthorizedUsers"`
+		AuthorizedTeams []TeamID        `json:"AuthorizedTeams"`
+		Labels          []Pair          `json:"Labels"`
+	}
+
+	// EndpointExtension represents a extension associated to an endpoint.
+	EndpointExtension struct {
+		Type EndpointExtensionType `json:"Type"`
+		URL  string                `json:"URL"`
+	}
+
+	// EndpointExtensionType represents the type of an endpoint extension. Only
+	// one extension of each type can be associated to an endpoint.
+	EndpointExtensionType int
+
+	// ResourceControlID represents a resource control identifier.
+	ResourceControlID int
+
+	// ResourceControl represent a reference to a Docker resource with specific access controls
+	ResourceControl struct {
+		ID                 ResourceControlID    `json:"Id"`
+		ResourceID         string               `json:"ResourceId"`
+		SubResourceIDs     []string             `json:"SubResourceIds"`
+		Type               ResourceControlType  `json:"Type"`
+		AdministratorsOnly bool                 `json:"AdministratorsOnly"`
+		UserAccesses       []UserResourceAccess `json:"UserAccesses"`
+		TeamAccesses       []TeamResourceAccess `json:"TeamAccesses"`
+
+		// Deprecated fields
+		// Deprecated in DBVersion == 2
+		OwnerID     UserID              `json:"OwnerId,omitempty"`
+		AccessLevel ResourceAccessLevel `json:"AccessLevel,omitempty"`
+	}
+
+	// ResourceControlType represents the type of resource associated to the resource control (volume, container, service...).
+	ResourceControlType int
+
+	// UserResourceAccess represents the level of control on a resource for a specific user.
+	UserResourceAccess struct {
+		UserID      UserID              `json:"UserId"`
+		AccessLevel ResourceAccessLevel `json:"AccessLevel"`
+	}
+
+	// TeamResourceAccess represents the level of control on a resource for a specific team.
+	TeamResourceAccess struct {
+		TeamID      TeamID              `json:"TeamId"`
+		AccessLevel ResourceAccessLevel `json:"AccessLevel"`
+	}
+
+	// ResourceAccessLevel represents the level of control associated to a resource.
+	ResourceAccessLevel int
+
+	// TLSFileType represents a type of TLS file required to connect to a Docker endpoint.
+	// It can be either a TLS CA file, a TLS certificate file or a TLS key file.
+	TLSFileType int
+
+	// SSHFileType represents a type of SSH file required to connect to a Docker endpoint.
+	// It can be either a private key file or a known hosts file.
+	SSHFileType int
+
+	// CLIService represents a service for managing CLI.
+	CLIService interface {
+		ParseFlags(version string) (*CLIFlags, error)
+		ValidateFlags(flags *CLIFlags) error
+	}
+
+	// DataStore defines the interface to manage the data.
+	DataStore interface {
+		Open() error
+		Init() error
+		Close() error
+		MigrateData() error
+		BackupDatabase(w io.Writer) error
+		RestoreDatabase(path string) error
+	}
+
+	// Server defines the interface to serve the API.
+	Server interface {
+		Start() error
+	}
+
+	// UserService represents a service for managing user data.
+	UserService interface {
+		User(ID UserID) (*User, error)
+		UserByUsername(username string) (*User, error)
+		Users() ([]User, error)
+		UsersByRole(role UserRole) ([]User, error)
+		CreateUser(user *User) error
+		UpdateUser(ID UserID, user *User) error
+		UpdateUserFunc(ID UserID, updateFunc func(user *User) error) error
+		DeleteUser(ID UserID) error
+	}
+
+	// TeamService represents a service for managing user data.
+	TeamService interface {
+		Team(ID TeamID) (*Team, error)
+		TeamByName(name string) (*Team, error)
+		Teams() ([]Team, error)
+		CreateTeam(team *Team) error
+		UpdateTeam(ID TeamID, team *Team) error
+		DeleteTeam(ID TeamID) error
+	}
+
+	// TeamMembershipService represents a service for managing team membership data.
+	TeamMembershipService interface {
+		TeamMembership(ID TeamMembershipID) (*TeamMembership, error)
+		TeamMemberships() ([]TeamMembership, error)
+		TeamMembershipsByUserID(userID UserID) ([]TeamMembership, error)
+		TeamMembershipsByTeamID(teamID TeamID) ([]TeamMembership, error)
+		CreateTeamMembership(membership *TeamMembership) error
+		UpdateTeamMembership(ID TeamMembershipID, membership *TeamMembership) error
+		DeleteTeamMembership(ID TeamMembershipID) error
+		DeleteTeamMembershipByUserID(userID UserID) error
+		DeleteTeamMembershipByTeamID(teamID TeamID) error
+	}
+
+	// RoleService represents a service for managing role data.
+	RoleService interface {
+		Role(ID RoleID) (*Role, error)
+		RoleByName(name string) (*Role, error)
+		Roles() ([]Role, error)
+		CreateRole(role *Role) error
+		UpdateRole(ID RoleID, role *Role) error
+		DeleteRole(ID RoleID) error
+	}
+
+	// RoleAssignmentService represents a service for managing role assignment data.
+	RoleAssignmentService interface {
+		RoleAssignment(ID RoleAssignmentID) (*RoleAssignment, error)
+		RoleAssignments() ([]RoleAssignment, error)
+		CreateRoleAssignment(assignment *RoleAssignment) error
+		DeleteRoleAssignment(ID RoleAssignmentID) error
+		DeleteRoleAssignmentsByUserID(userID UserID) error
+		DeleteRoleAssignmentsByTeamID(teamID TeamID) error
+		DeleteRoleAssignmentsByRoleID(roleID RoleID) error
+	}
+
+	// AuditLogService represents a service for managing the audit log.
+	AuditLogService interface {
+		CreateAuditLogEntry(entry *AuditLogEntry) error
+		AuditLogEntries(filter *AuditLogFilter) ([]AuditLogEntry, error)
+	}
+
+	// EndpointService represents a service for managing endpoint data.
+	EndpointService interface {
+		Endpoint(ID EndpointID) (*Endpoint, error)
+		Endpoints() ([]Endpoint, error)
+		CreateEndpoint(endpoint *Endpoint) error
+		UpdateEndpoint(ID EndpointID, endpoint *Endpoint) error
+		UpdateEndpointFunc(ID EndpointID, updateFunc func(endpoint *Endpoint)) error
+		DeleteEndpoint(ID EndpointID) error
+		Synchronize(toCreate, toUpdate, toDelete []*Endpoint) error
+		RegisterObserver(observer EndpointObserver)
+	}
+
+	// EndpointObserver represents a service notified once endpoints are created, updated or
+	// deleted through the EndpointService of the instance.
+	EndpointObserver interface {
+		EndpointChanged(endpoint *Endpoint)
+		EndpointDeleted(ID EndpointID)
+	}
+
+	// EndpointGroupService represents a service for managing endpoint group data.
+	EndpointGroupService interface {
+		EndpointGroup(ID EndpointGroupID) (*EndpointGroup, error)
+		EndpointGroups() ([]EndpointGroup, error)
+		CreateEndpointGroup(group *EndpointGroup) error
+		UpdateEndpointGroup(ID EndpointGroupID, group *EndpointGroup) error
+		DeleteEndpointGroup(ID EndpointGroupID) error
+	}
+
+	// RegistryService represents a service for managing registry data.
+	RegistryService interface {
+		Registry(ID RegistryID) (*Registry, error)
+		Registries() ([]Registry, error)
+		CreateRegistry(registry *Registry) error
+		UpdateRegistry(ID RegistryID, registry *Registry) error
+		DeleteRegistry(ID RegistryID) error
+	}
+
+	// StackService represents a service for managing stack data.
+	StackService interface {
+		Stack(ID StackID) (*Stack, error)
+		Stacks() ([]Stack, error)
+		StacksBySwarmID(ID string) ([]Stack, error)
+		CreateStack(stack *Stack) error
+		UpdateStack(ID StackID, stack *Stack) error
+		DeleteStack(ID StackID) error
+	}
+
+	// DockerHubService represents a service for managing the DockerHub object.
+	DockerHubService interface {
+		DockerHub() (*DockerHub, error)
+		StoreDockerHub(registry *DockerHub) error
+	}
+
+	// SettingsService represents a service for managing application settings.
+	SettingsService interface {
+		Settings() (*Settings, error)
+		StoreSettings(settings *Settings) error
+	}
+
+	// VersionService represents a service for managing version data.
+	VersionService interface {
+		DBVersion() (int, error)
+		StoreDBVersion(version int) error
+	}
+
+	// ResourceControlService represents a service for managing resource control data.
+	ResourceControlService interface {
+		ResourceControl(ID ResourceControlID) (*ResourceControl, error)
+		ResourceControlByResourceID(resourceID string) (*ResourceControl, error)
+		ResourceControls() ([]ResourceControl, error)
+		CreateResourceControl(rc *ResourceControl) error
+		UpdateResourceControl(ID ResourceControlID, resourceControl *ResourceControl) error
+		DeleteResourceControl(ID ResourceControlID) error
+	}
+
+	// CryptoService represents a service for encrypting/hashing data.
+	CryptoService interface {
+		Hash(data string) (string, error)
+		CompareHashAndData(hash string, data string) error
+	}
+
+	// EncryptionService represents a service used to encrypt sensitive data before storing it.
+	EncryptionService interface {
+		GenerateKey() ([]byte, error)
+		SetKey(key []byte) error
+		Encrypt(data string) (string, error)
+		Decrypt(data string) (string, error)
+	}
+
+	// TOTPService represents a service used to manage time-based one-time passwords (RFC 6238).
+	TOTPService interface {
+		GenerateSecret() (string, error)
+		GenerateRecoveryCodes(count int) ([]string, error)
+		ProvisioningURI(secret, accountName string) string
+		ValidateCode(secret, code string, lastTimeStep int64) (int64, bool)
+	}
+
+	// DigitalSignatureService represents a service to manage digital signatures.
+	DigitalSignatureService interface {
+		ParseKeyPair(private, public []byte) error
+		GenerateKeyPair() ([]byte, []byte, error)
+		EncodedPublicKey() string
+		PEMHeaders() (string, string)
+		Sign(message string) (string, error)
+	}
+
+	// ClusterService represents a service used to coordinate the instances sharing the same datastore.
+	ClusterService interface {
+		Status() (*ClusterStatus, error)
+		IsLeader() bool
+		Lock(name string) (context.Context, error)
+		Unlock(name string) error
+		SharedKey(name string, generate func() ([]byte, error)) ([]byte, error)
+	}
+
+	// BackupService represents a service for creating and restoring backups of the application data.
+	BackupService interface {
+		CreateBackup(w io.Writer, password string) error
+		RestoreBackup(r io.Reader, password string) error
+	}
+
+	// BackupStatusService represents a service for managing the status of the scheduled backups.
+	BackupStatusService interface {
+		BackupStatus() (*BackupStatus, error)
+		StoreBackupStatus(status *BackupStatus) error
+	}
+
+	// ConfigService represents a service for applying and exporting declarative configuration documents.
+	ConfigService interface {
+		ApplyConfig(document []byte, dryRun bool) ([]ConfigChange, error)
+		ExportConfig(format string) ([]byte, error)
+	}
+
+	// BackupScheduler represents a service for running the scheduled backups.
+	BackupScheduler interface {
+		ScheduleBackups(settings *BackupSettings) error
+	}
+
+	// JWTService represents a service for managing JWT tokens.
+	JWTService interface {
+		GenerateToken(data *TokenData) (string, error)
+		ParseAndVerifyToken(token string) (*TokenData, error)
+	}
+
+	// FileService represents a service for managing files.
+	FileService interface {
+		GetFileContent(filePath string) (string, error)
+		RemoveDirectory(directoryPath string) error
+		StoreTLSFile(folder string, fileType TLSFileType, r io.Reader) error
+		GetPathForTLSFile(folder string, fileType TLSFileType) (string, error)
+		DeleteTLSFile(folder string, fileType TLSFileType) error
+		DeleteTLSFiles(folder string) error
+		StoreSSHFile(folder string, fileType SSHFileType, r io.Reader) error
+		GetPathForSSHFile(folder string, fileType SSHFileType) (string, error)
+		DeleteSSHFiles(folder string) error
+		GetStackProjectPath(stackIdentifier string) string
+		StoreStackFileFromString(stackIdentifier, fileName, stackFileContent string) (string, error)
+		StoreStackFileFromReader(stackIdentifier, fileName string, r io.Reader) (string, error)
+		KeyPairFilesExist() (bool, error)
+		StoreKeyPair(private, public []byte, privatePEMHeader, publicPEMHeader string) error
+		LoadKeyPair() ([]byte, []byte, error)
+		WriteJSONToFile(path string, content interface{}) error
+	}
+
+	// GitService represents a service for managing Git.
+	GitService interface {
+		ClonePublicRepository(repositoryURL, destination string) error
+		ClonePrivateRepositoryWithBasicAuth(repositoryURL, destination, username, password string) error
+	}
+
+	// EndpointWatcher represents a service to synchronize the endpoints via an external source.
+	EndpointWatcher interface {
+		WatchEndpointFile(source string, merge bool) error
+		WatchEndpointDiscovery(provider EndpointDiscoveryProvider) error
+	}
+
+	// EndpointDiscoveryProvider represents a service used to find the Docker hosts registered
+	// in a service discovery system. Name identifies the provider and is recorded on the endpoints it manages.
+	EndpointDiscoveryProvider interface {
+		Name() string
+		Discover() ([]DiscoveredEndpoint, error)
+	}
+
+	// LDAPService represents a service used to authenticate users against a LDAP/AD.
+	LDAPService interface {
+		AuthenticateUser(username, password string, settings *LDAPSettings) error
+		TestConnectivity(settings *LDAPSettings) error
+	}
+
+	// StackManager represents a service to manage stacks.
+	StackManager interface {
+		Login(dockerhub *DockerHub, registries []Registry, endpoint *Endpoint)
+		Logout(endpoint *Endpoint) error
+		Deploy(ctx context.Context, stack *Stack, prune bool, endpoint *Endpoint) error
+		Remove(ctx context.Context, stack *Stack, endpoint *Endpoint) error
+	}
+)
+
+const (
+	// APIVersion is the version number of the Chain Platform API.
+	APIVersion = "1.17.1-dev"
+	// DBVersion is the version number of the Chain Platform database.
+	DBVersion = 15
+	// DefaultTemplatesURL represents the default URL for the templates definitions.
+	DefaultTemplatesURL = "https://raw.githubusercontent.com/chainid/templates/master/templates.json"
+	// PortainerAgentHeader represents the name of the header available in any agent response
+	PortainerAgentHeader = "Chain Platform-Agent"
+	// PortainerAgentTargetHeader represent the name of the header containing the target node name.
+	PortainerAgentTargetHeader = "X-PortainerAgent-Target"
+	// PortainerAgentSignatureHeader represent the name of the header containing the digital signature
+	PortainerAgentSignatureHeader = "X-PortainerAgent-Signature"
+	// PortainerAgentPublicKeyHeader represent the name of the header containing the public key
+	PortainerAgentPublicKeyHeader = "X-PortainerAgent-PublicKey"
+	// PortainerAgentSignatureMessage represents the message used to create a digital signature
+	// to be used when communicating with an agent
+	PortainerAgentSignatureMessage = "Chain Platform-App"
+	// EdgeJoinTokenHeader represents the name of the header containing the join token
+	// sent by an edge agent when opening its tunnel
+	EdgeJoinTokenHeader = "X-ChainId-Edge-Token"
+)
+
+const (
+	// BoltDataStore represents a datastore using an embedded BoltDB database
+	BoltDataStore = "bolt"
+	// ConsulDataStore represents a datastore using the Consul key/value store
+	ConsulDataStore = "consul"
+)
+
+const (
+	// ExternalEndpointsReplaceMode represents the synchronization mode in which the external endpoints
+	// source defines every endpoint and endpoint management via the API is disabled
+	ExternalEndpointsReplaceMode = "replace"
+	// ExternalEndpointsMergeMode represents the synchronization mode in which the endpoints defined in the
+	// external endpoints source coexist with the endpoints managed via the API
+	ExternalEndpointsMergeMode = "merge"
+)
+
+const (
+	// TLSFileCA represents a TLS CA certificate file.
+	TLSFileCA TLSFileType = iota
+	// TLSFileCert represents a TLS certificate file.
+	TLSFileCert
+	// TLSFileKey represents a TLS key file.
+	TLSFileKey
+)
+
+const (
+	// SSHFileKey represents a SSH private key file.
+	SSHFileKey SSHFileType = iota
+	// SSHFileKnownHosts represents a SSH known hosts file.
+	SSHFileKnownHosts
+)
+
+const (
+	_ MembershipRole = iota
+	// TeamLeader represents a leader role inside a team
+	TeamLeader
+	// TeamMember represents a member role inside a team
+	TeamMember
+)
+
+const (
+	_ UserRole = iota
+	// AdministratorRole represents an administrator user role
+	AdministratorRole
+	// StandardUserRole represents a regular user role
+	StandardUserRole
+)
+
+const (
+	_ RoleID = iota
+	// AdministratorRoleID represents the identifier of the built-in administrator role
+	AdministratorRoleID
+	// StandardUserRoleID represents the identifier of the built-in standard user role
+	StandardUserRoleID
+)
+
+const (
+	// EndpointCreatePermission allows the creation of endpoints
+	EndpointCreatePermission Permission = "endpoint:create"
+	// EndpointUpdatePermission allows the inspection and update of endpoints and their access
+	EndpointUpdatePermission Permission = "endpoint:update"
+	// EndpointDeletePermission allows the removal of endpoints
+	EndpointDeletePermission Permission = "endpoint:delete"
+	// EndpointGroupManagePermission allows the management of endpoint groups
+	EndpointGroupManagePermission Permission = "endpoint_group:manage"
+	// RegistryManagePermission allows the management of registries and of the DockerHub configuration
+	RegistryManagePermission Permission = "registry:manage"
+	// UserManagePermission allows the management of user accounts
+	UserManagePermission Permission = "user:manage"
+	// TeamManagePermission allows the management of teams
+	TeamManagePermission Permission = "team:manage"
+	// SettingsManagePermission allows the management of the application settings
+	SettingsManagePermission Permission = "settings:manage"
+	// StackDeployPermission allows the deployment and update of stacks
+	StackDeployPermission Permission = "stack:deploy"
+	// StackDeletePermission allows the removal of stacks
+	StackDeletePermission Permission = "stack:delete"
+	// ContainerExecPermission allows the creation of exec instances inside containers
+	ContainerExecPermission Permission = "container:exec"
+	// ContainerPrunePermission allows the removal of stopped containers
+	ContainerPrunePermission Permission = "container:prune"
+	// VolumePrunePermission allows the removal of unused volumes
+	VolumePrunePermission Permission = "volume:prune"
+	// NodeUpdatePermission allows the update of Swarm nodes
+	NodeUpdatePermission Permission = "node:update"
+	// SwarmManagePermission allows the management of the Swarm cluster
+	SwarmManagePermission Permission = "swarm:manage"
+)
+
+const (
+	_ AuthenticationMethod = iota
+	// AuthenticationInternal represents the internal authentication method (authentication against Chain Platform API)
+	AuthenticationInternal
+	// AuthenticationLDAP represents the LDAP authentication method (authentication against a LDAP server)
+	AuthenticationLDAP
+)
+
+const (
+	_ ResourceAccessLevel = iota
+	// ReadWriteAccessLevel represents an access level with read-write permissions on a resource
+	ReadWriteAccessLevel
+)
+
+const (
+	_ ResourceControlType = iota
+	// ContainerResourceControl represents a resource control associated to a Docker container
+	ContainerResourceControl
+	// ServiceResourceControl represents a resource control associated to a Docker service
+	ServiceResourceControl
+	// VolumeResourceControl represents a resource control associated to a Docker volume
+	VolumeResourceControl
+	// NetworkResourceControl represents a resource control associated to a Docker network
+	NetworkResourceControl
+	// SecretResourceControl represents a resource control associated to a Docker secret
+	SecretResourceControl
+	// StackResourceControl represents a resource control associated to a stack composed of Docker services
+	StackResourceControl
+	// ConfigResourceControl represents a resource control associated to a Docker config
+	ConfigResourceControl
+)
+
+const (
+	_ BackupTargetType = iota
+	// LocalBackupTarget represents a directory on the local filesystem
+	LocalBackupTarget
+	// S3BackupTarget represents a bucket of an S3-compatible storage
+	S3BackupTarget
+)
+
+const (
+	// ConfigChangeCreate represents the creation of a resource
+	ConfigChangeCreate ConfigChangeAction = "create"
+	// ConfigChangeUpdate represents the update of a resource
+	ConfigChangeUpdate ConfigChangeAction = "update"
+	// ConfigChangeDelete represents the removal of a resource
+	ConfigChangeDelete ConfigChangeAction = "delete"
+)
+
+const (
+	// ContainerSearchResult represents a Docker container
+	ContainerSearchResult SearchResultType = "container"
+	// ServiceSearchResult represents a Docker service
+	ServiceSearchResult SearchResultType = "service"
+	// VolumeSearchResult represents a Docker volume
+	VolumeSearchResult SearchResultType = "volume"
+	// NetworkSearchResult represents a Docker network
+	NetworkSearchResult SearchResultType = "network"
+	// StackSearchResult represents a stack
+	StackSearchResult SearchResultType = "stack"
+	// EndpointSearchResult represents an endpoint
+	EndpointSearchResult SearchResultType = "endpoint"
+	// RegistrySearchResult represents a registry
+	RegistrySearchResult SearchResultType = "registry"
+)
+
+const (
+	// ConfigFormatYAML represents a configuration document encoded in YAML
+	ConfigFormatYAML = "yaml"
+	// ConfigFormatJSON represents a configuration document encoded in JSON
+	ConfigFormatJSON = "json"
+)
+
+const (
+	_ EndpointExtensionType = iota
+	// StoridgeEndpointExtension represents the Storidge extension
+	StoridgeEndpointExtension
+)
+
+const (
+	_ EndpointType = iota
+	// DockerEnvironment represents an endpoint connected to a Docker environment
+	DockerEnvironment
+	// AgentOnDockerEnvironment represents an endpoint connected to a Chain Platform agent deployed on a Docker environment
+	AgentOnDockerEnvironment
+	// AzureEnvironment represents an endpoint connected to an Azure environment
+	AzureEnvironment
+	// EdgeAgentEnvironment represents an endpoint connected to an edge agent through a reverse tunnel
+	EdgeAgentEnvironment
+	// KubernetesEnvironment represents an endpoint connected to the API server of a Kubernetes environment
+	KubernetesEnvironment
+)
+
+const (
+	_ EndpointStatus = iota
+	// EndpointStatusUp represents an endpoint whose Docker API is reachable
+	EndpointStatusUp
+	// EndpointStatusDown represents an endpoint whose Docker API is unreachable
+	EndpointStatusDown
+)
+
+const (
+	_ CircuitBreakerState = iota
+	// CircuitBreakerClosed represents a circuit breaker letting the requests through
+	CircuitBreakerClosed
+	// CircuitBreakerOpen represents a circuit breaker rejecting the requests
+	CircuitBreakerOpen
+	// CircuitBreakerHalfOpen represents a circuit breaker letting a single request through
+	// to check whether the endpoint is reachable again
+	CircuitBreakerHalfOpen
+)
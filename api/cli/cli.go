@@ -1,141 +1,402 @@
-package cli
-
-import (
-	"time"
-
-	"github.com/chainid-io/dashboard"
-
-	"os"
-	"path/filepath"
-	"strings"
-
-	"gopkg.in/alecthomas/kingpin.v2"
-)
-
-// Service implements the CLIService interface
-type Service struct{}
-
-const (
-	errInvalidEndpointProtocol       = chainid.Error("Invalid endpoint protocol: Chain Platform only supports unix:// or tcp://")
-	errSocketNotFound                = chainid.Error("Unable to locate Unix socket")
-	errEndpointsFileNotFound         = chainid.Error("Unable to locate external endpoints file")
-	errInvalidSyncInterval           = chainid.Error("Invalid synchronization interval")
-	errEndpointExcludeExternal       = chainid.Error("Cannot use the -H flag mutually with --external-endpoints")
-	errNoAuthExcludeAdminPassword    = chainid.Error("Cannot use --no-auth with --admin-password or --admin-password-file")
-	errAdminPassExcludeAdminPassFile = chainid.Error("Cannot use --admin-password with --admin-password-file")
-)
-
-// ParseFlags parse the CLI flags and return a chainid.Flags struct
-func (*Service) ParseFlags(version string) (*chainid.CLIFlags, error) {
-	kingpin.Version(version)
-
-	flags := &chainid.CLIFlags{
-		Addr:              kingpin.Flag("bind", "Address and port to serve Chain Platform").Default(defaultBindAddress).Short('p').String(),
-		Assets:            kingpin.Flag("assets", "Path to the assets").Default(defaultAssetsDirectory).Short('a').String(),
-		Data:              kingpin.Flag("data", "Path to the folder where the data is stored").Default(defaultDataDirectory).Short('d').String(),
-		EndpointURL:       kingpin.Flag("host", "Endpoint URL").Short('H').String(),
-		ExternalEndpoints: kingpin.Flag("external-endpoints", "Path to a file defining available endpoints").String(),
-		NoAuth:            kingpin.Flag("no-auth", "Disable authentication").Default(defaultNoAuth).Bool(),
-		NoAnalytics:       kingpin.Flag("no-analytics", "Disable Analytics in app").Default(defaultNoAnalytics).Bool(),
-		TLS:               kingpin.Flag("tlsverify", "TLS support").Default(defaultTLS).Bool(),
-		TLSSkipVerify:     kingpin.Flag("tlsskipverify", "Disable TLS server verification").Default(defaultTLSSkipVerify).Bool(),
-		TLSCacert:         kingpin.Flag("tlscacert", "Path to the CA").Default(defaultTLSCACertPath).String(),
-		TLSCert:           kingpin.Flag("tlscert", "Path to the TLS certificate file").Default(defaultTLSCertPath).String(),
-		TLSKey:            kingpin.Flag("tlskey", "Path to the TLS key").Default(defaultTLSKeyPath).String(),
-		SSL:               kingpin.Flag("ssl", "Secure Chain Platform instance using SSL").Default(defaultSSL).Bool(),
-		SSLCert:           kingpin.Flag("sslcert", "Path to the SSL certificate used to secure the Chain Platform instance").Default(defaultSSLCertPath).String(),
-		SSLKey:            kingpin.Flag("sslkey", "Path to the SSL key used to secure the Chain Platform instance").Default(defaultSSLKeyPath).String(),
-		SyncInterval:      kingpin.Flag("sync-interval", "Duration between each synchronization via the external endpoints source").Default(defaultSyncInterval).String(),
-		AdminPassword:     kingpin.Flag("admin-password", "Hashed admin password").String(),
-		AdminPasswordFile: kingpin.Flag("admin-password-file", "Path to the file containing the password for the admin user").String(),
-		Labels:            pairs(kingpin.Flag("hide-label", "Hide containers with a specific label in the UI").Short('l')),
-		Logo:              kingpin.Flag("logo", "URL for the logo displayed in the UI").String(),
-		Templates:         kingpin.Flag("templates", "URL to the templates (apps) definitions").Short('t').String(),
-	}
-
-	kingpin.Parse()
-
-	if !filepath.IsAbs(*flags.Assets) {
-		ex, err := os.Executable()
-		if err != nil {
-			panic(err)
-		}
-		*flags.Assets = filepath.Join(filepath.Dir(ex), *flags.Assets)
-	}
-
-	return flags, nil
-}
-
-// ValidateFlags validates the values of the flags.
-func (*Service) ValidateFlags(flags *chainid.CLIFlags) error {
-
-	if *flags.EndpointURL != "" && *flags.ExternalEndpoints != "" {
-		return errEndpointExcludeExternal
-	}
-
-	err := validateEndpointURL(*flags.EndpointURL)
-	if err != nil {
-		return err
-	}
-
-	err = validateExternalEndpoints(*flags.ExternalEndpoints)
-	if err != nil {
-		return err
-	}
-
-	err = validateSyncInterval(*flags.SyncInterval)
-	if err != nil {
-		return err
-	}
-
-	if *flags.NoAuth && (*flags.AdminPassword != "" || *flags.AdminPasswordFile != "") {
-		return errNoAuthExcludeAdminPassword
-	}
-
-	if *flags.AdminPassword != "" && *flags.AdminPasswordFile != "" {
-		return errAdminPassExcludeAdminPassFile
-	}
-
-	return nil
-}
-
-func validateEndpointURL(endpointURL string) error {
-	if endpointURL != "" {
-		if !strings.HasPrefix(endpointURL, "unix://") && !strings.HasPrefix(endpointURL, "tcp://") {
-			return errInvalidEndpointProtocol
-		}
-
-		if strings.HasPrefix(endpointURL, "unix://") {
-			socketPath := strings.TrimPrefix(endpointURL, "unix://")
-			if _, err := os.Stat(socketPath); err != nil {
-				if os.IsNotExist(err) {
-					return errSocketNotFound
-				}
-				return err
-			}
-		}
-	}
-	return nil
-}
-
-func validateExternalEndpoints(externalEndpoints string) error {
-	if externalEndpoints != "" {
-		if _, err := os.Stat(externalEndpoints); err != nil {
-			if os.IsNotExist(err) {
-				return errEndpointsFileNotFound
-			}
-			return err
-		}
-	}
-	return nil
-}
-
-func validateSyncInterval(syncInterval string) error {
-	if syncInterval != defaultSyncInterval {
-		_, err := time.ParseDuration(syncInterval)
-		if err != nil {
-			return errInvalidSyncInterval
-		}
-	}
-	return nil
-}
+package cli
+
+import (
+	"time"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/discovery"
+	"github.com/chainid-io/dashboard/http/security"
+	"github.com/chainid-io/dashboard/ssh"
+
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// Service implements the CLIService interface
+type Service struct{}
+
+const (
+	errInvalidEndpointProtocol       = chainid.Error("Invalid endpoint protocol: Chain Platform only supports unix://, tcp:// or ssh://")
+	errSSHKeyRequired                = chainid.Error("An ssh:// endpoint requires the --sshkey flag")
+	errSSHKeyNotFound                = chainid.Error("Unable to locate SSH key file")
+	errSSHKnownHostsRequired         = chainid.Error("An ssh:// endpoint requires the --sshknownhosts flag unless --sshskipverify is set")
+	errSSHKnownHostsNotFound         = chainid.Error("Unable to locate SSH known hosts file")
+	errSocketNotFound                = chainid.Error("Unable to locate Unix socket")
+	errEndpointsFileNotFound         = chainid.Error("Unable to locate external endpoints file")
+	errInvalidEndpointsURL           = chainid.Error("Invalid external endpoints URL")
+	errInvalidEndpointsMode          = chainid.Error("Invalid external endpoints mode: Chain Platform only supports replace or merge")
+	errInvalidSyncInterval           = chainid.Error("Invalid synchronization interval")
+	errInvalidHealthCheckInterval    = chainid.Error("Invalid health check interval")
+	errInvalidSnapshotInterval       = chainid.Error("Invalid snapshot interval")
+	errInvalidHSTSMaxAge             = chainid.Error("Invalid HSTS max age")
+	errSSLRequired                   = chainid.Error("Cannot use --ssl-self-signed, --ssl-redirect, --hsts-max-age or --acme-domain without --ssl")
+	errACMEExcludeSelfSigned         = chainid.Error("Cannot use --acme-domain with --ssl-self-signed")
+	errACMERequiresRedirect          = chainid.Error("The ACME HTTP-01 challenge requires the --ssl-redirect flag")
+	errEndpointExcludeExternal       = chainid.Error("Cannot use the -H flag mutually with --external-endpoints")
+	errNoAuthExcludeAdminPassword    = chainid.Error("Cannot use --no-auth with --admin-password or --admin-password-file")
+	errAdminPassExcludeAdminPassFile = chainid.Error("Cannot use --admin-password with --admin-password-file")
+	errInvalidTrustedProxy           = chainid.Error("Invalid trusted proxy: must be an IP address or a CIDR range")
+	errConfigFileNotFound            = chainid.Error("Unable to locate configuration file")
+	errMasterKeyExcludeMasterKeyFile = chainid.Error("Cannot use --master-key with --master-key-file")
+	errMasterKeyRequired             = chainid.Error("Cannot use --previous-master-key-file or --rotate-data-key without a master key")
+	errMasterKeyFileNotFound         = chainid.Error("Unable to locate master key file")
+	errInvalidDatastore              = chainid.Error("Invalid datastore: Chain Platform only supports bolt or consul")
+	errRotateDataKeyRequiresBolt     = chainid.Error("Cannot use --rotate-data-key with a datastore other than bolt")
+	errRollbackRequiresBolt          = chainid.Error("Cannot use --rollback-migration with a datastore other than bolt")
+)
+
+// ParseFlags parse the CLI flags and return a chainid.Flags struct
+func (*Service) ParseFlags(version string) (*chainid.CLIFlags, error) {
+	kingpin.Version(version)
+
+	flags := &chainid.CLIFlags{
+		Addr:                  kingpin.Flag("bind", "Address and port to serve Chain Platform").Default(defaultBindAddress).Short('p').String(),
+		Assets:                kingpin.Flag("assets", "Path to the assets").Default(defaultAssetsDirectory).Short('a').String(),
+		Data:                  kingpin.Flag("data", "Path to the folder where the data is stored").Default(defaultDataDirectory).Short('d').String(),
+		EndpointURL:           kingpin.Flag("host", "Endpoint URL").Short('H').String(),
+		ExternalEndpoints:     kingpin.Flag("external-endpoints", "Path or HTTP(S) URL of a JSON or YAML file defining available endpoints").String(),
+		ExternalEndpointsMode: kingpin.Flag("external-endpoints-mode", "Synchronization mode of the external endpoints (replace or merge)").Default(defaultExternalEndpointsMode).String(),
+		Discovery:             kingpin.Flag("discovery", "Source of discovered endpoints, consul://<address>/<service> or dns://<name> (can be repeated)").Strings(),
+		DiscoveryToken:        kingpin.Flag("discovery-token", "ACL token used to query the Consul catalog").Envar("CHAINID_DISCOVERY_TOKEN").String(),
+		NoAuth:                kingpin.Flag("no-auth", "Disable authentication").Default(defaultNoAuth).Bool(),
+		NoAnalytics:           kingpin.Flag("no-analytics", "Disable Analytics in app").Default(defaultNoAnalytics).Bool(),
+		TLS:                   kingpin.Flag("tlsverify", "TLS support").Default(defaultTLS).Bool(),
+		TLSSkipVerify:         kingpin.Flag("tlsskipverify", "Disable TLS server verification").Default(defaultTLSSkipVerify).Bool(),
+		TLSCacert:             kingpin.Flag("tlscacert", "Path to the CA").Default(defaultTLSCACertPath).String(),
+		TLSCert:               kingpin.Flag("tlscert", "Path to the TLS certificate file").Default(defaultTLSCertPath).String(),
+		TLSKey:                kingpin.Flag("tlskey", "Path to the TLS key").Default(defaultTLSKeyPath).String(),
+		SSHKey:                kingpin.Flag("sshkey", "Path to the SSH private key used to connect to an ssh:// endpoint").String(),
+		SSHKnownHosts:         kingpin.Flag("sshknownhosts", "Path to the known hosts file listing the host keys of an ssh:// endpoint").String(),
+		SSHSkipVerify:         kingpin.Flag("sshskipverify", "Disable SSH host key verification").Bool(),
+		SSL:                   kingpin.Flag("ssl", "Secure Chain Platform instance using SSL").Default(defaultSSL).Bool(),
+		SSLCert:               kingpin.Flag("sslcert", "Path to the SSL certificate used to secure the Chain Platform instance").Default(defaultSSLCertPath).String(),
+		SSLKey:                kingpin.Flag("sslkey", "Path to the SSL key used to secure the Chain Platform instance").Default(defaultSSLKeyPath).String(),
+		SSLSelfSigned:         kingpin.Flag("ssl-self-signed", "Generate a self-signed SSL certificate when the SSL certificate file does not exist").Bool(),
+		SSLRedirectAddr:       kingpin.Flag("ssl-redirect", "Address and port of an HTTP listener redirecting to the SSL listener and answering the ACME challenges").String(),
+		HSTSMaxAge:            kingpin.Flag("hsts-max-age", "Duration of the Strict-Transport-Security header sent over SSL, disabled when zero").Default(defaultHSTSMaxAge).String(),
+		ACMEDomains:           kingpin.Flag("acme-domain", "Domain of the SSL certificate obtained from an ACME server (can be repeated)").Strings(),
+		ACMEEmail:             kingpin.Flag("acme-email", "Email address of the ACME account").String(),
+		ACMEDirectory:         kingpin.Flag("acme-directory", "URL of the directory of the ACME server").Default(defaultACMEDirectory).String(),
+		SyncInterval:          kingpin.Flag("sync-interval", "Duration between each synchronization via the external endpoints source").Default(defaultSyncInterval).String(),
+		HealthCheckInterval:   kingpin.Flag("health-check-interval", "Duration between each status check of the endpoints").Default(defaultHealthCheckInterval).String(),
+		SnapshotInterval:      kingpin.Flag("snapshot-interval", "Duration between each snapshot of the endpoints").Default(defaultSnapshotInterval).String(),
+		AdminPassword:         kingpin.Flag("admin-password", "Hashed admin password").String(),
+		AdminPasswordFile:     kingpin.Flag("admin-password-file", "Path to the file containing the password for the admin user").String(),
+		Labels:                pairs(kingpin.Flag("hide-label", "Hide containers with a specific label in the UI").Short('l')),
+		Logo:                  kingpin.Flag("logo", "URL for the logo displayed in the UI").String(),
+		Templates:             kingpin.Flag("templates", "URL to the templates (apps) definitions").Short('t').String(),
+		TrustedProxies:        kingpin.Flag("trusted-proxy", "IP address or CIDR range of a reverse proxy allowed to set the X-Forwarded-For header (can be repeated)").Strings(),
+		ConfigFile:            kingpin.Flag("config-file", "Path to a declarative configuration file (YAML or JSON) applied at startup").String(),
+		MasterKey:             kingpin.Flag("master-key", "Master key used to encrypt the secrets stored in the database").Envar("CHAINID_MASTER_KEY").String(),
+		MasterKeyFile:         kingpin.Flag("master-key-file", "Path to the file containing the master key used to encrypt the secrets stored in the database").String(),
+		PreviousMasterKeyFile: kingpin.Flag("previous-master-key-file", "Path to the file containing the previous master key, used to rotate the master key").String(),
+		RotateDataKey:         kingpin.Flag("rotate-data-key", "Re-encrypt the secrets stored in the database with a new data key").Bool(),
+		RollbackMigration:     kingpin.Flag("rollback-migration", "Restore the copy of the database made before the last migration and exit").Bool(),
+		Datastore:             kingpin.Flag("datastore", "Storage backend used to store the data (bolt or consul)").Default(defaultDatastore).String(),
+		DatastoreEndpoint:     kingpin.Flag("datastore-endpoint", "Address of the datastore server, ignored by the bolt datastore").Default(defaultDatastoreEndpoint).String(),
+		DatastorePrefix:       kingpin.Flag("datastore-prefix", "Prefix of the keys stored in the datastore, ignored by the bolt datastore").Default(defaultDatastorePrefix).String(),
+		DatastoreToken:        kingpin.Flag("datastore-token", "Access token used to connect to the datastore, ignored by the bolt datastore").Envar("CHAINID_DATASTORE_TOKEN").String(),
+		DB:                    &chainid.DBFlags{},
+	}
+
+	kingpin.Command("serve", "Start Chain Platform").Default()
+
+	db := kingpin.Command("db", "Inspect and repair the database of a stopped instance")
+	flags.DB.JSON = db.Flag("json", "Print the output in JSON format").Bool()
+	db.Command("dump", "Print the records of every bucket")
+	flags.DB.Bucket = db.Command("export", "Print the records of a bucket").Flag("bucket", "Name of the bucket").Required().String()
+	flags.DB.Version = db.Command("set-version", "Overwrite the version of the database").Arg("version", "Database version").Required().Int()
+	flags.DB.DryRun = db.Command("migrate", "Migrate the database to the current version").Flag("dry-run", "Migrate a copy of the database and leave the database untouched").Bool()
+	resetAdminPassword := db.Command("reset-admin-password", "Reset the password of an administrator, unlock the account and disable its two-factor authentication")
+	flags.DB.Username = resetAdminPassword.Flag("username", "Name of the administrator, defaults to the first administrator").String()
+	flags.DB.Password = resetAdminPassword.Flag("password", "New password, a random password is generated when not specified").String()
+	db.Command("verify", "Check the consistency of the data")
+
+	command := kingpin.Parse()
+	if strings.HasPrefix(command, "db ") {
+		flags.DB.Command = strings.TrimPrefix(command, "db ")
+	}
+
+	if !filepath.IsAbs(*flags.Assets) {
+		ex, err := os.Executable()
+		if err != nil {
+			panic(err)
+		}
+		*flags.Assets = filepath.Join(filepath.Dir(ex), *flags.Assets)
+	}
+
+	return flags, nil
+}
+
+// ValidateFlags validates the values of the flags.
+func (*Service) ValidateFlags(flags *chainid.CLIFlags) error {
+
+	if *flags.EndpointURL != "" && *flags.ExternalEndpoints != "" {
+		return errEndpointExcludeExternal
+	}
+
+	err := validateEndpointURL(*flags.EndpointURL)
+	if err != nil {
+		return err
+	}
+
+	err = validateSSHFlags(flags)
+	if err != nil {
+		return err
+	}
+
+	err = validateExternalEndpoints(*flags.ExternalEndpoints, *flags.ExternalEndpointsMode)
+	if err != nil {
+		return err
+	}
+
+	err = validateDiscovery(*flags.Discovery)
+	if err != nil {
+		return err
+	}
+
+	err = validateSyncInterval(*flags.SyncInterval)
+	if err != nil {
+		return err
+	}
+
+	err = validateHealthCheckInterval(*flags.HealthCheckInterval)
+	if err != nil {
+		return err
+	}
+
+	err = validateSnapshotInterval(*flags.SnapshotInterval)
+	if err != nil {
+		return err
+	}
+
+	err = validateSSLFlags(flags)
+	if err != nil {
+		return err
+	}
+
+	if *flags.NoAuth && (*flags.AdminPassword != "" || *flags.AdminPasswordFile != "") {
+		return errNoAuthExcludeAdminPassword
+	}
+
+	if *flags.AdminPassword != "" && *flags.AdminPasswordFile != "" {
+		return errAdminPassExcludeAdminPassFile
+	}
+
+	err = validateTrustedProxies(*flags.TrustedProxies)
+	if err != nil {
+		return err
+	}
+
+	err = validateConfigFile(*flags.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	err = validateMasterKey(flags)
+	if err != nil {
+		return err
+	}
+
+	err = validateDatastore(flags)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func validateEndpointURL(endpointURL string) error {
+	if endpointURL != "" {
+		if !strings.HasPrefix(endpointURL, "unix://") && !strings.HasPrefix(endpointURL, "tcp://") && !strings.HasPrefix(endpointURL, "ssh://") {
+			return errInvalidEndpointProtocol
+		}
+
+		if strings.HasPrefix(endpointURL, "ssh://") {
+			return ssh.ValidateEndpointURL(endpointURL)
+		}
+
+		if strings.HasPrefix(endpointURL, "unix://") {
+			socketPath := strings.TrimPrefix(endpointURL, "unix://")
+			if _, err := os.Stat(socketPath); err != nil {
+				if os.IsNotExist(err) {
+					return errSocketNotFound
+				}
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateSSHFlags(flags *chainid.CLIFlags) error {
+	if !strings.HasPrefix(*flags.EndpointURL, "ssh://") {
+		return nil
+	}
+
+	if *flags.SSHKey == "" {
+		return errSSHKeyRequired
+	}
+	if _, err := os.Stat(*flags.SSHKey); err != nil {
+		if os.IsNotExist(err) {
+			return errSSHKeyNotFound
+		}
+		return err
+	}
+
+	if *flags.SSHKnownHosts == "" {
+		if !*flags.SSHSkipVerify {
+			return errSSHKnownHostsRequired
+		}
+		return nil
+	}
+	if _, err := os.Stat(*flags.SSHKnownHosts); err != nil {
+		if os.IsNotExist(err) {
+			return errSSHKnownHostsNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func validateExternalEndpoints(externalEndpoints, mode string) error {
+	if mode != chainid.ExternalEndpointsReplaceMode && mode != chainid.ExternalEndpointsMergeMode {
+		return errInvalidEndpointsMode
+	}
+	if strings.HasPrefix(externalEndpoints, "http://") || strings.HasPrefix(externalEndpoints, "https://") {
+		if _, err := url.ParseRequestURI(externalEndpoints); err != nil {
+			return errInvalidEndpointsURL
+		}
+		return nil
+	}
+	if externalEndpoints != "" {
+		if _, err := os.Stat(externalEndpoints); err != nil {
+			if os.IsNotExist(err) {
+				return errEndpointsFileNotFound
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func validateDiscovery(sources []string) error {
+	for _, source := range sources {
+		_, err := discovery.NewProvider(source, "")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateConfigFile(configFile string) error {
+	if configFile != "" {
+		if _, err := os.Stat(configFile); err != nil {
+			if os.IsNotExist(err) {
+				return errConfigFileNotFound
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func validateMasterKey(flags *chainid.CLIFlags) error {
+	if *flags.MasterKey != "" && *flags.MasterKeyFile != "" {
+		return errMasterKeyExcludeMasterKeyFile
+	}
+
+	if *flags.MasterKey == "" && *flags.MasterKeyFile == "" && (*flags.PreviousMasterKeyFile != "" || *flags.RotateDataKey) {
+		return errMasterKeyRequired
+	}
+
+	for _, keyFile := range []string{*flags.MasterKeyFile, *flags.PreviousMasterKeyFile} {
+		if keyFile != "" {
+			if _, err := os.Stat(keyFile); err != nil {
+				if os.IsNotExist(err) {
+					return errMasterKeyFileNotFound
+				}
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateDatastore(flags *chainid.CLIFlags) error {
+	switch *flags.Datastore {
+	case chainid.BoltDataStore:
+		return nil
+	case chainid.ConsulDataStore:
+		if *flags.RotateDataKey {
+			return errRotateDataKeyRequiresBolt
+		}
+		if *flags.RollbackMigration {
+			return errRollbackRequiresBolt
+		}
+		return nil
+	}
+	return errInvalidDatastore
+}
+
+func validateSSLFlags(flags *chainid.CLIFlags) error {
+	maxAge, err := time.ParseDuration(*flags.HSTSMaxAge)
+	if err != nil || maxAge < 0 {
+		return errInvalidHSTSMaxAge
+	}
+
+	if !*flags.SSL && (*flags.SSLSelfSigned || *flags.SSLRedirectAddr != "" || maxAge > 0 || len(*flags.ACMEDomains) > 0) {
+		return errSSLRequired
+	}
+
+	if len(*flags.ACMEDomains) > 0 {
+		if *flags.SSLSelfSigned {
+			return errACMEExcludeSelfSigned
+		}
+		if *flags.SSLRedirectAddr == "" {
+			return errACMERequiresRedirect
+		}
+	}
+	return nil
+}
+
+func validateSyncInterval(syncInterval string) error {
+	if syncInterval != defaultSyncInterval {
+		_, err := time.ParseDuration(syncInterval)
+		if err != nil {
+			return errInvalidSyncInterval
+		}
+	}
+	return nil
+}
+
+func validateHealthCheckInterval(healthCheckInterval string) error {
+	_, err := time.ParseDuration(healthCheckInterval)
+	if err != nil {
+		return errInvalidHealthCheckInterval
+	}
+	return nil
+}
+
+func validateSnapshotInterval(snapshotInterval string) error {
+	_, err := time.ParseDuration(snapshotInterval)
+	if err != nil {
+		return errInvalidSnapshotInterval
+	}
+	return nil
+}
+
+func validateTrustedProxies(proxies []string) error {
+	for _, proxy := range proxies {
+		_, err := security.ParseIPNetwork(proxy)
+		if err != nil {
+			return errInvalidTrustedProxy
+		}
+	}
+	return nil
+}
@@ -1,20 +1,28 @@
-// +build !windows
-
-package cli
-
-const (
-	defaultBindAddress     = ":9000"
-	defaultDataDirectory   = "/data"
-	defaultAssetsDirectory = "./"
-	defaultNoAuth          = "false"
-	defaultNoAnalytics     = "false"
-	defaultTLS             = "false"
-	defaultTLSSkipVerify   = "false"
-	defaultTLSCACertPath   = "/certs/ca.pem"
-	defaultTLSCertPath     = "/certs/cert.pem"
-	defaultTLSKeyPath      = "/certs/key.pem"
-	defaultSSL             = "false"
-	defaultSSLCertPath     = "/certs/chainid.crt"
-	defaultSSLKeyPath      = "/certs/chainid.key"
-	defaultSyncInterval    = "60s"
-)
+// +build !windows
+
+package cli
+
+const (
+	defaultBindAddress           = ":9000"
+	defaultDataDirectory         = "/data"
+	defaultAssetsDirectory       = "./"
+	defaultNoAuth                = "false"
+	defaultNoAnalytics           = "false"
+	defaultTLS                   = "false"
+	defaultTLSSkipVerify         = "false"
+	defaultTLSCACertPath         = "/certs/ca.pem"
+	defaultTLSCertPath           = "/certs/cert.pem"
+	defaultTLSKeyPath            = "/certs/key.pem"
+	defaultSSL                   = "false"
+	defaultSSLCertPath           = "/certs/chainid.crt"
+	defaultSSLKeyPath            = "/certs/chainid.key"
+	defaultHSTSMaxAge            = "0s"
+	defaultACMEDirectory         = "https://acme-v02.api.letsencrypt.org/directory"
+	defaultSyncInterval          = "60s"
+	defaultHealthCheckInterval   = "30s"
+	defaultSnapshotInterval      = "5m"
+	defaultDatastore             = "bolt"
+	defaultDatastoreEndpoint     = "http://127.0.0.1:8500"
+	defaultDatastorePrefix       = "chainid"
+	defaultExternalEndpointsMode = "replace"
+)
@@ -1,18 +1,26 @@
-package cli
-
-const (
-	defaultBindAddress     = ":9000"
-	defaultDataDirectory   = "C:\\data"
-	defaultAssetsDirectory = "./"
-	defaultNoAuth          = "false"
-	defaultNoAnalytics     = "false"
-	defaultTLS             = "false"
-	defaultTLSSkipVerify   = "false"
-	defaultTLSCACertPath   = "C:\\certs\\ca.pem"
-	defaultTLSCertPath     = "C:\\certs\\cert.pem"
-	defaultTLSKeyPath      = "C:\\certs\\key.pem"
-	defaultSSL             = "false"
-	defaultSSLCertPath     = "C:\\certs\\chainid.crt"
-	defaultSSLKeyPath      = "C:\\certs\\chainid.key"
-	defaultSyncInterval    = "60s"
-)
+package cli
+
+const (
+	defaultBindAddress           = ":9000"
+	defaultDataDirectory         = "C:\\data"
+	defaultAssetsDirectory       = "./"
+	defaultNoAuth                = "false"
+	defaultNoAnalytics           = "false"
+	defaultTLS                   = "false"
+	defaultTLSSkipVerify         = "false"
+	defaultTLSCACertPath         = "C:\\certs\\ca.pem"
+	defaultTLSCertPath           = "C:\\certs\\cert.pem"
+	defaultTLSKeyPath            = "C:\\certs\\key.pem"
+	defaultSSL                   = "false"
+	defaultSSLCertPath           = "C:\\certs\\chainid.crt"
+	defaultSSLKeyPath            = "C:\\certs\\chainid.key"
+	defaultHSTSMaxAge            = "0s"
+	defaultACMEDirectory         = "https://acme-v02.api.letsencrypt.org/directory"
+	defaultSyncInterval          = "60s"
+	defaultHealthCheckInterval   = "30s"
+	defaultSnapshotInterval      = "5m"
+	defaultDatastore             = "bolt"
+	defaultDatastoreEndpoint     = "http://127.0.0.1:8500"
+	defaultDatastorePrefix       = "chainid"
+	defaultExternalEndpointsMode = "replace"
+)
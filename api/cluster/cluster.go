@@ -0,0 +1,447 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/kv"
+)
+
+const (
+	membersPrefix = "cluster/members/"
+	leaderKey     = "cluster/leader"
+	locksPrefix   = "cluster/locks/"
+	keysPrefix    = "cluster/keys/"
+	// encryptedKeysPrefix stores the shared keys encrypted with the data key of the datastore,
+	// the keys stored under keysPrefix are in plain text.
+	encryptedKeysPrefix = "cluster/encrypted_keys/"
+
+	defaultHeartbeatInterval = 5 * time.Second
+	defaultLockTTL           = 10 * time.Minute
+	defaultLockTimeout       = 10 * time.Minute
+	defaultLockRetryInterval = 500 * time.Millisecond
+)
+
+type (
+	// Service implements chainid.ClusterService on top of a key/value backend.
+	// Every member refreshes its registration and tries to become or remain the
+	// leader at each heartbeat. A member is considered gone and its leadership
+	// can be taken over when it has not been seen for three heartbeats.
+	Service struct {
+		mu                sync.Mutex
+		logger            *log.Logger
+		backend           kv.Backend
+		cipher            chainid.EncryptionService
+		member            chainid.ClusterMember
+		leader            bool
+		locks             map[string]*sync.Mutex
+		renewals          map[string]*lockRenewal
+		heartbeatInterval time.Duration
+		lockTTL           time.Duration
+		lockTimeout       time.Duration
+		lockRetryInterval time.Duration
+		stop              chan struct{}
+		done              chan struct{}
+	}
+
+	// lockRenewal represents the goroutine renewing the lease of a lock held by the member.
+	// cancel cancels the context returned to the holder of the lock.
+	lockRenewal struct {
+		stop   chan struct{}
+		done   chan struct{}
+		cancel context.CancelFunc
+	}
+
+	// lease represents the ownership of the leadership or of a lock until it expires.
+	// Expires is a Unix time in nanoseconds.
+	lease struct {
+		Owner   string `json:"Owner"`
+		Expires int64  `json:"Expires"`
+	}
+)
+
+// NewService initializes a new service for the member identified by ID. When cipher is not nil,
+// the shared keys are encrypted with it before being stored in the backend.
+func NewService(backend kv.Backend, ID, address string, cipher chainid.EncryptionService) *Service {
+	return &Service{
+		logger:            log.New(os.Stderr, "", log.LstdFlags),
+		backend:           backend,
+		cipher:            cipher,
+		member:            chainid.ClusterMember{ID: ID, Address: address},
+		locks:             make(map[string]*sync.Mutex),
+		renewals:          make(map[string]*lockRenewal),
+		heartbeatInterval: defaultHeartbeatInterval,
+		lockTTL:           defaultLockTTL,
+		lockTimeout:       defaultLockTimeout,
+		lockRetryInterval: defaultLockRetryInterval,
+	}
+}
+
+// Start registers the member and starts the heartbeat. The first election is run
+// before returning so that IsLeader is accurate as soon as the service is started.
+func (service *Service) Start() error {
+	err := service.heartbeat()
+	if err != nil {
+		return err
+	}
+
+	service.stop = make(chan struct{})
+	service.done = make(chan struct{})
+	go service.run()
+	return nil
+}
+
+// Stop stops the heartbeat, releases the leadership and unregisters the member.
+func (service *Service) Stop() error {
+	if service.stop != nil {
+		close(service.stop)
+		<-service.done
+		service.stop = nil
+	}
+
+	service.mu.Lock()
+	leader := service.leader
+	service.leader = false
+	service.mu.Unlock()
+
+	if leader {
+		err := service.release(leaderKey)
+		if err != nil {
+			return err
+		}
+	}
+	return service.backend.Delete(membersPrefix + service.member.ID)
+}
+
+func (service *Service) run() {
+	defer close(service.done)
+
+	ticker := time.NewTicker(service.heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := service.heartbeat()
+			if err != nil {
+				service.logger.Printf("Cluster heartbeat error: %s", err)
+			}
+		case <-service.stop:
+			return
+		}
+	}
+}
+
+// memberTTL returns the duration after which a member that has not been seen is considered gone.
+func (service *Service) memberTTL() time.Duration {
+	return 3 * service.heartbeatInterval
+}
+
+// heartbeat refreshes the registration of the member and runs the election.
+func (service *Service) heartbeat() error {
+	member := service.member
+	member.LastSeen = time.Now().Unix()
+
+	data, err := json.Marshal(member)
+	if err != nil {
+		return err
+	}
+
+	err = service.backend.Put(membersPrefix+member.ID, data)
+	if err != nil {
+		return err
+	}
+
+	leader, err := service.acquire(leaderKey, service.memberTTL())
+	service.mu.Lock()
+	if service.leader != leader {
+		if leader {
+			service.logger.Printf("Cluster member %s elected as leader", member.ID)
+		} else {
+			service.logger.Printf("Cluster member %s is no longer the leader", member.ID)
+		}
+	}
+	service.leader = leader
+	service.mu.Unlock()
+	return err
+}
+
+// acquire takes or renews the lease stored under key. It returns false if the
+// lease is owned by another member and has not expired.
+func (service *Service) acquire(key string, ttl time.Duration) (bool, error) {
+	current, err := service.backend.Get(key)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	if current != nil {
+		var existing lease
+		err = json.Unmarshal(current, &existing)
+		if err != nil {
+			return false, err
+		}
+
+		if existing.Owner != service.member.ID && existing.Expires > now.UnixNano() {
+			return false, nil
+		}
+	}
+
+	data, err := json.Marshal(&lease{Owner: service.member.ID, Expires: now.Add(ttl).UnixNano()})
+	if err != nil {
+		return false, err
+	}
+	return service.backend.CompareAndSwap(key, current, data)
+}
+
+// release expires the lease stored under key if it is owned by the member.
+func (service *Service) release(key string) error {
+	current, err := service.backend.Get(key)
+	if err != nil || current == nil {
+		return err
+	}
+
+	var existing lease
+	err = json.Unmarshal(current, &existing)
+	if err != nil {
+		return err
+	}
+	if existing.Owner != service.member.ID {
+		return nil
+	}
+
+	data, err := json.Marshal(&lease{})
+	if err != nil {
+		return err
+	}
+	_, err = service.backend.CompareAndSwap(key, current, data)
+	return err
+}
+
+// IsLeader returns true if the member is in charge of running the scheduled jobs.
+func (service *Service) IsLeader() bool {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+	return service.leader
+}
+
+// Status returns the members that have been seen recently and the current leader.
+func (service *Service) Status() (*chainid.ClusterStatus, error) {
+	entries, err := service.backend.List(membersPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &chainid.ClusterStatus{
+		MemberID: service.member.ID,
+		Members:  make([]chainid.ClusterMember, 0),
+	}
+
+	expiration := time.Now().Add(-service.memberTTL()).Unix()
+	for _, entry := range entries {
+		var member chainid.ClusterMember
+		err = json.Unmarshal(entry.Value, &member)
+		if err != nil {
+			return nil, err
+		}
+
+		if member.LastSeen >= expiration {
+			status.Members = append(status.Members, member)
+		}
+	}
+
+	data, err := service.backend.Get(leaderKey)
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		var leader lease
+		err = json.Unmarshal(data, &leader)
+		if err != nil {
+			return nil, err
+		}
+		if leader.Expires > time.Now().UnixNano() {
+			status.Leader = leader.Owner
+		}
+	}
+
+	return status, nil
+}
+
+// localLock returns the mutex serializing the requests of the member for the lock name.
+func (service *Service) localLock(name string) *sync.Mutex {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	lock, ok := service.locks[name]
+	if !ok {
+		lock = &sync.Mutex{}
+		service.locks[name] = lock
+	}
+	return lock
+}
+
+// Lock acquires the lock name for the member, waiting for the other members to release it.
+// The lease of the lock is renewed until it is released, so that a lock held by a member that
+// stopped unexpectedly expires after 10 minutes without blocking the other members.
+// The returned context is cancelled when the lock is released or when its lease cannot be
+// renewed, the holder must then stop the operation protected by the lock.
+// It returns chainid.ErrClusterLockTimeout if the lock cannot be acquired within 10 minutes.
+func (service *Service) Lock(name string) (context.Context, error) {
+	lock := service.localLock(name)
+	lock.Lock()
+
+	deadline := time.Now().Add(service.lockTimeout)
+	for {
+		acquired, err := service.acquire(locksPrefix+name, service.lockTTL)
+		if err != nil {
+			lock.Unlock()
+			return nil, err
+		}
+		if acquired {
+			ctx, cancel := context.WithCancel(context.Background())
+			service.startLockRenewal(name, cancel)
+			return ctx, nil
+		}
+
+		if time.Now().After(deadline) {
+			lock.Unlock()
+			return nil, chainid.ErrClusterLockTimeout
+		}
+		time.Sleep(service.lockRetryInterval)
+	}
+}
+
+// Unlock releases the lock name acquired with Lock.
+func (service *Service) Unlock(name string) error {
+	service.stopLockRenewal(name)
+	err := service.release(locksPrefix + name)
+	service.localLock(name).Unlock()
+	return err
+}
+
+// startLockRenewal renews the lease of the lock name every third of its time to live
+// until stopLockRenewal is called. When the lease cannot be renewed, the lock is considered
+// lost and cancel is called so that the holder stops relying on it.
+func (service *Service) startLockRenewal(name string, cancel context.CancelFunc) {
+	renewal := &lockRenewal{stop: make(chan struct{}), done: make(chan struct{}), cancel: cancel}
+	service.mu.Lock()
+	service.renewals[name] = renewal
+	service.mu.Unlock()
+
+	go func() {
+		defer close(renewal.done)
+
+		ticker := time.NewTicker(service.lockTTL / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				renewed, err := service.acquire(locksPrefix+name, service.lockTTL)
+				if err != nil {
+					service.logger.Printf("Unable to renew the cluster lock %s, the lock is lost: %s", name, err)
+					cancel()
+					return
+				} else if !renewed {
+					service.logger.Printf("Cluster lock %s has been taken over by another member", name)
+					cancel()
+					return
+				}
+			case <-renewal.stop:
+				return
+			}
+		}
+	}()
+}
+
+// stopLockRenewal stops the renewal of the lease of the lock name and waits for it to return.
+func (service *Service) stopLockRenewal(name string) {
+	service.mu.Lock()
+	renewal, ok := service.renewals[name]
+	delete(service.renewals, name)
+	service.mu.Unlock()
+
+	if ok {
+		close(renewal.stop)
+		<-renewal.done
+		renewal.cancel()
+	}
+}
+
+// SharedKey returns the key name shared by all the members. The key is created
+// with generate by the first member requesting it. When the service has a cipher, the key
+// is stored encrypted and a key previously stored in plain text is encrypted.
+func (service *Service) SharedKey(name string, generate func() ([]byte, error)) ([]byte, error) {
+	if service.cipher == nil {
+		encrypted, err := service.backend.Get(encryptedKeysPrefix + name)
+		if err != nil {
+			return nil, err
+		}
+		if encrypted != nil {
+			return nil, chainid.ErrMasterKeyRequired
+		}
+		return service.sharedKey(keysPrefix+name, generate)
+	}
+
+	encrypted, err := service.sharedKey(encryptedKeysPrefix+name, func() ([]byte, error) {
+		key, err := service.backend.Get(keysPrefix + name)
+		if err != nil {
+			return nil, err
+		}
+		if key == nil {
+			key, err = generate()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		encrypted, err := service.cipher.Encrypt(string(key))
+		return []byte(encrypted), err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := service.cipher.Decrypt(string(encrypted))
+	if err != nil {
+		return nil, err
+	}
+
+	// The key stored in plain text before the data key was created is no longer used.
+	err = service.backend.Delete(keysPrefix + name)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(key), nil
+}
+
+// sharedKey returns the value stored under key, the value is created with generate
+// by the first member requesting it.
+func (service *Service) sharedKey(key string, generate func() ([]byte, error)) ([]byte, error) {
+	value, err := service.backend.Get(key)
+	if err != nil || value != nil {
+		return value, err
+	}
+
+	value, err = generate()
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := service.backend.CompareAndSwap(key, nil, value)
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		return value, nil
+	}
+
+	// Another member created the key in the meantime.
+	return service.backend.Get(key)
+}
@@ -0,0 +1,254 @@
+package cluster
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/crypto"
+	"github.com/chainid-io/dashboard/kv"
+)
+
+func newTestService(backend kv.Backend, ID string) *Service {
+	service := NewService(backend, ID, ID+":9000", nil)
+	service.lockTimeout = 100 * time.Millisecond
+	service.lockRetryInterval = 10 * time.Millisecond
+	return service
+}
+
+func TestLeaderElection(t *testing.T) {
+	backend := kv.NewMemoryBackend()
+	first := newTestService(backend, "first")
+	second := newTestService(backend, "second")
+
+	err := first.heartbeat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = second.heartbeat()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !first.IsLeader() || second.IsLeader() {
+		t.Fatalf("expected only the first member to be the leader, got %v and %v", first.IsLeader(), second.IsLeader())
+	}
+
+	status, err := second.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Leader != "first" || status.MemberID != "second" || len(status.Members) != 2 {
+		t.Fatalf("unexpected cluster status: %+v", status)
+	}
+
+	err = first.Stop()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = second.heartbeat()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !second.IsLeader() {
+		t.Fatal("expected the second member to take over the leadership")
+	}
+
+	status, err = second.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Leader != "second" || len(status.Members) != 1 {
+		t.Fatalf("unexpected cluster status after the first member stopped: %+v", status)
+	}
+}
+
+func TestLock(t *testing.T) {
+	backend := kv.NewMemoryBackend()
+	first := newTestService(backend, "first")
+	second := newTestService(backend, "second")
+
+	ctx, err := first.Lock("stack_deployment")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = second.Lock("stack_deployment"); err != chainid.ErrClusterLockTimeout {
+		t.Fatalf("expected %v while the lock is held by another member, got %v", chainid.ErrClusterLockTimeout, err)
+	}
+
+	acquired := make(chan error)
+	go func() {
+		_, err := second.Lock("stack_deployment")
+		acquired <- err
+	}()
+
+	err = first.Unlock("stack_deployment")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx.Err() == nil {
+		t.Error("expected the context of the lock to be done once released")
+	}
+
+	if err = <-acquired; err != nil {
+		t.Fatalf("expected the lock to be acquired once released, got %v", err)
+	}
+
+	err = second.Unlock("stack_deployment")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLockRenewal(t *testing.T) {
+	backend := kv.NewMemoryBackend()
+	first := newTestService(backend, "first")
+	first.lockTTL = 30 * time.Millisecond
+	second := newTestService(backend, "second")
+
+	ctx, err := first.Lock("stack_deployment")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The lock is held for several times its time to live.
+	if _, err = second.Lock("stack_deployment"); err != chainid.ErrClusterLockTimeout {
+		t.Fatalf("expected the lease of the lock to be renewed while it is held, got %v", err)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("expected the context of the lock to remain active while the lease is renewed")
+	}
+
+	err = first.Unlock("stack_deployment")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = second.Lock("stack_deployment")
+	if err != nil {
+		t.Fatalf("expected the lock to be acquired once released, got %v", err)
+	}
+	err = second.Unlock("stack_deployment")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLockLost(t *testing.T) {
+	backend := kv.NewMemoryBackend()
+	first := newTestService(backend, "first")
+	first.lockTTL = 30 * time.Millisecond
+
+	ctx, err := first.Lock("stack_deployment")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Another member takes over the lock, e.g. after the lease expired during a network partition.
+	data, err := json.Marshal(&lease{Owner: "second", Expires: time.Now().Add(time.Minute).UnixNano()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = backend.Put(locksPrefix+"stack_deployment", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected the context of the lock to be cancelled once the lock is lost")
+	}
+
+	err = first.Unlock("stack_deployment")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSharedKey(t *testing.T) {
+	backend := kv.NewMemoryBackend()
+	first := newTestService(backend, "first")
+	second := newTestService(backend, "second")
+
+	generated := 0
+	generate := func() ([]byte, error) {
+		generated++
+		return []byte{byte(generated)}, nil
+	}
+
+	firstKey, err := first.SharedKey("jwt_secret", generate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secondKey, err := second.SharedKey("jwt_secret", generate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if generated != 1 || !bytes.Equal(firstKey, secondKey) {
+		t.Fatalf("expected the key to be generated once and shared, got %v and %v", firstKey, secondKey)
+	}
+}
+
+func TestEncryptedSharedKey(t *testing.T) {
+	backend := kv.NewMemoryBackend()
+	cipher := &crypto.AESService{}
+	dataKey, err := cipher.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cipher.SetKey(dataKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The key has been created in plain text before a master key was specified.
+	plain := newTestService(backend, "plain")
+	plainKey, err := plain.SharedKey("jwt_secret", func() ([]byte, error) { return []byte("secret"), nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := newTestService(backend, "first")
+	first.cipher = cipher
+	second := newTestService(backend, "second")
+	second.cipher = cipher
+
+	firstKey, err := first.SharedKey("jwt_secret", func() ([]byte, error) { return nil, errors.New("unexpected generation") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	secondKey, err := second.SharedKey("jwt_secret", func() ([]byte, error) { return nil, errors.New("unexpected generation") })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(firstKey, plainKey) || !bytes.Equal(secondKey, plainKey) {
+		t.Fatalf("expected the key stored in plain text to be kept, got %q and %q", firstKey, secondKey)
+	}
+
+	stored, err := backend.Get(keysPrefix + "jwt_secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored != nil {
+		t.Errorf("expected the key stored in plain text to be removed, got %q", stored)
+	}
+	stored, err = backend.Get(encryptedKeysPrefix + "jwt_secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored == nil || bytes.Contains(stored, plainKey) {
+		t.Errorf("expected the key to be stored encrypted, got %q", stored)
+	}
+
+	_, err = plain.SharedKey("jwt_secret", func() ([]byte, error) { return []byte("other"), nil })
+	if err != chainid.ErrMasterKeyRequired {
+		t.Errorf("expected %v without a cipher, got %v", chainid.ErrMasterKeyRequired, err)
+	}
+}
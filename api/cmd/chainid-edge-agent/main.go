@@ -0,0 +1,52 @@
+package main
+
+import (
+	"crypto/tls"
+	"log"
+	"net"
+	"os"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/tunnel"
+	"github.com/gorilla/websocket"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// chainid-edge-agent opens the tunnel of an edge endpoint to the dashboard and forwards
+// the Docker API requests of the dashboard to the local Docker engine.
+func main() {
+	url := kingpin.Flag("url", "Websocket URL of the tunnel endpoint of the dashboard (e.g. wss://dashboard.example.com/api/tunnel)").Required().String()
+	joinToken := kingpin.Flag("token", "Join token of the edge endpoint").Envar("CHAINID_EDGE_TOKEN").Required().String()
+	dockerSocket := kingpin.Flag("docker-socket", "Path to the Docker socket").Default("/var/run/docker.sock").String()
+	tlsSkipVerify := kingpin.Flag("tlsskipverify", "Disable TLS server verification when connecting to the dashboard").Default("false").Bool()
+	retryDelay := kingpin.Flag("retry-delay", "Delay before opening the tunnel again when it is closed").Default("10s").Duration()
+	kingpin.Parse()
+
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+
+	agent := &tunnel.Agent{
+		URL:       *url,
+		JoinToken: *joinToken,
+		DialDocker: func() (net.Conn, error) {
+			return net.Dial("unix", *dockerSocket)
+		},
+		Dialer: &websocket.Dialer{
+			HandshakeTimeout: 30 * time.Second,
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: *tlsSkipVerify,
+			},
+		},
+		Logger: logger,
+	}
+
+	for {
+		err := agent.Connect()
+		if err == chainid.ErrInvalidEdgeJoinToken {
+			logger.Fatal(err)
+		}
+		logger.Printf("Tunnel closed: %s, retrying in %s", err, *retryDelay)
+		time.Sleep(*retryDelay)
+	}
+}
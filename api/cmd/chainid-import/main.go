@@ -0,0 +1,80 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"strings"
+
+	"github.com/chainid-io/dashboard/bolt"
+	"github.com/chainid-io/dashboard/kv"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// chainid-import copies the content of a BoltDB database to a key/value datastore.
+// The database is migrated to the current version before the copy. When a master key
+// is specified, the secrets are encrypted in the datastore with a new data key, itself
+// encrypted with the master key.
+func main() {
+	data := kingpin.Flag("data", "Path to the folder where the BoltDB database is stored").Default("/data").Short('d').String()
+	masterKeyFile := kingpin.Flag("master-key-file", "Path to the file containing the master key used to encrypt the secrets stored in the database").String()
+	endpoint := kingpin.Flag("datastore-endpoint", "Address of the datastore server").Default("http://127.0.0.1:8500").String()
+	prefix := kingpin.Flag("datastore-prefix", "Prefix of the keys stored in the datastore").Default("chainid").String()
+	token := kingpin.Flag("datastore-token", "Access token used to connect to the datastore").Envar("CHAINID_DATASTORE_TOKEN").String()
+	kingpin.Parse()
+
+	var masterKey []byte
+	if *masterKeyFile != "" {
+		content, err := ioutil.ReadFile(*masterKeyFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		masterKey = []byte(strings.TrimSpace(string(content)))
+	}
+
+	source, err := bolt.NewStore(*data)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = source.Open()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer source.Close()
+
+	err = source.InitEncryption(masterKey, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = source.MigrateData()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	backend, err := kv.NewConsulBackend(*endpoint, *prefix, *token)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	store := kv.NewStore(backend)
+	defer store.Close()
+
+	err = store.Open()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = store.InitEncryption(masterKey, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = store.Import(source)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("Data imported from %s to %s", *data, *endpoint)
+}
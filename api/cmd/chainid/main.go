@@ -1,414 +1,843 @@
-package main // import "github.com/chainid-io/dashboard"
-
-import (
-	"strings"
-
-	"github.com/chainid-io/dashboard"
-	"github.com/chainid-io/dashboard/bolt"
-	"github.com/chainid-io/dashboard/cli"
-	"github.com/chainid-io/dashboard/cron"
-	"github.com/chainid-io/dashboard/crypto"
-	"github.com/chainid-io/dashboard/exec"
-	"github.com/chainid-io/dashboard/filesystem"
-	"github.com/chainid-io/dashboard/git"
-	"github.com/chainid-io/dashboard/http"
-	"github.com/chainid-io/dashboard/http/client"
-	"github.com/chainid-io/dashboard/jwt"
-	"github.com/chainid-io/dashboard/ldap"
-
-	"log"
-)
-
-func initCLI() *chainid.CLIFlags {
-	var cli chainid.CLIService = &cli.Service{}
-	flags, err := cli.ParseFlags(chainid.APIVersion)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	err = cli.ValidateFlags(flags)
-	if err != nil {
-		log.Fatal(err)
-	}
-	return flags
-}
-
-func initFileService(dataStorePath string) chainid.FileService {
-	fileService, err := filesystem.NewService(dataStorePath, "")
-	if err != nil {
-		log.Fatal(err)
-	}
-	return fileService
-}
-
-func initStore(dataStorePath string) *bolt.Store {
-	store, err := bolt.NewStore(dataStorePath)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	err = store.Open()
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	err = store.Init()
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	err = store.MigrateData()
-	if err != nil {
-		log.Fatal(err)
-	}
-	return store
-}
-
-func initStackManager(assetsPath string, dataStorePath string, signatureService chainid.DigitalSignatureService, fileService chainid.FileService) (chainid.StackManager, error) {
-	return exec.NewStackManager(assetsPath, dataStorePath, signatureService, fileService)
-}
-
-func initJWTService(authenticationEnabled bool) chainid.JWTService {
-	if authenticationEnabled {
-		jwtService, err := jwt.NewService()
-		if err != nil {
-			log.Fatal(err)
-		}
-		return jwtService
-	}
-	return nil
-}
-
-func initDigitalSignatureService() chainid.DigitalSignatureService {
-	return &crypto.ECDSAService{}
-}
-
-func initCryptoService() chainid.CryptoService {
-	return &crypto.Service{}
-}
-
-func initLDAPService() chainid.LDAPService {
-	return &ldap.Service{}
-}
-
-func initGitService() chainid.GitService {
-	return &git.Service{}
-}
-
-func initEndpointWatcher(endpointService chainid.EndpointService, externalEnpointFile string, syncInterval string) bool {
-	authorizeEndpointMgmt := true
-	if externalEnpointFile != "" {
-		authorizeEndpointMgmt = false
-		log.Println("Using external endpoint definition. Endpoint management via the API will be disabled.")
-		endpointWatcher := cron.NewWatcher(endpointService, syncInterval)
-		err := endpointWatcher.WatchEndpointFile(externalEnpointFile)
-		if err != nil {
-			log.Fatal(err)
-		}
-	}
-	return authorizeEndpointMgmt
-}
-
-func initStatus(authorizeEndpointMgmt bool, flags *chainid.CLIFlags) *chainid.Status {
-	return &chainid.Status{
-		Analytics:          !*flags.NoAnalytics,
-		Authentication:     !*flags.NoAuth,
-		EndpointManagement: authorizeEndpointMgmt,
-		Version:            chainid.APIVersion,
-	}
-}
-
-func initDockerHub(dockerHubService chainid.DockerHubService) error {
-	_, err := dockerHubService.DockerHub()
-	if err == chainid.ErrDockerHubNotFound {
-		dockerhub := &chainid.DockerHub{
-			Authentication: false,
-			Username:       "",
-			Password:       "",
-		}
-		return dockerHubService.StoreDockerHub(dockerhub)
-	} else if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func initSettings(settingsService chainid.SettingsService, flags *chainid.CLIFlags) error {
-	_, err := settingsService.Settings()
-	if err == chainid.ErrSettingsNotFound {
-		settings := &chainid.Settings{
-			LogoURL:                     *flags.Logo,
-			DisplayExternalContributors: false,
-			AuthenticationMethod:        chainid.AuthenticationInternal,
-			LDAPSettings: chainid.LDAPSettings{
-				TLSConfig: chainid.TLSConfiguration{},
-				SearchSettings: []chainid.LDAPSearchSettings{
-					chainid.LDAPSearchSettings{},
-				},
-			},
-			AllowBindMountsForRegularUsers:     true,
-			AllowPrivilegedModeForRegularUsers: true,
-		}
-
-		if *flags.Templates != "" {
-			settings.TemplatesURL = *flags.Templates
-		} else {
-			settings.TemplatesURL = chainid.DefaultTemplatesURL
-		}
-
-		if *flags.Labels != nil {
-			settings.BlackListedLabels = *flags.Labels
-		} else {
-			settings.BlackListedLabels = make([]chainid.Pair, 0)
-		}
-
-		return settingsService.StoreSettings(settings)
-	} else if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func retrieveFirstEndpointFromDatabase(endpointService chainid.EndpointService) *chainid.Endpoint {
-	endpoints, err := endpointService.Endpoints()
-	if err != nil {
-		log.Fatal(err)
-	}
-	return &endpoints[0]
-}
-
-func loadAndParseKeyPair(fileService chainid.FileService, signatureService chainid.DigitalSignatureService) error {
-	private, public, err := fileService.LoadKeyPair()
-	if err != nil {
-		return err
-	}
-	return signatureService.ParseKeyPair(private, public)
-}
-
-func generateAndStoreKeyPair(fileService chainid.FileService, signatureService chainid.DigitalSignatureService) error {
-	private, public, err := signatureService.GenerateKeyPair()
-	if err != nil {
-		return err
-	}
-	privateHeader, publicHeader := signatureService.PEMHeaders()
-	return fileService.StoreKeyPair(private, public, privateHeader, publicHeader)
-}
-
-func initKeyPair(fileService chainid.FileService, signatureService chainid.DigitalSignatureService) error {
-	existingKeyPair, err := fileService.KeyPairFilesExist()
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	if existingKeyPair {
-		return loadAndParseKeyPair(fileService, signatureService)
-	}
-	return generateAndStoreKeyPair(fileService, signatureService)
-}
-
-func createTLSSecuredEndpoint(flags *chainid.CLIFlags, endpointService chainid.EndpointService) error {
-	tlsConfiguration := chainid.TLSConfiguration{
-		TLS:           *flags.TLS,
-		TLSSkipVerify: *flags.TLSSkipVerify,
-	}
-
-	if *flags.TLS {
-		tlsConfiguration.TLSCACertPath = *flags.TLSCacert
-		tlsConfiguration.TLSCertPath = *flags.TLSCert
-		tlsConfiguration.TLSKeyPath = *flags.TLSKey
-	} else if !*flags.TLS && *flags.TLSSkipVerify {
-		tlsConfiguration.TLS = true
-	}
-
-	endpoint := &chainid.Endpoint{
-		Name:            "primary",
-		URL:             *flags.EndpointURL,
-		GroupID:         chainid.EndpointGroupID(1),
-		Type:            chainid.DockerEnvironment,
-		TLSConfig:       tlsConfiguration,
-		AuthorizedUsers: []chainid.UserID{},
-		AuthorizedTeams: []chainid.TeamID{},
-		Extensions:      []chainid.EndpointExtension{},
-	}
-
-	if strings.HasPrefix(endpoint.URL, "tcp://") {
-		tlsConfig, err := crypto.CreateTLSConfigurationFromDisk(tlsConfiguration.TLSCACertPath, tlsConfiguration.TLSCertPath, tlsConfiguration.TLSKeyPath, tlsConfiguration.TLSSkipVerify)
-		if err != nil {
-			return err
-		}
-
-		agentOnDockerEnvironment, err := client.ExecutePingOperation(endpoint.URL, tlsConfig)
-		if err != nil {
-			return err
-		}
-
-		if agentOnDockerEnvironment {
-			endpoint.Type = chainid.AgentOnDockerEnvironment
-		}
-	}
-
-	return endpointService.CreateEndpoint(endpoint)
-}
-
-func createUnsecuredEndpoint(endpointURL string, endpointService chainid.EndpointService) error {
-	if strings.HasPrefix(endpointURL, "tcp://") {
-		_, err := client.ExecutePingOperation(endpointURL, nil)
-		if err != nil {
-			return err
-		}
-	}
-
-	endpoint := &chainid.Endpoint{
-		Name:            "primary",
-		URL:             endpointURL,
-		GroupID:         chainid.EndpointGroupID(1),
-		Type:            chainid.DockerEnvironment,
-		TLSConfig:       chainid.TLSConfiguration{},
-		AuthorizedUsers: []chainid.UserID{},
-		AuthorizedTeams: []chainid.TeamID{},
-		Extensions:      []chainid.EndpointExtension{},
-	}
-
-	return endpointService.CreateEndpoint(endpoint)
-}
-
-func initEndpoint(flags *chainid.CLIFlags, endpointService chainid.EndpointService) error {
-	if *flags.EndpointURL == "" {
-		return nil
-	}
-
-	endpoints, err := endpointService.Endpoints()
-	if err != nil {
-		return err
-	}
-
-	if len(endpoints) > 0 {
-		log.Println("Instance already has defined endpoints. Skipping the endpoint defined via CLI.")
-		return nil
-	}
-
-	if *flags.TLS || *flags.TLSSkipVerify {
-		return createTLSSecuredEndpoint(flags, endpointService)
-	}
-	return createUnsecuredEndpoint(*flags.EndpointURL, endpointService)
-}
-
-func main() {
-	flags := initCLI()
-
-	fileService := initFileService(*flags.Data)
-
-	store := initStore(*flags.Data)
-	defer store.Close()
-
-	jwtService := initJWTService(!*flags.NoAuth)
-
-	cryptoService := initCryptoService()
-
-	digitalSignatureService := initDigitalSignatureService()
-
-	ldapService := initLDAPService()
-
-	gitService := initGitService()
-
-	authorizeEndpointMgmt := initEndpointWatcher(store.EndpointService, *flags.ExternalEndpoints, *flags.SyncInterval)
-
-	err := initKeyPair(fileService, digitalSignatureService)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	stackManager, err := initStackManager(*flags.Assets, *flags.Data, digitalSignatureService, fileService)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	err = initSettings(store.SettingsService, flags)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	err = initDockerHub(store.DockerHubService)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	applicationStatus := initStatus(authorizeEndpointMgmt, flags)
-
-	err = initEndpoint(flags, store.EndpointService)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	adminPasswordHash := ""
-	if *flags.AdminPasswordFile != "" {
-		content, err := fileService.GetFileContent(*flags.AdminPasswordFile)
-		if err != nil {
-			log.Fatal(err)
-		}
-		adminPasswordHash, err = cryptoService.Hash(content)
-		if err != nil {
-			log.Fatal(err)
-		}
-	} else if *flags.AdminPassword != "" {
-		adminPasswordHash = *flags.AdminPassword
-	}
-
-	if adminPasswordHash != "" {
-		users, err := store.UserService.UsersByRole(chainid.AdministratorRole)
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		if len(users) == 0 {
-			log.Printf("Creating admin user with password hash %s", adminPasswordHash)
-			user := &chainid.User{
-				Username: "admin",
-				Role:     chainid.AdministratorRole,
-				Password: adminPasswordHash,
-			}
-			err := store.UserService.CreateUser(user)
-			if err != nil {
-				log.Fatal(err)
-			}
-		} else {
-			log.Println("Instance already has an administrator user defined. Skipping admin password related flags.")
-		}
-	}
-
-	var server chainid.Server = &http.Server{
-		Status:                 applicationStatus,
-		BindAddress:            *flags.Addr,
-		AssetsPath:             *flags.Assets,
-		AuthDisabled:           *flags.NoAuth,
-		EndpointManagement:     authorizeEndpointMgmt,
-		UserService:            store.UserService,
-		TeamService:            store.TeamService,
-		TeamMembershipService:  store.TeamMembershipService,
-		EndpointService:        store.EndpointService,
-		EndpointGroupService:   store.EndpointGroupService,
-		ResourceControlService: store.ResourceControlService,
-		SettingsService:        store.SettingsService,
-		RegistryService:        store.RegistryService,
-		DockerHubService:       store.DockerHubService,
-		StackService:           store.StackService,
-		StackManager:           stackManager,
-		CryptoService:          cryptoService,
-		JWTService:             jwtService,
-		FileService:            fileService,
-		LDAPService:            ldapService,
-		GitService:             gitService,
-		SignatureService:       digitalSignatureService,
-		SSL:                    *flags.SSL,
-		SSLCert:                *flags.SSLCert,
-		SSLKey:                 *flags.SSLKey,
-	}
-
-	log.Printf("Starting Chain Platform %s on %s", chainid.APIVersion, *flags.Addr)
-	err = server.Start()
-	if err != nil {
-		log.Fatal(err)
-	}
-}
+package main // import "github.com/chainid-io/dashboard"
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/backup"
+	"github.com/chainid-io/dashboard/bolt"
+	"github.com/chainid-io/dashboard/certificate"
+	"github.com/chainid-io/dashboard/cli"
+	"github.com/chainid-io/dashboard/cluster"
+	"github.com/chainid-io/dashboard/config"
+	"github.com/chainid-io/dashboard/cron"
+	"github.com/chainid-io/dashboard/crypto"
+	"github.com/chainid-io/dashboard/discovery"
+	"github.com/chainid-io/dashboard/exec"
+	"github.com/chainid-io/dashboard/filesystem"
+	"github.com/chainid-io/dashboard/git"
+	"github.com/chainid-io/dashboard/http"
+	"github.com/chainid-io/dashboard/http/client"
+	"github.com/chainid-io/dashboard/jwt"
+	"github.com/chainid-io/dashboard/kv"
+	"github.com/chainid-io/dashboard/ldap"
+	"github.com/chainid-io/dashboard/ssh"
+
+	"log"
+)
+
+func initCLI() *chainid.CLIFlags {
+	var cli chainid.CLIService = &cli.Service{}
+	flags, err := cli.ParseFlags(chainid.APIVersion)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = cli.ValidateFlags(flags)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return flags
+}
+
+func initFileService(dataStorePath string) chainid.FileService {
+	fileService, err := filesystem.NewService(dataStorePath, "")
+	if err != nil {
+		log.Fatal(err)
+	}
+	return fileService
+}
+
+// dataStore groups the services of the datastore selected with the --datastore flag.
+type dataStore struct {
+	chainid.DataStore
+	UserService            chainid.UserService
+	TeamService            chainid.TeamService
+	TeamMembershipService  chainid.TeamMembershipService
+	EndpointService        chainid.EndpointService
+	EndpointGroupService   chainid.EndpointGroupService
+	ResourceControlService chainid.ResourceControlService
+	SettingsService        chainid.SettingsService
+	RegistryService        chainid.RegistryService
+	DockerHubService       chainid.DockerHubService
+	StackService           chainid.StackService
+	RoleService            chainid.RoleService
+	RoleAssignmentService  chainid.RoleAssignmentService
+	AuditLogService        chainid.AuditLogService
+	BackupStatusService    chainid.BackupStatusService
+	clusterBackend         kv.Backend
+	clusterCipher          chainid.EncryptionService
+}
+
+// sharedKeyPair represents the key pair shared by the members of the cluster.
+type sharedKeyPair struct {
+	Private []byte
+	Public  []byte
+}
+
+func initStore(dataStorePath string, flags *chainid.CLIFlags, masterKey, previousMasterKey []byte) *dataStore {
+	if *flags.Datastore == chainid.ConsulDataStore {
+		return initKVStore(flags, masterKey, previousMasterKey)
+	}
+
+	store := initBoltStore(dataStorePath, flags, masterKey, previousMasterKey)
+	return &dataStore{
+		DataStore:              store,
+		UserService:            store.UserService,
+		TeamService:            store.TeamService,
+		TeamMembershipService:  store.TeamMembershipService,
+		EndpointService:        store.EndpointService,
+		EndpointGroupService:   store.EndpointGroupService,
+		ResourceControlService: store.ResourceControlService,
+		SettingsService:        store.SettingsService,
+		RegistryService:        store.RegistryService,
+		DockerHubService:       store.DockerHubService,
+		StackService:           store.StackService,
+		RoleService:            store.RoleService,
+		RoleAssignmentService:  store.RoleAssignmentService,
+		AuditLogService:        store.AuditLogService,
+		BackupStatusService:    store.BackupStatusService,
+		clusterBackend:         kv.NewMemoryBackend(),
+	}
+}
+
+func initKVStore(flags *chainid.CLIFlags, masterKey, previousMasterKey []byte) *dataStore {
+	backend, err := kv.NewConsulBackend(*flags.DatastoreEndpoint, *flags.DatastorePrefix, *flags.DatastoreToken)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	store := kv.NewStore(backend)
+
+	err = store.Open()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = store.InitEncryption(masterKey, previousMasterKey)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = store.Init()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = store.MigrateData()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return &dataStore{
+		DataStore:              store,
+		UserService:            store.UserService,
+		TeamService:            store.TeamService,
+		TeamMembershipService:  store.TeamMembershipService,
+		EndpointService:        store.EndpointService,
+		EndpointGroupService:   store.EndpointGroupService,
+		ResourceControlService: store.ResourceControlService,
+		SettingsService:        store.SettingsService,
+		RegistryService:        store.RegistryService,
+		DockerHubService:       store.DockerHubService,
+		StackService:           store.StackService,
+		RoleService:            store.RoleService,
+		RoleAssignmentService:  store.RoleAssignmentService,
+		AuditLogService:        store.AuditLogService,
+		BackupStatusService:    store.BackupStatusService,
+		clusterBackend:         backend,
+		clusterCipher:          store.SecretCipher(),
+	}
+}
+
+func initBoltStore(dataStorePath string, flags *chainid.CLIFlags, masterKey, previousMasterKey []byte) *bolt.Store {
+	store, err := bolt.NewStore(dataStorePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = store.Open()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = store.InitEncryption(masterKey, previousMasterKey)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = store.Init()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = store.MigrateData()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *flags.RotateDataKey {
+		err = store.RotateDataKey()
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Println("Secrets encrypted with a new data key.")
+	}
+	return store
+}
+
+// rollbackMigration restores the copy of the database made before the last migration.
+func rollbackMigration(dataStorePath string) {
+	store, err := bolt.NewStore(dataStorePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	version, err := store.RollbackMigration()
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Database restored to version %d, start the matching version of Chain Platform to use it.", version)
+}
+
+// loadMasterKeys returns the master key used to encrypt the secrets stored in the database
+// and the previous one when it is rotated. Keys are nil when not specified.
+func loadMasterKeys(flags *chainid.CLIFlags, fileService chainid.FileService) ([]byte, []byte, error) {
+	masterKey := strings.TrimSpace(*flags.MasterKey)
+	if *flags.MasterKeyFile != "" {
+		content, err := fileService.GetFileContent(*flags.MasterKeyFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		masterKey = strings.TrimSpace(content)
+	}
+
+	if masterKey == "" {
+		return nil, nil, nil
+	}
+
+	if *flags.PreviousMasterKeyFile == "" {
+		return []byte(masterKey), nil, nil
+	}
+
+	content, err := fileService.GetFileContent(*flags.PreviousMasterKeyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	return []byte(masterKey), []byte(strings.TrimSpace(content)), nil
+}
+
+// initClusterService registers the instance as a member of the cluster formed by the
+// instances sharing the datastore. An instance using a Bolt datastore is the only member
+// of its cluster. The keys shared by the members are encrypted with cipher when it is not nil.
+func initClusterService(backend kv.Backend, cipher chainid.EncryptionService, bindAddress string) *cluster.Service {
+	hostname, err := os.Hostname()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	address := bindAddress
+	host, port, err := net.SplitHostPort(bindAddress)
+	if err == nil && host == "" {
+		address = net.JoinHostPort(hostname, port)
+	}
+
+	clusterService := cluster.NewService(backend, fmt.Sprintf("%s-%d", hostname, os.Getpid()), address, cipher)
+	err = clusterService.Start()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return clusterService
+}
+
+func initStackManager(assetsPath string, dataStorePath string, signatureService chainid.DigitalSignatureService, fileService chainid.FileService) (chainid.StackManager, error) {
+	return exec.NewStackManager(assetsPath, dataStorePath, signatureService, fileService)
+}
+
+func initJWTService(authenticationEnabled bool, clusterService chainid.ClusterService) chainid.JWTService {
+	if authenticationEnabled {
+		secret, err := clusterService.SharedKey("jwt_secret", jwt.GenerateSecret)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		jwtService, err := jwt.NewService(secret)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return jwtService
+	}
+	return nil
+}
+
+func initDigitalSignatureService() chainid.DigitalSignatureService {
+	return &crypto.ECDSAService{}
+}
+
+func initCryptoService() chainid.CryptoService {
+	return &crypto.Service{}
+}
+
+func initTOTPService() chainid.TOTPService {
+	return &crypto.TOTPService{
+		Issuer: "Chain Platform",
+	}
+}
+
+func initLDAPService() chainid.LDAPService {
+	return &ldap.Service{}
+}
+
+func initGitService() chainid.GitService {
+	return &git.Service{}
+}
+
+func initEndpointWatcher(endpointService chainid.EndpointService, endpointGroupService chainid.EndpointGroupService, clusterService chainid.ClusterService, flags *chainid.CLIFlags) bool {
+	authorizeEndpointMgmt := true
+	endpointWatcher := cron.NewWatcher(endpointService, endpointGroupService, clusterService, *flags.SyncInterval)
+
+	if *flags.ExternalEndpoints != "" {
+		merge := *flags.ExternalEndpointsMode == chainid.ExternalEndpointsMergeMode
+		if merge {
+			log.Println("Using external endpoint definition. Endpoints defined externally cannot be modified via the API.")
+		} else {
+			authorizeEndpointMgmt = false
+			log.Println("Using external endpoint definition. Endpoint management via the API will be disabled.")
+		}
+		err := endpointWatcher.WatchEndpointFile(*flags.ExternalEndpoints, merge)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	for _, source := range *flags.Discovery {
+		provider, err := discovery.NewProvider(source, *flags.DiscoveryToken)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Using endpoint discovery. Discovered endpoints cannot be modified via the API. [provider: %s]", provider.Name())
+		err = endpointWatcher.WatchEndpointDiscovery(provider)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	return authorizeEndpointMgmt
+}
+
+func initCertificateManager(flags *chainid.CLIFlags) *certificate.Manager {
+	if !*flags.SSL {
+		return nil
+	}
+
+	domains := *flags.ACMEDomains
+	certPath, keyPath := *flags.SSLCert, *flags.SSLKey
+	if len(domains) > 0 {
+		certPath = filepath.Join(*flags.Data, "tls", "acme.crt")
+		keyPath = filepath.Join(*flags.Data, "tls", "acme.key")
+	}
+	certificateManager := certificate.NewManager(certPath, keyPath)
+
+	// The certificate obtained from the ACME server replaces a self-signed certificate, which is
+	// served until the first certificate is issued.
+	if *flags.SSLSelfSigned || len(domains) > 0 {
+		hosts := domains
+		if len(hosts) == 0 {
+			hostname, err := os.Hostname()
+			if err != nil {
+				log.Fatal(err)
+			}
+			hosts = []string{hostname, "localhost", "127.0.0.1"}
+		}
+
+		generated, err := certificateManager.BootstrapSelfSigned(hosts)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if generated {
+			log.Printf("Generated a self-signed SSL certificate. [path: %s] [hosts: %v]", certPath, hosts)
+		}
+	}
+
+	err := certificateManager.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	certificateManager.Watch()
+
+	if len(domains) > 0 {
+		accountKey, err := certificate.LoadACMEAccountKey(filepath.Join(*flags.Data, "tls", "acme-account.key"))
+		if err != nil {
+			log.Fatal(err)
+		}
+		certificateManager.EnableACME(certificate.NewACMEClient(*flags.ACMEDirectory, *flags.ACMEEmail, accountKey), domains)
+	}
+	return certificateManager
+}
+
+func initBackupScheduler(backupService chainid.BackupService, backupStatusService chainid.BackupStatusService, settingsService chainid.SettingsService, clusterService chainid.ClusterService) chainid.BackupScheduler {
+	backupScheduler := cron.NewBackupScheduler(backupService, backupStatusService, clusterService)
+
+	settings, err := settingsService.Settings()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = backupScheduler.ScheduleBackups(&settings.BackupSettings)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return backupScheduler
+}
+
+func initConfigService(store *dataStore, cryptoService chainid.CryptoService, fileService chainid.FileService, backupScheduler chainid.BackupScheduler, endpointManagement bool) chainid.ConfigService {
+	return config.NewService(&config.ServiceParams{
+		UserService:            store.UserService,
+		TeamService:            store.TeamService,
+		TeamMembershipService:  store.TeamMembershipService,
+		RoleAssignmentService:  store.RoleAssignmentService,
+		EndpointService:        store.EndpointService,
+		EndpointGroupService:   store.EndpointGroupService,
+		RegistryService:        store.RegistryService,
+		ResourceControlService: store.ResourceControlService,
+		SettingsService:        store.SettingsService,
+		CryptoService:          cryptoService,
+		FileService:            fileService,
+		BackupScheduler:        backupScheduler,
+		EndpointManagement:     endpointManagement,
+	})
+}
+
+func applyConfigFile(configService chainid.ConfigService, fileService chainid.FileService, configFile string) error {
+	content, err := fileService.GetFileContent(configFile)
+	if err != nil {
+		return err
+	}
+
+	changes, err := configService.ApplyConfig([]byte(content), false)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		log.Printf("Configuration file: %s %s %s", change.Action, change.Kind, change.Name)
+	}
+	return nil
+}
+
+func initStatus(authorizeEndpointMgmt bool, flags *chainid.CLIFlags) *chainid.Status {
+	return &chainid.Status{
+		Analytics:          !*flags.NoAnalytics,
+		Authentication:     !*flags.NoAuth,
+		EndpointManagement: authorizeEndpointMgmt,
+		Version:            chainid.APIVersion,
+	}
+}
+
+func initDockerHub(dockerHubService chainid.DockerHubService) error {
+	_, err := dockerHubService.DockerHub()
+	if err == chainid.ErrDockerHubNotFound {
+		dockerhub := &chainid.DockerHub{
+			Authentication: false,
+			Username:       "",
+			Password:       "",
+		}
+		return dockerHubService.StoreDockerHub(dockerhub)
+	} else if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func initSettings(settingsService chainid.SettingsService, flags *chainid.CLIFlags) error {
+	_, err := settingsService.Settings()
+	if err == chainid.ErrSettingsNotFound {
+		settings := &chainid.Settings{
+			LogoURL:                     *flags.Logo,
+			DisplayExternalContributors: false,
+			AuthenticationMethod:        chainid.AuthenticationInternal,
+			LDAPSettings: chainid.LDAPSettings{
+				TLSConfig: chainid.TLSConfiguration{},
+				SearchSettings: []chainid.LDAPSearchSettings{
+					chainid.LDAPSearchSettings{},
+				},
+			},
+			AllowBindMountsForRegularUsers:     true,
+			AllowPrivilegedModeForRegularUsers: true,
+			LockoutSettings: chainid.LockoutSettings{
+				MaxFailedAttempts: 5,
+				LockoutDuration:   15,
+			},
+			PasswordPolicy: chainid.PasswordPolicy{
+				MinLength: 8,
+			},
+		}
+
+		if *flags.Templates != "" {
+			settings.TemplatesURL = *flags.Templates
+		} else {
+			settings.TemplatesURL = chainid.DefaultTemplatesURL
+		}
+
+		if *flags.Labels != nil {
+			settings.BlackListedLabels = *flags.Labels
+		} else {
+			settings.BlackListedLabels = make([]chainid.Pair, 0)
+		}
+
+		return settingsService.StoreSettings(settings)
+	} else if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func retrieveFirstEndpointFromDatabase(endpointService chainid.EndpointService) *chainid.Endpoint {
+	endpoints, err := endpointService.Endpoints()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return &endpoints[0]
+}
+
+func loadOrGenerateKeyPair(fileService chainid.FileService, signatureService chainid.DigitalSignatureService) ([]byte, []byte, error) {
+	existingKeyPair, err := fileService.KeyPairFilesExist()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if existingKeyPair {
+		return fileService.LoadKeyPair()
+	}
+
+	private, public, err := signatureService.GenerateKeyPair()
+	if err != nil {
+		return nil, nil, err
+	}
+	privateHeader, publicHeader := signatureService.PEMHeaders()
+	err = fileService.StoreKeyPair(private, public, privateHeader, publicHeader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return private, public, nil
+}
+
+// initKeyPair loads the key pair shared by the members of the cluster. The key pair
+// of the first member is shared with the members started afterwards.
+func initKeyPair(fileService chainid.FileService, signatureService chainid.DigitalSignatureService, clusterService chainid.ClusterService) error {
+	data, err := clusterService.SharedKey("signature_key_pair", func() ([]byte, error) {
+		private, public, err := loadOrGenerateKeyPair(fileService, signatureService)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(&sharedKeyPair{Private: private, Public: public})
+	})
+	if err != nil {
+		return err
+	}
+
+	var keyPair sharedKeyPair
+	err = json.Unmarshal(data, &keyPair)
+	if err != nil {
+		return err
+	}
+	return signatureService.ParseKeyPair(keyPair.Private, keyPair.Public)
+}
+
+func createTLSSecuredEndpoint(flags *chainid.CLIFlags, endpointService chainid.EndpointService) error {
+	tlsConfiguration := chainid.TLSConfiguration{
+		TLS:           *flags.TLS,
+		TLSSkipVerify: *flags.TLSSkipVerify,
+	}
+
+	if *flags.TLS {
+		tlsConfiguration.TLSCACertPath = *flags.TLSCacert
+		tlsConfiguration.TLSCertPath = *flags.TLSCert
+		tlsConfiguration.TLSKeyPath = *flags.TLSKey
+	} else if !*flags.TLS && *flags.TLSSkipVerify {
+		tlsConfiguration.TLS = true
+	}
+
+	endpoint := &chainid.Endpoint{
+		Name:            "primary",
+		URL:             *flags.EndpointURL,
+		GroupID:         chainid.EndpointGroupID(1),
+		Type:            chainid.DockerEnvironment,
+		TLSConfig:       tlsConfiguration,
+		AuthorizedUsers: []chainid.UserID{},
+		AuthorizedTeams: []chainid.TeamID{},
+		Extensions:      []chainid.EndpointExtension{},
+	}
+
+	if strings.HasPrefix(endpoint.URL, "tcp://") {
+		tlsConfig, err := crypto.CreateTLSConfigurationFromDisk(tlsConfiguration.TLSCACertPath, tlsConfiguration.TLSCertPath, tlsConfiguration.TLSKeyPath, tlsConfiguration.TLSSkipVerify)
+		if err != nil {
+			return err
+		}
+
+		agentOnDockerEnvironment, err := client.ExecutePingOperation(endpoint.URL, tlsConfig)
+		if err != nil {
+			return err
+		}
+
+		if agentOnDockerEnvironment {
+			endpoint.Type = chainid.AgentOnDockerEnvironment
+		}
+	}
+
+	return endpointService.CreateEndpoint(endpoint)
+}
+
+func createSSHEndpoint(flags *chainid.CLIFlags, endpointService chainid.EndpointService) error {
+	endpoint := &chainid.Endpoint{
+		Name:    "primary",
+		URL:     *flags.EndpointURL,
+		GroupID: chainid.EndpointGroupID(1),
+		Type:    chainid.DockerEnvironment,
+		SSHConfig: chainid.SSHConfiguration{
+			SSHSkipVerify:     *flags.SSHSkipVerify,
+			SSHKeyPath:        *flags.SSHKey,
+			SSHKnownHostsPath: *flags.SSHKnownHosts,
+		},
+		AuthorizedUsers: []chainid.UserID{},
+		AuthorizedTeams: []chainid.TeamID{},
+		Extensions:      []chainid.EndpointExtension{},
+	}
+
+	dialer, err := ssh.NewDialer(endpoint.URL, &endpoint.SSHConfig)
+	if err != nil {
+		return err
+	}
+	defer dialer.Close()
+
+	_, err = client.ExecuteSSHPingOperation(dialer.Dial)
+	if err != nil {
+		return err
+	}
+
+	return endpointService.CreateEndpoint(endpoint)
+}
+
+func createUnsecuredEndpoint(endpointURL string, endpointService chainid.EndpointService) error {
+	if strings.HasPrefix(endpointURL, "tcp://") {
+		_, err := client.ExecutePingOperation(endpointURL, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	endpoint := &chainid.Endpoint{
+		Name:            "primary",
+		URL:             endpointURL,
+		GroupID:         chainid.EndpointGroupID(1),
+		Type:            chainid.DockerEnvironment,
+		TLSConfig:       chainid.TLSConfiguration{},
+		AuthorizedUsers: []chainid.UserID{},
+		AuthorizedTeams: []chainid.TeamID{},
+		Extensions:      []chainid.EndpointExtension{},
+	}
+
+	return endpointService.CreateEndpoint(endpoint)
+}
+
+func initEndpoint(flags *chainid.CLIFlags, endpointService chainid.EndpointService) error {
+	if *flags.EndpointURL == "" {
+		return nil
+	}
+
+	endpoints, err := endpointService.Endpoints()
+	if err != nil {
+		return err
+	}
+
+	if len(endpoints) > 0 {
+		log.Println("Instance already has defined endpoints. Skipping the endpoint defined via CLI.")
+		return nil
+	}
+
+	if strings.HasPrefix(*flags.EndpointURL, "ssh://") {
+		return createSSHEndpoint(flags, endpointService)
+	}
+	if *flags.TLS || *flags.TLSSkipVerify {
+		return createTLSSecuredEndpoint(flags, endpointService)
+	}
+	return createUnsecuredEndpoint(*flags.EndpointURL, endpointService)
+}
+
+func main() {
+	flags := initCLI()
+
+	fileService := initFileService(*flags.Data)
+
+	if flags.DB.Command != "" {
+		err := runDBCommand(flags, fileService)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *flags.RollbackMigration {
+		rollbackMigration(*flags.Data)
+		return
+	}
+
+	masterKey, previousMasterKey, err := loadMasterKeys(flags, fileService)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	store := initStore(*flags.Data, flags, masterKey, previousMasterKey)
+	defer store.Close()
+
+	clusterService := initClusterService(store.clusterBackend, store.clusterCipher, *flags.Addr)
+	defer clusterService.Stop()
+
+	jwtService := initJWTService(!*flags.NoAuth, clusterService)
+
+	cryptoService := initCryptoService()
+
+	totpService := initTOTPService()
+
+	digitalSignatureService := initDigitalSignatureService()
+
+	ldapService := initLDAPService()
+
+	gitService := initGitService()
+
+	backupService := backup.NewService(*flags.Data, store)
+
+	authorizeEndpointMgmt := initEndpointWatcher(store.EndpointService, store.EndpointGroupService, clusterService, flags)
+
+	err = initKeyPair(fileService, digitalSignatureService, clusterService)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	stackManager, err := initStackManager(*flags.Assets, *flags.Data, digitalSignatureService, fileService)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = initSettings(store.SettingsService, flags)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	backupScheduler := initBackupScheduler(backupService, store.BackupStatusService, store.SettingsService, clusterService)
+
+	err = initDockerHub(store.DockerHubService)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	applicationStatus := initStatus(authorizeEndpointMgmt, flags)
+
+	err = initEndpoint(flags, store.EndpointService)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	adminPasswordHash := ""
+	if *flags.AdminPasswordFile != "" {
+		content, err := fileService.GetFileContent(*flags.AdminPasswordFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		adminPasswordHash, err = cryptoService.Hash(content)
+		if err != nil {
+			log.Fatal(err)
+		}
+	} else if *flags.AdminPassword != "" {
+		adminPasswordHash = *flags.AdminPassword
+	}
+
+	if adminPasswordHash != "" {
+		users, err := store.UserService.UsersByRole(chainid.AdministratorRole)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if len(users) == 0 {
+			log.Printf("Creating admin user with password hash %s", adminPasswordHash)
+			user := &chainid.User{
+				Username:          "admin",
+				Role:              chainid.AdministratorRole,
+				Password:          adminPasswordHash,
+				PasswordChangedAt: time.Now().Unix(),
+			}
+			err := store.UserService.CreateUser(user)
+			if err != nil {
+				log.Fatal(err)
+			}
+		} else {
+			log.Println("Instance already has an administrator user defined. Skipping admin password related flags.")
+		}
+	}
+
+	configService := initConfigService(store, cryptoService, fileService, backupScheduler, authorizeEndpointMgmt)
+
+	if *flags.ConfigFile != "" {
+		err = applyConfigFile(configService, fileService, *flags.ConfigFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	certificateManager := initCertificateManager(flags)
+
+	var server chainid.Server = &http.Server{
+		Status:                 applicationStatus,
+		BindAddress:            *flags.Addr,
+		AssetsPath:             *flags.Assets,
+		AuthDisabled:           *flags.NoAuth,
+		SecretsEncrypted:       masterKey != nil,
+		EndpointManagement:     authorizeEndpointMgmt,
+		UserService:            store.UserService,
+		TeamService:            store.TeamService,
+		TeamMembershipService:  store.TeamMembershipService,
+		EndpointService:        store.EndpointService,
+		EndpointGroupService:   store.EndpointGroupService,
+		ResourceControlService: store.ResourceControlService,
+		SettingsService:        store.SettingsService,
+		RegistryService:        store.RegistryService,
+		DockerHubService:       store.DockerHubService,
+		StackService:           store.StackService,
+		RoleService:            store.RoleService,
+		RoleAssignmentService:  store.RoleAssignmentService,
+		AuditLogService:        store.AuditLogService,
+		BackupService:          backupService,
+		BackupStatusService:    store.BackupStatusService,
+		BackupScheduler:        backupScheduler,
+		ConfigService:          configService,
+		ClusterService:         clusterService,
+		StackManager:           stackManager,
+		CryptoService:          cryptoService,
+		TOTPService:            totpService,
+		JWTService:             jwtService,
+		FileService:            fileService,
+		LDAPService:            ldapService,
+		GitService:             gitService,
+		SignatureService:       digitalSignatureService,
+		SSL:                    *flags.SSL,
+		SSLRedirectAddress:     *flags.SSLRedirectAddr,
+		HSTSMaxAge:             *flags.HSTSMaxAge,
+		CertificateManager:     certificateManager,
+		TrustedProxies:         *flags.TrustedProxies,
+		HealthCheckInterval:    *flags.HealthCheckInterval,
+		SnapshotInterval:       *flags.SnapshotInterval,
+	}
+
+	log.Printf("Starting Chain Platform %s on %s", chainid.APIVersion, *flags.Addr)
+	err = server.Start()
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/bolt"
+)
+
+type (
+	dbVersionOutput struct {
+		PreviousVersion int  `json:"PreviousVersion"`
+		Version         int  `json:"Version"`
+		DryRun          bool `json:"DryRun,omitempty"`
+	}
+
+	dbResetPasswordOutput struct {
+		Username string `json:"Username"`
+		Password string `json:"Password,omitempty"`
+	}
+
+	dbVerifyOutput struct {
+		Problems []string `json:"Problems"`
+	}
+)
+
+// runDBCommand runs a db subcommand on the database stored in the data directory.
+// The instance using the database must be stopped.
+func runDBCommand(flags *chainid.CLIFlags, fileService chainid.FileService) error {
+	if *flags.Datastore != chainid.BoltDataStore {
+		return chainid.ErrDataStoreOperationNotSupported
+	}
+
+	store, err := bolt.NewStore(*flags.Data)
+	if err != nil {
+		return err
+	}
+
+	if store.IsNew() {
+		return chainid.ErrDBNotFound
+	}
+
+	err = store.Open()
+	if err != nil {
+		return fmt.Errorf("Unable to open the database, make sure the instance is stopped: %s", err)
+	}
+	defer store.Close()
+
+	output := &dbOutput{w: os.Stdout, json: *flags.DB.JSON}
+
+	switch flags.DB.Command {
+	case "dump":
+		return dumpDatabase(store, output)
+	case "export":
+		return exportBucket(store, output, *flags.DB.Bucket)
+	case "set-version":
+		return setDatabaseVersion(store, output, *flags.DB.Version)
+	case "migrate":
+		err = initDBCommandEncryption(store, flags, fileService)
+		if err != nil {
+			return err
+		}
+		return migrateDatabase(store, output, *flags.DB.DryRun)
+	case "reset-admin-password":
+		return resetAdminPassword(store, output, *flags.DB.Username, *flags.DB.Password)
+	case "verify":
+		err = initDBCommandEncryption(store, flags, fileService)
+		if err != nil {
+			return err
+		}
+		return verifyDatabase(store, output)
+	}
+	return nil
+}
+
+// initDBCommandEncryption sets up the decryption of the secrets for the subcommands decoding the records.
+func initDBCommandEncryption(store *bolt.Store, flags *chainid.CLIFlags, fileService chainid.FileService) error {
+	masterKey, previousMasterKey, err := loadMasterKeys(flags, fileService)
+	if err != nil {
+		return err
+	}
+	return store.InitEncryption(masterKey, previousMasterKey)
+}
+
+// dbOutput writes the result of a subcommand either as JSON or as human-readable text.
+type dbOutput struct {
+	w    io.Writer
+	json bool
+}
+
+func (output *dbOutput) print(v interface{}, text func(w io.Writer)) error {
+	if !output.json {
+		text(output.w)
+		return nil
+	}
+
+	encoder := json.NewEncoder(output.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func printRecords(w io.Writer, records []bolt.Record) {
+	for _, record := range records {
+		fmt.Fprintf(w, "%s: %s\n", record.Key, record.Value)
+	}
+}
+
+func dumpDatabase(store *bolt.Store, output *dbOutput) error {
+	buckets, err := store.Buckets()
+	if err != nil {
+		return err
+	}
+
+	dump := make(map[string][]bolt.Record)
+	for _, bucket := range buckets {
+		records, err := store.BucketRecords(bucket)
+		if err != nil {
+			return err
+		}
+		dump[bucket] = records
+	}
+
+	return output.print(dump, func(w io.Writer) {
+		for _, bucket := range buckets {
+			fmt.Fprintf(w, "== %s (%d records)\n", bucket, len(dump[bucket]))
+			printRecords(w, dump[bucket])
+		}
+	})
+}
+
+func exportBucket(store *bolt.Store, output *dbOutput, bucket string) error {
+	records, err := store.BucketRecords(bucket)
+	if err != nil {
+		return err
+	}
+
+	return output.print(records, func(w io.Writer) {
+		printRecords(w, records)
+	})
+}
+
+func setDatabaseVersion(store *bolt.Store, output *dbOutput, version int) error {
+	if version < 0 || version > chainid.DBVersion {
+		return fmt.Errorf("Invalid database version %d, it must be between 0 and %d", version, chainid.DBVersion)
+	}
+
+	previousVersion, err := store.VersionService.DBVersion()
+	if err != nil && err != chainid.ErrDBVersionNotFound {
+		return err
+	}
+
+	err = store.VersionService.StoreDBVersion(version)
+	if err != nil {
+		return err
+	}
+
+	result := &dbVersionOutput{PreviousVersion: previousVersion, Version: version}
+	return output.print(result, func(w io.Writer) {
+		fmt.Fprintf(w, "Database version changed from %d to %d.\n", previousVersion, version)
+	})
+}
+
+func migrateDatabase(store *bolt.Store, output *dbOutput, dryRun bool) error {
+	var previousVersion int
+	var err error
+	if dryRun {
+		previousVersion, err = store.MigrateDataDryRun()
+	} else {
+		previousVersion, err = store.VersionService.DBVersion()
+		if err == chainid.ErrDBVersionNotFound {
+			previousVersion, err = 0, nil
+		}
+		if err == nil {
+			err = store.MigrateData()
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	result := &dbVersionOutput{PreviousVersion: previousVersion, Version: chainid.DBVersion, DryRun: dryRun}
+	return output.print(result, func(w io.Writer) {
+		switch {
+		case previousVersion >= chainid.DBVersion:
+			fmt.Fprintf(w, "Database version %d is up to date.\n", previousVersion)
+		case dryRun:
+			fmt.Fprintf(w, "Database can be migrated from version %d to %d, no change was made.\n", previousVersion, chainid.DBVersion)
+		default:
+			fmt.Fprintf(w, "Database migrated from version %d to %d.\n", previousVersion, chainid.DBVersion)
+		}
+	})
+}
+
+// resetAdminPassword resets the password of an administrator, unlocks the account and
+// disables its two-factor authentication. When no password is specified, a random password
+// is generated and must be changed at the next login.
+func resetAdminPassword(store *bolt.Store, output *dbOutput, username, password string) error {
+	user, err := findAdministrator(store, username)
+	if err != nil {
+		return err
+	}
+
+	generated := password == ""
+	if generated {
+		password, err = generatePassword()
+		if err != nil {
+			return err
+		}
+	}
+
+	hash, err := initCryptoService().Hash(password)
+	if err != nil {
+		return err
+	}
+
+	user.Password = hash
+	user.PasswordChangedAt = time.Now().Unix()
+	user.MustChangePassword = generated
+	user.Locked = false
+	user.LockedAt = 0
+	user.FailedLogins = 0
+	user.TOTPEnabled = false
+	user.TOTPSecret = ""
+	user.TOTPRecoveryCodes = nil
+
+	err = store.UserService.UpdateUser(user.ID, user)
+	if err != nil {
+		return err
+	}
+
+	result := &dbResetPasswordOutput{Username: user.Username}
+	if generated {
+		result.Password = password
+	}
+	return output.print(result, func(w io.Writer) {
+		if generated {
+			fmt.Fprintf(w, "Password of %s reset to %s, it must be changed at the next login.\n", user.Username, password)
+		} else {
+			fmt.Fprintf(w, "Password of %s reset.\n", user.Username)
+		}
+	})
+}
+
+func findAdministrator(store *bolt.Store, username string) (*chainid.User, error) {
+	if username == "" {
+		administrators, err := store.UserService.UsersByRole(chainid.AdministratorRole)
+		if err != nil {
+			return nil, err
+		}
+		if len(administrators) == 0 {
+			return nil, chainid.ErrUserNotFound
+		}
+		return &administrators[0], nil
+	}
+
+	user, err := store.UserService.UserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role != chainid.AdministratorRole {
+		return nil, fmt.Errorf("User %s is not an administrator", username)
+	}
+	return user, nil
+}
+
+func generatePassword() (string, error) {
+	data := make([]byte, 12)
+	_, err := rand.Read(data)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func verifyDatabase(store *bolt.Store, output *dbOutput) error {
+	problems, err := store.Verify()
+	if err != nil {
+		return err
+	}
+
+	err = output.print(&dbVerifyOutput{Problems: problems}, func(w io.Writer) {
+		if len(problems) == 0 {
+			fmt.Fprintln(w, "No problem found.")
+		}
+		for _, problem := range problems {
+			fmt.Fprintf(w, "- %s\n", problem)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(problems) > 0 {
+		return chainid.ErrDBVerificationFailed
+	}
+	return nil
+}
@@ -0,0 +1,1114 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/http/security"
+	"github.com/chainid-io/dashboard/tunnel"
+)
+
+// Kinds of resources reported in the changes made by a configuration document.
+const (
+	SettingsKind        = "settings"
+	UserKind            = "user"
+	TeamKind            = "team"
+	TeamMembershipKind  = "team_membership"
+	EndpointGroupKind   = "endpoint_group"
+	EndpointKind        = "endpoint"
+	RegistryKind        = "registry"
+	ResourceControlKind = "resource_control"
+)
+
+// applier applies the sections of a document in dependency order. In dry-run mode, no change is
+// made to the data: the resources that would be created are given placeholder identifiers so
+// that the following sections can reference them.
+type applier struct {
+	*Service
+	dryRun        bool
+	names         *resourceNames
+	changes       []chainid.ConfigChange
+	placeholderID int
+}
+
+func (service *Service) apply(document *Document, dryRun bool) ([]chainid.ConfigChange, error) {
+	names, err := service.loadResourceNames()
+	if err != nil {
+		return nil, err
+	}
+
+	a := &applier{
+		Service: service,
+		dryRun:  dryRun,
+		names:   names,
+		changes: []chainid.ConfigChange{},
+	}
+
+	steps := []func(document *Document) error{
+		a.applySettings,
+		a.applyUsers,
+		a.applyTeams,
+		a.applyTeamMemberships,
+		a.applyEndpointGroups,
+		a.applyEndpoints,
+		a.applyRegistries,
+		a.applyResourceControls,
+	}
+
+	for _, step := range steps {
+		err := step(document)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return a.changes, nil
+}
+
+func (a *applier) record(kind, name string, action chainid.ConfigChangeAction) {
+	a.changes = append(a.changes, chainid.ConfigChange{Kind: kind, Name: name, Action: action})
+}
+
+func (a *applier) nextPlaceholderID() int {
+	a.placeholderID--
+	return a.placeholderID
+}
+
+func documentError(format string, args ...interface{}) error {
+	return &DocumentError{Message: fmt.Sprintf(format, args...)}
+}
+
+// setEdgeJoinToken generates the join token of an edge endpoint created or converted by a
+// document. The join token is never part of a document.
+func setEdgeJoinToken(endpoint *chainid.Endpoint) error {
+	if endpoint.Type != chainid.EdgeAgentEnvironment {
+		endpoint.EdgeJoinToken = ""
+		return nil
+	}
+	if endpoint.EdgeJoinToken != "" {
+		return nil
+	}
+
+	joinToken, err := tunnel.GenerateJoinToken()
+	if err != nil {
+		return err
+	}
+	endpoint.EdgeJoinToken = joinToken
+	return nil
+}
+
+// uniqueNames returns a sorted copy of names without duplicates.
+func uniqueNames(names []string) []string {
+	result := make([]string, 0, len(names))
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (a *applier) userIDs(usernames []string, kind, name string) ([]chainid.UserID, error) {
+	IDs := make([]chainid.UserID, 0, len(usernames))
+	for _, username := range usernames {
+		ID, ok := a.names.users[username]
+		if !ok {
+			return nil, documentError("Unknown user %q referenced by %s %q", username, kind, name)
+		}
+		IDs = append(IDs, ID)
+	}
+	return IDs, nil
+}
+
+func (a *applier) teamIDs(teamNames []string, kind, name string) ([]chainid.TeamID, error) {
+	IDs := make([]chainid.TeamID, 0, len(teamNames))
+	for _, teamName := range teamNames {
+		ID, ok := a.names.teams[teamName]
+		if !ok {
+			return nil, documentError("Unknown team %q referenced by %s %q", teamName, kind, name)
+		}
+		IDs = append(IDs, ID)
+	}
+	return IDs, nil
+}
+
+// applySettings merges the fields defined in the document into the current settings.
+// Secrets left empty in the document keep their current value.
+func (a *applier) applySettings(document *Document) error {
+	if len(document.Settings) == 0 || string(document.Settings) == "null" {
+		return nil
+	}
+
+	current, err := a.settingsService.Settings()
+	if err != nil {
+		return err
+	}
+
+	// The current settings are copied through JSON so that decoding the document
+	// does not modify the slices they share.
+	data, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	settings := &chainid.Settings{}
+	err = json.Unmarshal(data, settings)
+	if err != nil {
+		return err
+	}
+
+	err = decodeStrict(document.Settings, settings)
+	if err != nil {
+		return documentError("Invalid settings: %s", err)
+	}
+
+	if settings.LDAPSettings.Password == "" {
+		settings.LDAPSettings.Password = current.LDAPSettings.Password
+	}
+	if settings.BackupSettings.Password == "" {
+		settings.BackupSettings.Password = current.BackupSettings.Password
+	}
+	if settings.BackupSettings.S3.SecretAccessKey == "" {
+		settings.BackupSettings.S3.SecretAccessKey = current.BackupSettings.S3.SecretAccessKey
+	}
+
+	if settings.AuthenticationMethod != chainid.AuthenticationInternal && settings.AuthenticationMethod != chainid.AuthenticationLDAP {
+		return documentError("Invalid settings: unsupported authentication method %d", settings.AuthenticationMethod)
+	}
+	if settings.TemplatesURL == "" {
+		return documentError("Invalid settings: the templates URL is required")
+	}
+
+	if reflect.DeepEqual(current, settings) {
+		return nil
+	}
+
+	a.record(SettingsKind, SettingsKind, chainid.ConfigChangeUpdate)
+	if a.dryRun {
+		return nil
+	}
+
+	if !reflect.DeepEqual(current.BackupSettings, settings.BackupSettings) && a.backupScheduler != nil {
+		err = a.backupScheduler.ScheduleBackups(&settings.BackupSettings)
+		if err == chainid.ErrInvalidBackupSchedule || err == chainid.ErrInvalidBackupTarget {
+			return documentError("Invalid settings: %s", err)
+		} else if err != nil {
+			return err
+		}
+	}
+
+	return a.settingsService.StoreSettings(settings)
+}
+
+func (a *applier) applyUsers(document *Document) error {
+	if document.Users == nil {
+		return nil
+	}
+
+	desired := make(map[string]bool)
+	administratorDefined := false
+	for _, user := range document.Users {
+		if user.Username == "" || strings.Contains(user.Username, " ") {
+			return documentError("Invalid username %q", user.Username)
+		}
+		if desired[user.Username] {
+			return documentError("User %q is defined more than once", user.Username)
+		}
+		if user.Role != chainid.AdministratorRole && user.Role != chainid.StandardUserRole {
+			return documentError("Invalid role %d for user %q", user.Role, user.Username)
+		}
+		desired[user.Username] = true
+		administratorDefined = administratorDefined || user.Role == chainid.AdministratorRole
+	}
+
+	if !administratorDefined {
+		return documentError("At least one administrator must be defined in the users section")
+	}
+
+	settings, err := a.settingsService.Settings()
+	if err != nil {
+		return err
+	}
+
+	users, err := a.userService.Users()
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]*chainid.User)
+	for i := range users {
+		existing[users[i].Username] = &users[i]
+	}
+
+	for _, documentUser := range document.Users {
+		user, ok := existing[documentUser.Username]
+		if !ok {
+			err = a.createUser(&documentUser, &settings.PasswordPolicy)
+		} else {
+			err = a.updateUser(user, &documentUser, &settings.PasswordPolicy)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+	for _, user := range users {
+		if desired[user.Username] {
+			continue
+		}
+
+		a.record(UserKind, user.Username, chainid.ConfigChangeDelete)
+		delete(a.names.users, user.Username)
+		if a.dryRun {
+			continue
+		}
+
+		err = a.userService.DeleteUser(user.ID)
+		if err != nil {
+			return err
+		}
+
+		err = a.teamMembershipService.DeleteTeamMembershipByUserID(user.ID)
+		if err != nil {
+			return err
+		}
+
+		err = a.roleAssignmentService.DeleteRoleAssignmentsByUserID(user.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setUserPassword sets the password of a user defined in a document, the password must satisfy the password policy.
+func (a *applier) setUserPassword(user *chainid.User, documentUser *DocumentUser, policy *chainid.PasswordPolicy) error {
+	err := security.SetUserPassword(user, documentUser.Password, policy, a.cryptoService)
+	if err == chainid.ErrCryptoHashFailure {
+		return err
+	} else if err != nil {
+		return documentError("Invalid password for user %q: %s", documentUser.Username, err)
+	}
+	return nil
+}
+
+func (a *applier) createUser(documentUser *DocumentUser, policy *chainid.PasswordPolicy) error {
+	user := &chainid.User{
+		Username: documentUser.Username,
+		Role:     documentUser.Role,
+	}
+
+	if documentUser.Password != "" {
+		err := a.setUserPassword(user, documentUser, policy)
+		if err != nil {
+			return err
+		}
+	}
+
+	a.record(UserKind, documentUser.Username, chainid.ConfigChangeCreate)
+	if a.dryRun {
+		a.names.users[documentUser.Username] = chainid.UserID(a.nextPlaceholderID())
+		return nil
+	}
+
+	err := a.userService.CreateUser(user)
+	if err != nil {
+		return err
+	}
+
+	a.names.users[user.Username] = user.ID
+	return nil
+}
+
+func (a *applier) updateUser(user *chainid.User, documentUser *DocumentUser, policy *chainid.PasswordPolicy) error {
+	updated := *user
+	updated.Role = documentUser.Role
+
+	passwordChanged := documentUser.Password != "" &&
+		(user.Password == "" || a.cryptoService.CompareHashAndData(user.Password, documentUser.Password) != nil)
+
+	if updated.Role == user.Role && !passwordChanged {
+		return nil
+	}
+
+	if passwordChanged {
+		err := a.setUserPassword(&updated, documentUser, policy)
+		if err != nil {
+			return err
+		}
+	}
+
+	a.record(UserKind, user.Username, chainid.ConfigChangeUpdate)
+	if a.dryRun {
+		return nil
+	}
+
+	return a.userService.UpdateUser(user.ID, &updated)
+}
+
+func (a *applier) applyTeams(document *Document) error {
+	if document.Teams == nil {
+		return nil
+	}
+
+	desired := make(map[string]bool)
+	for _, team := range document.Teams {
+		if team.Name == "" {
+			return documentError("A team name cannot be empty")
+		}
+		if desired[team.Name] {
+			return documentError("Team %q is defined more than once", team.Name)
+		}
+		desired[team.Name] = true
+	}
+
+	teams, err := a.teamService.Teams()
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]bool)
+	for _, team := range teams {
+		existing[team.Name] = true
+	}
+
+	for _, documentTeam := range document.Teams {
+		if existing[documentTeam.Name] {
+			continue
+		}
+
+		a.record(TeamKind, documentTeam.Name, chainid.ConfigChangeCreate)
+		if a.dryRun {
+			a.names.teams[documentTeam.Name] = chainid.TeamID(a.nextPlaceholderID())
+			continue
+		}
+
+		team := &chainid.Team{Name: documentTeam.Name}
+		err = a.teamService.CreateTeam(team)
+		if err != nil {
+			return err
+		}
+		a.names.teams[team.Name] = team.ID
+	}
+
+	sort.Slice(teams, func(i, j int) bool { return teams[i].Name < teams[j].Name })
+	for _, team := range teams {
+		if desired[team.Name] {
+			continue
+		}
+
+		a.record(TeamKind, team.Name, chainid.ConfigChangeDelete)
+		delete(a.names.teams, team.Name)
+		if a.dryRun {
+			continue
+		}
+
+		err = a.teamService.DeleteTeam(team.ID)
+		if err != nil {
+			return err
+		}
+
+		err = a.teamMembershipService.DeleteTeamMembershipByTeamID(team.ID)
+		if err != nil {
+			return err
+		}
+
+		err = a.roleAssignmentService.DeleteRoleAssignmentsByTeamID(team.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *applier) applyTeamMemberships(document *Document) error {
+	if document.TeamMemberships == nil {
+		return nil
+	}
+
+	type membershipIdentifier struct {
+		userID chainid.UserID
+		teamID chainid.TeamID
+	}
+
+	desired := make(map[membershipIdentifier]*DocumentTeamMembership)
+	for i := range document.TeamMemberships {
+		documentMembership := &document.TeamMemberships[i]
+		key := membershipKey(documentMembership)
+
+		userID, ok := a.names.users[documentMembership.User]
+		if !ok {
+			return documentError("Unknown user %q referenced by %s %q", documentMembership.User, TeamMembershipKind, key)
+		}
+		teamID, ok := a.names.teams[documentMembership.Team]
+		if !ok {
+			return documentError("Unknown team %q referenced by %s %q", documentMembership.Team, TeamMembershipKind, key)
+		}
+		if documentMembership.Role != chainid.TeamLeader && documentMembership.Role != chainid.TeamMember {
+			return documentError("Invalid role %d for %s %q", documentMembership.Role, TeamMembershipKind, key)
+		}
+
+		identifier := membershipIdentifier{userID: userID, teamID: teamID}
+		if desired[identifier] != nil {
+			return documentError("Team membership %q is defined more than once", key)
+		}
+		desired[identifier] = documentMembership
+	}
+
+	memberships, err := a.teamMembershipService.TeamMemberships()
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[membershipIdentifier]*chainid.TeamMembership)
+	for i := range memberships {
+		membership := &memberships[i]
+		documentMembership, ok := a.names.documentTeamMembership(membership)
+		if !ok {
+			// The user or the team has been removed by a previous section.
+			continue
+		}
+
+		identifier := membershipIdentifier{userID: membership.UserID, teamID: membership.TeamID}
+		documentMembershipDesired := desired[identifier]
+
+		if documentMembershipDesired == nil {
+			a.record(TeamMembershipKind, membershipKey(&documentMembership), chainid.ConfigChangeDelete)
+			if !a.dryRun {
+				err = a.teamMembershipService.DeleteTeamMembership(membership.ID)
+				if err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		existing[identifier] = membership
+		if membership.Role == documentMembershipDesired.Role {
+			continue
+		}
+
+		a.record(TeamMembershipKind, membershipKey(&documentMembership), chainid.ConfigChangeUpdate)
+		if !a.dryRun {
+			membership.Role = documentMembershipDesired.Role
+			err = a.teamMembershipService.UpdateTeamMembership(membership.ID, membership)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, documentMembership := range document.TeamMemberships {
+		identifier := membershipIdentifier{
+			userID: a.names.users[documentMembership.User],
+			teamID: a.names.teams[documentMembership.Team],
+		}
+		if existing[identifier] != nil {
+			continue
+		}
+
+		a.record(TeamMembershipKind, membershipKey(&documentMembership), chainid.ConfigChangeCreate)
+		if a.dryRun {
+			continue
+		}
+
+		membership := &chainid.TeamMembership{
+			UserID: identifier.userID,
+			TeamID: identifier.teamID,
+			Role:   documentMembership.Role,
+		}
+		err = a.teamMembershipService.CreateTeamMembership(membership)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *applier) applyEndpointGroups(document *Document) error {
+	if document.EndpointGroups == nil {
+		return nil
+	}
+
+	desired := make(map[string]bool)
+	for _, group := range document.EndpointGroups {
+		if group.Name == "" {
+			return documentError("An endpoint group name cannot be empty")
+		}
+		if desired[group.Name] {
+			return documentError("Endpoint group %q is defined more than once", group.Name)
+		}
+		desired[group.Name] = true
+	}
+
+	groups, err := a.endpointGroupService.EndpointGroups()
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]*chainid.EndpointGroup)
+	for i := range groups {
+		existing[groups[i].Name] = &groups[i]
+	}
+
+	for _, documentGroup := range document.EndpointGroups {
+		documentGroup.Labels = append([]chainid.Pair{}, documentGroup.Labels...)
+		documentGroup.AuthorizedUsers = uniqueNames(documentGroup.AuthorizedUsers)
+		documentGroup.AuthorizedTeams = uniqueNames(documentGroup.AuthorizedTeams)
+
+		authorizedUsers, err := a.userIDs(documentGroup.AuthorizedUsers, EndpointGroupKind, documentGroup.Name)
+		if err != nil {
+			return err
+		}
+		authorizedTeams, err := a.teamIDs(documentGroup.AuthorizedTeams, EndpointGroupKind, documentGroup.Name)
+		if err != nil {
+			return err
+		}
+
+		group, ok := existing[documentGroup.Name]
+		if !ok {
+			a.record(EndpointGroupKind, documentGroup.Name, chainid.ConfigChangeCreate)
+			if a.dryRun {
+				a.names.groups[documentGroup.Name] = chainid.EndpointGroupID(a.nextPlaceholderID())
+				continue
+			}
+
+			group = &chainid.EndpointGroup{
+				Name:            documentGroup.Name,
+				Description:     documentGroup.Description,
+				Labels:          documentGroup.Labels,
+				AuthorizedUsers: authorizedUsers,
+				AuthorizedTeams: authorizedTeams,
+			}
+			err = a.endpointGroupService.CreateEndpointGroup(group)
+			if err != nil {
+				return err
+			}
+			a.names.groups[group.Name] = group.ID
+			continue
+		}
+
+		if reflect.DeepEqual(a.names.documentEndpointGroup(group), documentGroup) {
+			continue
+		}
+
+		a.record(EndpointGroupKind, documentGroup.Name, chainid.ConfigChangeUpdate)
+		if a.dryRun {
+			continue
+		}
+
+		group.Description = documentGroup.Description
+		group.Labels = documentGroup.Labels
+		group.AuthorizedUsers = authorizedUsers
+		group.AuthorizedTeams = authorizedTeams
+		err = a.endpointGroupService.UpdateEndpointGroup(group.ID, group)
+		if err != nil {
+			return err
+		}
+	}
+
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	for _, group := range groups {
+		// The default group cannot be removed.
+		if desired[group.Name] || group.ID == defaultEndpointGroupID {
+			continue
+		}
+
+		a.record(EndpointGroupKind, group.Name, chainid.ConfigChangeDelete)
+		delete(a.names.groups, group.Name)
+		if a.dryRun {
+			continue
+		}
+
+		err = a.endpointGroupService.DeleteEndpointGroup(group.ID)
+		if err != nil {
+			return err
+		}
+
+		endpoints, err := a.endpointService.Endpoints()
+		if err != nil {
+			return err
+		}
+
+		for _, endpoint := range endpoints {
+			if endpoint.GroupID == group.ID {
+				endpoint.GroupID = defaultEndpointGroupID
+				err = a.endpointService.UpdateEndpoint(endpoint.ID, &endpoint)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (a *applier) applyEndpoints(document *Document) error {
+	if document.Endpoints == nil {
+		return nil
+	}
+
+	if !a.endpointManagement {
+		return documentError("Endpoint management is disabled, the endpoints section cannot be applied")
+	}
+
+	desired := make(map[string]bool)
+	for _, endpoint := range document.Endpoints {
+		if endpoint.Name == "" || (endpoint.URL == "" && endpoint.Type != chainid.EdgeAgentEnvironment) {
+			return documentError("An endpoint must have a name and a URL")
+		}
+		if desired[endpoint.Name] {
+			return documentError("Endpoint %q is defined more than once", endpoint.Name)
+		}
+		if endpoint.Type != 0 && endpoint.Type != chainid.DockerEnvironment && endpoint.Type != chainid.AgentOnDockerEnvironment && endpoint.Type != chainid.AzureEnvironment && endpoint.Type != chainid.EdgeAgentEnvironment && endpoint.Type != chainid.KubernetesEnvironment {
+			return documentError("Invalid type %d for endpoint %q", endpoint.Type, endpoint.Name)
+		}
+		desired[endpoint.Name] = true
+	}
+
+	endpoints, err := a.endpointService.Endpoints()
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]*chainid.Endpoint)
+	for i := range endpoints {
+		existing[endpoints[i].Name] = &endpoints[i]
+	}
+
+	for _, endpoint := range endpoints {
+		if endpoint.ExternallyManaged && desired[endpoint.Name] {
+			return documentError("Endpoint %q is managed by an external source", endpoint.Name)
+		}
+	}
+
+	for _, documentEndpoint := range document.Endpoints {
+		if documentEndpoint.Type == 0 {
+			documentEndpoint.Type = chainid.DockerEnvironment
+		}
+		documentEndpoint.AuthorizedUsers = uniqueNames(documentEndpoint.AuthorizedUsers)
+		documentEndpoint.AuthorizedTeams = uniqueNames(documentEndpoint.AuthorizedTeams)
+
+		groupID := defaultEndpointGroupID
+		if documentEndpoint.Group != "" {
+			ID, ok := a.names.groups[documentEndpoint.Group]
+			if !ok {
+				return documentError("Unknown endpoint group %q referenced by %s %q", documentEndpoint.Group, EndpointKind, documentEndpoint.Name)
+			}
+			groupID = ID
+		}
+		if groupID == defaultEndpointGroupID {
+			documentEndpoint.Group = ""
+		}
+
+		authorizedUsers, err := a.userIDs(documentEndpoint.AuthorizedUsers, EndpointKind, documentEndpoint.Name)
+		if err != nil {
+			return err
+		}
+		authorizedTeams, err := a.teamIDs(documentEndpoint.AuthorizedTeams, EndpointKind, documentEndpoint.Name)
+		if err != nil {
+			return err
+		}
+
+		azureCredentials := chainid.AzureCredentials{}
+		if documentEndpoint.Type == chainid.AzureEnvironment {
+			if documentEndpoint.AzureCredentials == nil {
+				documentEndpoint.AzureCredentials = &chainid.AzureCredentials{}
+			}
+			azureCredentials = *documentEndpoint.AzureCredentials
+			documentEndpoint.AzureCredentials = &chainid.AzureCredentials{
+				ApplicationID: azureCredentials.ApplicationID,
+				TenantID:      azureCredentials.TenantID,
+			}
+		} else {
+			documentEndpoint.AzureCredentials = nil
+		}
+
+		kubernetesCredentials := chainid.KubernetesCredentials{}
+		if documentEndpoint.Type == chainid.KubernetesEnvironment {
+			kubernetesCredentials.Token = documentEndpoint.KubernetesToken
+		}
+		documentEndpoint.KubernetesToken = ""
+
+		sshConfig := chainid.SSHConfiguration{}
+		if strings.HasPrefix(documentEndpoint.URL, "ssh://") {
+			if documentEndpoint.SSHConfig != nil {
+				sshConfig = *documentEndpoint.SSHConfig
+			}
+			documentEndpoint.SSHConfig = &sshConfig
+		} else {
+			documentEndpoint.SSHConfig = nil
+		}
+
+		endpoint, ok := existing[documentEndpoint.Name]
+		if !ok {
+			a.record(EndpointKind, documentEndpoint.Name, chainid.ConfigChangeCreate)
+			if a.dryRun {
+				continue
+			}
+
+			endpoint = &chainid.Endpoint{
+				Name:                  documentEndpoint.Name,
+				Type:                  documentEndpoint.Type,
+				URL:                   documentEndpoint.URL,
+				PublicURL:             documentEndpoint.PublicURL,
+				GroupID:               groupID,
+				TLSConfig:             documentEndpoint.TLSConfig,
+				SSHConfig:             sshConfig,
+				AzureCredentials:      azureCredentials,
+				KubernetesCredentials: kubernetesCredentials,
+				AuthorizedUsers:       authorizedUsers,
+				AuthorizedTeams:       authorizedTeams,
+				Extensions:            []chainid.EndpointExtension{},
+			}
+			err = setEdgeJoinToken(endpoint)
+			if err != nil {
+				return err
+			}
+			err = a.endpointService.CreateEndpoint(endpoint)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		azureKeyChanged := azureCredentials.AuthenticationKey != "" && azureCredentials.AuthenticationKey != endpoint.AzureCredentials.AuthenticationKey
+		kubernetesTokenChanged := kubernetesCredentials.Token != "" && kubernetesCredentials.Token != endpoint.KubernetesCredentials.Token
+		if reflect.DeepEqual(a.names.documentEndpoint(endpoint), documentEndpoint) && !azureKeyChanged && !kubernetesTokenChanged {
+			continue
+		}
+
+		a.record(EndpointKind, documentEndpoint.Name, chainid.ConfigChangeUpdate)
+		if a.dryRun {
+			continue
+		}
+
+		if azureCredentials.AuthenticationKey == "" {
+			azureCredentials.AuthenticationKey = endpoint.AzureCredentials.AuthenticationKey
+		}
+		if kubernetesCredentials.Token == "" && documentEndpoint.Type == chainid.KubernetesEnvironment {
+			kubernetesCredentials.Token = endpoint.KubernetesCredentials.Token
+		}
+
+		endpoint.Type = documentEndpoint.Type
+		endpoint.URL = documentEndpoint.URL
+		endpoint.PublicURL = documentEndpoint.PublicURL
+		endpoint.GroupID = groupID
+		endpoint.TLSConfig = documentEndpoint.TLSConfig
+		endpoint.SSHConfig = sshConfig
+		endpoint.AzureCredentials = azureCredentials
+		endpoint.KubernetesCredentials = kubernetesCredentials
+		endpoint.AuthorizedUsers = authorizedUsers
+		endpoint.AuthorizedTeams = authorizedTeams
+		err = setEdgeJoinToken(endpoint)
+		if err != nil {
+			return err
+		}
+		err = a.endpointService.UpdateEndpoint(endpoint.ID, endpoint)
+		if err != nil {
+			return err
+		}
+	}
+
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].Name < endpoints[j].Name })
+	for _, endpoint := range endpoints {
+		if desired[endpoint.Name] || endpoint.ExternallyManaged {
+			continue
+		}
+
+		a.record(EndpointKind, endpoint.Name, chainid.ConfigChangeDelete)
+		if a.dryRun {
+			continue
+		}
+
+		err = a.endpointService.DeleteEndpoint(endpoint.ID)
+		if err != nil {
+			return err
+		}
+
+		if endpoint.TLSConfig.TLS {
+			err = a.fileService.DeleteTLSFiles(strconv.Itoa(int(endpoint.ID)))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (a *applier) applyRegistries(document *Document) error {
+	if document.Registries == nil {
+		return nil
+	}
+
+	desired := make(map[string]bool)
+	urls := make(map[string]bool)
+	for _, registry := range document.Registries {
+		if registry.Name == "" || registry.URL == "" {
+			return documentError("A registry must have a name and a URL")
+		}
+		if desired[registry.Name] {
+			return documentError("Registry %q is defined more than once", registry.Name)
+		}
+		if urls[registry.URL] {
+			return documentError("Registry URL %q is defined more than once", registry.URL)
+		}
+		desired[registry.Name] = true
+		urls[registry.URL] = true
+	}
+
+	registries, err := a.registryService.Registries()
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]*chainid.Registry)
+	for i := range registries {
+		existing[registries[i].Name] = &registries[i]
+	}
+
+	for _, documentRegistry := range document.Registries {
+		password := documentRegistry.Password
+		documentRegistry.Password = ""
+		documentRegistry.AuthorizedUsers = uniqueNames(documentRegistry.AuthorizedUsers)
+		documentRegistry.AuthorizedTeams = uniqueNames(documentRegistry.AuthorizedTeams)
+
+		authorizedUsers, err := a.userIDs(documentRegistry.AuthorizedUsers, RegistryKind, documentRegistry.Name)
+		if err != nil {
+			return err
+		}
+		authorizedTeams, err := a.teamIDs(documentRegistry.AuthorizedTeams, RegistryKind, documentRegistry.Name)
+		if err != nil {
+			return err
+		}
+
+		registry, ok := existing[documentRegistry.Name]
+		if !ok {
+			a.record(RegistryKind, documentRegistry.Name, chainid.ConfigChangeCreate)
+			if a.dryRun {
+				continue
+			}
+
+			registry = &chainid.Registry{
+				Name:            documentRegistry.Name,
+				URL:             documentRegistry.URL,
+				Authentication:  documentRegistry.Authentication,
+				Username:        documentRegistry.Username,
+				Password:        password,
+				AuthorizedUsers: authorizedUsers,
+				AuthorizedTeams: authorizedTeams,
+			}
+			err = a.registryService.CreateRegistry(registry)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		passwordChanged := password != "" && password != registry.Password
+		if reflect.DeepEqual(a.names.documentRegistry(registry), documentRegistry) && !passwordChanged {
+			continue
+		}
+
+		a.record(RegistryKind, documentRegistry.Name, chainid.ConfigChangeUpdate)
+		if a.dryRun {
+			continue
+		}
+
+		registry.URL = documentRegistry.URL
+		registry.Authentication = documentRegistry.Authentication
+		registry.Username = documentRegistry.Username
+		if passwordChanged {
+			registry.Password = password
+		}
+		registry.AuthorizedUsers = authorizedUsers
+		registry.AuthorizedTeams = authorizedTeams
+		err = a.registryService.UpdateRegistry(registry.ID, registry)
+		if err != nil {
+			return err
+		}
+	}
+
+	sort.Slice(registries, func(i, j int) bool { return registries[i].Name < registries[j].Name })
+	for _, registry := range registries {
+		if desired[registry.Name] {
+			continue
+		}
+
+		a.record(RegistryKind, registry.Name, chainid.ConfigChangeDelete)
+		if a.dryRun {
+			continue
+		}
+
+		err = a.registryService.DeleteRegistry(registry.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *applier) applyResourceControls(document *Document) error {
+	if document.ResourceControls == nil {
+		return nil
+	}
+
+	desired := make(map[string]bool)
+	for _, resourceControl := range document.ResourceControls {
+		if resourceControl.ResourceID == "" {
+			return documentError("A resource control must have a resource identifier")
+		}
+		if desired[resourceControl.ResourceID] {
+			return documentError("Resource control %q is defined more than once", resourceControl.ResourceID)
+		}
+		if resourceControl.Type < chainid.ContainerResourceControl || resourceControl.Type > chainid.ConfigResourceControl {
+			return documentError("Invalid type %d for resource control %q", resourceControl.Type, resourceControl.ResourceID)
+		}
+		desired[resourceControl.ResourceID] = true
+	}
+
+	resourceControls, err := a.resourceControlService.ResourceControls()
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]*chainid.ResourceControl)
+	for i := range resourceControls {
+		existing[resourceControls[i].ResourceID] = &resourceControls[i]
+	}
+
+	for _, documentResourceControl := range document.ResourceControls {
+		userAccesses, teamAccesses, err := a.resourceAccesses(&documentResourceControl)
+		if err != nil {
+			return err
+		}
+
+		resourceControl, ok := existing[documentResourceControl.ResourceID]
+		if !ok {
+			a.record(ResourceControlKind, documentResourceControl.ResourceID, chainid.ConfigChangeCreate)
+			if a.dryRun {
+				continue
+			}
+
+			resourceControl = &chainid.ResourceControl{
+				ResourceID:         documentResourceControl.ResourceID,
+				SubResourceIDs:     documentResourceControl.SubResourceIDs,
+				Type:               documentResourceControl.Type,
+				AdministratorsOnly: documentResourceControl.AdministratorsOnly,
+				UserAccesses:       userAccesses,
+				TeamAccesses:       teamAccesses,
+			}
+			err = a.resourceControlService.CreateResourceControl(resourceControl)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if reflect.DeepEqual(a.names.documentResourceControl(resourceControl), documentResourceControl) {
+			continue
+		}
+
+		a.record(ResourceControlKind, documentResourceControl.ResourceID, chainid.ConfigChangeUpdate)
+		if a.dryRun {
+			continue
+		}
+
+		resourceControl.SubResourceIDs = documentResourceControl.SubResourceIDs
+		resourceControl.Type = documentResourceControl.Type
+		resourceControl.AdministratorsOnly = documentResourceControl.AdministratorsOnly
+		resourceControl.UserAccesses = userAccesses
+		resourceControl.TeamAccesses = teamAccesses
+		err = a.resourceControlService.UpdateResourceControl(resourceControl.ID, resourceControl)
+		if err != nil {
+			return err
+		}
+	}
+
+	sort.Slice(resourceControls, func(i, j int) bool { return resourceControls[i].ResourceID < resourceControls[j].ResourceID })
+	for _, resourceControl := range resourceControls {
+		if desired[resourceControl.ResourceID] {
+			continue
+		}
+
+		a.record(ResourceControlKind, resourceControl.ResourceID, chainid.ConfigChangeDelete)
+		if a.dryRun {
+			continue
+		}
+
+		err = a.resourceControlService.DeleteResourceControl(resourceControl.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resourceAccesses resolves the accesses of a resource control. The document resource control
+// is normalized (sorted, without duplicates) so that it can be compared with an exported one.
+func (a *applier) resourceAccesses(documentResourceControl *DocumentResourceControl) ([]chainid.UserResourceAccess, []chainid.TeamResourceAccess, error) {
+	name := documentResourceControl.ResourceID
+
+	documentResourceControl.SubResourceIDs = uniqueNames(documentResourceControl.SubResourceIDs)
+
+	userAccesses := []chainid.UserResourceAccess{}
+	documentUserAccesses := []DocumentUserAccess{}
+	seenUsers := make(map[string]bool)
+	for _, access := range documentResourceControl.UserAccesses {
+		if seenUsers[access.User] {
+			continue
+		}
+		seenUsers[access.User] = true
+
+		userID, ok := a.names.users[access.User]
+		if !ok {
+			return nil, nil, documentError("Unknown user %q referenced by %s %q", access.User, ResourceControlKind, name)
+		}
+		if access.AccessLevel != chainid.ReadWriteAccessLevel {
+			return nil, nil, documentError("Invalid access level %d for %s %q", access.AccessLevel, ResourceControlKind, name)
+		}
+		userAccesses = append(userAccesses, chainid.UserResourceAccess{UserID: userID, AccessLevel: access.AccessLevel})
+		documentUserAccesses = append(documentUserAccesses, access)
+	}
+	sort.Slice(documentUserAccesses, func(i, j int) bool { return documentUserAccesses[i].User < documentUserAccesses[j].User })
+	documentResourceControl.UserAccesses = documentUserAccesses
+
+	teamAccesses := []chainid.TeamResourceAccess{}
+	documentTeamAccesses := []DocumentTeamAccess{}
+	seenTeams := make(map[string]bool)
+	for _, access := range documentResourceControl.TeamAccesses {
+		if seenTeams[access.Team] {
+			continue
+		}
+		seenTeams[access.Team] = true
+
+		teamID, ok := a.names.teams[access.Team]
+		if !ok {
+			return nil, nil, documentError("Unknown team %q referenced by %s %q", access.Team, ResourceControlKind, name)
+		}
+		if access.AccessLevel != chainid.ReadWriteAccessLevel {
+			return nil, nil, documentError("Invalid access level %d for %s %q", access.AccessLevel, ResourceControlKind, name)
+		}
+		teamAccesses = append(teamAccesses, chainid.TeamResourceAccess{TeamID: teamID, AccessLevel: access.AccessLevel})
+		documentTeamAccesses = append(documentTeamAccesses, access)
+	}
+	sort.Slice(documentTeamAccesses, func(i, j int) bool { return documentTeamAccesses[i].Team < documentTeamAccesses[j].Team })
+	documentResourceControl.TeamAccesses = documentTeamAccesses
+
+	return userAccesses, teamAccesses, nil
+}
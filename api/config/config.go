@@ -0,0 +1,131 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/ghodss/yaml"
+)
+
+// Service represents a service used to apply and export declarative configuration documents.
+type Service struct {
+	mu                     sync.Mutex
+	userService            chainid.UserService
+	teamService            chainid.TeamService
+	teamMembershipService  chainid.TeamMembershipService
+	roleAssignmentService  chainid.RoleAssignmentService
+	endpointService        chainid.EndpointService
+	endpointGroupService   chainid.EndpointGroupService
+	registryService        chainid.RegistryService
+	resourceControlService chainid.ResourceControlService
+	settingsService        chainid.SettingsService
+	cryptoService          chainid.CryptoService
+	fileService            chainid.FileService
+	backupScheduler        chainid.BackupScheduler
+	endpointManagement     bool
+}
+
+// ServiceParams represents the parameters required to create a new Service.
+type ServiceParams struct {
+	UserService            chainid.UserService
+	TeamService            chainid.TeamService
+	TeamMembershipService  chainid.TeamMembershipService
+	RoleAssignmentService  chainid.RoleAssignmentService
+	EndpointService        chainid.EndpointService
+	EndpointGroupService   chainid.EndpointGroupService
+	RegistryService        chainid.RegistryService
+	ResourceControlService chainid.ResourceControlService
+	SettingsService        chainid.SettingsService
+	CryptoService          chainid.CryptoService
+	FileService            chainid.FileService
+	BackupScheduler        chainid.BackupScheduler
+	EndpointManagement     bool
+}
+
+// NewService initializes a new service.
+func NewService(parameters *ServiceParams) *Service {
+	return &Service{
+		userService:            parameters.UserService,
+		teamService:            parameters.TeamService,
+		teamMembershipService:  parameters.TeamMembershipService,
+		roleAssignmentService:  parameters.RoleAssignmentService,
+		endpointService:        parameters.EndpointService,
+		endpointGroupService:   parameters.EndpointGroupService,
+		registryService:        parameters.RegistryService,
+		resourceControlService: parameters.ResourceControlService,
+		settingsService:        parameters.SettingsService,
+		cryptoService:          parameters.CryptoService,
+		fileService:            parameters.FileService,
+		backupScheduler:        parameters.BackupScheduler,
+		endpointManagement:     parameters.EndpointManagement,
+	}
+}
+
+// ApplyConfig applies a YAML or JSON configuration document and returns the changes made.
+// The document is always validated against the current data before any change is made,
+// in dry-run mode the changes are only computed.
+func (service *Service) ApplyConfig(data []byte, dryRun bool) ([]chainid.ConfigChange, error) {
+	document, err := decodeDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	changes, err := service.apply(document, true)
+	if err != nil || dryRun {
+		return changes, err
+	}
+
+	return service.apply(document, false)
+}
+
+// ExportConfig returns a configuration document describing the current data in the specified format.
+// Secrets (passwords and keys) are not exported.
+func (service *Service) ExportConfig(format string) ([]byte, error) {
+	if format != chainid.ConfigFormatYAML && format != chainid.ConfigFormatJSON {
+		return nil, chainid.ErrInvalidConfigFormat
+	}
+
+	service.mu.Lock()
+	document, err := service.export()
+	service.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	if format == chainid.ConfigFormatYAML {
+		return yaml.JSONToYAML(data)
+	}
+	return data, nil
+}
+
+// decodeDocument decodes a YAML or JSON document. Unknown fields are rejected
+// to catch typing mistakes.
+func decodeDocument(data []byte) (*Document, error) {
+	data, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, &DocumentError{Message: "Unable to parse the configuration document: " + err.Error()}
+	}
+
+	document := &Document{}
+	err = decodeStrict(data, document)
+	if err != nil {
+		return nil, &DocumentError{Message: "Invalid configuration document: " + err.Error()}
+	}
+	return document, nil
+}
+
+func decodeStrict(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
@@ -0,0 +1,338 @@
+package config
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/bolt"
+	"github.com/chainid-io/dashboard/crypto"
+)
+
+const testDocument = `
+Settings:
+  LogoURL: https://example.com/logo.png
+  LDAPSettings:
+    ReaderDN: cn=reader
+Users:
+  - Username: admin
+    Role: 1
+    Password: admin-password
+  - Username: alice
+    Role: 2
+  - Username: bob
+    Role: 2
+Teams:
+  - Name: developers
+TeamMemberships:
+  - User: alice
+    Team: developers
+    Role: 1
+  - User: bob
+    Team: developers
+    Role: 2
+EndpointGroups:
+  - Name: Unassigned
+    Description: Unassigned endpoints
+  - Name: production
+    Description: Production endpoints
+    Labels:
+      - name: env
+        value: prod
+    AuthorizedTeams: [developers]
+Endpoints:
+  - Name: local
+    URL: unix:///var/run/docker.sock
+  - Name: prod-1
+    URL: tcp://10.0.0.1:2376
+    Group: production
+    TLSConfig:
+      TLS: true
+      TLSSkipVerify: true
+    AuthorizedUsers: [alice]
+  - Name: edge-1
+    Type: 4
+    Group: production
+  - Name: kubernetes-1
+    Type: 5
+    URL: https://10.0.0.2:6443
+    KubernetesToken: kubernetes-token
+Registries:
+  - Name: private
+    URL: registry.example.com
+    Authentication: true
+    Username: deploy
+    Password: registry-password
+    AuthorizedTeams: [developers]
+ResourceControls:
+  - ResourceId: web
+    Type: 6
+    UserAccesses:
+      - User: bob
+        AccessLevel: 1
+`
+
+func newTestService(t *testing.T) (*Service, *bolt.Store, func()) {
+	dataStorePath, err := ioutil.TempDir("", "chainid-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := bolt.NewStore(dataStorePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = store.SettingsService.StoreSettings(&chainid.Settings{
+		TemplatesURL:         "https://example.com/templates.json",
+		AuthenticationMethod: chainid.AuthenticationInternal,
+		LDAPSettings:         chainid.LDAPSettings{Password: "ldap-password"},
+		BlackListedLabels:    []chainid.Pair{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service := NewService(&ServiceParams{
+		UserService:            store.UserService,
+		TeamService:            store.TeamService,
+		TeamMembershipService:  store.TeamMembershipService,
+		RoleAssignmentService:  store.RoleAssignmentService,
+		EndpointService:        store.EndpointService,
+		EndpointGroupService:   store.EndpointGroupService,
+		RegistryService:        store.RegistryService,
+		ResourceControlService: store.ResourceControlService,
+		SettingsService:        store.SettingsService,
+		CryptoService:          &crypto.Service{},
+		EndpointManagement:     true,
+	})
+
+	return service, store, func() {
+		store.Close()
+		os.RemoveAll(dataStorePath)
+	}
+}
+
+func TestApplyConfigIsIdempotent(t *testing.T) {
+	service, store, cleanup := newTestService(t)
+	defer cleanup()
+
+	changes, err := service.ApplyConfig([]byte(testDocument), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// settings, 3 users, 1 team, 2 memberships, 1 group, 4 endpoints, 1 registry, 1 resource control
+	if len(changes) != 14 {
+		t.Fatalf("expected 14 changes, got %d: %v", len(changes), changes)
+	}
+
+	edgeEndpoint := findEndpoint(t, store, "edge-1")
+	if edgeEndpoint.EdgeJoinToken == "" {
+		t.Fatal("a join token must be generated for an edge endpoint")
+	}
+
+	changes, err = service.ApplyConfig([]byte(testDocument), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("expected no change when applying the same document twice, got %v", changes)
+	}
+	if findEndpoint(t, store, "edge-1").EdgeJoinToken != edgeEndpoint.EdgeJoinToken {
+		t.Error("the join token of an edge endpoint must be kept")
+	}
+	if findEndpoint(t, store, "kubernetes-1").KubernetesCredentials.Token != "kubernetes-token" {
+		t.Error("the token of a Kubernetes endpoint must be stored")
+	}
+
+	settings, err := store.SettingsService.Settings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.LogoURL != "https://example.com/logo.png" || settings.LDAPSettings.ReaderDN != "cn=reader" {
+		t.Errorf("settings were not merged: %+v", settings)
+	}
+	if settings.LDAPSettings.Password != "ldap-password" || settings.TemplatesURL != "https://example.com/templates.json" {
+		t.Errorf("settings absent from the document must be kept: %+v", settings)
+	}
+
+	admin, err := store.UserService.UserByUsername("admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if (&crypto.Service{}).CompareHashAndData(admin.Password, "admin-password") != nil {
+		t.Error("the password of the administrator must be hashed")
+	}
+}
+
+func findEndpoint(t *testing.T, store *bolt.Store, name string) *chainid.Endpoint {
+	endpoints, err := store.EndpointService.Endpoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range endpoints {
+		if endpoints[i].Name == name {
+			return &endpoints[i]
+		}
+	}
+	t.Fatalf("endpoint %q not found", name)
+	return nil
+}
+
+func TestExportConfigRoundTrip(t *testing.T) {
+	service, _, cleanup := newTestService(t)
+	defer cleanup()
+
+	_, err := service.ApplyConfig([]byte(testDocument), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, format := range []string{chainid.ConfigFormatYAML, chainid.ConfigFormatJSON} {
+		document, err := service.ExportConfig(format)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, secret := range []string{"admin-password", "registry-password", "ldap-password", "kubernetes-token"} {
+			if bytes.Contains(document, []byte(secret)) {
+				t.Errorf("%s export contains the secret %q", format, secret)
+			}
+		}
+
+		changes, err := service.ApplyConfig(document, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(changes) != 0 {
+			t.Errorf("expected no change when applying the %s export, got %v", format, changes)
+		}
+	}
+}
+
+func TestApplyConfigDryRunAndDeletion(t *testing.T) {
+	service, store, cleanup := newTestService(t)
+	defer cleanup()
+
+	changes, err := service.ApplyConfig([]byte(testDocument), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 14 {
+		t.Fatalf("expected 14 planned changes, got %d: %v", len(changes), changes)
+	}
+
+	users, err := store.UserService.Users()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 0 {
+		t.Fatal("a dry run must not modify the data")
+	}
+
+	_, err = service.ApplyConfig([]byte(testDocument), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err = service.ApplyConfig([]byte("Teams: []\n"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The memberships are removed with the team.
+	if len(changes) != 1 || changes[0].Kind != TeamKind || changes[0].Name != "developers" || changes[0].Action != chainid.ConfigChangeDelete {
+		t.Fatalf("expected the team to be deleted, got %v", changes)
+	}
+
+	memberships, err := store.TeamMembershipService.TeamMemberships()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(memberships) != 0 {
+		t.Errorf("expected the memberships of the removed team to be deleted, got %v", memberships)
+	}
+}
+
+func TestApplyConfigInvalidDocument(t *testing.T) {
+	service, store, cleanup := newTestService(t)
+	defer cleanup()
+
+	documents := []string{
+		"Users:\n  - Username: alice\n    Role: 2\n",
+		"Teams:\n  - Name: developers\nRegistries:\n  - Name: private\n    URL: registry.example.com\n    AuthorizedUsers: [unknown]\n",
+		"Unknown: true\n",
+		"Settings:\n  AuthenticationMethod: 3\n",
+	}
+
+	for _, document := range documents {
+		_, err := service.ApplyConfig([]byte(document), false)
+		if _, ok := err.(*DocumentError); !ok {
+			t.Errorf("expected a document error for %q, got %v", document, err)
+		}
+	}
+
+	teams, err := store.TeamService.Teams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(teams) != 0 {
+		t.Error("an invalid document must not be partially applied")
+	}
+}
+
+func TestApplyConfigPasswordPolicy(t *testing.T) {
+	service, store, cleanup := newTestService(t)
+	defer cleanup()
+
+	settings, err := store.SettingsService.Settings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings.PasswordPolicy = chainid.PasswordPolicy{MinLength: 12, HistorySize: 2}
+	err = store.SettingsService.StoreSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	document := func(password string) []byte {
+		return []byte("Users:\n  - Username: admin\n    Role: 1\n    Password: " + password + "\n")
+	}
+
+	for _, dryRun := range []bool{true, false} {
+		_, err = service.ApplyConfig(document("short"), dryRun)
+		if _, ok := err.(*DocumentError); !ok {
+			t.Errorf("expected a document error for a weak password (dry run: %v), got %v", dryRun, err)
+		}
+	}
+
+	for _, password := range []string{"first-password", "second-password"} {
+		_, err = service.ApplyConfig(document(password), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	admin, err := store.UserService.UserByUsername("admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(admin.PasswordHistory) != 1 {
+		t.Errorf("expected the previous password to be kept in the history, got %v", admin.PasswordHistory)
+	}
+
+	_, err = service.ApplyConfig(document("first-password"), false)
+	if _, ok := err.(*DocumentError); !ok {
+		t.Errorf("expected a document error for a reused password, got %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"encoding/json"
+
+	"github.com/chainid-io/dashboard"
+)
+
+type (
+	// Document represents the declarative configuration of an instance.
+	// Every section is optional. When a section is present, it is authoritative:
+	// the resources it defines are created or updated and the resources of the same
+	// kind that it does not define are removed. Resources reference each other by name.
+	Document struct {
+		Settings         json.RawMessage           `json:"Settings,omitempty"`
+		Users            []DocumentUser            `json:"Users"`
+		Teams            []DocumentTeam            `json:"Teams"`
+		TeamMemberships  []DocumentTeamMembership  `json:"TeamMemberships"`
+		EndpointGroups   []DocumentEndpointGroup   `json:"EndpointGroups"`
+		Endpoints        []DocumentEndpoint        `json:"Endpoints"`
+		Registries       []DocumentRegistry        `json:"Registries"`
+		ResourceControls []DocumentResourceControl `json:"ResourceControls"`
+	}
+
+	// DocumentUser represents a user account. Password is never exported, when it is
+	// empty the password of an existing user is left untouched.
+	DocumentUser struct {
+		Username string           `json:"Username"`
+		Role     chainid.UserRole `json:"Role"`
+		Password string           `json:"Password,omitempty"`
+	}
+
+	// DocumentTeam represents a team.
+	DocumentTeam struct {
+		Name string `json:"Name"`
+	}
+
+	// DocumentTeamMembership represents the membership of a user in a team.
+	DocumentTeamMembership struct {
+		User string                 `json:"User"`
+		Team string                 `json:"Team"`
+		Role chainid.MembershipRole `json:"Role"`
+	}
+
+	// DocumentEndpointGroup represents an endpoint group.
+	DocumentEndpointGroup struct {
+		Name            string         `json:"Name"`
+		Description     string         `json:"Description"`
+		Labels          []chainid.Pair `json:"Labels"`
+		AuthorizedUsers []string       `json:"AuthorizedUsers"`
+		AuthorizedTeams []string       `json:"AuthorizedTeams"`
+	}
+
+	// DocumentEndpoint represents an endpoint. The TLS files must already be present
+	// on the filesystem of the instance. An empty group places the endpoint in the default group.
+	// KubernetesToken is never exported, when it is empty the token of an existing Kubernetes
+	// endpoint is left untouched. SSHConfig is only used by the ssh:// endpoints, its files must
+	// also be present on the filesystem.
+	DocumentEndpoint struct {
+		Name             string                    `json:"Name"`
+		Type             chainid.EndpointType      `json:"Type"`
+		URL              string                    `json:"URL"`
+		PublicURL        string                    `json:"PublicURL"`
+		Group            string                    `json:"Group"`
+		TLSConfig        chainid.TLSConfiguration  `json:"TLSConfig"`
+		SSHConfig        *chainid.SSHConfiguration `json:"SSHConfig,omitempty"`
+		AzureCredentials *chainid.AzureCredentials `json:"AzureCredentials,omitempty"`
+		KubernetesToken  string                    `json:"KubernetesToken,omitempty"`
+		AuthorizedUsers  []string                  `json:"AuthorizedUsers"`
+		AuthorizedTeams  []string                  `json:"AuthorizedTeams"`
+	}
+
+	// DocumentRegistry represents a registry. Password is never exported, when it is
+	// empty the password of an existing registry is left untouched.
+	DocumentRegistry struct {
+		Name            string   `json:"Name"`
+		URL             string   `json:"URL"`
+		Authentication  bool     `json:"Authentication"`
+		Username        string   `json:"Username"`
+		Password        string   `json:"Password,omitempty"`
+		AuthorizedUsers []string `json:"AuthorizedUsers"`
+		AuthorizedTeams []string `json:"AuthorizedTeams"`
+	}
+
+	// DocumentResourceControl represents a resource control, identified by the
+	// identifier of the Docker resource.
+	DocumentResourceControl struct {
+		ResourceID         string                      `json:"ResourceId"`
+		SubResourceIDs     []string                    `json:"SubResourceIds"`
+		Type               chainid.ResourceControlType `json:"Type"`
+		AdministratorsOnly bool                        `json:"AdministratorsOnly"`
+		UserAccesses       []DocumentUserAccess        `json:"UserAccesses"`
+		TeamAccesses       []DocumentTeamAccess        `json:"TeamAccesses"`
+	}
+
+	// DocumentUserAccess represents the access of a user to a resource.
+	DocumentUserAccess struct {
+		User        string                      `json:"User"`
+		AccessLevel chainid.ResourceAccessLevel `json:"AccessLevel"`
+	}
+
+	// DocumentTeamAccess represents the access of a team to a resource.
+	DocumentTeamAccess struct {
+		Team        string                      `json:"Team"`
+		AccessLevel chainid.ResourceAccessLevel `json:"AccessLevel"`
+	}
+
+	// DocumentError represents an error caused by an invalid configuration document.
+	DocumentError struct {
+		Message string
+	}
+)
+
+func (e *DocumentError) Error() string { return e.Message }
@@ -0,0 +1,328 @@
+package config
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/chainid-io/dashboard"
+)
+
+// defaultEndpointGroupID is the identifier of the endpoint group created with the database.
+// It cannot be removed and is referenced by an empty group name in a document.
+const defaultEndpointGroupID = chainid.EndpointGroupID(1)
+
+// resourceNames maps the names used in a document to the identifiers of the resources.
+type resourceNames struct {
+	users  map[string]chainid.UserID
+	teams  map[string]chainid.TeamID
+	groups map[string]chainid.EndpointGroupID
+}
+
+func (service *Service) loadResourceNames() (*resourceNames, error) {
+	names := &resourceNames{
+		users:  make(map[string]chainid.UserID),
+		teams:  make(map[string]chainid.TeamID),
+		groups: make(map[string]chainid.EndpointGroupID),
+	}
+
+	users, err := service.userService.Users()
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		names.users[user.Username] = user.ID
+	}
+
+	teams, err := service.teamService.Teams()
+	if err != nil {
+		return nil, err
+	}
+	for _, team := range teams {
+		names.teams[team.Name] = team.ID
+	}
+
+	groups, err := service.endpointGroupService.EndpointGroups()
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		names.groups[group.Name] = group.ID
+	}
+
+	return names, nil
+}
+
+func (names *resourceNames) username(ID chainid.UserID) (string, bool) {
+	for name, userID := range names.users {
+		if userID == ID {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func (names *resourceNames) teamName(ID chainid.TeamID) (string, bool) {
+	for name, teamID := range names.teams {
+		if teamID == ID {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func (names *resourceNames) groupName(ID chainid.EndpointGroupID) (string, bool) {
+	if ID == defaultEndpointGroupID {
+		return "", true
+	}
+	for name, groupID := range names.groups {
+		if groupID == ID {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// usernames returns the sorted names of the users. Identifiers of users that
+// no longer exist are ignored.
+func (names *resourceNames) usernames(IDs []chainid.UserID) []string {
+	result := make([]string, 0, len(IDs))
+	for _, ID := range IDs {
+		if name, ok := names.username(ID); ok {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// teamNames returns the sorted names of the teams. Identifiers of teams that
+// no longer exist are ignored.
+func (names *resourceNames) teamNames(IDs []chainid.TeamID) []string {
+	result := make([]string, 0, len(IDs))
+	for _, ID := range IDs {
+		if name, ok := names.teamName(ID); ok {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (service *Service) export() (*Document, error) {
+	names, err := service.loadResourceNames()
+	if err != nil {
+		return nil, err
+	}
+
+	document := &Document{}
+
+	settings, err := service.settingsService.Settings()
+	if err != nil {
+		return nil, err
+	}
+	redactSettings(settings)
+	document.Settings, err = json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := service.userService.Users()
+	if err != nil {
+		return nil, err
+	}
+	document.Users = make([]DocumentUser, 0, len(users))
+	for _, user := range users {
+		document.Users = append(document.Users, documentUser(&user))
+	}
+	sort.Slice(document.Users, func(i, j int) bool { return document.Users[i].Username < document.Users[j].Username })
+
+	teams, err := service.teamService.Teams()
+	if err != nil {
+		return nil, err
+	}
+	document.Teams = make([]DocumentTeam, 0, len(teams))
+	for _, team := range teams {
+		document.Teams = append(document.Teams, DocumentTeam{Name: team.Name})
+	}
+	sort.Slice(document.Teams, func(i, j int) bool { return document.Teams[i].Name < document.Teams[j].Name })
+
+	memberships, err := service.teamMembershipService.TeamMemberships()
+	if err != nil {
+		return nil, err
+	}
+	document.TeamMemberships = make([]DocumentTeamMembership, 0, len(memberships))
+	for _, membership := range memberships {
+		if documentMembership, ok := names.documentTeamMembership(&membership); ok {
+			document.TeamMemberships = append(document.TeamMemberships, documentMembership)
+		}
+	}
+	sort.Slice(document.TeamMemberships, func(i, j int) bool {
+		return membershipKey(&document.TeamMemberships[i]) < membershipKey(&document.TeamMemberships[j])
+	})
+
+	groups, err := service.endpointGroupService.EndpointGroups()
+	if err != nil {
+		return nil, err
+	}
+	document.EndpointGroups = make([]DocumentEndpointGroup, 0, len(groups))
+	for _, group := range groups {
+		document.EndpointGroups = append(document.EndpointGroups, names.documentEndpointGroup(&group))
+	}
+	sort.Slice(document.EndpointGroups, func(i, j int) bool { return document.EndpointGroups[i].Name < document.EndpointGroups[j].Name })
+
+	endpoints, err := service.endpointService.Endpoints()
+	if err != nil {
+		return nil, err
+	}
+	document.Endpoints = make([]DocumentEndpoint, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		// The endpoints defined in the external endpoints source are not part of the configuration.
+		if endpoint.ExternallyManaged {
+			continue
+		}
+		document.Endpoints = append(document.Endpoints, names.documentEndpoint(&endpoint))
+	}
+	sort.Slice(document.Endpoints, func(i, j int) bool { return document.Endpoints[i].Name < document.Endpoints[j].Name })
+
+	registries, err := service.registryService.Registries()
+	if err != nil {
+		return nil, err
+	}
+	document.Registries = make([]DocumentRegistry, 0, len(registries))
+	for _, registry := range registries {
+		document.Registries = append(document.Registries, names.documentRegistry(&registry))
+	}
+	sort.Slice(document.Registries, func(i, j int) bool { return document.Registries[i].Name < document.Registries[j].Name })
+
+	resourceControls, err := service.resourceControlService.ResourceControls()
+	if err != nil {
+		return nil, err
+	}
+	document.ResourceControls = make([]DocumentResourceControl, 0, len(resourceControls))
+	for _, resourceControl := range resourceControls {
+		document.ResourceControls = append(document.ResourceControls, names.documentResourceControl(&resourceControl))
+	}
+	sort.Slice(document.ResourceControls, func(i, j int) bool {
+		return document.ResourceControls[i].ResourceID < document.ResourceControls[j].ResourceID
+	})
+
+	return document, nil
+}
+
+// redactSettings removes the secrets from the settings.
+func redactSettings(settings *chainid.Settings) {
+	settings.LDAPSettings.Password = ""
+	settings.BackupSettings.Password = ""
+	settings.BackupSettings.S3.SecretAccessKey = ""
+}
+
+func documentUser(user *chainid.User) DocumentUser {
+	return DocumentUser{
+		Username: user.Username,
+		Role:     user.Role,
+	}
+}
+
+func membershipKey(membership *DocumentTeamMembership) string {
+	return membership.Team + "/" + membership.User
+}
+
+func (names *resourceNames) documentTeamMembership(membership *chainid.TeamMembership) (DocumentTeamMembership, bool) {
+	username, userExists := names.username(membership.UserID)
+	teamName, teamExists := names.teamName(membership.TeamID)
+	return DocumentTeamMembership{
+		User: username,
+		Team: teamName,
+		Role: membership.Role,
+	}, userExists && teamExists
+}
+
+func (names *resourceNames) documentEndpointGroup(group *chainid.EndpointGroup) DocumentEndpointGroup {
+	labels := group.Labels
+	if labels == nil {
+		labels = []chainid.Pair{}
+	}
+
+	return DocumentEndpointGroup{
+		Name:            group.Name,
+		Description:     group.Description,
+		Labels:          labels,
+		AuthorizedUsers: names.usernames(group.AuthorizedUsers),
+		AuthorizedTeams: names.teamNames(group.AuthorizedTeams),
+	}
+}
+
+func (names *resourceNames) documentEndpoint(endpoint *chainid.Endpoint) DocumentEndpoint {
+	groupName, _ := names.groupName(endpoint.GroupID)
+
+	documentEndpoint := DocumentEndpoint{
+		Name:            endpoint.Name,
+		Type:            endpoint.Type,
+		URL:             endpoint.URL,
+		PublicURL:       endpoint.PublicURL,
+		Group:           groupName,
+		TLSConfig:       endpoint.TLSConfig,
+		AuthorizedUsers: names.usernames(endpoint.AuthorizedUsers),
+		AuthorizedTeams: names.teamNames(endpoint.AuthorizedTeams),
+	}
+
+	if strings.HasPrefix(endpoint.URL, "ssh://") {
+		sshConfig := endpoint.SSHConfig
+		documentEndpoint.SSHConfig = &sshConfig
+	}
+
+	if endpoint.Type == chainid.AzureEnvironment {
+		documentEndpoint.AzureCredentials = &chainid.AzureCredentials{
+			ApplicationID: endpoint.AzureCredentials.ApplicationID,
+			TenantID:      endpoint.AzureCredentials.TenantID,
+		}
+	}
+
+	return documentEndpoint
+}
+
+func (names *resourceNames) documentRegistry(registry *chainid.Registry) DocumentRegistry {
+	return DocumentRegistry{
+		Name:            registry.Name,
+		URL:             registry.URL,
+		Authentication:  registry.Authentication,
+		Username:        registry.Username,
+		AuthorizedUsers: names.usernames(registry.AuthorizedUsers),
+		AuthorizedTeams: names.teamNames(registry.AuthorizedTeams),
+	}
+}
+
+func (names *resourceNames) documentResourceControl(resourceControl *chainid.ResourceControl) DocumentResourceControl {
+	subResourceIDs := append([]string{}, resourceControl.SubResourceIDs...)
+	sort.Strings(subResourceIDs)
+
+	documentResourceControl := DocumentResourceControl{
+		ResourceID:         resourceControl.ResourceID,
+		SubResourceIDs:     subResourceIDs,
+		Type:               resourceControl.Type,
+		AdministratorsOnly: resourceControl.AdministratorsOnly,
+		UserAccesses:       []DocumentUserAccess{},
+		TeamAccesses:       []DocumentTeamAccess{},
+	}
+
+	for _, access := range resourceControl.UserAccesses {
+		if username, ok := names.username(access.UserID); ok {
+			documentResourceControl.UserAccesses = append(documentResourceControl.UserAccesses, DocumentUserAccess{User: username, AccessLevel: access.AccessLevel})
+		}
+	}
+	sort.Slice(documentResourceControl.UserAccesses, func(i, j int) bool {
+		return documentResourceControl.UserAccesses[i].User < documentResourceControl.UserAccesses[j].User
+	})
+
+	for _, access := range resourceControl.TeamAccesses {
+		if teamName, ok := names.teamName(access.TeamID); ok {
+			documentResourceControl.TeamAccesses = append(documentResourceControl.TeamAccesses, DocumentTeamAccess{Team: teamName, AccessLevel: access.AccessLevel})
+		}
+	}
+	sort.Slice(documentResourceControl.TeamAccesses, func(i, j int) bool {
+		return documentResourceControl.TeamAccesses[i].Team < documentResourceControl.TeamAccesses[j].Team
+	})
+
+	return documentResourceControl
+}
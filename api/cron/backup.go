@@ -0,0 +1,158 @@
+package cron
+
+import (
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/backup"
+	"github.com/robfig/cron"
+)
+
+type (
+	// BackupScheduler represents a service used to run the scheduled backups.
+	BackupScheduler struct {
+		mu                  sync.Mutex
+		cron                *cron.Cron
+		backupService       chainid.BackupService
+		backupStatusService chainid.BackupStatusService
+		clusterService      chainid.ClusterService
+	}
+
+	backupJob struct {
+		logger              *log.Logger
+		settings            chainid.BackupSettings
+		target              backup.Target
+		backupService       chainid.BackupService
+		backupStatusService chainid.BackupStatusService
+		clusterService      chainid.ClusterService
+	}
+)
+
+// NewBackupScheduler initializes a new service. The scheduled backups are only run
+// by the leader of the cluster.
+func NewBackupScheduler(backupService chainid.BackupService, backupStatusService chainid.BackupStatusService, clusterService chainid.ClusterService) *BackupScheduler {
+	return &BackupScheduler{
+		backupService:       backupService,
+		backupStatusService: backupStatusService,
+		clusterService:      clusterService,
+	}
+}
+
+// ScheduleBackups replaces the current backup schedule with the one defined in the settings.
+// Scheduled backups are stopped when they are disabled in the settings.
+func (scheduler *BackupScheduler) ScheduleBackups(settings *chainid.BackupSettings) error {
+	var schedule cron.Schedule
+	var target backup.Target
+
+	if settings.Enabled {
+		var err error
+		schedule, err = cron.ParseStandard(settings.Schedule)
+		if err != nil {
+			return chainid.ErrInvalidBackupSchedule
+		}
+
+		if settings.Retention < 0 {
+			return chainid.ErrInvalidBackupTarget
+		}
+
+		target, err = backup.NewTarget(settings)
+		if err != nil {
+			return err
+		}
+	}
+
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+
+	if scheduler.cron != nil {
+		scheduler.cron.Stop()
+		scheduler.cron = nil
+	}
+
+	if !settings.Enabled {
+		return nil
+	}
+
+	job := &backupJob{
+		logger:              log.New(os.Stderr, "", log.LstdFlags),
+		settings:            *settings,
+		target:              target,
+		backupService:       scheduler.backupService,
+		backupStatusService: scheduler.backupStatusService,
+		clusterService:      scheduler.clusterService,
+	}
+
+	scheduler.cron = cron.New()
+	scheduler.cron.Schedule(schedule, job)
+	scheduler.cron.Start()
+	return nil
+}
+
+// Run creates an archive, stores it on the target, removes the archives exceeding
+// the retention and records the outcome in the backup status.
+func (job *backupJob) Run() {
+	if !job.clusterService.IsLeader() {
+		return
+	}
+
+	now := time.Now()
+	name := backup.ArchiveName(now, job.settings.Password != "")
+
+	err := job.backup(name)
+	if err != nil {
+		job.logger.Printf("Scheduled backup error: %s", err)
+	}
+
+	status, statusErr := job.backupStatusService.BackupStatus()
+	if statusErr == chainid.ErrBackupStatusNotFound {
+		status = &chainid.BackupStatus{}
+	} else if statusErr != nil {
+		job.logger.Printf("Unable to retrieve backup status: %s", statusErr)
+		return
+	}
+
+	status.LastRunAt = now.Unix()
+	status.LastError = ""
+	if err != nil {
+		status.LastError = err.Error()
+	} else {
+		status.LastSuccessAt = now.Unix()
+		status.LastArchive = name
+	}
+
+	statusErr = job.backupStatusService.StoreBackupStatus(status)
+	if statusErr != nil {
+		job.logger.Printf("Unable to store backup status: %s", statusErr)
+	}
+}
+
+func (job *backupJob) backup(name string) error {
+	file, err := ioutil.TempFile("", name)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	err = job.backupService.CreateBackup(file, job.settings.Password)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	err = job.target.Store(name, file)
+	if err != nil {
+		return err
+	}
+
+	return backup.Rotate(job.target, job.settings.Retention)
+}
@@ -0,0 +1,179 @@
+package cron
+
+import (
+	"log"
+	"os"
+
+	"github.com/chainid-io/dashboard"
+)
+
+// endpointDiscoveryJob synchronizes the endpoints with the Docker hosts found by a discovery provider.
+// It only manages the endpoints recorded with the name of its provider as discovery source.
+type endpointDiscoveryJob struct {
+	logger               *log.Logger
+	provider             chainid.EndpointDiscoveryProvider
+	endpointService      chainid.EndpointService
+	endpointGroupService chainid.EndpointGroupService
+	clusterService       chainid.ClusterService
+}
+
+func newEndpointDiscoveryJob(provider chainid.EndpointDiscoveryProvider, endpointService chainid.EndpointService, endpointGroupService chainid.EndpointGroupService, clusterService chainid.ClusterService) *endpointDiscoveryJob {
+	return &endpointDiscoveryJob{
+		logger:               log.New(os.Stderr, "", log.LstdFlags),
+		provider:             provider,
+		endpointService:      endpointService,
+		endpointGroupService: endpointGroupService,
+		clusterService:       clusterService,
+	}
+}
+
+// groupIDs returns the identifiers of the endpoint groups indexed by name.
+func (job *endpointDiscoveryJob) groupIDs() (map[string]chainid.EndpointGroupID, error) {
+	groups, err := job.endpointGroupService.EndpointGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	groupIDs := make(map[string]chainid.EndpointGroupID)
+	for _, group := range groups {
+		groupIDs[group.Name] = group.ID
+	}
+	return groupIDs, nil
+}
+
+// convertDiscoveredEndpoint returns the endpoint of a discovered Docker host, the default
+// endpoint group is used when the group of the host does not exist.
+func (job *endpointDiscoveryJob) convertDiscoveredEndpoint(discovered *chainid.DiscoveredEndpoint, groupIDs map[string]chainid.EndpointGroupID) chainid.Endpoint {
+	endpoint := chainid.Endpoint{
+		Name:              discovered.Name,
+		Type:              chainid.DockerEnvironment,
+		URL:               discovered.URL,
+		GroupID:           defaultEndpointGroupID,
+		PublicURL:         discovered.PublicURL,
+		TLSConfig:         discovered.TLSConfig,
+		AuthorizedUsers:   []chainid.UserID{},
+		AuthorizedTeams:   []chainid.TeamID{},
+		Extensions:        []chainid.EndpointExtension{},
+		ExternallyManaged: true,
+		DiscoverySource:   job.provider.Name(),
+	}
+
+	if discovered.Group != "" {
+		groupID, ok := groupIDs[discovered.Group]
+		if ok {
+			endpoint.GroupID = groupID
+		} else {
+			job.logger.Printf("Endpoint group of discovered endpoint not found, using the default group. [name: %v] [group: %v]", discovered.Name, discovered.Group)
+		}
+	}
+
+	// The agent is always reached over TLS, its certificate is not verified unless TLS is configured.
+	if discovered.Agent {
+		endpoint.Type = chainid.AgentOnDockerEnvironment
+		if !endpoint.TLSConfig.TLS {
+			endpoint.TLSConfig = chainid.TLSConfiguration{TLS: true, TLSSkipVerify: true}
+		}
+	}
+	return endpoint
+}
+
+func (job *endpointDiscoveryJob) prepareSyncData(storedEndpoints, discoveredEndpoints []chainid.Endpoint) *synchronization {
+	endpointsToCreate := make([]*chainid.Endpoint, 0)
+	endpointsToUpdate := make([]*chainid.Endpoint, 0)
+	endpointsToDelete := make([]*chainid.Endpoint, 0)
+
+	stored := make(map[string]*chainid.Endpoint)
+	for idx := range storedEndpoints {
+		stored[storedEndpoints[idx].Name] = &storedEndpoints[idx]
+	}
+
+	discovered := make(map[string]bool)
+	for idx := range discoveredEndpoints {
+		endpoint := &discoveredEndpoints[idx]
+		if discovered[endpoint.Name] {
+			job.logger.Printf("Discovered endpoint defined more than once, skipping. [name: %v] [url: %v]", endpoint.Name, endpoint.URL)
+			continue
+		}
+		discovered[endpoint.Name] = true
+
+		original, ok := stored[endpoint.Name]
+		if !ok {
+			job.logger.Printf("Discovered endpoint not found in database, adding to database. [name: %v] [url: %v]", endpoint.Name, endpoint.URL)
+			endpointsToCreate = append(endpointsToCreate, endpoint)
+			continue
+		}
+
+		if original.DiscoverySource != endpoint.DiscoverySource {
+			job.logger.Printf("Discovered endpoint has the same name as an endpoint managed by another source, skipping. [name: %v] [url: %v]", endpoint.Name, endpoint.URL)
+			continue
+		}
+
+		if original.Type != endpoint.Type || original.URL != endpoint.URL || original.GroupID != endpoint.GroupID ||
+			original.PublicURL != endpoint.PublicURL || original.TLSConfig != endpoint.TLSConfig {
+			job.logger.Printf("New definition for a discovered endpoint found, updating database. [name: %v] [url: %v]", endpoint.Name, endpoint.URL)
+			original.Type = endpoint.Type
+			original.URL = endpoint.URL
+			original.GroupID = endpoint.GroupID
+			original.PublicURL = endpoint.PublicURL
+			original.TLSConfig = endpoint.TLSConfig
+			endpointsToUpdate = append(endpointsToUpdate, original)
+		}
+	}
+
+	for idx := range storedEndpoints {
+		endpoint := &storedEndpoints[idx]
+		if endpoint.DiscoverySource == job.provider.Name() && !discovered[endpoint.Name] {
+			job.logger.Printf("Stored endpoint no longer discovered, removing from database. [name: %v] [url: %v]", endpoint.Name, endpoint.URL)
+			endpointsToDelete = append(endpointsToDelete, endpoint)
+		}
+	}
+
+	return &synchronization{
+		endpointsToCreate: endpointsToCreate,
+		endpointsToUpdate: endpointsToUpdate,
+		endpointsToDelete: endpointsToDelete,
+	}
+}
+
+func (job *endpointDiscoveryJob) Sync() error {
+	discoveredEndpoints, err := job.provider.Discover()
+	if err != nil {
+		return err
+	}
+
+	groupIDs, err := job.groupIDs()
+	if err != nil {
+		return err
+	}
+
+	convertedEndpoints := make([]chainid.Endpoint, 0, len(discoveredEndpoints))
+	for idx := range discoveredEndpoints {
+		convertedEndpoints = append(convertedEndpoints, job.convertDiscoveredEndpoint(&discoveredEndpoints[idx], groupIDs))
+	}
+
+	storedEndpoints, err := job.endpointService.Endpoints()
+	if err != nil {
+		return err
+	}
+
+	sync := job.prepareSyncData(storedEndpoints, convertedEndpoints)
+	if sync.requireSync() {
+		err = job.endpointService.Synchronize(sync.endpointsToCreate, sync.endpointsToUpdate, sync.endpointsToDelete)
+		if err != nil {
+			return err
+		}
+		job.logger.Printf("Endpoint discovery ended. [provider: %v] [created: %v] [updated: %v] [deleted: %v]", job.provider.Name(), len(sync.endpointsToCreate), len(sync.endpointsToUpdate), len(sync.endpointsToDelete))
+	}
+	return nil
+}
+
+func (job *endpointDiscoveryJob) Run() {
+	if !job.clusterService.IsLeader() {
+		return
+	}
+
+	err := job.Sync()
+	if err != nil {
+		job.logger.Printf("Endpoint discovery error: %s [provider: %v]", err, job.provider.Name())
+	}
+}
@@ -0,0 +1,86 @@
+package cron
+
+import (
+	"testing"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/kv"
+)
+
+type staticDiscoveryProvider []chainid.DiscoveredEndpoint
+
+func (staticDiscoveryProvider) Name() string { return "static" }
+func (provider staticDiscoveryProvider) Discover() ([]chainid.DiscoveredEndpoint, error) {
+	return provider, nil
+}
+
+func TestEndpointDiscoveryJob(t *testing.T) {
+	store := kv.NewStore(kv.NewMemoryBackend())
+	group := &chainid.EndpointGroup{Name: "web"}
+	err := store.EndpointGroupService.CreateEndpointGroup(group)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, endpoint := range []*chainid.Endpoint{
+		{Name: "api", Type: chainid.DockerEnvironment, URL: "tcp://10.0.0.9:2375"},
+		{Name: "removed", Type: chainid.DockerEnvironment, URL: "tcp://10.0.0.8:2375", ExternallyManaged: true, DiscoverySource: "static"},
+		{Name: "other", Type: chainid.DockerEnvironment, URL: "tcp://10.0.0.7:2375", ExternallyManaged: true, DiscoverySource: "consul:docker"},
+	} {
+		err = store.EndpointService.CreateEndpoint(endpoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	provider := staticDiscoveryProvider{
+		{Name: "web-1", URL: "tcp://10.0.0.1:2375", Group: "web"},
+		{Name: "agent", URL: "tcp://10.0.0.2:9001", Group: "unknown", Agent: true},
+		{Name: "api", URL: "tcp://10.0.0.3:2375"},
+	}
+	job := newEndpointDiscoveryJob(provider, store.EndpointService, store.EndpointGroupService, leaderClusterService{})
+	err = job.Sync()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	endpoints, err := store.EndpointService.Endpoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byName := make(map[string]chainid.Endpoint)
+	for _, endpoint := range endpoints {
+		byName[endpoint.Name] = endpoint
+	}
+	if len(byName) != 4 {
+		t.Fatalf("expected 4 endpoints, got %+v", endpoints)
+	}
+	if web := byName["web-1"]; web.GroupID != group.ID || web.DiscoverySource != "static" || !web.ExternallyManaged {
+		t.Errorf("unexpected discovered endpoint %+v", web)
+	}
+	if agent := byName["agent"]; agent.Type != chainid.AgentOnDockerEnvironment || agent.GroupID != defaultEndpointGroupID || !agent.TLSConfig.TLS {
+		t.Errorf("unexpected agent endpoint %+v", agent)
+	}
+	if api := byName["api"]; api.DiscoverySource != "" || api.URL != "tcp://10.0.0.9:2375" {
+		t.Errorf("expected the endpoint managed via the API to be kept, got %+v", api)
+	}
+	if _, ok := byName["other"]; !ok {
+		t.Error("expected the endpoint of the other provider to be kept")
+	}
+
+	// A host whose definition changes is updated in place.
+	provider[0].URL = "tcp://10.0.0.1:2376"
+	err = job.Sync()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := store.EndpointService.Endpoint(byName["web-1"].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.URL != "tcp://10.0.0.1:2376" {
+		t.Errorf("expected the discovered endpoint to be updated, got %+v", updated)
+	}
+}
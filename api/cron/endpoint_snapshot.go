@@ -0,0 +1,115 @@
+package cron
+
+import (
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/http/client"
+	"github.com/chainid-io/dashboard/http/proxy"
+	"github.com/robfig/cron"
+)
+
+type (
+	// EndpointSnapshotter represents a service used to periodically take a snapshot of
+	// the Docker environment of the endpoints.
+	EndpointSnapshotter struct {
+		cron *cron.Cron
+		job  *endpointSnapshotJob
+	}
+
+	endpointSnapshotJob struct {
+		logger          *log.Logger
+		running         int32
+		endpointService chainid.EndpointService
+		clusterService  chainid.ClusterService
+		transports      *endpointTransports
+	}
+)
+
+// NewEndpointSnapshotter initializes a new service. The snapshots are only taken
+// by the leader of the cluster.
+func NewEndpointSnapshotter(endpointService chainid.EndpointService, clusterService chainid.ClusterService, proxyManager *proxy.Manager) *EndpointSnapshotter {
+	return &EndpointSnapshotter{
+		job: &endpointSnapshotJob{
+			logger:          log.New(os.Stderr, "", log.LstdFlags),
+			endpointService: endpointService,
+			clusterService:  clusterService,
+			transports:      newEndpointTransports(proxyManager),
+		},
+	}
+}
+
+// Start takes a first snapshot of every endpoint in the background and starts a cron job
+// taking a snapshot at the specified interval.
+func (snapshotter *EndpointSnapshotter) Start(interval string) error {
+	snapshotter.cron = cron.New()
+	err := snapshotter.cron.AddJob("@every "+interval, snapshotter.job)
+	if err != nil {
+		return err
+	}
+
+	go snapshotter.job.Run()
+	snapshotter.cron.Start()
+	return nil
+}
+
+// Stop stops the cron job.
+func (snapshotter *EndpointSnapshotter) Stop() {
+	if snapshotter.cron != nil {
+		snapshotter.cron.Stop()
+	}
+}
+
+// Run takes a snapshot of every endpoint concurrently. The last snapshot of an endpoint
+// is kept when the endpoint cannot be reached. A run is skipped when the previous one
+// is not finished yet.
+func (job *endpointSnapshotJob) Run() {
+	if !job.clusterService.IsLeader() {
+		return
+	}
+
+	if !atomic.CompareAndSwapInt32(&job.running, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&job.running, 0)
+
+	endpoints, err := job.endpointService.Endpoints()
+	if err != nil {
+		job.logger.Printf("Endpoint snapshot error: %s", err)
+		return
+	}
+
+	transports := job.transports.update(endpoints, job.logger)
+
+	var wg sync.WaitGroup
+	for ID, transport := range transports {
+		wg.Add(1)
+		go func(ID chainid.EndpointID, transport *endpointTransport) {
+			defer wg.Done()
+
+			snapshot, err := client.ExecuteSnapshotOperation(transport.target, transport.transport)
+			if err != nil {
+				job.logger.Printf("Unable to take a snapshot of endpoint %d: %s", ID, err)
+				return
+			}
+
+			err = job.storeSnapshot(ID, snapshot)
+			if err != nil && err != chainid.ErrEndpointNotFound {
+				job.logger.Printf("Unable to store the snapshot of endpoint %d: %s", ID, err)
+			}
+		}(ID, transport)
+	}
+	wg.Wait()
+}
+
+// storeSnapshot replaces the snapshot of the endpoint. Only the snapshots of the endpoint are
+// updated, inside a single transaction, to avoid overwriting the changes made while the
+// snapshot was taken.
+func (job *endpointSnapshotJob) storeSnapshot(ID chainid.EndpointID, snapshot *chainid.Snapshot) error {
+	return job.endpointService.UpdateEndpointFunc(ID, func(endpoint *chainid.Endpoint) {
+		endpoint.Snapshots = []chainid.Snapshot{*snapshot}
+	})
+}
@@ -0,0 +1,90 @@
+package cron
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/http/proxy"
+	"github.com/chainid-io/dashboard/kv"
+)
+
+func TestEndpointSnapshotJob(t *testing.T) {
+	responses := map[string]string{
+		"/info":            `{"ServerVersion":"18.06.1-ce","NCPU":2,"MemTotal":2048,"Swarm":{"LocalNodeState":"active","ControlAvailable":true,"Cluster":{"ID":"swarm1"}}}`,
+		"/containers/json": `[{"Id":"c1","Names":["/web.1"],"Image":"nginx:1.15","State":"running","Labels":{"com.docker.swarm.service.id":"s1"}},{"Id":"c2","Names":["/db"],"Image":"postgres","State":"exited"},{"Id":"c3","Names":["/cache"],"Image":"redis","State":"running"}]`,
+		"/images/json":     `[{},{}]`,
+		"/volumes":         `{"Volumes":[{"Name":"data"}]}`,
+		"/networks":        `[{"Id":"n1","Name":"bridge"}]`,
+		"/services":        `[{"ID":"s1","Spec":{"Name":"web","Labels":{"com.docker.stack.namespace":"front"},"TaskTemplate":{"ContainerSpec":{"Image":"nginx:1.15"}}}}]`,
+		"/nodes":           `[{"Description":{"Resources":{"NanoCPUs":2000000000,"MemoryBytes":2048}}},{"Description":{"Resources":{"NanoCPUs":4000000000,"MemoryBytes":4096}}}]`,
+	}
+	dockerAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(response))
+	}))
+
+	store := kv.NewStore(kv.NewMemoryBackend())
+	endpoint := &chainid.Endpoint{Name: "swarm", Type: chainid.DockerEnvironment, URL: strings.Replace(dockerAPI.URL, "http://", "tcp://", 1)}
+	err := store.EndpointService.CreateEndpoint(endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snapshotter := NewEndpointSnapshotter(store.EndpointService, leaderClusterService{}, proxy.NewManager(&proxy.ManagerParams{}))
+	snapshotter.job.Run()
+
+	endpoint, err = store.EndpointService.Endpoint(endpoint.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(endpoint.Snapshots) != 1 {
+		t.Fatalf("expected a snapshot to be stored, got %+v", endpoint.Snapshots)
+	}
+
+	expected := chainid.Snapshot{
+		Time:                  endpoint.Snapshots[0].Time,
+		DockerVersion:         "18.06.1-ce",
+		Swarm:                 true,
+		SwarmID:               "swarm1",
+		NodeCount:             2,
+		TotalCPU:              6,
+		TotalMemory:           6144,
+		ContainerCount:        3,
+		RunningContainerCount: 2,
+		StoppedContainerCount: 1,
+		ServiceCount:          1,
+		VolumeCount:           1,
+		ImageCount:            2,
+		Resources: []chainid.SnapshotResource{
+			{Type: chainid.ContainerSearchResult, ID: "c1", Name: "web.1", Image: "nginx:1.15", Labels: map[string]string{"com.docker.swarm.service.id": "s1"}},
+			{Type: chainid.ContainerSearchResult, ID: "c2", Name: "db", Image: "postgres"},
+			{Type: chainid.ContainerSearchResult, ID: "c3", Name: "cache", Image: "redis"},
+			{Type: chainid.VolumeSearchResult, ID: "data", Name: "data"},
+			{Type: chainid.NetworkSearchResult, ID: "n1", Name: "bridge"},
+			{Type: chainid.ServiceSearchResult, ID: "s1", Name: "web", Image: "nginx:1.15", Labels: map[string]string{"com.docker.stack.namespace": "front"}},
+		},
+	}
+	if !reflect.DeepEqual(endpoint.Snapshots[0], expected) {
+		t.Errorf("unexpected snapshot: %+v", endpoint.Snapshots[0])
+	}
+
+	// The last snapshot is kept when the endpoint cannot be reached.
+	dockerAPI.Close()
+	snapshotter.job.Run()
+
+	endpoint, err = store.EndpointService.Endpoint(endpoint.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(endpoint.Snapshots) != 1 || !reflect.DeepEqual(endpoint.Snapshots[0], expected) {
+		t.Errorf("expected the last snapshot to be kept, got %+v", endpoint.Snapshots)
+	}
+}
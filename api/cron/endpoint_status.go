@@ -0,0 +1,138 @@
+package cron
+
+import (
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/http/client"
+	"github.com/chainid-io/dashboard/http/proxy"
+	"github.com/robfig/cron"
+)
+
+// endpointStatusHistorySize is the number of status checks kept for each endpoint.
+const endpointStatusHistorySize = 20
+
+type (
+	// EndpointStatusChecker represents a service used to periodically check the status
+	// of the endpoints.
+	EndpointStatusChecker struct {
+		cron *cron.Cron
+		job  *endpointStatusJob
+	}
+
+	endpointStatusJob struct {
+		logger          *log.Logger
+		running         int32
+		endpointService chainid.EndpointService
+		clusterService  chainid.ClusterService
+		transports      *endpointTransports
+	}
+)
+
+// NewEndpointStatusChecker initializes a new service. The status checks are only run
+// by the leader of the cluster.
+func NewEndpointStatusChecker(endpointService chainid.EndpointService, clusterService chainid.ClusterService, proxyManager *proxy.Manager) *EndpointStatusChecker {
+	return &EndpointStatusChecker{
+		job: &endpointStatusJob{
+			logger:          log.New(os.Stderr, "", log.LstdFlags),
+			endpointService: endpointService,
+			clusterService:  clusterService,
+			transports:      newEndpointTransports(proxyManager),
+		},
+	}
+}
+
+// Start starts a cron job checking the status of every endpoint at the specified interval.
+func (checker *EndpointStatusChecker) Start(interval string) error {
+	checker.cron = cron.New()
+	err := checker.cron.AddJob("@every "+interval, checker.job)
+	if err != nil {
+		return err
+	}
+
+	checker.cron.Start()
+	return nil
+}
+
+// Stop stops the cron job.
+func (checker *EndpointStatusChecker) Stop() {
+	if checker.cron != nil {
+		checker.cron.Stop()
+	}
+}
+
+// Run checks the status of every endpoint concurrently and records the results. A run
+// is skipped when the previous one is not finished yet.
+func (job *endpointStatusJob) Run() {
+	if !job.clusterService.IsLeader() {
+		return
+	}
+
+	if !atomic.CompareAndSwapInt32(&job.running, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&job.running, 0)
+
+	endpoints, err := job.endpointService.Endpoints()
+	if err != nil {
+		job.logger.Printf("Endpoint status check error: %s", err)
+		return
+	}
+
+	transports := job.transports.update(endpoints, job.logger)
+
+	var wg sync.WaitGroup
+	for ID, transport := range transports {
+		wg.Add(1)
+		go func(ID chainid.EndpointID, transport *endpointTransport) {
+			defer wg.Done()
+
+			check := checkEndpointStatus(transport)
+			err := job.storeStatusCheck(ID, check)
+			if err != nil && err != chainid.ErrEndpointNotFound {
+				job.logger.Printf("Unable to store the status of endpoint %d: %s", ID, err)
+			}
+		}(ID, transport)
+	}
+	wg.Wait()
+}
+
+func checkEndpointStatus(transport *endpointTransport) *chainid.EndpointStatusCheck {
+	start := time.Now()
+	version, err := client.ExecuteVersionOperation(transport.target, transport.transport)
+
+	check := &chainid.EndpointStatusCheck{
+		Status:        chainid.EndpointStatusUp,
+		CheckedAt:     start.Unix(),
+		Latency:       int64(time.Since(start) / time.Millisecond),
+		DockerVersion: version,
+	}
+	if err != nil {
+		check.Status = chainid.EndpointStatusDown
+		check.Latency = 0
+		check.Error = err.Error()
+	}
+	return check
+}
+
+// storeStatusCheck records a status check on the endpoint. Only the status of the endpoint is
+// updated, inside a single transaction, to avoid overwriting the changes made while its status
+// was checked.
+func (job *endpointStatusJob) storeStatusCheck(ID chainid.EndpointID, check *chainid.EndpointStatusCheck) error {
+	return job.endpointService.UpdateEndpointFunc(ID, func(endpoint *chainid.Endpoint) {
+		endpoint.Status = check.Status
+		endpoint.Latency = check.Latency
+		if check.DockerVersion != "" {
+			endpoint.DockerVersion = check.DockerVersion
+		}
+
+		endpoint.StatusHistory = append(endpoint.StatusHistory, *check)
+		if len(endpoint.StatusHistory) > endpointStatusHistorySize {
+			endpoint.StatusHistory = endpoint.StatusHistory[len(endpoint.StatusHistory)-endpointStatusHistorySize:]
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package cron
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/http/proxy"
+	"github.com/chainid-io/dashboard/kv"
+)
+
+type leaderClusterService struct{}
+
+func (leaderClusterService) Status() (*chainid.ClusterStatus, error) {
+	return &chainid.ClusterStatus{}, nil
+}
+func (leaderClusterService) IsLeader() bool { return true }
+func (leaderClusterService) Lock(name string) (context.Context, error) {
+	return context.Background(), nil
+}
+func (leaderClusterService) Unlock(name string) error { return nil }
+func (leaderClusterService) SharedKey(name string, generate func() ([]byte, error)) ([]byte, error) {
+	return generate()
+}
+
+func TestEndpointStatusJob(t *testing.T) {
+	dockerAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/_ping":
+			w.Write([]byte("OK"))
+		case "/version":
+			w.Write([]byte(`{"Version":"18.06.1-ce"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer dockerAPI.Close()
+
+	store := kv.NewStore(kv.NewMemoryBackend())
+	endpoints := []*chainid.Endpoint{
+		{Name: "up", Type: chainid.DockerEnvironment, URL: strings.Replace(dockerAPI.URL, "http://", "tcp://", 1)},
+		{Name: "down", Type: chainid.DockerEnvironment, URL: "tcp://127.0.0.1:1"},
+	}
+	for _, endpoint := range endpoints {
+		err := store.EndpointService.CreateEndpoint(endpoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	checker := NewEndpointStatusChecker(store.EndpointService, leaderClusterService{}, proxy.NewManager(&proxy.ManagerParams{}))
+	for i := 0; i < endpointStatusHistorySize+1; i++ {
+		checker.job.Run()
+	}
+
+	up, err := store.EndpointService.Endpoint(endpoints[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if up.Status != chainid.EndpointStatusUp || up.DockerVersion != "18.06.1-ce" {
+		t.Errorf("unexpected status for the reachable endpoint: %v, %q", up.Status, up.DockerVersion)
+	}
+	if len(up.StatusHistory) != endpointStatusHistorySize {
+		t.Errorf("expected the history to be limited to %d checks, got %d", endpointStatusHistorySize, len(up.StatusHistory))
+	}
+
+	down, err := store.EndpointService.Endpoint(endpoints[1].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if down.Status != chainid.EndpointStatusDown || down.StatusHistory[0].Error == "" {
+		t.Errorf("unexpected status for the unreachable endpoint: %+v", down.StatusHistory[0])
+	}
+}
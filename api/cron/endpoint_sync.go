@@ -1,209 +1,370 @@
-package cron
-
-import (
-	"encoding/json"
-	"io/ioutil"
-	"log"
-	"os"
-	"strings"
-
-	"github.com/chainid-io/dashboard"
-)
-
-type (
-	endpointSyncJob struct {
-		logger           *log.Logger
-		endpointService  chainid.EndpointService
-		endpointFilePath string
-	}
-
-	synchronization struct {
-		endpointsToCreate []*chainid.Endpoint
-		endpointsToUpdate []*chainid.Endpoint
-		endpointsToDelete []*chainid.Endpoint
-	}
-
-	fileEndpoint struct {
-		Name          string `json:"Name"`
-		URL           string `json:"URL"`
-		TLS           bool   `json:"TLS,omitempty"`
-		TLSSkipVerify bool   `json:"TLSSkipVerify,omitempty"`
-		TLSCACert     string `json:"TLSCACert,omitempty"`
-		TLSCert       string `json:"TLSCert,omitempty"`
-		TLSKey        string `json:"TLSKey,omitempty"`
-	}
-)
-
-const (
-	// ErrEmptyEndpointArray is an error raised when the external endpoint source array is empty.
-	ErrEmptyEndpointArray = chainid.Error("External endpoint source is empty")
-)
-
-func newEndpointSyncJob(endpointFilePath string, endpointService chainid.EndpointService) endpointSyncJob {
-	return endpointSyncJob{
-		logger:           log.New(os.Stderr, "", log.LstdFlags),
-		endpointService:  endpointService,
-		endpointFilePath: endpointFilePath,
-	}
-}
-
-func endpointSyncError(err error, logger *log.Logger) bool {
-	if err != nil {
-		logger.Printf("Endpoint synchronization error: %s", err)
-		return true
-	}
-	return false
-}
-
-func isValidEndpoint(endpoint *chainid.Endpoint) bool {
-	if endpoint.Name != "" && endpoint.URL != "" {
-		if !strings.HasPrefix(endpoint.URL, "unix://") && !strings.HasPrefix(endpoint.URL, "tcp://") {
-			return false
-		}
-		return true
-	}
-	return false
-}
-
-func convertFileEndpoints(fileEndpoints []fileEndpoint) []chainid.Endpoint {
-	convertedEndpoints := make([]chainid.Endpoint, 0)
-
-	for _, e := range fileEndpoints {
-		endpoint := chainid.Endpoint{
-			Name:      e.Name,
-			URL:       e.URL,
-			TLSConfig: chainid.TLSConfiguration{},
-		}
-		if e.TLS {
-			endpoint.TLSConfig.TLS = true
-			endpoint.TLSConfig.TLSSkipVerify = e.TLSSkipVerify
-			endpoint.TLSConfig.TLSCACertPath = e.TLSCACert
-			endpoint.TLSConfig.TLSCertPath = e.TLSCert
-			endpoint.TLSConfig.TLSKeyPath = e.TLSKey
-		}
-		convertedEndpoints = append(convertedEndpoints, endpoint)
-	}
-
-	return convertedEndpoints
-}
-
-func endpointExists(endpoint *chainid.Endpoint, endpoints []chainid.Endpoint) int {
-	for idx, v := range endpoints {
-		if endpoint.Name == v.Name && isValidEndpoint(&v) {
-			return idx
-		}
-	}
-	return -1
-}
-
-func mergeEndpointIfRequired(original, updated *chainid.Endpoint) *chainid.Endpoint {
-	var endpoint *chainid.Endpoint
-	if original.URL != updated.URL || original.TLSConfig.TLS != updated.TLSConfig.TLS ||
-		(updated.TLSConfig.TLS && original.TLSConfig.TLSSkipVerify != updated.TLSConfig.TLSSkipVerify) ||
-		(updated.TLSConfig.TLS && original.TLSConfig.TLSCACertPath != updated.TLSConfig.TLSCACertPath) ||
-		(updated.TLSConfig.TLS && original.TLSConfig.TLSCertPath != updated.TLSConfig.TLSCertPath) ||
-		(updated.TLSConfig.TLS && original.TLSConfig.TLSKeyPath != updated.TLSConfig.TLSKeyPath) {
-		endpoint = original
-		endpoint.URL = updated.URL
-		if updated.TLSConfig.TLS {
-			endpoint.TLSConfig.TLS = true
-			endpoint.TLSConfig.TLSSkipVerify = updated.TLSConfig.TLSSkipVerify
-			endpoint.TLSConfig.TLSCACertPath = updated.TLSConfig.TLSCACertPath
-			endpoint.TLSConfig.TLSCertPath = updated.TLSConfig.TLSCertPath
-			endpoint.TLSConfig.TLSKeyPath = updated.TLSConfig.TLSKeyPath
-		} else {
-			endpoint.TLSConfig.TLS = false
-			endpoint.TLSConfig.TLSSkipVerify = false
-			endpoint.TLSConfig.TLSCACertPath = ""
-			endpoint.TLSConfig.TLSCertPath = ""
-			endpoint.TLSConfig.TLSKeyPath = ""
-		}
-	}
-	return endpoint
-}
-
-func (sync synchronization) requireSync() bool {
-	if len(sync.endpointsToCreate) != 0 || len(sync.endpointsToUpdate) != 0 || len(sync.endpointsToDelete) != 0 {
-		return true
-	}
-	return false
-}
-
-// TMP: endpointSyncJob method to access logger, should be generic
-func (job endpointSyncJob) prepareSyncData(storedEndpoints, fileEndpoints []chainid.Endpoint) *synchronization {
-	endpointsToCreate := make([]*chainid.Endpoint, 0)
-	endpointsToUpdate := make([]*chainid.Endpoint, 0)
-	endpointsToDelete := make([]*chainid.Endpoint, 0)
-
-	for idx := range storedEndpoints {
-		fidx := endpointExists(&storedEndpoints[idx], fileEndpoints)
-		if fidx != -1 {
-			endpoint := mergeEndpointIfRequired(&storedEndpoints[idx], &fileEndpoints[fidx])
-			if endpoint != nil {
-				job.logger.Printf("New definition for a stored endpoint found in file, updating database. [name: %v] [url: %v]\n", endpoint.Name, endpoint.URL)
-				endpointsToUpdate = append(endpointsToUpdate, endpoint)
-			}
-		} else {
-			job.logger.Printf("Stored endpoint not found in file (definition might be invalid), removing from database. [name: %v] [url: %v]", storedEndpoints[idx].Name, storedEndpoints[idx].URL)
-			endpointsToDelete = append(endpointsToDelete, &storedEndpoints[idx])
-		}
-	}
-
-	for idx, endpoint := range fileEndpoints {
-		if !isValidEndpoint(&endpoint) {
-			job.logger.Printf("Invalid file endpoint definition, skipping. [name: %v] [url: %v]", endpoint.Name, endpoint.URL)
-			continue
-		}
-		sidx := endpointExists(&fileEndpoints[idx], storedEndpoints)
-		if sidx == -1 {
-			job.logger.Printf("File endpoint not found in database, adding to database. [name: %v] [url: %v]", fileEndpoints[idx].Name, fileEndpoints[idx].URL)
-			endpointsToCreate = append(endpointsToCreate, &fileEndpoints[idx])
-		}
-	}
-
-	return &synchronization{
-		endpointsToCreate: endpointsToCreate,
-		endpointsToUpdate: endpointsToUpdate,
-		endpointsToDelete: endpointsToDelete,
-	}
-}
-
-func (job endpointSyncJob) Sync() error {
-	data, err := ioutil.ReadFile(job.endpointFilePath)
-	if endpointSyncError(err, job.logger) {
-		return err
-	}
-
-	var fileEndpoints []fileEndpoint
-	err = json.Unmarshal(data, &fileEndpoints)
-	if endpointSyncError(err, job.logger) {
-		return err
-	}
-
-	if len(fileEndpoints) == 0 {
-		return ErrEmptyEndpointArray
-	}
-
-	storedEndpoints, err := job.endpointService.Endpoints()
-	if endpointSyncError(err, job.logger) {
-		return err
-	}
-
-	convertedFileEndpoints := convertFileEndpoints(fileEndpoints)
-
-	sync := job.prepareSyncData(storedEndpoints, convertedFileEndpoints)
-	if sync.requireSync() {
-		err = job.endpointService.Synchronize(sync.endpointsToCreate, sync.endpointsToUpdate, sync.endpointsToDelete)
-		if endpointSyncError(err, job.logger) {
-			return err
-		}
-		job.logger.Printf("Endpoint synchronization ended. [created: %v] [updated: %v] [deleted: %v]", len(sync.endpointsToCreate), len(sync.endpointsToUpdate), len(sync.endpointsToDelete))
-	}
-	return nil
-}
-
-func (job endpointSyncJob) Run() {
-	job.logger.Println("Endpoint synchronization job started.")
-	err := job.Sync()
-	endpointSyncError(err, job.logger)
-}
+package cron
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/ghodss/yaml"
+)
+
+type (
+	endpointSyncJob struct {
+		logger          *log.Logger
+		endpointService chainid.EndpointService
+		clusterService  chainid.ClusterService
+		client          *http.Client
+		// source is the path or the HTTP(S) URL of the file defining the endpoints.
+		source string
+		// merge is set when the endpoints managed via the API must be kept.
+		merge bool
+		// etag is the entity tag of the last document synchronized from an HTTP(S) source.
+		etag string
+	}
+
+	synchronization struct {
+		endpointsToCreate []*chainid.Endpoint
+		endpointsToUpdate []*chainid.Endpoint
+		endpointsToDelete []*chainid.Endpoint
+	}
+
+	// fileEndpoint represents an endpoint defined in the external endpoints source.
+	// AuthorizedTeams is left untouched on the stored endpoint when it is not defined.
+	fileEndpoint struct {
+		Name            string                  `json:"Name"`
+		URL             string                  `json:"URL"`
+		Type            chainid.EndpointType    `json:"Type,omitempty"`
+		Agent           bool                    `json:"Agent,omitempty"`
+		GroupID         chainid.EndpointGroupID `json:"GroupId,omitempty"`
+		PublicURL       string                  `json:"PublicURL,omitempty"`
+		AuthorizedTeams []chainid.TeamID        `json:"AuthorizedTeams,omitempty"`
+		TLS             bool                    `json:"TLS,omitempty"`
+		TLSSkipVerify   bool                    `json:"TLSSkipVerify,omitempty"`
+		TLSCACert       string                  `json:"TLSCACert,omitempty"`
+		TLSCert         string                  `json:"TLSCert,omitempty"`
+		TLSKey          string                  `json:"TLSKey,omitempty"`
+		SSHKey          string                  `json:"SSHKey,omitempty"`
+		SSHKnownHosts   string                  `json:"SSHKnownHosts,omitempty"`
+		SSHSkipVerify   bool                    `json:"SSHSkipVerify,omitempty"`
+	}
+)
+
+const (
+	// ErrEmptyEndpointArray is an error raised when the external endpoint source array is empty.
+	ErrEmptyEndpointArray = chainid.Error("External endpoint source is empty")
+)
+
+const (
+	defaultEndpointGroupID = chainid.EndpointGroupID(1)
+	sourceRequestTimeout   = 10 * time.Second
+)
+
+func newEndpointSyncJob(source string, merge bool, endpointService chainid.EndpointService, clusterService chainid.ClusterService) *endpointSyncJob {
+	return &endpointSyncJob{
+		logger:          log.New(os.Stderr, "", log.LstdFlags),
+		endpointService: endpointService,
+		clusterService:  clusterService,
+		client:          &http.Client{Timeout: sourceRequestTimeout},
+		source:          source,
+		merge:           merge,
+	}
+}
+
+func endpointSyncError(err error, logger *log.Logger) bool {
+	if err != nil {
+		logger.Printf("Endpoint synchronization error: %s", err)
+		return true
+	}
+	return false
+}
+
+func isValidEndpoint(endpoint *chainid.Endpoint) bool {
+	if endpoint.Type != chainid.DockerEnvironment && endpoint.Type != chainid.AgentOnDockerEnvironment {
+		return false
+	}
+	if endpoint.Name != "" && endpoint.URL != "" {
+		if endpoint.Type == chainid.AgentOnDockerEnvironment {
+			return strings.HasPrefix(endpoint.URL, "tcp://")
+		}
+		if strings.HasPrefix(endpoint.URL, "ssh://") {
+			return endpoint.SSHConfig.SSHKeyPath != ""
+		}
+		if !strings.HasPrefix(endpoint.URL, "unix://") && !strings.HasPrefix(endpoint.URL, "tcp://") {
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+func convertFileEndpoints(fileEndpoints []fileEndpoint) []chainid.Endpoint {
+	convertedEndpoints := make([]chainid.Endpoint, 0)
+
+	for _, e := range fileEndpoints {
+		endpoint := chainid.Endpoint{
+			Name:              e.Name,
+			Type:              e.Type,
+			URL:               e.URL,
+			GroupID:           e.GroupID,
+			PublicURL:         e.PublicURL,
+			TLSConfig:         chainid.TLSConfiguration{},
+			AuthorizedUsers:   []chainid.UserID{},
+			AuthorizedTeams:   e.AuthorizedTeams,
+			Extensions:        []chainid.EndpointExtension{},
+			ExternallyManaged: true,
+		}
+		if endpoint.Type == 0 {
+			endpoint.Type = chainid.DockerEnvironment
+		}
+		if endpoint.GroupID == 0 {
+			endpoint.GroupID = defaultEndpointGroupID
+		}
+		if e.Agent {
+			endpoint.Type = chainid.AgentOnDockerEnvironment
+		}
+		// The agent is always reached over TLS, its certificate is not verified unless TLS is configured.
+		if endpoint.Type == chainid.AgentOnDockerEnvironment {
+			endpoint.TLSConfig.TLS = true
+			endpoint.TLSConfig.TLSSkipVerify = true
+		}
+		if e.TLS {
+			endpoint.TLSConfig.TLS = true
+			endpoint.TLSConfig.TLSSkipVerify = e.TLSSkipVerify
+			endpoint.TLSConfig.TLSCACertPath = e.TLSCACert
+			endpoint.TLSConfig.TLSCertPath = e.TLSCert
+			endpoint.TLSConfig.TLSKeyPath = e.TLSKey
+		}
+		if strings.HasPrefix(e.URL, "ssh://") {
+			endpoint.SSHConfig = chainid.SSHConfiguration{
+				SSHSkipVerify:     e.SSHSkipVerify,
+				SSHKeyPath:        e.SSHKey,
+				SSHKnownHostsPath: e.SSHKnownHosts,
+			}
+		}
+		convertedEndpoints = append(convertedEndpoints, endpoint)
+	}
+
+	return convertedEndpoints
+}
+
+func endpointExists(endpoint *chainid.Endpoint, endpoints []chainid.Endpoint) int {
+	for idx, v := range endpoints {
+		if endpoint.Name == v.Name && isValidEndpoint(&v) {
+			return idx
+		}
+	}
+	return -1
+}
+
+func sameTeams(original, updated []chainid.TeamID) bool {
+	if len(original) != len(updated) {
+		return false
+	}
+	for idx := range original {
+		if original[idx] != updated[idx] {
+			return false
+		}
+	}
+	return true
+}
+
+// reservedEndpointExists returns true when an endpoint with the same name is managed by a discovery
+// provider or, in merge mode, via the API.
+func reservedEndpointExists(endpoint *chainid.Endpoint, endpoints []chainid.Endpoint, merge bool) bool {
+	for _, v := range endpoints {
+		if endpoint.Name == v.Name && (v.DiscoverySource != "" || (merge && !v.ExternallyManaged)) {
+			return true
+		}
+	}
+	return false
+}
+
+func mergeEndpointIfRequired(original, updated *chainid.Endpoint) *chainid.Endpoint {
+	var endpoint *chainid.Endpoint
+	if original.URL != updated.URL || original.TLSConfig.TLS != updated.TLSConfig.TLS ||
+		original.Type != updated.Type || original.GroupID != updated.GroupID ||
+		original.PublicURL != updated.PublicURL || !original.ExternallyManaged ||
+		(updated.AuthorizedTeams != nil && !sameTeams(original.AuthorizedTeams, updated.AuthorizedTeams)) ||
+		(updated.TLSConfig.TLS && original.TLSConfig.TLSSkipVerify != updated.TLSConfig.TLSSkipVerify) ||
+		(updated.TLSConfig.TLS && original.TLSConfig.TLSCACertPath != updated.TLSConfig.TLSCACertPath) ||
+		(updated.TLSConfig.TLS && original.TLSConfig.TLSCertPath != updated.TLSConfig.TLSCertPath) ||
+		(updated.TLSConfig.TLS && original.TLSConfig.TLSKeyPath != updated.TLSConfig.TLSKeyPath) ||
+		original.SSHConfig != updated.SSHConfig {
+		endpoint = original
+		endpoint.Type = updated.Type
+		endpoint.URL = updated.URL
+		endpoint.GroupID = updated.GroupID
+		endpoint.PublicURL = updated.PublicURL
+		endpoint.SSHConfig = updated.SSHConfig
+		endpoint.ExternallyManaged = true
+		if updated.AuthorizedTeams != nil {
+			endpoint.AuthorizedTeams = updated.AuthorizedTeams
+		}
+		if updated.TLSConfig.TLS {
+			endpoint.TLSConfig.TLS = true
+			endpoint.TLSConfig.TLSSkipVerify = updated.TLSConfig.TLSSkipVerify
+			endpoint.TLSConfig.TLSCACertPath = updated.TLSConfig.TLSCACertPath
+			endpoint.TLSConfig.TLSCertPath = updated.TLSConfig.TLSCertPath
+			endpoint.TLSConfig.TLSKeyPath = updated.TLSConfig.TLSKeyPath
+		} else {
+			endpoint.TLSConfig.TLS = false
+			endpoint.TLSConfig.TLSSkipVerify = false
+			endpoint.TLSConfig.TLSCACertPath = ""
+			endpoint.TLSConfig.TLSCertPath = ""
+			endpoint.TLSConfig.TLSKeyPath = ""
+		}
+	}
+	return endpoint
+}
+
+func (sync synchronization) requireSync() bool {
+	if len(sync.endpointsToCreate) != 0 || len(sync.endpointsToUpdate) != 0 || len(sync.endpointsToDelete) != 0 {
+		return true
+	}
+	return false
+}
+
+// TMP: endpointSyncJob method to access logger, should be generic
+func (job *endpointSyncJob) prepareSyncData(storedEndpoints, fileEndpoints []chainid.Endpoint) *synchronization {
+	endpointsToCreate := make([]*chainid.Endpoint, 0)
+	endpointsToUpdate := make([]*chainid.Endpoint, 0)
+	endpointsToDelete := make([]*chainid.Endpoint, 0)
+
+	for idx := range storedEndpoints {
+		// The endpoints managed by a discovery provider are left untouched, as well as the
+		// endpoints managed via the API in merge mode.
+		if storedEndpoints[idx].DiscoverySource != "" || (job.merge && !storedEndpoints[idx].ExternallyManaged) {
+			continue
+		}
+
+		fidx := endpointExists(&storedEndpoints[idx], fileEndpoints)
+		if fidx != -1 {
+			endpoint := mergeEndpointIfRequired(&storedEndpoints[idx], &fileEndpoints[fidx])
+			if endpoint != nil {
+				job.logger.Printf("New definition for a stored endpoint found in file, updating database. [name: %v] [url: %v]\n", endpoint.Name, endpoint.URL)
+				endpointsToUpdate = append(endpointsToUpdate, endpoint)
+			}
+		} else {
+			job.logger.Printf("Stored endpoint not found in file (definition might be invalid), removing from database. [name: %v] [url: %v]", storedEndpoints[idx].Name, storedEndpoints[idx].URL)
+			endpointsToDelete = append(endpointsToDelete, &storedEndpoints[idx])
+		}
+	}
+
+	for idx, endpoint := range fileEndpoints {
+		if !isValidEndpoint(&endpoint) {
+			job.logger.Printf("Invalid file endpoint definition, skipping. [name: %v] [url: %v]", endpoint.Name, endpoint.URL)
+			continue
+		}
+		if reservedEndpointExists(&fileEndpoints[idx], storedEndpoints, job.merge) {
+			job.logger.Printf("File endpoint has the same name as an endpoint managed by another source, skipping. [name: %v] [url: %v]", endpoint.Name, endpoint.URL)
+			continue
+		}
+		sidx := endpointExists(&fileEndpoints[idx], storedEndpoints)
+		if sidx == -1 {
+			job.logger.Printf("File endpoint not found in database, adding to database. [name: %v] [url: %v]", fileEndpoints[idx].Name, fileEndpoints[idx].URL)
+			if fileEndpoints[idx].AuthorizedTeams == nil {
+				fileEndpoints[idx].AuthorizedTeams = []chainid.TeamID{}
+			}
+			endpointsToCreate = append(endpointsToCreate, &fileEndpoints[idx])
+		}
+	}
+
+	return &synchronization{
+		endpointsToCreate: endpointsToCreate,
+		endpointsToUpdate: endpointsToUpdate,
+		endpointsToDelete: endpointsToDelete,
+	}
+}
+
+func isHTTPSource(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// readSource returns the content of the external endpoints source along with its entity tag.
+// A nil content is returned when the HTTP(S) source has not changed since the last synchronization.
+func (job *endpointSyncJob) readSource() ([]byte, string, error) {
+	if !isHTTPSource(job.source) {
+		data, err := ioutil.ReadFile(job.source)
+		return data, "", err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, job.source, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if job.etag != "" {
+		req.Header.Set("If-None-Match", job.etag)
+	}
+
+	resp, err := job.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return nil, job.etag, nil
+	case http.StatusOK:
+		data, err := ioutil.ReadAll(resp.Body)
+		return data, resp.Header.Get("ETag"), err
+	default:
+		return nil, "", fmt.Errorf("unexpected status code %d when retrieving %s", resp.StatusCode, job.source)
+	}
+}
+
+func (job *endpointSyncJob) Sync() error {
+	data, etag, err := job.readSource()
+	if endpointSyncError(err, job.logger) {
+		return err
+	}
+	if data == nil {
+		return nil
+	}
+
+	var fileEndpoints []fileEndpoint
+	err = yaml.Unmarshal(data, &fileEndpoints)
+	if endpointSyncError(err, job.logger) {
+		return err
+	}
+
+	if len(fileEndpoints) == 0 {
+		return ErrEmptyEndpointArray
+	}
+
+	storedEndpoints, err := job.endpointService.Endpoints()
+	if endpointSyncError(err, job.logger) {
+		return err
+	}
+
+	convertedFileEndpoints := convertFileEndpoints(fileEndpoints)
+
+	sync := job.prepareSyncData(storedEndpoints, convertedFileEndpoints)
+	if sync.requireSync() {
+		err = job.endpointService.Synchronize(sync.endpointsToCreate, sync.endpointsToUpdate, sync.endpointsToDelete)
+		if endpointSyncError(err, job.logger) {
+			return err
+		}
+		job.logger.Printf("Endpoint synchronization ended. [created: %v] [updated: %v] [deleted: %v]", len(sync.endpointsToCreate), len(sync.endpointsToUpdate), len(sync.endpointsToDelete))
+	}
+
+	// The entity tag is only recorded once the document has been synchronized, so that
+	// a failed synchronization is retried even when the source does not change.
+	job.etag = etag
+	return nil
+}
+
+func (job *endpointSyncJob) Run() {
+	if !job.clusterService.IsLeader() {
+		return
+	}
+
+	job.logger.Println("Endpoint synchronization job started.")
+	err := job.Sync()
+	endpointSyncError(err, job.logger)
+}
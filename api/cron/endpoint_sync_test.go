@@ -0,0 +1,196 @@
+package cron
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/kv"
+)
+
+func TestEndpointSyncYAMLFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "endpoint-sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	source := filepath.Join(dir, "endpoints.yml")
+	err = ioutil.WriteFile(source, []byte(`
+- Name: local
+  URL: unix:///var/run/docker.sock
+- Name: agent
+  URL: tcp://10.0.0.1:9001
+  Agent: true
+  GroupId: 2
+  PublicURL: 10.0.0.1
+  AuthorizedTeams: [1, 3]
+- Name: invalid
+  URL: http://10.0.0.2
+`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := kv.NewStore(kv.NewMemoryBackend())
+	job := newEndpointSyncJob(source, false, store.EndpointService, leaderClusterService{})
+	err = job.Sync()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	endpoints, err := store.EndpointService.Endpoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(endpoints) != 2 {
+		t.Fatalf("expected the 2 valid endpoints to be created, got %+v", endpoints)
+	}
+
+	byName := make(map[string]chainid.Endpoint)
+	for _, endpoint := range endpoints {
+		if !endpoint.ExternallyManaged {
+			t.Errorf("expected endpoint %q to be externally managed", endpoint.Name)
+		}
+		byName[endpoint.Name] = endpoint
+	}
+
+	local := byName["local"]
+	if local.Type != chainid.DockerEnvironment || local.GroupID != defaultEndpointGroupID || local.TLSConfig.TLS || local.AuthorizedTeams == nil {
+		t.Errorf("unexpected local endpoint %+v", local)
+	}
+
+	agent := byName["agent"]
+	if agent.Type != chainid.AgentOnDockerEnvironment || agent.GroupID != 2 || agent.PublicURL != "10.0.0.1" || !agent.TLSConfig.TLS || len(agent.AuthorizedTeams) != 2 {
+		t.Errorf("unexpected agent endpoint %+v", agent)
+	}
+
+	// The teams of an endpoint are only managed by the source when they are defined.
+	local.AuthorizedTeams = []chainid.TeamID{4}
+	err = store.EndpointService.UpdateEndpoint(local.ID, &local)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = job.Sync()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := store.EndpointService.Endpoint(local.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updated.AuthorizedTeams) != 1 || updated.AuthorizedTeams[0] != 4 {
+		t.Errorf("expected the teams of the local endpoint to be kept, got %v", updated.AuthorizedTeams)
+	}
+}
+
+func TestEndpointSyncHTTPSource(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`[{"Name":"remote","URL":"tcp://10.0.0.1:2375"}]`))
+	}))
+	defer server.Close()
+
+	store := kv.NewStore(kv.NewMemoryBackend())
+	job := newEndpointSyncJob(server.URL, false, store.EndpointService, leaderClusterService{})
+	err := job.Sync()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.etag != `"v1"` {
+		t.Errorf("expected the entity tag of the source to be recorded, got %q", job.etag)
+	}
+
+	endpoints, err := store.EndpointService.Endpoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(endpoints) != 1 || endpoints[0].Name != "remote" {
+		t.Fatalf("expected the remote endpoint to be created, got %+v", endpoints)
+	}
+
+	// An unchanged source is not synchronized again.
+	err = store.EndpointService.DeleteEndpoint(endpoints[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = job.Sync()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+
+	endpoints, err = store.EndpointService.Endpoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(endpoints) != 0 {
+		t.Errorf("expected the synchronization to be skipped, got %+v", endpoints)
+	}
+}
+
+func TestEndpointSyncMergeMode(t *testing.T) {
+	dir, err := ioutil.TempDir("", "endpoint-sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	source := filepath.Join(dir, "endpoints.json")
+	err = ioutil.WriteFile(source, []byte(`[{"Name":"file","URL":"tcp://10.0.0.1:2375"},{"Name":"api","URL":"tcp://10.0.0.2:2375"}]`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := kv.NewStore(kv.NewMemoryBackend())
+	for _, endpoint := range []*chainid.Endpoint{
+		{Name: "api", Type: chainid.DockerEnvironment, URL: "tcp://10.0.0.3:2375"},
+		{Name: "other", Type: chainid.DockerEnvironment, URL: "tcp://10.0.0.4:2375"},
+		{Name: "removed", Type: chainid.DockerEnvironment, URL: "tcp://10.0.0.5:2375", ExternallyManaged: true},
+	} {
+		err = store.EndpointService.CreateEndpoint(endpoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	job := newEndpointSyncJob(source, true, store.EndpointService, leaderClusterService{})
+	err = job.Sync()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	endpoints, err := store.EndpointService.Endpoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byName := make(map[string]chainid.Endpoint)
+	for _, endpoint := range endpoints {
+		byName[endpoint.Name] = endpoint
+	}
+	if len(byName) != 3 || !byName["file"].ExternallyManaged {
+		t.Fatalf("expected the file endpoint to be added to the endpoints managed via the API, got %+v", endpoints)
+	}
+	if api := byName["api"]; api.ExternallyManaged || api.URL != "tcp://10.0.0.3:2375" {
+		t.Errorf("expected the endpoint managed via the API to be kept, got %+v", api)
+	}
+	if _, ok := byName["removed"]; ok {
+		t.Errorf("expected the externally managed endpoint missing from the source to be removed")
+	}
+}
@@ -0,0 +1,85 @@
+package cron
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/http/proxy"
+)
+
+type (
+	// endpointTransports keeps the transports used to reach the Docker API of the endpoints
+	// between the runs of a job.
+	endpointTransports struct {
+		proxyManager *proxy.Manager
+		transports   map[chainid.EndpointID]*endpointTransport
+	}
+
+	// endpointTransport is the transport used to reach the Docker API of an endpoint, it is
+	// created again when the connection settings of the endpoint change.
+	endpointTransport struct {
+		settings  proxy.ConnectionSettings
+		transport http.RoundTripper
+		target    string
+	}
+)
+
+func newEndpointTransports(proxyManager *proxy.Manager) *endpointTransports {
+	return &endpointTransports{
+		proxyManager: proxyManager,
+		transports:   make(map[chainid.EndpointID]*endpointTransport),
+	}
+}
+
+// update returns the transports of the Docker endpoints, the transports of the endpoints
+// that no longer exist are discarded. Azure and Kubernetes endpoints are ignored.
+func (cache *endpointTransports) update(endpoints []chainid.Endpoint, logger *log.Logger) map[chainid.EndpointID]*endpointTransport {
+	transports := make(map[chainid.EndpointID]*endpointTransport)
+	for i := range endpoints {
+		endpoint := &endpoints[i]
+		if endpoint.Type == chainid.AzureEnvironment || endpoint.Type == chainid.KubernetesEnvironment {
+			continue
+		}
+
+		transport, err := cache.transport(endpoint)
+		if err != nil {
+			logger.Printf("Unable to create the transport of endpoint %d: %s", endpoint.ID, err)
+			continue
+		}
+		transports[endpoint.ID] = transport
+	}
+
+	for endpointID, transport := range cache.transports {
+		if transports[endpointID] != transport {
+			transport.close()
+		}
+	}
+	cache.transports = transports
+	return transports
+}
+
+func (cache *endpointTransports) transport(endpoint *chainid.Endpoint) (*endpointTransport, error) {
+	transport := cache.transports[endpoint.ID]
+	if transport != nil && transport.settings.Matches(endpoint) {
+		return transport, nil
+	}
+
+	roundTripper, target, err := cache.proxyManager.CreateDockerTransport(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return &endpointTransport{
+		settings:  proxy.NewConnectionSettings(endpoint),
+		transport: roundTripper,
+		target:    target,
+	}, nil
+}
+
+// close releases the idle connections of a transport that is replaced or discarded.
+func (transport *endpointTransport) close() {
+	if closer, ok := transport.transport.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
@@ -0,0 +1,60 @@
+package cron
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"testing"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/http/proxy"
+)
+
+// closeRecorder is a transport recording whether its idle connections were closed.
+type closeRecorder struct {
+	http.RoundTripper
+	closed bool
+}
+
+func (transport *closeRecorder) CloseIdleConnections() {
+	transport.closed = true
+}
+
+func TestEndpointTransportsUpdate(t *testing.T) {
+	logger := log.New(ioutil.Discard, "", 0)
+	cache := newEndpointTransports(proxy.NewManager(&proxy.ManagerParams{}))
+	endpoints := []chainid.Endpoint{
+		{ID: 1, URL: "tcp://127.0.0.1:2375"},
+		{ID: 2, URL: "tcp://127.0.0.1:2376"},
+	}
+
+	transports := cache.update(endpoints, logger)
+	if len(transports) != 2 {
+		t.Fatalf("expected 2 transports, got %d", len(transports))
+	}
+	first, second := transports[1], transports[2]
+	firstRecorder := &closeRecorder{RoundTripper: first.transport}
+	first.transport = firstRecorder
+	secondRecorder := &closeRecorder{RoundTripper: second.transport}
+	second.transport = secondRecorder
+
+	// The status checker updates the endpoints periodically, their transports must be kept.
+	endpoints[0].Status = chainid.EndpointStatusDown
+	endpoints[0].Snapshots = []chainid.Snapshot{{Time: 1}}
+	transports = cache.update(endpoints, logger)
+	if transports[1] != first || transports[2] != second {
+		t.Fatalf("expected the transports to be kept after a status update")
+	}
+
+	endpoints[0].TransportSettings.DialTimeout = 5
+	transports = cache.update(endpoints[:1], logger)
+	if transports[1] == nil || transports[1] == first {
+		t.Errorf("expected the transport to be created again after the transport settings changed")
+	}
+	if !firstRecorder.closed {
+		t.Errorf("expected the replaced transport to be closed")
+	}
+	if _, ok := transports[2]; ok || !secondRecorder.closed {
+		t.Errorf("expected the transport of the missing endpoint to be discarded and closed")
+	}
+}
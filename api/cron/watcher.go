@@ -1,40 +1,65 @@
-package cron
-
-import (
-	"github.com/chainid-io/dashboard"
-	"github.com/robfig/cron"
-)
-
-// Watcher represents a service for managing crons.
-type Watcher struct {
-	Cron            *cron.Cron
-	EndpointService chainid.EndpointService
-	syncInterval    string
-}
-
-// NewWatcher initializes a new service.
-func NewWatcher(endpointService chainid.EndpointService, syncInterval string) *Watcher {
-	return &Watcher{
-		Cron:            cron.New(),
-		EndpointService: endpointService,
-		syncInterval:    syncInterval,
-	}
-}
-
-// WatchEndpointFile starts a cron job to synchronize the endpoints from a file
-func (watcher *Watcher) WatchEndpointFile(endpointFilePath string) error {
-	job := newEndpointSyncJob(endpointFilePath, watcher.EndpointService)
-
-	err := job.Sync()
-	if err != nil {
-		return err
-	}
-
-	err = watcher.Cron.AddJob("@every "+watcher.syncInterval, job)
-	if err != nil {
-		return err
-	}
-
-	watcher.Cron.Start()
-	return nil
-}
+package cron
+
+import (
+	"github.com/chainid-io/dashboard"
+	"github.com/robfig/cron"
+)
+
+// Watcher represents a service for managing crons.
+type Watcher struct {
+	Cron                 *cron.Cron
+	EndpointService      chainid.EndpointService
+	EndpointGroupService chainid.EndpointGroupService
+	ClusterService       chainid.ClusterService
+	syncInterval         string
+}
+
+// NewWatcher initializes a new service.
+func NewWatcher(endpointService chainid.EndpointService, endpointGroupService chainid.EndpointGroupService, clusterService chainid.ClusterService, syncInterval string) *Watcher {
+	return &Watcher{
+		Cron:                 cron.New(),
+		EndpointService:      endpointService,
+		EndpointGroupService: endpointGroupService,
+		ClusterService:       clusterService,
+		syncInterval:         syncInterval,
+	}
+}
+
+// WatchEndpointFile starts a cron job to synchronize the endpoints from a file or an HTTP(S) URL.
+// When merge is set, the endpoints managed via the API are kept along with the synchronized endpoints.
+// The synchronization is only run by the leader of the cluster.
+func (watcher *Watcher) WatchEndpointFile(source string, merge bool) error {
+	job := newEndpointSyncJob(source, merge, watcher.EndpointService, watcher.ClusterService)
+
+	if watcher.ClusterService.IsLeader() {
+		err := job.Sync()
+		if err != nil {
+			return err
+		}
+	}
+
+	err := watcher.Cron.AddJob("@every "+watcher.syncInterval, job)
+	if err != nil {
+		return err
+	}
+
+	watcher.Cron.Start()
+	return nil
+}
+
+// WatchEndpointDiscovery starts a cron job to synchronize the endpoints with the Docker hosts found by
+// a discovery provider. Unlike the file synchronization, a failure of the first synchronization is only
+// logged since the discovery system might not be available yet.
+// The synchronization is only run by the leader of the cluster.
+func (watcher *Watcher) WatchEndpointDiscovery(provider chainid.EndpointDiscoveryProvider) error {
+	job := newEndpointDiscoveryJob(provider, watcher.EndpointService, watcher.EndpointGroupService, watcher.ClusterService)
+	job.Run()
+
+	err := watcher.Cron.AddJob("@every "+watcher.syncInterval, job)
+	if err != nil {
+		return err
+	}
+
+	watcher.Cron.Start()
+	return nil
+}
@@ -0,0 +1,90 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+
+	"github.com/chainid-io/dashboard"
+)
+
+// encryptionKeySize is the size of the key used by AESService (AES-256).
+const encryptionKeySize = 32
+
+// AESService is a service used to encrypt sensitive data (e.g. TOTP secrets) before
+// storing it. It uses AES-256 in GCM mode and encodes the result using base64.
+type AESService struct {
+	aead cipher.AEAD
+}
+
+// GenerateKey generates a new random encryption key.
+func (service *AESService) GenerateKey() ([]byte, error) {
+	key := make([]byte, encryptionKeySize)
+	_, err := rand.Read(key)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// SetKey associates the specified key to the service.
+func (service *AESService) SetKey(key []byte) error {
+	if len(key) != encryptionKeySize {
+		return chainid.ErrInvalidEncryptionKey
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+
+	service.aead = aead
+	return nil
+}
+
+// Encrypt encrypts data and returns the base64 encoded result. A random nonce is
+// generated for each call and prepended to the ciphertext.
+func (service *AESService) Encrypt(data string) (string, error) {
+	if service.aead == nil {
+		return "", chainid.ErrInvalidEncryptionKey
+	}
+
+	nonce := make([]byte, service.aead.NonceSize())
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return "", err
+	}
+
+	ciphertext := service.aead.Seal(nonce, nonce, []byte(data), nil)
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// Decrypt decrypts data previously encrypted with Encrypt.
+func (service *AESService) Decrypt(data string) (string, error) {
+	if service.aead == nil {
+		return "", chainid.ErrInvalidEncryptionKey
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return "", chainid.ErrDecryptionFailure
+	}
+
+	nonceSize := service.aead.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return "", chainid.ErrDecryptionFailure
+	}
+
+	plaintext, err := service.aead.Open(nil, ciphertext[:nonceSize], ciphertext[nonceSize:], nil)
+	if err != nil {
+		return "", chainid.ErrDecryptionFailure
+	}
+
+	return string(plaintext), nil
+}
@@ -0,0 +1,80 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+
+	"github.com/chainid-io/dashboard"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	passphraseSaltSize = 16
+	// scrypt parameters recommended for interactive logins.
+	scryptN = 32768
+	scryptR = 8
+	scryptP = 1
+)
+
+// EncryptWithPassphrase encrypts data with AES-256-GCM using a key derived from the passphrase
+// with scrypt. The random salt and nonce are prepended to the ciphertext.
+func EncryptWithPassphrase(data []byte, passphrase string) ([]byte, error) {
+	salt := make([]byte, passphraseSaltSize)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newPassphraseAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	output := append(salt, nonce...)
+	return aead.Seal(output, nonce, data, nil), nil
+}
+
+// DecryptWithPassphrase decrypts data previously encrypted with EncryptWithPassphrase.
+func DecryptWithPassphrase(data []byte, passphrase string) ([]byte, error) {
+	if len(data) < passphraseSaltSize {
+		return nil, chainid.ErrDecryptionFailure
+	}
+
+	aead, err := newPassphraseAEAD(passphrase, data[:passphraseSaltSize])
+	if err != nil {
+		return nil, err
+	}
+
+	data = data[passphraseSaltSize:]
+	nonceSize := aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, chainid.ErrDecryptionFailure
+	}
+
+	plaintext, err := aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return nil, chainid.ErrDecryptionFailure
+	}
+	return plaintext, nil
+}
+
+func newPassphraseAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, encryptionKeySize)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,202 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+
+	"github.com/chainid-io/dashboard"
+)
+
+const (
+	// passphraseChunkSize is the size of the plaintext encrypted in each frame of a stream.
+	passphraseChunkSize = 64 * 1024
+	// passphraseNoncePrefixSize is the size of the random part of the nonces of a stream,
+	// the nonce of a frame is composed of the prefix, the index of the frame and a flag set
+	// on the last frame so that frames cannot be reordered, removed or truncated.
+	passphraseNoncePrefixSize = 7
+)
+
+type (
+	passphraseWriter struct {
+		w       io.Writer
+		aead    cipher.AEAD
+		prefix  []byte
+		counter uint32
+		buffer  []byte
+		closed  bool
+	}
+
+	passphraseReader struct {
+		r         io.Reader
+		aead      cipher.AEAD
+		prefix    []byte
+		counter   uint32
+		plaintext []byte
+		done      bool
+	}
+)
+
+// NewPassphraseWriter returns a writer encrypting the data written to w with AES-256-GCM using
+// a key derived from the passphrase with scrypt. The data is encrypted in frames of 64KB so that
+// it does not have to be kept in memory. The random salt and nonce prefix are written first, each
+// frame is then written prefixed with its length. Close must be called to write the last frame,
+// it does not close w.
+func NewPassphraseWriter(w io.Writer, passphrase string) (io.WriteCloser, error) {
+	header := make([]byte, passphraseSaltSize+passphraseNoncePrefixSize)
+	_, err := rand.Read(header)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newPassphraseAEAD(passphrase, header[:passphraseSaltSize])
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = w.Write(header)
+	if err != nil {
+		return nil, err
+	}
+
+	return &passphraseWriter{
+		w:      w,
+		aead:   aead,
+		prefix: header[passphraseSaltSize:],
+		buffer: make([]byte, 0, passphraseChunkSize),
+	}, nil
+}
+
+// Write buffers the data and encrypts a frame each time a chunk is complete. A complete chunk
+// is only written once more data is received, as the last frame is flagged when closing.
+func (writer *passphraseWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		if len(writer.buffer) == passphraseChunkSize {
+			err := writer.writeFrame(false)
+			if err != nil {
+				return written, err
+			}
+		}
+
+		n := copy(writer.buffer[len(writer.buffer):passphraseChunkSize], p)
+		writer.buffer = writer.buffer[:len(writer.buffer)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close writes the last frame.
+func (writer *passphraseWriter) Close() error {
+	if writer.closed {
+		return nil
+	}
+	writer.closed = true
+	return writer.writeFrame(true)
+}
+
+func (writer *passphraseWriter) writeFrame(last bool) error {
+	nonce := frameNonce(writer.prefix, writer.counter, last)
+	writer.counter++
+	if writer.counter == 0 {
+		return chainid.Error("Too many frames in the encrypted stream")
+	}
+
+	frame := make([]byte, 4, 4+len(writer.buffer)+writer.aead.Overhead())
+	frame = writer.aead.Seal(frame, nonce, writer.buffer, nil)
+	binary.BigEndian.PutUint32(frame[:4], uint32(len(frame)-4))
+	writer.buffer = writer.buffer[:0]
+
+	_, err := writer.w.Write(frame)
+	return err
+}
+
+// NewPassphraseReader returns a reader decrypting the data written with NewPassphraseWriter.
+// The reader returns chainid.ErrDecryptionFailure if the passphrase is invalid or if the data
+// has been altered or truncated.
+func NewPassphraseReader(r io.Reader, passphrase string) (io.Reader, error) {
+	header := make([]byte, passphraseSaltSize+passphraseNoncePrefixSize)
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return nil, chainid.ErrDecryptionFailure
+	}
+
+	aead, err := newPassphraseAEAD(passphrase, header[:passphraseSaltSize])
+	if err != nil {
+		return nil, err
+	}
+
+	return &passphraseReader{
+		r:      r,
+		aead:   aead,
+		prefix: header[passphraseSaltSize:],
+	}, nil
+}
+
+func (reader *passphraseReader) Read(p []byte) (int, error) {
+	for len(reader.plaintext) == 0 {
+		if reader.done {
+			return 0, io.EOF
+		}
+
+		err := reader.readFrame()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, reader.plaintext)
+	reader.plaintext = reader.plaintext[n:]
+	return n, nil
+}
+
+// readFrame decrypts the next frame. The stream must end right after the last frame.
+func (reader *passphraseReader) readFrame() error {
+	var length [4]byte
+	_, err := io.ReadFull(reader.r, length[:])
+	if err != nil {
+		return chainid.ErrDecryptionFailure
+	}
+
+	size := binary.BigEndian.Uint32(length[:])
+	if size > passphraseChunkSize+uint32(reader.aead.Overhead()) {
+		return chainid.ErrDecryptionFailure
+	}
+
+	frame := make([]byte, size)
+	_, err = io.ReadFull(reader.r, frame)
+	if err != nil {
+		return chainid.ErrDecryptionFailure
+	}
+
+	plaintext, err := reader.aead.Open(nil, frameNonce(reader.prefix, reader.counter, false), frame, nil)
+	if err != nil {
+		plaintext, err = reader.aead.Open(nil, frameNonce(reader.prefix, reader.counter, true), frame, nil)
+		if err != nil {
+			return chainid.ErrDecryptionFailure
+		}
+
+		var trailing [1]byte
+		_, err = io.ReadFull(reader.r, trailing[:])
+		if err != io.EOF {
+			return chainid.ErrDecryptionFailure
+		}
+		reader.done = true
+	}
+
+	reader.counter++
+	reader.plaintext = plaintext
+	return nil
+}
+
+func frameNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, passphraseNoncePrefixSize+5)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[passphraseNoncePrefixSize:], counter)
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+	return nonce
+}
@@ -0,0 +1,97 @@
+package crypto
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/rand"
+	"testing"
+
+	"github.com/chainid-io/dashboard"
+)
+
+func encryptStream(t *testing.T, data []byte, passphrase string) []byte {
+	var encrypted bytes.Buffer
+	writer, err := NewPassphraseWriter(&encrypted, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The data is written in small writes to exercise the buffering of the frames.
+	for len(data) > 0 {
+		n := 1000
+		if n > len(data) {
+			n = len(data)
+		}
+		_, err = writer.Write(data[:n])
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = data[n:]
+	}
+
+	err = writer.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encrypted.Bytes()
+}
+
+func decryptStream(encrypted []byte, passphrase string) ([]byte, error) {
+	reader, err := NewPassphraseReader(bytes.NewReader(encrypted), passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(reader)
+}
+
+func TestPassphraseStream(t *testing.T) {
+	for _, size := range []int{0, 1, passphraseChunkSize, passphraseChunkSize + 1, 3 * passphraseChunkSize} {
+		data := make([]byte, size)
+		rand.Read(data)
+
+		encrypted := encryptStream(t, data, "secret")
+		decrypted, err := decryptStream(encrypted, "secret")
+		if err != nil {
+			t.Fatalf("unable to decrypt %d bytes: %s", size, err)
+		}
+		if !bytes.Equal(decrypted, data) {
+			t.Fatalf("unexpected decrypted data for %d bytes", size)
+		}
+	}
+}
+
+func TestPassphraseStreamAltered(t *testing.T) {
+	data := make([]byte, 2*passphraseChunkSize+10)
+	rand.Read(data)
+	encrypted := encryptStream(t, data, "secret")
+	frameSize := 4 + passphraseChunkSize + 16
+	header := passphraseSaltSize + passphraseNoncePrefixSize
+
+	tampered := append([]byte{}, encrypted...)
+	tampered[len(tampered)-1] ^= 1
+
+	reordered := append([]byte{}, encrypted[:header]...)
+	reordered = append(reordered, encrypted[header+frameSize:header+2*frameSize]...)
+	reordered = append(reordered, encrypted[header:header+frameSize]...)
+	reordered = append(reordered, encrypted[header+2*frameSize:]...)
+
+	testCases := []struct {
+		name       string
+		encrypted  []byte
+		passphrase string
+	}{
+		{"invalid passphrase", encrypted, "invalid"},
+		{"tampered frame", tampered, "secret"},
+		{"reordered frames", reordered, "secret"},
+		{"missing last frame", encrypted[:header+2*frameSize], "secret"},
+		{"truncated frame", encrypted[:len(encrypted)-1], "secret"},
+		{"trailing data", append(append([]byte{}, encrypted...), 0), "secret"},
+	}
+
+	for _, tc := range testCases {
+		_, err := decryptStream(tc.encrypted, tc.passphrase)
+		if err != chainid.ErrDecryptionFailure {
+			t.Errorf("%s: expected %v, got %v", tc.name, chainid.ErrDecryptionFailure, err)
+		}
+	}
+}
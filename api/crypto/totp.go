@@ -0,0 +1,129 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// totpPeriod is the validity period of a code, in seconds.
+	totpPeriod = 30
+	// totpDigits is the number of digits of a code.
+	totpDigits = 6
+	// totpSkew is the number of periods before and after the current one
+	// during which a code is still accepted, to account for clock drift.
+	totpSkew = 1
+	// totpSecretSize is the size of a generated secret, in bytes.
+	totpSecretSize = 20
+	// recoveryCodeSize is the size of a generated recovery code, in bytes.
+	recoveryCodeSize = 5
+)
+
+var base32NoPadding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TOTPService is a service used to generate and validate time-based one-time
+// passwords as described in RFC 6238 (HMAC-SHA1, 6 digits, 30 seconds period).
+// These parameters are the ones supported by most authenticator applications.
+type TOTPService struct {
+	Issuer string
+}
+
+// GenerateSecret generates a new random base32 encoded secret.
+func (service *TOTPService) GenerateSecret() (string, error) {
+	secret := make([]byte, totpSecretSize)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", err
+	}
+	return base32NoPadding.EncodeToString(secret), nil
+}
+
+// GenerateRecoveryCodes generates a list of random one-time recovery codes.
+func (service *TOTPService) GenerateRecoveryCodes(count int) ([]string, error) {
+	codes := make([]string, count)
+	for i := range codes {
+		code := make([]byte, recoveryCodeSize)
+		_, err := rand.Read(code)
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = fmt.Sprintf("%x-%x", code[:2], code[2:])
+	}
+	return codes, nil
+}
+
+// ProvisioningURI returns the otpauth:// URI used by authenticator applications
+// to register the secret (usually rendered as a QR code).
+func (service *TOTPService) ProvisioningURI(secret, accountName string) string {
+	label := url.PathEscape(service.Issuer + ":" + accountName)
+
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", service.Issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprintf("%d", totpDigits))
+	params.Set("period", fmt.Sprintf("%d", totpPeriod))
+
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// ValidateCode checks that the specified code is valid for the secret at the current time and
+// returns the time step of the code. The codes of the time steps up to lastTimeStep were already
+// used and are rejected, so that a code cannot be replayed.
+func (service *TOTPService) ValidateCode(secret, code string, lastTimeStep int64) (int64, bool) {
+	return validateTOTPCode(secret, code, time.Now(), lastTimeStep)
+}
+
+func validateTOTPCode(secret, code string, t time.Time, lastTimeStep int64) (int64, bool) {
+	key, err := base32NoPadding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return 0, false
+	}
+
+	code = strings.Replace(code, " ", "", -1)
+	if len(code) != totpDigits {
+		return 0, false
+	}
+
+	counter := t.Unix() / totpPeriod
+	for i := -totpSkew; i <= totpSkew; i++ {
+		timeStep := counter + int64(i)
+		if timeStep <= lastTimeStep {
+			continue
+		}
+
+		expected := generateTOTPCode(key, uint64(timeStep))
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return timeStep, true
+		}
+	}
+	return 0, false
+}
+
+// generateTOTPCode computes the HOTP value (RFC 4226) for the specified key and counter.
+func generateTOTPCode(key []byte, counter uint64) string {
+	message := make([]byte, 8)
+	binary.BigEndian.PutUint64(message, counter)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulo := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		modulo *= 10
+	}
+
+	return fmt.Sprintf("%0*d", totpDigits, value%modulo)
+}
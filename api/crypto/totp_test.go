@@ -0,0 +1,56 @@
+package crypto
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// Test vectors from RFC 6238 appendix B (SHA1), truncated to 6 digits.
+func TestValidateTOTPCode(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	cases := []struct {
+		timestamp int64
+		code      string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, c := range cases {
+		if _, valid := validateTOTPCode(secret, c.code, time.Unix(c.timestamp, 0), 0); !valid {
+			t.Errorf("Expected code %s to be valid at %d", c.code, c.timestamp)
+		}
+	}
+
+	if _, valid := validateTOTPCode(secret, "287082", time.Unix(1111111109, 0), 0); valid {
+		t.Error("Expected code to be rejected outside of its validity period")
+	}
+}
+
+func TestValidateTOTPCodeReplay(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	now := time.Unix(1111111109, 0)
+
+	timeStep, valid := validateTOTPCode(secret, "081804", now, 0)
+	if !valid || timeStep != 1111111109/totpPeriod {
+		t.Fatalf("Expected code to be accepted at time step %d, got %d (valid: %v)", 1111111109/totpPeriod, timeStep, valid)
+	}
+
+	if _, valid := validateTOTPCode(secret, "081804", now, timeStep); valid {
+		t.Error("Expected a code already used to be rejected")
+	}
+
+	// The code of the previous time step is still accepted within the skew,
+	// unless a more recent code was already used.
+	if _, valid := validateTOTPCode(secret, "081804", now.Add(totpPeriod*time.Second), timeStep-1); !valid {
+		t.Error("Expected a code of the previous time step to be accepted")
+	}
+	if _, valid := validateTOTPCode(secret, "081804", now.Add(totpPeriod*time.Second), timeStep+1); valid {
+		t.Error("Expected a code older than the last used code to be rejected")
+	}
+}
@@ -0,0 +1,379 @@
+// Package datastoretest provides a conformance test suite for the implementations
+// of the chainid.DataStore services.
+package datastoretest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/chainid-io/dashboard"
+)
+
+// Services groups the data services of a datastore.
+type Services struct {
+	DataStore              chainid.DataStore
+	UserService            chainid.UserService
+	TeamService            chainid.TeamService
+	TeamMembershipService  chainid.TeamMembershipService
+	EndpointService        chainid.EndpointService
+	EndpointGroupService   chainid.EndpointGroupService
+	ResourceControlService chainid.ResourceControlService
+	VersionService         chainid.VersionService
+	SettingsService        chainid.SettingsService
+	RegistryService        chainid.RegistryService
+	DockerHubService       chainid.DockerHubService
+	StackService           chainid.StackService
+	RoleService            chainid.RoleService
+	RoleAssignmentService  chainid.RoleAssignmentService
+	AuditLogService        chainid.AuditLogService
+	BackupStatusService    chainid.BackupStatusService
+}
+
+// Run runs the conformance test suite. newServices must return the services of an
+// empty and opened datastore, and a function releasing its resources.
+func Run(t *testing.T, newServices func(t *testing.T) (*Services, func())) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, services *Services)
+	}{
+		{"Init", testInit},
+		{"Users", testUsers},
+		{"TeamMemberships", testTeamMemberships},
+		{"Endpoints", testEndpoints},
+		{"ResourceControls", testResourceControls},
+		{"Stacks", testStacks},
+		{"RoleAssignments", testRoleAssignments},
+		{"AuditLog", testAuditLog},
+		{"Singletons", testSingletons},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			services, cleanup := newServices(t)
+			defer cleanup()
+			test.test(t, services)
+		})
+	}
+}
+
+func check(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func expectError(t *testing.T, err, expected error) {
+	t.Helper()
+	if err != expected {
+		t.Fatalf("expected error %q, got %v", expected, err)
+	}
+}
+
+func testInit(t *testing.T, services *Services) {
+	check(t, services.DataStore.Init())
+	check(t, services.DataStore.Init())
+	check(t, services.DataStore.MigrateData())
+
+	version, err := services.VersionService.DBVersion()
+	check(t, err)
+	if version != chainid.DBVersion {
+		t.Errorf("expected version %d, got %d", chainid.DBVersion, version)
+	}
+
+	groups, err := services.EndpointGroupService.EndpointGroups()
+	check(t, err)
+	if len(groups) != 1 || groups[0].ID != 1 {
+		t.Errorf("expected the default endpoint group, got %v", groups)
+	}
+
+	role, err := services.RoleService.Role(chainid.AdministratorRoleID)
+	check(t, err)
+	if !role.BuiltIn {
+		t.Error("the administrator role must be a built-in role")
+	}
+
+	roles, err := services.RoleService.Roles()
+	check(t, err)
+	if len(roles) != 2 {
+		t.Errorf("expected the 2 built-in roles, got %v", roles)
+	}
+
+	_, err = services.RoleService.RoleByName("Standard user")
+	check(t, err)
+}
+
+func testUsers(t *testing.T, services *Services) {
+	_, err := services.UserService.User(1)
+	expectError(t, err, chainid.ErrUserNotFound)
+
+	admin := &chainid.User{Username: "admin", Role: chainid.AdministratorRole}
+	check(t, services.UserService.CreateUser(admin))
+	user := &chainid.User{Username: "alice", Role: chainid.StandardUserRole}
+	check(t, services.UserService.CreateUser(user))
+	if admin.ID != 1 || user.ID != 2 {
+		t.Fatalf("expected sequential identifiers, got %d and %d", admin.ID, user.ID)
+	}
+
+	found, err := services.UserService.UserByUsername("alice")
+	check(t, err)
+	if found.ID != user.ID {
+		t.Errorf("expected user %d, got %d", user.ID, found.ID)
+	}
+	_, err = services.UserService.UserByUsername("bob")
+	expectError(t, err, chainid.ErrUserNotFound)
+
+	administrators, err := services.UserService.UsersByRole(chainid.AdministratorRole)
+	check(t, err)
+	if len(administrators) != 1 || administrators[0].Username != "admin" {
+		t.Errorf("expected the admin user, got %v", administrators)
+	}
+
+	user.Username = "alice2"
+	check(t, services.UserService.UpdateUser(user.ID, user))
+	found, err = services.UserService.User(user.ID)
+	check(t, err)
+	if found.Username != "alice2" {
+		t.Errorf("expected the user to be updated, got %v", found)
+	}
+
+	check(t, services.UserService.UpdateUserFunc(user.ID, func(user *chainid.User) error {
+		user.FailedLogins++
+		return nil
+	}))
+	errAborted := errors.New("aborted")
+	err = services.UserService.UpdateUserFunc(user.ID, func(user *chainid.User) error {
+		user.FailedLogins++
+		return errAborted
+	})
+	expectError(t, err, errAborted)
+	found, err = services.UserService.User(user.ID)
+	check(t, err)
+	if found.FailedLogins != 1 || found.Username != "alice2" {
+		t.Errorf("expected only the first update to be saved, got %v", found)
+	}
+	err = services.UserService.UpdateUserFunc(42, func(user *chainid.User) error { return nil })
+	expectError(t, err, chainid.ErrUserNotFound)
+
+	check(t, services.UserService.DeleteUser(admin.ID))
+	users, err := services.UserService.Users()
+	check(t, err)
+	if len(users) != 1 || users[0].ID != user.ID {
+		t.Errorf("expected only user %d, got %v", user.ID, users)
+	}
+
+	// Identifiers of deleted objects are not reused.
+	other := &chainid.User{Username: "bob"}
+	check(t, services.UserService.CreateUser(other))
+	if other.ID != 3 {
+		t.Errorf("expected identifier 3, got %d", other.ID)
+	}
+}
+
+func testTeamMemberships(t *testing.T, services *Services) {
+	team := &chainid.Team{Name: "developers"}
+	check(t, services.TeamService.CreateTeam(team))
+	otherTeam := &chainid.Team{Name: "operators"}
+	check(t, services.TeamService.CreateTeam(otherTeam))
+
+	found, err := services.TeamService.TeamByName("operators")
+	check(t, err)
+	if found.ID != otherTeam.ID {
+		t.Errorf("expected team %d, got %d", otherTeam.ID, found.ID)
+	}
+
+	memberships := []*chainid.TeamMembership{
+		{UserID: 1, TeamID: team.ID, Role: chainid.TeamLeader},
+		{UserID: 2, TeamID: team.ID, Role: chainid.TeamMember},
+		{UserID: 1, TeamID: otherTeam.ID, Role: chainid.TeamMember},
+	}
+	for _, membership := range memberships {
+		check(t, services.TeamMembershipService.CreateTeamMembership(membership))
+	}
+
+	byUser, err := services.TeamMembershipService.TeamMembershipsByUserID(1)
+	check(t, err)
+	byTeam, err := services.TeamMembershipService.TeamMembershipsByTeamID(team.ID)
+	check(t, err)
+	if len(byUser) != 2 || len(byTeam) != 2 {
+		t.Errorf("expected 2 memberships by user and by team, got %v and %v", byUser, byTeam)
+	}
+
+	check(t, services.TeamMembershipService.DeleteTeamMembershipByTeamID(team.ID))
+	remaining, err := services.TeamMembershipService.TeamMemberships()
+	check(t, err)
+	if len(remaining) != 1 || remaining[0].TeamID != otherTeam.ID {
+		t.Errorf("expected the membership of team %d only, got %v", otherTeam.ID, remaining)
+	}
+
+	check(t, services.TeamMembershipService.DeleteTeamMembershipByUserID(1))
+	remaining, err = services.TeamMembershipService.TeamMemberships()
+	check(t, err)
+	if len(remaining) != 0 {
+		t.Errorf("expected no membership, got %v", remaining)
+	}
+
+	_, err = services.TeamMembershipService.TeamMembership(memberships[0].ID)
+	expectError(t, err, chainid.ErrTeamMembershipNotFound)
+}
+
+func testEndpoints(t *testing.T, services *Services) {
+	first := &chainid.Endpoint{Name: "first", URL: "tcp://first:2375"}
+	check(t, services.EndpointService.CreateEndpoint(first))
+	second := &chainid.Endpoint{Name: "second", URL: "tcp://second:2375"}
+	check(t, services.EndpointService.CreateEndpoint(second))
+
+	second.URL = "tcp://second:2376"
+	third := &chainid.Endpoint{Name: "third", URL: "tcp://third:2375"}
+	check(t, services.EndpointService.Synchronize([]*chainid.Endpoint{third}, []*chainid.Endpoint{second}, []*chainid.Endpoint{first}))
+
+	endpoints, err := services.EndpointService.Endpoints()
+	check(t, err)
+	if len(endpoints) != 2 || endpoints[0].Name != "second" || endpoints[0].URL != "tcp://second:2376" || endpoints[1].ID != third.ID {
+		t.Errorf("unexpected endpoints after synchronization: %v", endpoints)
+	}
+
+	_, err = services.EndpointService.Endpoint(first.ID)
+	expectError(t, err, chainid.ErrEndpointNotFound)
+
+	check(t, services.EndpointService.UpdateEndpointFunc(third.ID, func(endpoint *chainid.Endpoint) {
+		endpoint.Status = chainid.EndpointStatusDown
+	}))
+	updated, err := services.EndpointService.Endpoint(third.ID)
+	check(t, err)
+	if updated.Status != chainid.EndpointStatusDown || updated.URL != "tcp://third:2375" {
+		t.Errorf("unexpected endpoint after update: %v", updated)
+	}
+
+	err = services.EndpointService.UpdateEndpointFunc(first.ID, func(endpoint *chainid.Endpoint) {})
+	expectError(t, err, chainid.ErrEndpointNotFound)
+}
+
+func testResourceControls(t *testing.T, services *Services) {
+	resourceControl := &chainid.ResourceControl{
+		ResourceID:     "service",
+		SubResourceIDs: []string{"task-1", "task-2"},
+		Type:           chainid.ServiceResourceControl,
+	}
+	check(t, services.ResourceControlService.CreateResourceControl(resourceControl))
+
+	for _, resourceID := range []string{"service", "task-2"} {
+		found, err := services.ResourceControlService.ResourceControlByResourceID(resourceID)
+		check(t, err)
+		if found.ID != resourceControl.ID {
+			t.Errorf("expected resource control %d for %s, got %d", resourceControl.ID, resourceID, found.ID)
+		}
+	}
+
+	_, err := services.ResourceControlService.ResourceControlByResourceID("unknown")
+	expectError(t, err, chainid.ErrResourceControlNotFound)
+
+	check(t, services.ResourceControlService.DeleteResourceControl(resourceControl.ID))
+	_, err = services.ResourceControlService.ResourceControl(resourceControl.ID)
+	expectError(t, err, chainid.ErrResourceControlNotFound)
+}
+
+func testStacks(t *testing.T, services *Services) {
+	stack := &chainid.Stack{ID: "web_swarm1", Name: "web", SwarmID: "swarm1", Env: []chainid.Pair{{Name: "TOKEN", Value: "secret"}}}
+	check(t, services.StackService.CreateStack(stack))
+	check(t, services.StackService.CreateStack(&chainid.Stack{ID: "db_swarm2", Name: "db", SwarmID: "swarm2"}))
+
+	found, err := services.StackService.Stack("web_swarm1")
+	check(t, err)
+	if found.Env[0].Value != "secret" {
+		t.Errorf("expected the stack environment to be stored, got %v", found.Env)
+	}
+
+	stacks, err := services.StackService.StacksBySwarmID("swarm1")
+	check(t, err)
+	if len(stacks) != 1 || stacks[0].ID != stack.ID {
+		t.Errorf("expected stack %s, got %v", stack.ID, stacks)
+	}
+
+	check(t, services.StackService.DeleteStack(stack.ID))
+	_, err = services.StackService.Stack(stack.ID)
+	expectError(t, err, chainid.ErrStackNotFound)
+}
+
+func testRoleAssignments(t *testing.T, services *Services) {
+	assignments := []*chainid.RoleAssignment{
+		{RoleID: 3, UserID: 1},
+		{RoleID: 3, TeamID: 1},
+		{RoleID: 4, UserID: 1},
+	}
+	for _, assignment := range assignments {
+		check(t, services.RoleAssignmentService.CreateRoleAssignment(assignment))
+	}
+
+	check(t, services.RoleAssignmentService.DeleteRoleAssignmentsByRoleID(3))
+	remaining, err := services.RoleAssignmentService.RoleAssignments()
+	check(t, err)
+	if len(remaining) != 1 || remaining[0].ID != assignments[2].ID {
+		t.Errorf("expected assignment %d only, got %v", assignments[2].ID, remaining)
+	}
+
+	check(t, services.RoleAssignmentService.DeleteRoleAssignmentsByUserID(1))
+	_, err = services.RoleAssignmentService.RoleAssignment(assignments[2].ID)
+	expectError(t, err, chainid.ErrRoleAssignmentNotFound)
+}
+
+func testAuditLog(t *testing.T, services *Services) {
+	for i := 1; i <= 5; i++ {
+		entry := &chainid.AuditLogEntry{Timestamp: int64(i), UserID: chainid.UserID(i%2 + 1), Method: "POST"}
+		check(t, services.AuditLogService.CreateAuditLogEntry(entry))
+		if entry.ID != chainid.AuditLogEntryID(i) {
+			t.Fatalf("expected entry %d, got %d", i, entry.ID)
+		}
+	}
+
+	entries, err := services.AuditLogService.AuditLogEntries(&chainid.AuditLogFilter{UserID: 2, Limit: 2})
+	check(t, err)
+	if len(entries) != 2 || entries[0].Timestamp != 5 || entries[1].Timestamp != 3 {
+		t.Errorf("expected the two most recent entries of user 2, got %v", entries)
+	}
+
+	entries, err = services.AuditLogService.AuditLogEntries(&chainid.AuditLogFilter{From: 2, To: 4})
+	check(t, err)
+	if len(entries) != 3 {
+		t.Errorf("expected 3 entries, got %v", entries)
+	}
+}
+
+func testSingletons(t *testing.T, services *Services) {
+	_, err := services.SettingsService.Settings()
+	expectError(t, err, chainid.ErrSettingsNotFound)
+	_, err = services.DockerHubService.DockerHub()
+	expectError(t, err, chainid.ErrDockerHubNotFound)
+	_, err = services.BackupStatusService.BackupStatus()
+	expectError(t, err, chainid.ErrBackupStatusNotFound)
+	_, err = services.VersionService.DBVersion()
+	expectError(t, err, chainid.ErrDBVersionNotFound)
+
+	check(t, services.SettingsService.StoreSettings(&chainid.Settings{LogoURL: "logo", LDAPSettings: chainid.LDAPSettings{Password: "secret"}}))
+	settings, err := services.SettingsService.Settings()
+	check(t, err)
+	if settings.LogoURL != "logo" || settings.LDAPSettings.Password != "secret" {
+		t.Errorf("unexpected settings: %v", settings)
+	}
+
+	check(t, services.DockerHubService.StoreDockerHub(&chainid.DockerHub{Authentication: true, Username: "user", Password: "secret"}))
+	dockerhub, err := services.DockerHubService.DockerHub()
+	check(t, err)
+	if dockerhub.Username != "user" || dockerhub.Password != "secret" {
+		t.Errorf("unexpected DockerHub credentials: %v", dockerhub)
+	}
+
+	check(t, services.BackupStatusService.StoreBackupStatus(&chainid.BackupStatus{LastArchive: "archive"}))
+	status, err := services.BackupStatusService.BackupStatus()
+	check(t, err)
+	if status.LastArchive != "archive" {
+		t.Errorf("unexpected backup status: %v", status)
+	}
+
+	check(t, services.VersionService.StoreDBVersion(7))
+	version, err := services.VersionService.DBVersion()
+	check(t, err)
+	if version != 7 {
+		t.Errorf("expected version 7, got %d", version)
+	}
+}
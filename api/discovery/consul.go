@@ -0,0 +1,127 @@
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/chainid-io/dashboard"
+)
+
+type (
+	// ConsulProvider finds the Docker hosts registered as instances of a service in the Consul catalog.
+	// The attributes of an instance are read from its tags, then from its metadata.
+	ConsulProvider struct {
+		address    string
+		service    string
+		tag        string
+		datacenter string
+		token      string
+		client     *http.Client
+	}
+
+	// consulService is an instance of a service returned by the catalog API of Consul.
+	consulService struct {
+		Node           string            `json:"Node"`
+		Address        string            `json:"Address"`
+		ServiceAddress string            `json:"ServiceAddress"`
+		ServicePort    int               `json:"ServicePort"`
+		ServiceTags    []string          `json:"ServiceTags"`
+		ServiceMeta    map[string]string `json:"ServiceMeta"`
+	}
+)
+
+// NewConsulProvider returns a provider using the Consul agent available at the specified address
+// (e.g. http://127.0.0.1:8500). The tag, datacenter and ACL token are optional.
+func NewConsulProvider(address, service, tag, datacenter, token string) *ConsulProvider {
+	return &ConsulProvider{
+		address:    strings.TrimSuffix(address, "/"),
+		service:    service,
+		tag:        tag,
+		datacenter: datacenter,
+		token:      token,
+		client:     &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// newConsulProvider returns the provider defined by a URL in the
+// consul://<address>/<service>?tag=<tag>&dc=<datacenter>&scheme=https format.
+func newConsulProvider(u *url.URL, token string) (*ConsulProvider, error) {
+	service := strings.Trim(u.Path, "/")
+	if service == "" || strings.Contains(service, "/") {
+		return nil, chainid.ErrInvalidDiscoveryProvider
+	}
+
+	query := u.Query()
+	scheme := query.Get("scheme")
+	if scheme == "" {
+		scheme = "http"
+	}
+	if scheme != "http" && scheme != "https" {
+		return nil, chainid.ErrInvalidDiscoveryProvider
+	}
+
+	return NewConsulProvider(scheme+"://"+u.Host, service, query.Get("tag"), query.Get("dc"), token), nil
+}
+
+// Name returns the name of the provider.
+func (provider *ConsulProvider) Name() string {
+	return "consul:" + provider.service
+}
+
+// Discover returns the instances of the service, sorted by name.
+func (provider *ConsulProvider) Discover() ([]chainid.DiscoveredEndpoint, error) {
+	query := url.Values{}
+	if provider.tag != "" {
+		query.Set("tag", provider.tag)
+	}
+	if provider.datacenter != "" {
+		query.Set("dc", provider.datacenter)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, provider.address+"/v1/catalog/service/"+url.PathEscape(provider.service)+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if provider.token != "" {
+		req.Header.Set("X-Consul-Token", provider.token)
+	}
+
+	resp, err := provider.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d returned by the Consul catalog", resp.StatusCode)
+	}
+
+	var services []consulService
+	err = json.NewDecoder(resp.Body).Decode(&services)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := make([]chainid.DiscoveredEndpoint, 0, len(services))
+	for _, service := range services {
+		attributes := parseTags(service.ServiceTags)
+		for key, value := range service.ServiceMeta {
+			setAttribute(attributes, key, value)
+		}
+		if attributes[nameAttribute] == "" {
+			attributes[nameAttribute] = service.Node
+		}
+
+		address := service.ServiceAddress
+		if address == "" {
+			address = service.Address
+		}
+		endpoints = append(endpoints, newDiscoveredEndpoint(address, service.ServicePort, attributes))
+	}
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].Name < endpoints[j].Name })
+	return endpoints, nil
+}
@@ -0,0 +1,99 @@
+package discovery
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+)
+
+// requestTimeout is the maximum duration of a query sent to a discovery system.
+const requestTimeout = 10 * time.Second
+
+// Attributes read from the tags and metadata of a registered Docker host.
+const (
+	nameAttribute          = "chainid.name"
+	groupAttribute         = "chainid.group"
+	publicURLAttribute     = "chainid.publicurl"
+	agentAttribute         = "chainid.agent"
+	tlsAttribute           = "chainid.tls"
+	tlsSkipVerifyAttribute = "chainid.tlsskipverify"
+	tlsCACertAttribute     = "chainid.tlscacert"
+	tlsCertAttribute       = "chainid.tlscert"
+	tlsKeyAttribute        = "chainid.tlskey"
+)
+
+// NewProvider returns the discovery provider defined by a source, either consul://<address>/<service>
+// to query the Consul catalog or dns://<name> to resolve the SRV records of a name. The token is the
+// ACL token sent to Consul, it is ignored by the DNS provider.
+func NewProvider(source, token string) (chainid.EndpointDiscoveryProvider, error) {
+	u, err := url.Parse(source)
+	if err != nil || u.Host == "" {
+		return nil, chainid.ErrInvalidDiscoveryProvider
+	}
+
+	switch u.Scheme {
+	case "consul":
+		return newConsulProvider(u, token)
+	case "dns":
+		return NewDNSProvider(u.Host, nil), nil
+	}
+	return nil, chainid.ErrInvalidDiscoveryProvider
+}
+
+// parseTags returns the attributes defined by the tags in the key=value format, the other tags are ignored.
+func parseTags(tags []string) map[string]string {
+	attributes := make(map[string]string)
+	for _, tag := range tags {
+		parts := strings.SplitN(tag, "=", 2)
+		if len(parts) == 2 {
+			setAttribute(attributes, parts[0], parts[1])
+		}
+	}
+	return attributes
+}
+
+// setAttribute records an attribute, the keys starting with chainid_ are accepted as well since
+// the Consul metadata keys cannot contain dots.
+func setAttribute(attributes map[string]string, key, value string) {
+	if strings.HasPrefix(key, "chainid_") {
+		key = "chainid." + strings.TrimPrefix(key, "chainid_")
+	}
+	if strings.HasPrefix(key, "chainid.") {
+		attributes[key] = value
+	}
+}
+
+// newDiscoveredEndpoint returns the endpoint of the Docker host reachable at the specified address,
+// configured from its attributes. The name defaults to the host of the address.
+func newDiscoveredEndpoint(host string, port int, attributes map[string]string) chainid.DiscoveredEndpoint {
+	endpoint := chainid.DiscoveredEndpoint{
+		Name:      host,
+		URL:       "tcp://" + net.JoinHostPort(host, strconv.Itoa(port)),
+		PublicURL: attributes[publicURLAttribute],
+		Group:     attributes[groupAttribute],
+		Agent:     parseBool(attributes[agentAttribute]),
+	}
+	if attributes[nameAttribute] != "" {
+		endpoint.Name = attributes[nameAttribute]
+	}
+
+	if parseBool(attributes[tlsAttribute]) {
+		endpoint.TLSConfig = chainid.TLSConfiguration{
+			TLS:           true,
+			TLSSkipVerify: parseBool(attributes[tlsSkipVerifyAttribute]),
+			TLSCACertPath: attributes[tlsCACertAttribute],
+			TLSCertPath:   attributes[tlsCertAttribute],
+			TLSKeyPath:    attributes[tlsKeyAttribute],
+		}
+	}
+	return endpoint
+}
+
+func parseBool(value string) bool {
+	b, _ := strconv.ParseBool(value)
+	return b
+}
@@ -0,0 +1,118 @@
+package discovery
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/chainid-io/dashboard"
+)
+
+func TestNewProvider(t *testing.T) {
+	tests := []struct {
+		source string
+		name   string
+	}{
+		{"consul://127.0.0.1:8500/docker?tag=production", "consul:docker"},
+		{"consul://consul.example.com/docker?scheme=https&dc=eu", "consul:docker"},
+		{"dns://_docker._tcp.example.com", "dns:_docker._tcp.example.com"},
+		{"consul://127.0.0.1:8500", ""},
+		{"consul://127.0.0.1:8500/docker?scheme=ftp", ""},
+		{"dns://", ""},
+		{"etcd://127.0.0.1:2379/docker", ""},
+	}
+	for _, test := range tests {
+		provider, err := NewProvider(test.source, "")
+		if test.name == "" {
+			if err != chainid.ErrInvalidDiscoveryProvider {
+				t.Errorf("expected %q to be rejected, got %v", test.source, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error for %q: %v", test.source, err)
+			continue
+		}
+		if provider.Name() != test.name {
+			t.Errorf("expected the provider of %q to be named %q, got %q", test.source, test.name, provider.Name())
+		}
+	}
+}
+
+func TestConsulProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/catalog/service/docker" || r.URL.Query().Get("tag") != "production" || r.Header.Get("X-Consul-Token") != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`[
+			{"Node":"node-2","Address":"10.0.0.2","ServicePort":2376,"ServiceTags":["production","chainid.group=web","chainid.tls=true"],"ServiceMeta":{"chainid_tlsskipverify":"true"}},
+			{"Node":"node-1","Address":"10.0.0.1","ServiceAddress":"192.168.0.1","ServicePort":2375,"ServiceTags":["production"],"ServiceMeta":{"chainid_name":"manager"}}
+		]`))
+	}))
+	defer server.Close()
+
+	provider := NewConsulProvider(server.URL, "docker", "production", "", "secret")
+	endpoints, err := provider.Discover()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []chainid.DiscoveredEndpoint{
+		{Name: "manager", URL: "tcp://192.168.0.1:2375"},
+		{Name: "node-2", URL: "tcp://10.0.0.2:2376", Group: "web", TLSConfig: chainid.TLSConfiguration{TLS: true, TLSSkipVerify: true}},
+	}
+	if !reflect.DeepEqual(endpoints, expected) {
+		t.Errorf("expected %+v, got %+v", expected, endpoints)
+	}
+
+	_, err = NewConsulProvider(server.URL, "docker", "production", "", "").Discover()
+	if err == nil {
+		t.Error("expected an error when the catalog rejects the request")
+	}
+}
+
+type fakeResolver struct {
+	records []*net.SRV
+	txt     map[string][]string
+}
+
+func (resolver *fakeResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	return name, resolver.records, nil
+}
+
+func (resolver *fakeResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	txt, ok := resolver.txt[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return txt, nil
+}
+
+func TestDNSProvider(t *testing.T) {
+	resolver := &fakeResolver{
+		records: []*net.SRV{
+			{Target: "docker-1.example.com.", Port: 2375},
+			{Target: "docker-2.example.com.", Port: 9001},
+		},
+		txt: map[string][]string{
+			"docker-2.example.com": {"v=spf1", "chainid.agent=true", "chainid.publicurl=docker-2.example.com"},
+		},
+	}
+
+	endpoints, err := NewDNSProvider("_docker._tcp.example.com", resolver).Discover()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []chainid.DiscoveredEndpoint{
+		{Name: "docker-1.example.com", URL: "tcp://docker-1.example.com:2375"},
+		{Name: "docker-2.example.com", URL: "tcp://docker-2.example.com:9001", PublicURL: "docker-2.example.com", Agent: true},
+	}
+	if !reflect.DeepEqual(endpoints, expected) {
+		t.Errorf("expected %+v, got %+v", expected, endpoints)
+	}
+}
@@ -0,0 +1,68 @@
+package discovery
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/chainid-io/dashboard"
+)
+
+type (
+	// DNSProvider finds the Docker hosts listed in the SRV records of a name. The attributes
+	// of a host are read from the TXT records of its target, in the key=value format.
+	DNSProvider struct {
+		name     string
+		resolver Resolver
+	}
+
+	// Resolver represents the DNS queries used by the DNS provider, it is implemented by net.Resolver.
+	Resolver interface {
+		LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+		LookupTXT(ctx context.Context, name string) ([]string, error)
+	}
+)
+
+// NewDNSProvider returns a provider resolving the SRV records of a name (e.g. _docker._tcp.example.com).
+// The default resolver is used when resolver is nil.
+func NewDNSProvider(name string, resolver Resolver) *DNSProvider {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	return &DNSProvider{
+		name:     name,
+		resolver: resolver,
+	}
+}
+
+// Name returns the name of the provider.
+func (provider *DNSProvider) Name() string {
+	return "dns:" + provider.name
+}
+
+// Discover returns the targets of the SRV records, sorted by priority and weight.
+func (provider *DNSProvider) Discover() ([]chainid.DiscoveredEndpoint, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	_, records, err := provider.resolver.LookupSRV(ctx, "", "", provider.name)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := make([]chainid.DiscoveredEndpoint, 0, len(records))
+	for _, record := range records {
+		target := strings.TrimSuffix(record.Target, ".")
+
+		// A target without TXT records is configured with the default settings.
+		txt, err := provider.resolver.LookupTXT(ctx, target)
+		if err != nil {
+			if dnsErr, ok := err.(*net.DNSError); !ok || !dnsErr.IsNotFound {
+				return nil, err
+			}
+		}
+
+		endpoints = append(endpoints, newDiscoveredEndpoint(target, int(record.Port), parseTags(txt)))
+	}
+	return endpoints, nil
+}
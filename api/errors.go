@@ -1,112 +1,214 @@
-package chainid
-
-// General errors.
-const (
-	ErrUnauthorized           = Error("Unauthorized")
-	ErrResourceAccessDenied   = Error("Access denied to resource")
-	ErrResourceNotFound       = Error("Unable to find resource")
-	ErrUnsupportedDockerAPI   = Error("Unsupported Docker API response")
-	ErrMissingSecurityContext = Error("Unable to find security details in request context")
-)
-
-// User errors.
-const (
-	ErrUserNotFound            = Error("User not found")
-	ErrUserAlreadyExists       = Error("User already exists")
-	ErrInvalidUsername         = Error("Invalid username. White spaces are not allowed")
-	ErrAdminAlreadyInitialized = Error("An administrator user already exists")
-	ErrAdminCannotRemoveSelf   = Error("Cannot remove your own user account. Contact another administrator")
-)
-
-// Team errors.
-const (
-	ErrTeamNotFound      = Error("Team not found")
-	ErrTeamAlreadyExists = Error("Team already exists")
-)
-
-// TeamMembership errors.
-const (
-	ErrTeamMembershipNotFound      = Error("Team membership not found")
-	ErrTeamMembershipAlreadyExists = Error("Team membership already exists for this user and team")
-)
-
-// ResourceControl errors.
-const (
-	ErrResourceControlNotFound      = Error("Resource control not found")
-	ErrResourceControlAlreadyExists = Error("A resource control is already applied on this resource")
-	ErrInvalidResourceControlType   = Error("Unsupported resource control type")
-)
-
-// Endpoint errors.
-const (
-	ErrEndpointNotFound     = Error("Endpoint not found")
-	ErrEndpointAccessDenied = Error("Access denied to endpoint")
-)
-
-// Azure environment errors
-const (
-	ErrAzureInvalidCredentials = Error("Invalid Azure credentials")
-)
-
-// Endpoint group errors.
-const (
-	ErrEndpointGroupNotFound    = Error("Endpoint group not found")
-	ErrCannotRemoveDefaultGroup = Error("Cannot remove the default endpoint group")
-)
-
-// Registry errors.
-const (
-	ErrRegistryNotFound      = Error("Registry not found")
-	ErrRegistryAlreadyExists = Error("A registry is already defined for this URL")
-)
-
-// Stack errors
-const (
-	ErrStackNotFound                   = Error("Stack not found")
-	ErrStackAlreadyExists              = Error("A stack already exists with this name")
-	ErrComposeFileNotFoundInRepository = Error("Unable to find a Compose file in the repository")
-)
-
-// Endpoint extensions error
-const (
-	ErrEndpointExtensionNotSupported      = Error("This extension is not supported")
-	ErrEndpointExtensionAlreadyAssociated = Error("This extension is already associated to the endpoint")
-)
-
-// Version errors.
-const (
-	ErrDBVersionNotFound = Error("DB version not found")
-)
-
-// Settings errors.
-const (
-	ErrSettingsNotFound = Error("Settings not found")
-)
-
-// DockerHub errors.
-const (
-	ErrDockerHubNotFound = Error("Dockerhub not found")
-)
-
-// Crypto errors.
-const (
-	ErrCryptoHashFailure = Error("Unable to hash data")
-)
-
-// JWT errors.
-const (
-	ErrSecretGeneration   = Error("Unable to generate secret key")
-	ErrInvalidJWTToken    = Error("Invalid JWT token")
-	ErrMissingContextData = Error("Unable to find JWT data in request context")
-)
-
-// File errors.
-const (
-	ErrUndefinedTLSFileType = Error("Undefined TLS file type")
-)
-
-// Error represents an application error.
-type Error string
-
-// Error returns the error message.
-func (e Error) Error() string { return string(e) }
+package chainid
+
+// General errors.
+const (
+	ErrUnauthorized           = Error("Unauthorized")
+	ErrResourceAccessDenied   = Error("Access denied to resource")
+	ErrResourceNotFound       = Error("Unable to find resource")
+	ErrUnsupportedDockerAPI   = Error("Unsupported Docker API response")
+	ErrMissingSecurityContext = Error("Unable to find security details in request context")
+)
+
+// User errors.
+const (
+	ErrUserNotFound            = Error("User not found")
+	ErrUserAlreadyExists       = Error("User already exists")
+	ErrInvalidUsername         = Error("Invalid username. White spaces are not allowed")
+	ErrAdminAlreadyInitialized = Error("An administrator user already exists")
+	ErrAdminCannotRemoveSelf   = Error("Cannot remove your own user account. Contact another administrator")
+	ErrAccountLocked           = Error("Account is locked after too many failed login attempts. Contact an administrator")
+	ErrLoginDelayNotElapsed    = Error("Too many failed login attempts. Retry later")
+)
+
+// Password policy errors.
+const (
+	ErrPasswordTooShort              = Error("Password does not meet the minimum length required by the password policy")
+	ErrPasswordMissingCharacterClass = Error("Password does not contain the character classes required by the password policy")
+	ErrPasswordReused                = Error("Password has been used recently and cannot be reused")
+	ErrPasswordChangeRequired        = Error("Password must be changed")
+)
+
+// Two-factor authentication errors.
+const (
+	ErrTwoFactorAuthenticationRequired = Error("Two-factor authentication must be enabled for this account")
+	ErrTOTPAlreadyEnabled              = Error("Two-factor authentication is already enabled for this user")
+	ErrTOTPNotEnrolled                 = Error("Two-factor authentication enrollment has not been started for this user")
+	ErrInvalidTOTPCode                 = Error("Invalid two-factor authentication code")
+	ErrTOTPRequiresMasterKey           = Error("Two-factor authentication requires a master key to encrypt the secrets stored in the database")
+)
+
+// Team errors.
+const (
+	ErrTeamNotFound      = Error("Team not found")
+	ErrTeamAlreadyExists = Error("Team already exists")
+)
+
+// TeamMembership errors.
+const (
+	ErrTeamMembershipNotFound      = Error("Team membership not found")
+	ErrTeamMembershipAlreadyExists = Error("Team membership already exists for this user and team")
+)
+
+// Role errors.
+const (
+	ErrRoleNotFound                 = Error("Role not found")
+	ErrRoleAlreadyExists            = Error("A role with the same name already exists")
+	ErrBuiltInRoleCannotBeModified  = Error("The administrator role cannot be modified")
+	ErrBuiltInRoleCannotBeRemoved   = Error("Built-in roles cannot be removed")
+	ErrInvalidPermission            = Error("Unsupported permission")
+	ErrRoleAssignmentNotFound       = Error("Role assignment not found")
+	ErrRoleAssignmentAlreadyExists  = Error("This role is already assigned with the same scope")
+	ErrInvalidRoleAssignmentSubject = Error("A role assignment must target either a user or a team")
+)
+
+// ResourceControl errors.
+const (
+	ErrResourceControlNotFound      = Error("Resource control not found")
+	ErrResourceControlAlreadyExists = Error("A resource control is already applied on this resource")
+	ErrInvalidResourceControlType   = Error("Unsupported resource control type")
+)
+
+// Endpoint errors.
+const (
+	ErrEndpointNotFound           = Error("Endpoint not found")
+	ErrEndpointAccessDenied       = Error("Access denied to endpoint")
+	ErrEndpointStatusNotSupported = Error("Status checks are not supported for this endpoint")
+	ErrEndpointCircuitOpen        = Error("The endpoint is unreachable, requests are suspended after repeated connection failures")
+	ErrEndpointExternallyManaged  = Error("The endpoint is managed by an external source")
+)
+
+// Endpoint discovery errors.
+const (
+	ErrInvalidDiscoveryProvider = Error("Invalid discovery provider: Chain Platform only supports consul://<address>/<service> or dns://<name>")
+)
+
+// Edge endpoint errors.
+const (
+	ErrTunnelNotConnected   = Error("The tunnel of the edge endpoint is not connected")
+	ErrInvalidEdgeJoinToken = Error("Invalid edge join token")
+)
+
+// Kubernetes endpoint errors.
+const (
+	ErrInvalidKubeconfig            = Error("Invalid kubeconfig")
+	ErrKubernetesNamespaceDenied    = Error("Access denied to Kubernetes namespace")
+	ErrKubernetesClusterScopeDenied = Error("Access denied to Kubernetes cluster-scoped resources")
+)
+
+// SSH endpoint errors.
+const (
+	ErrInvalidSSHEndpointURL = Error("Invalid SSH endpoint URL, expected ssh://user@host[:port]")
+	ErrInvalidSSHKey         = Error("Invalid SSH private key")
+	ErrInvalidSSHKnownHosts  = Error("Invalid SSH known hosts file")
+	ErrSSHHostKeyRequired    = Error("A known hosts file is required unless SSH host key verification is disabled")
+	ErrSSHHostKeyMismatch    = Error("The SSH host key is not listed in the known hosts file")
+)
+
+// Azure environment errors
+const (
+	ErrAzureInvalidCredentials = Error("Invalid Azure credentials")
+)
+
+// Endpoint group errors.
+const (
+	ErrEndpointGroupNotFound    = Error("Endpoint group not found")
+	ErrCannotRemoveDefaultGroup = Error("Cannot remove the default endpoint group")
+)
+
+// Registry errors.
+const (
+	ErrRegistryNotFound      = Error("Registry not found")
+	ErrRegistryAlreadyExists = Error("A registry is already defined for this URL")
+)
+
+// Stack errors
+const (
+	ErrStackNotFound                   = Error("Stack not found")
+	ErrStackAlreadyExists              = Error("A stack already exists with this name")
+	ErrComposeFileNotFoundInRepository = Error("Unable to find a Compose file in the repository")
+)
+
+// Endpoint extensions error
+const (
+	ErrEndpointExtensionNotSupported      = Error("This extension is not supported")
+	ErrEndpointExtensionAlreadyAssociated = Error("This extension is already associated to the endpoint")
+)
+
+// Version errors.
+const (
+	ErrDBVersionNotFound         = Error("DB version not found")
+	ErrMigrationSnapshotNotFound = Error("No copy of the database made before a migration was found")
+)
+
+// Settings errors.
+const (
+	ErrSettingsNotFound = Error("Settings not found")
+)
+
+// DockerHub errors.
+const (
+	ErrDockerHubNotFound = Error("Dockerhub not found")
+)
+
+// Crypto errors.
+const (
+	ErrCryptoHashFailure    = Error("Unable to hash data")
+	ErrInvalidEncryptionKey = Error("Invalid encryption key")
+	ErrDecryptionFailure    = Error("Unable to decrypt data")
+)
+
+// Backup errors.
+const (
+	ErrInvalidBackupArchive     = Error("Invalid backup archive")
+	ErrBackupPasswordRequired   = Error("This backup is encrypted, a password is required to restore it")
+	ErrUnsupportedBackupVersion = Error("The backup was created with a more recent version of the database")
+	ErrBackupStatusNotFound     = Error("No scheduled backup has been run yet")
+	ErrInvalidBackupSchedule    = Error("Invalid backup schedule")
+	ErrInvalidBackupTarget      = Error("Invalid backup target")
+)
+
+// Datastore errors.
+const (
+	ErrDataStoreOperationNotSupported = Error("This operation is not supported by the datastore")
+	ErrDataStoreMigrationNotSupported = Error("The data of this datastore cannot be migrated, it must be migrated in a Bolt database and imported again")
+	ErrDataStoreNotEmpty              = Error("The datastore already contains data")
+	ErrDBBucketNotFound               = Error("Bucket not found in the database")
+	ErrDBNotFound                     = Error("No database found in the data directory")
+	ErrDBVerificationFailed           = Error("Problems were found in the database")
+)
+
+// Encryption at rest errors.
+const (
+	ErrMasterKeyRequired = Error("The database contains encrypted secrets, a master key is required")
+	ErrInvalidMasterKey  = Error("Unable to decrypt the data key with the specified master key")
+)
+
+// Config errors.
+const (
+	ErrInvalidConfigFormat = Error("Unsupported configuration format")
+)
+
+// Cluster errors.
+const (
+	ErrClusterLockTimeout = Error("Unable to acquire the lock, another instance is holding it")
+	ErrClusterLockLost    = Error("The lock has been lost before the end of the operation")
+)
+
+// JWT errors.
+const (
+	ErrSecretGeneration   = Error("Unable to generate secret key")
+	ErrInvalidJWTToken    = Error("Invalid JWT token")
+	ErrMissingContextData = Error("Unable to find JWT data in request context")
+)
+
+// File errors.
+const (
+	ErrUndefinedTLSFileType = Error("Undefined TLS file type")
+	ErrUndefinedSSHFileType = Error("Undefined SSH file type")
+)
+
+// Error represents an application error.
+type Error string
+
+// Error returns the error message.
+func (e Error) Error() string { return string(e) }
@@ -1,99 +1,181 @@
-package client
-
-import (
-	"crypto/tls"
-	"encoding/json"
-	"fmt"
-	"net/http"
-	"net/url"
-	"strings"
-	"time"
-
-	"github.com/chainid-io/dashboard"
-)
-
-// HTTPClient represents a client to send HTTP requests.
-type HTTPClient struct {
-	*http.Client
-}
-
-// NewHTTPClient is used to build a new HTTPClient.
-func NewHTTPClient() *HTTPClient {
-	return &HTTPClient{
-		&http.Client{
-			Timeout: time.Second * 5,
-		},
-	}
-}
-
-// AzureAuthenticationResponse represents an Azure API authentication response.
-type AzureAuthenticationResponse struct {
-	AccessToken string `json:"access_token"`
-	ExpiresOn   string `json:"expires_on"`
-}
-
-// ExecuteAzureAuthenticationRequest is used to execute an authentication request
-// against the Azure API. It re-uses the same http.Client.
-func (client *HTTPClient) ExecuteAzureAuthenticationRequest(credentials *chainid.AzureCredentials) (*AzureAuthenticationResponse, error) {
-	loginURL := fmt.Sprintf("https://login.microsoftonline.com/%s/oauth2/token", credentials.TenantID)
-	params := url.Values{
-		"grant_type":    {"client_credentials"},
-		"client_id":     {credentials.ApplicationID},
-		"client_secret": {credentials.AuthenticationKey},
-		"resource":      {"https://management.azure.com/"},
-	}
-
-	response, err := client.PostForm(loginURL, params)
-	if err != nil {
-		return nil, err
-	}
-
-	if response.StatusCode != http.StatusOK {
-		return nil, chainid.ErrAzureInvalidCredentials
-	}
-
-	var token AzureAuthenticationResponse
-	err = json.NewDecoder(response.Body).Decode(&token)
-	if err != nil {
-		return nil, err
-	}
-
-	return &token, nil
-}
-
-// ExecutePingOperation will send a SystemPing operation HTTP request to a Docker environment
-// using the specified host and optional TLS configuration.
-// It uses a new Http.Client for each operation.
-func ExecutePingOperation(host string, tlsConfig *tls.Config) (bool, error) {
-	transport := &http.Transport{}
-
-	scheme := "http"
-	if tlsConfig != nil {
-		transport.TLSClientConfig = tlsConfig
-		scheme = "https"
-	}
-
-	client := &http.Client{
-		Timeout:   time.Second * 3,
-		Transport: transport,
-	}
-
-	target := strings.Replace(host, "tcp://", scheme+"://", 1)
-	return pingOperation(client, target)
-}
-
-func pingOperation(client *http.Client, target string) (bool, error) {
-	pingOperationURL := target + "/_ping"
-
-	response, err := client.Get(pingOperationURL)
-	if err != nil {
-		return false, err
-	}
-
-	agentOnDockerEnvironment := false
-	if response.Header.Get(chainid.PortainerAgentHeader) != "" {
-		agentOnDockerEnvironment = true
-	}
-
-	return agentOnDockerEnvironment, nil
-}
+package client
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+)
+
+// HTTPClient represents a client to send HTTP requests.
+type HTTPClient struct {
+	*http.Client
+}
+
+// NewHTTPClient is used to build a new HTTPClient.
+func NewHTTPClient() *HTTPClient {
+	return &HTTPClient{
+		&http.Client{
+			Timeout: time.Second * 5,
+		},
+	}
+}
+
+// AzureAuthenticationResponse represents an Azure API authentication response.
+type AzureAuthenticationResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresOn   string `json:"expires_on"`
+}
+
+// ExecuteAzureAuthenticationRequest is used to execute an authentication request
+// against the Azure API. It re-uses the same http.Client.
+func (client *HTTPClient) ExecuteAzureAuthenticationRequest(credentials *chainid.AzureCredentials) (*AzureAuthenticationResponse, error) {
+	loginURL := fmt.Sprintf("https://login.microsoftonline.com/%s/oauth2/token", credentials.TenantID)
+	params := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {credentials.ApplicationID},
+		"client_secret": {credentials.AuthenticationKey},
+		"resource":      {"https://management.azure.com/"},
+	}
+
+	response, err := client.PostForm(loginURL, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, chainid.ErrAzureInvalidCredentials
+	}
+
+	var token AzureAuthenticationResponse
+	err = json.NewDecoder(response.Body).Decode(&token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// ExecutePingOperation will send a SystemPing operation HTTP request to a Docker environment
+// using the specified host and optional TLS configuration.
+// It uses a new Http.Client for each operation.
+func ExecutePingOperation(host string, tlsConfig *tls.Config) (bool, error) {
+	transport := &http.Transport{}
+
+	scheme := "http"
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+		scheme = "https"
+	}
+
+	client := &http.Client{
+		Timeout:   time.Second * 3,
+		Transport: transport,
+	}
+
+	target := strings.Replace(host, "tcp://", scheme+"://", 1)
+	return pingOperation(client, target)
+}
+
+// ExecuteSSHPingOperation will send a SystemPing operation HTTP request to a Docker environment
+// reached over SSH using the specified dial function. The timeout includes the SSH handshake.
+func ExecuteSSHPingOperation(dial func() (net.Conn, error)) (bool, error) {
+	transport := &http.Transport{
+		Dial: func(proto, addr string) (net.Conn, error) {
+			return dial()
+		},
+	}
+	defer transport.CloseIdleConnections()
+
+	client := &http.Client{
+		Timeout:   time.Second * 10,
+		Transport: transport,
+	}
+
+	return pingOperation(client, "http://ssh")
+}
+
+// ExecuteVersionOperation will send a SystemPing and a SystemVersion operation HTTP request to
+// a Docker environment using the specified transport and return the version of the Docker engine.
+func ExecuteVersionOperation(target string, transport http.RoundTripper) (string, error) {
+	client := &http.Client{
+		Timeout:   time.Second * 3,
+		Transport: transport,
+	}
+
+	_, err := pingOperation(client, target)
+	if err != nil {
+		return "", err
+	}
+
+	var version struct {
+		Version string `json:"Version"`
+	}
+	err = getOperation(client, target+"/version", &version)
+	if err != nil {
+		return "", err
+	}
+
+	return version.Version, nil
+}
+
+// ExecuteKubernetesVersionOperation will send a version request to the API server of a Kubernetes
+// environment and return the version of the API server. The token is optional when the client
+// is authenticated with a certificate.
+func ExecuteKubernetesVersionOperation(serverURL, token string, tlsConfig *tls.Config) (string, error) {
+	client := &http.Client{
+		Timeout:   time.Second * 3,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+	}
+
+	request, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(serverURL, "/")+"/version", nil)
+	if err != nil {
+		return "", err
+	}
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code for %s: %d", request.URL, response.StatusCode)
+	}
+
+	var version struct {
+		GitVersion string `json:"gitVersion"`
+	}
+	err = json.NewDecoder(response.Body).Decode(&version)
+	if err != nil {
+		return "", err
+	}
+
+	return version.GitVersion, nil
+}
+
+func pingOperation(client *http.Client, target string) (bool, error) {
+	pingOperationURL := target + "/_ping"
+
+	response, err := client.Get(pingOperationURL)
+	if err != nil {
+		return false, err
+	}
+	defer response.Body.Close()
+
+	agentOnDockerEnvironment := false
+	if response.Header.Get(chainid.PortainerAgentHeader) != "" {
+		agentOnDockerEnvironment = true
+	}
+
+	return agentOnDockerEnvironment, nil
+}
@@ -0,0 +1,208 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+)
+
+type (
+	dockerInfo struct {
+		ServerVersion string `json:"ServerVersion"`
+		NCPU          int    `json:"NCPU"`
+		MemTotal      int64  `json:"MemTotal"`
+		Swarm         struct {
+			LocalNodeState   string `json:"LocalNodeState"`
+			ControlAvailable bool   `json:"ControlAvailable"`
+			Cluster          struct {
+				ID string `json:"ID"`
+			} `json:"Cluster"`
+		} `json:"Swarm"`
+	}
+
+	dockerContainer struct {
+		ID     string            `json:"Id"`
+		Names  []string          `json:"Names"`
+		Image  string            `json:"Image"`
+		State  string            `json:"State"`
+		Labels map[string]string `json:"Labels"`
+	}
+
+	dockerVolume struct {
+		Name   string            `json:"Name"`
+		Labels map[string]string `json:"Labels"`
+	}
+
+	dockerVolumeList struct {
+		Volumes []dockerVolume `json:"Volumes"`
+	}
+
+	dockerNetwork struct {
+		ID     string            `json:"Id"`
+		Name   string            `json:"Name"`
+		Labels map[string]string `json:"Labels"`
+	}
+
+	dockerService struct {
+		ID   string `json:"ID"`
+		Spec struct {
+			Name         string            `json:"Name"`
+			Labels       map[string]string `json:"Labels"`
+			TaskTemplate struct {
+				ContainerSpec struct {
+					Image string `json:"Image"`
+				} `json:"ContainerSpec"`
+			} `json:"TaskTemplate"`
+		} `json:"Spec"`
+	}
+
+	dockerNode struct {
+		Description struct {
+			Resources struct {
+				NanoCPUs    int64 `json:"NanoCPUs"`
+				MemoryBytes int64 `json:"MemoryBytes"`
+			} `json:"Resources"`
+		} `json:"Description"`
+	}
+)
+
+// ExecuteSnapshotOperation will send the HTTP requests required to create a snapshot of a
+// Docker environment using the specified transport. When the environment is a Swarm manager,
+// the CPU and memory totals are computed from the resources of every node of the cluster
+// and the identifier of the cluster is recorded to associate the endpoint with its stacks.
+// The containers, services, volumes and networks are recorded in the snapshot to be searched.
+func ExecuteSnapshotOperation(target string, transport http.RoundTripper) (*chainid.Snapshot, error) {
+	client := &http.Client{
+		Timeout:   time.Second * 10,
+		Transport: transport,
+	}
+
+	var info dockerInfo
+	err := getOperation(client, target+"/info", &info)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &chainid.Snapshot{
+		Time:          time.Now().Unix(),
+		DockerVersion: info.ServerVersion,
+		Swarm:         info.Swarm.LocalNodeState == "active",
+		SwarmID:       info.Swarm.Cluster.ID,
+		NodeCount:     1,
+		TotalCPU:      info.NCPU,
+		TotalMemory:   info.MemTotal,
+	}
+
+	var containers []dockerContainer
+	err = getOperation(client, target+"/containers/json?all=1", &containers)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.ContainerCount = len(containers)
+	for _, container := range containers {
+		if container.State == "running" {
+			snapshot.RunningContainerCount++
+		} else {
+			snapshot.StoppedContainerCount++
+		}
+
+		resource := chainid.SnapshotResource{
+			Type:   chainid.ContainerSearchResult,
+			ID:     container.ID,
+			Image:  container.Image,
+			Labels: container.Labels,
+		}
+		if len(container.Names) > 0 {
+			resource.Name = strings.TrimPrefix(container.Names[0], "/")
+		}
+		snapshot.Resources = append(snapshot.Resources, resource)
+	}
+
+	var images []json.RawMessage
+	err = getOperation(client, target+"/images/json", &images)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.ImageCount = len(images)
+
+	var volumes dockerVolumeList
+	err = getOperation(client, target+"/volumes", &volumes)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.VolumeCount = len(volumes.Volumes)
+	for _, volume := range volumes.Volumes {
+		snapshot.Resources = append(snapshot.Resources, chainid.SnapshotResource{
+			Type:   chainid.VolumeSearchResult,
+			ID:     volume.Name,
+			Name:   volume.Name,
+			Labels: volume.Labels,
+		})
+	}
+
+	var networks []dockerNetwork
+	err = getOperation(client, target+"/networks", &networks)
+	if err != nil {
+		return nil, err
+	}
+	for _, network := range networks {
+		snapshot.Resources = append(snapshot.Resources, chainid.SnapshotResource{
+			Type:   chainid.NetworkSearchResult,
+			ID:     network.ID,
+			Name:   network.Name,
+			Labels: network.Labels,
+		})
+	}
+
+	if snapshot.Swarm && info.Swarm.ControlAvailable {
+		var services []dockerService
+		err = getOperation(client, target+"/services", &services)
+		if err != nil {
+			return nil, err
+		}
+		snapshot.ServiceCount = len(services)
+		for _, service := range services {
+			snapshot.Resources = append(snapshot.Resources, chainid.SnapshotResource{
+				Type:   chainid.ServiceSearchResult,
+				ID:     service.ID,
+				Name:   service.Spec.Name,
+				Image:  service.Spec.TaskTemplate.ContainerSpec.Image,
+				Labels: service.Spec.Labels,
+			})
+		}
+
+		var nodes []dockerNode
+		err = getOperation(client, target+"/nodes", &nodes)
+		if err != nil {
+			return nil, err
+		}
+
+		snapshot.NodeCount = len(nodes)
+		snapshot.TotalCPU = 0
+		snapshot.TotalMemory = 0
+		for _, node := range nodes {
+			snapshot.TotalCPU += int(node.Description.Resources.NanoCPUs / 1e9)
+			snapshot.TotalMemory += node.Description.Resources.MemoryBytes
+		}
+	}
+
+	return snapshot, nil
+}
+
+func getOperation(client *http.Client, operationURL string, v interface{}) error {
+	response, err := client.Get(operationURL)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code for %s: %d", operationURL, response.StatusCode)
+	}
+
+	return json.NewDecoder(response.Body).Decode(v)
+}
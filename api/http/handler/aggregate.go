@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+	httperror "github.com/chainid-io/dashboard/http/error"
+	"github.com/chainid-io/dashboard/http/proxy"
+	"github.com/chainid-io/dashboard/http/security"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// aggregateConcurrency is the maximum number of endpoints queried at the same time.
+	aggregateConcurrency = 10
+	// aggregateTimeout is the time given to each endpoint to answer.
+	aggregateTimeout = 30 * time.Second
+)
+
+// AggregateHandler represents an HTTP API handler for listing the Docker resources of every
+// endpoint accessible to the user.
+type AggregateHandler struct {
+	*mux.Router
+	Logger               *log.Logger
+	EndpointService      chainid.EndpointService
+	EndpointGroupService chainid.EndpointGroupService
+	ProxyManager         *proxy.Manager
+}
+
+// aggregatedResourceType describes how the resources of a type are listed through the Docker API.
+type aggregatedResourceType struct {
+	path string
+	// field is the field of the response object containing the resources, it is empty when
+	// the Docker API answers with an array.
+	field string
+}
+
+var aggregatedResourceTypes = map[string]aggregatedResourceType{
+	"containers": {path: "/containers/json"},
+	"services":   {path: "/services"},
+	"images":     {path: "/images/json"},
+	"volumes":    {path: "/volumes", field: "Volumes"},
+	"networks":   {path: "/networks"},
+}
+
+// NewAggregateHandler returns a new instance of AggregateHandler.
+func NewAggregateHandler(bouncer *security.RequestBouncer) *AggregateHandler {
+	h := &AggregateHandler{
+		Router: mux.NewRouter(),
+		Logger: log.New(os.Stderr, "", log.LstdFlags),
+	}
+	h.Handle("/aggregate/{resourceType:containers|services|images|volumes|networks}",
+		bouncer.RestrictedAccess(http.HandlerFunc(h.handleGetAggregate))).Methods(http.MethodGet)
+
+	return h
+}
+
+type aggregatedResource struct {
+	EndpointID   chainid.EndpointID `json:"EndpointId"`
+	EndpointName string             `json:"EndpointName"`
+	Resource     json.RawMessage    `json:"Resource"`
+}
+
+type aggregateEndpointError struct {
+	EndpointID   chainid.EndpointID `json:"EndpointId"`
+	EndpointName string             `json:"EndpointName"`
+	Err          string             `json:"Err"`
+}
+
+type getAggregateResponse struct {
+	Resources []aggregatedResource     `json:"Resources"`
+	Errors    []aggregateEndpointError `json:"Errors"`
+}
+
+// handleGetAggregate handles GET requests on /aggregate/{resourceType}
+// The resources are listed concurrently on every Docker endpoint accessible to the user, the
+// query parameters are forwarded to the Docker API. The endpoints that cannot be queried are
+// reported in the Errors field of the response along with the resources of the other endpoints.
+func (handler *AggregateHandler) handleGetAggregate(w http.ResponseWriter, r *http.Request) {
+	resourceType := aggregatedResourceTypes[mux.Vars(r)["resourceType"]]
+
+	securityContext, err := security.RetrieveRestrictedRequestContext(r)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	endpoints, err := handler.EndpointService.Endpoints()
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	groups, err := handler.EndpointGroupService.EndpointGroups()
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	filteredEndpoints, err := security.FilterEndpoints(endpoints, groups, securityContext)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	dockerEndpoints := make([]chainid.Endpoint, 0)
+	for _, endpoint := range filteredEndpoints {
+		if endpoint.Type != chainid.AzureEnvironment && endpoint.Type != chainid.KubernetesEnvironment {
+			dockerEndpoints = append(dockerEndpoints, endpoint)
+		}
+	}
+
+	resources := make([][]json.RawMessage, len(dockerEndpoints))
+	errors := make([]error, len(dockerEndpoints))
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, aggregateConcurrency)
+	for i := range dockerEndpoints {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			resources[i], errors[i] = handler.listEndpointResources(r, &dockerEndpoints[i], resourceType)
+		}(i)
+	}
+	wg.Wait()
+
+	response := &getAggregateResponse{
+		Resources: make([]aggregatedResource, 0),
+		Errors:    make([]aggregateEndpointError, 0),
+	}
+	for i, endpoint := range dockerEndpoints {
+		if errors[i] != nil {
+			response.Errors = append(response.Errors, aggregateEndpointError{
+				EndpointID:   endpoint.ID,
+				EndpointName: endpoint.Name,
+				Err:          errors[i].Error(),
+			})
+			continue
+		}
+
+		for _, resource := range resources[i] {
+			response.Resources = append(response.Resources, aggregatedResource{
+				EndpointID:   endpoint.ID,
+				EndpointName: endpoint.Name,
+				Resource:     resource,
+			})
+		}
+	}
+
+	encodeJSON(w, response, handler.Logger)
+}
+
+// listEndpointResources lists the resources of an endpoint through its proxy, the resources
+// are filtered and decorated the same way as when the Docker API of the endpoint is queried directly.
+func (handler *AggregateHandler) listEndpointResources(r *http.Request, endpoint *chainid.Endpoint, resourceType aggregatedResourceType) ([]json.RawMessage, error) {
+	proxy := handler.ProxyManager.GetProxy(endpoint.ID)
+	if proxy == nil {
+		var err error
+		proxy, err = handler.ProxyManager.CreateAndRegisterProxy(endpoint)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(security.StoreEndpointGroupID(r, endpoint.GroupID), aggregateTimeout)
+	defer cancel()
+
+	request, err := http.NewRequest(http.MethodGet, resourceType.path, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.URL.RawQuery = r.URL.RawQuery
+	request = request.WithContext(ctx)
+
+	response := &bufferedResponseWriter{header: make(http.Header)}
+	proxy.ServeHTTP(response, request)
+
+	if response.statusCode != http.StatusOK {
+		var dockerError struct {
+			Message string `json:"message"`
+			Err     string `json:"err"`
+		}
+		json.Unmarshal(response.body.Bytes(), &dockerError)
+		if dockerError.Message != "" {
+			return nil, chainid.Error(dockerError.Message)
+		} else if dockerError.Err != "" {
+			return nil, chainid.Error(dockerError.Err)
+		}
+		return nil, chainid.Error(http.StatusText(response.statusCode))
+	}
+
+	var resources []json.RawMessage
+	if resourceType.field == "" {
+		err = json.Unmarshal(response.body.Bytes(), &resources)
+	} else {
+		var object map[string][]json.RawMessage
+		err = json.Unmarshal(response.body.Bytes(), &object)
+		resources = object[resourceType.field]
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
+// bufferedResponseWriter keeps in memory the response of a request served internally.
+type bufferedResponseWriter struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedResponseWriter) WriteHeader(code int) {
+	if w.statusCode == 0 {
+		w.statusCode = code
+	}
+}
+
+func (w *bufferedResponseWriter) Write(data []byte) (int, error) {
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+	return w.body.Write(data)
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/http/proxy"
+	"github.com/chainid-io/dashboard/http/security"
+	"github.com/chainid-io/dashboard/jwt"
+	"github.com/chainid-io/dashboard/kv"
+)
+
+func TestAggregateContainers(t *testing.T) {
+	store := kv.NewStore(kv.NewMemoryBackend())
+	err := store.SettingsService.StoreSettings(&chainid.Settings{AuthenticationMethod: chainid.AuthenticationInternal})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := &chainid.User{Username: "alice", Role: chainid.StandardUserRole}
+	err = store.UserService.CreateUser(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	group := &chainid.EndpointGroup{Name: "Unassigned", AuthorizedUsers: []chainid.UserID{}, AuthorizedTeams: []chainid.TeamID{}}
+	err = store.EndpointGroupService.CreateEndpointGroup(group)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = store.ResourceControlService.CreateResourceControl(&chainid.ResourceControl{
+		ResourceID:         "restricted",
+		Type:               chainid.ContainerResourceControl,
+		AdministratorsOnly: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/containers/json" || r.URL.Query().Get("all") != "1" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"Id":"public"},{"Id":"restricted"}]`))
+	}))
+	defer healthy.Close()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"message":"daemon unavailable"}`))
+	}))
+	defer failing.Close()
+
+	var unauthorizedRequests int32
+	unauthorized := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&unauthorizedRequests, 1)
+		w.Write([]byte(`[]`))
+	}))
+	defer unauthorized.Close()
+
+	for _, endpoint := range []*chainid.Endpoint{
+		{Name: "healthy", URL: "tcp://" + strings.TrimPrefix(healthy.URL, "http://"), AuthorizedUsers: []chainid.UserID{user.ID}},
+		{Name: "failing", URL: "tcp://" + strings.TrimPrefix(failing.URL, "http://"), AuthorizedUsers: []chainid.UserID{user.ID}},
+		{Name: "unauthorized", URL: "tcp://" + strings.TrimPrefix(unauthorized.URL, "http://")},
+	} {
+		endpoint.Type = chainid.DockerEnvironment
+		endpoint.GroupID = group.ID
+		err = store.EndpointService.CreateEndpoint(endpoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	secret, err := jwt.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtService, err := jwt.NewService(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := jwtService.GenerateToken(&chainid.TokenData{ID: user.ID, Username: user.Username, Role: user.Role})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authorizer := security.NewAuthorizer(store.RoleService, store.RoleAssignmentService, store.TeamMembershipService)
+	bouncer := security.NewRequestBouncer(jwtService, store.UserService, store.TeamMembershipService, store.SettingsService, store.EndpointService, authorizer, false)
+
+	handler := NewAggregateHandler(bouncer)
+	handler.EndpointService = store.EndpointService
+	handler.EndpointGroupService = store.EndpointGroupService
+	handler.ProxyManager = proxy.NewManager(&proxy.ManagerParams{
+		ResourceControlService: store.ResourceControlService,
+		TeamMembershipService:  store.TeamMembershipService,
+		SettingsService:        store.SettingsService,
+		Authorizer:             authorizer,
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/aggregate/containers?all=1", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var response struct {
+		Resources []struct {
+			EndpointName string
+			Resource     struct {
+				ID string `json:"Id"`
+			}
+		}
+		Errors []aggregateEndpointError
+	}
+	err = json.Unmarshal(rr.Body.Bytes(), &response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(response.Resources) != 1 || response.Resources[0].EndpointName != "healthy" || response.Resources[0].Resource.ID != "public" {
+		t.Errorf("expected only the public container of the healthy endpoint, got %+v", response.Resources)
+	}
+	if len(response.Errors) != 1 || response.Errors[0].EndpointName != "failing" || response.Errors[0].Err != "daemon unavailable" {
+		t.Errorf("expected the failing endpoint to be reported, got %+v", response.Errors)
+	}
+	if atomic.LoadInt32(&unauthorizedRequests) != 0 {
+		t.Error("expected the endpoint the user cannot access not to be queried")
+	}
+}
@@ -0,0 +1,312 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+	httperror "github.com/chainid-io/dashboard/http/error"
+	"github.com/chainid-io/dashboard/http/security"
+
+	"github.com/gorilla/mux"
+)
+
+// AuditHandler represents an HTTP API handler for recording and querying the audit log.
+type AuditHandler struct {
+	*mux.Router
+	Logger          *log.Logger
+	AuditLogService chainid.AuditLogService
+	JWTService      chainid.JWTService
+	rateLimiter     *security.RateLimiter
+}
+
+// dockerAPIVersionRe matches the optional version prefix of a Docker API path.
+var dockerAPIVersionRe = regexp.MustCompile(`^v[0-9]+\.[0-9]+$`)
+
+// NewAuditHandler returns a new instance of AuditHandler.
+func NewAuditHandler(bouncer *security.RequestBouncer, rateLimiter *security.RateLimiter) *AuditHandler {
+	h := &AuditHandler{
+		Router:      mux.NewRouter(),
+		Logger:      log.New(os.Stderr, "", log.LstdFlags),
+		rateLimiter: rateLimiter,
+	}
+	h.Handle("/audit",
+		bouncer.AdministratorAccess(http.HandlerFunc(h.handleGetAudit))).Methods(http.MethodGet)
+	h.Handle("/audit/export",
+		bouncer.AdministratorAccess(http.HandlerFunc(h.handleGetAuditExport))).Methods(http.MethodGet)
+
+	return h
+}
+
+// auditResponseWriter records the status code of the response sent to the client.
+type auditResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (w *auditResponseWriter) WriteHeader(code int) {
+	if w.statusCode == 0 {
+		w.statusCode = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *auditResponseWriter) Write(data []byte) (int, error) {
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// Flush implements http.Flusher, it is required by the streaming Docker operations.
+func (w *auditResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker, it is required by the Docker attach and exec operations.
+func (w *auditResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusSwitchingProtocols
+	}
+	return hijacker.Hijack()
+}
+
+// auditedRequest returns true if the request must be recorded in the audit log. The read-only
+// requests are not recorded, except the connection upgrades used by the exec and attach operations
+// (e.g. the websocket exec or the Kubernetes exec and attach requests).
+func auditedRequest(r *http.Request) bool {
+	if !strings.HasPrefix(r.URL.Path, "/api/") {
+		return false
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return r.Header.Get("Upgrade") != ""
+	}
+	return true
+}
+
+// Audit records the outcome of the request in the audit log once it has been served.
+// The request body is never read, so credentials sent to the API are not recorded.
+func (handler *AuditHandler) Audit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entry := &chainid.AuditLogEntry{
+			Timestamp: time.Now().Unix(),
+			Method:    r.Method,
+			Path:      r.URL.Path,
+			ClientIP:  handler.rateLimiter.ClientIP(r),
+		}
+		entry.EndpointID, entry.ResourceID = auditRequestTarget(r.URL)
+
+		tokenData := handler.requestTokenData(r)
+		if tokenData != nil {
+			entry.UserID = tokenData.ID
+			entry.Username = tokenData.Username
+		}
+
+		rw := &auditResponseWriter{ResponseWriter: w}
+		next.ServeHTTP(rw, r)
+
+		entry.StatusCode = rw.statusCode
+		if entry.StatusCode == 0 {
+			entry.StatusCode = http.StatusOK
+		}
+		entry.Success = entry.StatusCode < http.StatusBadRequest
+
+		err := handler.AuditLogService.CreateAuditLogEntry(entry)
+		if err != nil {
+			handler.Logger.Printf("unable to record audit log entry for %s %s: %s", entry.Method, entry.Path, err)
+		}
+	})
+}
+
+// requestTokenData returns the data of the token associated to the request or nil
+// if the request is not authenticated.
+func (handler *AuditHandler) requestTokenData(r *http.Request) *chainid.TokenData {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		return nil
+	}
+
+	tokenData, err := handler.JWTService.ParseAndVerifyToken(token)
+	if err != nil {
+		return nil
+	}
+	return tokenData
+}
+
+// auditRequestTarget extracts the endpoint identifier and the identifier of the targeted resource
+// from the URL of an API request, e.g. /api/endpoints/1/docker/containers/{id}/stop. They are read
+// from the query parameters of the websocket requests, e.g. /api/websocket/exec?endpointId=1&id={id}.
+func auditRequestTarget(requestURL *url.URL) (chainid.EndpointID, string) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(requestURL.Path, "/api/"), "/"), "/")
+	if len(parts) < 2 {
+		return 0, ""
+	}
+
+	if parts[0] == "websocket" {
+		query := requestURL.Query()
+		id, _ := strconv.Atoi(query.Get("endpointId"))
+		return chainid.EndpointID(id), query.Get("id")
+	}
+
+	id, err := strconv.Atoi(parts[1])
+	if parts[0] != "endpoints" || err != nil {
+		return 0, parts[1]
+	}
+	endpointID := chainid.EndpointID(id)
+
+	if len(parts) == 2 {
+		return endpointID, parts[1]
+	} else if parts[2] == "kubernetes" {
+		return endpointID, kubernetesResourceName(parts[3:])
+	} else if parts[2] != "docker" {
+		if len(parts) > 3 {
+			return endpointID, parts[3]
+		}
+		return endpointID, ""
+	}
+
+	dockerParts := parts[3:]
+	if len(dockerParts) > 0 && dockerAPIVersionRe.MatchString(dockerParts[0]) {
+		dockerParts = dockerParts[1:]
+	}
+
+	if len(dockerParts) < 2 {
+		return endpointID, ""
+	}
+
+	switch dockerParts[1] {
+	case "create", "prune", "json":
+		return endpointID, ""
+	}
+	return endpointID, dockerParts[1]
+}
+
+// kubernetesResourceName returns the name of the resource targeted by a Kubernetes API path,
+// e.g. api/v1/namespaces/{namespace}/pods/{name}/exec or apis/apps/v1/namespaces/{name}.
+func kubernetesResourceName(parts []string) string {
+	// The version prefix is api/{version} for the core group and apis/{group}/{version} for the other groups.
+	versionLength := 2
+	if len(parts) > 0 && parts[0] == "apis" {
+		versionLength = 3
+	}
+	if len(parts) < versionLength {
+		return ""
+	}
+	parts = parts[versionLength:]
+
+	if len(parts) > 3 && parts[0] == "namespaces" {
+		parts = parts[2:]
+	}
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[1]
+}
+
+// handleGetAudit handles GET requests on /audit?userId=<userId>&endpointId=<endpointId>&method=<method>&resourceId=<resourceId>&from=<from>&to=<to>&limit=<limit>
+func (handler *AuditHandler) handleGetAudit(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditLogFilter(r)
+	if err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidQueryFormat, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	entries, err := handler.AuditLogService.AuditLogEntries(filter)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	encodeJSON(w, entries, handler.Logger)
+}
+
+// handleGetAuditExport handles GET requests on /audit/export
+// It accepts the same filters as /audit and returns the entries as JSON lines.
+func (handler *AuditHandler) handleGetAuditExport(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditLogFilter(r)
+	if err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidQueryFormat, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	entries, err := handler.AuditLogService.AuditLogEntries(filter)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", "attachment; filename=audit.jsonl")
+
+	encoder := json.NewEncoder(w)
+	for i := range entries {
+		err = encoder.Encode(&entries[i])
+		if err != nil {
+			handler.Logger.Printf("unable to export audit log entry: %s", err)
+			return
+		}
+	}
+}
+
+func parseAuditLogFilter(r *http.Request) (*chainid.AuditLogFilter, error) {
+	query := r.URL.Query()
+	filter := &chainid.AuditLogFilter{
+		Method:     strings.ToUpper(query.Get("method")),
+		ResourceID: query.Get("resourceId"),
+	}
+
+	userID, err := parseInt64QueryParameter(query.Get("userId"))
+	if err != nil {
+		return nil, err
+	}
+	filter.UserID = chainid.UserID(userID)
+
+	endpointID, err := parseInt64QueryParameter(query.Get("endpointId"))
+	if err != nil {
+		return nil, err
+	}
+	filter.EndpointID = chainid.EndpointID(endpointID)
+
+	limit, err := parseInt64QueryParameter(query.Get("limit"))
+	if err != nil {
+		return nil, err
+	}
+	filter.Limit = int(limit)
+
+	filter.From, err = parseInt64QueryParameter(query.Get("from"))
+	if err != nil {
+		return nil, err
+	}
+
+	filter.To, err = parseInt64QueryParameter(query.Get("to"))
+	if err != nil {
+		return nil, err
+	}
+
+	return filter, nil
+}
+
+// parseInt64QueryParameter parses an optional integer query parameter, it returns 0 when the parameter is empty.
+func parseInt64QueryParameter(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
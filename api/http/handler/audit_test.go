@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/chainid-io/dashboard"
+)
+
+func TestAuditedRequest(t *testing.T) {
+	cases := []struct {
+		method   string
+		path     string
+		upgrade  string
+		expected bool
+	}{
+		{http.MethodPost, "/api/endpoints/1/docker/containers/create", "", true},
+		{http.MethodDelete, "/api/users/2", "", true},
+		{http.MethodGet, "/api/endpoints/1/docker/containers/json", "", false},
+		{http.MethodPost, "/index.html", "", false},
+		{http.MethodGet, "/api/websocket/exec", "websocket", true},
+		{http.MethodGet, "/api/endpoints/1/kubernetes/api/v1/namespaces/default/pods/web/exec", "SPDY/3.1", true},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest(c.method, c.path, nil)
+		if c.upgrade != "" {
+			req.Header.Set("Connection", "Upgrade")
+			req.Header.Set("Upgrade", c.upgrade)
+		}
+
+		if audited := auditedRequest(req); audited != c.expected {
+			t.Errorf("expected audited to be %v for %s %s (upgrade: %q), got %v", c.expected, c.method, c.path, c.upgrade, audited)
+		}
+	}
+}
+
+func TestAuditRequestTarget(t *testing.T) {
+	cases := []struct {
+		url        string
+		endpointID chainid.EndpointID
+		resourceID string
+	}{
+		{"/api/endpoints", 0, ""},
+		{"/api/users/2", 0, "2"},
+		{"/api/endpoints/1", 1, "1"},
+		{"/api/endpoints/1/access", 1, ""},
+		{"/api/endpoints/1/docker/containers/create", 1, ""},
+		{"/api/endpoints/1/docker/containers/abc/stop", 1, "abc"},
+		{"/api/endpoints/1/docker/v1.39/containers/abc/stop", 1, "abc"},
+		{"/api/endpoints/1/docker/volumes/prune", 1, ""},
+		{"/api/endpoints/1/kubernetes/api/v1/namespaces/default/pods/web/exec", 1, "web"},
+		{"/api/endpoints/1/kubernetes/apis/apps/v1/namespaces/default/deployments/web", 1, "web"},
+		{"/api/endpoints/1/kubernetes/api/v1/namespaces/default", 1, "default"},
+		{"/api/endpoints/1/kubernetes/api/v1/nodes/node-1", 1, "node-1"},
+		{"/api/endpoints/1/kubernetes/version", 1, ""},
+		{"/api/websocket/exec?id=abc&endpointId=3", 3, "abc"},
+		{"/api/websocket/exec?id=abc", 0, "abc"},
+	}
+
+	for _, c := range cases {
+		requestURL, err := url.Parse(c.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		endpointID, resourceID := auditRequestTarget(requestURL)
+		if endpointID != c.endpointID || resourceID != c.resourceID {
+			t.Errorf("expected %s to target endpoint %d and resource %q, got %d and %q", c.url, c.endpointID, c.resourceID, endpointID, resourceID)
+		}
+	}
+}
+
+func TestParseAuditLogFilter(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/audit?userId=2&endpointId=3&method=post&resourceId=abc&from=100&to=200&limit=10", nil)
+	filter, err := parseAuditLogFilter(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := chainid.AuditLogFilter{UserID: 2, EndpointID: 3, Method: "POST", ResourceID: "abc", From: 100, To: 200, Limit: 10}
+	if *filter != expected {
+		t.Errorf("expected filter %+v, got %+v", expected, *filter)
+	}
+
+	filter, err = parseAuditLogFilter(httptest.NewRequest(http.MethodGet, "/audit", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *filter != (chainid.AuditLogFilter{}) {
+		t.Errorf("expected an empty filter, got %+v", *filter)
+	}
+
+	for _, query := range []string{"userId=admin", "endpointId=1.5", "limit=ten", "from=yesterday", "to=-"} {
+		_, err = parseAuditLogFilter(httptest.NewRequest(http.MethodGet, "/audit?"+query, nil))
+		if err == nil {
+			t.Errorf("expected the query %q to be rejected", query)
+		}
+	}
+}
@@ -1,126 +1,276 @@
-package handler
-
-import (
-	"github.com/chainid-io/dashboard"
-
-	"encoding/json"
-	"log"
-	"net/http"
-	"os"
-
-	"github.com/asaskevich/govalidator"
-	"github.com/gorilla/mux"
-	httperror "github.com/chainid-io/dashboard/http/error"
-	"github.com/chainid-io/dashboard/http/security"
-)
-
-// AuthHandler represents an HTTP API handler for managing authentication.
-type AuthHandler struct {
-	*mux.Router
-	Logger          *log.Logger
-	authDisabled    bool
-	UserService     chainid.UserService
-	CryptoService   chainid.CryptoService
-	JWTService      chainid.JWTService
-	LDAPService     chainid.LDAPService
-	SettingsService chainid.SettingsService
-}
-
-const (
-	// ErrInvalidCredentialsFormat is an error raised when credentials format is not valid
-	ErrInvalidCredentialsFormat = chainid.Error("Invalid credentials format")
-	// ErrInvalidCredentials is an error raised when credentials for a user are invalid
-	ErrInvalidCredentials = chainid.Error("Invalid credentials")
-	// ErrAuthDisabled is an error raised when trying to access the authentication endpoints
-	// when the server has been started with the --no-auth flag
-	ErrAuthDisabled = chainid.Error("Authentication is disabled")
-)
-
-// NewAuthHandler returns a new instance of AuthHandler.
-func NewAuthHandler(bouncer *security.RequestBouncer, rateLimiter *security.RateLimiter, authDisabled bool) *AuthHandler {
-	h := &AuthHandler{
-		Router:       mux.NewRouter(),
-		Logger:       log.New(os.Stderr, "", log.LstdFlags),
-		authDisabled: authDisabled,
-	}
-	h.Handle("/auth",
-		rateLimiter.LimitAccess(bouncer.PublicAccess(http.HandlerFunc(h.handlePostAuth)))).Methods(http.MethodPost)
-
-	return h
-}
-
-type (
-	postAuthRequest struct {
-		Username string `valid:"required"`
-		Password string `valid:"required"`
-	}
-
-	postAuthResponse struct {
-		JWT string `json:"jwt"`
-	}
-)
-
-func (handler *AuthHandler) handlePostAuth(w http.ResponseWriter, r *http.Request) {
-	if handler.authDisabled {
-		httperror.WriteErrorResponse(w, ErrAuthDisabled, http.StatusServiceUnavailable, handler.Logger)
-		return
-	}
-
-	var req postAuthRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httperror.WriteErrorResponse(w, ErrInvalidJSON, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	_, err := govalidator.ValidateStruct(req)
-	if err != nil {
-		httperror.WriteErrorResponse(w, ErrInvalidCredentialsFormat, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	var username = req.Username
-	var password = req.Password
-
-	u, err := handler.UserService.UserByUsername(username)
-	if err == chainid.ErrUserNotFound {
-		httperror.WriteErrorResponse(w, ErrInvalidCredentials, http.StatusBadRequest, handler.Logger)
-		return
-	} else if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-
-	settings, err := handler.SettingsService.Settings()
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-
-	if settings.AuthenticationMethod == chainid.AuthenticationLDAP && u.ID != 1 {
-		err = handler.LDAPService.AuthenticateUser(username, password, &settings.LDAPSettings)
-		if err != nil {
-			httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-			return
-		}
-	} else {
-		err = handler.CryptoService.CompareHashAndData(u.Password, password)
-		if err != nil {
-			httperror.WriteErrorResponse(w, ErrInvalidCredentials, http.StatusUnprocessableEntity, handler.Logger)
-			return
-		}
-	}
-
-	tokenData := &chainid.TokenData{
-		ID:       u.ID,
-		Username: u.Username,
-		Role:     u.Role,
-	}
-
-	token, err := handler.JWTService.GenerateToken(tokenData)
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-
-	encodeJSON(w, &postAuthResponse{JWT: token}, handler.Logger)
-}
+package handler
+
+import (
+	"github.com/chainid-io/dashboard"
+
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/gorilla/mux"
+	httperror "github.com/chainid-io/dashboard/http/error"
+	"github.com/chainid-io/dashboard/http/security"
+)
+
+// AuthHandler represents an HTTP API handler for managing authentication.
+type AuthHandler struct {
+	*mux.Router
+	Logger          *log.Logger
+	authDisabled    bool
+	rateLimiter     *security.RateLimiter
+	UserService     chainid.UserService
+	CryptoService   chainid.CryptoService
+	JWTService      chainid.JWTService
+	LDAPService     chainid.LDAPService
+	SettingsService chainid.SettingsService
+	TOTPService     chainid.TOTPService
+}
+
+const (
+	// ErrInvalidCredentialsFormat is an error raised when credentials format is not valid
+	ErrInvalidCredentialsFormat = chainid.Error("Invalid credentials format")
+	// ErrInvalidCredentials is an error raised when credentials for a user are invalid
+	ErrInvalidCredentials = chainid.Error("Invalid credentials")
+	// ErrAuthDisabled is an error raised when trying to access the authentication endpoints
+	// when the server has been started with the --no-auth flag
+	ErrAuthDisabled = chainid.Error("Authentication is disabled")
+)
+
+// NewAuthHandler returns a new instance of AuthHandler.
+func NewAuthHandler(bouncer *security.RequestBouncer, rateLimiter *security.RateLimiter, authDisabled bool) *AuthHandler {
+	h := &AuthHandler{
+		Router:       mux.NewRouter(),
+		Logger:       log.New(os.Stderr, "", log.LstdFlags),
+		authDisabled: authDisabled,
+		rateLimiter:  rateLimiter,
+	}
+	h.Handle("/auth",
+		rateLimiter.LimitAccess(bouncer.PublicAccess(http.HandlerFunc(h.handlePostAuth)))).Methods(http.MethodPost)
+
+	return h
+}
+
+type (
+	postAuthRequest struct {
+		Username     string `valid:"required"`
+		Password     string `valid:"required"`
+		TOTPCode     string `valid:"-"`
+		RecoveryCode string `valid:"-"`
+	}
+
+	postAuthResponse struct {
+		JWT          string `json:"jwt,omitempty"`
+		TOTPRequired bool   `json:"totpRequired,omitempty"`
+	}
+)
+
+func (handler *AuthHandler) handlePostAuth(w http.ResponseWriter, r *http.Request) {
+	if handler.authDisabled {
+		httperror.WriteErrorResponse(w, ErrAuthDisabled, http.StatusServiceUnavailable, handler.Logger)
+		return
+	}
+
+	var req postAuthRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidJSON, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	_, err := govalidator.ValidateStruct(req)
+	if err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidCredentialsFormat, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	var username = req.Username
+	var password = req.Password
+
+	u, err := handler.UserService.UserByUsername(username)
+	if err == chainid.ErrUserNotFound {
+		httperror.WriteErrorResponse(w, ErrInvalidCredentials, http.StatusBadRequest, handler.Logger)
+		return
+	} else if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	settings, err := handler.SettingsService.Settings()
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	if settings.AuthenticationMethod == chainid.AuthenticationLDAP && u.ID != 1 {
+		err = handler.LDAPService.AuthenticateUser(username, password, &settings.LDAPSettings)
+		if err != nil {
+			httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+			return
+		}
+	} else {
+		now := time.Now()
+		if security.IsAccountLocked(u, &settings.LockoutSettings, now) {
+			httperror.WriteErrorResponse(w, chainid.ErrAccountLocked, http.StatusForbidden, handler.Logger)
+			return
+		}
+
+		delay := security.LoginDelay(u, now)
+		if delay > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(delay/time.Second)+1))
+			httperror.WriteErrorResponse(w, chainid.ErrLoginDelayNotElapsed, http.StatusTooManyRequests, handler.Logger)
+			return
+		}
+
+		err = handler.CryptoService.CompareHashAndData(u.Password, password)
+		if err != nil {
+			handler.registerFailedLogin(u, &settings.LockoutSettings, r, now)
+			httperror.WriteErrorResponse(w, ErrInvalidCredentials, http.StatusUnprocessableEntity, handler.Logger)
+			return
+		}
+
+		var totpCode, recoveryCodeHash string
+		if u.TOTPEnabled {
+			if req.TOTPCode == "" && req.RecoveryCode == "" {
+				encodeJSON(w, &postAuthResponse{TOTPRequired: true}, handler.Logger)
+				return
+			}
+
+			totpCode = req.TOTPCode
+			if totpCode == "" {
+				recoveryCodeHash = handler.matchRecoveryCode(u, req.RecoveryCode)
+				if recoveryCodeHash == "" {
+					handler.registerFailedLogin(u, &settings.LockoutSettings, r, now)
+					httperror.WriteErrorResponse(w, chainid.ErrInvalidTOTPCode, http.StatusUnprocessableEntity, handler.Logger)
+					return
+				}
+			}
+		}
+
+		err = handler.completeLogin(u, totpCode, recoveryCodeHash, &settings.LockoutSettings, now)
+		if err == chainid.ErrInvalidTOTPCode {
+			handler.registerFailedLogin(u, &settings.LockoutSettings, r, now)
+			httperror.WriteErrorResponse(w, chainid.ErrInvalidTOTPCode, http.StatusUnprocessableEntity, handler.Logger)
+			return
+		} else if err == chainid.ErrAccountLocked {
+			httperror.WriteErrorResponse(w, chainid.ErrAccountLocked, http.StatusForbidden, handler.Logger)
+			return
+		} else if err != nil {
+			httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+			return
+		}
+	}
+
+	tokenData := &chainid.TokenData{
+		ID:       u.ID,
+		Username: u.Username,
+		Role:     u.Role,
+	}
+
+	token, err := handler.JWTService.GenerateToken(tokenData)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	encodeJSON(w, &postAuthResponse{JWT: token}, handler.Logger)
+}
+
+// registerFailedLogin increments the failed login counter of the user and logs
+// the lockout of the account when the threshold is reached. The counter is incremented
+// inside a single update of the user so that the concurrent failed attempts are all counted.
+func (handler *AuthHandler) registerFailedLogin(user *chainid.User, settings *chainid.LockoutSettings, r *http.Request, now time.Time) {
+	var locked bool
+	var failedLogins int
+	err := handler.UserService.UpdateUserFunc(user.ID, func(current *chainid.User) error {
+		locked = security.RegisterFailedLogin(current, settings, now)
+		failedLogins = current.FailedLogins
+		return nil
+	})
+	if err != nil {
+		handler.Logger.Printf("Unable to update failed login attempts for account %s: %s", user.Username, err)
+		return
+	}
+
+	if locked {
+		handler.Logger.Printf("Account %s locked after %d failed login attempts (last attempt from %s)", user.Username, failedLogins, handler.rateLimiter.ClientIP(r))
+	}
+}
+
+// matchRecoveryCode returns the hash of the recovery code of the user matching the specified
+// code, or an empty string if the code does not match any recovery code.
+func (handler *AuthHandler) matchRecoveryCode(user *chainid.User, recoveryCode string) string {
+	for _, hash := range user.TOTPRecoveryCodes {
+		if handler.CryptoService.CompareHashAndData(hash, recoveryCode) == nil {
+			return hash
+		}
+	}
+	return ""
+}
+
+// completeLogin validates the TOTP code or removes the recovery code used to log in and resets
+// the failed login counter inside a single update of the user. The login is rejected with
+// ErrAccountLocked if the account has been locked by concurrent failed attempts, and with
+// ErrInvalidTOTPCode if the code or the recovery code has been used in the meantime.
+func (handler *AuthHandler) completeLogin(user *chainid.User, totpCode, recoveryCodeHash string, settings *chainid.LockoutSettings, now time.Time) error {
+	remainingRecoveryCodes := -1
+	err := handler.UserService.UpdateUserFunc(user.ID, func(current *chainid.User) error {
+		if security.IsAccountLocked(current, settings, now) {
+			return chainid.ErrAccountLocked
+		}
+
+		if totpCode != "" {
+			valid, err := validateUserTOTPCode(current, totpCode, handler.TOTPService)
+			if err != nil {
+				return err
+			}
+			if !valid {
+				return chainid.ErrInvalidTOTPCode
+			}
+		} else if recoveryCodeHash != "" {
+			idx := indexOf(current.TOTPRecoveryCodes, recoveryCodeHash)
+			if idx == -1 {
+				return chainid.ErrInvalidTOTPCode
+			}
+			current.TOTPRecoveryCodes = append(current.TOTPRecoveryCodes[:idx], current.TOTPRecoveryCodes[idx+1:]...)
+			remainingRecoveryCodes = len(current.TOTPRecoveryCodes)
+		}
+
+		security.ResetFailedLogins(current)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if remainingRecoveryCodes >= 0 {
+		handler.Logger.Printf("Recovery code used for user %s, %d recovery code(s) left", user.Username, remainingRecoveryCodes)
+	}
+	return nil
+}
+
+func indexOf(values []string, value string) int {
+	for idx, v := range values {
+		if v == value {
+			return idx
+		}
+	}
+	return -1
+}
+
+// validateUserTOTPCode validates the code against the TOTP secret associated to the user.
+// The time step of an accepted code is recorded on the user so that the code cannot be used again,
+// the user must then be updated by the caller.
+func validateUserTOTPCode(user *chainid.User, code string, totpService chainid.TOTPService) (bool, error) {
+	if user.TOTPSecret == "" {
+		return false, chainid.ErrTOTPNotEnrolled
+	}
+
+	timeStep, valid := totpService.ValidateCode(user.TOTPSecret, code, user.TOTPLastTimeStep)
+	if valid {
+		user.TOTPLastTimeStep = timeStep
+	}
+	return valid, nil
+}
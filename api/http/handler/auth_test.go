@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/http/security"
+)
+
+func TestPostAuthCountsConcurrentFailedLogins(t *testing.T) {
+	test := newUserHandlerTest(t, true)
+	store, user := test.store, test.user
+	err := store.SettingsService.StoreSettings(&chainid.Settings{
+		AuthenticationMethod: chainid.AuthenticationInternal,
+		LockoutSettings:      chainid.LockoutSettings{MaxFailedAttempts: 20},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bouncer := security.NewRequestBouncer(test.jwtService, store.UserService, store.TeamMembershipService, store.SettingsService, store.EndpointService, nil, false)
+	handler := NewAuthHandler(bouncer, security.NewRateLimiter(100, time.Second, time.Hour), false)
+	handler.UserService = store.UserService
+	handler.SettingsService = store.SettingsService
+	handler.CryptoService = test.handler.CryptoService
+	handler.JWTService = test.jwtService
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	failures := 0
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rr := serveTestRequest(handler, http.MethodPost, "/auth", "", `{"Username":"alice","Password":"invalid"}`)
+			if rr.Code == http.StatusUnprocessableEntity {
+				mu.Lock()
+				failures++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	stored, err := store.UserService.User(user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failures == 0 || stored.FailedLogins != failures {
+		t.Fatalf("expected %d failed login attempts to be recorded, got %d", failures, stored.FailedLogins)
+	}
+
+	err = store.UserService.UpdateUserFunc(user.ID, func(user *chainid.User) error {
+		user.LastFailedLogin = 0
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := serveTestRequest(handler, http.MethodPost, "/auth", "", `{"Username":"alice","Password":"password"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	stored, err = store.UserService.User(user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.FailedLogins != 0 {
+		t.Errorf("expected the failed login attempts to be reset, got %d", stored.FailedLogins)
+	}
+}
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+	httperror "github.com/chainid-io/dashboard/http/error"
+	"github.com/chainid-io/dashboard/http/proxy"
+	"github.com/chainid-io/dashboard/http/security"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/gorilla/mux"
+)
+
+// BackupHandler represents an HTTP API handler for backing up and restoring the application data.
+type BackupHandler struct {
+	*mux.Router
+	Logger              *log.Logger
+	BackupService       chainid.BackupService
+	FileService         chainid.FileService
+	SignatureService    chainid.DigitalSignatureService
+	EndpointService     chainid.EndpointService
+	ProxyManager        *proxy.Manager
+	SettingsService     chainid.SettingsService
+	BackupScheduler     chainid.BackupScheduler
+	BackupStatusService chainid.BackupStatusService
+}
+
+// NewBackupHandler returns a new instance of BackupHandler.
+func NewBackupHandler(bouncer *security.RequestBouncer) *BackupHandler {
+	h := &BackupHandler{
+		Router: mux.NewRouter(),
+		Logger: log.New(os.Stderr, "", log.LstdFlags),
+	}
+	h.Handle("/backup",
+		bouncer.AdministratorAccess(http.HandlerFunc(h.handlePostBackup))).Methods(http.MethodPost)
+	h.Handle("/backup/status",
+		bouncer.AdministratorAccess(http.HandlerFunc(h.handleGetBackupStatus))).Methods(http.MethodGet)
+	h.Handle("/restore",
+		bouncer.AdministratorAccess(http.HandlerFunc(h.handlePostRestore))).Methods(http.MethodPost)
+
+	return h
+}
+
+type postBackupRequest struct {
+	Password string `valid:"-"`
+}
+
+// handlePostBackup handles POST requests on /backup
+// The archive is streamed in the response, it is encrypted when a password is specified.
+func (handler *BackupHandler) handlePostBackup(w http.ResponseWriter, r *http.Request) {
+	var req postBackupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidJSON, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	_, err := govalidator.ValidateStruct(req)
+	if err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	fileName := fmt.Sprintf("chainid-backup-%s.tar.gz", time.Now().Format("20060102150405"))
+	if req.Password != "" {
+		fileName += ".enc"
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", "attachment; filename="+fileName)
+
+	err = handler.BackupService.CreateBackup(w, req.Password)
+	if err != nil {
+		// The headers might already have been sent, the error can only be logged.
+		handler.Logger.Printf("unable to create backup: %s", err)
+	}
+}
+
+// handleGetBackupStatus handles GET requests on /backup/status
+func (handler *BackupHandler) handleGetBackupStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := handler.BackupStatusService.BackupStatus()
+	if err == chainid.ErrBackupStatusNotFound {
+		status = &chainid.BackupStatus{}
+	} else if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	encodeJSON(w, status, handler.Logger)
+}
+
+// handlePostRestore handles POST requests on /restore
+// The archive must be sent as a multipart file named "file", the password of
+// an encrypted archive can be specified with the "password" form value.
+func (handler *BackupHandler) handlePostRestore(w http.ResponseWriter, r *http.Request) {
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
+		return
+	}
+	defer file.Close()
+
+	err = handler.BackupService.RestoreBackup(file, r.FormValue("password"))
+	if err == chainid.ErrInvalidBackupArchive || err == chainid.ErrBackupPasswordRequired || err == chainid.ErrDecryptionFailure {
+		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+		return
+	} else if err == chainid.ErrUnsupportedBackupVersion || err == chainid.ErrMasterKeyRequired || err == chainid.ErrInvalidMasterKey || err == chainid.ErrDataStoreOperationNotSupported {
+		httperror.WriteErrorResponse(w, err, http.StatusConflict, handler.Logger)
+		return
+	} else if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	err = handler.reloadRestoredData()
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+}
+
+// reloadRestoredData loads the keys restored from the archive, recreates the proxies
+// of the restored endpoints, evicts the proxies of the endpoints that no longer exist
+// and applies the restored backup schedule.
+func (handler *BackupHandler) reloadRestoredData() error {
+	keyPairExists, err := handler.FileService.KeyPairFilesExist()
+	if err != nil {
+		return err
+	}
+
+	if keyPairExists {
+		private, public, err := handler.FileService.LoadKeyPair()
+		if err != nil {
+			return err
+		}
+
+		err = handler.SignatureService.ParseKeyPair(private, public)
+		if err != nil {
+			return err
+		}
+	}
+
+	endpoints, err := handler.EndpointService.Endpoints()
+	if err != nil {
+		return err
+	}
+
+	restored := make(map[chainid.EndpointID]bool)
+	for i := range endpoints {
+		restored[endpoints[i].ID] = true
+		_, err = handler.ProxyManager.CreateAndRegisterProxy(&endpoints[i])
+		if err != nil {
+			handler.Logger.Printf("unable to create proxy for restored endpoint %s: %s", endpoints[i].Name, err)
+		}
+	}
+
+	for _, proxy := range handler.ProxyManager.Proxies() {
+		if !restored[proxy.EndpointID] {
+			handler.ProxyManager.EndpointDeleted(proxy.EndpointID)
+		}
+	}
+
+	settings, err := handler.SettingsService.Settings()
+	if err != nil {
+		return err
+	}
+
+	err = handler.BackupScheduler.ScheduleBackups(&settings.BackupSettings)
+	if err != nil {
+		handler.Logger.Printf("unable to schedule restored backup settings: %s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/filesystem"
+	"github.com/chainid-io/dashboard/http/proxy"
+	"github.com/chainid-io/dashboard/kv"
+)
+
+type testBackupScheduler struct{}
+
+func (testBackupScheduler) ScheduleBackups(settings *chainid.BackupSettings) error { return nil }
+
+func TestReloadRestoredDataEvictsProxies(t *testing.T) {
+	dataStorePath, err := ioutil.TempDir("", "chainid-backup-handler")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dataStorePath)
+
+	fileService, err := filesystem.NewService(dataStorePath, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := kv.NewStore(kv.NewMemoryBackend())
+	err = store.SettingsService.StoreSettings(&chainid.Settings{AuthenticationMethod: chainid.AuthenticationInternal})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored := &chainid.Endpoint{Name: "restored", Type: chainid.DockerEnvironment, URL: "tcp://127.0.0.1:2375"}
+	err = store.EndpointService.CreateEndpoint(restored)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proxyManager := proxy.NewManager(&proxy.ManagerParams{})
+	// The proxy of an endpoint created after the backup remains cached once the backup is restored.
+	removed := &chainid.Endpoint{ID: restored.ID + 1, Name: "removed", Type: chainid.DockerEnvironment, URL: "tcp://127.0.0.1:2376"}
+	_, err = proxyManager.CreateAndRegisterProxy(removed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := NewBackupHandler(nil)
+	handler.FileService = fileService
+	handler.EndpointService = store.EndpointService
+	handler.ProxyManager = proxyManager
+	handler.SettingsService = store.SettingsService
+	handler.BackupScheduler = testBackupScheduler{}
+
+	err = handler.reloadRestoredData()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proxies := proxyManager.Proxies()
+	if len(proxies) != 1 || proxies[0].EndpointID != restored.ID {
+		t.Errorf("expected only the proxy of the restored endpoint to be cached, got %+v", proxies)
+	}
+	if proxyManager.GetProxy(removed.ID) != nil {
+		t.Error("expected the proxy of the removed endpoint to be evicted")
+	}
+}
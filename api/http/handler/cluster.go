@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"github.com/chainid-io/dashboard"
+	httperror "github.com/chainid-io/dashboard/http/error"
+	"github.com/chainid-io/dashboard/http/security"
+
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/gorilla/mux"
+)
+
+// ClusterHandler represents an HTTP API handler for inspecting the instances sharing the datastore.
+type ClusterHandler struct {
+	*mux.Router
+	Logger         *log.Logger
+	ClusterService chainid.ClusterService
+}
+
+// NewClusterHandler returns a new instance of ClusterHandler.
+func NewClusterHandler(bouncer *security.RequestBouncer) *ClusterHandler {
+	h := &ClusterHandler{
+		Router: mux.NewRouter(),
+		Logger: log.New(os.Stderr, "", log.LstdFlags),
+	}
+	h.Handle("/cluster",
+		bouncer.AdministratorAccess(http.HandlerFunc(h.handleGetCluster))).Methods(http.MethodGet)
+
+	return h
+}
+
+// handleGetCluster handles GET requests on /cluster
+func (handler *ClusterHandler) handleGetCluster(w http.ResponseWriter, r *http.Request) {
+	status, err := handler.ClusterService.Status()
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	encodeJSON(w, status, handler.Logger)
+}
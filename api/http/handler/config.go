@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/config"
+	httperror "github.com/chainid-io/dashboard/http/error"
+	"github.com/chainid-io/dashboard/http/security"
+
+	"github.com/gorilla/mux"
+)
+
+// ConfigHandler represents an HTTP API handler for applying and exporting declarative configuration documents.
+type ConfigHandler struct {
+	*mux.Router
+	Logger        *log.Logger
+	ConfigService chainid.ConfigService
+}
+
+// NewConfigHandler returns a new instance of ConfigHandler.
+func NewConfigHandler(bouncer *security.RequestBouncer) *ConfigHandler {
+	h := &ConfigHandler{
+		Router: mux.NewRouter(),
+		Logger: log.New(os.Stderr, "", log.LstdFlags),
+	}
+	h.Handle("/config/apply",
+		bouncer.AdministratorAccess(http.HandlerFunc(h.handlePostConfigApply))).Methods(http.MethodPost)
+	h.Handle("/config/export",
+		bouncer.AdministratorAccess(http.HandlerFunc(h.handleGetConfigExport))).Methods(http.MethodGet)
+
+	return h
+}
+
+type postConfigApplyResponse struct {
+	DryRun  bool                   `json:"DryRun"`
+	Changes []chainid.ConfigChange `json:"Changes"`
+}
+
+// handlePostConfigApply handles POST requests on /config/apply
+// The request body is a YAML or JSON configuration document. When the dryRun query parameter
+// is set to true, the changes are returned without being applied.
+func (handler *ConfigHandler) handlePostConfigApply(w http.ResponseWriter, r *http.Request) {
+	dryRun := r.URL.Query().Get("dryRun") == "true"
+
+	document, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	changes, err := handler.ConfigService.ApplyConfig(document, dryRun)
+	if _, ok := err.(*config.DocumentError); ok {
+		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+		return
+	} else if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	encodeJSON(w, &postConfigApplyResponse{DryRun: dryRun, Changes: changes}, handler.Logger)
+}
+
+// handleGetConfigExport handles GET requests on /config/export?format=<format>
+// The format can be either yaml (default) or json. Secrets are not exported.
+func (handler *ConfigHandler) handleGetConfigExport(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = chainid.ConfigFormatYAML
+	}
+
+	document, err := handler.ConfigService.ExportConfig(format)
+	if err == chainid.ErrInvalidConfigFormat {
+		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+		return
+	} else if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	if format == chainid.ConfigFormatJSON {
+		w.Header().Set("Content-Type", "application/json")
+	} else {
+		w.Header().Set("Content-Type", "application/x-yaml")
+	}
+	w.Header().Set("Content-Disposition", "attachment; filename=chainid-config."+format)
+	w.Write(document)
+}
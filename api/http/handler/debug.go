@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/chainid-io/dashboard/http/proxy"
+	"github.com/chainid-io/dashboard/http/security"
+
+	"github.com/gorilla/mux"
+)
+
+// DebugHandler represents an HTTP API handler for inspecting the internal state of the server.
+type DebugHandler struct {
+	*mux.Router
+	Logger       *log.Logger
+	ProxyManager *proxy.Manager
+}
+
+// NewDebugHandler returns a new instance of DebugHandler.
+func NewDebugHandler(bouncer *security.RequestBouncer) *DebugHandler {
+	h := &DebugHandler{
+		Router: mux.NewRouter(),
+		Logger: log.New(os.Stderr, "", log.LstdFlags),
+	}
+	h.Handle("/debug/proxies",
+		bouncer.AdministratorAccess(http.HandlerFunc(h.handleGetProxies))).Methods(http.MethodGet)
+
+	return h
+}
+
+// handleGetProxies handles GET requests on /debug/proxies
+func (handler *DebugHandler) handleGetProxies(w http.ResponseWriter, r *http.Request) {
+	encodeJSON(w, handler.ProxyManager.Proxies(), handler.Logger)
+}
@@ -1,612 +1,1170 @@
-package handler
-
-import (
-	"bytes"
-	"strings"
-
-	"github.com/chainid-io/dashboard"
-	"github.com/chainid-io/dashboard/crypto"
-	"github.com/chainid-io/dashboard/http/client"
-	httperror "github.com/chainid-io/dashboard/http/error"
-	"github.com/chainid-io/dashboard/http/proxy"
-	"github.com/chainid-io/dashboard/http/security"
-
-	"encoding/json"
-	"log"
-	"net/http"
-	"os"
-	"strconv"
-
-	"github.com/asaskevich/govalidator"
-	"github.com/gorilla/mux"
-)
-
-// EndpointHandler represents an HTTP API handler for managing Docker endpoints.
-type EndpointHandler struct {
-	*mux.Router
-	Logger                      *log.Logger
-	authorizeEndpointManagement bool
-	EndpointService             chainid.EndpointService
-	EndpointGroupService        chainid.EndpointGroupService
-	FileService                 chainid.FileService
-	ProxyManager                *proxy.Manager
-}
-
-const (
-	// ErrEndpointManagementDisabled is an error raised when trying to access the endpoints management endpoints
-	// when the server has been started with the --external-endpoints flag
-	ErrEndpointManagementDisabled = chainid.Error("Endpoint management is disabled")
-)
-
-// NewEndpointHandler returns a new instance of EndpointHandler.
-func NewEndpointHandler(bouncer *security.RequestBouncer, authorizeEndpointManagement bool) *EndpointHandler {
-	h := &EndpointHandler{
-		Router: mux.NewRouter(),
-		Logger: log.New(os.Stderr, "", log.LstdFlags),
-		authorizeEndpointManagement: authorizeEndpointManagement,
-	}
-	h.Handle("/endpoints",
-		bouncer.AdministratorAccess(http.HandlerFunc(h.handlePostEndpoints))).Methods(http.MethodPost)
-	h.Handle("/endpoints",
-		bouncer.RestrictedAccess(http.HandlerFunc(h.handleGetEndpoints))).Methods(http.MethodGet)
-	h.Handle("/endpoints/{id}",
-		bouncer.AdministratorAccess(http.HandlerFunc(h.handleGetEndpoint))).Methods(http.MethodGet)
-	h.Handle("/endpoints/{id}",
-		bouncer.AdministratorAccess(http.HandlerFunc(h.handlePutEndpoint))).Methods(http.MethodPut)
-	h.Handle("/endpoints/{id}/access",
-		bouncer.AdministratorAccess(http.HandlerFunc(h.handlePutEndpointAccess))).Methods(http.MethodPut)
-	h.Handle("/endpoints/{id}",
-		bouncer.AdministratorAccess(http.HandlerFunc(h.handleDeleteEndpoint))).Methods(http.MethodDelete)
-
-	return h
-}
-
-type (
-	putEndpointAccessRequest struct {
-		AuthorizedUsers []int `valid:"-"`
-		AuthorizedTeams []int `valid:"-"`
-	}
-
-	putEndpointsRequest struct {
-		Name                string `valid:"-"`
-		URL                 string `valid:"-"`
-		PublicURL           string `valid:"-"`
-		GroupID             int    `valid:"-"`
-		TLS                 bool   `valid:"-"`
-		TLSSkipVerify       bool   `valid:"-"`
-		TLSSkipClientVerify bool   `valid:"-"`
-	}
-
-	postEndpointPayload struct {
-		name                      string
-		url                       string
-		endpointType              int
-		publicURL                 string
-		groupID                   int
-		useTLS                    bool
-		skipTLSServerVerification bool
-		skipTLSClientVerification bool
-		caCert                    []byte
-		cert                      []byte
-		key                       []byte
-		azureApplicationID        string
-		azureTenantID             string
-		azureAuthenticationKey    string
-	}
-)
-
-// handleGetEndpoints handles GET requests on /endpoints
-func (handler *EndpointHandler) handleGetEndpoints(w http.ResponseWriter, r *http.Request) {
-	securityContext, err := security.RetrieveRestrictedRequestContext(r)
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-
-	endpoints, err := handler.EndpointService.Endpoints()
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-
-	groups, err := handler.EndpointGroupService.EndpointGroups()
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-
-	filteredEndpoints, err := security.FilterEndpoints(endpoints, groups, securityContext)
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-
-	for i := range filteredEndpoints {
-		filteredEndpoints[i].AzureCredentials = chainid.AzureCredentials{}
-	}
-
-	encodeJSON(w, filteredEndpoints, handler.Logger)
-}
-
-func (handler *EndpointHandler) createAzureEndpoint(payload *postEndpointPayload) (*chainid.Endpoint, error) {
-	credentials := chainid.AzureCredentials{
-		ApplicationID:     payload.azureApplicationID,
-		TenantID:          payload.azureTenantID,
-		AuthenticationKey: payload.azureAuthenticationKey,
-	}
-
-	httpClient := client.NewHTTPClient()
-	_, err := httpClient.ExecuteAzureAuthenticationRequest(&credentials)
-	if err != nil {
-		return nil, err
-	}
-
-	endpoint := &chainid.Endpoint{
-		Name:             payload.name,
-		URL:              payload.url,
-		Type:             chainid.AzureEnvironment,
-		GroupID:          chainid.EndpointGroupID(payload.groupID),
-		PublicURL:        payload.publicURL,
-		AuthorizedUsers:  []chainid.UserID{},
-		AuthorizedTeams:  []chainid.TeamID{},
-		Extensions:       []chainid.EndpointExtension{},
-		AzureCredentials: credentials,
-	}
-
-	err = handler.EndpointService.CreateEndpoint(endpoint)
-	if err != nil {
-		return nil, err
-	}
-
-	return endpoint, nil
-}
-
-func (handler *EndpointHandler) createTLSSecuredEndpoint(payload *postEndpointPayload) (*chainid.Endpoint, error) {
-	tlsConfig, err := crypto.CreateTLSConfigurationFromBytes(payload.caCert, payload.cert, payload.key, payload.skipTLSClientVerification, payload.skipTLSServerVerification)
-	if err != nil {
-		return nil, err
-	}
-
-	agentOnDockerEnvironment, err := client.ExecutePingOperation(payload.url, tlsConfig)
-	if err != nil {
-		return nil, err
-	}
-
-	endpointType := chainid.DockerEnvironment
-	if agentOnDockerEnvironment {
-		endpointType = chainid.AgentOnDockerEnvironment
-	}
-
-	endpoint := &chainid.Endpoint{
-		Name:      payload.name,
-		URL:       payload.url,
-		Type:      endpointType,
-		GroupID:   chainid.EndpointGroupID(payload.groupID),
-		PublicURL: payload.publicURL,
-		TLSConfig: chainid.TLSConfiguration{
-			TLS:           payload.useTLS,
-			TLSSkipVerify: payload.skipTLSServerVerification,
-		},
-		AuthorizedUsers: []chainid.UserID{},
-		AuthorizedTeams: []chainid.TeamID{},
-		Extensions:      []chainid.EndpointExtension{},
-	}
-
-	err = handler.EndpointService.CreateEndpoint(endpoint)
-	if err != nil {
-		return nil, err
-	}
-
-	folder := strconv.Itoa(int(endpoint.ID))
-
-	if !payload.skipTLSServerVerification {
-		r := bytes.NewReader(payload.caCert)
-		// TODO: review the API exposed by the FileService to store
-		// a file from a byte slice and return the path to the stored file instead
-		// of using multiple legacy calls (StoreTLSFile, GetPathForTLSFile) here.
-		err = handler.FileService.StoreTLSFile(folder, chainid.TLSFileCA, r)
-		if err != nil {
-			handler.EndpointService.DeleteEndpoint(endpoint.ID)
-			return nil, err
-		}
-		caCertPath, _ := handler.FileService.GetPathForTLSFile(folder, chainid.TLSFileCA)
-		endpoint.TLSConfig.TLSCACertPath = caCertPath
-	}
-
-	if !payload.skipTLSClientVerification {
-		r := bytes.NewReader(payload.cert)
-		err = handler.FileService.StoreTLSFile(folder, chainid.TLSFileCert, r)
-		if err != nil {
-			handler.EndpointService.DeleteEndpoint(endpoint.ID)
-			return nil, err
-		}
-		certPath, _ := handler.FileService.GetPathForTLSFile(folder, chainid.TLSFileCert)
-		endpoint.TLSConfig.TLSCertPath = certPath
-
-		r = bytes.NewReader(payload.key)
-		err = handler.FileService.StoreTLSFile(folder, chainid.TLSFileKey, r)
-		if err != nil {
-			handler.EndpointService.DeleteEndpoint(endpoint.ID)
-			return nil, err
-		}
-		keyPath, _ := handler.FileService.GetPathForTLSFile(folder, chainid.TLSFileKey)
-		endpoint.TLSConfig.TLSKeyPath = keyPath
-	}
-
-	err = handler.EndpointService.UpdateEndpoint(endpoint.ID, endpoint)
-	if err != nil {
-		return nil, err
-	}
-
-	return endpoint, nil
-}
-
-func (handler *EndpointHandler) createUnsecuredEndpoint(payload *postEndpointPayload) (*chainid.Endpoint, error) {
-	endpointType := chainid.DockerEnvironment
-
-	if !strings.HasPrefix(payload.url, "unix://") {
-		agentOnDockerEnvironment, err := client.ExecutePingOperation(payload.url, nil)
-		if err != nil {
-			return nil, err
-		}
-		if agentOnDockerEnvironment {
-			endpointType = chainid.AgentOnDockerEnvironment
-		}
-	}
-
-	endpoint := &chainid.Endpoint{
-		Name:      payload.name,
-		URL:       payload.url,
-		Type:      endpointType,
-		GroupID:   chainid.EndpointGroupID(payload.groupID),
-		PublicURL: payload.publicURL,
-		TLSConfig: chainid.TLSConfiguration{
-			TLS: false,
-		},
-		AuthorizedUsers: []chainid.UserID{},
-		AuthorizedTeams: []chainid.TeamID{},
-		Extensions:      []chainid.EndpointExtension{},
-	}
-
-	err := handler.EndpointService.CreateEndpoint(endpoint)
-	if err != nil {
-		return nil, err
-	}
-
-	return endpoint, nil
-}
-
-func (handler *EndpointHandler) createEndpoint(payload *postEndpointPayload) (*chainid.Endpoint, error) {
-	if chainid.EndpointType(payload.endpointType) == chainid.AzureEnvironment {
-		return handler.createAzureEndpoint(payload)
-	}
-
-	if payload.useTLS {
-		return handler.createTLSSecuredEndpoint(payload)
-	}
-	return handler.createUnsecuredEndpoint(payload)
-}
-
-func convertPostEndpointRequestToPayload(r *http.Request) (*postEndpointPayload, error) {
-	payload := &postEndpointPayload{}
-	payload.name = r.FormValue("Name")
-
-	endpointType := r.FormValue("EndpointType")
-
-	if payload.name == "" || endpointType == "" {
-		return nil, ErrInvalidRequestFormat
-	}
-
-	parsedType, err := strconv.Atoi(endpointType)
-	if err != nil {
-		return nil, err
-	}
-
-	payload.url = r.FormValue("URL")
-	payload.endpointType = parsedType
-
-	if chainid.EndpointType(payload.endpointType) != chainid.AzureEnvironment && payload.url == "" {
-		return nil, ErrInvalidRequestFormat
-	}
-
-	payload.publicURL = r.FormValue("PublicURL")
-
-	if chainid.EndpointType(payload.endpointType) == chainid.AzureEnvironment {
-		payload.azureApplicationID = r.FormValue("AzureApplicationID")
-		payload.azureTenantID = r.FormValue("AzureTenantID")
-		payload.azureAuthenticationKey = r.FormValue("AzureAuthenticationKey")
-
-		if payload.azureApplicationID == "" || payload.azureTenantID == "" || payload.azureAuthenticationKey == "" {
-			return nil, ErrInvalidRequestFormat
-		}
-	}
-
-	rawGroupID := r.FormValue("GroupID")
-	if rawGroupID == "" {
-		payload.groupID = 1
-	} else {
-		groupID, err := strconv.Atoi(rawGroupID)
-		if err != nil {
-			return nil, err
-		}
-		payload.groupID = groupID
-	}
-
-	payload.useTLS = r.FormValue("TLS") == "true"
-
-	if payload.useTLS {
-		payload.skipTLSServerVerification = r.FormValue("TLSSkipVerify") == "true"
-		payload.skipTLSClientVerification = r.FormValue("TLSSkipClientVerify") == "true"
-
-		if !payload.skipTLSServerVerification {
-			caCert, err := getUploadedFileContent(r, "TLSCACertFile")
-			if err != nil {
-				return nil, err
-			}
-			payload.caCert = caCert
-		}
-
-		if !payload.skipTLSClientVerification {
-			cert, err := getUploadedFileContent(r, "TLSCertFile")
-			if err != nil {
-				return nil, err
-			}
-			payload.cert = cert
-			key, err := getUploadedFileContent(r, "TLSKeyFile")
-			if err != nil {
-				return nil, err
-			}
-			payload.key = key
-		}
-	}
-
-	return payload, nil
-}
-
-// handlePostEndpoints handles POST requests on /endpoints
-func (handler *EndpointHandler) handlePostEndpoints(w http.ResponseWriter, r *http.Request) {
-	if !handler.authorizeEndpointManagement {
-		httperror.WriteErrorResponse(w, ErrEndpointManagementDisabled, http.StatusServiceUnavailable, handler.Logger)
-		return
-	}
-
-	payload, err := convertPostEndpointRequestToPayload(r)
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	endpoint, err := handler.createEndpoint(payload)
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-
-	encodeJSON(w, &endpoint, handler.Logger)
-}
-
-// handleGetEndpoint handles GET requests on /endpoints/:id
-func (handler *EndpointHandler) handleGetEndpoint(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id := vars["id"]
-
-	endpointID, err := strconv.Atoi(id)
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	endpoint, err := handler.EndpointService.Endpoint(chainid.EndpointID(endpointID))
-	if err == chainid.ErrEndpointNotFound {
-		httperror.WriteErrorResponse(w, err, http.StatusNotFound, handler.Logger)
-		return
-	} else if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-
-	endpoint.AzureCredentials = chainid.AzureCredentials{}
-
-	encodeJSON(w, endpoint, handler.Logger)
-}
-
-// handlePutEndpointAccess handles PUT requests on /endpoints/:id/access
-func (handler *EndpointHandler) handlePutEndpointAccess(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id := vars["id"]
-
-	endpointID, err := strconv.Atoi(id)
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	var req putEndpointAccessRequest
-	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httperror.WriteErrorResponse(w, ErrInvalidJSON, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	_, err = govalidator.ValidateStruct(req)
-	if err != nil {
-		httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	endpoint, err := handler.EndpointService.Endpoint(chainid.EndpointID(endpointID))
-	if err == chainid.ErrEndpointNotFound {
-		httperror.WriteErrorResponse(w, err, http.StatusNotFound, handler.Logger)
-		return
-	} else if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-
-	if req.AuthorizedUsers != nil {
-		authorizedUserIDs := []chainid.UserID{}
-		for _, value := range req.AuthorizedUsers {
-			authorizedUserIDs = append(authorizedUserIDs, chainid.UserID(value))
-		}
-		endpoint.AuthorizedUsers = authorizedUserIDs
-	}
-
-	if req.AuthorizedTeams != nil {
-		authorizedTeamIDs := []chainid.TeamID{}
-		for _, value := range req.AuthorizedTeams {
-			authorizedTeamIDs = append(authorizedTeamIDs, chainid.TeamID(value))
-		}
-		endpoint.AuthorizedTeams = authorizedTeamIDs
-	}
-
-	err = handler.EndpointService.UpdateEndpoint(endpoint.ID, endpoint)
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-}
-
-// handlePutEndpoint handles PUT requests on /endpoints/:id
-func (handler *EndpointHandler) handlePutEndpoint(w http.ResponseWriter, r *http.Request) {
-	if !handler.authorizeEndpointManagement {
-		httperror.WriteErrorResponse(w, ErrEndpointManagementDisabled, http.StatusServiceUnavailable, handler.Logger)
-		return
-	}
-
-	vars := mux.Vars(r)
-	id := vars["id"]
-
-	endpointID, err := strconv.Atoi(id)
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	var req putEndpointsRequest
-	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httperror.WriteErrorResponse(w, ErrInvalidJSON, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	_, err = govalidator.ValidateStruct(req)
-	if err != nil {
-		httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	endpoint, err := handler.EndpointService.Endpoint(chainid.EndpointID(endpointID))
-	if err == chainid.ErrEndpointNotFound {
-		httperror.WriteErrorResponse(w, err, http.StatusNotFound, handler.Logger)
-		return
-	} else if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-
-	if req.Name != "" {
-		endpoint.Name = req.Name
-	}
-
-	if req.URL != "" {
-		endpoint.URL = req.URL
-	}
-
-	if req.PublicURL != "" {
-		endpoint.PublicURL = req.PublicURL
-	}
-
-	if req.GroupID != 0 {
-		endpoint.GroupID = chainid.EndpointGroupID(req.GroupID)
-	}
-
-	folder := strconv.Itoa(int(endpoint.ID))
-	if req.TLS {
-		endpoint.TLSConfig.TLS = true
-		endpoint.TLSConfig.TLSSkipVerify = req.TLSSkipVerify
-		if !req.TLSSkipVerify {
-			caCertPath, _ := handler.FileService.GetPathForTLSFile(folder, chainid.TLSFileCA)
-			endpoint.TLSConfig.TLSCACertPath = caCertPath
-		} else {
-			endpoint.TLSConfig.TLSCACertPath = ""
-			handler.FileService.DeleteTLSFile(folder, chainid.TLSFileCA)
-		}
-
-		if !req.TLSSkipClientVerify {
-			certPath, _ := handler.FileService.GetPathForTLSFile(folder, chainid.TLSFileCert)
-			endpoint.TLSConfig.TLSCertPath = certPath
-			keyPath, _ := handler.FileService.GetPathForTLSFile(folder, chainid.TLSFileKey)
-			endpoint.TLSConfig.TLSKeyPath = keyPath
-		} else {
-			endpoint.TLSConfig.TLSCertPath = ""
-			handler.FileService.DeleteTLSFile(folder, chainid.TLSFileCert)
-			endpoint.TLSConfig.TLSKeyPath = ""
-			handler.FileService.DeleteTLSFile(folder, chainid.TLSFileKey)
-		}
-	} else {
-		endpoint.TLSConfig.TLS = false
-		endpoint.TLSConfig.TLSSkipVerify = false
-		endpoint.TLSConfig.TLSCACertPath = ""
-		endpoint.TLSConfig.TLSCertPath = ""
-		endpoint.TLSConfig.TLSKeyPath = ""
-		err = handler.FileService.DeleteTLSFiles(folder)
-		if err != nil {
-			httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-			return
-		}
-	}
-
-	_, err = handler.ProxyManager.CreateAndRegisterProxy(endpoint)
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-
-	err = handler.EndpointService.UpdateEndpoint(endpoint.ID, endpoint)
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-}
-
-// handleDeleteEndpoint handles DELETE requests on /endpoints/:id
-func (handler *EndpointHandler) handleDeleteEndpoint(w http.ResponseWriter, r *http.Request) {
-	if !handler.authorizeEndpointManagement {
-		httperror.WriteErrorResponse(w, ErrEndpointManagementDisabled, http.StatusServiceUnavailable, handler.Logger)
-		return
-	}
-
-	vars := mux.Vars(r)
-	id := vars["id"]
-
-	endpointID, err := strconv.Atoi(id)
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	endpoint, err := handler.EndpointService.Endpoint(chainid.EndpointID(endpointID))
-
-	if err == chainid.ErrEndpointNotFound {
-		httperror.WriteErrorResponse(w, err, http.StatusNotFound, handler.Logger)
-		return
-	} else if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-
-	handler.ProxyManager.DeleteProxy(string(endpointID))
-	handler.ProxyManager.DeleteExtensionProxies(string(endpointID))
-
-	err = handler.EndpointService.DeleteEndpoint(chainid.EndpointID(endpointID))
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-
-	if endpoint.TLSConfig.TLS {
-		err = handler.FileService.DeleteTLSFiles(id)
-		if err != nil {
-			httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-			return
-		}
-	}
-}
+package handler
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/crypto"
+	"github.com/chainid-io/dashboard/http/client"
+	httperror "github.com/chainid-io/dashboard/http/error"
+	"github.com/chainid-io/dashboard/http/proxy"
+	"github.com/chainid-io/dashboard/http/security"
+	"github.com/chainid-io/dashboard/kubernetes"
+	"github.com/chainid-io/dashboard/ssh"
+	"github.com/chainid-io/dashboard/tunnel"
+
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/gorilla/mux"
+)
+
+// EndpointHandler represents an HTTP API handler for managing Docker endpoints.
+type EndpointHandler struct {
+	*mux.Router
+	Logger                      *log.Logger
+	authorizeEndpointManagement bool
+	EndpointService             chainid.EndpointService
+	EndpointGroupService        chainid.EndpointGroupService
+	FileService                 chainid.FileService
+	ProxyManager                *proxy.Manager
+	TunnelService               *tunnel.Service
+	Authorizer                  *security.Authorizer
+}
+
+const (
+	// ErrEndpointManagementDisabled is an error raised when trying to access the endpoints management endpoints
+	// when the server has been started with the --external-endpoints flag
+	ErrEndpointManagementDisabled = chainid.Error("Endpoint management is disabled")
+)
+
+// NewEndpointHandler returns a new instance of EndpointHandler.
+func NewEndpointHandler(bouncer *security.RequestBouncer, authorizeEndpointManagement bool) *EndpointHandler {
+	h := &EndpointHandler{
+		Router: mux.NewRouter(),
+		Logger: log.New(os.Stderr, "", log.LstdFlags),
+		authorizeEndpointManagement: authorizeEndpointManagement,
+	}
+	h.Handle("/endpoints",
+		bouncer.PermissionAccess(http.HandlerFunc(h.handlePostEndpoints), chainid.EndpointCreatePermission)).Methods(http.MethodPost)
+	h.Handle("/endpoints",
+		bouncer.RestrictedAccess(http.HandlerFunc(h.handleGetEndpoints))).Methods(http.MethodGet)
+	h.Handle("/endpoints/{id}",
+		bouncer.EndpointPermissionAccess(http.HandlerFunc(h.handleGetEndpoint), chainid.EndpointUpdatePermission)).Methods(http.MethodGet)
+	h.Handle("/endpoints/{id}",
+		bouncer.EndpointPermissionAccess(http.HandlerFunc(h.handlePutEndpoint), chainid.EndpointUpdatePermission)).Methods(http.MethodPut)
+	h.Handle("/endpoints/{id}/status",
+		bouncer.RestrictedAccess(http.HandlerFunc(h.handleGetEndpointStatus))).Methods(http.MethodGet)
+	h.Handle("/endpoints/{id}/tunnel/token",
+		bouncer.EndpointPermissionAccess(http.HandlerFunc(h.handlePostEndpointTunnelToken), chainid.EndpointUpdatePermission)).Methods(http.MethodPost)
+	h.Handle("/endpoints/{id}/access",
+		bouncer.EndpointPermissionAccess(http.HandlerFunc(h.handlePutEndpointAccess), chainid.EndpointUpdatePermission)).Methods(http.MethodPut)
+	h.Handle("/endpoints/{id}/namespaces",
+		bouncer.EndpointPermissionAccess(http.HandlerFunc(h.handlePutEndpointNamespaces), chainid.EndpointUpdatePermission)).Methods(http.MethodPut)
+	h.Handle("/endpoints/{id}",
+		bouncer.EndpointPermissionAccess(http.HandlerFunc(h.handleDeleteEndpoint), chainid.EndpointDeletePermission)).Methods(http.MethodDelete)
+
+	return h
+}
+
+type (
+	putEndpointAccessRequest struct {
+		AuthorizedUsers []int `valid:"-"`
+		AuthorizedTeams []int `valid:"-"`
+	}
+
+	putEndpointNamespacesRequest struct {
+		Namespaces []putEndpointNamespaceAccess `valid:"-"`
+	}
+
+	putEndpointNamespaceAccess struct {
+		Namespace       string `valid:"required"`
+		AuthorizedUsers []int  `valid:"-"`
+		AuthorizedTeams []int  `valid:"-"`
+	}
+
+	getEndpointStatusResponse struct {
+		Status         chainid.EndpointStatus        `json:"Status"`
+		Latency        int64                         `json:"Latency"`
+		DockerVersion  string                        `json:"DockerVersion"`
+		History        []chainid.EndpointStatusCheck `json:"History"`
+		Tunnel         *chainid.TunnelStatus         `json:"Tunnel,omitempty"`
+		CircuitBreaker *chainid.CircuitBreakerStatus `json:"CircuitBreaker,omitempty"`
+	}
+
+	postEndpointTunnelTokenResponse struct {
+		JoinToken string `json:"JoinToken"`
+	}
+
+	putEndpointsRequest struct {
+		Name                string `valid:"-"`
+		URL                 string `valid:"-"`
+		PublicURL           string `valid:"-"`
+		GroupID             int    `valid:"-"`
+		TLS                 bool   `valid:"-"`
+		TLSSkipVerify       bool   `valid:"-"`
+		TLSSkipClientVerify bool   `valid:"-"`
+		// TransportSettings is left untouched when omitted.
+		TransportSettings *chainid.EndpointTransportSettings `valid:"-"`
+	}
+
+	postEndpointPayload struct {
+		name                      string
+		url                       string
+		endpointType              int
+		publicURL                 string
+		groupID                   int
+		useTLS                    bool
+		skipTLSServerVerification bool
+		skipTLSClientVerification bool
+		caCert                    []byte
+		cert                      []byte
+		key                       []byte
+		azureApplicationID        string
+		azureTenantID             string
+		azureAuthenticationKey    string
+		kubeconfig                []byte
+		kubernetesToken           string
+		sshKey                    []byte
+		sshKnownHosts             []byte
+		skipSSHVerification       bool
+	}
+)
+
+// handleGetEndpoints handles GET requests on /endpoints
+func (handler *EndpointHandler) handleGetEndpoints(w http.ResponseWriter, r *http.Request) {
+	securityContext, err := security.RetrieveRestrictedRequestContext(r)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	endpoints, err := handler.EndpointService.Endpoints()
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	groups, err := handler.EndpointGroupService.EndpointGroups()
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	filteredEndpoints, err := security.FilterEndpoints(endpoints, groups, securityContext)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	for i := range filteredEndpoints {
+		filteredEndpoints[i].AzureCredentials = chainid.AzureCredentials{}
+		filteredEndpoints[i].KubernetesCredentials = chainid.KubernetesCredentials{}
+		filteredEndpoints[i].EdgeJoinToken = ""
+		hideSnapshotResources(&filteredEndpoints[i])
+	}
+
+	encodeJSON(w, filteredEndpoints, handler.Logger)
+}
+
+func (handler *EndpointHandler) createAzureEndpoint(payload *postEndpointPayload) (*chainid.Endpoint, error) {
+	credentials := chainid.AzureCredentials{
+		ApplicationID:     payload.azureApplicationID,
+		TenantID:          payload.azureTenantID,
+		AuthenticationKey: payload.azureAuthenticationKey,
+	}
+
+	httpClient := client.NewHTTPClient()
+	_, err := httpClient.ExecuteAzureAuthenticationRequest(&credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := &chainid.Endpoint{
+		Name:             payload.name,
+		URL:              payload.url,
+		Type:             chainid.AzureEnvironment,
+		GroupID:          chainid.EndpointGroupID(payload.groupID),
+		PublicURL:        payload.publicURL,
+		AuthorizedUsers:  []chainid.UserID{},
+		AuthorizedTeams:  []chainid.TeamID{},
+		Extensions:       []chainid.EndpointExtension{},
+		AzureCredentials: credentials,
+	}
+
+	err = handler.EndpointService.CreateEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return endpoint, nil
+}
+
+func (handler *EndpointHandler) createEdgeAgentEndpoint(payload *postEndpointPayload) (*chainid.Endpoint, error) {
+	joinToken, err := tunnel.GenerateJoinToken()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := &chainid.Endpoint{
+		Name:            payload.name,
+		Type:            chainid.EdgeAgentEnvironment,
+		GroupID:         chainid.EndpointGroupID(payload.groupID),
+		PublicURL:       payload.publicURL,
+		AuthorizedUsers: []chainid.UserID{},
+		AuthorizedTeams: []chainid.TeamID{},
+		Extensions:      []chainid.EndpointExtension{},
+		EdgeJoinToken:   joinToken,
+	}
+
+	err = handler.EndpointService.CreateEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return endpoint, nil
+}
+
+func (handler *EndpointHandler) createKubernetesEndpoint(payload *postEndpointPayload) (*chainid.Endpoint, error) {
+	serverURL := payload.url
+	token := payload.kubernetesToken
+	caCert, cert, key := payload.caCert, payload.cert, payload.key
+	skipTLSServerVerification := payload.skipTLSServerVerification
+
+	if payload.kubeconfig != nil {
+		kubeconfig, err := kubernetes.ParseKubeconfig(payload.kubeconfig)
+		if err != nil {
+			return nil, err
+		}
+		serverURL = kubeconfig.Server
+		token = kubeconfig.Token
+		caCert, cert, key = kubeconfig.CertificateAuthority, kubeconfig.ClientCertificate, kubeconfig.ClientKey
+		skipTLSServerVerification = kubeconfig.InsecureSkipTLSVerify
+	}
+
+	tlsConfig, err := crypto.CreateTLSConfigurationFromBytes(caCert, cert, key, len(cert) == 0, skipTLSServerVerification)
+	if err != nil {
+		return nil, err
+	}
+	if len(caCert) == 0 {
+		// The certificate of the API server is verified with the system certificates.
+		tlsConfig.RootCAs = nil
+	}
+
+	_, err = client.ExecuteKubernetesVersionOperation(serverURL, token, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := &chainid.Endpoint{
+		Name:      payload.name,
+		URL:       serverURL,
+		Type:      chainid.KubernetesEnvironment,
+		GroupID:   chainid.EndpointGroupID(payload.groupID),
+		PublicURL: payload.publicURL,
+		TLSConfig: chainid.TLSConfiguration{
+			TLS:           strings.HasPrefix(serverURL, "https://"),
+			TLSSkipVerify: skipTLSServerVerification,
+		},
+		AuthorizedUsers:       []chainid.UserID{},
+		AuthorizedTeams:       []chainid.TeamID{},
+		Extensions:            []chainid.EndpointExtension{},
+		KubernetesCredentials: chainid.KubernetesCredentials{Token: token},
+		KubernetesNamespaces:  []chainid.KubernetesNamespaceAccess{},
+	}
+
+	err = handler.EndpointService.CreateEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	folder := strconv.Itoa(int(endpoint.ID))
+	files := []struct {
+		fileType chainid.TLSFileType
+		content  []byte
+		path     *string
+	}{
+		{chainid.TLSFileCA, caCert, &endpoint.TLSConfig.TLSCACertPath},
+		{chainid.TLSFileCert, cert, &endpoint.TLSConfig.TLSCertPath},
+		{chainid.TLSFileKey, key, &endpoint.TLSConfig.TLSKeyPath},
+	}
+	for _, file := range files {
+		if len(file.content) == 0 || (file.fileType == chainid.TLSFileCA && skipTLSServerVerification) {
+			continue
+		}
+
+		err = handler.FileService.StoreTLSFile(folder, file.fileType, bytes.NewReader(file.content))
+		if err != nil {
+			handler.EndpointService.DeleteEndpoint(endpoint.ID)
+			return nil, err
+		}
+		*file.path, _ = handler.FileService.GetPathForTLSFile(folder, file.fileType)
+	}
+
+	err = handler.EndpointService.UpdateEndpoint(endpoint.ID, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return endpoint, nil
+}
+
+func (handler *EndpointHandler) createSSHEndpoint(payload *postEndpointPayload) (*chainid.Endpoint, error) {
+	dialer, err := ssh.NewDialerFromContent(payload.url, payload.sshKey, payload.sshKnownHosts, payload.skipSSHVerification)
+	if err != nil {
+		return nil, err
+	}
+	defer dialer.Close()
+
+	_, err = client.ExecuteSSHPingOperation(dialer.Dial)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := &chainid.Endpoint{
+		Name:      payload.name,
+		URL:       payload.url,
+		Type:      chainid.DockerEnvironment,
+		GroupID:   chainid.EndpointGroupID(payload.groupID),
+		PublicURL: payload.publicURL,
+		SSHConfig: chainid.SSHConfiguration{
+			SSHSkipVerify: payload.skipSSHVerification,
+		},
+		AuthorizedUsers: []chainid.UserID{},
+		AuthorizedTeams: []chainid.TeamID{},
+		Extensions:      []chainid.EndpointExtension{},
+	}
+
+	err = handler.EndpointService.CreateEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	folder := strconv.Itoa(int(endpoint.ID))
+	files := []struct {
+		fileType chainid.SSHFileType
+		content  []byte
+		path     *string
+	}{
+		{chainid.SSHFileKey, payload.sshKey, &endpoint.SSHConfig.SSHKeyPath},
+		{chainid.SSHFileKnownHosts, payload.sshKnownHosts, &endpoint.SSHConfig.SSHKnownHostsPath},
+	}
+	for _, file := range files {
+		if len(file.content) == 0 {
+			continue
+		}
+
+		err = handler.FileService.StoreSSHFile(folder, file.fileType, bytes.NewReader(file.content))
+		if err != nil {
+			handler.EndpointService.DeleteEndpoint(endpoint.ID)
+			return nil, err
+		}
+		*file.path, _ = handler.FileService.GetPathForSSHFile(folder, file.fileType)
+	}
+
+	err = handler.EndpointService.UpdateEndpoint(endpoint.ID, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return endpoint, nil
+}
+
+func (handler *EndpointHandler) createTLSSecuredEndpoint(payload *postEndpointPayload) (*chainid.Endpoint, error) {
+	tlsConfig, err := crypto.CreateTLSConfigurationFromBytes(payload.caCert, payload.cert, payload.key, payload.skipTLSClientVerification, payload.skipTLSServerVerification)
+	if err != nil {
+		return nil, err
+	}
+
+	agentOnDockerEnvironment, err := client.ExecutePingOperation(payload.url, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	endpointType := chainid.DockerEnvironment
+	if agentOnDockerEnvironment {
+		endpointType = chainid.AgentOnDockerEnvironment
+	}
+
+	endpoint := &chainid.Endpoint{
+		Name:      payload.name,
+		URL:       payload.url,
+		Type:      endpointType,
+		GroupID:   chainid.EndpointGroupID(payload.groupID),
+		PublicURL: payload.publicURL,
+		TLSConfig: chainid.TLSConfiguration{
+			TLS:           payload.useTLS,
+			TLSSkipVerify: payload.skipTLSServerVerification,
+		},
+		AuthorizedUsers: []chainid.UserID{},
+		AuthorizedTeams: []chainid.TeamID{},
+		Extensions:      []chainid.EndpointExtension{},
+	}
+
+	err = handler.EndpointService.CreateEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	folder := strconv.Itoa(int(endpoint.ID))
+
+	if !payload.skipTLSServerVerification {
+		r := bytes.NewReader(payload.caCert)
+		// TODO: review the API exposed by the FileService to store
+		// a file from a byte slice and return the path to the stored file instead
+		// of using multiple legacy calls (StoreTLSFile, GetPathForTLSFile) here.
+		err = handler.FileService.StoreTLSFile(folder, chainid.TLSFileCA, r)
+		if err != nil {
+			handler.EndpointService.DeleteEndpoint(endpoint.ID)
+			return nil, err
+		}
+		caCertPath, _ := handler.FileService.GetPathForTLSFile(folder, chainid.TLSFileCA)
+		endpoint.TLSConfig.TLSCACertPath = caCertPath
+	}
+
+	if !payload.skipTLSClientVerification {
+		r := bytes.NewReader(payload.cert)
+		err = handler.FileService.StoreTLSFile(folder, chainid.TLSFileCert, r)
+		if err != nil {
+			handler.EndpointService.DeleteEndpoint(endpoint.ID)
+			return nil, err
+		}
+		certPath, _ := handler.FileService.GetPathForTLSFile(folder, chainid.TLSFileCert)
+		endpoint.TLSConfig.TLSCertPath = certPath
+
+		r = bytes.NewReader(payload.key)
+		err = handler.FileService.StoreTLSFile(folder, chainid.TLSFileKey, r)
+		if err != nil {
+			handler.EndpointService.DeleteEndpoint(endpoint.ID)
+			return nil, err
+		}
+		keyPath, _ := handler.FileService.GetPathForTLSFile(folder, chainid.TLSFileKey)
+		endpoint.TLSConfig.TLSKeyPath = keyPath
+	}
+
+	err = handler.EndpointService.UpdateEndpoint(endpoint.ID, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return endpoint, nil
+}
+
+func (handler *EndpointHandler) createUnsecuredEndpoint(payload *postEndpointPayload) (*chainid.Endpoint, error) {
+	endpointType := chainid.DockerEnvironment
+
+	if !strings.HasPrefix(payload.url, "unix://") {
+		agentOnDockerEnvironment, err := client.ExecutePingOperation(payload.url, nil)
+		if err != nil {
+			return nil, err
+		}
+		if agentOnDockerEnvironment {
+			endpointType = chainid.AgentOnDockerEnvironment
+		}
+	}
+
+	endpoint := &chainid.Endpoint{
+		Name:      payload.name,
+		URL:       payload.url,
+		Type:      endpointType,
+		GroupID:   chainid.EndpointGroupID(payload.groupID),
+		PublicURL: payload.publicURL,
+		TLSConfig: chainid.TLSConfiguration{
+			TLS: false,
+		},
+		AuthorizedUsers: []chainid.UserID{},
+		AuthorizedTeams: []chainid.TeamID{},
+		Extensions:      []chainid.EndpointExtension{},
+	}
+
+	err := handler.EndpointService.CreateEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return endpoint, nil
+}
+
+func (handler *EndpointHandler) createEndpoint(payload *postEndpointPayload) (*chainid.Endpoint, error) {
+	if chainid.EndpointType(payload.endpointType) == chainid.AzureEnvironment {
+		return handler.createAzureEndpoint(payload)
+	}
+
+	if chainid.EndpointType(payload.endpointType) == chainid.EdgeAgentEnvironment {
+		return handler.createEdgeAgentEndpoint(payload)
+	}
+
+	if chainid.EndpointType(payload.endpointType) == chainid.KubernetesEnvironment {
+		return handler.createKubernetesEndpoint(payload)
+	}
+
+	if strings.HasPrefix(payload.url, "ssh://") {
+		return handler.createSSHEndpoint(payload)
+	}
+
+	if payload.useTLS {
+		return handler.createTLSSecuredEndpoint(payload)
+	}
+	return handler.createUnsecuredEndpoint(payload)
+}
+
+func convertPostEndpointRequestToPayload(r *http.Request) (*postEndpointPayload, error) {
+	payload := &postEndpointPayload{}
+	payload.name = r.FormValue("Name")
+
+	endpointType := r.FormValue("EndpointType")
+
+	if payload.name == "" || endpointType == "" {
+		return nil, ErrInvalidRequestFormat
+	}
+
+	parsedType, err := strconv.Atoi(endpointType)
+	if err != nil {
+		return nil, err
+	}
+
+	payload.url = r.FormValue("URL")
+	payload.endpointType = parsedType
+
+	requiresURL := chainid.EndpointType(payload.endpointType) != chainid.AzureEnvironment && chainid.EndpointType(payload.endpointType) != chainid.EdgeAgentEnvironment && chainid.EndpointType(payload.endpointType) != chainid.KubernetesEnvironment
+	if requiresURL && payload.url == "" {
+		return nil, ErrInvalidRequestFormat
+	}
+
+	payload.publicURL = r.FormValue("PublicURL")
+
+	if chainid.EndpointType(payload.endpointType) == chainid.AzureEnvironment {
+		payload.azureApplicationID = r.FormValue("AzureApplicationID")
+		payload.azureTenantID = r.FormValue("AzureTenantID")
+		payload.azureAuthenticationKey = r.FormValue("AzureAuthenticationKey")
+
+		if payload.azureApplicationID == "" || payload.azureTenantID == "" || payload.azureAuthenticationKey == "" {
+			return nil, ErrInvalidRequestFormat
+		}
+	}
+
+	// A Kubernetes environment is defined either by a kubeconfig file or by the URL of the API server
+	// along with the token of a service account.
+	if chainid.EndpointType(payload.endpointType) == chainid.KubernetesEnvironment {
+		kubeconfig, err := getUploadedFileContent(r, "KubeconfigFile")
+		if err != nil && err != http.ErrMissingFile {
+			return nil, err
+		}
+		payload.kubeconfig = kubeconfig
+		payload.kubernetesToken = r.FormValue("KubernetesToken")
+
+		if payload.kubeconfig == nil && (payload.url == "" || payload.kubernetesToken == "") {
+			return nil, ErrInvalidRequestFormat
+		}
+	}
+
+	// A Docker environment reached over SSH requires a private key, the host key is verified
+	// with a known hosts file unless the verification is disabled.
+	if strings.HasPrefix(payload.url, "ssh://") {
+		sshKey, err := getUploadedFileContent(r, "SSHKeyFile")
+		if err != nil {
+			return nil, err
+		}
+		payload.sshKey = sshKey
+		payload.skipSSHVerification = r.FormValue("SSHSkipVerify") == "true"
+
+		if !payload.skipSSHVerification {
+			sshKnownHosts, err := getUploadedFileContent(r, "SSHKnownHostsFile")
+			if err != nil {
+				return nil, err
+			}
+			payload.sshKnownHosts = sshKnownHosts
+		}
+	}
+
+	rawGroupID := r.FormValue("GroupID")
+	if rawGroupID == "" {
+		payload.groupID = 1
+	} else {
+		groupID, err := strconv.Atoi(rawGroupID)
+		if err != nil {
+			return nil, err
+		}
+		payload.groupID = groupID
+	}
+
+	payload.useTLS = r.FormValue("TLS") == "true"
+
+	if payload.useTLS {
+		payload.skipTLSServerVerification = r.FormValue("TLSSkipVerify") == "true"
+		payload.skipTLSClientVerification = r.FormValue("TLSSkipClientVerify") == "true"
+
+		if !payload.skipTLSServerVerification {
+			caCert, err := getUploadedFileContent(r, "TLSCACertFile")
+			if err != nil {
+				return nil, err
+			}
+			payload.caCert = caCert
+		}
+
+		if !payload.skipTLSClientVerification {
+			cert, err := getUploadedFileContent(r, "TLSCertFile")
+			if err != nil {
+				return nil, err
+			}
+			payload.cert = cert
+			key, err := getUploadedFileContent(r, "TLSKeyFile")
+			if err != nil {
+				return nil, err
+			}
+			payload.key = key
+		}
+	}
+
+	return payload, nil
+}
+
+// handlePostEndpoints handles POST requests on /endpoints
+func (handler *EndpointHandler) handlePostEndpoints(w http.ResponseWriter, r *http.Request) {
+	if !handler.authorizeEndpointManagement {
+		httperror.WriteErrorResponse(w, ErrEndpointManagementDisabled, http.StatusServiceUnavailable, handler.Logger)
+		return
+	}
+
+	payload, err := convertPostEndpointRequestToPayload(r)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	endpoint, err := handler.createEndpoint(payload)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	encodeJSON(w, &endpoint, handler.Logger)
+}
+
+// handleGetEndpoint handles GET requests on /endpoints/:id
+func (handler *EndpointHandler) handleGetEndpoint(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	endpointID, err := strconv.Atoi(id)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	endpoint, err := handler.EndpointService.Endpoint(chainid.EndpointID(endpointID))
+	if err == chainid.ErrEndpointNotFound {
+		httperror.WriteErrorResponse(w, err, http.StatusNotFound, handler.Logger)
+		return
+	} else if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	endpoint.AzureCredentials = chainid.AzureCredentials{}
+	endpoint.KubernetesCredentials = chainid.KubernetesCredentials{}
+	hideSnapshotResources(endpoint)
+
+	encodeJSON(w, endpoint, handler.Logger)
+}
+
+// hideSnapshotResources removes the resources recorded in the snapshots of an endpoint, they are
+// only used by the search and are not filtered based on the resource controls.
+func hideSnapshotResources(endpoint *chainid.Endpoint) {
+	for i := range endpoint.Snapshots {
+		endpoint.Snapshots[i].Resources = nil
+	}
+}
+
+// handleGetEndpointStatus handles GET requests on /endpoints/:id/status
+func (handler *EndpointHandler) handleGetEndpointStatus(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	endpointID, err := strconv.Atoi(id)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	securityContext, err := security.RetrieveRestrictedRequestContext(r)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	endpoint, err := handler.EndpointService.Endpoint(chainid.EndpointID(endpointID))
+	if err == chainid.ErrEndpointNotFound {
+		httperror.WriteErrorResponse(w, err, http.StatusNotFound, handler.Logger)
+		return
+	} else if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	if !securityContext.IsAdmin {
+		group, err := handler.EndpointGroupService.EndpointGroup(endpoint.GroupID)
+		if err != nil {
+			httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+			return
+		}
+
+		if !security.AuthorizedEndpointAccess(endpoint, group, securityContext.UserID, securityContext.UserMemberships) {
+			httperror.WriteErrorResponse(w, chainid.ErrEndpointAccessDenied, http.StatusForbidden, handler.Logger)
+			return
+		}
+	}
+
+	history := endpoint.StatusHistory
+	if history == nil {
+		history = []chainid.EndpointStatusCheck{}
+	}
+
+	response := &getEndpointStatusResponse{
+		Status:        endpoint.Status,
+		Latency:       endpoint.Latency,
+		DockerVersion: endpoint.DockerVersion,
+		History:       history,
+	}
+
+	if endpoint.Type == chainid.EdgeAgentEnvironment {
+		response.Tunnel = handler.TunnelService.TunnelStatus(endpoint.ID)
+	}
+	response.CircuitBreaker = handler.ProxyManager.CircuitBreakerStatus(endpoint.ID)
+
+	encodeJSON(w, response, handler.Logger)
+}
+
+// handlePostEndpointTunnelToken handles POST requests on /endpoints/:id/tunnel/token
+// A new join token is generated and the current tunnel of the endpoint is closed.
+func (handler *EndpointHandler) handlePostEndpointTunnelToken(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	endpointID, err := strconv.Atoi(id)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	endpoint, err := handler.EndpointService.Endpoint(chainid.EndpointID(endpointID))
+	if err == chainid.ErrEndpointNotFound {
+		httperror.WriteErrorResponse(w, err, http.StatusNotFound, handler.Logger)
+		return
+	} else if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	if endpoint.ExternallyManaged {
+		httperror.WriteErrorResponse(w, chainid.ErrEndpointExternallyManaged, http.StatusForbidden, handler.Logger)
+		return
+	}
+
+	if endpoint.Type != chainid.EdgeAgentEnvironment {
+		httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	joinToken, err := tunnel.GenerateJoinToken()
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+	endpoint.EdgeJoinToken = joinToken
+
+	err = handler.EndpointService.UpdateEndpoint(endpoint.ID, endpoint)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	handler.TunnelService.CloseTunnel(endpoint.ID)
+
+	encodeJSON(w, &postEndpointTunnelTokenResponse{JoinToken: joinToken}, handler.Logger)
+}
+
+// handlePutEndpointAccess handles PUT requests on /endpoints/:id/access
+func (handler *EndpointHandler) handlePutEndpointAccess(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	endpointID, err := strconv.Atoi(id)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	var req putEndpointAccessRequest
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidJSON, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	_, err = govalidator.ValidateStruct(req)
+	if err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	endpoint, err := handler.EndpointService.Endpoint(chainid.EndpointID(endpointID))
+	if err == chainid.ErrEndpointNotFound {
+		httperror.WriteErrorResponse(w, err, http.StatusNotFound, handler.Logger)
+		return
+	} else if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	if endpoint.ExternallyManaged {
+		httperror.WriteErrorResponse(w, chainid.ErrEndpointExternallyManaged, http.StatusForbidden, handler.Logger)
+		return
+	}
+
+	if req.AuthorizedUsers != nil {
+		authorizedUserIDs := []chainid.UserID{}
+		for _, value := range req.AuthorizedUsers {
+			authorizedUserIDs = append(authorizedUserIDs, chainid.UserID(value))
+		}
+		endpoint.AuthorizedUsers = authorizedUserIDs
+	}
+
+	if req.AuthorizedTeams != nil {
+		authorizedTeamIDs := []chainid.TeamID{}
+		for _, value := range req.AuthorizedTeams {
+			authorizedTeamIDs = append(authorizedTeamIDs, chainid.TeamID(value))
+		}
+		endpoint.AuthorizedTeams = authorizedTeamIDs
+	}
+
+	err = handler.EndpointService.UpdateEndpoint(endpoint.ID, endpoint)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+}
+
+// handlePutEndpointNamespaces handles PUT requests on /endpoints/:id/namespaces
+// The namespace accesses of a Kubernetes environment are replaced.
+func (handler *EndpointHandler) handlePutEndpointNamespaces(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	endpointID, err := strconv.Atoi(id)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	var req putEndpointNamespacesRequest
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidJSON, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	namespaces := make([]chainid.KubernetesNamespaceAccess, 0, len(req.Namespaces))
+	names := make(map[string]bool)
+	for _, namespace := range req.Namespaces {
+		_, err = govalidator.ValidateStruct(namespace)
+		if err != nil || names[namespace.Namespace] {
+			httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
+			return
+		}
+		names[namespace.Namespace] = true
+
+		access := chainid.KubernetesNamespaceAccess{
+			Namespace:       namespace.Namespace,
+			AuthorizedUsers: []chainid.UserID{},
+			AuthorizedTeams: []chainid.TeamID{},
+		}
+		for _, value := range namespace.AuthorizedUsers {
+			access.AuthorizedUsers = append(access.AuthorizedUsers, chainid.UserID(value))
+		}
+		for _, value := range namespace.AuthorizedTeams {
+			access.AuthorizedTeams = append(access.AuthorizedTeams, chainid.TeamID(value))
+		}
+		namespaces = append(namespaces, access)
+	}
+
+	endpoint, err := handler.EndpointService.Endpoint(chainid.EndpointID(endpointID))
+	if err == chainid.ErrEndpointNotFound {
+		httperror.WriteErrorResponse(w, err, http.StatusNotFound, handler.Logger)
+		return
+	} else if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	if endpoint.ExternallyManaged {
+		httperror.WriteErrorResponse(w, chainid.ErrEndpointExternallyManaged, http.StatusForbidden, handler.Logger)
+		return
+	}
+
+	if endpoint.Type != chainid.KubernetesEnvironment {
+		httperror.WriteErrorResponse(w, ErrNotKubernetesEndpoint, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	endpoint.KubernetesNamespaces = namespaces
+
+	_, err = handler.ProxyManager.CreateAndRegisterProxy(endpoint)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	err = handler.EndpointService.UpdateEndpoint(endpoint.ID, endpoint)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+}
+
+// handlePutEndpoint handles PUT requests on /endpoints/:id
+func (handler *EndpointHandler) handlePutEndpoint(w http.ResponseWriter, r *http.Request) {
+	if !handler.authorizeEndpointManagement {
+		httperror.WriteErrorResponse(w, ErrEndpointManagementDisabled, http.StatusServiceUnavailable, handler.Logger)
+		return
+	}
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	endpointID, err := strconv.Atoi(id)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	var req putEndpointsRequest
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidJSON, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	_, err = govalidator.ValidateStruct(req)
+	if err != nil || (req.TransportSettings != nil && !validTransportSettings(req.TransportSettings)) {
+		httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	endpoint, err := handler.EndpointService.Endpoint(chainid.EndpointID(endpointID))
+	if err == chainid.ErrEndpointNotFound {
+		httperror.WriteErrorResponse(w, err, http.StatusNotFound, handler.Logger)
+		return
+	} else if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	if endpoint.ExternallyManaged {
+		httperror.WriteErrorResponse(w, chainid.ErrEndpointExternallyManaged, http.StatusForbidden, handler.Logger)
+		return
+	}
+
+	statusCode, err := handler.authorizeEndpointUpdate(r, endpoint, &req)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, statusCode, handler.Logger)
+		return
+	}
+
+	if req.TransportSettings != nil {
+		endpoint.TransportSettings = *req.TransportSettings
+	}
+
+	if req.Name != "" {
+		endpoint.Name = req.Name
+	}
+
+	if req.URL != "" && endpoint.Type != chainid.EdgeAgentEnvironment {
+		// The key of an SSH endpoint is only uploaded when the endpoint is created.
+		if strings.HasPrefix(req.URL, "ssh://") && endpoint.SSHConfig.SSHKeyPath == "" {
+			httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
+			return
+		}
+		endpoint.URL = req.URL
+	}
+
+	if req.PublicURL != "" {
+		endpoint.PublicURL = req.PublicURL
+	}
+
+	if req.GroupID != 0 {
+		endpoint.GroupID = chainid.EndpointGroupID(req.GroupID)
+	}
+
+	// The TLS configuration of a Kubernetes environment is defined by its credentials.
+	if endpoint.Type != chainid.KubernetesEnvironment {
+		folder := strconv.Itoa(int(endpoint.ID))
+		if req.TLS {
+			endpoint.TLSConfig.TLS = true
+			endpoint.TLSConfig.TLSSkipVerify = req.TLSSkipVerify
+			if !req.TLSSkipVerify {
+				caCertPath, _ := handler.FileService.GetPathForTLSFile(folder, chainid.TLSFileCA)
+				endpoint.TLSConfig.TLSCACertPath = caCertPath
+			} else {
+				endpoint.TLSConfig.TLSCACertPath = ""
+				handler.FileService.DeleteTLSFile(folder, chainid.TLSFileCA)
+			}
+
+			if !req.TLSSkipClientVerify {
+				certPath, _ := handler.FileService.GetPathForTLSFile(folder, chainid.TLSFileCert)
+				endpoint.TLSConfig.TLSCertPath = certPath
+				keyPath, _ := handler.FileService.GetPathForTLSFile(folder, chainid.TLSFileKey)
+				endpoint.TLSConfig.TLSKeyPath = keyPath
+			} else {
+				endpoint.TLSConfig.TLSCertPath = ""
+				handler.FileService.DeleteTLSFile(folder, chainid.TLSFileCert)
+				endpoint.TLSConfig.TLSKeyPath = ""
+				handler.FileService.DeleteTLSFile(folder, chainid.TLSFileKey)
+			}
+		} else {
+			endpoint.TLSConfig.TLS = false
+			endpoint.TLSConfig.TLSSkipVerify = false
+			endpoint.TLSConfig.TLSCACertPath = ""
+			endpoint.TLSConfig.TLSCertPath = ""
+			endpoint.TLSConfig.TLSKeyPath = ""
+			err = handler.FileService.DeleteTLSFiles(folder)
+			if err != nil {
+				httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+				return
+			}
+		}
+	}
+
+	_, err = handler.ProxyManager.CreateAndRegisterProxy(endpoint)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	err = handler.EndpointService.UpdateEndpoint(endpoint.ID, endpoint)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+}
+
+// authorizeEndpointUpdate checks the permissions required by the changes of an endpoint update in addition
+// to the permission checked on the current group of the endpoint. Moving the endpoint to another group
+// requires the permission on the target group, changing the URL requires a global permission since it
+// defines the Docker daemon reached through the endpoint.
+func (handler *EndpointHandler) authorizeEndpointUpdate(r *http.Request, endpoint *chainid.Endpoint, req *putEndpointsRequest) (int, error) {
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	groupID := chainid.EndpointGroupID(req.GroupID)
+	if groupID != 0 && groupID != endpoint.GroupID {
+		_, err = handler.EndpointGroupService.EndpointGroup(groupID)
+		if err == chainid.ErrEndpointGroupNotFound {
+			return http.StatusNotFound, err
+		} else if err != nil {
+			return http.StatusInternalServerError, err
+		}
+
+		authorized, err := handler.Authorizer.Authorized(tokenData, chainid.EndpointUpdatePermission, groupID)
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+		if !authorized {
+			return http.StatusForbidden, chainid.ErrResourceAccessDenied
+		}
+	}
+
+	if req.URL != "" && req.URL != endpoint.URL {
+		authorized, err := handler.Authorizer.Authorized(tokenData, chainid.EndpointUpdatePermission, 0)
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+		if !authorized {
+			return http.StatusForbidden, chainid.ErrResourceAccessDenied
+		}
+	}
+
+	return http.StatusOK, nil
+}
+
+// validTransportSettings checks that the durations and the number of idle connections are not
+// negative, a negative circuit breaker threshold disables the circuit breaker.
+func validTransportSettings(settings *chainid.EndpointTransportSettings) bool {
+	values := []int{
+		settings.DialTimeout,
+		settings.TLSHandshakeTimeout,
+		settings.ResponseHeaderTimeout,
+		settings.IdleConnTimeout,
+		settings.KeepAlive,
+		settings.MaxIdleConns,
+		settings.CircuitBreakerTimeout,
+	}
+	for _, value := range values {
+		if value < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// handleDeleteEndpoint handles DELETE requests on /endpoints/:id
+func (handler *EndpointHandler) handleDeleteEndpoint(w http.ResponseWriter, r *http.Request) {
+	if !handler.authorizeEndpointManagement {
+		httperror.WriteErrorResponse(w, ErrEndpointManagementDisabled, http.StatusServiceUnavailable, handler.Logger)
+		return
+	}
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	endpointID, err := strconv.Atoi(id)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	endpoint, err := handler.EndpointService.Endpoint(chainid.EndpointID(endpointID))
+
+	if err == chainid.ErrEndpointNotFound {
+		httperror.WriteErrorResponse(w, err, http.StatusNotFound, handler.Logger)
+		return
+	} else if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	if endpoint.ExternallyManaged {
+		httperror.WriteErrorResponse(w, chainid.ErrEndpointExternallyManaged, http.StatusForbidden, handler.Logger)
+		return
+	}
+
+	if endpoint.Type == chainid.EdgeAgentEnvironment {
+		handler.TunnelService.CloseTunnel(endpoint.ID)
+	}
+
+	err = handler.EndpointService.DeleteEndpoint(chainid.EndpointID(endpointID))
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	if endpoint.TLSConfig.TLS {
+		err = handler.FileService.DeleteTLSFiles(id)
+		if err != nil {
+			httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+			return
+		}
+	}
+
+	if endpoint.SSHConfig.SSHKeyPath != "" {
+		err = handler.FileService.DeleteSSHFiles(id)
+		if err != nil {
+			httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+			return
+		}
+	}
+}
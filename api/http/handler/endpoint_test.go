@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/filesystem"
+	"github.com/chainid-io/dashboard/http/proxy"
+	"github.com/chainid-io/dashboard/http/security"
+	"github.com/chainid-io/dashboard/jwt"
+	"github.com/chainid-io/dashboard/kv"
+)
+
+func TestPutEndpointAuthorization(t *testing.T) {
+	dataStorePath, err := ioutil.TempDir("", "chainid-endpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dataStorePath)
+
+	fileService, err := filesystem.NewService(dataStorePath, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := kv.NewStore(kv.NewMemoryBackend())
+	err = store.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.SettingsService.StoreSettings(&chainid.Settings{AuthenticationMethod: chainid.AuthenticationInternal})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := &chainid.User{Username: "alice", Role: chainid.StandardUserRole}
+	err = store.UserService.CreateUser(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	managed := &chainid.EndpointGroup{Name: "managed", AuthorizedUsers: []chainid.UserID{}, AuthorizedTeams: []chainid.TeamID{}}
+	other := &chainid.EndpointGroup{Name: "other", AuthorizedUsers: []chainid.UserID{}, AuthorizedTeams: []chainid.TeamID{}}
+	for _, group := range []*chainid.EndpointGroup{managed, other} {
+		err = store.EndpointGroupService.CreateEndpointGroup(group)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	role := &chainid.Role{Name: "updater", Permissions: []chainid.Permission{chainid.EndpointUpdatePermission}}
+	err = store.RoleService.CreateRole(role)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.RoleAssignmentService.CreateRoleAssignment(&chainid.RoleAssignment{RoleID: role.ID, UserID: user.ID, EndpointGroupID: managed.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	endpoint := &chainid.Endpoint{Name: "local", Type: chainid.DockerEnvironment, URL: "tcp://127.0.0.1:2375", GroupID: managed.ID}
+	err = store.EndpointService.CreateEndpoint(endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret, err := jwt.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtService, err := jwt.NewService(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := jwtService.GenerateToken(&chainid.TokenData{ID: user.ID, Username: user.Username, Role: user.Role})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authorizer := security.NewAuthorizer(store.RoleService, store.RoleAssignmentService, store.TeamMembershipService)
+	bouncer := security.NewRequestBouncer(jwtService, store.UserService, store.TeamMembershipService, store.SettingsService, store.EndpointService, authorizer, false)
+
+	handler := NewEndpointHandler(bouncer, true)
+	handler.EndpointService = store.EndpointService
+	handler.EndpointGroupService = store.EndpointGroupService
+	handler.FileService = fileService
+	handler.Authorizer = authorizer
+	handler.ProxyManager = proxy.NewManager(&proxy.ManagerParams{
+		ResourceControlService: store.ResourceControlService,
+		TeamMembershipService:  store.TeamMembershipService,
+		SettingsService:        store.SettingsService,
+		Authorizer:             authorizer,
+	})
+
+	testCases := []struct {
+		name       string
+		body       string
+		statusCode int
+	}{
+		{"move to a group without permission", fmt.Sprintf(`{"GroupID":%d}`, other.ID), http.StatusForbidden},
+		{"move to a missing group", `{"GroupID":42}`, http.StatusNotFound},
+		{"change the URL with a group-scoped permission", `{"URL":"unix:///var/run/docker.sock"}`, http.StatusForbidden},
+		{"rename", fmt.Sprintf(`{"Name":"renamed","GroupID":%d,"URL":"tcp://127.0.0.1:2375"}`, managed.ID), http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/endpoints/%d", endpoint.ID), strings.NewReader(tc.body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != tc.statusCode {
+			t.Errorf("%s: expected status code %d, got %d: %s", tc.name, tc.statusCode, rr.Code, rr.Body.String())
+		}
+	}
+
+	updated, err := store.EndpointService.Endpoint(endpoint.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Name != "renamed" || updated.GroupID != managed.ID || updated.URL != endpoint.URL {
+		t.Errorf("expected only the name of the endpoint to be updated, got %+v", updated)
+	}
+}
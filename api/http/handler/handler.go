@@ -1,120 +1,163 @@
-package handler
-
-import (
-	"encoding/json"
-	"io/ioutil"
-	"log"
-	"net/http"
-	"strings"
-
-	"github.com/chainid-io/dashboard"
-	httperror "github.com/chainid-io/dashboard/http/error"
-	"github.com/chainid-io/dashboard/http/handler/extensions"
-)
-
-// Handler is a collection of all the service handlers.
-type Handler struct {
-	AuthHandler           *AuthHandler
-	UserHandler           *UserHandler
-	TeamHandler           *TeamHandler
-	TeamMembershipHandler *TeamMembershipHandler
-	EndpointHandler       *EndpointHandler
-	EndpointGroupHandler  *EndpointGroupHandler
-	RegistryHandler       *RegistryHandler
-	DockerHubHandler      *DockerHubHandler
-	ExtensionHandler      *ExtensionHandler
-	StoridgeHandler       *extensions.StoridgeHandler
-	ResourceHandler       *ResourceHandler
-	StackHandler          *StackHandler
-	StatusHandler         *StatusHandler
-	SettingsHandler       *SettingsHandler
-	TemplatesHandler      *TemplatesHandler
-	DockerHandler         *DockerHandler
-	AzureHandler          *AzureHandler
-	WebSocketHandler      *WebSocketHandler
-	UploadHandler         *UploadHandler
-	FileHandler           *FileHandler
-}
-
-const (
-	// ErrInvalidJSON defines an error raised the app is unable to parse request data
-	ErrInvalidJSON = chainid.Error("Invalid JSON")
-	// ErrInvalidRequestFormat defines an error raised when the format of the data sent in a request is not valid
-	ErrInvalidRequestFormat = chainid.Error("Invalid request data format")
-	// ErrInvalidQueryFormat defines an error raised when the data sent in the query or the URL is invalid
-	ErrInvalidQueryFormat = chainid.Error("Invalid query format")
-)
-
-// ServeHTTP delegates a request to the appropriate subhandler.
-func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-
-	switch {
-	case strings.HasPrefix(r.URL.Path, "/api/auth"):
-		http.StripPrefix("/api", h.AuthHandler).ServeHTTP(w, r)
-	case strings.HasPrefix(r.URL.Path, "/api/dockerhub"):
-		http.StripPrefix("/api", h.DockerHubHandler).ServeHTTP(w, r)
-	case strings.HasPrefix(r.URL.Path, "/api/endpoint_groups"):
-		http.StripPrefix("/api", h.EndpointGroupHandler).ServeHTTP(w, r)
-	case strings.HasPrefix(r.URL.Path, "/api/endpoints"):
-		switch {
-		case strings.Contains(r.URL.Path, "/docker/"):
-			http.StripPrefix("/api/endpoints", h.DockerHandler).ServeHTTP(w, r)
-		case strings.Contains(r.URL.Path, "/stacks"):
-			http.StripPrefix("/api/endpoints", h.StackHandler).ServeHTTP(w, r)
-		case strings.Contains(r.URL.Path, "/extensions/storidge"):
-			http.StripPrefix("/api/endpoints", h.StoridgeHandler).ServeHTTP(w, r)
-		case strings.Contains(r.URL.Path, "/extensions"):
-			http.StripPrefix("/api/endpoints", h.ExtensionHandler).ServeHTTP(w, r)
-		case strings.Contains(r.URL.Path, "/azure/"):
-			http.StripPrefix("/api/endpoints", h.AzureHandler).ServeHTTP(w, r)
-		default:
-			http.StripPrefix("/api", h.EndpointHandler).ServeHTTP(w, r)
-		}
-	case strings.HasPrefix(r.URL.Path, "/api/registries"):
-		http.StripPrefix("/api", h.RegistryHandler).ServeHTTP(w, r)
-	case strings.HasPrefix(r.URL.Path, "/api/resource_controls"):
-		http.StripPrefix("/api", h.ResourceHandler).ServeHTTP(w, r)
-	case strings.HasPrefix(r.URL.Path, "/api/settings"):
-		http.StripPrefix("/api", h.SettingsHandler).ServeHTTP(w, r)
-	case strings.HasPrefix(r.URL.Path, "/api/status"):
-		http.StripPrefix("/api", h.StatusHandler).ServeHTTP(w, r)
-	case strings.HasPrefix(r.URL.Path, "/api/templates"):
-		http.StripPrefix("/api", h.TemplatesHandler).ServeHTTP(w, r)
-	case strings.HasPrefix(r.URL.Path, "/api/upload"):
-		http.StripPrefix("/api", h.UploadHandler).ServeHTTP(w, r)
-	case strings.HasPrefix(r.URL.Path, "/api/users"):
-		http.StripPrefix("/api", h.UserHandler).ServeHTTP(w, r)
-	case strings.HasPrefix(r.URL.Path, "/api/teams"):
-		http.StripPrefix("/api", h.TeamHandler).ServeHTTP(w, r)
-	case strings.HasPrefix(r.URL.Path, "/api/team_memberships"):
-		http.StripPrefix("/api", h.TeamMembershipHandler).ServeHTTP(w, r)
-	case strings.HasPrefix(r.URL.Path, "/api/websocket"):
-		http.StripPrefix("/api", h.WebSocketHandler).ServeHTTP(w, r)
-	case strings.HasPrefix(r.URL.Path, "/"):
-		h.FileHandler.ServeHTTP(w, r)
-	}
-}
-
-// encodeJSON encodes v to w in JSON format. WriteErrorResponse() is called if encoding fails.
-func encodeJSON(w http.ResponseWriter, v interface{}, logger *log.Logger) {
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(v); err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, logger)
-	}
-}
-
-// getUploadedFileContent retrieve the content of a file uploaded in the request.
-// Uses requestParameter as the key to retrieve the file in the request payload.
-func getUploadedFileContent(request *http.Request, requestParameter string) ([]byte, error) {
-	file, _, err := request.FormFile(requestParameter)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	fileContent, err := ioutil.ReadAll(file)
-	if err != nil {
-		return nil, err
-	}
-	return fileContent, nil
-}
+package handler
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/chainid-io/dashboard"
+	httperror "github.com/chainid-io/dashboard/http/error"
+	"github.com/chainid-io/dashboard/http/handler/extensions"
+)
+
+// Handler is a collection of all the service handlers.
+type Handler struct {
+	AggregateHandler      *AggregateHandler
+	AuthHandler           *AuthHandler
+	AuditHandler          *AuditHandler
+	BackupHandler         *BackupHandler
+	ClusterHandler        *ClusterHandler
+	ConfigHandler         *ConfigHandler
+	DebugHandler          *DebugHandler
+	UserHandler           *UserHandler
+	TeamHandler           *TeamHandler
+	TeamMembershipHandler *TeamMembershipHandler
+	EndpointHandler       *EndpointHandler
+	EndpointGroupHandler  *EndpointGroupHandler
+	RegistryHandler       *RegistryHandler
+	DockerHubHandler      *DockerHubHandler
+	ExtensionHandler      *ExtensionHandler
+	StoridgeHandler       *extensions.StoridgeHandler
+	ResourceHandler       *ResourceHandler
+	RoleHandler           *RoleHandler
+	RoleAssignmentHandler *RoleAssignmentHandler
+	StackHandler          *StackHandler
+	StatusHandler         *StatusHandler
+	SearchHandler         *SearchHandler
+	SettingsHandler       *SettingsHandler
+	TemplatesHandler      *TemplatesHandler
+	TunnelHandler         *TunnelHandler
+	DockerHandler         *DockerHandler
+	AzureHandler          *AzureHandler
+	KubernetesHandler     *KubernetesHandler
+	WebSocketHandler      *WebSocketHandler
+	UploadHandler         *UploadHandler
+	FileHandler           *FileHandler
+}
+
+const (
+	// ErrInvalidJSON defines an error raised the app is unable to parse request data
+	ErrInvalidJSON = chainid.Error("Invalid JSON")
+	// ErrInvalidRequestFormat defines an error raised when the format of the data sent in a request is not valid
+	ErrInvalidRequestFormat = chainid.Error("Invalid request data format")
+	// ErrInvalidQueryFormat defines an error raised when the data sent in the query or the URL is invalid
+	ErrInvalidQueryFormat = chainid.Error("Invalid query format")
+)
+
+// ServeHTTP records mutating and connection upgrade requests in the audit log and delegates them to the appropriate subhandler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if auditedRequest(r) {
+		h.AuditHandler.Audit(http.HandlerFunc(h.dispatch)).ServeHTTP(w, r)
+		return
+	}
+	h.dispatch(w, r)
+}
+
+// dispatch delegates a request to the appropriate subhandler.
+func (h *Handler) dispatch(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/api/aggregate"):
+		http.StripPrefix("/api", h.AggregateHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/audit"):
+		http.StripPrefix("/api", h.AuditHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/auth"):
+		http.StripPrefix("/api", h.AuthHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/backup"):
+		http.StripPrefix("/api", h.BackupHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/cluster"):
+		http.StripPrefix("/api", h.ClusterHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/debug"):
+		http.StripPrefix("/api", h.DebugHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/config"):
+		http.StripPrefix("/api", h.ConfigHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/dockerhub"):
+		http.StripPrefix("/api", h.DockerHubHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/endpoint_groups"):
+		http.StripPrefix("/api", h.EndpointGroupHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/endpoints"):
+		switch {
+		case strings.Contains(r.URL.Path, "/docker/"):
+			http.StripPrefix("/api/endpoints", h.DockerHandler).ServeHTTP(w, r)
+		case strings.Contains(r.URL.Path, "/stacks"):
+			http.StripPrefix("/api/endpoints", h.StackHandler).ServeHTTP(w, r)
+		case strings.Contains(r.URL.Path, "/extensions/storidge"):
+			http.StripPrefix("/api/endpoints", h.StoridgeHandler).ServeHTTP(w, r)
+		case strings.Contains(r.URL.Path, "/extensions"):
+			http.StripPrefix("/api/endpoints", h.ExtensionHandler).ServeHTTP(w, r)
+		case strings.Contains(r.URL.Path, "/azure/"):
+			http.StripPrefix("/api/endpoints", h.AzureHandler).ServeHTTP(w, r)
+		case strings.Contains(r.URL.Path, "/kubernetes/"):
+			http.StripPrefix("/api/endpoints", h.KubernetesHandler).ServeHTTP(w, r)
+		default:
+			http.StripPrefix("/api", h.EndpointHandler).ServeHTTP(w, r)
+		}
+	case strings.HasPrefix(r.URL.Path, "/api/registries"):
+		http.StripPrefix("/api", h.RegistryHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/resource_controls"):
+		http.StripPrefix("/api", h.ResourceHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/restore"):
+		http.StripPrefix("/api", h.BackupHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/role_assignments"):
+		http.StripPrefix("/api", h.RoleAssignmentHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/roles"):
+		http.StripPrefix("/api", h.RoleHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/search"):
+		http.StripPrefix("/api", h.SearchHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/settings"):
+		http.StripPrefix("/api", h.SettingsHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/status"):
+		http.StripPrefix("/api", h.StatusHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/templates"):
+		http.StripPrefix("/api", h.TemplatesHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/tunnel"):
+		http.StripPrefix("/api", h.TunnelHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/upload"):
+		http.StripPrefix("/api", h.UploadHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/users"):
+		http.StripPrefix("/api", h.UserHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/teams"):
+		http.StripPrefix("/api", h.TeamHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/team_memberships"):
+		http.StripPrefix("/api", h.TeamMembershipHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/websocket"):
+		http.StripPrefix("/api", h.WebSocketHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/"):
+		h.FileHandler.ServeHTTP(w, r)
+	}
+}
+
+// encodeJSON encodes v to w in JSON format. WriteErrorResponse() is called if encoding fails.
+func encodeJSON(w http.ResponseWriter, v interface{}, logger *log.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, logger)
+	}
+}
+
+// getUploadedFileContent retrieve the content of a file uploaded in the request.
+// Uses requestParameter as the key to retrieve the file in the request payload.
+func getUploadedFileContent(request *http.Request, requestParameter string) ([]byte, error) {
+	file, _, err := request.FormFile(requestParameter)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	fileContent, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	return fileContent, nil
+}
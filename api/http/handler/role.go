@@ -0,0 +1,268 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/chainid-io/dashboard"
+	httperror "github.com/chainid-io/dashboard/http/error"
+	"github.com/chainid-io/dashboard/http/security"
+
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/gorilla/mux"
+)
+
+// RoleHandler represents an HTTP API handler for managing roles.
+type RoleHandler struct {
+	*mux.Router
+	Logger                *log.Logger
+	RoleService           chainid.RoleService
+	RoleAssignmentService chainid.RoleAssignmentService
+}
+
+// NewRoleHandler returns a new instance of RoleHandler.
+func NewRoleHandler(bouncer *security.RequestBouncer) *RoleHandler {
+	h := &RoleHandler{
+		Router: mux.NewRouter(),
+		Logger: log.New(os.Stderr, "", log.LstdFlags),
+	}
+	h.Handle("/roles",
+		bouncer.AdministratorAccess(http.HandlerFunc(h.handlePostRoles))).Methods(http.MethodPost)
+	h.Handle("/roles",
+		bouncer.AdministratorAccess(http.HandlerFunc(h.handleGetRoles))).Methods(http.MethodGet)
+	h.Handle("/roles/{id}",
+		bouncer.AdministratorAccess(http.HandlerFunc(h.handleGetRole))).Methods(http.MethodGet)
+	h.Handle("/roles/{id}",
+		bouncer.AdministratorAccess(http.HandlerFunc(h.handlePutRole))).Methods(http.MethodPut)
+	h.Handle("/roles/{id}",
+		bouncer.AdministratorAccess(http.HandlerFunc(h.handleDeleteRole))).Methods(http.MethodDelete)
+
+	return h
+}
+
+type (
+	postRolesRequest struct {
+		Name        string               `valid:"required"`
+		Description string               `valid:"-"`
+		Permissions []chainid.Permission `valid:"-"`
+	}
+
+	postRolesResponse struct {
+		ID int `json:"Id"`
+	}
+
+	putRoleRequest struct {
+		Name        string               `valid:"-"`
+		Description string               `valid:"-"`
+		Permissions []chainid.Permission `valid:"-"`
+	}
+)
+
+// handlePostRoles handles POST requests on /roles
+func (handler *RoleHandler) handlePostRoles(w http.ResponseWriter, r *http.Request) {
+	var req postRolesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidJSON, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	_, err := govalidator.ValidateStruct(req)
+	if err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	err = validatePermissions(req.Permissions)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	role, err := handler.RoleService.RoleByName(req.Name)
+	if err != nil && err != chainid.ErrRoleNotFound {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+	if role != nil {
+		httperror.WriteErrorResponse(w, chainid.ErrRoleAlreadyExists, http.StatusConflict, handler.Logger)
+		return
+	}
+
+	role = &chainid.Role{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: req.Permissions,
+	}
+	if role.Permissions == nil {
+		role.Permissions = []chainid.Permission{}
+	}
+
+	err = handler.RoleService.CreateRole(role)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	encodeJSON(w, &postRolesResponse{ID: int(role.ID)}, handler.Logger)
+}
+
+// handleGetRoles handles GET requests on /roles
+func (handler *RoleHandler) handleGetRoles(w http.ResponseWriter, r *http.Request) {
+	roles, err := handler.RoleService.Roles()
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	encodeJSON(w, roles, handler.Logger)
+}
+
+// handleGetRole handles GET requests on /roles/:id
+func (handler *RoleHandler) handleGetRole(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	roleID, err := strconv.Atoi(id)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	role, err := handler.RoleService.Role(chainid.RoleID(roleID))
+	if err == chainid.ErrRoleNotFound {
+		httperror.WriteErrorResponse(w, err, http.StatusNotFound, handler.Logger)
+		return
+	} else if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	encodeJSON(w, &role, handler.Logger)
+}
+
+// handlePutRole handles PUT requests on /roles/:id
+func (handler *RoleHandler) handlePutRole(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	roleID, err := strconv.Atoi(id)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	var req putRoleRequest
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidJSON, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	_, err = govalidator.ValidateStruct(req)
+	if err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	err = validatePermissions(req.Permissions)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	role, err := handler.RoleService.Role(chainid.RoleID(roleID))
+	if err == chainid.ErrRoleNotFound {
+		httperror.WriteErrorResponse(w, err, http.StatusNotFound, handler.Logger)
+		return
+	} else if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	if role.ID == chainid.AdministratorRoleID {
+		httperror.WriteErrorResponse(w, chainid.ErrBuiltInRoleCannotBeModified, http.StatusForbidden, handler.Logger)
+		return
+	}
+
+	if req.Name != "" && req.Name != role.Name {
+		if role.BuiltIn {
+			httperror.WriteErrorResponse(w, chainid.ErrBuiltInRoleCannotBeModified, http.StatusForbidden, handler.Logger)
+			return
+		}
+
+		existingRole, err := handler.RoleService.RoleByName(req.Name)
+		if err != nil && err != chainid.ErrRoleNotFound {
+			httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+			return
+		}
+		if existingRole != nil {
+			httperror.WriteErrorResponse(w, chainid.ErrRoleAlreadyExists, http.StatusConflict, handler.Logger)
+			return
+		}
+		role.Name = req.Name
+	}
+
+	if req.Description != "" {
+		role.Description = req.Description
+	}
+
+	if req.Permissions != nil {
+		role.Permissions = req.Permissions
+	}
+
+	err = handler.RoleService.UpdateRole(role.ID, role)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+}
+
+// handleDeleteRole handles DELETE requests on /roles/:id
+func (handler *RoleHandler) handleDeleteRole(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	roleID, err := strconv.Atoi(id)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	role, err := handler.RoleService.Role(chainid.RoleID(roleID))
+	if err == chainid.ErrRoleNotFound {
+		httperror.WriteErrorResponse(w, err, http.StatusNotFound, handler.Logger)
+		return
+	} else if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	if role.BuiltIn {
+		httperror.WriteErrorResponse(w, chainid.ErrBuiltInRoleCannotBeRemoved, http.StatusForbidden, handler.Logger)
+		return
+	}
+
+	err = handler.RoleService.DeleteRole(role.ID)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	err = handler.RoleAssignmentService.DeleteRoleAssignmentsByRoleID(role.ID)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+}
+
+func validatePermissions(permissions []chainid.Permission) error {
+	for _, permission := range permissions {
+		if !security.ValidPermission(permission) {
+			return chainid.ErrInvalidPermission
+		}
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/chainid-io/dashboard"
+	httperror "github.com/chainid-io/dashboard/http/error"
+	"github.com/chainid-io/dashboard/http/security"
+
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/gorilla/mux"
+)
+
+// RoleAssignmentHandler represents an HTTP API handler for managing role assignments.
+type RoleAssignmentHandler struct {
+	*mux.Router
+	Logger                *log.Logger
+	RoleService           chainid.RoleService
+	RoleAssignmentService chainid.RoleAssignmentService
+	UserService           chainid.UserService
+	TeamService           chainid.TeamService
+	EndpointGroupService  chainid.EndpointGroupService
+}
+
+// NewRoleAssignmentHandler returns a new instance of RoleAssignmentHandler.
+func NewRoleAssignmentHandler(bouncer *security.RequestBouncer) *RoleAssignmentHandler {
+	h := &RoleAssignmentHandler{
+		Router: mux.NewRouter(),
+		Logger: log.New(os.Stderr, "", log.LstdFlags),
+	}
+	h.Handle("/role_assignments",
+		bouncer.AdministratorAccess(http.HandlerFunc(h.handlePostRoleAssignments))).Methods(http.MethodPost)
+	h.Handle("/role_assignments",
+		bouncer.AdministratorAccess(http.HandlerFunc(h.handleGetRoleAssignments))).Methods(http.MethodGet)
+	h.Handle("/role_assignments/{id}",
+		bouncer.AdministratorAccess(http.HandlerFunc(h.handleDeleteRoleAssignment))).Methods(http.MethodDelete)
+
+	return h
+}
+
+type (
+	postRoleAssignmentsRequest struct {
+		RoleID          int `valid:"required"`
+		UserID          int `valid:"-"`
+		TeamID          int `valid:"-"`
+		EndpointGroupID int `valid:"-"`
+	}
+
+	postRoleAssignmentsResponse struct {
+		ID int `json:"Id"`
+	}
+)
+
+// handlePostRoleAssignments handles POST requests on /role_assignments
+func (handler *RoleAssignmentHandler) handlePostRoleAssignments(w http.ResponseWriter, r *http.Request) {
+	var req postRoleAssignmentsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidJSON, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	_, err := govalidator.ValidateStruct(req)
+	if err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	if (req.UserID == 0) == (req.TeamID == 0) {
+		httperror.WriteErrorResponse(w, chainid.ErrInvalidRoleAssignmentSubject, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	assignment := &chainid.RoleAssignment{
+		RoleID:          chainid.RoleID(req.RoleID),
+		UserID:          chainid.UserID(req.UserID),
+		TeamID:          chainid.TeamID(req.TeamID),
+		EndpointGroupID: chainid.EndpointGroupID(req.EndpointGroupID),
+	}
+
+	code, err := handler.checkRoleAssignmentReferences(assignment)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, code, handler.Logger)
+		return
+	}
+
+	assignments, err := handler.RoleAssignmentService.RoleAssignments()
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	for _, existing := range assignments {
+		if existing.RoleID == assignment.RoleID && existing.UserID == assignment.UserID &&
+			existing.TeamID == assignment.TeamID && existing.EndpointGroupID == assignment.EndpointGroupID {
+			httperror.WriteErrorResponse(w, chainid.ErrRoleAssignmentAlreadyExists, http.StatusConflict, handler.Logger)
+			return
+		}
+	}
+
+	err = handler.RoleAssignmentService.CreateRoleAssignment(assignment)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	encodeJSON(w, &postRoleAssignmentsResponse{ID: int(assignment.ID)}, handler.Logger)
+}
+
+// checkRoleAssignmentReferences ensures that the role, the user or team and the endpoint group
+// referenced by the assignment exist. It returns the HTTP status code to use alongside the error.
+func (handler *RoleAssignmentHandler) checkRoleAssignmentReferences(assignment *chainid.RoleAssignment) (int, error) {
+	_, err := handler.RoleService.Role(assignment.RoleID)
+	if err == chainid.ErrRoleNotFound {
+		return http.StatusNotFound, err
+	} else if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	if assignment.UserID != 0 {
+		_, err = handler.UserService.User(assignment.UserID)
+		if err == chainid.ErrUserNotFound {
+			return http.StatusNotFound, err
+		} else if err != nil {
+			return http.StatusInternalServerError, err
+		}
+	}
+
+	if assignment.TeamID != 0 {
+		_, err = handler.TeamService.Team(assignment.TeamID)
+		if err == chainid.ErrTeamNotFound {
+			return http.StatusNotFound, err
+		} else if err != nil {
+			return http.StatusInternalServerError, err
+		}
+	}
+
+	if assignment.EndpointGroupID != 0 {
+		_, err = handler.EndpointGroupService.EndpointGroup(assignment.EndpointGroupID)
+		if err == chainid.ErrEndpointGroupNotFound {
+			return http.StatusNotFound, err
+		} else if err != nil {
+			return http.StatusInternalServerError, err
+		}
+	}
+
+	return http.StatusOK, nil
+}
+
+// handleGetRoleAssignments handles GET requests on /role_assignments
+func (handler *RoleAssignmentHandler) handleGetRoleAssignments(w http.ResponseWriter, r *http.Request) {
+	assignments, err := handler.RoleAssignmentService.RoleAssignments()
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	encodeJSON(w, assignments, handler.Logger)
+}
+
+// handleDeleteRoleAssignment handles DELETE requests on /role_assignments/:id
+func (handler *RoleAssignmentHandler) handleDeleteRoleAssignment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	assignmentID, err := strconv.Atoi(id)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	_, err = handler.RoleAssignmentService.RoleAssignment(chainid.RoleAssignmentID(assignmentID))
+	if err == chainid.ErrRoleAssignmentNotFound {
+		httperror.WriteErrorResponse(w, err, http.StatusNotFound, handler.Logger)
+		return
+	} else if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	err = handler.RoleAssignmentService.DeleteRoleAssignment(chainid.RoleAssignmentID(assignmentID))
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+}
@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chainid-io/dashboard"
+	httperror "github.com/chainid-io/dashboard/http/error"
+	"github.com/chainid-io/dashboard/http/proxy"
+	"github.com/chainid-io/dashboard/http/security"
+	"github.com/chainid-io/dashboard/search"
+
+	"github.com/gorilla/mux"
+)
+
+const defaultSearchLimit = 50
+
+// SearchHandler represents an HTTP API handler for searching the endpoints, stacks, registries
+// and Docker resources accessible to the user.
+type SearchHandler struct {
+	*mux.Router
+	Logger                 *log.Logger
+	SearchIndex            *search.Index
+	EndpointGroupService   chainid.EndpointGroupService
+	StackService           chainid.StackService
+	RegistryService        chainid.RegistryService
+	ResourceControlService chainid.ResourceControlService
+	SettingsService        chainid.SettingsService
+}
+
+// NewSearchHandler returns a new instance of SearchHandler.
+func NewSearchHandler(bouncer *security.RequestBouncer) *SearchHandler {
+	h := &SearchHandler{
+		Router: mux.NewRouter(),
+		Logger: log.New(os.Stderr, "", log.LstdFlags),
+	}
+	h.Handle("/search",
+		bouncer.RestrictedAccess(http.HandlerFunc(h.handleGetSearch))).Methods(http.MethodGet)
+
+	return h
+}
+
+// handleGetSearch handles GET requests on /search?q=<query>&limit=<limit>
+// The Docker resources are searched in the last snapshot of the endpoints, the resources with a
+// blacklisted label are ignored. The stacks are only returned when they are deployed on the Swarm
+// cluster of an endpoint accessible to the user. The results are sorted by decreasing score and
+// limited to 50 results by default.
+func (handler *SearchHandler) handleGetSearch(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.FormValue("q"))
+	if query == "" {
+		httperror.WriteErrorResponse(w, ErrInvalidQueryFormat, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	limit := defaultSearchLimit
+	if value := r.FormValue("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			httperror.WriteErrorResponse(w, ErrInvalidQueryFormat, http.StatusBadRequest, handler.Logger)
+			return
+		}
+	}
+
+	securityContext, err := security.RetrieveRestrictedRequestContext(r)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	endpoints, err := handler.SearchIndex.Endpoints()
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	groups, err := handler.EndpointGroupService.EndpointGroups()
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	filteredEndpoints, err := security.FilterEndpoints(endpoints, groups, securityContext)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	resourceControls, err := handler.ResourceControlService.ResourceControls()
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	results := make([]chainid.SearchResult, 0)
+	endpointIDs := make([]chainid.EndpointID, 0)
+	for _, endpoint := range filteredEndpoints {
+		endpointIDs = append(endpointIDs, endpoint.ID)
+		results = appendSearchResult(results, query, chainid.SearchResult{
+			Type: chainid.EndpointSearchResult,
+			ID:   strconv.Itoa(int(endpoint.ID)),
+			Name: endpoint.Name,
+			URL:  endpoint.URL,
+		})
+	}
+
+	settings, err := handler.SettingsService.Settings()
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	resources, err := handler.SearchIndex.Search(query, endpointIDs, settings.BlackListedLabels, func(resourceIDs []string) bool {
+		return proxy.CanAccessResource(resourceIDs, resourceControls, securityContext.IsAdmin,
+			securityContext.UserID, securityContext.UserMemberships)
+	})
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+	results = append(results, resources...)
+
+	stacks, err := handler.StackService.Stacks()
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	stacks = filterStacksBySwarm(stacks, filteredEndpoints)
+	filteredStacks := proxy.FilterStacks(stacks, resourceControls, securityContext.IsAdmin,
+		securityContext.UserID, securityContext.UserMemberships)
+	for _, stack := range filteredStacks {
+		results = appendSearchResult(results, query, chainid.SearchResult{
+			Type: chainid.StackSearchResult,
+			ID:   string(stack.ID),
+			Name: stack.Name,
+		})
+	}
+
+	registries, err := handler.RegistryService.Registries()
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	filteredRegistries, err := security.FilterRegistries(registries, securityContext)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	for _, registry := range filteredRegistries {
+		results = appendSearchResult(results, query, chainid.SearchResult{
+			Type: chainid.RegistrySearchResult,
+			ID:   strconv.Itoa(int(registry.ID)),
+			Name: registry.Name,
+			URL:  registry.URL,
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Name < results[j].Name
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	encodeJSON(w, results, handler.Logger)
+}
+
+// filterStacksBySwarm returns the stacks deployed on the Swarm clusters of the endpoints,
+// the cluster of an endpoint is known from its last snapshot.
+func filterStacksBySwarm(stacks []chainid.Stack, endpoints []chainid.Endpoint) []chainid.Stack {
+	swarmIDs := make(map[string]bool)
+	for _, endpoint := range endpoints {
+		for _, snapshot := range endpoint.Snapshots {
+			if snapshot.SwarmID != "" {
+				swarmIDs[snapshot.SwarmID] = true
+			}
+		}
+	}
+
+	filteredStacks := make([]chainid.Stack, 0)
+	for _, stack := range stacks {
+		if swarmIDs[stack.SwarmID] {
+			filteredStacks = append(filteredStacks, stack)
+		}
+	}
+	return filteredStacks
+}
+
+// appendSearchResult appends a result to the results when it matches the query.
+func appendSearchResult(results []chainid.SearchResult, query string, result chainid.SearchResult) []chainid.SearchResult {
+	result.Score = search.Score(query, &result)
+	if result.Score > 0 {
+		results = append(results, result)
+	}
+	return results
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/http/security"
+	"github.com/chainid-io/dashboard/jwt"
+	"github.com/chainid-io/dashboard/kv"
+	"github.com/chainid-io/dashboard/search"
+)
+
+func TestSearchStacksOfInaccessibleEndpoints(t *testing.T) {
+	store := kv.NewStore(kv.NewMemoryBackend())
+	err := store.SettingsService.StoreSettings(&chainid.Settings{AuthenticationMethod: chainid.AuthenticationInternal})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := &chainid.User{Username: "alice", Role: chainid.StandardUserRole}
+	err = store.UserService.CreateUser(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	accessible := &chainid.EndpointGroup{Name: "accessible", AuthorizedUsers: []chainid.UserID{user.ID}, AuthorizedTeams: []chainid.TeamID{}}
+	restricted := &chainid.EndpointGroup{Name: "restricted", AuthorizedUsers: []chainid.UserID{}, AuthorizedTeams: []chainid.TeamID{}}
+	for _, group := range []*chainid.EndpointGroup{accessible, restricted} {
+		err = store.EndpointGroupService.CreateEndpointGroup(group)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	endpoints := []*chainid.Endpoint{
+		{Name: "production", Type: chainid.DockerEnvironment, GroupID: accessible.ID, Snapshots: []chainid.Snapshot{{Swarm: true, SwarmID: "swarm1"}}},
+		{Name: "finance", Type: chainid.DockerEnvironment, GroupID: restricted.ID, Snapshots: []chainid.Snapshot{{Swarm: true, SwarmID: "swarm2"}}},
+	}
+	for _, endpoint := range endpoints {
+		err = store.EndpointService.CreateEndpoint(endpoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, stack := range []*chainid.Stack{
+		{ID: "web_swarm1", Name: "web", SwarmID: "swarm1"},
+		{ID: "webpayroll_swarm2", Name: "webpayroll", SwarmID: "swarm2"},
+	} {
+		err = store.StackService.CreateStack(stack)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	secret, err := jwt.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtService, err := jwt.NewService(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := jwtService.GenerateToken(&chainid.TokenData{ID: user.ID, Username: user.Username, Role: user.Role})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authorizer := security.NewAuthorizer(store.RoleService, store.RoleAssignmentService, store.TeamMembershipService)
+	bouncer := security.NewRequestBouncer(jwtService, store.UserService, store.TeamMembershipService, store.SettingsService, store.EndpointService, authorizer, false)
+
+	handler := NewSearchHandler(bouncer)
+	handler.SearchIndex = search.NewIndex(store.EndpointService)
+	handler.EndpointGroupService = store.EndpointGroupService
+	handler.StackService = store.StackService
+	handler.RegistryService = store.RegistryService
+	handler.ResourceControlService = store.ResourceControlService
+	handler.SettingsService = store.SettingsService
+
+	req := httptest.NewRequest(http.MethodGet, "/search?q=web", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var results []chainid.SearchResult
+	err = json.NewDecoder(rr.Body).Decode(&results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Type != chainid.StackSearchResult || results[0].ID != "web_swarm1" {
+		t.Errorf("expected only the stack of the accessible endpoint, got %+v", results)
+	}
+}
@@ -1,177 +1,244 @@
-package handler
-
-import (
-	"encoding/json"
-
-	"github.com/asaskevich/govalidator"
-	"github.com/chainid-io/dashboard"
-	"github.com/chainid-io/dashboard/filesystem"
-	httperror "github.com/chainid-io/dashboard/http/error"
-	"github.com/chainid-io/dashboard/http/security"
-
-	"log"
-	"net/http"
-	"os"
-
-	"github.com/gorilla/mux"
-)
-
-// SettingsHandler represents an HTTP API handler for managing Settings.
-type SettingsHandler struct {
-	*mux.Router
-	Logger          *log.Logger
-	SettingsService chainid.SettingsService
-	LDAPService     chainid.LDAPService
-	FileService     chainid.FileService
-}
-
-// NewSettingsHandler returns a new instance of OldSettingsHandler.
-func NewSettingsHandler(bouncer *security.RequestBouncer) *SettingsHandler {
-	h := &SettingsHandler{
-		Router: mux.NewRouter(),
-		Logger: log.New(os.Stderr, "", log.LstdFlags),
-	}
-	h.Handle("/settings",
-		bouncer.AdministratorAccess(http.HandlerFunc(h.handleGetSettings))).Methods(http.MethodGet)
-	h.Handle("/settings",
-		bouncer.AdministratorAccess(http.HandlerFunc(h.handlePutSettings))).Methods(http.MethodPut)
-	h.Handle("/settings/public",
-		bouncer.PublicAccess(http.HandlerFunc(h.handleGetPublicSettings))).Methods(http.MethodGet)
-	h.Handle("/settings/authentication/checkLDAP",
-		bouncer.AdministratorAccess(http.HandlerFunc(h.handlePutSettingsLDAPCheck))).Methods(http.MethodPut)
-
-	return h
-}
-
-type (
-	publicSettingsResponse struct {
-		LogoURL                            string                         `json:"LogoURL"`
-		DisplayExternalContributors        bool                           `json:"DisplayExternalContributors"`
-		AuthenticationMethod               chainid.AuthenticationMethod `json:"AuthenticationMethod"`
-		AllowBindMountsForRegularUsers     bool                           `json:"AllowBindMountsForRegularUsers"`
-		AllowPrivilegedModeForRegularUsers bool                           `json:"AllowPrivilegedModeForRegularUsers"`
-	}
-
-	putSettingsRequest struct {
-		TemplatesURL                       string                 `valid:"required"`
-		LogoURL                            string                 `valid:""`
-		BlackListedLabels                  []chainid.Pair       `valid:""`
-		DisplayExternalContributors        bool                   `valid:""`
-		AuthenticationMethod               int                    `valid:"required"`
-		LDAPSettings                       chainid.LDAPSettings `valid:""`
-		AllowBindMountsForRegularUsers     bool                   `valid:""`
-		AllowPrivilegedModeForRegularUsers bool                   `valid:""`
-	}
-
-	putSettingsLDAPCheckRequest struct {
-		LDAPSettings chainid.LDAPSettings `valid:""`
-	}
-)
-
-// handleGetSettings handles GET requests on /settings
-func (handler *SettingsHandler) handleGetSettings(w http.ResponseWriter, r *http.Request) {
-	settings, err := handler.SettingsService.Settings()
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-
-	encodeJSON(w, settings, handler.Logger)
-	return
-}
-
-// handleGetPublicSettings handles GET requests on /settings/public
-func (handler *SettingsHandler) handleGetPublicSettings(w http.ResponseWriter, r *http.Request) {
-	settings, err := handler.SettingsService.Settings()
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-
-	publicSettings := &publicSettingsResponse{
-		LogoURL:                            settings.LogoURL,
-		DisplayExternalContributors:        settings.DisplayExternalContributors,
-		AuthenticationMethod:               settings.AuthenticationMethod,
-		AllowBindMountsForRegularUsers:     settings.AllowBindMountsForRegularUsers,
-		AllowPrivilegedModeForRegularUsers: settings.AllowPrivilegedModeForRegularUsers,
-	}
-
-	encodeJSON(w, publicSettings, handler.Logger)
-	return
-}
-
-// handlePutSettings handles PUT requests on /settings
-func (handler *SettingsHandler) handlePutSettings(w http.ResponseWriter, r *http.Request) {
-	var req putSettingsRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httperror.WriteErrorResponse(w, ErrInvalidJSON, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	_, err := govalidator.ValidateStruct(req)
-	if err != nil {
-		httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	settings := &chainid.Settings{
-		TemplatesURL:                       req.TemplatesURL,
-		LogoURL:                            req.LogoURL,
-		BlackListedLabels:                  req.BlackListedLabels,
-		DisplayExternalContributors:        req.DisplayExternalContributors,
-		LDAPSettings:                       req.LDAPSettings,
-		AllowBindMountsForRegularUsers:     req.AllowBindMountsForRegularUsers,
-		AllowPrivilegedModeForRegularUsers: req.AllowPrivilegedModeForRegularUsers,
-	}
-
-	if req.AuthenticationMethod == 1 {
-		settings.AuthenticationMethod = chainid.AuthenticationInternal
-	} else if req.AuthenticationMethod == 2 {
-		settings.AuthenticationMethod = chainid.AuthenticationLDAP
-	} else {
-		httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	if (settings.LDAPSettings.TLSConfig.TLS || settings.LDAPSettings.StartTLS) && !settings.LDAPSettings.TLSConfig.TLSSkipVerify {
-		caCertPath, _ := handler.FileService.GetPathForTLSFile(filesystem.LDAPStorePath, chainid.TLSFileCA)
-		settings.LDAPSettings.TLSConfig.TLSCACertPath = caCertPath
-	} else {
-		settings.LDAPSettings.TLSConfig.TLSCACertPath = ""
-		err := handler.FileService.DeleteTLSFiles(filesystem.LDAPStorePath)
-		if err != nil {
-			httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		}
-	}
-
-	err = handler.SettingsService.StoreSettings(settings)
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-	}
-}
-
-// handlePutSettingsLDAPCheck handles PUT requests on /settings/ldap/check
-func (handler *SettingsHandler) handlePutSettingsLDAPCheck(w http.ResponseWriter, r *http.Request) {
-	var req putSettingsLDAPCheckRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httperror.WriteErrorResponse(w, ErrInvalidJSON, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	_, err := govalidator.ValidateStruct(req)
-	if err != nil {
-		httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	if (req.LDAPSettings.TLSConfig.TLS || req.LDAPSettings.StartTLS) && !req.LDAPSettings.TLSConfig.TLSSkipVerify {
-		caCertPath, _ := handler.FileService.GetPathForTLSFile(filesystem.LDAPStorePath, chainid.TLSFileCA)
-		req.LDAPSettings.TLSConfig.TLSCACertPath = caCertPath
-	}
-
-	err = handler.LDAPService.TestConnectivity(&req.LDAPSettings)
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-}
+package handler
+
+import (
+	"encoding/json"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/filesystem"
+	httperror "github.com/chainid-io/dashboard/http/error"
+	"github.com/chainid-io/dashboard/http/security"
+
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/gorilla/mux"
+)
+
+// SettingsHandler represents an HTTP API handler for managing Settings.
+type SettingsHandler struct {
+	*mux.Router
+	Logger          *log.Logger
+	SettingsService chainid.SettingsService
+	LDAPService     chainid.LDAPService
+	FileService     chainid.FileService
+	BackupScheduler chainid.BackupScheduler
+	// secretsEncrypted is set when a master key is specified, two-factor authentication
+	// cannot be enforced otherwise since the TOTP secrets would be stored in plain text.
+	secretsEncrypted bool
+}
+
+// NewSettingsHandler returns a new instance of OldSettingsHandler.
+func NewSettingsHandler(bouncer *security.RequestBouncer, secretsEncrypted bool) *SettingsHandler {
+	h := &SettingsHandler{
+		Router:           mux.NewRouter(),
+		Logger:           log.New(os.Stderr, "", log.LstdFlags),
+		secretsEncrypted: secretsEncrypted,
+	}
+	h.Handle("/settings",
+		bouncer.PermissionAccess(http.HandlerFunc(h.handleGetSettings), chainid.SettingsManagePermission)).Methods(http.MethodGet)
+	h.Handle("/settings",
+		bouncer.PermissionAccess(http.HandlerFunc(h.handlePutSettings), chainid.SettingsManagePermission)).Methods(http.MethodPut)
+	h.Handle("/settings/public",
+		bouncer.PublicAccess(http.HandlerFunc(h.handleGetPublicSettings))).Methods(http.MethodGet)
+	h.Handle("/settings/authentication/checkLDAP",
+		bouncer.PermissionAccess(http.HandlerFunc(h.handlePutSettingsLDAPCheck), chainid.SettingsManagePermission)).Methods(http.MethodPut)
+
+	return h
+}
+
+type (
+	publicSettingsResponse struct {
+		LogoURL                            string                         `json:"LogoURL"`
+		DisplayExternalContributors        bool                           `json:"DisplayExternalContributors"`
+		AuthenticationMethod               chainid.AuthenticationMethod `json:"AuthenticationMethod"`
+		AllowBindMountsForRegularUsers     bool                           `json:"AllowBindMountsForRegularUsers"`
+		AllowPrivilegedModeForRegularUsers bool                           `json:"AllowPrivilegedModeForRegularUsers"`
+		PasswordPolicy                     chainid.PasswordPolicy         `json:"PasswordPolicy"`
+	}
+
+	putSettingsRequest struct {
+		TemplatesURL                       string                 `valid:"required"`
+		LogoURL                            string                 `valid:""`
+		BlackListedLabels                  []chainid.Pair       `valid:""`
+		DisplayExternalContributors        bool                   `valid:""`
+		AuthenticationMethod               int                    `valid:"required"`
+		LDAPSettings                       chainid.LDAPSettings `valid:""`
+		AllowBindMountsForRegularUsers     bool                   `valid:""`
+		AllowPrivilegedModeForRegularUsers bool                   `valid:""`
+		EnforceTwoFactorAuthentication     bool                   `valid:""`
+		LockoutSettings                    chainid.LockoutSettings `valid:""`
+		PasswordPolicy                     chainid.PasswordPolicy  `valid:""`
+		BackupSettings                     chainid.BackupSettings  `valid:""`
+	}
+
+	putSettingsLDAPCheckRequest struct {
+		LDAPSettings chainid.LDAPSettings `valid:""`
+	}
+)
+
+// handleGetSettings handles GET requests on /settings
+func (handler *SettingsHandler) handleGetSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := handler.SettingsService.Settings()
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	hideSettingsSensitiveFields(settings)
+	encodeJSON(w, settings, handler.Logger)
+	return
+}
+
+// handleGetPublicSettings handles GET requests on /settings/public
+func (handler *SettingsHandler) handleGetPublicSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := handler.SettingsService.Settings()
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	publicSettings := &publicSettingsResponse{
+		LogoURL:                            settings.LogoURL,
+		DisplayExternalContributors:        settings.DisplayExternalContributors,
+		AuthenticationMethod:               settings.AuthenticationMethod,
+		AllowBindMountsForRegularUsers:     settings.AllowBindMountsForRegularUsers,
+		AllowPrivilegedModeForRegularUsers: settings.AllowPrivilegedModeForRegularUsers,
+		PasswordPolicy:                     settings.PasswordPolicy,
+	}
+
+	encodeJSON(w, publicSettings, handler.Logger)
+	return
+}
+
+// handlePutSettings handles PUT requests on /settings
+func (handler *SettingsHandler) handlePutSettings(w http.ResponseWriter, r *http.Request) {
+	var req putSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidJSON, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	_, err := govalidator.ValidateStruct(req)
+	if err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	settings := &chainid.Settings{
+		TemplatesURL:                       req.TemplatesURL,
+		LogoURL:                            req.LogoURL,
+		BlackListedLabels:                  req.BlackListedLabels,
+		DisplayExternalContributors:        req.DisplayExternalContributors,
+		LDAPSettings:                       req.LDAPSettings,
+		AllowBindMountsForRegularUsers:     req.AllowBindMountsForRegularUsers,
+		AllowPrivilegedModeForRegularUsers: req.AllowPrivilegedModeForRegularUsers,
+		EnforceTwoFactorAuthentication:     req.EnforceTwoFactorAuthentication,
+		LockoutSettings:                    req.LockoutSettings,
+		PasswordPolicy:                     req.PasswordPolicy,
+		BackupSettings:                     req.BackupSettings,
+	}
+
+	currentSettings, err := handler.SettingsService.Settings()
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+	keepSettingsSensitiveFields(settings, currentSettings)
+
+	if settings.EnforceTwoFactorAuthentication && !currentSettings.EnforceTwoFactorAuthentication && !handler.secretsEncrypted {
+		httperror.WriteErrorResponse(w, chainid.ErrTOTPRequiresMasterKey, http.StatusServiceUnavailable, handler.Logger)
+		return
+	}
+
+	if req.AuthenticationMethod == 1 {
+		settings.AuthenticationMethod = chainid.AuthenticationInternal
+	} else if req.AuthenticationMethod == 2 {
+		settings.AuthenticationMethod = chainid.AuthenticationLDAP
+	} else {
+		httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	if (settings.LDAPSettings.TLSConfig.TLS || settings.LDAPSettings.StartTLS) && !settings.LDAPSettings.TLSConfig.TLSSkipVerify {
+		caCertPath, _ := handler.FileService.GetPathForTLSFile(filesystem.LDAPStorePath, chainid.TLSFileCA)
+		settings.LDAPSettings.TLSConfig.TLSCACertPath = caCertPath
+	} else {
+		settings.LDAPSettings.TLSConfig.TLSCACertPath = ""
+		err := handler.FileService.DeleteTLSFiles(filesystem.LDAPStorePath)
+		if err != nil {
+			httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		}
+	}
+
+	err = handler.BackupScheduler.ScheduleBackups(&settings.BackupSettings)
+	if err == chainid.ErrInvalidBackupSchedule || err == chainid.ErrInvalidBackupTarget {
+		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+		return
+	} else if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	err = handler.SettingsService.StoreSettings(settings)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+	}
+}
+
+// handlePutSettingsLDAPCheck handles PUT requests on /settings/ldap/check
+func (handler *SettingsHandler) handlePutSettingsLDAPCheck(w http.ResponseWriter, r *http.Request) {
+	var req putSettingsLDAPCheckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidJSON, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	_, err := govalidator.ValidateStruct(req)
+	if err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	if (req.LDAPSettings.TLSConfig.TLS || req.LDAPSettings.StartTLS) && !req.LDAPSettings.TLSConfig.TLSSkipVerify {
+		caCertPath, _ := handler.FileService.GetPathForTLSFile(filesystem.LDAPStorePath, chainid.TLSFileCA)
+		req.LDAPSettings.TLSConfig.TLSCACertPath = caCertPath
+	}
+
+	if req.LDAPSettings.Password == "" {
+		settings, err := handler.SettingsService.Settings()
+		if err != nil {
+			httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+			return
+		}
+		req.LDAPSettings.Password = settings.LDAPSettings.Password
+	}
+
+	err = handler.LDAPService.TestConnectivity(&req.LDAPSettings)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+}
+
+// hideSettingsSensitiveFields removes the credentials stored in the settings before sending them in a response.
+func hideSettingsSensitiveFields(settings *chainid.Settings) {
+	settings.LDAPSettings.Password = ""
+	settings.BackupSettings.Password = ""
+	settings.BackupSettings.S3.SecretAccessKey = ""
+}
+
+// keepSettingsSensitiveFields keeps the stored credentials that are not specified in the updated settings,
+// since they are never sent to the clients.
+func keepSettingsSensitiveFields(settings, current *chainid.Settings) {
+	if settings.LDAPSettings.Password == "" {
+		settings.LDAPSettings.Password = current.LDAPSettings.Password
+	}
+	if settings.BackupSettings.Password == "" {
+		settings.BackupSettings.Password = current.BackupSettings.Password
+	}
+	if settings.BackupSettings.S3.SecretAccessKey == "" {
+		settings.BackupSettings.S3.SecretAccessKey = current.BackupSettings.S3.SecretAccessKey
+	}
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/chainid-io/dashboard"
+	httperror "github.com/chainid-io/dashboard/http/error"
+	"github.com/chainid-io/dashboard/http/security"
+	"github.com/chainid-io/dashboard/tunnel"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+// TunnelHandler represents an HTTP API handler for the reverse tunnels opened by the edge agents.
+type TunnelHandler struct {
+	*mux.Router
+	Logger             *log.Logger
+	EndpointService    chainid.EndpointService
+	TunnelService      *tunnel.Service
+	connectionUpgrader websocket.Upgrader
+}
+
+// NewTunnelHandler returns a new instance of TunnelHandler.
+func NewTunnelHandler(bouncer *security.RequestBouncer) *TunnelHandler {
+	h := &TunnelHandler{
+		Router:             mux.NewRouter(),
+		Logger:             log.New(os.Stderr, "", log.LstdFlags),
+		connectionUpgrader: websocket.Upgrader{},
+	}
+	h.Handle("/tunnel",
+		bouncer.PublicAccess(http.HandlerFunc(h.handleTunnel))).Methods(http.MethodGet)
+
+	return h
+}
+
+// handleTunnel handles GET requests on /tunnel. The edge agent is identified by the join token
+// of its endpoint and the connection is upgraded to the websocket protocol.
+func (handler *TunnelHandler) handleTunnel(w http.ResponseWriter, r *http.Request) {
+	joinToken := r.Header.Get(chainid.EdgeJoinTokenHeader)
+	if joinToken == "" {
+		httperror.WriteErrorResponse(w, chainid.ErrInvalidEdgeJoinToken, http.StatusForbidden, handler.Logger)
+		return
+	}
+
+	endpoints, err := handler.EndpointService.Endpoints()
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	var endpoint *chainid.Endpoint
+	for i := range endpoints {
+		if endpoints[i].Type == chainid.EdgeAgentEnvironment && subtle.ConstantTimeCompare([]byte(endpoints[i].EdgeJoinToken), []byte(joinToken)) == 1 {
+			endpoint = &endpoints[i]
+			break
+		}
+	}
+	if endpoint == nil {
+		httperror.WriteErrorResponse(w, chainid.ErrInvalidEdgeJoinToken, http.StatusForbidden, handler.Logger)
+		return
+	}
+
+	conn, err := handler.connectionUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		handler.Logger.Printf("Unable to open the tunnel of endpoint %d: %s", endpoint.ID, err)
+		return
+	}
+
+	handler.TunnelService.Serve(endpoint.ID, conn)
+}
@@ -1,463 +1,909 @@
-package handler
-
-import (
-	"strconv"
-	"strings"
-
-	"github.com/chainid-io/dashboard"
-	httperror "github.com/chainid-io/dashboard/http/error"
-	"github.com/chainid-io/dashboard/http/security"
-
-	"encoding/json"
-	"log"
-	"net/http"
-	"os"
-
-	"github.com/asaskevich/govalidator"
-	"github.com/gorilla/mux"
-)
-
-// UserHandler represents an HTTP API handler for managing users.
-type UserHandler struct {
-	*mux.Router
-	Logger                 *log.Logger
-	UserService            chainid.UserService
-	TeamService            chainid.TeamService
-	TeamMembershipService  chainid.TeamMembershipService
-	ResourceControlService chainid.ResourceControlService
-	CryptoService          chainid.CryptoService
-	SettingsService        chainid.SettingsService
-}
-
-// NewUserHandler returns a new instance of UserHandler.
-func NewUserHandler(bouncer *security.RequestBouncer) *UserHandler {
-	h := &UserHandler{
-		Router: mux.NewRouter(),
-		Logger: log.New(os.Stderr, "", log.LstdFlags),
-	}
-	h.Handle("/users",
-		bouncer.RestrictedAccess(http.HandlerFunc(h.handlePostUsers))).Methods(http.MethodPost)
-	h.Handle("/users",
-		bouncer.RestrictedAccess(http.HandlerFunc(h.handleGetUsers))).Methods(http.MethodGet)
-	h.Handle("/users/{id}",
-		bouncer.AdministratorAccess(http.HandlerFunc(h.handleGetUser))).Methods(http.MethodGet)
-	h.Handle("/users/{id}",
-		bouncer.AuthenticatedAccess(http.HandlerFunc(h.handlePutUser))).Methods(http.MethodPut)
-	h.Handle("/users/{id}",
-		bouncer.AdministratorAccess(http.HandlerFunc(h.handleDeleteUser))).Methods(http.MethodDelete)
-	h.Handle("/users/{id}/memberships",
-		bouncer.AuthenticatedAccess(http.HandlerFunc(h.handleGetMemberships))).Methods(http.MethodGet)
-	h.Handle("/users/{id}/passwd",
-		bouncer.AuthenticatedAccess(http.HandlerFunc(h.handlePostUserPasswd))).Methods(http.MethodPost)
-	h.Handle("/users/admin/check",
-		bouncer.PublicAccess(http.HandlerFunc(h.handleGetAdminCheck))).Methods(http.MethodGet)
-	h.Handle("/users/admin/init",
-		bouncer.PublicAccess(http.HandlerFunc(h.handlePostAdminInit))).Methods(http.MethodPost)
-
-	return h
-}
-
-type (
-	postUsersRequest struct {
-		Username string `valid:"required"`
-		Password string `valid:""`
-		Role     int    `valid:"required"`
-	}
-
-	postUsersResponse struct {
-		ID int `json:"Id"`
-	}
-
-	postUserPasswdRequest struct {
-		Password string `valid:"required"`
-	}
-
-	postUserPasswdResponse struct {
-		Valid bool `json:"valid"`
-	}
-
-	putUserRequest struct {
-		Password string `valid:"-"`
-		Role     int    `valid:"-"`
-	}
-
-	postAdminInitRequest struct {
-		Username string `valid:"required"`
-		Password string `valid:"required"`
-	}
-)
-
-// handlePostUsers handles POST requests on /users
-func (handler *UserHandler) handlePostUsers(w http.ResponseWriter, r *http.Request) {
-	var req postUsersRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httperror.WriteErrorResponse(w, ErrInvalidJSON, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	_, err := govalidator.ValidateStruct(req)
-	if err != nil {
-		httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	securityContext, err := security.RetrieveRestrictedRequestContext(r)
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-
-	if !securityContext.IsAdmin && !securityContext.IsTeamLeader {
-		httperror.WriteErrorResponse(w, chainid.ErrResourceAccessDenied, http.StatusForbidden, nil)
-		return
-	}
-
-	if securityContext.IsTeamLeader && req.Role == 1 {
-		httperror.WriteErrorResponse(w, chainid.ErrResourceAccessDenied, http.StatusForbidden, nil)
-		return
-	}
-
-	if strings.ContainsAny(req.Username, " ") {
-		httperror.WriteErrorResponse(w, chainid.ErrInvalidUsername, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	user, err := handler.UserService.UserByUsername(req.Username)
-	if err != nil && err != chainid.ErrUserNotFound {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-	if user != nil {
-		httperror.WriteErrorResponse(w, chainid.ErrUserAlreadyExists, http.StatusConflict, handler.Logger)
-		return
-	}
-
-	var role chainid.UserRole
-	if req.Role == 1 {
-		role = chainid.AdministratorRole
-	} else {
-		role = chainid.StandardUserRole
-	}
-
-	user = &chainid.User{
-		Username: req.Username,
-		Role:     role,
-	}
-
-	settings, err := handler.SettingsService.Settings()
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-
-	if settings.AuthenticationMethod == chainid.AuthenticationInternal {
-		user.Password, err = handler.CryptoService.Hash(req.Password)
-		if err != nil {
-			httperror.WriteErrorResponse(w, chainid.ErrCryptoHashFailure, http.StatusBadRequest, handler.Logger)
-			return
-		}
-	}
-
-	err = handler.UserService.CreateUser(user)
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-
-	encodeJSON(w, &postUsersResponse{ID: int(user.ID)}, handler.Logger)
-}
-
-// handleGetUsers handles GET requests on /users
-func (handler *UserHandler) handleGetUsers(w http.ResponseWriter, r *http.Request) {
-	securityContext, err := security.RetrieveRestrictedRequestContext(r)
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-
-	users, err := handler.UserService.Users()
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-
-	filteredUsers := security.FilterUsers(users, securityContext)
-
-	for i := range filteredUsers {
-		filteredUsers[i].Password = ""
-	}
-
-	encodeJSON(w, filteredUsers, handler.Logger)
-}
-
-// handlePostUserPasswd handles POST requests on /users/:id/passwd
-func (handler *UserHandler) handlePostUserPasswd(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id := vars["id"]
-
-	userID, err := strconv.Atoi(id)
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	var req postUserPasswdRequest
-	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httperror.WriteErrorResponse(w, ErrInvalidJSON, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	_, err = govalidator.ValidateStruct(req)
-	if err != nil {
-		httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	var password = req.Password
-
-	u, err := handler.UserService.User(chainid.UserID(userID))
-	if err == chainid.ErrUserNotFound {
-		httperror.WriteErrorResponse(w, err, http.StatusNotFound, handler.Logger)
-		return
-	} else if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-
-	valid := true
-	err = handler.CryptoService.CompareHashAndData(u.Password, password)
-	if err != nil {
-		valid = false
-	}
-
-	encodeJSON(w, &postUserPasswdResponse{Valid: valid}, handler.Logger)
-}
-
-// handleGetUser handles GET requests on /users/:id
-func (handler *UserHandler) handleGetUser(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id := vars["id"]
-
-	userID, err := strconv.Atoi(id)
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	user, err := handler.UserService.User(chainid.UserID(userID))
-	if err == chainid.ErrUserNotFound {
-		httperror.WriteErrorResponse(w, err, http.StatusNotFound, handler.Logger)
-		return
-	} else if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-
-	user.Password = ""
-	encodeJSON(w, &user, handler.Logger)
-}
-
-// handlePutUser handles PUT requests on /users/:id
-func (handler *UserHandler) handlePutUser(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id := vars["id"]
-
-	userID, err := strconv.Atoi(id)
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	tokenData, err := security.RetrieveTokenData(r)
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-
-	if tokenData.Role != chainid.AdministratorRole && tokenData.ID != chainid.UserID(userID) {
-		httperror.WriteErrorResponse(w, chainid.ErrUnauthorized, http.StatusForbidden, handler.Logger)
-		return
-	}
-
-	var req putUserRequest
-	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httperror.WriteErrorResponse(w, ErrInvalidJSON, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	_, err = govalidator.ValidateStruct(req)
-	if err != nil {
-		httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	if req.Password == "" && req.Role == 0 {
-		httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	user, err := handler.UserService.User(chainid.UserID(userID))
-	if err == chainid.ErrUserNotFound {
-		httperror.WriteErrorResponse(w, err, http.StatusNotFound, handler.Logger)
-		return
-	} else if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-
-	if req.Password != "" {
-		user.Password, err = handler.CryptoService.Hash(req.Password)
-		if err != nil {
-			httperror.WriteErrorResponse(w, chainid.ErrCryptoHashFailure, http.StatusBadRequest, handler.Logger)
-			return
-		}
-	}
-
-	if req.Role != 0 {
-		if tokenData.Role != chainid.AdministratorRole {
-			httperror.WriteErrorResponse(w, chainid.ErrUnauthorized, http.StatusForbidden, handler.Logger)
-			return
-		}
-		if req.Role == 1 {
-			user.Role = chainid.AdministratorRole
-		} else {
-			user.Role = chainid.StandardUserRole
-		}
-	}
-
-	err = handler.UserService.UpdateUser(user.ID, user)
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-}
-
-// handleGetAdminCheck handles GET requests on /users/admin/check
-func (handler *UserHandler) handleGetAdminCheck(w http.ResponseWriter, r *http.Request) {
-	users, err := handler.UserService.UsersByRole(chainid.AdministratorRole)
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-	if len(users) == 0 {
-		httperror.WriteErrorResponse(w, chainid.ErrUserNotFound, http.StatusNotFound, handler.Logger)
-		return
-	}
-}
-
-// handlePostAdminInit handles POST requests on /users/admin/init
-func (handler *UserHandler) handlePostAdminInit(w http.ResponseWriter, r *http.Request) {
-	var req postAdminInitRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httperror.WriteErrorResponse(w, ErrInvalidJSON, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	_, err := govalidator.ValidateStruct(req)
-	if err != nil {
-		httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	users, err := handler.UserService.UsersByRole(chainid.AdministratorRole)
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-	if len(users) == 0 {
-		user := &chainid.User{
-			Username: req.Username,
-			Role:     chainid.AdministratorRole,
-		}
-		user.Password, err = handler.CryptoService.Hash(req.Password)
-		if err != nil {
-			httperror.WriteErrorResponse(w, chainid.ErrCryptoHashFailure, http.StatusBadRequest, handler.Logger)
-			return
-		}
-
-		err = handler.UserService.CreateUser(user)
-		if err != nil {
-			httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-			return
-		}
-	} else {
-		httperror.WriteErrorResponse(w, chainid.ErrAdminAlreadyInitialized, http.StatusConflict, handler.Logger)
-		return
-	}
-}
-
-// handleDeleteUser handles DELETE requests on /users/:id
-func (handler *UserHandler) handleDeleteUser(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id := vars["id"]
-
-	userID, err := strconv.Atoi(id)
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	tokenData, err := security.RetrieveTokenData(r)
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-
-	if tokenData.ID == chainid.UserID(userID) {
-		httperror.WriteErrorResponse(w, chainid.ErrAdminCannotRemoveSelf, http.StatusForbidden, handler.Logger)
-		return
-	}
-
-	_, err = handler.UserService.User(chainid.UserID(userID))
-
-	if err == chainid.ErrUserNotFound {
-		httperror.WriteErrorResponse(w, err, http.StatusNotFound, handler.Logger)
-		return
-	} else if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-
-	err = handler.UserService.DeleteUser(chainid.UserID(userID))
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-
-	err = handler.TeamMembershipService.DeleteTeamMembershipByUserID(chainid.UserID(userID))
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-}
-
-// handleGetMemberships handles GET requests on /users/:id/memberships
-func (handler *UserHandler) handleGetMemberships(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id := vars["id"]
-
-	userID, err := strconv.Atoi(id)
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
-		return
-	}
-
-	tokenData, err := security.RetrieveTokenData(r)
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-
-	if tokenData.Role != chainid.AdministratorRole && tokenData.ID != chainid.UserID(userID) {
-		httperror.WriteErrorResponse(w, chainid.ErrUnauthorized, http.StatusForbidden, handler.Logger)
-		return
-	}
-
-	memberships, err := handler.TeamMembershipService.TeamMembershipsByUserID(chainid.UserID(userID))
-	if err != nil {
-		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
-		return
-	}
-
-	encodeJSON(w, memberships, handler.Logger)
-}
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/chainid-io/dashboard"
+	httperror "github.com/chainid-io/dashboard/http/error"
+	"github.com/chainid-io/dashboard/http/security"
+
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/gorilla/mux"
+)
+
+// UserHandler represents an HTTP API handler for managing users.
+type UserHandler struct {
+	*mux.Router
+	Logger                 *log.Logger
+	UserService            chainid.UserService
+	TeamService            chainid.TeamService
+	TeamMembershipService  chainid.TeamMembershipService
+	ResourceControlService chainid.ResourceControlService
+	CryptoService          chainid.CryptoService
+	SettingsService        chainid.SettingsService
+	TOTPService            chainid.TOTPService
+	RoleAssignmentService  chainid.RoleAssignmentService
+	// secretsEncrypted is set when a master key is specified, the TOTP secrets are
+	// only stored when they can be encrypted.
+	secretsEncrypted bool
+}
+
+// userRecoveryCodesCount is the number of recovery codes generated when enabling two-factor authentication.
+const userRecoveryCodesCount = 10
+
+// NewUserHandler returns a new instance of UserHandler.
+func NewUserHandler(bouncer *security.RequestBouncer, secretsEncrypted bool) *UserHandler {
+	h := &UserHandler{
+		Router:           mux.NewRouter(),
+		Logger:           log.New(os.Stderr, "", log.LstdFlags),
+		secretsEncrypted: secretsEncrypted,
+	}
+	h.Handle("/users",
+		bouncer.RestrictedAccess(http.HandlerFunc(h.handlePostUsers))).Methods(http.MethodPost)
+	h.Handle("/users",
+		bouncer.RestrictedAccess(http.HandlerFunc(h.handleGetUsers))).Methods(http.MethodGet)
+	h.Handle("/users/{id}",
+		bouncer.PermissionAccess(http.HandlerFunc(h.handleGetUser), chainid.UserManagePermission)).Methods(http.MethodGet)
+	h.Handle("/users/{id}",
+		bouncer.AuthenticatedAccess(http.HandlerFunc(h.handlePutUser))).Methods(http.MethodPut)
+	h.Handle("/users/{id}",
+		bouncer.PermissionAccess(http.HandlerFunc(h.handleDeleteUser), chainid.UserManagePermission)).Methods(http.MethodDelete)
+	h.Handle("/users/{id}/memberships",
+		bouncer.AuthenticatedAccess(http.HandlerFunc(h.handleGetMemberships))).Methods(http.MethodGet)
+	h.Handle("/users/{id}/passwd",
+		bouncer.AccountSetupAccess(http.HandlerFunc(h.handlePostUserPasswd))).Methods(http.MethodPost)
+	h.Handle("/users/{id}/totp",
+		bouncer.AccountSetupAccess(http.HandlerFunc(h.handlePostUserTOTP))).Methods(http.MethodPost)
+	h.Handle("/users/{id}/totp",
+		bouncer.AccountSetupAccess(http.HandlerFunc(h.handleDeleteUserTOTP))).Methods(http.MethodDelete)
+	h.Handle("/users/{id}/totp/verify",
+		bouncer.AccountSetupAccess(http.HandlerFunc(h.handlePostUserTOTPVerify))).Methods(http.MethodPost)
+	h.Handle("/users/{id}/totp/reset",
+		bouncer.PermissionAccess(http.HandlerFunc(h.handlePostUserTOTPReset), chainid.UserManagePermission)).Methods(http.MethodPost)
+	h.Handle("/users/{id}/unlock",
+		bouncer.PermissionAccess(http.HandlerFunc(h.handlePostUserUnlock), chainid.UserManagePermission)).Methods(http.MethodPost)
+	h.Handle("/users/admin/check",
+		bouncer.PublicAccess(http.HandlerFunc(h.handleGetAdminCheck))).Methods(http.MethodGet)
+	h.Handle("/users/admin/init",
+		bouncer.PublicAccess(http.HandlerFunc(h.handlePostAdminInit))).Methods(http.MethodPost)
+
+	return h
+}
+
+type (
+	postUsersRequest struct {
+		Username            string `valid:"required"`
+		Password            string `valid:""`
+		Role                int    `valid:"required"`
+		ForcePasswordChange bool   `valid:"-"`
+	}
+
+	postUsersResponse struct {
+		ID int `json:"Id"`
+	}
+
+	postUserPasswdRequest struct {
+		Password    string `valid:"required"`
+		NewPassword string `valid:"-"`
+	}
+
+	postUserPasswdResponse struct {
+		Valid bool `json:"valid"`
+	}
+
+	putUserRequest struct {
+		Password            string `valid:"-"`
+		Role                int    `valid:"-"`
+		ForcePasswordChange bool   `valid:"-"`
+	}
+
+	postUserTOTPResponse struct {
+		Secret          string `json:"Secret"`
+		ProvisioningURI string `json:"ProvisioningURI"`
+	}
+
+	postUserTOTPVerifyRequest struct {
+		Code string `valid:"required"`
+	}
+
+	postUserTOTPVerifyResponse struct {
+		RecoveryCodes []string `json:"RecoveryCodes"`
+	}
+
+	deleteUserTOTPRequest struct {
+		Password     string `valid:"required"`
+		Code         string `valid:"-"`
+		RecoveryCode string `valid:"-"`
+	}
+
+	postAdminInitRequest struct {
+		Username string `valid:"required"`
+		Password string `valid:"required"`
+	}
+)
+
+// handlePostUsers handles POST requests on /users
+func (handler *UserHandler) handlePostUsers(w http.ResponseWriter, r *http.Request) {
+	var req postUsersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidJSON, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	_, err := govalidator.ValidateStruct(req)
+	if err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	securityContext, err := security.RetrieveRestrictedRequestContext(r)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	if !securityContext.IsAdmin && !securityContext.IsTeamLeader {
+		httperror.WriteErrorResponse(w, chainid.ErrResourceAccessDenied, http.StatusForbidden, nil)
+		return
+	}
+
+	if securityContext.IsTeamLeader && req.Role == 1 {
+		httperror.WriteErrorResponse(w, chainid.ErrResourceAccessDenied, http.StatusForbidden, nil)
+		return
+	}
+
+	if strings.ContainsAny(req.Username, " ") {
+		httperror.WriteErrorResponse(w, chainid.ErrInvalidUsername, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	user, err := handler.UserService.UserByUsername(req.Username)
+	if err != nil && err != chainid.ErrUserNotFound {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+	if user != nil {
+		httperror.WriteErrorResponse(w, chainid.ErrUserAlreadyExists, http.StatusConflict, handler.Logger)
+		return
+	}
+
+	var role chainid.UserRole
+	if req.Role == 1 {
+		role = chainid.AdministratorRole
+	} else {
+		role = chainid.StandardUserRole
+	}
+
+	user = &chainid.User{
+		Username: req.Username,
+		Role:     role,
+	}
+
+	settings, err := handler.SettingsService.Settings()
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	if settings.AuthenticationMethod == chainid.AuthenticationInternal {
+		err = handler.setUserPassword(user, req.Password, &settings.PasswordPolicy)
+		if err != nil {
+			httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+			return
+		}
+		user.MustChangePassword = req.ForcePasswordChange
+	}
+
+	err = handler.UserService.CreateUser(user)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	encodeJSON(w, &postUsersResponse{ID: int(user.ID)}, handler.Logger)
+}
+
+// handleGetUsers handles GET requests on /users
+func (handler *UserHandler) handleGetUsers(w http.ResponseWriter, r *http.Request) {
+	securityContext, err := security.RetrieveRestrictedRequestContext(r)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	users, err := handler.UserService.Users()
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	filteredUsers := security.FilterUsers(users, securityContext)
+
+	for i := range filteredUsers {
+		hideUserSensitiveFields(&filteredUsers[i])
+	}
+
+	encodeJSON(w, filteredUsers, handler.Logger)
+}
+
+// handlePostUserPasswd handles POST requests on /users/:id/passwd
+// It checks the current password of the user and replaces it with the new password when specified.
+// This is the only way for a user who must change their password to update their account.
+func (handler *UserHandler) handlePostUserPasswd(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	userID, err := strconv.Atoi(id)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	if tokenData.ID != chainid.UserID(userID) {
+		httperror.WriteErrorResponse(w, chainid.ErrUnauthorized, http.StatusForbidden, handler.Logger)
+		return
+	}
+
+	var req postUserPasswdRequest
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidJSON, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	_, err = govalidator.ValidateStruct(req)
+	if err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	var password = req.Password
+
+	u, err := handler.UserService.User(chainid.UserID(userID))
+	if err == chainid.ErrUserNotFound {
+		httperror.WriteErrorResponse(w, err, http.StatusNotFound, handler.Logger)
+		return
+	} else if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	valid := true
+	err = handler.CryptoService.CompareHashAndData(u.Password, password)
+	if err != nil {
+		valid = false
+	}
+
+	if valid && req.NewPassword != "" {
+		settings, err := handler.SettingsService.Settings()
+		if err != nil {
+			httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+			return
+		}
+
+		err = handler.setUserPassword(u, req.NewPassword, &settings.PasswordPolicy)
+		if err != nil {
+			httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+			return
+		}
+
+		err = handler.UserService.UpdateUser(u.ID, u)
+		if err != nil {
+			httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+			return
+		}
+	}
+
+	encodeJSON(w, &postUserPasswdResponse{Valid: valid}, handler.Logger)
+}
+
+// handleGetUser handles GET requests on /users/:id
+func (handler *UserHandler) handleGetUser(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	userID, err := strconv.Atoi(id)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	user, err := handler.UserService.User(chainid.UserID(userID))
+	if err == chainid.ErrUserNotFound {
+		httperror.WriteErrorResponse(w, err, http.StatusNotFound, handler.Logger)
+		return
+	} else if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	hideUserSensitiveFields(user)
+	encodeJSON(w, &user, handler.Logger)
+}
+
+// handlePutUser handles PUT requests on /users/:id
+func (handler *UserHandler) handlePutUser(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	userID, err := strconv.Atoi(id)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	if tokenData.Role != chainid.AdministratorRole && tokenData.ID != chainid.UserID(userID) {
+		httperror.WriteErrorResponse(w, chainid.ErrUnauthorized, http.StatusForbidden, handler.Logger)
+		return
+	}
+
+	var req putUserRequest
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidJSON, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	_, err = govalidator.ValidateStruct(req)
+	if err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	if req.Password == "" && req.Role == 0 && !req.ForcePasswordChange {
+		httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	user, err := handler.UserService.User(chainid.UserID(userID))
+	if err == chainid.ErrUserNotFound {
+		httperror.WriteErrorResponse(w, err, http.StatusNotFound, handler.Logger)
+		return
+	} else if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	if req.Password != "" {
+		settings, err := handler.SettingsService.Settings()
+		if err != nil {
+			httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+			return
+		}
+
+		err = handler.setUserPassword(user, req.Password, &settings.PasswordPolicy)
+		if err != nil {
+			httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+			return
+		}
+	}
+
+	if req.ForcePasswordChange {
+		if tokenData.Role != chainid.AdministratorRole {
+			httperror.WriteErrorResponse(w, chainid.ErrUnauthorized, http.StatusForbidden, handler.Logger)
+			return
+		}
+		user.MustChangePassword = true
+	}
+
+	if req.Role != 0 {
+		if tokenData.Role != chainid.AdministratorRole {
+			httperror.WriteErrorResponse(w, chainid.ErrUnauthorized, http.StatusForbidden, handler.Logger)
+			return
+		}
+		if req.Role == 1 {
+			user.Role = chainid.AdministratorRole
+		} else {
+			user.Role = chainid.StandardUserRole
+		}
+	}
+
+	err = handler.UserService.UpdateUser(user.ID, user)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+}
+
+// handlePostUserTOTP handles POST requests on /users/:id/totp
+// It starts the two-factor authentication enrollment of the user by generating a new secret.
+func (handler *UserHandler) handlePostUserTOTP(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	userID, err := strconv.Atoi(id)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	if tokenData.ID != chainid.UserID(userID) {
+		httperror.WriteErrorResponse(w, chainid.ErrUnauthorized, http.StatusForbidden, handler.Logger)
+		return
+	}
+
+	user, err := handler.UserService.User(chainid.UserID(userID))
+	if err == chainid.ErrUserNotFound {
+		httperror.WriteErrorResponse(w, err, http.StatusNotFound, handler.Logger)
+		return
+	} else if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	if user.TOTPEnabled {
+		httperror.WriteErrorResponse(w, chainid.ErrTOTPAlreadyEnabled, http.StatusConflict, handler.Logger)
+		return
+	}
+
+	if !handler.secretsEncrypted {
+		httperror.WriteErrorResponse(w, chainid.ErrTOTPRequiresMasterKey, http.StatusServiceUnavailable, handler.Logger)
+		return
+	}
+
+	secret, err := handler.TOTPService.GenerateSecret()
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	user.TOTPSecret = secret
+	err = handler.UserService.UpdateUser(user.ID, user)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	response := &postUserTOTPResponse{
+		Secret:          secret,
+		ProvisioningURI: handler.TOTPService.ProvisioningURI(secret, user.Username),
+	}
+
+	encodeJSON(w, response, handler.Logger)
+}
+
+// handlePostUserTOTPVerify handles POST requests on /users/:id/totp/verify
+// It completes the two-factor authentication enrollment of the user and returns
+// the recovery codes associated to the account.
+func (handler *UserHandler) handlePostUserTOTPVerify(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	userID, err := strconv.Atoi(id)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	if tokenData.ID != chainid.UserID(userID) {
+		httperror.WriteErrorResponse(w, chainid.ErrUnauthorized, http.StatusForbidden, handler.Logger)
+		return
+	}
+
+	var req postUserTOTPVerifyRequest
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidJSON, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	_, err = govalidator.ValidateStruct(req)
+	if err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	user, err := handler.UserService.User(chainid.UserID(userID))
+	if err == chainid.ErrUserNotFound {
+		httperror.WriteErrorResponse(w, err, http.StatusNotFound, handler.Logger)
+		return
+	} else if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	if user.TOTPEnabled {
+		httperror.WriteErrorResponse(w, chainid.ErrTOTPAlreadyEnabled, http.StatusConflict, handler.Logger)
+		return
+	}
+
+	valid, err := validateUserTOTPCode(user, req.Code, handler.TOTPService)
+	if err == chainid.ErrTOTPNotEnrolled {
+		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+		return
+	} else if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+	if !valid {
+		httperror.WriteErrorResponse(w, chainid.ErrInvalidTOTPCode, http.StatusUnprocessableEntity, handler.Logger)
+		return
+	}
+
+	recoveryCodes, err := handler.TOTPService.GenerateRecoveryCodes(userRecoveryCodesCount)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	user.TOTPRecoveryCodes = make([]string, 0, len(recoveryCodes))
+	for _, code := range recoveryCodes {
+		hash, err := handler.CryptoService.Hash(code)
+		if err != nil {
+			httperror.WriteErrorResponse(w, chainid.ErrCryptoHashFailure, http.StatusInternalServerError, handler.Logger)
+			return
+		}
+		user.TOTPRecoveryCodes = append(user.TOTPRecoveryCodes, hash)
+	}
+	user.TOTPEnabled = true
+
+	err = handler.UserService.UpdateUser(user.ID, user)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	encodeJSON(w, &postUserTOTPVerifyResponse{RecoveryCodes: recoveryCodes}, handler.Logger)
+}
+
+// handleDeleteUserTOTP handles DELETE requests on /users/:id/totp
+// It disables two-factor authentication for the authenticated user. The current password of the
+// user is required, along with a TOTP code or a recovery code when two-factor authentication is enabled.
+func (handler *UserHandler) handleDeleteUserTOTP(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	userID, err := strconv.Atoi(id)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	if tokenData.ID != chainid.UserID(userID) {
+		httperror.WriteErrorResponse(w, chainid.ErrUnauthorized, http.StatusForbidden, handler.Logger)
+		return
+	}
+
+	var req deleteUserTOTPRequest
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidJSON, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	_, err = govalidator.ValidateStruct(req)
+	if err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	user, err := handler.UserService.User(chainid.UserID(userID))
+	if err == chainid.ErrUserNotFound {
+		httperror.WriteErrorResponse(w, err, http.StatusNotFound, handler.Logger)
+		return
+	} else if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	err = handler.CryptoService.CompareHashAndData(user.Password, req.Password)
+	if err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidCredentials, http.StatusUnprocessableEntity, handler.Logger)
+		return
+	}
+
+	if user.TOTPEnabled {
+		valid, err := handler.checkSecondFactor(user, req.Code, req.RecoveryCode)
+		if err != nil {
+			httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+			return
+		}
+		if !valid {
+			httperror.WriteErrorResponse(w, chainid.ErrInvalidTOTPCode, http.StatusUnprocessableEntity, handler.Logger)
+			return
+		}
+	}
+
+	err = handler.disableUserTOTP(user.ID)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+}
+
+// handlePostUserTOTPReset handles POST requests on /users/:id/totp/reset
+// It allows an administrator to disable two-factor authentication for a user who lost access
+// to their device. Administrators must disable their own two-factor authentication with
+// the DELETE /users/:id/totp endpoint.
+func (handler *UserHandler) handlePostUserTOTPReset(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	userID, err := strconv.Atoi(id)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	if tokenData.ID == chainid.UserID(userID) {
+		httperror.WriteErrorResponse(w, chainid.ErrUnauthorized, http.StatusForbidden, handler.Logger)
+		return
+	}
+
+	user, err := handler.UserService.User(chainid.UserID(userID))
+	if err == chainid.ErrUserNotFound {
+		httperror.WriteErrorResponse(w, err, http.StatusNotFound, handler.Logger)
+		return
+	} else if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	err = handler.disableUserTOTP(user.ID)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	handler.Logger.Printf("Two-factor authentication of account %s reset by %s", user.Username, tokenData.Username)
+}
+
+// checkSecondFactor validates either a TOTP code or a recovery code for the specified user.
+func (handler *UserHandler) checkSecondFactor(user *chainid.User, code, recoveryCode string) (bool, error) {
+	if code != "" {
+		return validateUserTOTPCode(user, code, handler.TOTPService)
+	}
+
+	for _, hash := range user.TOTPRecoveryCodes {
+		if recoveryCode != "" && handler.CryptoService.CompareHashAndData(hash, recoveryCode) == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// disableUserTOTP removes the TOTP secret and the recovery codes of a user.
+func (handler *UserHandler) disableUserTOTP(ID chainid.UserID) error {
+	return handler.UserService.UpdateUserFunc(ID, func(user *chainid.User) error {
+		user.TOTPSecret = ""
+		user.TOTPEnabled = false
+		user.TOTPRecoveryCodes = nil
+		return nil
+	})
+}
+
+// handlePostUserUnlock handles POST requests on /users/:id/unlock
+// It resets the failed login attempts counter of the user and unlocks the account.
+func (handler *UserHandler) handlePostUserUnlock(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	userID, err := strconv.Atoi(id)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	user, err := handler.UserService.User(chainid.UserID(userID))
+	if err == chainid.ErrUserNotFound {
+		httperror.WriteErrorResponse(w, err, http.StatusNotFound, handler.Logger)
+		return
+	} else if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	security.ResetFailedLogins(user)
+
+	err = handler.UserService.UpdateUser(user.ID, user)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	handler.Logger.Printf("Account %s unlocked by %s", user.Username, tokenData.Username)
+}
+
+// handleGetAdminCheck handles GET requests on /users/admin/check
+func (handler *UserHandler) handleGetAdminCheck(w http.ResponseWriter, r *http.Request) {
+	users, err := handler.UserService.UsersByRole(chainid.AdministratorRole)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+	if len(users) == 0 {
+		httperror.WriteErrorResponse(w, chainid.ErrUserNotFound, http.StatusNotFound, handler.Logger)
+		return
+	}
+}
+
+// handlePostAdminInit handles POST requests on /users/admin/init
+func (handler *UserHandler) handlePostAdminInit(w http.ResponseWriter, r *http.Request) {
+	var req postAdminInitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidJSON, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	_, err := govalidator.ValidateStruct(req)
+	if err != nil {
+		httperror.WriteErrorResponse(w, ErrInvalidRequestFormat, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	users, err := handler.UserService.UsersByRole(chainid.AdministratorRole)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+	if len(users) == 0 {
+		settings, err := handler.SettingsService.Settings()
+		if err != nil {
+			httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+			return
+		}
+
+		user := &chainid.User{
+			Username: req.Username,
+			Role:     chainid.AdministratorRole,
+		}
+		err = handler.setUserPassword(user, req.Password, &settings.PasswordPolicy)
+		if err != nil {
+			httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+			return
+		}
+
+		err = handler.UserService.CreateUser(user)
+		if err != nil {
+			httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+			return
+		}
+	} else {
+		httperror.WriteErrorResponse(w, chainid.ErrAdminAlreadyInitialized, http.StatusConflict, handler.Logger)
+		return
+	}
+}
+
+// handleDeleteUser handles DELETE requests on /users/:id
+func (handler *UserHandler) handleDeleteUser(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	userID, err := strconv.Atoi(id)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	if tokenData.ID == chainid.UserID(userID) {
+		httperror.WriteErrorResponse(w, chainid.ErrAdminCannotRemoveSelf, http.StatusForbidden, handler.Logger)
+		return
+	}
+
+	user, err := handler.UserService.User(chainid.UserID(userID))
+
+	if err == chainid.ErrUserNotFound {
+		httperror.WriteErrorResponse(w, err, http.StatusNotFound, handler.Logger)
+		return
+	} else if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	if user.Role == chainid.AdministratorRole && tokenData.Role != chainid.AdministratorRole {
+		httperror.WriteErrorResponse(w, chainid.ErrResourceAccessDenied, http.StatusForbidden, handler.Logger)
+		return
+	}
+
+	err = handler.UserService.DeleteUser(chainid.UserID(userID))
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	err = handler.TeamMembershipService.DeleteTeamMembershipByUserID(chainid.UserID(userID))
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	err = handler.RoleAssignmentService.DeleteRoleAssignmentsByUserID(chainid.UserID(userID))
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+}
+
+// handleGetMemberships handles GET requests on /users/:id/memberships
+func (handler *UserHandler) handleGetMemberships(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	userID, err := strconv.Atoi(id)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusBadRequest, handler.Logger)
+		return
+	}
+
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	if tokenData.Role != chainid.AdministratorRole && tokenData.ID != chainid.UserID(userID) {
+		httperror.WriteErrorResponse(w, chainid.ErrUnauthorized, http.StatusForbidden, handler.Logger)
+		return
+	}
+
+	memberships, err := handler.TeamMembershipService.TeamMembershipsByUserID(chainid.UserID(userID))
+	if err != nil {
+		httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, handler.Logger)
+		return
+	}
+
+	encodeJSON(w, memberships, handler.Logger)
+}
+
+// setUserPassword validates the password against the password policy and updates the password of the user.
+func (handler *UserHandler) setUserPassword(user *chainid.User, password string, policy *chainid.PasswordPolicy) error {
+	return security.SetUserPassword(user, password, policy, handler.CryptoService)
+}
+
+// hideUserSensitiveFields removes the credentials related fields of a user before sending it in a response.
+func hideUserSensitiveFields(user *chainid.User) {
+	user.Password = ""
+	user.PasswordHistory = nil
+	user.TOTPSecret = ""
+	user.TOTPRecoveryCodes = nil
+}
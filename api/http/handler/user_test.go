@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/crypto"
+	"github.com/chainid-io/dashboard/http/security"
+	"github.com/chainid-io/dashboard/jwt"
+	"github.com/chainid-io/dashboard/kv"
+)
+
+type userHandlerTest struct {
+	handler    *UserHandler
+	store      *kv.Store
+	jwtService chainid.JWTService
+	user       *chainid.User
+	token      string
+}
+
+// newUserHandlerTest returns a user handler backed by an in-memory datastore containing
+// a single standard user with the password "password", along with a token issued for it.
+func newUserHandlerTest(t *testing.T, secretsEncrypted bool) *userHandlerTest {
+	store := kv.NewStore(kv.NewMemoryBackend())
+	err := store.SettingsService.StoreSettings(&chainid.Settings{AuthenticationMethod: chainid.AuthenticationInternal})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cryptoService := &crypto.Service{}
+	password, err := cryptoService.Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := &chainid.User{Username: "alice", Password: password, Role: chainid.StandardUserRole}
+	err = store.UserService.CreateUser(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret, err := jwt.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtService, err := jwt.NewService(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authorizer := security.NewAuthorizer(store.RoleService, store.RoleAssignmentService, store.TeamMembershipService)
+	bouncer := security.NewRequestBouncer(jwtService, store.UserService, store.TeamMembershipService, store.SettingsService, store.EndpointService, authorizer, false)
+
+	handler := NewUserHandler(bouncer, secretsEncrypted)
+	handler.UserService = store.UserService
+	handler.SettingsService = store.SettingsService
+	handler.CryptoService = cryptoService
+	handler.TOTPService = &crypto.TOTPService{Issuer: "test"}
+
+	test := &userHandlerTest{handler: handler, store: store, jwtService: jwtService, user: user}
+	test.token = test.generateToken(t, user)
+	return test
+}
+
+func (test *userHandlerTest) generateToken(t *testing.T, user *chainid.User) string {
+	token, err := test.jwtService.GenerateToken(&chainid.TokenData{ID: user.ID, Username: user.Username, Role: user.Role})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func serveTestRequest(handler http.Handler, method, url, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, url, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestPostUserTOTPRequiresEncryption(t *testing.T) {
+	for _, secretsEncrypted := range []bool{false, true} {
+		test := newUserHandlerTest(t, secretsEncrypted)
+
+		rr := serveTestRequest(test.handler, http.MethodPost, "/users/"+strconv.Itoa(int(test.user.ID))+"/totp", test.token, "")
+
+		expected := http.StatusOK
+		if !secretsEncrypted {
+			expected = http.StatusServiceUnavailable
+		}
+		if rr.Code != expected {
+			t.Fatalf("expected status code %d when secrets encryption is %v, got %d: %s", expected, secretsEncrypted, rr.Code, rr.Body.String())
+		}
+
+		stored, err := test.store.UserService.User(test.user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (stored.TOTPSecret != "") != secretsEncrypted {
+			t.Errorf("expected the TOTP secret to be stored only when secrets are encrypted, got %q", stored.TOTPSecret)
+		}
+	}
+}
+
+func TestDeleteUserTOTP(t *testing.T) {
+	test := newUserHandlerTest(t, true)
+
+	recoveryCode, err := test.handler.CryptoService.Hash("recovery")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = test.store.UserService.UpdateUserFunc(test.user.ID, func(user *chainid.User) error {
+		user.TOTPSecret = "JBSWY3DPEHPK3PXP"
+		user.TOTPEnabled = true
+		user.TOTPRecoveryCodes = []string{recoveryCode}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	admin := &chainid.User{Username: "admin", Role: chainid.AdministratorRole}
+	err = test.store.UserService.CreateUser(admin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	adminToken := test.generateToken(t, admin)
+
+	userURL := "/users/" + strconv.Itoa(int(test.user.ID))
+	testCases := []struct {
+		name       string
+		method     string
+		url        string
+		token      string
+		body       string
+		statusCode int
+	}{
+		{"disable without credentials", http.MethodDelete, userURL + "/totp", test.token, `{}`, http.StatusBadRequest},
+		{"disable with an invalid password", http.MethodDelete, userURL + "/totp", test.token, `{"Password":"invalid","RecoveryCode":"recovery"}`, http.StatusUnprocessableEntity},
+		{"disable without a second factor", http.MethodDelete, userURL + "/totp", test.token, `{"Password":"password"}`, http.StatusUnprocessableEntity},
+		{"disable with an invalid code", http.MethodDelete, userURL + "/totp", test.token, `{"Password":"password","Code":"000000"}`, http.StatusUnprocessableEntity},
+		{"disable another user", http.MethodDelete, userURL + "/totp", adminToken, `{"Password":"password","RecoveryCode":"recovery"}`, http.StatusForbidden},
+		{"reset without permission", http.MethodPost, userURL + "/totp/reset", test.token, "", http.StatusForbidden},
+		{"reset own account", http.MethodPost, "/users/" + strconv.Itoa(int(admin.ID)) + "/totp/reset", adminToken, "", http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		rr := serveTestRequest(test.handler, tc.method, tc.url, tc.token, tc.body)
+		if rr.Code != tc.statusCode {
+			t.Errorf("%s: expected status code %d, got %d: %s", tc.name, tc.statusCode, rr.Code, rr.Body.String())
+		}
+	}
+
+	stored, err := test.store.UserService.User(test.user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.TOTPEnabled {
+		t.Fatal("expected two-factor authentication to remain enabled")
+	}
+
+	rr := serveTestRequest(test.handler, http.MethodDelete, userURL+"/totp", test.token, `{"Password":"password","RecoveryCode":"recovery"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	stored, err = test.store.UserService.User(test.user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.TOTPEnabled || stored.TOTPSecret != "" || len(stored.TOTPRecoveryCodes) != 0 {
+		t.Errorf("expected two-factor authentication to be disabled, got %+v", stored)
+	}
+
+	err = test.store.UserService.UpdateUserFunc(test.user.ID, func(user *chainid.User) error {
+		user.TOTPSecret = "JBSWY3DPEHPK3PXP"
+		user.TOTPEnabled = true
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr = serveTestRequest(test.handler, http.MethodPost, userURL+"/totp/reset", adminToken, "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	stored, err = test.store.UserService.User(test.user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.TOTPEnabled || stored.TOTPSecret != "" {
+		t.Errorf("expected two-factor authentication to be reset, got %+v", stored)
+	}
+}
@@ -0,0 +1,124 @@
+package proxy
+
+import (
+	"sync"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+)
+
+const (
+	defaultCircuitBreakerThreshold = 5
+	defaultCircuitBreakerTimeout   = 30 * time.Second
+)
+
+// circuitBreaker stops sending requests to an endpoint after consecutive connection failures.
+// Once the timeout has elapsed, a single request is let through and its outcome decides whether
+// the circuit breaker is closed or opened again.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	timeout   time.Duration
+	state     chainid.CircuitBreakerState
+	failures  int
+	openedAt  time.Time
+	probing   bool
+}
+
+func newCircuitBreaker(settings *chainid.EndpointTransportSettings) *circuitBreaker {
+	breaker := &circuitBreaker{state: chainid.CircuitBreakerClosed}
+	breaker.configure(settings)
+	return breaker
+}
+
+// configure updates the threshold and the timeout of the circuit breaker, its state is preserved.
+func (breaker *circuitBreaker) configure(settings *chainid.EndpointTransportSettings) {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	breaker.threshold = settings.CircuitBreakerThreshold
+	if breaker.threshold == 0 {
+		breaker.threshold = defaultCircuitBreakerThreshold
+	}
+
+	breaker.timeout = time.Duration(settings.CircuitBreakerTimeout) * time.Second
+	if breaker.timeout <= 0 {
+		breaker.timeout = defaultCircuitBreakerTimeout
+	}
+
+	if breaker.threshold < 0 {
+		breaker.state = chainid.CircuitBreakerClosed
+		breaker.failures = 0
+		breaker.probing = false
+	}
+}
+
+// allow returns an error when the request must not be sent to the endpoint. A request that is
+// allowed must be followed by a call to record or release.
+func (breaker *circuitBreaker) allow() error {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	switch breaker.state {
+	case chainid.CircuitBreakerOpen:
+		if time.Since(breaker.openedAt) < breaker.timeout {
+			return chainid.ErrEndpointCircuitOpen
+		}
+		breaker.state = chainid.CircuitBreakerHalfOpen
+		breaker.probing = true
+	case chainid.CircuitBreakerHalfOpen:
+		if breaker.probing {
+			return chainid.ErrEndpointCircuitOpen
+		}
+		breaker.probing = true
+	}
+	return nil
+}
+
+// record updates the state of the circuit breaker with the outcome of a request.
+func (breaker *circuitBreaker) record(success bool) {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	breaker.probing = false
+
+	if breaker.threshold < 0 {
+		return
+	}
+
+	if success {
+		breaker.state = chainid.CircuitBreakerClosed
+		breaker.failures = 0
+		return
+	}
+
+	breaker.failures++
+	if breaker.state == chainid.CircuitBreakerHalfOpen || breaker.failures >= breaker.threshold {
+		breaker.state = chainid.CircuitBreakerOpen
+		breaker.openedAt = time.Now()
+	}
+}
+
+// release is used when the outcome of a request says nothing about the endpoint, for instance
+// when the request is canceled by the client.
+func (breaker *circuitBreaker) release() {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	breaker.probing = false
+}
+
+func (breaker *circuitBreaker) status() *chainid.CircuitBreakerStatus {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	status := &chainid.CircuitBreakerStatus{
+		State:               breaker.state,
+		ConsecutiveFailures: breaker.failures,
+	}
+	if breaker.state != chainid.CircuitBreakerClosed {
+		status.OpenedAt = breaker.openedAt.Unix()
+		status.RetryAt = breaker.openedAt.Add(breaker.timeout).Unix()
+	}
+	return status
+}
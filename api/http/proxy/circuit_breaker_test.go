@@ -0,0 +1,114 @@
+package proxy
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+)
+
+func TestCircuitBreakerOpensAfterThreshold(t *testing.T) {
+	breaker := newCircuitBreaker(&chainid.EndpointTransportSettings{CircuitBreakerThreshold: 2})
+
+	for i := 0; i < 2; i++ {
+		if err := breaker.allow(); err != nil {
+			t.Fatalf("expected request %d to be allowed, got %v", i, err)
+		}
+		breaker.record(false)
+	}
+
+	if err := breaker.allow(); err != chainid.ErrEndpointCircuitOpen {
+		t.Fatalf("expected %q, got %v", chainid.ErrEndpointCircuitOpen, err)
+	}
+
+	status := breaker.status()
+	if status.State != chainid.CircuitBreakerOpen || status.ConsecutiveFailures != 2 || status.RetryAt <= status.OpenedAt {
+		t.Errorf("unexpected status %+v", status)
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	breaker := newCircuitBreaker(&chainid.EndpointTransportSettings{CircuitBreakerThreshold: 1})
+	breaker.allow()
+	breaker.record(false)
+
+	// The timeout has elapsed, a single request is let through.
+	breaker.openedAt = time.Now().Add(-time.Hour)
+	if err := breaker.allow(); err != nil {
+		t.Fatalf("expected the probe to be allowed, got %v", err)
+	}
+	if err := breaker.allow(); err != chainid.ErrEndpointCircuitOpen {
+		t.Fatalf("expected a single probe, got %v", err)
+	}
+
+	breaker.record(false)
+	if breaker.status().State != chainid.CircuitBreakerOpen {
+		t.Fatalf("expected a failed probe to open the circuit breaker again")
+	}
+
+	breaker.openedAt = time.Now().Add(-time.Hour)
+	breaker.allow()
+	breaker.record(true)
+	status := breaker.status()
+	if status.State != chainid.CircuitBreakerClosed || status.ConsecutiveFailures != 0 {
+		t.Errorf("expected a successful probe to close the circuit breaker, got %+v", status)
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	breaker := newCircuitBreaker(&chainid.EndpointTransportSettings{CircuitBreakerThreshold: -1})
+
+	for i := 0; i < 10; i++ {
+		if err := breaker.allow(); err != nil {
+			t.Fatalf("expected a disabled circuit breaker to allow request %d, got %v", i, err)
+		}
+		breaker.record(false)
+	}
+}
+
+func TestExecuteDockerRequestFailsFast(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	settings := &chainid.EndpointTransportSettings{CircuitBreakerThreshold: 1, DialTimeout: 1}
+	transport := &proxyTransport{
+		dockerTransport: &http.Transport{},
+		circuitBreaker:  newCircuitBreaker(settings),
+	}
+	configureTransport(transport.dockerTransport, settings)
+
+	request := httptest.NewRequest(http.MethodGet, "http://"+address+"/_ping", nil)
+	request.RequestURI = ""
+
+	_, err = transport.executeDockerRequest(request)
+	if err == nil || err == chainid.ErrEndpointCircuitOpen {
+		t.Fatalf("expected a connection error, got %v", err)
+	}
+
+	_, err = transport.executeDockerRequest(request)
+	if err != chainid.ErrEndpointCircuitOpen {
+		t.Fatalf("expected %q, got %v", chainid.ErrEndpointCircuitOpen, err)
+	}
+}
+
+func TestConfigureTransportDefaults(t *testing.T) {
+	transport := &http.Transport{}
+	configureTransport(transport, &chainid.EndpointTransportSettings{ResponseHeaderTimeout: 60})
+
+	if transport.TLSHandshakeTimeout != defaultTLSHandshakeTimeout || transport.IdleConnTimeout != defaultIdleConnTimeout {
+		t.Errorf("expected the default timeouts, got %v and %v", transport.TLSHandshakeTimeout, transport.IdleConnTimeout)
+	}
+	if transport.ResponseHeaderTimeout != time.Minute {
+		t.Errorf("expected a response header timeout of a minute, got %v", transport.ResponseHeaderTimeout)
+	}
+	if transport.MaxIdleConnsPerHost != defaultMaxIdleConns || transport.DialContext == nil {
+		t.Errorf("expected the default connection settings")
+	}
+}
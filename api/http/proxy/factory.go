@@ -1,101 +1,190 @@
-package proxy
-
-import (
-	"net"
-	"net/http"
-	"net/http/httputil"
-	"net/url"
-
-	"github.com/chainid-io/dashboard"
-	"github.com/chainid-io/dashboard/crypto"
-)
-
-const azureAPIBaseURL = "https://management.azure.com"
-
-// proxyFactory is a factory to create reverse proxies to Docker endpoints
-type proxyFactory struct {
-	ResourceControlService chainid.ResourceControlService
-	TeamMembershipService  chainid.TeamMembershipService
-	SettingsService        chainid.SettingsService
-	RegistryService        chainid.RegistryService
-	DockerHubService       chainid.DockerHubService
-	SignatureService       chainid.DigitalSignatureService
-}
-
-func (factory *proxyFactory) newHTTPProxy(u *url.URL) http.Handler {
-	u.Scheme = "http"
-	return newSingleHostReverseProxyWithHostHeader(u)
-}
-
-func newAzureProxy(credentials *chainid.AzureCredentials) (http.Handler, error) {
-	url, err := url.Parse(azureAPIBaseURL)
-	if err != nil {
-		return nil, err
-	}
-
-	proxy := newSingleHostReverseProxyWithHostHeader(url)
-	proxy.Transport = NewAzureTransport(credentials)
-
-	return proxy, nil
-}
-
-func (factory *proxyFactory) newDockerHTTPSProxy(u *url.URL, tlsConfig *chainid.TLSConfiguration, enableSignature bool) (http.Handler, error) {
-	u.Scheme = "https"
-
-	proxy := factory.createDockerReverseProxy(u, enableSignature)
-	config, err := crypto.CreateTLSConfigurationFromDisk(tlsConfig.TLSCACertPath, tlsConfig.TLSCertPath, tlsConfig.TLSKeyPath, tlsConfig.TLSSkipVerify)
-	if err != nil {
-		return nil, err
-	}
-
-	proxy.Transport.(*proxyTransport).dockerTransport.TLSClientConfig = config
-	return proxy, nil
-}
-
-func (factory *proxyFactory) newDockerHTTPProxy(u *url.URL, enableSignature bool) http.Handler {
-	u.Scheme = "http"
-	return factory.createDockerReverseProxy(u, enableSignature)
-}
-
-func (factory *proxyFactory) newDockerSocketProxy(path string) http.Handler {
-	proxy := &socketProxy{}
-	transport := &proxyTransport{
-		enableSignature:        false,
-		ResourceControlService: factory.ResourceControlService,
-		TeamMembershipService:  factory.TeamMembershipService,
-		SettingsService:        factory.SettingsService,
-		RegistryService:        factory.RegistryService,
-		DockerHubService:       factory.DockerHubService,
-		dockerTransport:        newSocketTransport(path),
-	}
-	proxy.Transport = transport
-	return proxy
-}
-
-func (factory *proxyFactory) createDockerReverseProxy(u *url.URL, enableSignature bool) *httputil.ReverseProxy {
-	proxy := newSingleHostReverseProxyWithHostHeader(u)
-	transport := &proxyTransport{
-		enableSignature:        enableSignature,
-		ResourceControlService: factory.ResourceControlService,
-		TeamMembershipService:  factory.TeamMembershipService,
-		SettingsService:        factory.SettingsService,
-		RegistryService:        factory.RegistryService,
-		DockerHubService:       factory.DockerHubService,
-		dockerTransport:        &http.Transport{},
-	}
-
-	if enableSignature {
-		transport.SignatureService = factory.SignatureService
-	}
-
-	proxy.Transport = transport
-	return proxy
-}
-
-func newSocketTransport(socketPath string) *http.Transport {
-	return &http.Transport{
-		Dial: func(proto, addr string) (conn net.Conn, err error) {
-			return net.Dial("unix", socketPath)
-		},
-	}
-}
+package proxy
+
+import (
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/crypto"
+	httperror "github.com/chainid-io/dashboard/http/error"
+	"github.com/chainid-io/dashboard/http/security"
+	"github.com/chainid-io/dashboard/ssh"
+	"github.com/chainid-io/dashboard/tunnel"
+)
+
+const (
+	azureAPIBaseURL = "https://management.azure.com"
+	// edgeAPIURL is the URL used for the requests sent through the tunnel of an edge endpoint,
+	// the host is ignored as the connections are opened by the tunnel service.
+	edgeAPIURL = "http://edge"
+)
+
+// proxyFactory is a factory to create reverse proxies to Docker endpoints
+type proxyFactory struct {
+	ResourceControlService chainid.ResourceControlService
+	TeamMembershipService  chainid.TeamMembershipService
+	SettingsService        chainid.SettingsService
+	RegistryService        chainid.RegistryService
+	DockerHubService       chainid.DockerHubService
+	SignatureService       chainid.DigitalSignatureService
+	Authorizer             *security.Authorizer
+	TunnelService          *tunnel.Service
+}
+
+func (factory *proxyFactory) newHTTPProxy(u *url.URL) http.Handler {
+	u.Scheme = "http"
+	return newSingleHostReverseProxyWithHostHeader(u)
+}
+
+func newAzureProxy(credentials *chainid.AzureCredentials) (http.Handler, error) {
+	url, err := url.Parse(azureAPIBaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := newSingleHostReverseProxyWithHostHeader(url)
+	proxy.Transport = NewAzureTransport(credentials)
+
+	return proxy, nil
+}
+
+func (factory *proxyFactory) newDockerHTTPSProxy(u *url.URL, tlsConfig *chainid.TLSConfiguration, enableSignature bool) (http.Handler, error) {
+	u.Scheme = "https"
+
+	proxy := factory.createDockerReverseProxy(u, enableSignature)
+	config, err := crypto.CreateTLSConfigurationFromDisk(tlsConfig.TLSCACertPath, tlsConfig.TLSCertPath, tlsConfig.TLSKeyPath, tlsConfig.TLSSkipVerify)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy.Transport.(*proxyTransport).dockerTransport.TLSClientConfig = config
+	return proxy, nil
+}
+
+func (factory *proxyFactory) newDockerHTTPProxy(u *url.URL, enableSignature bool) http.Handler {
+	u.Scheme = "http"
+	return factory.createDockerReverseProxy(u, enableSignature)
+}
+
+func (factory *proxyFactory) newDockerSocketProxy(path string) http.Handler {
+	proxy := &socketProxy{}
+	transport := &proxyTransport{
+		enableSignature:        false,
+		ResourceControlService: factory.ResourceControlService,
+		TeamMembershipService:  factory.TeamMembershipService,
+		SettingsService:        factory.SettingsService,
+		RegistryService:        factory.RegistryService,
+		DockerHubService:       factory.DockerHubService,
+		Authorizer:             factory.Authorizer,
+		dockerTransport:        newSocketTransport(path),
+	}
+	proxy.Transport = transport
+	return proxy
+}
+
+func (factory *proxyFactory) newDockerSSHProxy(endpointURL string, sshConfig *chainid.SSHConfiguration) (http.Handler, error) {
+	dialer, err := ssh.NewDialer(endpointURL, sshConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := factory.newDockerSocketProxy("").(*socketProxy)
+	proxy.Transport.dockerTransport = newSSHTransport(dialer)
+	return proxy, nil
+}
+
+func (factory *proxyFactory) newDockerTunnelProxy(endpointID chainid.EndpointID) (http.Handler, error) {
+	u, err := url.Parse(edgeAPIURL)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := factory.createDockerReverseProxy(u, false)
+	proxy.Transport.(*proxyTransport).dockerTransport = newTunnelTransport(factory.TunnelService, endpointID)
+	return proxy, nil
+}
+
+func (factory *proxyFactory) newKubernetesProxy(endpoint *chainid.Endpoint) (http.Handler, error) {
+	u, err := url.Parse(endpoint.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &endpoint.TLSConfig
+	config, err := crypto.CreateTLSConfigurationFromDisk(tlsConfig.TLSCACertPath, tlsConfig.TLSCertPath, tlsConfig.TLSKeyPath, tlsConfig.TLSSkipVerify)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := newSingleHostReverseProxyWithHostHeader(u)
+	// The watch requests are streamed to the client.
+	proxy.FlushInterval = -1
+	proxy.Transport = &kubernetesTransport{
+		httpTransport:         &http.Transport{TLSClientConfig: config},
+		token:                 endpoint.KubernetesCredentials.Token,
+		namespaces:            endpoint.KubernetesNamespaces,
+		TeamMembershipService: factory.TeamMembershipService,
+	}
+	return proxy, nil
+}
+
+func (factory *proxyFactory) createDockerReverseProxy(u *url.URL, enableSignature bool) *httputil.ReverseProxy {
+	proxy := newSingleHostReverseProxyWithHostHeader(u)
+	transport := &proxyTransport{
+		enableSignature:        enableSignature,
+		ResourceControlService: factory.ResourceControlService,
+		TeamMembershipService:  factory.TeamMembershipService,
+		SettingsService:        factory.SettingsService,
+		RegistryService:        factory.RegistryService,
+		DockerHubService:       factory.DockerHubService,
+		Authorizer:             factory.Authorizer,
+		dockerTransport:        &http.Transport{},
+	}
+
+	if enableSignature {
+		transport.SignatureService = factory.SignatureService
+	}
+
+	proxy.Transport = transport
+	proxy.ErrorHandler = writeDockerProxyError
+	return proxy
+}
+
+// writeDockerProxyError replaces the default error handler of the reverse proxy so that the
+// client can tell an endpoint whose circuit breaker is open from an unreachable one.
+func writeDockerProxyError(w http.ResponseWriter, r *http.Request, err error) {
+	code := http.StatusBadGateway
+	if err == chainid.ErrEndpointCircuitOpen {
+		code = http.StatusServiceUnavailable
+	}
+	httperror.WriteErrorResponse(w, err, code, nil)
+}
+
+func newSocketTransport(socketPath string) *http.Transport {
+	return &http.Transport{
+		Dial: func(proto, addr string) (conn net.Conn, err error) {
+			return net.Dial("unix", socketPath)
+		},
+	}
+}
+
+func newSSHTransport(dialer *ssh.Dialer) *http.Transport {
+	return &http.Transport{
+		Dial: func(proto, addr string) (conn net.Conn, err error) {
+			return dialer.Dial()
+		},
+		// The idle connections are closed so that the SSH connection can be released.
+		IdleConnTimeout: 30 * time.Second,
+	}
+}
+
+func newTunnelTransport(tunnelService *tunnel.Service, endpointID chainid.EndpointID) *http.Transport {
+	return &http.Transport{
+		Dial: func(proto, addr string) (conn net.Conn, err error) {
+			return tunnelService.Dial(endpointID)
+		},
+	}
+}
@@ -0,0 +1,208 @@
+package proxy
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/http/security"
+)
+
+type (
+	// kubernetesTransport authenticates the requests sent to the API server of a Kubernetes
+	// environment and restricts the users to the namespaces they are authorized to access.
+	// The administrators are not restricted.
+	kubernetesTransport struct {
+		httpTransport         *http.Transport
+		token                 string
+		namespaces            []chainid.KubernetesNamespaceAccess
+		TeamMembershipService chainid.TeamMembershipService
+	}
+
+	kubernetesRequestScope int
+
+	kubernetesStatus struct {
+		Kind       string `json:"kind"`
+		APIVersion string `json:"apiVersion"`
+		Status     string `json:"status"`
+		Message    string `json:"message"`
+		Reason     string `json:"reason"`
+		Code       int    `json:"code"`
+	}
+)
+
+const (
+	// kubernetesDiscoveryScope represents the version and API discovery requests.
+	kubernetesDiscoveryScope kubernetesRequestScope = iota
+	// kubernetesNamespaceListScope represents the requests on the list of namespaces.
+	kubernetesNamespaceListScope
+	// kubernetesNamespaceScope represents the requests on a namespace object.
+	kubernetesNamespaceScope
+	// kubernetesNamespacedScope represents the requests on the resources of a namespace.
+	kubernetesNamespacedScope
+	// kubernetesClusterScope represents the requests on cluster-scoped resources and
+	// on the resources of every namespace.
+	kubernetesClusterScope
+)
+
+func (transport *kubernetesTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	tokenData, err := security.RetrieveTokenData(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if tokenData.Role == chainid.AdministratorRole {
+		return transport.executeKubernetesRequest(request)
+	}
+
+	memberships, err := transport.TeamMembershipService.TeamMembershipsByUserID(tokenData.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	namespace, scope := parseKubernetesRequestPath(request.URL.Path)
+	switch scope {
+	case kubernetesDiscoveryScope:
+		if request.Method != http.MethodGet {
+			return writeKubernetesAccessDeniedResponse(chainid.ErrKubernetesClusterScopeDenied)
+		}
+		return transport.executeKubernetesRequest(request)
+	case kubernetesNamespaceListScope:
+		if request.Method != http.MethodGet || isKubernetesWatchRequest(request) {
+			return writeKubernetesAccessDeniedResponse(chainid.ErrKubernetesClusterScopeDenied)
+		}
+		return transport.namespaceListOperation(request, tokenData.ID, memberships)
+	case kubernetesNamespaceScope, kubernetesNamespacedScope:
+		if !transport.authorizedNamespace(namespace, tokenData.ID, memberships) {
+			return writeKubernetesAccessDeniedResponse(chainid.ErrKubernetesNamespaceDenied)
+		}
+		if scope == kubernetesNamespaceScope && request.Method != http.MethodGet {
+			return writeKubernetesAccessDeniedResponse(chainid.ErrKubernetesNamespaceDenied)
+		}
+		return transport.executeKubernetesRequest(request)
+	default:
+		return writeKubernetesAccessDeniedResponse(chainid.ErrKubernetesClusterScopeDenied)
+	}
+}
+
+func (transport *kubernetesTransport) executeKubernetesRequest(request *http.Request) (*http.Response, error) {
+	// The authorization header contains the token of the dashboard API, it is replaced
+	// by the credentials of the endpoint.
+	request.Header.Del("Authorization")
+	if transport.token != "" {
+		request.Header.Set("Authorization", "Bearer "+transport.token)
+	}
+	return transport.httpTransport.RoundTrip(request)
+}
+
+func (transport *kubernetesTransport) authorizedNamespace(namespace string, userID chainid.UserID, memberships []chainid.TeamMembership) bool {
+	for i := range transport.namespaces {
+		access := &transport.namespaces[i]
+		if access.Namespace == namespace {
+			return security.AuthorizedKubernetesNamespaceAccess(access, userID, memberships)
+		}
+	}
+	return false
+}
+
+// namespaceListOperation filters the list of namespaces, only the namespaces the user is
+// authorized to access are returned. Both the NamespaceList and the Table formats are supported.
+func (transport *kubernetesTransport) namespaceListOperation(request *http.Request, userID chainid.UserID, memberships []chainid.TeamMembership) (*http.Response, error) {
+	// The response must be decoded, protocol buffers and compressed responses are not supported.
+	if strings.Contains(request.Header.Get("Accept"), "protobuf") {
+		request.Header.Set("Accept", "application/json")
+	}
+	request.Header.Del("Accept-Encoding")
+
+	response, err := transport.executeKubernetesRequest(request)
+	if err != nil || response.StatusCode != http.StatusOK {
+		return response, err
+	}
+
+	responseObject, err := getResponseAsJSONOBject(response)
+	if err != nil {
+		return nil, err
+	}
+
+	itemsKey := "items"
+	if responseObject["kind"] == "Table" {
+		itemsKey = "rows"
+	}
+
+	items, _ := responseObject[itemsKey].([]interface{})
+	filteredItems := make([]interface{}, 0)
+	for _, item := range items {
+		object, _ := item.(map[string]interface{})
+		if itemsKey == "rows" {
+			object = extractJSONField(object, "object")
+		}
+		metadata := extractJSONField(object, "metadata")
+		name, _ := metadata["name"].(string)
+
+		if transport.authorizedNamespace(name, userID, memberships) {
+			filteredItems = append(filteredItems, item)
+		}
+	}
+	responseObject[itemsKey] = filteredItems
+
+	return response, rewriteResponse(response, responseObject, http.StatusOK)
+}
+
+// parseKubernetesRequestPath returns the scope of a request on the API server along with the
+// namespace targeted by the request.
+// The paths of the core API are /api/{version}/... and the paths of the named groups are
+// /apis/{group}/{version}/...
+func parseKubernetesRequestPath(path string) (string, kubernetesRequestScope) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	var prefixLength int
+	switch segments[0] {
+	case "api":
+		prefixLength = 2
+	case "apis":
+		prefixLength = 3
+	case "version", "openapi":
+		return "", kubernetesDiscoveryScope
+	default:
+		return "", kubernetesClusterScope
+	}
+
+	if len(segments) <= prefixLength {
+		return "", kubernetesDiscoveryScope
+	}
+
+	resource := segments[prefixLength:]
+	if resource[0] != "namespaces" {
+		return "", kubernetesClusterScope
+	}
+
+	switch {
+	case len(resource) == 1:
+		return "", kubernetesNamespaceListScope
+	case len(resource) == 2 || resource[2] == "status" || resource[2] == "finalize":
+		return resource[1], kubernetesNamespaceScope
+	default:
+		return resource[1], kubernetesNamespacedScope
+	}
+}
+
+func isKubernetesWatchRequest(request *http.Request) bool {
+	watch, err := strconv.ParseBool(request.URL.Query().Get("watch"))
+	return err == nil && watch
+}
+
+func writeKubernetesAccessDeniedResponse(err error) (*http.Response, error) {
+	response := &http.Response{}
+	status := &kubernetesStatus{
+		Kind:       "Status",
+		APIVersion: "v1",
+		Status:     "Failure",
+		Message:    err.Error(),
+		Reason:     "Forbidden",
+		Code:       http.StatusForbidden,
+	}
+	err = rewriteResponse(response, status, http.StatusForbidden)
+	response.Header.Set("Content-Type", "application/json")
+	return response, err
+}
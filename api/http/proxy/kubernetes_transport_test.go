@@ -0,0 +1,180 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/http/security"
+)
+
+const testKubernetesToken = "service-account-token"
+
+type teamMembershipServiceStub struct {
+	chainid.TeamMembershipService
+	memberships []chainid.TeamMembership
+}
+
+func (service *teamMembershipServiceStub) TeamMembershipsByUserID(userID chainid.UserID) ([]chainid.TeamMembership, error) {
+	memberships := make([]chainid.TeamMembership, 0)
+	for _, membership := range service.memberships {
+		if membership.UserID == userID {
+			memberships = append(memberships, membership)
+		}
+	}
+	return memberships, nil
+}
+
+// newFakeKubernetesAPIServer returns an API server answering every authenticated request
+// with its path, and the list of namespaces on /api/v1/namespaces.
+func newFakeKubernetesAPIServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testKubernetesToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/v1/namespaces" {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"kind": "NamespaceList",
+				"items": []interface{}{
+					map[string]interface{}{"metadata": map[string]interface{}{"name": "default"}},
+					map[string]interface{}{"metadata": map[string]interface{}{"name": "team-a"}},
+					map[string]interface{}{"metadata": map[string]interface{}{"name": "team-b"}},
+				},
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"path": r.URL.Path})
+	}))
+}
+
+func newTestKubernetesProxy(t *testing.T, serverURL string) http.Handler {
+	manager := NewManager(&ManagerParams{
+		TeamMembershipService: &teamMembershipServiceStub{
+			memberships: []chainid.TeamMembership{{UserID: 3, TeamID: 1}},
+		},
+	})
+
+	proxy, err := manager.CreateAndRegisterProxy(&chainid.Endpoint{
+		ID:                    1,
+		Type:                  chainid.KubernetesEnvironment,
+		URL:                   serverURL,
+		KubernetesCredentials: chainid.KubernetesCredentials{Token: testKubernetesToken},
+		KubernetesNamespaces: []chainid.KubernetesNamespaceAccess{
+			{Namespace: "team-a", AuthorizedUsers: []chainid.UserID{2}},
+			{Namespace: "team-b", AuthorizedTeams: []chainid.TeamID{1}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return proxy
+}
+
+func serveKubernetesRequest(proxy http.Handler, method, path string, tokenData *chainid.TokenData) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, nil)
+	request.Header.Set("Authorization", "Bearer dashboard-token")
+	request = request.WithContext(security.StoreTokenData(request, tokenData))
+
+	recorder := httptest.NewRecorder()
+	proxy.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestKubernetesProxyNamespaceAccess(t *testing.T) {
+	server := newFakeKubernetesAPIServer(t)
+	defer server.Close()
+	proxy := newTestKubernetesProxy(t, server.URL)
+
+	admin := &chainid.TokenData{ID: 1, Role: chainid.AdministratorRole}
+	userA := &chainid.TokenData{ID: 2, Role: chainid.StandardUserRole}
+	userB := &chainid.TokenData{ID: 3, Role: chainid.StandardUserRole}
+
+	tests := []struct {
+		method    string
+		path      string
+		tokenData *chainid.TokenData
+		status    int
+	}{
+		{http.MethodGet, "/api/v1/nodes", admin, http.StatusOK},
+		{http.MethodGet, "/api/v1/namespaces/team-b/pods", admin, http.StatusOK},
+		{http.MethodGet, "/version", userA, http.StatusOK},
+		{http.MethodGet, "/apis/apps/v1", userA, http.StatusOK},
+		{http.MethodGet, "/api/v1/namespaces/team-a/pods", userA, http.StatusOK},
+		{http.MethodDelete, "/apis/apps/v1/namespaces/team-a/deployments/web", userA, http.StatusOK},
+		{http.MethodGet, "/api/v1/namespaces/team-a", userA, http.StatusOK},
+		{http.MethodDelete, "/api/v1/namespaces/team-a", userA, http.StatusForbidden},
+		{http.MethodGet, "/api/v1/namespaces/team-b/pods", userA, http.StatusForbidden},
+		{http.MethodGet, "/api/v1/namespaces/default/pods", userA, http.StatusForbidden},
+		{http.MethodGet, "/api/v1/pods", userA, http.StatusForbidden},
+		{http.MethodGet, "/api/v1/nodes", userA, http.StatusForbidden},
+		{http.MethodGet, "/api/v1/namespaces/team-b/pods", userB, http.StatusOK},
+		{http.MethodGet, "/api/v1/namespaces/team-a/pods", userB, http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		recorder := serveKubernetesRequest(proxy, test.method, test.path, test.tokenData)
+		if recorder.Code != test.status {
+			t.Errorf("%s %s as user %d: expected status %d, got %d", test.method, test.path, test.tokenData.ID, test.status, recorder.Code)
+		}
+	}
+}
+
+func TestKubernetesProxyNamespaceList(t *testing.T) {
+	server := newFakeKubernetesAPIServer(t)
+	defer server.Close()
+	proxy := newTestKubernetesProxy(t, server.URL)
+
+	tests := []struct {
+		tokenData  *chainid.TokenData
+		namespaces []string
+	}{
+		{&chainid.TokenData{ID: 1, Role: chainid.AdministratorRole}, []string{"default", "team-a", "team-b"}},
+		{&chainid.TokenData{ID: 2, Role: chainid.StandardUserRole}, []string{"team-a"}},
+		{&chainid.TokenData{ID: 3, Role: chainid.StandardUserRole}, []string{"team-b"}},
+		{&chainid.TokenData{ID: 4, Role: chainid.StandardUserRole}, []string{}},
+	}
+
+	for _, test := range tests {
+		recorder := serveKubernetesRequest(proxy, http.MethodGet, "/api/v1/namespaces", test.tokenData)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("user %d: expected status 200, got %d", test.tokenData.ID, recorder.Code)
+		}
+
+		var list struct {
+			Items []struct {
+				Metadata struct {
+					Name string `json:"name"`
+				} `json:"metadata"`
+			} `json:"items"`
+		}
+		err := json.NewDecoder(recorder.Body).Decode(&list)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		names := make([]string, 0)
+		for _, item := range list.Items {
+			names = append(names, item.Metadata.Name)
+		}
+		if len(names) != len(test.namespaces) {
+			t.Errorf("user %d: expected namespaces %v, got %v", test.tokenData.ID, test.namespaces, names)
+			continue
+		}
+		for i := range names {
+			if names[i] != test.namespaces[i] {
+				t.Errorf("user %d: expected namespaces %v, got %v", test.tokenData.ID, test.namespaces, names)
+				break
+			}
+		}
+	}
+
+	recorder := serveKubernetesRequest(proxy, http.MethodGet, "/api/v1/namespaces?watch=true", &chainid.TokenData{ID: 2, Role: chainid.StandardUserRole})
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf("expected a watch on the namespaces to be denied, got status %d", recorder.Code)
+	}
+}
@@ -1,129 +1,371 @@
-package proxy
-
-import (
-	"net/http"
-	"net/url"
-	"strings"
-
-	"github.com/orcaman/concurrent-map"
-	"github.com/chainid-io/dashboard"
-)
-
-type (
-	// Manager represents a service used to manage Docker proxies.
-	Manager struct {
-		proxyFactory     *proxyFactory
-		proxies          cmap.ConcurrentMap
-		extensionProxies cmap.ConcurrentMap
-	}
-
-	// ManagerParams represents the required parameters to create a new Manager instance.
-	ManagerParams struct {
-		ResourceControlService chainid.ResourceControlService
-		TeamMembershipService  chainid.TeamMembershipService
-		SettingsService        chainid.SettingsService
-		RegistryService        chainid.RegistryService
-		DockerHubService       chainid.DockerHubService
-		SignatureService       chainid.DigitalSignatureService
-	}
-)
-
-// NewManager initializes a new proxy Service
-func NewManager(parameters *ManagerParams) *Manager {
-	return &Manager{
-		proxies:          cmap.New(),
-		extensionProxies: cmap.New(),
-		proxyFactory: &proxyFactory{
-			ResourceControlService: parameters.ResourceControlService,
-			TeamMembershipService:  parameters.TeamMembershipService,
-			SettingsService:        parameters.SettingsService,
-			RegistryService:        parameters.RegistryService,
-			DockerHubService:       parameters.DockerHubService,
-			SignatureService:       parameters.SignatureService,
-		},
-	}
-}
-
-func (manager *Manager) createDockerProxy(endpointURL *url.URL, tlsConfig *chainid.TLSConfiguration) (http.Handler, error) {
-	if endpointURL.Scheme == "tcp" {
-		if tlsConfig.TLS || tlsConfig.TLSSkipVerify {
-			return manager.proxyFactory.newDockerHTTPSProxy(endpointURL, tlsConfig, false)
-		}
-		return manager.proxyFactory.newDockerHTTPProxy(endpointURL, false), nil
-	}
-	// Assume unix:// scheme
-	return manager.proxyFactory.newDockerSocketProxy(endpointURL.Path), nil
-}
-
-func (manager *Manager) createProxy(endpoint *chainid.Endpoint) (http.Handler, error) {
-	endpointURL, err := url.Parse(endpoint.URL)
-	if err != nil {
-		return nil, err
-	}
-
-	switch endpoint.Type {
-	case chainid.AgentOnDockerEnvironment:
-		return manager.proxyFactory.newDockerHTTPSProxy(endpointURL, &endpoint.TLSConfig, true)
-	case chainid.AzureEnvironment:
-		return newAzureProxy(&endpoint.AzureCredentials)
-	default:
-		return manager.createDockerProxy(endpointURL, &endpoint.TLSConfig)
-	}
-}
-
-// CreateAndRegisterProxy creates a new HTTP reverse proxy based on endpoint properties and and adds it to the registered proxies.
-// It can also be used to create a new HTTP reverse proxy and replace an already registered proxy.
-func (manager *Manager) CreateAndRegisterProxy(endpoint *chainid.Endpoint) (http.Handler, error) {
-	proxy, err := manager.createProxy(endpoint)
-	if err != nil {
-		return nil, err
-	}
-
-	manager.proxies.Set(string(endpoint.ID), proxy)
-	return proxy, nil
-}
-
-// GetProxy returns the proxy associated to a key
-func (manager *Manager) GetProxy(key string) http.Handler {
-	proxy, ok := manager.proxies.Get(key)
-	if !ok {
-		return nil
-	}
-	return proxy.(http.Handler)
-}
-
-// DeleteProxy deletes the proxy associated to a key
-func (manager *Manager) DeleteProxy(key string) {
-	manager.proxies.Remove(key)
-}
-
-// CreateAndRegisterExtensionProxy creates a new HTTP reverse proxy for an extension and adds it to the registered proxies.
-func (manager *Manager) CreateAndRegisterExtensionProxy(key, extensionAPIURL string) (http.Handler, error) {
-
-	extensionURL, err := url.Parse(extensionAPIURL)
-	if err != nil {
-		return nil, err
-	}
-
-	proxy := manager.proxyFactory.newHTTPProxy(extensionURL)
-	manager.extensionProxies.Set(key, proxy)
-	return proxy, nil
-}
-
-// GetExtensionProxy returns the extension proxy associated to a key
-func (manager *Manager) GetExtensionProxy(key string) http.Handler {
-	proxy, ok := manager.extensionProxies.Get(key)
-	if !ok {
-		return nil
-	}
-	return proxy.(http.Handler)
-}
-
-// DeleteExtensionProxies deletes all the extension proxies associated to a key
-func (manager *Manager) DeleteExtensionProxies(key string) {
-	for _, k := range manager.extensionProxies.Keys() {
-		if strings.Contains(k, key+"_") {
-			manager.extensionProxies.Remove(k)
-		}
-	}
-}
+package proxy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/orcaman/concurrent-map"
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/http/security"
+	"github.com/chainid-io/dashboard/tunnel"
+)
+
+type (
+	// Manager represents a service used to manage Docker proxies. The proxies are cached by
+	// endpoint and evicted when the connection settings of their endpoint change, the manager
+	// must be registered as an observer of the EndpointService.
+	Manager struct {
+		proxyFactory     *proxyFactory
+		proxies          cmap.ConcurrentMap
+		extensionProxies cmap.ConcurrentMap
+
+		// The circuit breaker of an endpoint is shared by all the transports created for it.
+		mu              sync.Mutex
+		circuitBreakers map[chainid.EndpointID]*circuitBreaker
+	}
+
+	// cachedProxy represents a proxy along with the settings of the endpoint it was created for.
+	cachedProxy struct {
+		ConnectionSettings
+		handler   http.Handler
+		createdAt time.Time
+	}
+
+	// ConnectionSettings represents the settings used to connect to an endpoint when a proxy or
+	// a transport is created for it. tlsDigest is the digest of the TLS files, see tlsFilesDigest.
+	ConnectionSettings struct {
+		endpoint  chainid.Endpoint
+		tlsDigest string
+	}
+
+	// ManagerParams represents the required parameters to create a new Manager instance.
+	ManagerParams struct {
+		ResourceControlService chainid.ResourceControlService
+		TeamMembershipService  chainid.TeamMembershipService
+		SettingsService        chainid.SettingsService
+		RegistryService        chainid.RegistryService
+		DockerHubService       chainid.DockerHubService
+		SignatureService       chainid.DigitalSignatureService
+		Authorizer             *security.Authorizer
+		TunnelService          *tunnel.Service
+	}
+)
+
+// NewManager initializes a new proxy Service
+func NewManager(parameters *ManagerParams) *Manager {
+	return &Manager{
+		proxies:          cmap.New(),
+		extensionProxies: cmap.New(),
+		circuitBreakers:  make(map[chainid.EndpointID]*circuitBreaker),
+		proxyFactory: &proxyFactory{
+			ResourceControlService: parameters.ResourceControlService,
+			TeamMembershipService:  parameters.TeamMembershipService,
+			SettingsService:        parameters.SettingsService,
+			RegistryService:        parameters.RegistryService,
+			DockerHubService:       parameters.DockerHubService,
+			SignatureService:       parameters.SignatureService,
+			Authorizer:             parameters.Authorizer,
+			TunnelService:          parameters.TunnelService,
+		},
+	}
+}
+
+func (manager *Manager) createDockerProxy(endpoint *chainid.Endpoint, endpointURL *url.URL) (http.Handler, error) {
+	tlsConfig := &endpoint.TLSConfig
+	if endpointURL.Scheme == "tcp" {
+		if tlsConfig.TLS || tlsConfig.TLSSkipVerify {
+			return manager.proxyFactory.newDockerHTTPSProxy(endpointURL, tlsConfig, false)
+		}
+		return manager.proxyFactory.newDockerHTTPProxy(endpointURL, false), nil
+	}
+	if endpointURL.Scheme == "ssh" {
+		return manager.proxyFactory.newDockerSSHProxy(endpoint.URL, &endpoint.SSHConfig)
+	}
+	// Assume unix:// scheme
+	return manager.proxyFactory.newDockerSocketProxy(endpointURL.Path), nil
+}
+
+func (manager *Manager) createProxy(endpoint *chainid.Endpoint) (http.Handler, error) {
+	proxy, err := manager.createEndpointProxy(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := dockerProxyTransport(proxy)
+	if transport != nil {
+		configureTransport(transport.dockerTransport, &endpoint.TransportSettings)
+		transport.circuitBreaker = manager.circuitBreaker(endpoint)
+	}
+
+	return proxy, nil
+}
+
+func (manager *Manager) createEndpointProxy(endpoint *chainid.Endpoint) (http.Handler, error) {
+	if endpoint.Type == chainid.EdgeAgentEnvironment {
+		return manager.proxyFactory.newDockerTunnelProxy(endpoint.ID)
+	}
+
+	endpointURL, err := url.Parse(endpoint.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch endpoint.Type {
+	case chainid.AgentOnDockerEnvironment:
+		return manager.proxyFactory.newDockerHTTPSProxy(endpointURL, &endpoint.TLSConfig, true)
+	case chainid.AzureEnvironment:
+		return newAzureProxy(&endpoint.AzureCredentials)
+	case chainid.KubernetesEnvironment:
+		return manager.proxyFactory.newKubernetesProxy(endpoint)
+	default:
+		return manager.createDockerProxy(endpoint, endpointURL)
+	}
+}
+
+// CreateAndRegisterProxy creates a new HTTP reverse proxy based on endpoint properties and and adds it to the registered proxies.
+// It can also be used to create a new HTTP reverse proxy and replace an already registered proxy.
+func (manager *Manager) CreateAndRegisterProxy(endpoint *chainid.Endpoint) (http.Handler, error) {
+	proxy, err := manager.createProxy(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	manager.proxies.Set(proxyKey(endpoint.ID), &cachedProxy{
+		ConnectionSettings: NewConnectionSettings(endpoint),
+		handler:            proxy,
+		createdAt:          time.Now(),
+	})
+	return proxy, nil
+}
+
+// EndpointChanged rebuilds the cached proxy of an endpoint when its connection settings change.
+// The proxy is evicted when it cannot be rebuilt, the error is then reported on the next request.
+func (manager *Manager) EndpointChanged(endpoint *chainid.Endpoint) {
+	value, ok := manager.proxies.Get(proxyKey(endpoint.ID))
+	if !ok || value.(*cachedProxy).Matches(endpoint) {
+		return
+	}
+
+	manager.deleteExtensionProxies(endpoint.ID)
+	_, err := manager.CreateAndRegisterProxy(endpoint)
+	if err != nil {
+		manager.DeleteProxy(endpoint.ID)
+	}
+}
+
+// EndpointDeleted evicts the proxies and the circuit breaker of a deleted endpoint.
+func (manager *Manager) EndpointDeleted(endpointID chainid.EndpointID) {
+	manager.DeleteProxy(endpointID)
+	manager.deleteExtensionProxies(endpointID)
+
+	manager.mu.Lock()
+	delete(manager.circuitBreakers, endpointID)
+	manager.mu.Unlock()
+}
+
+// Proxies returns the proxies cached by the manager, sorted by endpoint identifier.
+func (manager *Manager) Proxies() []chainid.ProxyInfo {
+	proxies := make([]chainid.ProxyInfo, 0)
+	for _, value := range manager.proxies.Items() {
+		cached := value.(*cachedProxy)
+		info := chainid.ProxyInfo{
+			EndpointID:     cached.endpoint.ID,
+			EndpointName:   cached.endpoint.Name,
+			EndpointType:   cached.endpoint.Type,
+			URL:            cached.endpoint.URL,
+			CreatedAt:      cached.createdAt.Unix(),
+			Extensions:     []chainid.EndpointExtensionType{},
+			CircuitBreaker: manager.CircuitBreakerStatus(cached.endpoint.ID),
+		}
+		for _, key := range manager.extensionProxies.Keys() {
+			if strings.HasPrefix(key, proxyKey(cached.endpoint.ID)+"_") {
+				extensionType, _ := strconv.Atoi(strings.TrimPrefix(key, proxyKey(cached.endpoint.ID)+"_"))
+				info.Extensions = append(info.Extensions, chainid.EndpointExtensionType(extensionType))
+			}
+		}
+		proxies = append(proxies, info)
+	}
+
+	sort.Slice(proxies, func(i, j int) bool { return proxies[i].EndpointID < proxies[j].EndpointID })
+	return proxies
+}
+
+// NewConnectionSettings records the connection settings of an endpoint along with the content
+// of its TLS files.
+func NewConnectionSettings(endpoint *chainid.Endpoint) ConnectionSettings {
+	return ConnectionSettings{
+		endpoint:  *endpoint,
+		tlsDigest: tlsFilesDigest(&endpoint.TLSConfig),
+	}
+}
+
+// Matches returns true when the proxy or the transport created with the settings can still be
+// used after the endpoint is updated. The status and the snapshots of an endpoint are updated
+// periodically and must not evict its proxy. The TLS files of an endpoint are replaced in place
+// when they are uploaded again, their content is compared along with their paths.
+func (settings *ConnectionSettings) Matches(updated *chainid.Endpoint) bool {
+	cached := &settings.endpoint
+	return cached.Type == updated.Type &&
+		cached.URL == updated.URL &&
+		cached.TLSConfig == updated.TLSConfig &&
+		cached.SSHConfig == updated.SSHConfig &&
+		cached.TransportSettings == updated.TransportSettings &&
+		cached.AzureCredentials == updated.AzureCredentials &&
+		cached.KubernetesCredentials == updated.KubernetesCredentials &&
+		reflect.DeepEqual(cached.KubernetesNamespaces, updated.KubernetesNamespaces) &&
+		reflect.DeepEqual(cached.Extensions, updated.Extensions) &&
+		settings.tlsDigest == tlsFilesDigest(&updated.TLSConfig)
+}
+
+// tlsFilesDigest returns a digest of the content of the TLS files of a configuration.
+// The files that cannot be read are skipped, the proxy reports the error when it is created.
+func tlsFilesDigest(config *chainid.TLSConfiguration) string {
+	hash := sha256.New()
+	for _, path := range []string{config.TLSCACertPath, config.TLSCertPath, config.TLSKeyPath} {
+		if path != "" {
+			content, err := ioutil.ReadFile(path)
+			if err == nil {
+				hash.Write(content)
+			}
+		}
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func proxyKey(endpointID chainid.EndpointID) string {
+	return strconv.Itoa(int(endpointID))
+}
+
+func extensionProxyKey(endpointID chainid.EndpointID, extensionType chainid.EndpointExtensionType) string {
+	return proxyKey(endpointID) + "_" + strconv.Itoa(int(extensionType))
+}
+
+// CreateDockerTransport creates a transport based on the one used by the proxy of a Docker endpoint
+// and returns it along with the URL of the Docker API. The access control of the proxy is not applied
+// to the requests sent through this transport, it must only be used by the server itself.
+func (manager *Manager) CreateDockerTransport(endpoint *chainid.Endpoint) (http.RoundTripper, string, error) {
+	if endpoint.Type == chainid.AzureEnvironment || endpoint.Type == chainid.KubernetesEnvironment {
+		return nil, "", chainid.ErrEndpointStatusNotSupported
+	}
+
+	endpointURL, err := url.Parse(endpoint.URL)
+	if err != nil {
+		return nil, "", err
+	}
+
+	proxy, err := manager.createProxy(endpoint)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch proxy := proxy.(type) {
+	case *httputil.ReverseProxy:
+		if endpoint.Type == chainid.EdgeAgentEnvironment {
+			return &unrestrictedTransport{proxy.Transport.(*proxyTransport)}, edgeAPIURL, nil
+		}
+
+		scheme := "http"
+		if endpoint.Type == chainid.AgentOnDockerEnvironment || endpoint.TLSConfig.TLS || endpoint.TLSConfig.TLSSkipVerify {
+			scheme = "https"
+		}
+		return &unrestrictedTransport{proxy.Transport.(*proxyTransport)}, scheme + "://" + endpointURL.Host, nil
+	case *socketProxy:
+		return &unrestrictedTransport{proxy.Transport}, "http://unixsocket", nil
+	}
+	return nil, "", chainid.ErrEndpointStatusNotSupported
+}
+
+// CircuitBreakerStatus returns the status of the circuit breaker of an endpoint. It returns nil
+// when no request has been sent to the endpoint.
+func (manager *Manager) CircuitBreakerStatus(endpointID chainid.EndpointID) *chainid.CircuitBreakerStatus {
+	manager.mu.Lock()
+	breaker := manager.circuitBreakers[endpointID]
+	manager.mu.Unlock()
+
+	if breaker == nil {
+		return nil
+	}
+	return breaker.status()
+}
+
+func (manager *Manager) circuitBreaker(endpoint *chainid.Endpoint) *circuitBreaker {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+
+	breaker := manager.circuitBreakers[endpoint.ID]
+	if breaker == nil {
+		breaker = newCircuitBreaker(&endpoint.TransportSettings)
+		manager.circuitBreakers[endpoint.ID] = breaker
+	} else {
+		breaker.configure(&endpoint.TransportSettings)
+	}
+	return breaker
+}
+
+// dockerProxyTransport returns the transport of a proxy to a Docker API, or nil for the
+// other proxies.
+func dockerProxyTransport(proxy http.Handler) *proxyTransport {
+	switch proxy := proxy.(type) {
+	case *httputil.ReverseProxy:
+		transport, _ := proxy.Transport.(*proxyTransport)
+		return transport
+	case *socketProxy:
+		return proxy.Transport
+	}
+	return nil
+}
+
+// GetProxy returns the cached proxy of an endpoint
+func (manager *Manager) GetProxy(endpointID chainid.EndpointID) http.Handler {
+	value, ok := manager.proxies.Get(proxyKey(endpointID))
+	if !ok {
+		return nil
+	}
+	return value.(*cachedProxy).handler
+}
+
+// DeleteProxy deletes the cached proxy of an endpoint
+func (manager *Manager) DeleteProxy(endpointID chainid.EndpointID) {
+	manager.proxies.Remove(proxyKey(endpointID))
+}
+
+// CreateAndRegisterExtensionProxy creates a new HTTP reverse proxy for an extension of an endpoint and adds it to the registered proxies.
+func (manager *Manager) CreateAndRegisterExtensionProxy(endpointID chainid.EndpointID, extensionType chainid.EndpointExtensionType, extensionAPIURL string) (http.Handler, error) {
+
+	extensionURL, err := url.Parse(extensionAPIURL)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := manager.proxyFactory.newHTTPProxy(extensionURL)
+	manager.extensionProxies.Set(extensionProxyKey(endpointID, extensionType), proxy)
+	return proxy, nil
+}
+
+// GetExtensionProxy returns the proxy of an extension of an endpoint
+func (manager *Manager) GetExtensionProxy(endpointID chainid.EndpointID, extensionType chainid.EndpointExtensionType) http.Handler {
+	proxy, ok := manager.extensionProxies.Get(extensionProxyKey(endpointID, extensionType))
+	if !ok {
+		return nil
+	}
+	return proxy.(http.Handler)
+}
+
+func (manager *Manager) deleteExtensionProxies(endpointID chainid.EndpointID) {
+	for _, key := range manager.extensionProxies.Keys() {
+		if strings.HasPrefix(key, proxyKey(endpointID)+"_") {
+			manager.extensionProxies.Remove(key)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package proxy
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chainid-io/dashboard"
+)
+
+func cachedProxyOf(manager *Manager, endpointID chainid.EndpointID) *cachedProxy {
+	value, ok := manager.proxies.Get(proxyKey(endpointID))
+	if !ok {
+		return nil
+	}
+	return value.(*cachedProxy)
+}
+
+func TestManagerProxyKeys(t *testing.T) {
+	manager := NewManager(&ManagerParams{})
+
+	for _, ID := range []chainid.EndpointID{1, 10, 1000} {
+		_, err := manager.CreateAndRegisterProxy(&chainid.Endpoint{ID: ID, URL: "tcp://127.0.0.1:2375"})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	proxies := manager.Proxies()
+	if len(proxies) != 3 || proxies[0].EndpointID != 1 || proxies[1].EndpointID != 10 || proxies[2].EndpointID != 1000 {
+		t.Fatalf("unexpected proxies %+v", proxies)
+	}
+	if manager.GetProxy(100) != nil {
+		t.Errorf("expected no proxy for an unknown endpoint")
+	}
+}
+
+func TestManagerEndpointChanged(t *testing.T) {
+	manager := NewManager(&ManagerParams{})
+	endpoint := &chainid.Endpoint{ID: 1, URL: "tcp://127.0.0.1:2375"}
+	_, err := manager.CreateAndRegisterProxy(endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = manager.CreateAndRegisterExtensionProxy(1, chainid.StoridgeEndpointExtension, "http://127.0.0.1:8282")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cached := cachedProxyOf(manager, 1)
+
+	// The status checker updates the endpoints periodically, their proxies must be kept.
+	updated := *endpoint
+	updated.Status = chainid.EndpointStatusDown
+	updated.Snapshots = []chainid.Snapshot{{Time: 1}}
+	manager.EndpointChanged(&updated)
+	if cachedProxyOf(manager, 1) != cached {
+		t.Fatalf("expected the proxy to be kept after a status update")
+	}
+
+	updated.URL = "tcp://127.0.0.1:2376"
+	manager.EndpointChanged(&updated)
+	rebuilt := cachedProxyOf(manager, 1)
+	if rebuilt == nil || rebuilt == cached || rebuilt.endpoint.URL != updated.URL {
+		t.Fatalf("expected the proxy to be rebuilt after an URL update")
+	}
+	if manager.GetExtensionProxy(1, chainid.StoridgeEndpointExtension) != nil {
+		t.Errorf("expected the extension proxies to be evicted after an URL update")
+	}
+
+	updated.URL = "tcp://%zz"
+	manager.EndpointChanged(&updated)
+	if manager.GetProxy(1) != nil {
+		t.Errorf("expected the proxy to be evicted when it cannot be rebuilt")
+	}
+
+	// The endpoints without a cached proxy are left alone, their proxy is created on demand.
+	manager.EndpointChanged(&chainid.Endpoint{ID: 2, URL: "tcp://127.0.0.1:2375"})
+	if manager.GetProxy(2) != nil {
+		t.Errorf("expected no proxy to be created for an endpoint that is not proxied")
+	}
+}
+
+func TestManagerEndpointTLSFilesChanged(t *testing.T) {
+	dir, err := ioutil.TempDir("", "proxy-manager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	caCertPath := filepath.Join(dir, "ca.pem")
+	err = ioutil.WriteFile(caCertPath, []byte("first"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manager := NewManager(&ManagerParams{})
+	endpoint := &chainid.Endpoint{ID: 1, URL: "tcp://127.0.0.1:2376", TLSConfig: chainid.TLSConfiguration{TLS: true, TLSCACertPath: caCertPath}}
+	_, err = manager.CreateAndRegisterProxy(endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cached := cachedProxyOf(manager, 1)
+
+	manager.EndpointChanged(endpoint)
+	if cachedProxyOf(manager, 1) != cached {
+		t.Fatalf("expected the proxy to be kept when the TLS files are unchanged")
+	}
+
+	// The TLS files uploaded again are stored at the same path.
+	err = ioutil.WriteFile(caCertPath, []byte("second"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manager.EndpointChanged(endpoint)
+	if rebuilt := cachedProxyOf(manager, 1); rebuilt == nil || rebuilt == cached {
+		t.Errorf("expected the proxy to be rebuilt after the TLS files are replaced")
+	}
+}
+
+func TestManagerEndpointDeleted(t *testing.T) {
+	manager := NewManager(&ManagerParams{})
+	for _, ID := range []chainid.EndpointID{1, 11} {
+		_, err := manager.CreateAndRegisterProxy(&chainid.Endpoint{ID: ID, URL: "tcp://127.0.0.1:2375"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = manager.CreateAndRegisterExtensionProxy(ID, chainid.StoridgeEndpointExtension, "http://127.0.0.1:8282")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	manager.EndpointDeleted(1)
+
+	if manager.GetProxy(1) != nil || manager.GetExtensionProxy(1, chainid.StoridgeEndpointExtension) != nil {
+		t.Errorf("expected the proxies of the deleted endpoint to be evicted")
+	}
+	if manager.CircuitBreakerStatus(1) != nil {
+		t.Errorf("expected the circuit breaker of the deleted endpoint to be evicted")
+	}
+	if manager.GetProxy(11) == nil || manager.GetExtensionProxy(11, chainid.StoridgeEndpointExtension) == nil {
+		t.Errorf("expected the proxies of the other endpoints to be kept")
+	}
+}
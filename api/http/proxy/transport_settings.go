@@ -0,0 +1,48 @@
+package proxy
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+)
+
+const (
+	defaultDialTimeout         = 10 * time.Second
+	defaultTLSHandshakeTimeout = 10 * time.Second
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultKeepAlive           = 30 * time.Second
+	defaultMaxIdleConns        = 10
+)
+
+// configureTransport applies the transport settings of an endpoint to the transport used to
+// reach its Docker API. The response header timeout is disabled by default as some operations
+// of the Docker API only answer once they are completed.
+func configureTransport(transport *http.Transport, settings *chainid.EndpointTransportSettings) {
+	transport.TLSHandshakeTimeout = durationSetting(settings.TLSHandshakeTimeout, defaultTLSHandshakeTimeout)
+	transport.ResponseHeaderTimeout = durationSetting(settings.ResponseHeaderTimeout, 0)
+	transport.IdleConnTimeout = durationSetting(settings.IdleConnTimeout, defaultIdleConnTimeout)
+	transport.DisableKeepAlives = settings.DisableKeepAlives
+
+	transport.MaxIdleConnsPerHost = settings.MaxIdleConns
+	if transport.MaxIdleConnsPerHost <= 0 {
+		transport.MaxIdleConnsPerHost = defaultMaxIdleConns
+	}
+
+	// The transports dialing a socket, an SSH connection or a tunnel keep their own dial function.
+	if transport.Dial == nil && transport.DialContext == nil {
+		dialer := &net.Dialer{
+			Timeout:   durationSetting(settings.DialTimeout, defaultDialTimeout),
+			KeepAlive: durationSetting(settings.KeepAlive, defaultKeepAlive),
+		}
+		transport.DialContext = dialer.DialContext
+	}
+}
+
+func durationSetting(seconds int, defaultValue time.Duration) time.Duration {
+	if seconds <= 0 {
+		return defaultValue
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,25 @@
+package proxy
+
+import "net/http"
+
+// unrestrictedTransport represents a transport used by the server itself to execute HTTP requests
+// against the Docker API. The access control of the proxy is not applied, the requests are only
+// signed when the endpoint requires it.
+type unrestrictedTransport struct {
+	*proxyTransport
+}
+
+func (transport *unrestrictedTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	err := transport.signRequest(request)
+	if err != nil {
+		return nil, err
+	}
+	return transport.executeDockerRequest(request)
+}
+
+// CloseIdleConnections closes the idle connections of the transport once it is no longer used.
+func (transport *unrestrictedTransport) CloseIdleConnections() {
+	if transport.dockerTransport != nil {
+		transport.dockerTransport.CloseIdleConnections()
+	}
+}
@@ -1,187 +1,324 @@
-package security
-
-import (
-	"github.com/chainid-io/dashboard"
-	httperror "github.com/chainid-io/dashboard/http/error"
-
-	"net/http"
-	"strings"
-)
-
-type (
-	// RequestBouncer represents an entity that manages API request accesses
-	RequestBouncer struct {
-		jwtService            chainid.JWTService
-		userService           chainid.UserService
-		teamMembershipService chainid.TeamMembershipService
-		authDisabled          bool
-	}
-
-	// RestrictedRequestContext is a data structure containing information
-	// used in RestrictedAccess
-	RestrictedRequestContext struct {
-		IsAdmin         bool
-		IsTeamLeader    bool
-		UserID          chainid.UserID
-		UserMemberships []chainid.TeamMembership
-	}
-)
-
-// NewRequestBouncer initializes a new RequestBouncer
-func NewRequestBouncer(jwtService chainid.JWTService, userService chainid.UserService, teamMembershipService chainid.TeamMembershipService, authDisabled bool) *RequestBouncer {
-	return &RequestBouncer{
-		jwtService:            jwtService,
-		userService:           userService,
-		teamMembershipService: teamMembershipService,
-		authDisabled:          authDisabled,
-	}
-}
-
-// PublicAccess defines a security check for public endpoints.
-// No authentication is required to access these endpoints.
-func (bouncer *RequestBouncer) PublicAccess(h http.Handler) http.Handler {
-	h = mwSecureHeaders(h)
-	return h
-}
-
-// AuthenticatedAccess defines a security check for private endpoints.
-// Authentication is required to access these endpoints.
-func (bouncer *RequestBouncer) AuthenticatedAccess(h http.Handler) http.Handler {
-	h = bouncer.mwCheckAuthentication(h)
-	h = mwSecureHeaders(h)
-	return h
-}
-
-// RestrictedAccess defines a security check for restricted endpoints.
-// Authentication is required to access these endpoints.
-// The request context will be enhanced with a RestrictedRequestContext object
-// that might be used later to authorize/filter access to resources.
-func (bouncer *RequestBouncer) RestrictedAccess(h http.Handler) http.Handler {
-	h = bouncer.mwUpgradeToRestrictedRequest(h)
-	h = bouncer.AuthenticatedAccess(h)
-	return h
-}
-
-// AdministratorAccess defines a chain of middleware for restricted endpoints.
-// Authentication as well as administrator role are required to access these endpoints.
-func (bouncer *RequestBouncer) AdministratorAccess(h http.Handler) http.Handler {
-	h = mwCheckAdministratorRole(h)
-	h = bouncer.AuthenticatedAccess(h)
-	return h
-}
-
-// mwSecureHeaders provides secure headers middleware for handlers.
-func mwSecureHeaders(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Add("X-Content-Type-Options", "nosniff")
-		w.Header().Add("X-Frame-Options", "DENY")
-		next.ServeHTTP(w, r)
-	})
-}
-
-// mwUpgradeToRestrictedRequest will enhance the current request with
-// a new RestrictedRequestContext object.
-func (bouncer *RequestBouncer) mwUpgradeToRestrictedRequest(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		tokenData, err := RetrieveTokenData(r)
-		if err != nil {
-			httperror.WriteErrorResponse(w, chainid.ErrResourceAccessDenied, http.StatusForbidden, nil)
-			return
-		}
-
-		requestContext, err := bouncer.newRestrictedContextRequest(tokenData.ID, tokenData.Role)
-		if err != nil {
-			httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, nil)
-			return
-		}
-
-		ctx := storeRestrictedRequestContext(r, requestContext)
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
-}
-
-// mwCheckAdministratorRole check the role of the user associated to the request
-func mwCheckAdministratorRole(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		tokenData, err := RetrieveTokenData(r)
-		if err != nil || tokenData.Role != chainid.AdministratorRole {
-			httperror.WriteErrorResponse(w, chainid.ErrResourceAccessDenied, http.StatusForbidden, nil)
-			return
-		}
-
-		next.ServeHTTP(w, r)
-	})
-}
-
-// mwCheckAuthentication provides Authentication middleware for handlers
-func (bouncer *RequestBouncer) mwCheckAuthentication(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		var tokenData *chainid.TokenData
-		if !bouncer.authDisabled {
-			var token string
-
-			// Get token from the Authorization header
-			tokens, ok := r.Header["Authorization"]
-			if ok && len(tokens) >= 1 {
-				token = tokens[0]
-				token = strings.TrimPrefix(token, "Bearer ")
-			}
-
-			if token == "" {
-				httperror.WriteErrorResponse(w, chainid.ErrUnauthorized, http.StatusUnauthorized, nil)
-				return
-			}
-
-			var err error
-			tokenData, err = bouncer.jwtService.ParseAndVerifyToken(token)
-			if err != nil {
-				httperror.WriteErrorResponse(w, err, http.StatusUnauthorized, nil)
-				return
-			}
-
-			_, err = bouncer.userService.User(tokenData.ID)
-			if err != nil && err == chainid.ErrUserNotFound {
-				httperror.WriteErrorResponse(w, chainid.ErrUnauthorized, http.StatusUnauthorized, nil)
-				return
-			} else if err != nil {
-				httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, nil)
-				return
-			}
-		} else {
-			tokenData = &chainid.TokenData{
-				Role: chainid.AdministratorRole,
-			}
-		}
-
-		ctx := storeTokenData(r, tokenData)
-		next.ServeHTTP(w, r.WithContext(ctx))
-		return
-	})
-}
-
-func (bouncer *RequestBouncer) newRestrictedContextRequest(userID chainid.UserID, userRole chainid.UserRole) (*RestrictedRequestContext, error) {
-	requestContext := &RestrictedRequestContext{
-		IsAdmin: true,
-		UserID:  userID,
-	}
-
-	if userRole != chainid.AdministratorRole {
-		requestContext.IsAdmin = false
-		memberships, err := bouncer.teamMembershipService.TeamMembershipsByUserID(userID)
-		if err != nil {
-			return nil, err
-		}
-
-		isTeamLeader := false
-		for _, membership := range memberships {
-			if membership.Role == chainid.TeamLeader {
-				isTeamLeader = true
-			}
-		}
-
-		requestContext.IsTeamLeader = isTeamLeader
-		requestContext.UserMemberships = memberships
-	}
-
-	return requestContext, nil
-}
+package security
+
+import (
+	"github.com/chainid-io/dashboard"
+	httperror "github.com/chainid-io/dashboard/http/error"
+
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type (
+	// RequestBouncer represents an entity that manages API request accesses
+	RequestBouncer struct {
+		jwtService            chainid.JWTService
+		userService           chainid.UserService
+		teamMembershipService chainid.TeamMembershipService
+		settingsService       chainid.SettingsService
+		endpointService       chainid.EndpointService
+		authorizer            *Authorizer
+		authDisabled          bool
+		hstsMaxAge            time.Duration
+	}
+
+	// RestrictedRequestContext is a data structure containing information
+	// used in RestrictedAccess
+	RestrictedRequestContext struct {
+		IsAdmin         bool
+		IsTeamLeader    bool
+		UserID          chainid.UserID
+		UserMemberships []chainid.TeamMembership
+	}
+)
+
+// NewRequestBouncer initializes a new RequestBouncer
+func NewRequestBouncer(jwtService chainid.JWTService, userService chainid.UserService, teamMembershipService chainid.TeamMembershipService, settingsService chainid.SettingsService, endpointService chainid.EndpointService, authorizer *Authorizer, authDisabled bool) *RequestBouncer {
+	return &RequestBouncer{
+		jwtService:            jwtService,
+		userService:           userService,
+		teamMembershipService: teamMembershipService,
+		settingsService:       settingsService,
+		endpointService:       endpointService,
+		authorizer:            authorizer,
+		authDisabled:          authDisabled,
+	}
+}
+
+// PublicAccess defines a security check for public endpoints.
+// No authentication is required to access these endpoints.
+func (bouncer *RequestBouncer) PublicAccess(h http.Handler) http.Handler {
+	h = bouncer.mwSecureHeaders(h)
+	return h
+}
+
+// AuthenticatedAccess defines a security check for private endpoints.
+// Authentication is required to access these endpoints.
+func (bouncer *RequestBouncer) AuthenticatedAccess(h http.Handler) http.Handler {
+	h = bouncer.mwCheckAuthentication(h, true)
+	h = bouncer.mwSecureHeaders(h)
+	return h
+}
+
+// AccountSetupAccess defines a security check for the endpoints used to complete
+// the setup of a user account (e.g. password change, two-factor authentication enrollment).
+// Authentication is required to access these endpoints but the account requirements
+// defined in the settings are not enforced.
+func (bouncer *RequestBouncer) AccountSetupAccess(h http.Handler) http.Handler {
+	h = bouncer.mwCheckAuthentication(h, false)
+	h = bouncer.mwSecureHeaders(h)
+	return h
+}
+
+// RestrictedAccess defines a security check for restricted endpoints.
+// Authentication is required to access these endpoints.
+// The request context will be enhanced with a RestrictedRequestContext object
+// that might be used later to authorize/filter access to resources.
+func (bouncer *RequestBouncer) RestrictedAccess(h http.Handler) http.Handler {
+	h = bouncer.mwUpgradeToRestrictedRequest(h)
+	h = bouncer.AuthenticatedAccess(h)
+	return h
+}
+
+// AdministratorAccess defines a chain of middleware for restricted endpoints.
+// Authentication as well as administrator role are required to access these endpoints.
+func (bouncer *RequestBouncer) AdministratorAccess(h http.Handler) http.Handler {
+	h = mwCheckAdministratorRole(h)
+	h = bouncer.AuthenticatedAccess(h)
+	return h
+}
+
+// PermissionAccess defines a chain of middleware for endpoints protected by permissions.
+// Authentication is required to access these endpoints and the user must be granted
+// at least one of the permissions through a global role assignment.
+func (bouncer *RequestBouncer) PermissionAccess(h http.Handler, permissions ...chainid.Permission) http.Handler {
+	h = bouncer.mwCheckPermission(h, permissions, nil)
+	h = bouncer.AuthenticatedAccess(h)
+	return h
+}
+
+// EndpointPermissionAccess defines a chain of middleware for the endpoints routes protected by permissions,
+// the endpoint is identified by the id variable of the route. Authentication is required to access these
+// endpoints and the user must be granted at least one of the permissions through a global role assignment
+// or a role assignment scoped to the group of the endpoint.
+func (bouncer *RequestBouncer) EndpointPermissionAccess(h http.Handler, permissions ...chainid.Permission) http.Handler {
+	h = bouncer.mwCheckPermission(h, permissions, bouncer.endpointGroupID)
+	h = bouncer.AuthenticatedAccess(h)
+	return h
+}
+
+// EnableHSTS sets the Strict-Transport-Security header on the responses to the requests
+// served over SSL, the browsers will then only reach the instance over SSL for maxAge.
+func (bouncer *RequestBouncer) EnableHSTS(maxAge time.Duration) {
+	bouncer.hstsMaxAge = maxAge
+}
+
+// mwSecureHeaders provides secure headers middleware for handlers.
+func (bouncer *RequestBouncer) mwSecureHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("X-Content-Type-Options", "nosniff")
+		w.Header().Add("X-Frame-Options", "DENY")
+		if bouncer.hstsMaxAge > 0 && r.TLS != nil {
+			w.Header().Set("Strict-Transport-Security", "max-age="+strconv.Itoa(int(bouncer.hstsMaxAge.Seconds())))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// mwUpgradeToRestrictedRequest will enhance the current request with
+// a new RestrictedRequestContext object.
+func (bouncer *RequestBouncer) mwUpgradeToRestrictedRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenData, err := RetrieveTokenData(r)
+		if err != nil {
+			httperror.WriteErrorResponse(w, chainid.ErrResourceAccessDenied, http.StatusForbidden, nil)
+			return
+		}
+
+		requestContext, err := bouncer.newRestrictedContextRequest(tokenData.ID, tokenData.Role)
+		if err != nil {
+			httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, nil)
+			return
+		}
+
+		ctx := storeRestrictedRequestContext(r, requestContext)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// mwCheckAdministratorRole check the role of the user associated to the request
+func mwCheckAdministratorRole(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenData, err := RetrieveTokenData(r)
+		if err != nil || tokenData.Role != chainid.AdministratorRole {
+			httperror.WriteErrorResponse(w, chainid.ErrResourceAccessDenied, http.StatusForbidden, nil)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// mwCheckPermission checks that the user associated to the request is granted one of the permissions.
+// When groupID is set, it returns the endpoint group targeted by the request and the role assignments
+// scoped to this group are taken into account.
+func (bouncer *RequestBouncer) mwCheckPermission(next http.Handler, permissions []chainid.Permission, groupID func(r *http.Request) (chainid.EndpointGroupID, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenData, err := RetrieveTokenData(r)
+		if err != nil {
+			httperror.WriteErrorResponse(w, chainid.ErrResourceAccessDenied, http.StatusForbidden, nil)
+			return
+		}
+
+		var endpointGroupID chainid.EndpointGroupID
+		if groupID != nil && tokenData.Role != chainid.AdministratorRole {
+			endpointGroupID, err = groupID(r)
+			if err != nil {
+				httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, nil)
+				return
+			}
+		}
+
+		for _, permission := range permissions {
+			authorized, err := bouncer.authorizer.Authorized(tokenData, permission, endpointGroupID)
+			if err != nil {
+				httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, nil)
+				return
+			}
+
+			if authorized {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		httperror.WriteErrorResponse(w, chainid.ErrResourceAccessDenied, http.StatusForbidden, nil)
+	})
+}
+
+// endpointGroupID returns the group of the endpoint identified by the id variable of the route.
+// It returns 0 when the endpoint does not exist, only the global role assignments then apply.
+func (bouncer *RequestBouncer) endpointGroupID(r *http.Request) (chainid.EndpointGroupID, error) {
+	endpointID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return 0, nil
+	}
+
+	endpoint, err := bouncer.endpointService.Endpoint(chainid.EndpointID(endpointID))
+	if err == chainid.ErrEndpointNotFound {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return endpoint.GroupID, nil
+}
+
+// mwCheckAuthentication provides Authentication middleware for handlers.
+// When enforceAccountRequirements is set, requests associated to an account
+// that does not satisfy the account requirements are rejected.
+func (bouncer *RequestBouncer) mwCheckAuthentication(next http.Handler, enforceAccountRequirements bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var tokenData *chainid.TokenData
+		if !bouncer.authDisabled {
+			var token string
+
+			// Get token from the Authorization header
+			tokens, ok := r.Header["Authorization"]
+			if ok && len(tokens) >= 1 {
+				token = tokens[0]
+				token = strings.TrimPrefix(token, "Bearer ")
+			}
+
+			if token == "" {
+				httperror.WriteErrorResponse(w, chainid.ErrUnauthorized, http.StatusUnauthorized, nil)
+				return
+			}
+
+			var err error
+			tokenData, err = bouncer.jwtService.ParseAndVerifyToken(token)
+			if err != nil {
+				httperror.WriteErrorResponse(w, err, http.StatusUnauthorized, nil)
+				return
+			}
+
+			user, err := bouncer.userService.User(tokenData.ID)
+			if err != nil && err == chainid.ErrUserNotFound {
+				httperror.WriteErrorResponse(w, chainid.ErrUnauthorized, http.StatusUnauthorized, nil)
+				return
+			} else if err != nil {
+				httperror.WriteErrorResponse(w, err, http.StatusInternalServerError, nil)
+				return
+			}
+
+			if enforceAccountRequirements {
+				err = bouncer.checkAccountRequirements(user)
+				if err != nil {
+					httperror.WriteErrorResponse(w, err, http.StatusForbidden, nil)
+					return
+				}
+			}
+		} else {
+			tokenData = &chainid.TokenData{
+				Role: chainid.AdministratorRole,
+			}
+		}
+
+		ctx := StoreTokenData(r, tokenData)
+		next.ServeHTTP(w, r.WithContext(ctx))
+		return
+	})
+}
+
+// checkAccountRequirements returns an error if the account does not satisfy
+// the requirements defined in the settings.
+func (bouncer *RequestBouncer) checkAccountRequirements(user *chainid.User) error {
+	settings, err := bouncer.settingsService.Settings()
+	if err != nil {
+		return err
+	}
+
+	internalAuthentication := settings.AuthenticationMethod == chainid.AuthenticationInternal || user.ID == 1
+	if !internalAuthentication {
+		return nil
+	}
+
+	if user.MustChangePassword || IsPasswordExpired(user, &settings.PasswordPolicy, time.Now()) {
+		return chainid.ErrPasswordChangeRequired
+	}
+
+	if settings.EnforceTwoFactorAuthentication && !user.TOTPEnabled {
+		return chainid.ErrTwoFactorAuthenticationRequired
+	}
+
+	return nil
+}
+
+func (bouncer *RequestBouncer) newRestrictedContextRequest(userID chainid.UserID, userRole chainid.UserRole) (*RestrictedRequestContext, error) {
+	requestContext := &RestrictedRequestContext{
+		IsAdmin: true,
+		UserID:  userID,
+	}
+
+	if userRole != chainid.AdministratorRole {
+		requestContext.IsAdmin = false
+		memberships, err := bouncer.teamMembershipService.TeamMembershipsByUserID(userID)
+		if err != nil {
+			return nil, err
+		}
+
+		isTeamLeader := false
+		for _, membership := range memberships {
+			if membership.Role == chainid.TeamLeader {
+				isTeamLeader = true
+			}
+		}
+
+		requestContext.IsTeamLeader = isTeamLeader
+		requestContext.UserMemberships = memberships
+	}
+
+	return requestContext, nil
+}
@@ -0,0 +1,68 @@
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/kv"
+
+	"github.com/gorilla/mux"
+)
+
+func TestEndpointPermissionAccess(t *testing.T) {
+	store := kv.NewStore(kv.NewMemoryBackend())
+
+	role := &chainid.Role{Name: "operator", Permissions: []chainid.Permission{chainid.EndpointUpdatePermission}}
+	err := store.RoleService.CreateRole(role)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = store.RoleAssignmentService.CreateRoleAssignment(&chainid.RoleAssignment{RoleID: role.ID, UserID: 2, EndpointGroupID: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inGroup := &chainid.Endpoint{Name: "in-group", GroupID: 5}
+	outOfGroup := &chainid.Endpoint{Name: "out-of-group", GroupID: 6}
+	for _, endpoint := range []*chainid.Endpoint{inGroup, outOfGroup} {
+		err = store.EndpointService.CreateEndpoint(endpoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	authorizer := NewAuthorizer(store.RoleService, store.RoleAssignmentService, store.TeamMembershipService)
+	bouncer := NewRequestBouncer(nil, store.UserService, store.TeamMembershipService, store.SettingsService, store.EndpointService, authorizer, false)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	cases := []struct {
+		name     string
+		handler  http.Handler
+		id       string
+		expected int
+	}{
+		{"Endpoint inside the group of the assignment", bouncer.mwCheckPermission(next, []chainid.Permission{chainid.EndpointUpdatePermission}, bouncer.endpointGroupID), "1", http.StatusNoContent},
+		{"Endpoint outside of the group of the assignment", bouncer.mwCheckPermission(next, []chainid.Permission{chainid.EndpointUpdatePermission}, bouncer.endpointGroupID), "2", http.StatusForbidden},
+		{"Unknown endpoint", bouncer.mwCheckPermission(next, []chainid.Permission{chainid.EndpointUpdatePermission}, bouncer.endpointGroupID), "3", http.StatusForbidden},
+		{"Global permission check", bouncer.mwCheckPermission(next, []chainid.Permission{chainid.EndpointUpdatePermission}, nil), "1", http.StatusForbidden},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/endpoints/"+c.id, nil)
+			req = req.WithContext(StoreTokenData(req, &chainid.TokenData{ID: 2, Role: chainid.StandardUserRole}))
+			req = mux.SetURLVars(req, map[string]string{"id": c.id})
+
+			rr := httptest.NewRecorder()
+			c.handler.ServeHTTP(rr, req)
+			if rr.Code != c.expected {
+				t.Errorf("expected status code %d, got %d", c.expected, rr.Code)
+			}
+		})
+	}
+}
@@ -1,50 +1,64 @@
-package security
-
-import (
-	"context"
-	"net/http"
-
-	"github.com/chainid-io/dashboard"
-)
-
-type (
-	contextKey int
-)
-
-const (
-	contextAuthenticationKey contextKey = iota
-	contextRestrictedRequest
-)
-
-// storeTokenData stores a TokenData object inside the request context and returns the enhanced context.
-func storeTokenData(request *http.Request, tokenData *chainid.TokenData) context.Context {
-	return context.WithValue(request.Context(), contextAuthenticationKey, tokenData)
-}
-
-// RetrieveTokenData returns the TokenData object stored in the request context.
-func RetrieveTokenData(request *http.Request) (*chainid.TokenData, error) {
-	contextData := request.Context().Value(contextAuthenticationKey)
-	if contextData == nil {
-		return nil, chainid.ErrMissingContextData
-	}
-
-	tokenData := contextData.(*chainid.TokenData)
-	return tokenData, nil
-}
-
-// storeRestrictedRequestContext stores a RestrictedRequestContext object inside the request context
-// and returns the enhanced context.
-func storeRestrictedRequestContext(request *http.Request, requestContext *RestrictedRequestContext) context.Context {
-	return context.WithValue(request.Context(), contextRestrictedRequest, requestContext)
-}
-
-// RetrieveRestrictedRequestContext returns the RestrictedRequestContext object stored in the request context.
-func RetrieveRestrictedRequestContext(request *http.Request) (*RestrictedRequestContext, error) {
-	contextData := request.Context().Value(contextRestrictedRequest)
-	if contextData == nil {
-		return nil, chainid.ErrMissingSecurityContext
-	}
-
-	requestContext := contextData.(*RestrictedRequestContext)
-	return requestContext, nil
-}
+package security
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/chainid-io/dashboard"
+)
+
+type (
+	contextKey int
+)
+
+const (
+	contextAuthenticationKey contextKey = iota
+	contextRestrictedRequest
+	contextEndpointGroup
+)
+
+// StoreTokenData stores a TokenData object inside the request context and returns the enhanced context.
+func StoreTokenData(request *http.Request, tokenData *chainid.TokenData) context.Context {
+	return context.WithValue(request.Context(), contextAuthenticationKey, tokenData)
+}
+
+// RetrieveTokenData returns the TokenData object stored in the request context.
+func RetrieveTokenData(request *http.Request) (*chainid.TokenData, error) {
+	contextData := request.Context().Value(contextAuthenticationKey)
+	if contextData == nil {
+		return nil, chainid.ErrMissingContextData
+	}
+
+	tokenData := contextData.(*chainid.TokenData)
+	return tokenData, nil
+}
+
+// storeRestrictedRequestContext stores a RestrictedRequestContext object inside the request context
+// and returns the enhanced context.
+func storeRestrictedRequestContext(request *http.Request, requestContext *RestrictedRequestContext) context.Context {
+	return context.WithValue(request.Context(), contextRestrictedRequest, requestContext)
+}
+
+// RetrieveRestrictedRequestContext returns the RestrictedRequestContext object stored in the request context.
+func RetrieveRestrictedRequestContext(request *http.Request) (*RestrictedRequestContext, error) {
+	contextData := request.Context().Value(contextRestrictedRequest)
+	if contextData == nil {
+		return nil, chainid.ErrMissingSecurityContext
+	}
+
+	requestContext := contextData.(*RestrictedRequestContext)
+	return requestContext, nil
+}
+
+// StoreEndpointGroupID stores the identifier of the endpoint group targeted by the request
+// inside the request context and returns the enhanced context.
+func StoreEndpointGroupID(request *http.Request, groupID chainid.EndpointGroupID) context.Context {
+	return context.WithValue(request.Context(), contextEndpointGroup, groupID)
+}
+
+// RetrieveEndpointGroupID returns the endpoint group identifier stored in the request context.
+// It returns 0 when the request is not associated to an endpoint group.
+func RetrieveEndpointGroupID(request *http.Request) chainid.EndpointGroupID {
+	groupID, _ := request.Context().Value(contextEndpointGroup).(chainid.EndpointGroupID)
+	return groupID
+}
@@ -0,0 +1,80 @@
+package security
+
+import (
+	"time"
+
+	"github.com/chainid-io/dashboard"
+)
+
+const (
+	// loginDelayBase is the delay applied after the second consecutive failed login attempt.
+	// It is doubled after each subsequent failure.
+	loginDelayBase = 1 * time.Second
+	// loginDelayMax is the maximum delay between two login attempts.
+	loginDelayMax = 30 * time.Second
+)
+
+// LoginDelay returns the remaining duration a user must wait before being allowed to
+// try to log in again. The delay grows exponentially with the number of consecutive
+// failed login attempts.
+func LoginDelay(user *chainid.User, now time.Time) time.Duration {
+	if user.FailedLogins < 2 {
+		return 0
+	}
+
+	delay := loginDelayBase
+	for i := 2; i < user.FailedLogins && delay < loginDelayMax; i++ {
+		delay *= 2
+	}
+	if delay > loginDelayMax {
+		delay = loginDelayMax
+	}
+
+	remaining := time.Unix(user.LastFailedLogin, 0).Add(delay).Sub(now)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// IsAccountLocked checks whether the account of the user is locked. A lockout expires
+// once the lockout duration defined in the settings has elapsed.
+func IsAccountLocked(user *chainid.User, settings *chainid.LockoutSettings, now time.Time) bool {
+	if !user.Locked {
+		return false
+	}
+
+	if settings.LockoutDuration == 0 {
+		return true
+	}
+
+	expiration := time.Unix(user.LockedAt, 0).Add(time.Duration(settings.LockoutDuration) * time.Minute)
+	return now.Before(expiration)
+}
+
+// RegisterFailedLogin increments the failed login counter of the user and locks
+// the account when the threshold defined in the settings is reached.
+// It returns true if the account has been locked.
+func RegisterFailedLogin(user *chainid.User, settings *chainid.LockoutSettings, now time.Time) bool {
+	if user.Locked && !IsAccountLocked(user, settings, now) {
+		ResetFailedLogins(user)
+	}
+
+	user.FailedLogins++
+	user.LastFailedLogin = now.Unix()
+
+	if settings.MaxFailedAttempts > 0 && user.FailedLogins >= settings.MaxFailedAttempts && !user.Locked {
+		user.Locked = true
+		user.LockedAt = now.Unix()
+		return true
+	}
+	return false
+}
+
+// ResetFailedLogins resets the failed login counter of the user and unlocks the account.
+func ResetFailedLogins(user *chainid.User) {
+	user.FailedLogins = 0
+	user.LastFailedLogin = 0
+	user.Locked = false
+	user.LockedAt = 0
+}
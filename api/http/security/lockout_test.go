@@ -0,0 +1,111 @@
+package security
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+)
+
+func TestRegisterFailedLogin(t *testing.T) {
+	now := time.Unix(1000000, 0)
+	settings := &chainid.LockoutSettings{MaxFailedAttempts: 3, LockoutDuration: 15}
+
+	t.Run("Account locked once the threshold is reached", func(t *testing.T) {
+		user := &chainid.User{}
+		for i := 1; i < 3; i++ {
+			if RegisterFailedLogin(user, settings, now) {
+				t.Fatalf("expected the account not to be locked after %d failed attempts", i)
+			}
+		}
+		if !RegisterFailedLogin(user, settings, now) {
+			t.Fatal("expected the account to be locked after 3 failed attempts")
+		}
+		if !user.Locked || user.LockedAt != now.Unix() || user.FailedLogins != 3 {
+			t.Errorf("unexpected user state %+v", user)
+		}
+		if RegisterFailedLogin(user, settings, now) {
+			t.Error("expected an already locked account not to be reported as locked again")
+		}
+	})
+
+	t.Run("Lockout disabled", func(t *testing.T) {
+		user := &chainid.User{}
+		for i := 0; i < 10; i++ {
+			RegisterFailedLogin(user, &chainid.LockoutSettings{}, now)
+		}
+		if user.Locked || user.FailedLogins != 10 {
+			t.Errorf("unexpected user state %+v", user)
+		}
+	})
+
+	t.Run("Counter reset once the lockout has expired", func(t *testing.T) {
+		user := &chainid.User{Locked: true, LockedAt: now.Unix(), FailedLogins: 3}
+		later := now.Add(16 * time.Minute)
+		if RegisterFailedLogin(user, settings, later) {
+			t.Error("expected the account not to be locked after a single failed attempt")
+		}
+		if user.Locked || user.FailedLogins != 1 || user.LastFailedLogin != later.Unix() {
+			t.Errorf("unexpected user state %+v", user)
+		}
+	})
+}
+
+func TestIsAccountLocked(t *testing.T) {
+	now := time.Unix(1000000, 0)
+	user := &chainid.User{Locked: true, LockedAt: now.Unix()}
+
+	cases := []struct {
+		name     string
+		user     *chainid.User
+		duration int
+		at       time.Time
+		locked   bool
+	}{
+		{"Unlocked account", &chainid.User{}, 15, now, false},
+		{"Lockout in progress", user, 15, now.Add(14 * time.Minute), true},
+		{"Lockout expired", user, 15, now.Add(15 * time.Minute), false},
+		{"Lockout without expiration", user, 0, now.Add(24 * time.Hour), true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			settings := &chainid.LockoutSettings{MaxFailedAttempts: 3, LockoutDuration: c.duration}
+			if locked := IsAccountLocked(c.user, settings, c.at); locked != c.locked {
+				t.Errorf("expected locked to be %v, got %v", c.locked, locked)
+			}
+		})
+	}
+}
+
+func TestResetFailedLogins(t *testing.T) {
+	user := &chainid.User{Locked: true, LockedAt: 10, FailedLogins: 5, LastFailedLogin: 10}
+	ResetFailedLogins(user)
+	if user.Locked || user.LockedAt != 0 || user.FailedLogins != 0 || user.LastFailedLogin != 0 {
+		t.Errorf("expected the account to be unlocked, got %+v", user)
+	}
+}
+
+func TestLoginDelay(t *testing.T) {
+	now := time.Unix(1000000, 0)
+
+	cases := []struct {
+		failedLogins int
+		elapsed      time.Duration
+		delay        time.Duration
+	}{
+		{1, 0, 0},
+		{2, 0, 1 * time.Second},
+		{4, 0, 4 * time.Second},
+		{4, 3 * time.Second, 1 * time.Second},
+		{4, 10 * time.Second, 0},
+		{20, 0, 30 * time.Second},
+	}
+
+	for _, c := range cases {
+		user := &chainid.User{FailedLogins: c.failedLogins, LastFailedLogin: now.Unix()}
+		if delay := LoginDelay(user, now.Add(c.elapsed)); delay != c.delay {
+			t.Errorf("expected a delay of %v after %d failed logins and %v, got %v", c.delay, c.failedLogins, c.elapsed, delay)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package security
+
+import (
+	"time"
+	"unicode"
+
+	"github.com/chainid-io/dashboard"
+)
+
+// ValidatePasswordStrength checks that the password satisfies the length and
+// character classes requirements of the password policy.
+func ValidatePasswordStrength(password string, policy *chainid.PasswordPolicy) error {
+	if len([]rune(password)) < policy.MinLength {
+		return chainid.ErrPasswordTooShort
+	}
+
+	var hasUppercase, hasLowercase, hasDigit, hasSpecial bool
+	for _, c := range password {
+		switch {
+		case unicode.IsUpper(c):
+			hasUppercase = true
+		case unicode.IsLower(c):
+			hasLowercase = true
+		case unicode.IsDigit(c):
+			hasDigit = true
+		default:
+			hasSpecial = true
+		}
+	}
+
+	if (policy.RequireUppercase && !hasUppercase) ||
+		(policy.RequireLowercase && !hasLowercase) ||
+		(policy.RequireDigit && !hasDigit) ||
+		(policy.RequireSpecialCharacter && !hasSpecial) {
+		return chainid.ErrPasswordMissingCharacterClass
+	}
+
+	return nil
+}
+
+// IsPasswordExpired checks whether the password of the user is older than
+// the maximum age defined in the password policy.
+func IsPasswordExpired(user *chainid.User, policy *chainid.PasswordPolicy, now time.Time) bool {
+	if policy.MaxAge == 0 || user.PasswordChangedAt == 0 {
+		return false
+	}
+
+	expiration := time.Unix(user.PasswordChangedAt, 0).AddDate(0, 0, policy.MaxAge)
+	return !now.Before(expiration)
+}
+
+// SetUserPassword validates the password against the password policy and updates the password of the user.
+// The replaced password is kept in the password history of the user when the policy forbids password reuse.
+func SetUserPassword(user *chainid.User, password string, policy *chainid.PasswordPolicy, cryptoService chainid.CryptoService) error {
+	err := ValidatePasswordStrength(password, policy)
+	if err != nil {
+		return err
+	}
+
+	history := user.PasswordHistory
+	if user.Password != "" {
+		history = append([]string{user.Password}, history...)
+	}
+
+	if len(history) > policy.HistorySize {
+		history = history[:policy.HistorySize]
+	}
+
+	for _, hash := range history {
+		if cryptoService.CompareHashAndData(hash, password) == nil {
+			return chainid.ErrPasswordReused
+		}
+	}
+
+	hash, err := cryptoService.Hash(password)
+	if err != nil {
+		return chainid.ErrCryptoHashFailure
+	}
+
+	if len(history) > 0 && len(history) == policy.HistorySize {
+		history = history[:len(history)-1]
+	}
+
+	user.Password = hash
+	user.PasswordHistory = history
+	user.PasswordChangedAt = time.Now().Unix()
+	user.MustChangePassword = false
+	return nil
+}
@@ -0,0 +1,137 @@
+package security
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+)
+
+// plainCryptoService is a CryptoService storing the passwords with a prefix instead of a hash.
+type plainCryptoService struct{}
+
+func (plainCryptoService) Hash(data string) (string, error) {
+	return "hash:" + data, nil
+}
+
+func (plainCryptoService) CompareHashAndData(hash string, data string) error {
+	if hash != "hash:"+data {
+		return errors.New("password mismatch")
+	}
+	return nil
+}
+
+func TestValidatePasswordStrength(t *testing.T) {
+	policy := &chainid.PasswordPolicy{
+		MinLength:               8,
+		RequireUppercase:        true,
+		RequireLowercase:        true,
+		RequireDigit:            true,
+		RequireSpecialCharacter: true,
+	}
+
+	cases := []struct {
+		password string
+		policy   *chainid.PasswordPolicy
+		expected error
+	}{
+		{"Passw0rd!", policy, nil},
+		{"Pa0!", policy, chainid.ErrPasswordTooShort},
+		{"passw0rd!", policy, chainid.ErrPasswordMissingCharacterClass},
+		{"PASSW0RD!", policy, chainid.ErrPasswordMissingCharacterClass},
+		{"Password!", policy, chainid.ErrPasswordMissingCharacterClass},
+		{"Passw0rdd", policy, chainid.ErrPasswordMissingCharacterClass},
+		{"Pässw0rd€", policy, nil},
+		{"ab", &chainid.PasswordPolicy{MinLength: 3}, chainid.ErrPasswordTooShort},
+		{"ééé", &chainid.PasswordPolicy{MinLength: 3}, nil},
+		{"password", &chainid.PasswordPolicy{}, nil},
+	}
+
+	for _, c := range cases {
+		if err := ValidatePasswordStrength(c.password, c.policy); err != c.expected {
+			t.Errorf("expected %q to return %v, got %v", c.password, c.expected, err)
+		}
+	}
+}
+
+func TestIsPasswordExpired(t *testing.T) {
+	changedAt := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name    string
+		maxAge  int
+		changed int64
+		at      time.Time
+		expired bool
+	}{
+		{"Expiry disabled", 0, changedAt.Unix(), changedAt.AddDate(1, 0, 0), false},
+		{"Password change date unknown", 30, 0, changedAt, false},
+		{"Password within its maximum age", 30, changedAt.Unix(), changedAt.AddDate(0, 0, 29), false},
+		{"Password at its maximum age", 30, changedAt.Unix(), changedAt.AddDate(0, 0, 30), true},
+		{"Password older than its maximum age", 30, changedAt.Unix(), changedAt.AddDate(0, 2, 0), true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			user := &chainid.User{PasswordChangedAt: c.changed}
+			if expired := IsPasswordExpired(user, &chainid.PasswordPolicy{MaxAge: c.maxAge}, c.at); expired != c.expired {
+				t.Errorf("expected expired to be %v, got %v", c.expired, expired)
+			}
+		})
+	}
+}
+
+func TestSetUserPassword(t *testing.T) {
+	cryptoService := plainCryptoService{}
+
+	cases := []struct {
+		name            string
+		historySize     int
+		password        string
+		expected        error
+		expectedHistory []string
+	}{
+		{"Reuse allowed without history", 0, "current", nil, nil},
+		{"Current password reused", 2, "current", chainid.ErrPasswordReused, nil},
+		{"Previous password reused", 3, "first", chainid.ErrPasswordReused, nil},
+		{"Password older than the history", 2, "first", nil, []string{"hash:current"}},
+		{"New password", 3, "new", nil, []string{"hash:current", "hash:second"}},
+		{"Weak password", 0, "", chainid.ErrPasswordTooShort, nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			user := &chainid.User{
+				Password:           "hash:current",
+				PasswordHistory:    []string{"hash:second", "hash:first"},
+				MustChangePassword: true,
+			}
+			policy := &chainid.PasswordPolicy{MinLength: 1, HistorySize: c.historySize}
+
+			err := SetUserPassword(user, c.password, policy, cryptoService)
+			if err != c.expected {
+				t.Fatalf("expected %v, got %v", c.expected, err)
+			}
+
+			if err != nil {
+				if user.Password != "hash:current" || len(user.PasswordHistory) != 2 || !user.MustChangePassword {
+					t.Errorf("expected the user to be left unchanged, got %+v", user)
+				}
+				return
+			}
+
+			if user.Password != "hash:"+c.password || user.MustChangePassword || user.PasswordChangedAt == 0 {
+				t.Errorf("expected the password to be changed, got %+v", user)
+			}
+			if len(user.PasswordHistory) != len(c.expectedHistory) {
+				t.Fatalf("expected history %v, got %v", c.expectedHistory, user.PasswordHistory)
+			}
+			for i := range c.expectedHistory {
+				if user.PasswordHistory[i] != c.expectedHistory[i] {
+					t.Errorf("expected history %v, got %v", c.expectedHistory, user.PasswordHistory)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package security
+
+import "github.com/chainid-io/dashboard"
+
+// Permissions lists every permission that can be granted through a role.
+var Permissions = []chainid.Permission{
+	chainid.EndpointCreatePermission,
+	chainid.EndpointUpdatePermission,
+	chainid.EndpointDeletePermission,
+	chainid.EndpointGroupManagePermission,
+	chainid.RegistryManagePermission,
+	chainid.UserManagePermission,
+	chainid.TeamManagePermission,
+	chainid.SettingsManagePermission,
+	chainid.StackDeployPermission,
+	chainid.StackDeletePermission,
+	chainid.ContainerExecPermission,
+	chainid.ContainerPrunePermission,
+	chainid.VolumePrunePermission,
+	chainid.NodeUpdatePermission,
+	chainid.SwarmManagePermission,
+}
+
+// ValidPermission returns true if the permission is a known permission.
+func ValidPermission(permission chainid.Permission) bool {
+	for _, p := range Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorizer represents a service used to check the permissions granted to a user
+// through its role assignments.
+type Authorizer struct {
+	roleService           chainid.RoleService
+	roleAssignmentService chainid.RoleAssignmentService
+	teamMembershipService chainid.TeamMembershipService
+}
+
+// NewAuthorizer initializes a new Authorizer.
+func NewAuthorizer(roleService chainid.RoleService, roleAssignmentService chainid.RoleAssignmentService, teamMembershipService chainid.TeamMembershipService) *Authorizer {
+	return &Authorizer{
+		roleService:           roleService,
+		roleAssignmentService: roleAssignmentService,
+		teamMembershipService: teamMembershipService,
+	}
+}
+
+// Authorized returns true if the user associated to the token data is granted the permission.
+// When groupID is set, role assignments scoped to this endpoint group are taken into account
+// in addition to the global ones. Administrators are granted every permission.
+func (authorizer *Authorizer) Authorized(tokenData *chainid.TokenData, permission chainid.Permission, groupID chainid.EndpointGroupID) (bool, error) {
+	if tokenData.Role == chainid.AdministratorRole {
+		return true, nil
+	}
+
+	roles, err := authorizer.roleService.Roles()
+	if err != nil {
+		return false, err
+	}
+
+	assignments, err := authorizer.roleAssignmentService.RoleAssignments()
+	if err != nil {
+		return false, err
+	}
+
+	memberships, err := authorizer.teamMembershipService.TeamMembershipsByUserID(tokenData.ID)
+	if err != nil {
+		return false, err
+	}
+
+	return HasPermission(roles, assignments, tokenData.ID, memberships, permission, groupID), nil
+}
+
+// HasPermission returns true if one of the roles granted to a non-administrator user includes the permission.
+// The standard user role is always granted, other roles are granted through assignments to the user
+// or to one of its teams, either globally or for the specified endpoint group.
+func HasPermission(roles []chainid.Role, assignments []chainid.RoleAssignment, userID chainid.UserID, memberships []chainid.TeamMembership, permission chainid.Permission, groupID chainid.EndpointGroupID) bool {
+	granted := map[chainid.RoleID]bool{
+		chainid.StandardUserRoleID: true,
+	}
+
+	for _, assignment := range assignments {
+		if assignment.EndpointGroupID != 0 && assignment.EndpointGroupID != groupID {
+			continue
+		}
+
+		if assignment.UserID != 0 && assignment.UserID == userID {
+			granted[assignment.RoleID] = true
+			continue
+		}
+
+		for _, membership := range memberships {
+			if assignment.TeamID != 0 && assignment.TeamID == membership.TeamID {
+				granted[assignment.RoleID] = true
+			}
+		}
+	}
+
+	for _, role := range roles {
+		if !granted[role.ID] {
+			continue
+		}
+
+		for _, p := range role.Permissions {
+			if p == permission {
+				return true
+			}
+		}
+	}
+
+	return false
+}
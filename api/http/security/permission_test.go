@@ -0,0 +1,47 @@
+package security
+
+import (
+	"testing"
+
+	"github.com/chainid-io/dashboard"
+)
+
+func TestHasPermission(t *testing.T) {
+	roles := []chainid.Role{
+		{ID: chainid.StandardUserRoleID, Permissions: []chainid.Permission{chainid.StackDeployPermission}},
+		{ID: 3, Permissions: []chainid.Permission{chainid.NodeUpdatePermission}},
+	}
+	memberships := []chainid.TeamMembership{{UserID: 2, TeamID: 1}}
+
+	t.Run("Standard user role is always granted", func(t *testing.T) {
+		if !HasPermission(roles, nil, 2, nil, chainid.StackDeployPermission, 0) {
+			t.Errorf("expected permission %s to be granted", chainid.StackDeployPermission)
+		}
+		if HasPermission(roles, nil, 2, nil, chainid.NodeUpdatePermission, 0) {
+			t.Errorf("expected permission %s to be denied", chainid.NodeUpdatePermission)
+		}
+	})
+
+	t.Run("Role assigned to a team of the user", func(t *testing.T) {
+		assignments := []chainid.RoleAssignment{{RoleID: 3, TeamID: 1}}
+		if !HasPermission(roles, assignments, 2, memberships, chainid.NodeUpdatePermission, 0) {
+			t.Errorf("expected permission %s to be granted", chainid.NodeUpdatePermission)
+		}
+		if HasPermission(roles, assignments, 3, nil, chainid.NodeUpdatePermission, 0) {
+			t.Errorf("expected permission %s to be denied to a user outside of the team", chainid.NodeUpdatePermission)
+		}
+	})
+
+	t.Run("Role assigned on an endpoint group", func(t *testing.T) {
+		assignments := []chainid.RoleAssignment{{RoleID: 3, UserID: 2, EndpointGroupID: 5}}
+		if !HasPermission(roles, assignments, 2, nil, chainid.NodeUpdatePermission, 5) {
+			t.Errorf("expected permission %s to be granted inside the group", chainid.NodeUpdatePermission)
+		}
+		if HasPermission(roles, assignments, 2, nil, chainid.NodeUpdatePermission, 6) {
+			t.Errorf("expected permission %s to be denied outside of the group", chainid.NodeUpdatePermission)
+		}
+		if HasPermission(roles, assignments, 2, nil, chainid.NodeUpdatePermission, 0) {
+			t.Errorf("expected permission %s to be denied globally", chainid.NodeUpdatePermission)
+		}
+	})
+}
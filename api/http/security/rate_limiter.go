@@ -1,47 +1,126 @@
-package security
-
-import (
-	"net/http"
-	"strings"
-	"time"
-
-	"github.com/g07cha/defender"
-	"github.com/chainid-io/dashboard"
-	httperror "github.com/chainid-io/dashboard/http/error"
-)
-
-// RateLimiter represents an entity that manages request rate limiting
-type RateLimiter struct {
-	*defender.Defender
-}
-
-// NewRateLimiter initializes a new RateLimiter
-func NewRateLimiter(maxRequests int, duration time.Duration, banDuration time.Duration) *RateLimiter {
-	messages := make(chan struct{})
-	limiter := defender.New(maxRequests, duration, banDuration)
-	go limiter.CleanupTask(messages)
-	return &RateLimiter{
-		limiter,
-	}
-}
-
-// LimitAccess wraps current request with check if remote address does not goes above the defined limits
-func (limiter *RateLimiter) LimitAccess(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ip := StripAddrPort(r.RemoteAddr)
-		if banned := limiter.Inc(ip); banned == true {
-			httperror.WriteErrorResponse(w, chainid.ErrResourceAccessDenied, http.StatusForbidden, nil)
-			return
-		}
-		next.ServeHTTP(w, r)
-	})
-}
-
-// StripAddrPort removes port from IP address
-func StripAddrPort(addr string) string {
-	portIndex := strings.LastIndex(addr, ":")
-	if portIndex != -1 {
-		addr = addr[:portIndex]
-	}
-	return addr
-}
+package security
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/g07cha/defender"
+	"github.com/chainid-io/dashboard"
+	httperror "github.com/chainid-io/dashboard/http/error"
+)
+
+// RateLimiter represents an entity that manages request rate limiting
+type RateLimiter struct {
+	*defender.Defender
+	trustedProxies []*net.IPNet
+}
+
+// NewRateLimiter initializes a new RateLimiter
+func NewRateLimiter(maxRequests int, duration time.Duration, banDuration time.Duration) *RateLimiter {
+	messages := make(chan struct{})
+	limiter := defender.New(maxRequests, duration, banDuration)
+	go limiter.CleanupTask(messages)
+	return &RateLimiter{
+		Defender: limiter,
+	}
+}
+
+// SetTrustedProxies defines the list of reverse proxies (IP addresses or CIDR ranges)
+// allowed to specify the address of the client via the X-Forwarded-For header.
+func (limiter *RateLimiter) SetTrustedProxies(proxies []string) error {
+	trustedProxies := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		network, err := ParseIPNetwork(proxy)
+		if err != nil {
+			return err
+		}
+		trustedProxies = append(trustedProxies, network)
+	}
+	limiter.trustedProxies = trustedProxies
+	return nil
+}
+
+// ClientIP returns the IP address of the client associated to the request.
+// The X-Forwarded-For header is only honored when the request comes from a trusted proxy,
+// in which case the right-most address that is not a trusted proxy is returned.
+func (limiter *RateLimiter) ClientIP(r *http.Request) string {
+	ip := StripAddrPort(r.RemoteAddr)
+	if !limiter.isTrustedProxy(ip) {
+		return ip
+	}
+
+	forwardedFor := r.Header.Get("X-Forwarded-For")
+	if forwardedFor == "" {
+		return ip
+	}
+
+	addresses := strings.Split(forwardedFor, ",")
+	for i := len(addresses) - 1; i >= 0; i-- {
+		address := strings.TrimSpace(addresses[i])
+		if address == "" {
+			continue
+		}
+		ip = address
+		if !limiter.isTrustedProxy(address) {
+			break
+		}
+	}
+	return ip
+}
+
+func (limiter *RateLimiter) isTrustedProxy(address string) bool {
+	ip := net.ParseIP(strings.Trim(address, "[]"))
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range limiter.trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// LimitAccess wraps current request with check if remote address does not goes above the defined limits
+func (limiter *RateLimiter) LimitAccess(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := limiter.ClientIP(r)
+		if banned := limiter.Inc(ip); banned == true {
+			httperror.WriteErrorResponse(w, chainid.ErrResourceAccessDenied, http.StatusForbidden, nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// StripAddrPort removes port from IP address
+func StripAddrPort(addr string) string {
+	portIndex := strings.LastIndex(addr, ":")
+	if portIndex != -1 {
+		addr = addr[:portIndex]
+	}
+	return addr
+}
+
+// ParseIPNetwork parses an IP address or a CIDR range into a network.
+// An IP address is converted to a network containing only this address.
+func ParseIPNetwork(value string) (*net.IPNet, error) {
+	if strings.Contains(value, "/") {
+		_, network, err := net.ParseCIDR(value)
+		return network, err
+	}
+
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return nil, &net.ParseError{Type: "IP address", Text: value}
+	}
+
+	bits := 8 * net.IPv4len
+	if ip.To4() == nil {
+		bits = 8 * net.IPv6len
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
@@ -1,69 +1,136 @@
-package security
-
-import (
-	"net/http"
-	"net/http/httptest"
-	"testing"
-	"time"
-)
-
-func TestLimitAccess(t *testing.T) {
-	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-	})
-
-	t.Run("Request below the limit", func(t *testing.T) {
-		req := httptest.NewRequest("GET", "/", nil)
-		rr := httptest.NewRecorder()
-		rateLimiter := NewRateLimiter(10, 1*time.Second, 1*time.Hour)
-		handler := rateLimiter.LimitAccess(testHandler)
-
-		handler.ServeHTTP(rr, req)
-
-		if status := rr.Code; status != http.StatusOK {
-			t.Errorf("handler returned wrong status code: got %v want %v",
-				status, http.StatusOK)
-		}
-	})
-
-	t.Run("Request above the limit", func(t *testing.T) {
-		rateLimiter := NewRateLimiter(1, 1*time.Second, 1*time.Hour)
-		handler := rateLimiter.LimitAccess(testHandler)
-
-		ts := httptest.NewServer(handler)
-		defer ts.Close()
-		http.Get(ts.URL)
-		resp, err := http.Get(ts.URL)
-		if err != nil {
-			t.Fatal(err)
-		}
-
-		if status := resp.StatusCode; status != http.StatusForbidden {
-			t.Errorf("handler returned wrong status code: got %v want %v",
-				status, http.StatusForbidden)
-		}
-	})
-}
-
-func TestStripAddrPort(t *testing.T) {
-	t.Run("IP with port", func(t *testing.T) {
-		result := StripAddrPort("127.0.0.1:1000")
-		if result != "127.0.0.1" {
-			t.Errorf("Expected IP with address to be '127.0.0.1', but it was %s instead", result)
-		}
-	})
-
-	t.Run("IP without port", func(t *testing.T) {
-		result := StripAddrPort("127.0.0.1")
-		if result != "127.0.0.1" {
-			t.Errorf("Expected IP with address to be '127.0.0.1', but it was %s instead", result)
-		}
-	})
-
-	t.Run("Local IP", func(t *testing.T) {
-		result := StripAddrPort("[::1]:1000")
-		if result != "[::1]" {
-			t.Errorf("Expected IP with address to be '[::1]', but it was %s instead", result)
-		}
-	})
-}
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLimitAccess(t *testing.T) {
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	t.Run("Request below the limit", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/", nil)
+		rr := httptest.NewRecorder()
+		rateLimiter := NewRateLimiter(10, 1*time.Second, 1*time.Hour)
+		handler := rateLimiter.LimitAccess(testHandler)
+
+		handler.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v",
+				status, http.StatusOK)
+		}
+	})
+
+	t.Run("Request above the limit", func(t *testing.T) {
+		rateLimiter := NewRateLimiter(1, 1*time.Second, 1*time.Hour)
+		handler := rateLimiter.LimitAccess(testHandler)
+
+		ts := httptest.NewServer(handler)
+		defer ts.Close()
+		http.Get(ts.URL)
+		resp, err := http.Get(ts.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if status := resp.StatusCode; status != http.StatusForbidden {
+			t.Errorf("handler returned wrong status code: got %v want %v",
+				status, http.StatusForbidden)
+		}
+	})
+}
+
+func TestStripAddrPort(t *testing.T) {
+	t.Run("IP with port", func(t *testing.T) {
+		result := StripAddrPort("127.0.0.1:1000")
+		if result != "127.0.0.1" {
+			t.Errorf("Expected IP with address to be '127.0.0.1', but it was %s instead", result)
+		}
+	})
+
+	t.Run("IP without port", func(t *testing.T) {
+		result := StripAddrPort("127.0.0.1")
+		if result != "127.0.0.1" {
+			t.Errorf("Expected IP with address to be '127.0.0.1', but it was %s instead", result)
+		}
+	})
+
+	t.Run("Local IP", func(t *testing.T) {
+		result := StripAddrPort("[::1]:1000")
+		if result != "[::1]" {
+			t.Errorf("Expected IP with address to be '[::1]', but it was %s instead", result)
+		}
+	})
+}
+
+func TestParseIPNetwork(t *testing.T) {
+	cases := []struct {
+		value   string
+		network string
+		valid   bool
+	}{
+		{"10.0.0.1", "10.0.0.1/32", true},
+		{"10.0.0.0/8", "10.0.0.0/8", true},
+		{"::1", "::1/128", true},
+		{"fd00::/64", "fd00::/64", true},
+		{"10.0.0.0/33", "", false},
+		{"proxy.local", "", false},
+	}
+
+	for _, c := range cases {
+		network, err := ParseIPNetwork(c.value)
+		if !c.valid {
+			if err == nil {
+				t.Errorf("expected %q to be rejected", c.value)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("expected %q to be accepted, got %s", c.value, err)
+		} else if network.String() != c.network {
+			t.Errorf("expected %q to be parsed as %s, got %s", c.value, c.network, network)
+		}
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	rateLimiter := NewRateLimiter(10, 1*time.Second, 1*time.Hour)
+	err := rateLimiter.SetTrustedProxies([]string{"10.0.0.1", "192.168.0.0/16"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name          string
+		remoteAddr    string
+		forwardedFor  string
+		expectedValue string
+	}{
+		{"Direct request", "172.16.0.1:1000", "", "172.16.0.1"},
+		{"Header ignored from an untrusted client", "172.16.0.1:1000", "1.2.3.4", "172.16.0.1"},
+		{"Trusted proxy without header", "10.0.0.1:1000", "", "10.0.0.1"},
+		{"Trusted proxy", "10.0.0.1:1000", "1.2.3.4", "1.2.3.4"},
+		{"Address spoofed by the client", "10.0.0.1:1000", "6.6.6.6, 1.2.3.4", "1.2.3.4"},
+		{"Chain of trusted proxies", "10.0.0.1:1000", "1.2.3.4, 192.168.1.1", "1.2.3.4"},
+		{"Only trusted proxies", "10.0.0.1:1000", "192.168.1.1, 192.168.1.2", "192.168.1.1"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = c.remoteAddr
+			if c.forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", c.forwardedFor)
+			}
+
+			if ip := rateLimiter.ClientIP(req); ip != c.expectedValue {
+				t.Errorf("expected client IP to be %s, got %s", c.expectedValue, ip)
+			}
+		})
+	}
+}
@@ -1,160 +1,322 @@
-package http
-
-import (
-	"time"
-
-	"github.com/chainid-io/dashboard"
-	"github.com/chainid-io/dashboard/http/handler"
-	"github.com/chainid-io/dashboard/http/handler/extensions"
-	"github.com/chainid-io/dashboard/http/proxy"
-	"github.com/chainid-io/dashboard/http/security"
-
-	"net/http"
-	"path/filepath"
-)
-
-// Server implements the chainid.Server interface
-type Server struct {
-	BindAddress            string
-	AssetsPath             string
-	AuthDisabled           bool
-	EndpointManagement     bool
-	Status                 *chainid.Status
-	UserService            chainid.UserService
-	TeamService            chainid.TeamService
-	TeamMembershipService  chainid.TeamMembershipService
-	EndpointService        chainid.EndpointService
-	EndpointGroupService   chainid.EndpointGroupService
-	ResourceControlService chainid.ResourceControlService
-	SettingsService        chainid.SettingsService
-	CryptoService          chainid.CryptoService
-	JWTService             chainid.JWTService
-	FileService            chainid.FileService
-	RegistryService        chainid.RegistryService
-	DockerHubService       chainid.DockerHubService
-	StackService           chainid.StackService
-	StackManager           chainid.StackManager
-	LDAPService            chainid.LDAPService
-	GitService             chainid.GitService
-	SignatureService       chainid.DigitalSignatureService
-	Handler                *handler.Handler
-	SSL                    bool
-	SSLCert                string
-	SSLKey                 string
-}
-
-// Start starts the HTTP server
-func (server *Server) Start() error {
-	requestBouncer := security.NewRequestBouncer(server.JWTService, server.UserService, server.TeamMembershipService, server.AuthDisabled)
-	proxyManagerParameters := &proxy.ManagerParams{
-		ResourceControlService: server.ResourceControlService,
-		TeamMembershipService:  server.TeamMembershipService,
-		SettingsService:        server.SettingsService,
-		RegistryService:        server.RegistryService,
-		DockerHubService:       server.DockerHubService,
-		SignatureService:       server.SignatureService,
-	}
-	proxyManager := proxy.NewManager(proxyManagerParameters)
-	rateLimiter := security.NewRateLimiter(10, 1*time.Second, 1*time.Hour)
-
-	var fileHandler = handler.NewFileHandler(filepath.Join(server.AssetsPath, "public"))
-	var authHandler = handler.NewAuthHandler(requestBouncer, rateLimiter, server.AuthDisabled)
-	authHandler.UserService = server.UserService
-	authHandler.CryptoService = server.CryptoService
-	authHandler.JWTService = server.JWTService
-	authHandler.LDAPService = server.LDAPService
-	authHandler.SettingsService = server.SettingsService
-	var userHandler = handler.NewUserHandler(requestBouncer)
-	userHandler.UserService = server.UserService
-	userHandler.TeamService = server.TeamService
-	userHandler.TeamMembershipService = server.TeamMembershipService
-	userHandler.CryptoService = server.CryptoService
-	userHandler.ResourceControlService = server.ResourceControlService
-	userHandler.SettingsService = server.SettingsService
-	var teamHandler = handler.NewTeamHandler(requestBouncer)
-	teamHandler.TeamService = server.TeamService
-	teamHandler.TeamMembershipService = server.TeamMembershipService
-	var teamMembershipHandler = handler.NewTeamMembershipHandler(requestBouncer)
-	teamMembershipHandler.TeamMembershipService = server.TeamMembershipService
-	var statusHandler = handler.NewStatusHandler(requestBouncer, server.Status)
-	var settingsHandler = handler.NewSettingsHandler(requestBouncer)
-	settingsHandler.SettingsService = server.SettingsService
-	settingsHandler.LDAPService = server.LDAPService
-	settingsHandler.FileService = server.FileService
-	var templatesHandler = handler.NewTemplatesHandler(requestBouncer)
-	templatesHandler.SettingsService = server.SettingsService
-	var dockerHandler = handler.NewDockerHandler(requestBouncer)
-	dockerHandler.EndpointService = server.EndpointService
-	dockerHandler.EndpointGroupService = server.EndpointGroupService
-	dockerHandler.TeamMembershipService = server.TeamMembershipService
-	dockerHandler.ProxyManager = proxyManager
-	var azureHandler = handler.NewAzureHandler(requestBouncer)
-	azureHandler.EndpointService = server.EndpointService
-	azureHandler.EndpointGroupService = server.EndpointGroupService
-	azureHandler.TeamMembershipService = server.TeamMembershipService
-	azureHandler.ProxyManager = proxyManager
-	var websocketHandler = handler.NewWebSocketHandler()
-	websocketHandler.EndpointService = server.EndpointService
-	websocketHandler.SignatureService = server.SignatureService
-	var endpointHandler = handler.NewEndpointHandler(requestBouncer, server.EndpointManagement)
-	endpointHandler.EndpointService = server.EndpointService
-	endpointHandler.EndpointGroupService = server.EndpointGroupService
-	endpointHandler.FileService = server.FileService
-	endpointHandler.ProxyManager = proxyManager
-	var endpointGroupHandler = handler.NewEndpointGroupHandler(requestBouncer)
-	endpointGroupHandler.EndpointGroupService = server.EndpointGroupService
-	endpointGroupHandler.EndpointService = server.EndpointService
-	var registryHandler = handler.NewRegistryHandler(requestBouncer)
-	registryHandler.RegistryService = server.RegistryService
-	var dockerHubHandler = handler.NewDockerHubHandler(requestBouncer)
-	dockerHubHandler.DockerHubService = server.DockerHubService
-	var resourceHandler = handler.NewResourceHandler(requestBouncer)
-	resourceHandler.ResourceControlService = server.ResourceControlService
-	var uploadHandler = handler.NewUploadHandler(requestBouncer)
-	uploadHandler.FileService = server.FileService
-	var stackHandler = handler.NewStackHandler(requestBouncer)
-	stackHandler.FileService = server.FileService
-	stackHandler.StackService = server.StackService
-	stackHandler.EndpointService = server.EndpointService
-	stackHandler.ResourceControlService = server.ResourceControlService
-	stackHandler.StackManager = server.StackManager
-	stackHandler.GitService = server.GitService
-	stackHandler.RegistryService = server.RegistryService
-	stackHandler.DockerHubService = server.DockerHubService
-	var extensionHandler = handler.NewExtensionHandler(requestBouncer)
-	extensionHandler.EndpointService = server.EndpointService
-	extensionHandler.ProxyManager = proxyManager
-	var storidgeHandler = extensions.NewStoridgeHandler(requestBouncer)
-	storidgeHandler.EndpointService = server.EndpointService
-	storidgeHandler.EndpointGroupService = server.EndpointGroupService
-	storidgeHandler.TeamMembershipService = server.TeamMembershipService
-	storidgeHandler.ProxyManager = proxyManager
-
-	server.Handler = &handler.Handler{
-		AuthHandler:           authHandler,
-		UserHandler:           userHandler,
-		TeamHandler:           teamHandler,
-		TeamMembershipHandler: teamMembershipHandler,
-		EndpointHandler:       endpointHandler,
-		EndpointGroupHandler:  endpointGroupHandler,
-		RegistryHandler:       registryHandler,
-		DockerHubHandler:      dockerHubHandler,
-		ResourceHandler:       resourceHandler,
-		SettingsHandler:       settingsHandler,
-		StatusHandler:         statusHandler,
-		StackHandler:          stackHandler,
-		TemplatesHandler:      templatesHandler,
-		DockerHandler:         dockerHandler,
-		AzureHandler:          azureHandler,
-		WebSocketHandler:      websocketHandler,
-		FileHandler:           fileHandler,
-		UploadHandler:         uploadHandler,
-		ExtensionHandler:      extensionHandler,
-		StoridgeHandler:       storidgeHandler,
-	}
-
-	if server.SSL {
-		return http.ListenAndServeTLS(server.BindAddress, server.SSLCert, server.SSLKey, server.Handler)
-	}
-	return http.ListenAndServe(server.BindAddress, server.Handler)
-}
+package http
+
+import (
+	"crypto/tls"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/certificate"
+	"github.com/chainid-io/dashboard/cron"
+	"github.com/chainid-io/dashboard/http/handler"
+	"github.com/chainid-io/dashboard/http/handler/extensions"
+	"github.com/chainid-io/dashboard/http/proxy"
+	"github.com/chainid-io/dashboard/http/security"
+	"github.com/chainid-io/dashboard/search"
+	"github.com/chainid-io/dashboard/tunnel"
+
+	"net/http"
+	"path/filepath"
+)
+
+// Server implements the chainid.Server interface
+type Server struct {
+	BindAddress            string
+	AssetsPath             string
+	AuthDisabled           bool
+	SecretsEncrypted       bool
+	EndpointManagement     bool
+	Status                 *chainid.Status
+	UserService            chainid.UserService
+	TeamService            chainid.TeamService
+	TeamMembershipService  chainid.TeamMembershipService
+	EndpointService        chainid.EndpointService
+	EndpointGroupService   chainid.EndpointGroupService
+	ResourceControlService chainid.ResourceControlService
+	SettingsService        chainid.SettingsService
+	CryptoService          chainid.CryptoService
+	TOTPService            chainid.TOTPService
+	JWTService             chainid.JWTService
+	FileService            chainid.FileService
+	RegistryService        chainid.RegistryService
+	DockerHubService       chainid.DockerHubService
+	StackService           chainid.StackService
+	RoleService            chainid.RoleService
+	RoleAssignmentService  chainid.RoleAssignmentService
+	AuditLogService        chainid.AuditLogService
+	BackupService          chainid.BackupService
+	BackupStatusService    chainid.BackupStatusService
+	BackupScheduler        chainid.BackupScheduler
+	ConfigService          chainid.ConfigService
+	ClusterService         chainid.ClusterService
+	StackManager           chainid.StackManager
+	LDAPService            chainid.LDAPService
+	GitService             chainid.GitService
+	SignatureService       chainid.DigitalSignatureService
+	Handler                *handler.Handler
+	HealthCheckInterval    string
+	SnapshotInterval       string
+	SSL                    bool
+	SSLRedirectAddress     string
+	HSTSMaxAge             string
+	CertificateManager     *certificate.Manager
+	TrustedProxies         []string
+}
+
+// Start starts the HTTP server
+func (server *Server) Start() error {
+	tunnelService := tunnel.NewService()
+	authorizer := security.NewAuthorizer(server.RoleService, server.RoleAssignmentService, server.TeamMembershipService)
+	requestBouncer := security.NewRequestBouncer(server.JWTService, server.UserService, server.TeamMembershipService, server.SettingsService, server.EndpointService, authorizer, server.AuthDisabled)
+	proxyManagerParameters := &proxy.ManagerParams{
+		ResourceControlService: server.ResourceControlService,
+		TeamMembershipService:  server.TeamMembershipService,
+		SettingsService:        server.SettingsService,
+		RegistryService:        server.RegistryService,
+		DockerHubService:       server.DockerHubService,
+		SignatureService:       server.SignatureService,
+		Authorizer:             authorizer,
+		TunnelService:          tunnelService,
+	}
+	proxyManager := proxy.NewManager(proxyManagerParameters)
+	server.EndpointService.RegisterObserver(proxyManager)
+	searchIndex := search.NewIndex(server.EndpointService)
+	server.EndpointService.RegisterObserver(searchIndex)
+	rateLimiter := security.NewRateLimiter(10, 1*time.Second, 1*time.Hour)
+	err := rateLimiter.SetTrustedProxies(server.TrustedProxies)
+	if err != nil {
+		return err
+	}
+
+	hstsMaxAge, err := time.ParseDuration(server.HSTSMaxAge)
+	if err != nil {
+		return err
+	}
+	requestBouncer.EnableHSTS(hstsMaxAge)
+
+	endpointStatusChecker := cron.NewEndpointStatusChecker(server.EndpointService, server.ClusterService, proxyManager)
+	err = endpointStatusChecker.Start(server.HealthCheckInterval)
+	if err != nil {
+		return err
+	}
+	defer endpointStatusChecker.Stop()
+
+	endpointSnapshotter := cron.NewEndpointSnapshotter(server.EndpointService, server.ClusterService, proxyManager)
+	err = endpointSnapshotter.Start(server.SnapshotInterval)
+	if err != nil {
+		return err
+	}
+	defer endpointSnapshotter.Stop()
+
+	var aggregateHandler = handler.NewAggregateHandler(requestBouncer)
+	aggregateHandler.EndpointService = server.EndpointService
+	aggregateHandler.EndpointGroupService = server.EndpointGroupService
+	aggregateHandler.ProxyManager = proxyManager
+	var auditHandler = handler.NewAuditHandler(requestBouncer, rateLimiter)
+	auditHandler.AuditLogService = server.AuditLogService
+	auditHandler.JWTService = server.JWTService
+	var backupHandler = handler.NewBackupHandler(requestBouncer)
+	backupHandler.BackupService = server.BackupService
+	backupHandler.FileService = server.FileService
+	backupHandler.SignatureService = server.SignatureService
+	backupHandler.EndpointService = server.EndpointService
+	backupHandler.ProxyManager = proxyManager
+	backupHandler.SettingsService = server.SettingsService
+	backupHandler.BackupScheduler = server.BackupScheduler
+	backupHandler.BackupStatusService = server.BackupStatusService
+	var clusterHandler = handler.NewClusterHandler(requestBouncer)
+	clusterHandler.ClusterService = server.ClusterService
+	var configHandler = handler.NewConfigHandler(requestBouncer)
+	configHandler.ConfigService = server.ConfigService
+	var debugHandler = handler.NewDebugHandler(requestBouncer)
+	debugHandler.ProxyManager = proxyManager
+	var fileHandler = handler.NewFileHandler(filepath.Join(server.AssetsPath, "public"))
+	var authHandler = handler.NewAuthHandler(requestBouncer, rateLimiter, server.AuthDisabled)
+	authHandler.UserService = server.UserService
+	authHandler.CryptoService = server.CryptoService
+	authHandler.JWTService = server.JWTService
+	authHandler.LDAPService = server.LDAPService
+	authHandler.SettingsService = server.SettingsService
+	authHandler.TOTPService = server.TOTPService
+	var userHandler = handler.NewUserHandler(requestBouncer, server.SecretsEncrypted)
+	userHandler.UserService = server.UserService
+	userHandler.TeamService = server.TeamService
+	userHandler.TeamMembershipService = server.TeamMembershipService
+	userHandler.CryptoService = server.CryptoService
+	userHandler.ResourceControlService = server.ResourceControlService
+	userHandler.SettingsService = server.SettingsService
+	userHandler.TOTPService = server.TOTPService
+	userHandler.RoleAssignmentService = server.RoleAssignmentService
+	var teamHandler = handler.NewTeamHandler(requestBouncer)
+	teamHandler.TeamService = server.TeamService
+	teamHandler.TeamMembershipService = server.TeamMembershipService
+	teamHandler.RoleAssignmentService = server.RoleAssignmentService
+	var teamMembershipHandler = handler.NewTeamMembershipHandler(requestBouncer)
+	teamMembershipHandler.TeamMembershipService = server.TeamMembershipService
+	var statusHandler = handler.NewStatusHandler(requestBouncer, server.Status)
+	var searchHandler = handler.NewSearchHandler(requestBouncer)
+	searchHandler.SearchIndex = searchIndex
+	searchHandler.EndpointGroupService = server.EndpointGroupService
+	searchHandler.StackService = server.StackService
+	searchHandler.RegistryService = server.RegistryService
+	searchHandler.ResourceControlService = server.ResourceControlService
+	searchHandler.SettingsService = server.SettingsService
+	var settingsHandler = handler.NewSettingsHandler(requestBouncer, server.SecretsEncrypted)
+	settingsHandler.SettingsService = server.SettingsService
+	settingsHandler.LDAPService = server.LDAPService
+	settingsHandler.FileService = server.FileService
+	settingsHandler.BackupScheduler = server.BackupScheduler
+	var templatesHandler = handler.NewTemplatesHandler(requestBouncer)
+	templatesHandler.SettingsService = server.SettingsService
+	var dockerHandler = handler.NewDockerHandler(requestBouncer)
+	dockerHandler.EndpointService = server.EndpointService
+	dockerHandler.EndpointGroupService = server.EndpointGroupService
+	dockerHandler.TeamMembershipService = server.TeamMembershipService
+	dockerHandler.ProxyManager = proxyManager
+	var azureHandler = handler.NewAzureHandler(requestBouncer)
+	azureHandler.EndpointService = server.EndpointService
+	azureHandler.EndpointGroupService = server.EndpointGroupService
+	azureHandler.TeamMembershipService = server.TeamMembershipService
+	azureHandler.ProxyManager = proxyManager
+	var kubernetesHandler = handler.NewKubernetesHandler(requestBouncer)
+	kubernetesHandler.EndpointService = server.EndpointService
+	kubernetesHandler.EndpointGroupService = server.EndpointGroupService
+	kubernetesHandler.TeamMembershipService = server.TeamMembershipService
+	kubernetesHandler.ProxyManager = proxyManager
+	var websocketHandler = handler.NewWebSocketHandler()
+	websocketHandler.EndpointService = server.EndpointService
+	websocketHandler.SignatureService = server.SignatureService
+	websocketHandler.TunnelService = tunnelService
+	var endpointHandler = handler.NewEndpointHandler(requestBouncer, server.EndpointManagement)
+	endpointHandler.EndpointService = server.EndpointService
+	endpointHandler.EndpointGroupService = server.EndpointGroupService
+	endpointHandler.FileService = server.FileService
+	endpointHandler.ProxyManager = proxyManager
+	endpointHandler.TunnelService = tunnelService
+	endpointHandler.Authorizer = authorizer
+	var tunnelHandler = handler.NewTunnelHandler(requestBouncer)
+	tunnelHandler.EndpointService = server.EndpointService
+	tunnelHandler.TunnelService = tunnelService
+	var endpointGroupHandler = handler.NewEndpointGroupHandler(requestBouncer)
+	endpointGroupHandler.EndpointGroupService = server.EndpointGroupService
+	endpointGroupHandler.EndpointService = server.EndpointService
+	var registryHandler = handler.NewRegistryHandler(requestBouncer)
+	registryHandler.RegistryService = server.RegistryService
+	var dockerHubHandler = handler.NewDockerHubHandler(requestBouncer)
+	dockerHubHandler.DockerHubService = server.DockerHubService
+	var roleHandler = handler.NewRoleHandler(requestBouncer)
+	roleHandler.RoleService = server.RoleService
+	roleHandler.RoleAssignmentService = server.RoleAssignmentService
+	var roleAssignmentHandler = handler.NewRoleAssignmentHandler(requestBouncer)
+	roleAssignmentHandler.RoleService = server.RoleService
+	roleAssignmentHandler.RoleAssignmentService = server.RoleAssignmentService
+	roleAssignmentHandler.UserService = server.UserService
+	roleAssignmentHandler.TeamService = server.TeamService
+	roleAssignmentHandler.EndpointGroupService = server.EndpointGroupService
+	var resourceHandler = handler.NewResourceHandler(requestBouncer)
+	resourceHandler.ResourceControlService = server.ResourceControlService
+	var uploadHandler = handler.NewUploadHandler(requestBouncer)
+	uploadHandler.FileService = server.FileService
+	var stackHandler = handler.NewStackHandler(requestBouncer)
+	stackHandler.FileService = server.FileService
+	stackHandler.StackService = server.StackService
+	stackHandler.EndpointService = server.EndpointService
+	stackHandler.ResourceControlService = server.ResourceControlService
+	stackHandler.StackManager = server.StackManager
+	stackHandler.GitService = server.GitService
+	stackHandler.RegistryService = server.RegistryService
+	stackHandler.DockerHubService = server.DockerHubService
+	stackHandler.Authorizer = authorizer
+	stackHandler.ClusterService = server.ClusterService
+	var extensionHandler = handler.NewExtensionHandler(requestBouncer)
+	extensionHandler.EndpointService = server.EndpointService
+	extensionHandler.ProxyManager = proxyManager
+	var storidgeHandler = extensions.NewStoridgeHandler(requestBouncer)
+	storidgeHandler.EndpointService = server.EndpointService
+	storidgeHandler.EndpointGroupService = server.EndpointGroupService
+	storidgeHandler.TeamMembershipService = server.TeamMembershipService
+	storidgeHandler.ProxyManager = proxyManager
+
+	server.Handler = &handler.Handler{
+		AggregateHandler:      aggregateHandler,
+		AuthHandler:           authHandler,
+		AuditHandler:          auditHandler,
+		BackupHandler:         backupHandler,
+		ClusterHandler:        clusterHandler,
+		ConfigHandler:         configHandler,
+		DebugHandler:          debugHandler,
+		UserHandler:           userHandler,
+		TeamHandler:           teamHandler,
+		TeamMembershipHandler: teamMembershipHandler,
+		EndpointHandler:       endpointHandler,
+		EndpointGroupHandler:  endpointGroupHandler,
+		RegistryHandler:       registryHandler,
+		DockerHubHandler:      dockerHubHandler,
+		ResourceHandler:       resourceHandler,
+		RoleHandler:           roleHandler,
+		RoleAssignmentHandler: roleAssignmentHandler,
+		SearchHandler:         searchHandler,
+		SettingsHandler:       settingsHandler,
+		StatusHandler:         statusHandler,
+		StackHandler:          stackHandler,
+		TemplatesHandler:      templatesHandler,
+		TunnelHandler:         tunnelHandler,
+		DockerHandler:         dockerHandler,
+		AzureHandler:          azureHandler,
+		KubernetesHandler:     kubernetesHandler,
+		WebSocketHandler:      websocketHandler,
+		FileHandler:           fileHandler,
+		UploadHandler:         uploadHandler,
+		ExtensionHandler:      extensionHandler,
+		StoridgeHandler:       storidgeHandler,
+	}
+
+	if server.SSL {
+		return server.startSSL()
+	}
+	return http.ListenAndServe(server.BindAddress, server.Handler)
+}
+
+// startSSL serves the handler over SSL with the certificate provided by the certificate manager.
+// The redirect listener is bound before the ACME certificate renewal starts, since it answers the challenges.
+func (server *Server) startSSL() error {
+	if server.SSLRedirectAddress != "" {
+		listener, err := net.Listen("tcp", server.SSLRedirectAddress)
+		if err != nil {
+			return err
+		}
+		go http.Serve(listener, server.CertificateManager.HTTPHandler(redirectToSSL(server.BindAddress)))
+	}
+	server.CertificateManager.StartRenewal()
+
+	httpServer := &http.Server{
+		Addr:      server.BindAddress,
+		Handler:   server.Handler,
+		TLSConfig: &tls.Config{GetCertificate: server.CertificateManager.GetCertificate},
+	}
+	return httpServer.ListenAndServeTLS("", "")
+}
+
+// redirectToSSL returns a handler redirecting the requests to the same URL on the SSL listener.
+func redirectToSSL(bindAddress string) http.Handler {
+	_, port, _ := net.SplitHostPort(bindAddress)
+	if port == "443" {
+		port = ""
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(r.Host); err == nil {
+			host = h
+		}
+		host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+
+		if port != "" {
+			host = net.JoinHostPort(host, port)
+		} else if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+	})
+}
@@ -0,0 +1,191 @@
+// Package secrets encodes the records containing secrets and manages the data key used to
+// encrypt them. It is shared by the BoltDB and the key/value datastores so that both store
+// the secrets in the same way.
+package secrets
+
+import (
+	"encoding/json"
+
+	"github.com/chainid-io/dashboard"
+)
+
+// MarshalUser encodes a user to binary format.
+// The TOTP secret is encrypted when cipher is not nil.
+func MarshalUser(user *chainid.User, cipher chainid.EncryptionService) ([]byte, error) {
+	encoded := encodedUser{User: *user, EncryptedSecrets: cipher != nil}
+
+	err := encryptSecrets(cipher, &encoded.TOTPSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&encoded)
+}
+
+// UnmarshalUser decodes a user from a binary data.
+func UnmarshalUser(data []byte, user *chainid.User, cipher chainid.EncryptionService) error {
+	var encoded encodedUser
+	err := json.Unmarshal(data, &encoded)
+	if err != nil {
+		return err
+	}
+
+	cipher, err = secretCipher(encoded.EncryptedSecrets, cipher)
+	if err != nil {
+		return err
+	}
+
+	*user = encoded.User
+	return decryptSecrets(cipher, &user.TOTPSecret)
+}
+
+// MarshalEndpoint encodes an endpoint to binary format. The Azure authentication key,
+// the Kubernetes token and the edge join token are encrypted when cipher is not nil.
+func MarshalEndpoint(endpoint *chainid.Endpoint, cipher chainid.EncryptionService) ([]byte, error) {
+	encoded := encodedEndpoint{Endpoint: *endpoint, EncryptedSecrets: cipher != nil}
+
+	err := encryptSecrets(cipher, &encoded.AzureCredentials.AuthenticationKey, &encoded.KubernetesCredentials.Token, &encoded.EdgeJoinToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&encoded)
+}
+
+// UnmarshalEndpoint decodes an endpoint from a binary data.
+func UnmarshalEndpoint(data []byte, endpoint *chainid.Endpoint, cipher chainid.EncryptionService) error {
+	var encoded encodedEndpoint
+	err := json.Unmarshal(data, &encoded)
+	if err != nil {
+		return err
+	}
+
+	cipher, err = secretCipher(encoded.EncryptedSecrets, cipher)
+	if err != nil {
+		return err
+	}
+
+	*endpoint = encoded.Endpoint
+	return decryptSecrets(cipher, &endpoint.AzureCredentials.AuthenticationKey, &endpoint.KubernetesCredentials.Token, &endpoint.EdgeJoinToken)
+}
+
+// MarshalStack encodes a stack to binary format.
+// The values of the environment variables are encrypted when cipher is not nil.
+func MarshalStack(stack *chainid.Stack, cipher chainid.EncryptionService) ([]byte, error) {
+	encoded := encodedStack{Stack: *stack, EncryptedSecrets: cipher != nil}
+	encoded.Env = copyPairs(stack.Env)
+
+	err := encryptSecrets(cipher, pairValues(encoded.Env)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&encoded)
+}
+
+// UnmarshalStack decodes a stack from a binary data.
+func UnmarshalStack(data []byte, stack *chainid.Stack, cipher chainid.EncryptionService) error {
+	var encoded encodedStack
+	err := json.Unmarshal(data, &encoded)
+	if err != nil {
+		return err
+	}
+
+	cipher, err = secretCipher(encoded.EncryptedSecrets, cipher)
+	if err != nil {
+		return err
+	}
+
+	*stack = encoded.Stack
+	return decryptSecrets(cipher, pairValues(stack.Env)...)
+}
+
+// MarshalRegistry encodes a registry to binary format.
+// The password is encrypted when cipher is not nil.
+func MarshalRegistry(registry *chainid.Registry, cipher chainid.EncryptionService) ([]byte, error) {
+	encoded := encodedRegistry{Registry: *registry, EncryptedSecrets: cipher != nil}
+
+	err := encryptSecrets(cipher, &encoded.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&encoded)
+}
+
+// UnmarshalRegistry decodes a registry from a binary data.
+func UnmarshalRegistry(data []byte, registry *chainid.Registry, cipher chainid.EncryptionService) error {
+	var encoded encodedRegistry
+	err := json.Unmarshal(data, &encoded)
+	if err != nil {
+		return err
+	}
+
+	cipher, err = secretCipher(encoded.EncryptedSecrets, cipher)
+	if err != nil {
+		return err
+	}
+
+	*registry = encoded.Registry
+	return decryptSecrets(cipher, &registry.Password)
+}
+
+// MarshalSettings encodes a settings object to binary format.
+// The LDAP password and the backup secrets are encrypted when cipher is not nil.
+func MarshalSettings(settings *chainid.Settings, cipher chainid.EncryptionService) ([]byte, error) {
+	encoded := encodedSettings{Settings: *settings, EncryptedSecrets: cipher != nil}
+
+	err := encryptSecrets(cipher, &encoded.LDAPSettings.Password, &encoded.BackupSettings.Password, &encoded.BackupSettings.S3.SecretAccessKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&encoded)
+}
+
+// UnmarshalSettings decodes a settings object from a binary data.
+func UnmarshalSettings(data []byte, settings *chainid.Settings, cipher chainid.EncryptionService) error {
+	var encoded encodedSettings
+	err := json.Unmarshal(data, &encoded)
+	if err != nil {
+		return err
+	}
+
+	cipher, err = secretCipher(encoded.EncryptedSecrets, cipher)
+	if err != nil {
+		return err
+	}
+
+	*settings = encoded.Settings
+	return decryptSecrets(cipher, &settings.LDAPSettings.Password, &settings.BackupSettings.Password, &settings.BackupSettings.S3.SecretAccessKey)
+}
+
+// MarshalDockerHub encodes a Dockerhub object to binary format.
+// The password is encrypted when cipher is not nil.
+func MarshalDockerHub(settings *chainid.DockerHub, cipher chainid.EncryptionService) ([]byte, error) {
+	encoded := encodedDockerHub{DockerHub: *settings, EncryptedSecrets: cipher != nil}
+
+	err := encryptSecrets(cipher, &encoded.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&encoded)
+}
+
+// UnmarshalDockerHub decodes a Dockerhub object from a binary data.
+func UnmarshalDockerHub(data []byte, settings *chainid.DockerHub, cipher chainid.EncryptionService) error {
+	var encoded encodedDockerHub
+	err := json.Unmarshal(data, &encoded)
+	if err != nil {
+		return err
+	}
+
+	cipher, err = secretCipher(encoded.EncryptedSecrets, cipher)
+	if err != nil {
+		return err
+	}
+
+	*settings = encoded.DockerHub
+	return decryptSecrets(cipher, &settings.Password)
+}
@@ -0,0 +1,81 @@
+package secrets
+
+import (
+	"crypto/sha256"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/crypto"
+)
+
+// NewMasterKeyService returns the service used to encrypt the data key. The master key
+// can be of any length, the encryption key is derived from it.
+func NewMasterKeyService(masterKey []byte) (chainid.EncryptionService, error) {
+	key := sha256.Sum256(masterKey)
+
+	service := &crypto.AESService{}
+	err := service.SetKey(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return service, nil
+}
+
+// GenerateDataKey generates a new data key and returns the service used to encrypt the
+// secrets with it, along with the data key encrypted with the master key.
+func GenerateDataKey(masterKeyService chainid.EncryptionService) (chainid.EncryptionService, string, error) {
+	dataKeyService := &crypto.AESService{}
+	dataKey, err := dataKeyService.GenerateKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = dataKeyService.SetKey(dataKey)
+	if err != nil {
+		return nil, "", err
+	}
+
+	wrappedDataKey, err := masterKeyService.Encrypt(string(dataKey))
+	if err != nil {
+		return nil, "", err
+	}
+	return dataKeyService, wrappedDataKey, nil
+}
+
+// UnwrapDataKey decrypts the data key with the master key and returns the service used to
+// encrypt the secrets with it. It returns chainid.ErrInvalidMasterKey if the data key was
+// encrypted with another master key.
+func UnwrapDataKey(masterKeyService chainid.EncryptionService, wrappedDataKey string) (chainid.EncryptionService, error) {
+	dataKey, err := masterKeyService.Decrypt(wrappedDataKey)
+	if err != nil {
+		return nil, chainid.ErrInvalidMasterKey
+	}
+
+	dataKeyService := &crypto.AESService{}
+	err = dataKeyService.SetKey([]byte(dataKey))
+	if err != nil {
+		return nil, err
+	}
+	return dataKeyService, nil
+}
+
+// RewrapDataKey decrypts the data key with the previous master key and encrypts it again
+// with the new master key. It returns the service used to encrypt the secrets with the
+// data key, along with the data key encrypted with the new master key.
+func RewrapDataKey(masterKeyService, previousMasterKeyService chainid.EncryptionService, wrappedDataKey string) (chainid.EncryptionService, string, error) {
+	dataKey, err := previousMasterKeyService.Decrypt(wrappedDataKey)
+	if err != nil {
+		return nil, "", chainid.ErrInvalidMasterKey
+	}
+
+	dataKeyService := &crypto.AESService{}
+	err = dataKeyService.SetKey([]byte(dataKey))
+	if err != nil {
+		return nil, "", err
+	}
+
+	wrappedDataKey, err = masterKeyService.Encrypt(dataKey)
+	if err != nil {
+		return nil, "", err
+	}
+	return dataKeyService, wrappedDataKey, nil
+}
@@ -0,0 +1,121 @@
+package secrets
+
+import (
+	"encoding/json"
+
+	"github.com/chainid-io/dashboard"
+)
+
+// The records containing secrets are flagged with EncryptedSecrets when their secrets are
+// encrypted with the data key. The flag is only set by the encoding functions of this package,
+// so that a secret stored in plain text is never mistaken for an encrypted one whatever its value.
+type (
+	encodedUser struct {
+		chainid.User
+		EncryptedSecrets bool `json:"EncryptedSecrets,omitempty"`
+	}
+
+	encodedEndpoint struct {
+		chainid.Endpoint
+		EncryptedSecrets bool `json:"EncryptedSecrets,omitempty"`
+	}
+
+	encodedStack struct {
+		chainid.Stack
+		EncryptedSecrets bool `json:"EncryptedSecrets,omitempty"`
+	}
+
+	encodedRegistry struct {
+		chainid.Registry
+		EncryptedSecrets bool `json:"EncryptedSecrets,omitempty"`
+	}
+
+	encodedSettings struct {
+		chainid.Settings
+		EncryptedSecrets bool `json:"EncryptedSecrets,omitempty"`
+	}
+
+	encodedDockerHub struct {
+		chainid.DockerHub
+		EncryptedSecrets bool `json:"EncryptedSecrets,omitempty"`
+	}
+)
+
+// Encrypted reports whether the secrets of an encoded record are encrypted with the data key.
+func Encrypted(data []byte) (bool, error) {
+	var record struct {
+		EncryptedSecrets bool
+	}
+	err := json.Unmarshal(data, &record)
+	return record.EncryptedSecrets, err
+}
+
+// secretCipher returns the service used to decrypt the secrets of a record. It returns
+// chainid.ErrMasterKeyRequired if the secrets are encrypted and no data key is loaded.
+func secretCipher(encrypted bool, cipher chainid.EncryptionService) (chainid.EncryptionService, error) {
+	if !encrypted {
+		return nil, nil
+	}
+	if cipher == nil {
+		return nil, chainid.ErrMasterKeyRequired
+	}
+	return cipher, nil
+}
+
+// encryptSecrets encrypts the secrets in place. Nothing is done when cipher is nil.
+func encryptSecrets(cipher chainid.EncryptionService, secrets ...*string) error {
+	if cipher == nil {
+		return nil
+	}
+
+	for _, secret := range secrets {
+		if *secret == "" {
+			continue
+		}
+
+		encrypted, err := cipher.Encrypt(*secret)
+		if err != nil {
+			return err
+		}
+		*secret = encrypted
+	}
+	return nil
+}
+
+// decryptSecrets decrypts the secrets in place. Nothing is done when cipher is nil.
+func decryptSecrets(cipher chainid.EncryptionService, secrets ...*string) error {
+	if cipher == nil {
+		return nil
+	}
+
+	for _, secret := range secrets {
+		if *secret == "" {
+			continue
+		}
+
+		decrypted, err := cipher.Decrypt(*secret)
+		if err != nil {
+			return err
+		}
+		*secret = decrypted
+	}
+	return nil
+}
+
+func pairValues(pairs []chainid.Pair) []*string {
+	values := make([]*string, len(pairs))
+	for i := range pairs {
+		values[i] = &pairs[i].Value
+	}
+	return values
+}
+
+func copyPairs(pairs []chainid.Pair) []chainid.Pair {
+	if pairs == nil {
+		return nil
+	}
+
+	copied := make([]chainid.Pair, len(pairs))
+	copy(copied, pairs)
+	return copied
+}
@@ -0,0 +1,120 @@
+package kubernetes
+
+import (
+	"encoding/base64"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/ghodss/yaml"
+)
+
+type (
+	// Kubeconfig represents the connection settings of the current context of a kubeconfig file.
+	// The certificates are PEM encoded.
+	Kubeconfig struct {
+		Server                string
+		InsecureSkipTLSVerify bool
+		CertificateAuthority  []byte
+		ClientCertificate     []byte
+		ClientKey             []byte
+		Token                 string
+	}
+
+	kubeconfigFile struct {
+		CurrentContext string `json:"current-context"`
+		Clusters       []struct {
+			Name    string `json:"name"`
+			Cluster struct {
+				Server                   string `json:"server"`
+				InsecureSkipTLSVerify    bool   `json:"insecure-skip-tls-verify"`
+				CertificateAuthorityData string `json:"certificate-authority-data"`
+			} `json:"cluster"`
+		} `json:"clusters"`
+		Contexts []struct {
+			Name    string `json:"name"`
+			Context struct {
+				Cluster string `json:"cluster"`
+				User    string `json:"user"`
+			} `json:"context"`
+		} `json:"contexts"`
+		Users []struct {
+			Name string `json:"name"`
+			User struct {
+				ClientCertificateData string `json:"client-certificate-data"`
+				ClientKeyData         string `json:"client-key-data"`
+				Token                 string `json:"token"`
+			} `json:"user"`
+		} `json:"users"`
+	}
+)
+
+// ParseKubeconfig returns the connection settings of the current context of a kubeconfig file.
+// Only the credentials embedded in the file are supported, the files referenced by a kubeconfig
+// cannot be read by the server.
+func ParseKubeconfig(data []byte) (*Kubeconfig, error) {
+	var file kubeconfigFile
+	err := yaml.Unmarshal(data, &file)
+	if err != nil {
+		return nil, chainid.ErrInvalidKubeconfig
+	}
+
+	contextName := file.CurrentContext
+	if contextName == "" && len(file.Contexts) == 1 {
+		contextName = file.Contexts[0].Name
+	}
+
+	var clusterName, userName string
+	found := false
+	for _, context := range file.Contexts {
+		if context.Name == contextName {
+			clusterName = context.Context.Cluster
+			userName = context.Context.User
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, chainid.ErrInvalidKubeconfig
+	}
+
+	config := &Kubeconfig{}
+
+	found = false
+	for _, cluster := range file.Clusters {
+		if cluster.Name != clusterName {
+			continue
+		}
+		config.Server = cluster.Cluster.Server
+		config.InsecureSkipTLSVerify = cluster.Cluster.InsecureSkipTLSVerify
+		config.CertificateAuthority, err = base64.StdEncoding.DecodeString(cluster.Cluster.CertificateAuthorityData)
+		if err != nil {
+			return nil, chainid.ErrInvalidKubeconfig
+		}
+		found = true
+		break
+	}
+	if !found || config.Server == "" {
+		return nil, chainid.ErrInvalidKubeconfig
+	}
+
+	for _, user := range file.Users {
+		if user.Name != userName {
+			continue
+		}
+		config.Token = user.User.Token
+		config.ClientCertificate, err = base64.StdEncoding.DecodeString(user.User.ClientCertificateData)
+		if err != nil {
+			return nil, chainid.ErrInvalidKubeconfig
+		}
+		config.ClientKey, err = base64.StdEncoding.DecodeString(user.User.ClientKeyData)
+		if err != nil {
+			return nil, chainid.ErrInvalidKubeconfig
+		}
+		break
+	}
+
+	if config.Token == "" && (len(config.ClientCertificate) == 0 || len(config.ClientKey) == 0) {
+		return nil, chainid.ErrInvalidKubeconfig
+	}
+
+	return config, nil
+}
@@ -0,0 +1,74 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/chainid-io/dashboard"
+)
+
+const testKubeconfig = `
+apiVersion: v1
+kind: Config
+current-context: production
+clusters:
+  - name: staging
+    cluster:
+      server: https://staging.example.com:6443
+  - name: production
+    cluster:
+      server: https://production.example.com:6443
+      certificate-authority-data: Y2EtY2VydGlmaWNhdGU=
+contexts:
+  - name: staging
+    context:
+      cluster: staging
+      user: developer
+  - name: production
+    context:
+      cluster: production
+      user: admin
+users:
+  - name: developer
+    user:
+      token: developer-token
+  - name: admin
+    user:
+      client-certificate-data: Y2xpZW50LWNlcnRpZmljYXRl
+      client-key-data: Y2xpZW50LWtleQ==
+`
+
+func TestParseKubeconfig(t *testing.T) {
+	config, err := ParseKubeconfig([]byte(testKubeconfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Server != "https://production.example.com:6443" {
+		t.Errorf("expected the server of the current context, got %q", config.Server)
+	}
+	if string(config.CertificateAuthority) != "ca-certificate" {
+		t.Errorf("unexpected certificate authority %q", config.CertificateAuthority)
+	}
+	if string(config.ClientCertificate) != "client-certificate" || string(config.ClientKey) != "client-key" {
+		t.Errorf("unexpected client certificate %q and key %q", config.ClientCertificate, config.ClientKey)
+	}
+	if config.Token != "" {
+		t.Errorf("expected no token, got %q", config.Token)
+	}
+}
+
+func TestParseInvalidKubeconfig(t *testing.T) {
+	tests := []string{
+		"not: [valid",
+		"current-context: missing\ncontexts: []\n",
+		// The credentials of the user are stored in a file.
+		"current-context: c\ncontexts:\n  - name: c\n    context: {cluster: c, user: u}\nclusters:\n  - name: c\n    cluster: {server: https://example.com}\nusers:\n  - name: u\n    user: {client-certificate: /home/u/cert.pem}\n",
+	}
+
+	for _, data := range tests {
+		_, err := ParseKubeconfig([]byte(data))
+		if err != chainid.ErrInvalidKubeconfig {
+			t.Errorf("expected %q for %q, got %v", chainid.ErrInvalidKubeconfig, data, err)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package kv
+
+import (
+	"encoding/json"
+
+	"github.com/chainid-io/dashboard"
+)
+
+// auditLogMaxEntries is the maximum number of entries kept in the audit log.
+// The oldest entries are removed when the limit is reached.
+const auditLogMaxEntries = 10000
+
+// AuditLogService represents a service for managing the audit log.
+type AuditLogService struct {
+	entries *collection
+}
+
+// CreateAuditLogEntry appends a new entry to the audit log and removes the entry
+// exceeding the capacity of the log.
+func (service *AuditLogService) CreateAuditLogEntry(entry *chainid.AuditLogEntry) error {
+	id, err := service.entries.nextID()
+	if err != nil {
+		return err
+	}
+	entry.ID = chainid.AuditLogEntryID(id)
+
+	err = service.entries.put(formatID(id), entry)
+	if err != nil {
+		return err
+	}
+
+	oldestID := id - auditLogMaxEntries
+	if oldestID <= 0 {
+		return nil
+	}
+	return service.entries.delete(formatID(oldestID))
+}
+
+// AuditLogEntries returns the entries matching the filter, most recent first.
+func (service *AuditLogService) AuditLogEntries(filter *chainid.AuditLogFilter) ([]chainid.AuditLogEntry, error) {
+	var allEntries = make([]chainid.AuditLogEntry, 0)
+	err := service.entries.forEach(func(data []byte) error {
+		var entry chainid.AuditLogEntry
+		err := json.Unmarshal(data, &entry)
+		if err != nil {
+			return err
+		}
+		allEntries = append(allEntries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var entries = make([]chainid.AuditLogEntry, 0)
+	for i := len(allEntries) - 1; i >= 0; i-- {
+		if !auditLogEntryMatches(&allEntries[i], filter) {
+			continue
+		}
+
+		entries = append(entries, allEntries[i])
+		if filter.Limit > 0 && len(entries) >= filter.Limit {
+			break
+		}
+	}
+	return entries, nil
+}
+
+func auditLogEntryMatches(entry *chainid.AuditLogEntry, filter *chainid.AuditLogFilter) bool {
+	if filter.UserID != 0 && entry.UserID != filter.UserID {
+		return false
+	}
+	if filter.EndpointID != 0 && entry.EndpointID != filter.EndpointID {
+		return false
+	}
+	if filter.Method != "" && entry.Method != filter.Method {
+		return false
+	}
+	if filter.ResourceID != "" && entry.ResourceID != filter.ResourceID {
+		return false
+	}
+	if filter.From != 0 && entry.Timestamp < filter.From {
+		return false
+	}
+	if filter.To != 0 && entry.Timestamp > filter.To {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,39 @@
+package kv
+
+import (
+	"encoding/json"
+
+	"github.com/chainid-io/dashboard"
+)
+
+// BackupStatusService represents a service to manage the status of the scheduled backups.
+type BackupStatusService struct {
+	backend Backend
+}
+
+// BackupStatus retrieves the status of the scheduled backups.
+func (service *BackupStatusService) BackupStatus() (*chainid.BackupStatus, error) {
+	data, err := service.backend.Get(backupStatusKey)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, chainid.ErrBackupStatusNotFound
+	}
+
+	var status chainid.BackupStatus
+	err = json.Unmarshal(data, &status)
+	if err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// StoreBackupStatus persists the status of the scheduled backups.
+func (service *BackupStatusService) StoreBackupStatus(status *chainid.BackupStatus) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	return service.backend.Put(backupStatusKey, data)
+}
@@ -0,0 +1,189 @@
+package kv
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// sequenceKey is the key storing the last identifier assigned in a collection.
+const sequenceKey = "sequence"
+
+// collection groups the objects of the same type under a common key prefix,
+// in the same way a bucket does in BoltDB.
+type collection struct {
+	backend Backend
+	name    string
+	// secrets encodes the objects containing secrets, the other objects are encoded in JSON.
+	secrets *secretCodec
+}
+
+// formatID returns the key of an integer identifier. Identifiers are zero-padded
+// so that the objects are listed in the order of their identifiers.
+func formatID(ID int) string {
+	return fmt.Sprintf("%020d", ID)
+}
+
+func (c *collection) key(ID string) string {
+	return c.name + "/objects/" + ID
+}
+
+func (c *collection) encode(object interface{}) ([]byte, error) {
+	if c.secrets != nil {
+		return c.secrets.encode(object)
+	}
+	return json.Marshal(object)
+}
+
+func (c *collection) decode(data []byte, object interface{}) error {
+	if c.secrets != nil {
+		return c.secrets.decode(data, object)
+	}
+	return json.Unmarshal(data, object)
+}
+
+// get decodes the object stored with the specified identifier. It returns notFound if
+// the object does not exist.
+func (c *collection) get(ID string, object interface{}, notFound error) error {
+	data, err := c.backend.Get(c.key(ID))
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return notFound
+	}
+	return c.decode(data, object)
+}
+
+// put encodes and stores an object with the specified identifier.
+func (c *collection) put(ID string, object interface{}) error {
+	data, err := c.encode(object)
+	if err != nil {
+		return err
+	}
+	return c.backend.Put(c.key(ID), data)
+}
+
+// update replaces the object stored with the specified identifier by the object returned by fn
+// with a compare-and-swap operation. fn is called with the encoded value of the object and is
+// called again when the object is changed in the meantime. It returns notFound if the object
+// does not exist.
+func (c *collection) update(ID string, notFound error, fn func(data []byte) (interface{}, error)) error {
+	for {
+		current, err := c.backend.Get(c.key(ID))
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			return notFound
+		}
+
+		object, err := fn(current)
+		if err != nil {
+			return err
+		}
+
+		data, err := c.encode(object)
+		if err != nil {
+			return err
+		}
+
+		swapped, err := c.backend.CompareAndSwap(c.key(ID), current, data)
+		if err != nil || swapped {
+			return err
+		}
+	}
+}
+
+func (c *collection) delete(ID string) error {
+	return c.backend.Delete(c.key(ID))
+}
+
+// encryptSecrets encodes again with the data key the objects whose secrets are stored in plain text.
+func (c *collection) encryptSecrets() error {
+	entries, err := c.backend.List(c.name + "/objects/")
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		err = encryptValue(c.backend, entry.Key, c.secrets)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// forEach calls fn with the encoded value of every object of the collection,
+// in the order of their identifiers.
+func (c *collection) forEach(fn func(data []byte) error) error {
+	entries, err := c.backend.List(c.name + "/objects/")
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		err = fn(entry.Value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nextID returns a new identifier. The sequence is updated with a compare-and-swap
+// operation so that concurrent instances never assign the same identifier.
+func (c *collection) nextID() (int, error) {
+	key := c.name + "/" + sequenceKey
+	for {
+		current, err := c.backend.Get(key)
+		if err != nil {
+			return 0, err
+		}
+
+		ID := 1
+		if current != nil {
+			last, err := strconv.Atoi(strings.TrimSpace(string(current)))
+			if err != nil {
+				return 0, err
+			}
+			ID = last + 1
+		}
+
+		swapped, err := c.backend.CompareAndSwap(key, current, []byte(strconv.Itoa(ID)))
+		if err != nil {
+			return 0, err
+		}
+		if swapped {
+			return ID, nil
+		}
+	}
+}
+
+// setSequence sets the last assigned identifier if it is lower than ID.
+func (c *collection) setSequence(ID int) error {
+	key := c.name + "/" + sequenceKey
+	for {
+		current, err := c.backend.Get(key)
+		if err != nil {
+			return err
+		}
+
+		if current != nil {
+			last, err := strconv.Atoi(strings.TrimSpace(string(current)))
+			if err != nil {
+				return err
+			}
+			if last >= ID {
+				return nil
+			}
+		}
+
+		swapped, err := c.backend.CompareAndSwap(key, current, []byte(strconv.Itoa(ID)))
+		if err != nil || swapped {
+			return err
+		}
+	}
+}
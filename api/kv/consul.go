@@ -0,0 +1,177 @@
+package kv
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// consulEntry is an entry returned by the Consul KV API. Values are base64 encoded.
+type consulEntry struct {
+	Key         string `json:"Key"`
+	Value       []byte `json:"Value"`
+	ModifyIndex uint64 `json:"ModifyIndex"`
+}
+
+// ConsulBackend is a Backend storing the data in the KV store of a Consul cluster,
+// which allows several instances to share the same data.
+type ConsulBackend struct {
+	address string
+	prefix  string
+	token   string
+	client  *http.Client
+}
+
+// NewConsulBackend returns a Backend using the Consul agent available at the specified address
+// (e.g. http://127.0.0.1:8500). Every key is stored under prefix. The ACL token is optional.
+func NewConsulBackend(address, prefix, token string) (*ConsulBackend, error) {
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+
+	_, err := url.Parse(address)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix = strings.Trim(prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	return &ConsulBackend{
+		address: strings.TrimSuffix(address, "/"),
+		prefix:  prefix,
+		token:   token,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Get returns the value associated to the key, or nil if the key does not exist.
+func (backend *ConsulBackend) Get(key string) ([]byte, error) {
+	entry, err := backend.entry(key)
+	if err != nil || entry == nil {
+		return nil, err
+	}
+	return entry.Value, nil
+}
+
+// List returns the entries whose key starts with the prefix, sorted by key.
+func (backend *ConsulBackend) List(prefix string) ([]Entry, error) {
+	var consulEntries []consulEntry
+	found, err := backend.do(http.MethodGet, prefix, url.Values{"recurse": {"true"}}, nil, &consulEntries)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0)
+	if !found {
+		return entries, nil
+	}
+
+	for _, entry := range consulEntries {
+		entries = append(entries, Entry{Key: strings.TrimPrefix(entry.Key, backend.prefix), Value: entry.Value})
+	}
+	return entries, nil
+}
+
+// Put associates the value to the key.
+func (backend *ConsulBackend) Put(key string, value []byte) error {
+	_, err := backend.do(http.MethodPut, key, nil, value, nil)
+	return err
+}
+
+// Delete removes the key.
+func (backend *ConsulBackend) Delete(key string) error {
+	_, err := backend.do(http.MethodDelete, key, nil, nil, nil)
+	return err
+}
+
+// CompareAndSwap associates the value to the key only if the current value is equal to previous.
+// It relies on the check-and-set operation of Consul, using the modify index of the current value.
+func (backend *ConsulBackend) CompareAndSwap(key string, previous, value []byte) (bool, error) {
+	var index uint64
+	if previous != nil {
+		entry, err := backend.entry(key)
+		if err != nil {
+			return false, err
+		}
+		if entry == nil || !bytes.Equal(entry.Value, previous) {
+			return false, nil
+		}
+		index = entry.ModifyIndex
+	}
+
+	var swapped bool
+	_, err := backend.do(http.MethodPut, key, url.Values{"cas": {strconv.FormatUint(index, 10)}}, value, &swapped)
+	if err != nil {
+		return false, err
+	}
+	return swapped, nil
+}
+
+// Close releases the idle connections to the Consul agent.
+func (backend *ConsulBackend) Close() error {
+	backend.client.CloseIdleConnections()
+	return nil
+}
+
+func (backend *ConsulBackend) entry(key string) (*consulEntry, error) {
+	var entries []consulEntry
+	found, err := backend.do(http.MethodGet, key, nil, nil, &entries)
+	if err != nil || !found || len(entries) == 0 {
+		return nil, err
+	}
+	return &entries[0], nil
+}
+
+// do sends a request to the KV API and decodes the JSON response in result.
+// It returns false if the key does not exist.
+func (backend *ConsulBackend) do(method, key string, query url.Values, body []byte, result interface{}) (bool, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	requestURL := backend.address + "/v1/kv/" + (&url.URL{Path: backend.prefix + key}).EscapedPath()
+	if query != nil {
+		requestURL += "?" + query.Encode()
+	}
+
+	request, err := http.NewRequest(method, requestURL, reader)
+	if err != nil {
+		return false, err
+	}
+	if backend.token != "" {
+		request.Header.Set("X-Consul-Token", backend.token)
+	}
+
+	response, err := backend.client.Do(request)
+	if err != nil {
+		return false, err
+	}
+	defer response.Body.Close()
+
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return false, err
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		return false, nil
+	} else if response.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("Consul request %s %s failed with status %d: %s", method, request.URL.Path, response.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	if result == nil {
+		return true, nil
+	}
+	return true, json.Unmarshal(data, result)
+}
@@ -0,0 +1,152 @@
+package kv
+
+import (
+	"io"
+	"sync"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/bolt"
+)
+
+// Store defines the implementation of chainid.DataStore using a key/value
+// storage system. Unlike BoltDB, the storage can be shared by several instances.
+type Store struct {
+	// Services
+	UserService            *UserService
+	TeamService            *TeamService
+	TeamMembershipService  *TeamMembershipService
+	EndpointService        *EndpointService
+	EndpointGroupService   *EndpointGroupService
+	ResourceControlService *ResourceControlService
+	VersionService         *VersionService
+	SettingsService        *SettingsService
+	RegistryService        *RegistryService
+	DockerHubService       *DockerHubService
+	StackService           *StackService
+	RoleService            *RoleService
+	RoleAssignmentService  *RoleAssignmentService
+	AuditLogService        *AuditLogService
+	BackupStatusService    *BackupStatusService
+
+	backend Backend
+
+	// mu protects secretCipher, the service encrypting the secrets with the data key.
+	// It is nil when no master key is specified.
+	mu           sync.RWMutex
+	secretCipher chainid.EncryptionService
+}
+
+const (
+	versionKey                = "version"
+	settingsKey               = "settings"
+	dockerhubKey              = "dockerhub"
+	backupStatusKey           = "backup_status"
+	userCollectionName        = "users"
+	teamCollectionName        = "teams"
+	teamMembershipCollection  = "team_membership"
+	endpointCollectionName    = "endpoints"
+	endpointGroupCollection   = "endpoint_groups"
+	resourceControlCollection = "resource_control"
+	registryCollectionName    = "registries"
+	stackCollectionName       = "stacks"
+	roleCollectionName        = "roles"
+	roleAssignmentCollection  = "role_assignments"
+	auditLogCollectionName    = "audit_log"
+)
+
+// NewStore initializes a new Store and the associated services.
+func NewStore(backend Backend) *Store {
+	store := &Store{backend: backend}
+	store.UserService = &UserService{users: &collection{backend, userCollectionName, newUserCodec(store)}}
+	store.TeamService = &TeamService{teams: &collection{backend, teamCollectionName, nil}}
+	store.TeamMembershipService = &TeamMembershipService{memberships: &collection{backend, teamMembershipCollection, nil}}
+	store.EndpointService = &EndpointService{endpoints: &collection{backend, endpointCollectionName, newEndpointCodec(store)}}
+	store.EndpointGroupService = &EndpointGroupService{groups: &collection{backend, endpointGroupCollection, nil}}
+	store.ResourceControlService = &ResourceControlService{resourceControls: &collection{backend, resourceControlCollection, nil}}
+	store.VersionService = &VersionService{backend: backend}
+	store.SettingsService = &SettingsService{backend: backend, codec: newSettingsCodec(store)}
+	store.RegistryService = &RegistryService{registries: &collection{backend, registryCollectionName, newRegistryCodec(store)}}
+	store.DockerHubService = &DockerHubService{backend: backend, codec: newDockerHubCodec(store)}
+	store.StackService = &StackService{stacks: &collection{backend, stackCollectionName, newStackCodec(store)}}
+	store.RoleService = &RoleService{roles: &collection{backend, roleCollectionName, nil}}
+	store.RoleAssignmentService = &RoleAssignmentService{assignments: &collection{backend, roleAssignmentCollection, nil}}
+	store.AuditLogService = &AuditLogService{entries: &collection{backend, auditLogCollectionName, nil}}
+	store.BackupStatusService = &BackupStatusService{backend: backend}
+	return store
+}
+
+// Open checks that the storage is reachable.
+func (store *Store) Open() error {
+	_, err := store.backend.Get(versionKey)
+	return err
+}
+
+// Init creates the default data set.
+func (store *Store) Init() error {
+	groups, err := store.EndpointGroupService.EndpointGroups()
+	if err != nil {
+		return err
+	}
+
+	if len(groups) == 0 {
+		unassignedGroup := &chainid.EndpointGroup{
+			Name:            "Unassigned",
+			Description:     "Unassigned endpoints",
+			Labels:          []chainid.Pair{},
+			AuthorizedUsers: []chainid.UserID{},
+			AuthorizedTeams: []chainid.TeamID{},
+		}
+
+		err = store.EndpointGroupService.CreateEndpointGroup(unassignedGroup)
+		if err != nil {
+			return err
+		}
+	}
+
+	roles, err := store.RoleService.Roles()
+	if err != nil {
+		return err
+	}
+
+	if len(roles) == 0 {
+		for _, role := range bolt.BuiltInRoles() {
+			err = store.RoleService.CreateRole(&role)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Close closes the connection to the storage.
+func (store *Store) Close() error {
+	return store.backend.Close()
+}
+
+// MigrateData stores the current DBVersion in a new storage. The data model of a key/value
+// storage cannot be migrated, the data must be migrated in a BoltDB database and imported again.
+func (store *Store) MigrateData() error {
+	version, err := store.VersionService.DBVersion()
+	if err == chainid.ErrDBVersionNotFound {
+		return store.VersionService.StoreDBVersion(chainid.DBVersion)
+	} else if err != nil {
+		return err
+	}
+
+	if version != chainid.DBVersion {
+		return chainid.ErrDataStoreMigrationNotSupported
+	}
+	return nil
+}
+
+// BackupDatabase is not supported, the storage must be backed up with its own tools.
+func (store *Store) BackupDatabase(w io.Writer) error {
+	return chainid.ErrDataStoreOperationNotSupported
+}
+
+// RestoreDatabase is not supported, the storage must be restored with its own tools.
+func (store *Store) RestoreDatabase(path string) error {
+	return chainid.ErrDataStoreOperationNotSupported
+}
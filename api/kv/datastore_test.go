@@ -0,0 +1,362 @@
+package kv
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/bolt"
+	"github.com/chainid-io/dashboard/datastoretest"
+)
+
+// fakeConsulServer implements the subset of the Consul KV API used by ConsulBackend.
+type fakeConsulServer struct {
+	mu      sync.Mutex
+	index   uint64
+	entries map[string]consulEntry
+}
+
+func newFakeConsulServer() *httptest.Server {
+	server := &fakeConsulServer{entries: make(map[string]consulEntry)}
+	return httptest.NewServer(server)
+}
+
+func (server *fakeConsulServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	key := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+
+	switch r.Method {
+	case http.MethodGet:
+		result := make([]consulEntry, 0)
+		for entryKey, entry := range server.entries {
+			if entryKey == key || (r.URL.Query().Get("recurse") != "" && strings.HasPrefix(entryKey, key)) {
+				result = append(result, entry)
+			}
+		}
+		if len(result) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+		json.NewEncoder(w).Encode(result)
+
+	case http.MethodPut:
+		value, _ := ioutil.ReadAll(r.Body)
+		if cas := r.URL.Query().Get("cas"); cas != "" {
+			index, _ := strconv.ParseUint(cas, 10, 64)
+			if server.entries[key].ModifyIndex != index {
+				w.Write([]byte("false"))
+				return
+			}
+		}
+		server.index++
+		server.entries[key] = consulEntry{Key: key, Value: value, ModifyIndex: server.index}
+		w.Write([]byte("true"))
+
+	case http.MethodDelete:
+		delete(server.entries, key)
+		w.Write([]byte("true"))
+	}
+}
+
+func newTestServices(store *Store, cleanup func()) (*datastoretest.Services, func()) {
+	services := &datastoretest.Services{
+		DataStore:              store,
+		UserService:            store.UserService,
+		TeamService:            store.TeamService,
+		TeamMembershipService:  store.TeamMembershipService,
+		EndpointService:        store.EndpointService,
+		EndpointGroupService:   store.EndpointGroupService,
+		ResourceControlService: store.ResourceControlService,
+		VersionService:         store.VersionService,
+		SettingsService:        store.SettingsService,
+		RegistryService:        store.RegistryService,
+		DockerHubService:       store.DockerHubService,
+		StackService:           store.StackService,
+		RoleService:            store.RoleService,
+		RoleAssignmentService:  store.RoleAssignmentService,
+		AuditLogService:        store.AuditLogService,
+		BackupStatusService:    store.BackupStatusService,
+	}
+	return services, cleanup
+}
+
+func TestMemoryDataStoreConformance(t *testing.T) {
+	datastoretest.Run(t, func(t *testing.T) (*datastoretest.Services, func()) {
+		store := NewStore(NewMemoryBackend())
+		return newTestServices(store, func() { store.Close() })
+	})
+}
+
+func TestConsulDataStoreConformance(t *testing.T) {
+	datastoretest.Run(t, func(t *testing.T) (*datastoretest.Services, func()) {
+		server := newFakeConsulServer()
+		backend, err := NewConsulBackend(server.URL, "chainid", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		store := NewStore(backend)
+		err = store.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return newTestServices(store, func() {
+			store.Close()
+			server.Close()
+		})
+	})
+}
+
+func rawValues(t *testing.T, backend Backend) []byte {
+	entries, err := backend.List("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var data []byte
+	for _, entry := range entries {
+		data = append(data, entry.Value...)
+	}
+	return data
+}
+
+func TestSecretsEncryption(t *testing.T) {
+	backend := NewMemoryBackend()
+	store := NewStore(backend)
+	err := store.InitEncryption(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = store.RegistryService.CreateRegistry(&chainid.Registry{Name: "private", Password: "registry-password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.DockerHubService.StoreDockerHub(&chainid.DockerHub{Username: "user", Password: "dockerhub-password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.SettingsService.StoreSettings(&chainid.Settings{LDAPSettings: chainid.LDAPSettings{Password: "ldap-password"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.UserService.CreateUser(&chainid.User{Username: "user", TOTPSecret: "totp-secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.StackService.CreateStack(&chainid.Stack{ID: "stack", Env: []chainid.Pair{{Name: "TOKEN", Value: "stack-token"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Existing secrets are encrypted when a master key is specified for the first time.
+	store = NewStore(backend)
+	err = store.InitEncryption([]byte("first-key"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = store.EndpointService.CreateEndpoint(&chainid.Endpoint{Name: "edge", EdgeJoinToken: "edge-token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw := rawValues(t, backend)
+	for _, secret := range []string{"registry-password", "dockerhub-password", "ldap-password", "totp-secret", "stack-token", "edge-token"} {
+		if bytes.Contains(raw, []byte(secret)) {
+			t.Fatalf("%s must not be stored in plain text", secret)
+		}
+	}
+
+	assertSecrets := func(store *Store) {
+		registry, err := store.RegistryService.Registry(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dockerhub, err := store.DockerHubService.DockerHub()
+		if err != nil {
+			t.Fatal(err)
+		}
+		settings, err := store.SettingsService.Settings()
+		if err != nil {
+			t.Fatal(err)
+		}
+		user, err := store.UserService.UserByUsername("user")
+		if err != nil {
+			t.Fatal(err)
+		}
+		stack, err := store.StackService.Stack("stack")
+		if err != nil {
+			t.Fatal(err)
+		}
+		endpoint, err := store.EndpointService.Endpoint(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if registry.Password != "registry-password" || dockerhub.Password != "dockerhub-password" ||
+			settings.LDAPSettings.Password != "ldap-password" || user.TOTPSecret != "totp-secret" ||
+			stack.Env[0].Value != "stack-token" || endpoint.EdgeJoinToken != "edge-token" {
+			t.Fatalf("unexpected secrets: %q, %q, %q, %q, %q, %q", registry.Password, dockerhub.Password,
+				settings.LDAPSettings.Password, user.TOTPSecret, stack.Env[0].Value, endpoint.EdgeJoinToken)
+		}
+	}
+	assertSecrets(store)
+
+	// The other instances sharing the datastore use the same data key.
+	other := NewStore(backend)
+	if err = other.InitEncryption(nil, nil); err != chainid.ErrMasterKeyRequired {
+		t.Errorf("expected %v without master key, got %v", chainid.ErrMasterKeyRequired, err)
+	}
+	if err = other.InitEncryption([]byte("second-key"), nil); err != chainid.ErrInvalidMasterKey {
+		t.Errorf("expected %v with an invalid master key, got %v", chainid.ErrInvalidMasterKey, err)
+	}
+
+	err = other.InitEncryption([]byte("second-key"), []byte("first-key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSecrets(other)
+
+	other = NewStore(backend)
+	err = other.InitEncryption([]byte("second-key"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSecrets(other)
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	server := newFakeConsulServer()
+	defer server.Close()
+
+	consulBackend, err := NewConsulBackend(server.URL, "chainid", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, backend := range []Backend{NewMemoryBackend(), consulBackend} {
+		swapped, err := backend.CompareAndSwap("key", nil, []byte("1"))
+		if err != nil || !swapped {
+			t.Fatalf("expected the key to be created, got %v, %v", swapped, err)
+		}
+
+		swapped, err = backend.CompareAndSwap("key", nil, []byte("2"))
+		if err != nil || swapped {
+			t.Fatalf("expected an existing key not to be created again, got %v, %v", swapped, err)
+		}
+
+		swapped, err = backend.CompareAndSwap("key", []byte("0"), []byte("2"))
+		if err != nil || swapped {
+			t.Fatalf("expected the key not to be updated with a stale value, got %v, %v", swapped, err)
+		}
+
+		swapped, err = backend.CompareAndSwap("key", []byte("1"), []byte("2"))
+		if err != nil || !swapped {
+			t.Fatalf("expected the key to be updated, got %v, %v", swapped, err)
+		}
+
+		value, err := backend.Get("key")
+		if err != nil || string(value) != "2" {
+			t.Fatalf("expected value 2, got %q, %v", value, err)
+		}
+	}
+}
+
+func TestImport(t *testing.T) {
+	dataStorePath, err := ioutil.TempDir("", "chainid-import")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dataStorePath)
+
+	source, err := bolt.NewStore(dataStorePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = source.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer source.Close()
+
+	err = source.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = source.MigrateData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = source.InitEncryption([]byte("master-key"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, username := range []string{"admin", "removed", "alice"} {
+		err = source.UserService.CreateUser(&chainid.User{Username: username})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = source.UserService.DeleteUser(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = source.RegistryService.CreateRegistry(&chainid.Registry{Name: "private", Password: "registry-password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backend := NewMemoryBackend()
+	store := NewStore(backend)
+	err = store.InitEncryption([]byte("master-key"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.Import(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(rawValues(t, backend), []byte("registry-password")) {
+		t.Fatal("expected the secrets to be encrypted in the datastore")
+	}
+
+	user, err := store.UserService.User(3)
+	if err != nil || user.Username != "alice" {
+		t.Fatalf("expected the identifiers to be preserved, got %v, %v", user, err)
+	}
+
+	registry, err := store.RegistryService.Registry(1)
+	if err != nil || registry.Password != "registry-password" {
+		t.Fatalf("expected the registry to be imported, got %v, %v", registry, err)
+	}
+
+	roles, err := store.RoleService.Roles()
+	if err != nil || len(roles) != 2 {
+		t.Fatalf("expected the built-in roles to be imported, got %v, %v", roles, err)
+	}
+
+	newUser := &chainid.User{Username: "bob"}
+	err = store.UserService.CreateUser(newUser)
+	if err != nil || newUser.ID != 4 {
+		t.Fatalf("expected identifier 4 to be assigned after the import, got %d, %v", newUser.ID, err)
+	}
+
+	if err = store.Import(source); err != chainid.ErrDataStoreNotEmpty {
+		t.Errorf("expected %v when importing twice, got %v", chainid.ErrDataStoreNotEmpty, err)
+	}
+}
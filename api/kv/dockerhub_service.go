@@ -0,0 +1,38 @@
+package kv
+
+import (
+	"github.com/chainid-io/dashboard"
+)
+
+// DockerHubService represents a service for managing registries.
+type DockerHubService struct {
+	backend Backend
+	codec   *secretCodec
+}
+
+// DockerHub returns the DockerHub object.
+func (service *DockerHubService) DockerHub() (*chainid.DockerHub, error) {
+	data, err := service.backend.Get(dockerhubKey)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, chainid.ErrDockerHubNotFound
+	}
+
+	var dockerhub chainid.DockerHub
+	err = service.codec.decode(data, &dockerhub)
+	if err != nil {
+		return nil, err
+	}
+	return &dockerhub, nil
+}
+
+// StoreDockerHub persists a DockerHub object.
+func (service *DockerHubService) StoreDockerHub(dockerhub *chainid.DockerHub) error {
+	data, err := service.codec.encode(dockerhub)
+	if err != nil {
+		return err
+	}
+	return service.backend.Put(dockerhubKey, data)
+}
@@ -0,0 +1,129 @@
+package kv
+
+import (
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/internal/secrets"
+)
+
+// dataKeyKey is the key storing the data key, encrypted with the master key.
+const dataKeyKey = "encryption/data_key"
+
+// InitEncryption loads the data key used to encrypt the secrets stored in the datastore.
+// The data key is shared by the instances using the datastore and is stored encrypted with
+// the master key (envelope encryption), so that every instance must be started with the same
+// master key. When the datastore does not contain a data key yet, a new one is generated.
+// If the data key cannot be decrypted with the master key, the previous master key is used
+// and the data key is encrypted again with the new master key. The secrets stored in plain
+// text are then encrypted with the data key.
+// It must be called after Open and before any data is read.
+func (store *Store) InitEncryption(masterKey, previousMasterKey []byte) error {
+	wrappedDataKey, err := store.backend.Get(dataKeyKey)
+	if err != nil {
+		return err
+	}
+
+	if masterKey == nil {
+		if wrappedDataKey != nil {
+			return chainid.ErrMasterKeyRequired
+		}
+		return nil
+	}
+
+	masterKeyService, err := secrets.NewMasterKeyService(masterKey)
+	if err != nil {
+		return err
+	}
+
+	var dataKeyService chainid.EncryptionService
+	var newWrappedDataKey string
+	if wrappedDataKey == nil {
+		dataKeyService, newWrappedDataKey, err = secrets.GenerateDataKey(masterKeyService)
+	} else {
+		dataKeyService, err = secrets.UnwrapDataKey(masterKeyService, string(wrappedDataKey))
+		if err == chainid.ErrInvalidMasterKey && previousMasterKey != nil {
+			var previousMasterKeyService chainid.EncryptionService
+			previousMasterKeyService, err = secrets.NewMasterKeyService(previousMasterKey)
+			if err == nil {
+				dataKeyService, newWrappedDataKey, err = secrets.RewrapDataKey(masterKeyService, previousMasterKeyService, string(wrappedDataKey))
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if newWrappedDataKey != "" {
+		swapped, err := store.backend.CompareAndSwap(dataKeyKey, wrappedDataKey, []byte(newWrappedDataKey))
+		if err != nil {
+			return err
+		}
+		// The data key has been created or rotated by another instance in the meantime.
+		if !swapped {
+			return store.InitEncryption(masterKey, previousMasterKey)
+		}
+	}
+
+	store.mu.Lock()
+	store.secretCipher = dataKeyService
+	store.mu.Unlock()
+
+	return store.encryptSecrets()
+}
+
+// SecretCipher returns the service encrypting the secrets with the data key,
+// or nil when no master key has been specified.
+func (store *Store) SecretCipher() chainid.EncryptionService {
+	return store.cipher()
+}
+
+func (store *Store) cipher() chainid.EncryptionService {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	return store.secretCipher
+}
+
+// encryptSecrets encrypts with the data key the secrets stored in plain text, either before
+// the data key was created or by an initialization that has been interrupted.
+func (store *Store) encryptSecrets() error {
+	collections := []*collection{
+		store.UserService.users,
+		store.EndpointService.endpoints,
+		store.RegistryService.registries,
+		store.StackService.stacks,
+	}
+
+	for _, c := range collections {
+		err := c.encryptSecrets()
+		if err != nil {
+			return err
+		}
+	}
+
+	err := encryptValue(store.backend, settingsKey, store.SettingsService.codec)
+	if err != nil {
+		return err
+	}
+	return encryptValue(store.backend, dockerhubKey, store.DockerHubService.codec)
+}
+
+// encryptValue encodes again with the data key the value associated to the key if its secrets
+// are stored in plain text. The value is replaced with a compare-and-swap operation so that
+// the changes made concurrently by the other instances are not overwritten.
+func encryptValue(backend Backend, key string, codec *secretCodec) error {
+	for {
+		current, err := backend.Get(key)
+		if err != nil || current == nil {
+			return err
+		}
+
+		data, err := codec.encrypt(current)
+		if err != nil || data == nil {
+			return err
+		}
+
+		swapped, err := backend.CompareAndSwap(key, current, data)
+		if err != nil || swapped {
+			return err
+		}
+	}
+}
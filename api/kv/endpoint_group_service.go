@@ -0,0 +1,60 @@
+package kv
+
+import (
+	"encoding/json"
+
+	"github.com/chainid-io/dashboard"
+)
+
+// EndpointGroupService represents a service for managing endpoint groups.
+type EndpointGroupService struct {
+	groups *collection
+}
+
+// EndpointGroup returns an endpoint group by ID.
+func (service *EndpointGroupService) EndpointGroup(ID chainid.EndpointGroupID) (*chainid.EndpointGroup, error) {
+	var endpointGroup chainid.EndpointGroup
+	err := service.groups.get(formatID(int(ID)), &endpointGroup, chainid.ErrEndpointGroupNotFound)
+	if err != nil {
+		return nil, err
+	}
+	return &endpointGroup, nil
+}
+
+// EndpointGroups return an array containing all the endpoint groups.
+func (service *EndpointGroupService) EndpointGroups() ([]chainid.EndpointGroup, error) {
+	var endpointGroups = make([]chainid.EndpointGroup, 0)
+	err := service.groups.forEach(func(data []byte) error {
+		var endpointGroup chainid.EndpointGroup
+		err := json.Unmarshal(data, &endpointGroup)
+		if err != nil {
+			return err
+		}
+		endpointGroups = append(endpointGroups, endpointGroup)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return endpointGroups, nil
+}
+
+// CreateEndpointGroup assign an ID to a new endpoint group and saves it.
+func (service *EndpointGroupService) CreateEndpointGroup(endpointGroup *chainid.EndpointGroup) error {
+	id, err := service.groups.nextID()
+	if err != nil {
+		return err
+	}
+	endpointGroup.ID = chainid.EndpointGroupID(id)
+	return service.groups.put(formatID(id), endpointGroup)
+}
+
+// UpdateEndpointGroup updates an endpoint group.
+func (service *EndpointGroupService) UpdateEndpointGroup(ID chainid.EndpointGroupID, endpointGroup *chainid.EndpointGroup) error {
+	return service.groups.put(formatID(int(ID)), endpointGroup)
+}
+
+// DeleteEndpointGroup deletes an endpoint group.
+func (service *EndpointGroupService) DeleteEndpointGroup(ID chainid.EndpointGroupID) error {
+	return service.groups.delete(formatID(int(ID)))
+}
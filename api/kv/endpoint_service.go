@@ -0,0 +1,158 @@
+package kv
+
+import (
+	"sync"
+
+	"github.com/chainid-io/dashboard"
+)
+
+// EndpointService represents a service for managing endpoints.
+type EndpointService struct {
+	endpoints *collection
+
+	mu        sync.RWMutex
+	observers []chainid.EndpointObserver
+}
+
+// RegisterObserver registers an observer notified after each change made by the service.
+// The changes made by the other instances sharing the datastore are not notified.
+func (service *EndpointService) RegisterObserver(observer chainid.EndpointObserver) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	service.observers = append(service.observers, observer)
+}
+
+func (service *EndpointService) notifyChanged(endpoint *chainid.Endpoint) {
+	service.mu.RLock()
+	defer service.mu.RUnlock()
+
+	for _, observer := range service.observers {
+		observer.EndpointChanged(endpoint)
+	}
+}
+
+func (service *EndpointService) notifyDeleted(ID chainid.EndpointID) {
+	service.mu.RLock()
+	defer service.mu.RUnlock()
+
+	for _, observer := range service.observers {
+		observer.EndpointDeleted(ID)
+	}
+}
+
+// Endpoint returns an endpoint by ID.
+func (service *EndpointService) Endpoint(ID chainid.EndpointID) (*chainid.Endpoint, error) {
+	var endpoint chainid.Endpoint
+	err := service.endpoints.get(formatID(int(ID)), &endpoint, chainid.ErrEndpointNotFound)
+	if err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+// Endpoints return an array containing all the endpoints.
+func (service *EndpointService) Endpoints() ([]chainid.Endpoint, error) {
+	var endpoints = make([]chainid.Endpoint, 0)
+	err := service.endpoints.forEach(func(data []byte) error {
+		var endpoint chainid.Endpoint
+		err := service.endpoints.decode(data, &endpoint)
+		if err != nil {
+			return err
+		}
+		endpoints = append(endpoints, endpoint)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+// Synchronize creates, updates and deletes endpoints. Unlike the BoltDB implementation,
+// the operations are not applied inside a single transaction.
+func (service *EndpointService) Synchronize(toCreate, toUpdate, toDelete []*chainid.Endpoint) error {
+	for _, endpoint := range toCreate {
+		err := service.CreateEndpoint(endpoint)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, endpoint := range toUpdate {
+		err := service.UpdateEndpoint(endpoint.ID, endpoint)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, endpoint := range toDelete {
+		err := service.DeleteEndpoint(endpoint.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CreateEndpoint assign an ID to a new endpoint and saves it.
+func (service *EndpointService) CreateEndpoint(endpoint *chainid.Endpoint) error {
+	id, err := service.endpoints.nextID()
+	if err != nil {
+		return err
+	}
+	endpoint.ID = chainid.EndpointID(id)
+	err = service.endpoints.put(formatID(id), endpoint)
+	if err != nil {
+		return err
+	}
+
+	service.notifyChanged(endpoint)
+	return nil
+}
+
+// UpdateEndpoint updates an endpoint.
+func (service *EndpointService) UpdateEndpoint(ID chainid.EndpointID, endpoint *chainid.Endpoint) error {
+	err := service.endpoints.put(formatID(int(ID)), endpoint)
+	if err != nil {
+		return err
+	}
+
+	service.notifyChanged(endpoint)
+	return nil
+}
+
+// UpdateEndpointFunc applies updateFunc to an endpoint and saves it with a compare-and-swap
+// operation, so that the changes made concurrently to the other fields of the endpoint are
+// not overwritten.
+func (service *EndpointService) UpdateEndpointFunc(ID chainid.EndpointID, updateFunc func(endpoint *chainid.Endpoint)) error {
+	var endpoint *chainid.Endpoint
+	err := service.endpoints.update(formatID(int(ID)), chainid.ErrEndpointNotFound, func(data []byte) (interface{}, error) {
+		endpoint = &chainid.Endpoint{}
+		err := service.endpoints.decode(data, endpoint)
+		if err != nil {
+			return nil, err
+		}
+
+		updateFunc(endpoint)
+		return endpoint, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	service.notifyChanged(endpoint)
+	return nil
+}
+
+// DeleteEndpoint deletes an endpoint.
+func (service *EndpointService) DeleteEndpoint(ID chainid.EndpointID) error {
+	err := service.endpoints.delete(formatID(int(ID)))
+	if err != nil {
+		return err
+	}
+
+	service.notifyDeleted(ID)
+	return nil
+}
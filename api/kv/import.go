@@ -0,0 +1,221 @@
+package kv
+
+import (
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/bolt"
+)
+
+// Import copies the data of a BoltDB database into the store. The identifiers of the
+// objects are preserved. The store must be empty and the database must have been migrated
+// to the current DBVersion.
+func (store *Store) Import(source *bolt.Store) error {
+	_, err := store.VersionService.DBVersion()
+	if err == nil {
+		return chainid.ErrDataStoreNotEmpty
+	} else if err != chainid.ErrDBVersionNotFound {
+		return err
+	}
+
+	version, err := source.VersionService.DBVersion()
+	if err != nil {
+		return err
+	}
+	if version != chainid.DBVersion {
+		return chainid.ErrDataStoreMigrationNotSupported
+	}
+
+	importers := []func(source *bolt.Store) error{
+		store.importUsers,
+		store.importTeams,
+		store.importTeamMemberships,
+		store.importEndpoints,
+		store.importEndpointGroups,
+		store.importResourceControls,
+		store.importRegistries,
+		store.importStacks,
+		store.importRoles,
+		store.importRoleAssignments,
+		store.importSettings,
+	}
+
+	for _, importData := range importers {
+		err = importData(source)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The version is stored last so that an interrupted import can be started again.
+	return store.VersionService.StoreDBVersion(version)
+}
+
+// importObject stores an object with its original identifier and makes sure
+// the identifier will not be assigned again.
+func importObject(c *collection, ID int, object interface{}) error {
+	err := c.put(formatID(ID), object)
+	if err != nil {
+		return err
+	}
+	return c.setSequence(ID)
+}
+
+func (store *Store) importUsers(source *bolt.Store) error {
+	users, err := source.UserService.Users()
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		err = importObject(store.UserService.users, int(user.ID), &user)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (store *Store) importTeams(source *bolt.Store) error {
+	teams, err := source.TeamService.Teams()
+	if err != nil {
+		return err
+	}
+	for _, team := range teams {
+		err = importObject(store.TeamService.teams, int(team.ID), &team)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (store *Store) importTeamMemberships(source *bolt.Store) error {
+	memberships, err := source.TeamMembershipService.TeamMemberships()
+	if err != nil {
+		return err
+	}
+	for _, membership := range memberships {
+		err = importObject(store.TeamMembershipService.memberships, int(membership.ID), &membership)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (store *Store) importEndpoints(source *bolt.Store) error {
+	endpoints, err := source.EndpointService.Endpoints()
+	if err != nil {
+		return err
+	}
+	for _, endpoint := range endpoints {
+		err = importObject(store.EndpointService.endpoints, int(endpoint.ID), &endpoint)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (store *Store) importEndpointGroups(source *bolt.Store) error {
+	groups, err := source.EndpointGroupService.EndpointGroups()
+	if err != nil {
+		return err
+	}
+	for _, group := range groups {
+		err = importObject(store.EndpointGroupService.groups, int(group.ID), &group)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (store *Store) importResourceControls(source *bolt.Store) error {
+	resourceControls, err := source.ResourceControlService.ResourceControls()
+	if err != nil {
+		return err
+	}
+	for _, resourceControl := range resourceControls {
+		err = importObject(store.ResourceControlService.resourceControls, int(resourceControl.ID), &resourceControl)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (store *Store) importRegistries(source *bolt.Store) error {
+	registries, err := source.RegistryService.Registries()
+	if err != nil {
+		return err
+	}
+	for _, registry := range registries {
+		err = importObject(store.RegistryService.registries, int(registry.ID), &registry)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (store *Store) importStacks(source *bolt.Store) error {
+	stacks, err := source.StackService.Stacks()
+	if err != nil {
+		return err
+	}
+	for _, stack := range stacks {
+		err = store.StackService.CreateStack(&stack)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (store *Store) importRoles(source *bolt.Store) error {
+	roles, err := source.RoleService.Roles()
+	if err != nil {
+		return err
+	}
+	for _, role := range roles {
+		err = importObject(store.RoleService.roles, int(role.ID), &role)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (store *Store) importRoleAssignments(source *bolt.Store) error {
+	assignments, err := source.RoleAssignmentService.RoleAssignments()
+	if err != nil {
+		return err
+	}
+	for _, assignment := range assignments {
+		err = importObject(store.RoleAssignmentService.assignments, int(assignment.ID), &assignment)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importSettings copies the settings and the DockerHub credentials. The audit log
+// and the status of the scheduled backups are not imported.
+func (store *Store) importSettings(source *bolt.Store) error {
+	settings, err := source.SettingsService.Settings()
+	if err == nil {
+		err = store.SettingsService.StoreSettings(settings)
+	}
+	if err != nil && err != chainid.ErrSettingsNotFound {
+		return err
+	}
+
+	dockerhub, err := source.DockerHubService.DockerHub()
+	if err == nil {
+		err = store.DockerHubService.StoreDockerHub(dockerhub)
+	}
+	if err != nil && err != chainid.ErrDockerHubNotFound {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package kv
+
+// Entry represents a key/value pair stored in a Backend.
+type Entry struct {
+	Key   string
+	Value []byte
+}
+
+// Backend represents a key/value storage system. Keys are slash separated paths.
+type Backend interface {
+	// Get returns the value associated to the key, or nil if the key does not exist.
+	Get(key string) ([]byte, error)
+	// List returns the entries whose key starts with the prefix, sorted by key.
+	List(prefix string) ([]Entry, error)
+	// Put associates the value to the key.
+	Put(key string, value []byte) error
+	// Delete removes the key. Deleting a key that does not exist is not an error.
+	Delete(key string) error
+	// CompareAndSwap associates the value to the key only if the current value is equal
+	// to previous. A nil previous value means that the key must not exist.
+	// It returns false when the value has not been updated.
+	CompareAndSwap(key string, previous, value []byte) (bool, error)
+	// Close releases the resources associated to the backend.
+	Close() error
+}
@@ -0,0 +1,90 @@
+package kv
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MemoryBackend is an in-process Backend. The data is lost when the process exits,
+// it is meant to be used in tests and by instances that do not share their data.
+type MemoryBackend struct {
+	mu   sync.RWMutex
+	data map[string][]byte
+}
+
+// NewMemoryBackend returns a new empty MemoryBackend.
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{
+		data: make(map[string][]byte),
+	}
+}
+
+// Get returns the value associated to the key, or nil if the key does not exist.
+func (backend *MemoryBackend) Get(key string) ([]byte, error) {
+	backend.mu.RLock()
+	defer backend.mu.RUnlock()
+
+	value, ok := backend.data[key]
+	if !ok {
+		return nil, nil
+	}
+	return copyValue(value), nil
+}
+
+// List returns the entries whose key starts with the prefix, sorted by key.
+func (backend *MemoryBackend) List(prefix string) ([]Entry, error) {
+	backend.mu.RLock()
+	defer backend.mu.RUnlock()
+
+	entries := make([]Entry, 0)
+	for key, value := range backend.data {
+		if strings.HasPrefix(key, prefix) {
+			entries = append(entries, Entry{Key: key, Value: copyValue(value)})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries, nil
+}
+
+// Put associates the value to the key.
+func (backend *MemoryBackend) Put(key string, value []byte) error {
+	backend.mu.Lock()
+	backend.data[key] = copyValue(value)
+	backend.mu.Unlock()
+	return nil
+}
+
+// Delete removes the key.
+func (backend *MemoryBackend) Delete(key string) error {
+	backend.mu.Lock()
+	delete(backend.data, key)
+	backend.mu.Unlock()
+	return nil
+}
+
+// CompareAndSwap associates the value to the key only if the current value is equal to previous.
+func (backend *MemoryBackend) CompareAndSwap(key string, previous, value []byte) (bool, error) {
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+
+	current, exists := backend.data[key]
+	if (previous == nil && exists) || (previous != nil && (!exists || !bytes.Equal(current, previous))) {
+		return false, nil
+	}
+
+	backend.data[key] = copyValue(value)
+	return true, nil
+}
+
+// Close does nothing, the data is kept until the backend is garbage collected.
+func (backend *MemoryBackend) Close() error {
+	return nil
+}
+
+func copyValue(value []byte) []byte {
+	result := make([]byte, len(value))
+	copy(result, value)
+	return result
+}
@@ -0,0 +1,58 @@
+package kv
+
+import (
+	"github.com/chainid-io/dashboard"
+)
+
+// RegistryService represents a service for managing registries.
+type RegistryService struct {
+	registries *collection
+}
+
+// Registry returns an registry by ID.
+func (service *RegistryService) Registry(ID chainid.RegistryID) (*chainid.Registry, error) {
+	var registry chainid.Registry
+	err := service.registries.get(formatID(int(ID)), &registry, chainid.ErrRegistryNotFound)
+	if err != nil {
+		return nil, err
+	}
+	return &registry, nil
+}
+
+// Registries returns an array containing all the registries.
+func (service *RegistryService) Registries() ([]chainid.Registry, error) {
+	var registries = make([]chainid.Registry, 0)
+	err := service.registries.forEach(func(data []byte) error {
+		var registry chainid.Registry
+		err := service.registries.decode(data, &registry)
+		if err != nil {
+			return err
+		}
+		registries = append(registries, registry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return registries, nil
+}
+
+// CreateRegistry creates a new registry.
+func (service *RegistryService) CreateRegistry(registry *chainid.Registry) error {
+	id, err := service.registries.nextID()
+	if err != nil {
+		return err
+	}
+	registry.ID = chainid.RegistryID(id)
+	return service.registries.put(formatID(id), registry)
+}
+
+// UpdateRegistry updates an registry.
+func (service *RegistryService) UpdateRegistry(ID chainid.RegistryID, registry *chainid.Registry) error {
+	return service.registries.put(formatID(int(ID)), registry)
+}
+
+// DeleteRegistry deletes an registry.
+func (service *RegistryService) DeleteRegistry(ID chainid.RegistryID) error {
+	return service.registries.delete(formatID(int(ID)))
+}
@@ -0,0 +1,86 @@
+package kv
+
+import (
+	"encoding/json"
+
+	"github.com/chainid-io/dashboard"
+)
+
+// ResourceControlService represents a service for managing resource controls.
+type ResourceControlService struct {
+	resourceControls *collection
+}
+
+// ResourceControl returns a ResourceControl object by ID
+func (service *ResourceControlService) ResourceControl(ID chainid.ResourceControlID) (*chainid.ResourceControl, error) {
+	var resourceControl chainid.ResourceControl
+	err := service.resourceControls.get(formatID(int(ID)), &resourceControl, chainid.ErrResourceControlNotFound)
+	if err != nil {
+		return nil, err
+	}
+	return &resourceControl, nil
+}
+
+// ResourceControlByResourceID returns a ResourceControl object by checking if the resourceID is equal
+// to the main ResourceID or in SubResourceIDs
+func (service *ResourceControlService) ResourceControlByResourceID(resourceID string) (*chainid.ResourceControl, error) {
+	resourceControls, err := service.ResourceControls()
+	if err != nil {
+		return nil, err
+	}
+
+	var resourceControl *chainid.ResourceControl
+	for i, rc := range resourceControls {
+		if rc.ResourceID == resourceID {
+			resourceControl = &resourceControls[i]
+		}
+		for _, subResourceID := range rc.SubResourceIDs {
+			if subResourceID == resourceID {
+				resourceControl = &resourceControls[i]
+			}
+		}
+	}
+
+	if resourceControl == nil {
+		return nil, chainid.ErrResourceControlNotFound
+	}
+	return resourceControl, nil
+}
+
+// ResourceControls returns all the ResourceControl objects
+func (service *ResourceControlService) ResourceControls() ([]chainid.ResourceControl, error) {
+	var rcs = make([]chainid.ResourceControl, 0)
+	err := service.resourceControls.forEach(func(data []byte) error {
+		var resourceControl chainid.ResourceControl
+		err := json.Unmarshal(data, &resourceControl)
+		if err != nil {
+			return err
+		}
+		rcs = append(rcs, resourceControl)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rcs, nil
+}
+
+// CreateResourceControl creates a new ResourceControl object
+func (service *ResourceControlService) CreateResourceControl(resourceControl *chainid.ResourceControl) error {
+	id, err := service.resourceControls.nextID()
+	if err != nil {
+		return err
+	}
+	resourceControl.ID = chainid.ResourceControlID(id)
+	return service.resourceControls.put(formatID(id), resourceControl)
+}
+
+// UpdateResourceControl saves a ResourceControl object.
+func (service *ResourceControlService) UpdateResourceControl(ID chainid.ResourceControlID, resourceControl *chainid.ResourceControl) error {
+	return service.resourceControls.put(formatID(int(ID)), resourceControl)
+}
+
+// DeleteResourceControl deletes a ResourceControl object by ID
+func (service *ResourceControlService) DeleteResourceControl(ID chainid.ResourceControlID) error {
+	return service.resourceControls.delete(formatID(int(ID)))
+}
@@ -0,0 +1,93 @@
+package kv
+
+import (
+	"encoding/json"
+
+	"github.com/chainid-io/dashboard"
+)
+
+// RoleAssignmentService represents a service for managing role assignments.
+type RoleAssignmentService struct {
+	assignments *collection
+}
+
+// RoleAssignment returns a RoleAssignment by ID
+func (service *RoleAssignmentService) RoleAssignment(ID chainid.RoleAssignmentID) (*chainid.RoleAssignment, error) {
+	var assignment chainid.RoleAssignment
+	err := service.assignments.get(formatID(int(ID)), &assignment, chainid.ErrRoleAssignmentNotFound)
+	if err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+// RoleAssignments return an array containing all the role assignments.
+func (service *RoleAssignmentService) RoleAssignments() ([]chainid.RoleAssignment, error) {
+	var assignments = make([]chainid.RoleAssignment, 0)
+	err := service.assignments.forEach(func(data []byte) error {
+		var assignment chainid.RoleAssignment
+		err := json.Unmarshal(data, &assignment)
+		if err != nil {
+			return err
+		}
+		assignments = append(assignments, assignment)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+// CreateRoleAssignment creates a new RoleAssignment.
+func (service *RoleAssignmentService) CreateRoleAssignment(assignment *chainid.RoleAssignment) error {
+	id, err := service.assignments.nextID()
+	if err != nil {
+		return err
+	}
+	assignment.ID = chainid.RoleAssignmentID(id)
+	return service.assignments.put(formatID(id), assignment)
+}
+
+// DeleteRoleAssignment deletes a RoleAssignment.
+func (service *RoleAssignmentService) DeleteRoleAssignment(ID chainid.RoleAssignmentID) error {
+	return service.assignments.delete(formatID(int(ID)))
+}
+
+// DeleteRoleAssignmentsByUserID deletes all the RoleAssignment objects associated to a UserID.
+func (service *RoleAssignmentService) DeleteRoleAssignmentsByUserID(userID chainid.UserID) error {
+	return service.deleteRoleAssignments(func(assignment *chainid.RoleAssignment) bool {
+		return assignment.UserID == userID
+	})
+}
+
+// DeleteRoleAssignmentsByTeamID deletes all the RoleAssignment objects associated to a TeamID.
+func (service *RoleAssignmentService) DeleteRoleAssignmentsByTeamID(teamID chainid.TeamID) error {
+	return service.deleteRoleAssignments(func(assignment *chainid.RoleAssignment) bool {
+		return assignment.TeamID == teamID
+	})
+}
+
+// DeleteRoleAssignmentsByRoleID deletes all the RoleAssignment objects associated to a RoleID.
+func (service *RoleAssignmentService) DeleteRoleAssignmentsByRoleID(roleID chainid.RoleID) error {
+	return service.deleteRoleAssignments(func(assignment *chainid.RoleAssignment) bool {
+		return assignment.RoleID == roleID
+	})
+}
+
+func (service *RoleAssignmentService) deleteRoleAssignments(match func(assignment *chainid.RoleAssignment) bool) error {
+	assignments, err := service.RoleAssignments()
+	if err != nil {
+		return err
+	}
+
+	for _, assignment := range assignments {
+		if match(&assignment) {
+			err = service.DeleteRoleAssignment(assignment.ID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
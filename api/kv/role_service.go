@@ -0,0 +1,75 @@
+package kv
+
+import (
+	"encoding/json"
+
+	"github.com/chainid-io/dashboard"
+)
+
+// RoleService represents a service for managing roles.
+type RoleService struct {
+	roles *collection
+}
+
+// Role returns a Role by ID
+func (service *RoleService) Role(ID chainid.RoleID) (*chainid.Role, error) {
+	var role chainid.Role
+	err := service.roles.get(formatID(int(ID)), &role, chainid.ErrRoleNotFound)
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// RoleByName returns a role by name.
+func (service *RoleService) RoleByName(name string) (*chainid.Role, error) {
+	roles, err := service.Roles()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, role := range roles {
+		if role.Name == name {
+			return &role, nil
+		}
+	}
+	return nil, chainid.ErrRoleNotFound
+}
+
+// Roles return an array containing all the roles.
+func (service *RoleService) Roles() ([]chainid.Role, error) {
+	var roles = make([]chainid.Role, 0)
+	err := service.roles.forEach(func(data []byte) error {
+		var role chainid.Role
+		err := json.Unmarshal(data, &role)
+		if err != nil {
+			return err
+		}
+		roles = append(roles, role)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// UpdateRole saves a Role.
+func (service *RoleService) UpdateRole(ID chainid.RoleID, role *chainid.Role) error {
+	return service.roles.put(formatID(int(ID)), role)
+}
+
+// CreateRole creates a new Role.
+func (service *RoleService) CreateRole(role *chainid.Role) error {
+	id, err := service.roles.nextID()
+	if err != nil {
+		return err
+	}
+	role.ID = chainid.RoleID(id)
+	return service.roles.put(formatID(id), role)
+}
+
+// DeleteRole deletes a Role.
+func (service *RoleService) DeleteRole(ID chainid.RoleID) error {
+	return service.roles.delete(formatID(int(ID)))
+}
@@ -0,0 +1,117 @@
+package kv
+
+import (
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/internal/secrets"
+)
+
+// secretCodec encodes the objects containing secrets in the same way as the BoltDB
+// datastore. The secrets are encrypted with the data key of the store once it is loaded.
+type secretCodec struct {
+	store     *Store
+	newObject func() interface{}
+	marshal   func(object interface{}, cipher chainid.EncryptionService) ([]byte, error)
+	unmarshal func(data []byte, object interface{}, cipher chainid.EncryptionService) error
+}
+
+func (codec *secretCodec) encode(object interface{}) ([]byte, error) {
+	return codec.marshal(object, codec.store.cipher())
+}
+
+func (codec *secretCodec) decode(data []byte, object interface{}) error {
+	return codec.unmarshal(data, object, codec.store.cipher())
+}
+
+// encrypt returns the value encoded again with the data key, or nil if its secrets
+// are already encrypted.
+func (codec *secretCodec) encrypt(data []byte) ([]byte, error) {
+	encrypted, err := secrets.Encrypted(data)
+	if err != nil || encrypted {
+		return nil, err
+	}
+
+	object := codec.newObject()
+	err = codec.decode(data, object)
+	if err != nil {
+		return nil, err
+	}
+	return codec.encode(object)
+}
+
+func newUserCodec(store *Store) *secretCodec {
+	return &secretCodec{
+		store:     store,
+		newObject: func() interface{} { return &chainid.User{} },
+		marshal: func(object interface{}, cipher chainid.EncryptionService) ([]byte, error) {
+			return secrets.MarshalUser(object.(*chainid.User), cipher)
+		},
+		unmarshal: func(data []byte, object interface{}, cipher chainid.EncryptionService) error {
+			return secrets.UnmarshalUser(data, object.(*chainid.User), cipher)
+		},
+	}
+}
+
+func newEndpointCodec(store *Store) *secretCodec {
+	return &secretCodec{
+		store:     store,
+		newObject: func() interface{} { return &chainid.Endpoint{} },
+		marshal: func(object interface{}, cipher chainid.EncryptionService) ([]byte, error) {
+			return secrets.MarshalEndpoint(object.(*chainid.Endpoint), cipher)
+		},
+		unmarshal: func(data []byte, object interface{}, cipher chainid.EncryptionService) error {
+			return secrets.UnmarshalEndpoint(data, object.(*chainid.Endpoint), cipher)
+		},
+	}
+}
+
+func newRegistryCodec(store *Store) *secretCodec {
+	return &secretCodec{
+		store:     store,
+		newObject: func() interface{} { return &chainid.Registry{} },
+		marshal: func(object interface{}, cipher chainid.EncryptionService) ([]byte, error) {
+			return secrets.MarshalRegistry(object.(*chainid.Registry), cipher)
+		},
+		unmarshal: func(data []byte, object interface{}, cipher chainid.EncryptionService) error {
+			return secrets.UnmarshalRegistry(data, object.(*chainid.Registry), cipher)
+		},
+	}
+}
+
+func newStackCodec(store *Store) *secretCodec {
+	return &secretCodec{
+		store:     store,
+		newObject: func() interface{} { return &chainid.Stack{} },
+		marshal: func(object interface{}, cipher chainid.EncryptionService) ([]byte, error) {
+			return secrets.MarshalStack(object.(*chainid.Stack), cipher)
+		},
+		unmarshal: func(data []byte, object interface{}, cipher chainid.EncryptionService) error {
+			return secrets.UnmarshalStack(data, object.(*chainid.Stack), cipher)
+		},
+	}
+}
+
+func newSettingsCodec(store *Store) *secretCodec {
+	return &secretCodec{
+		store:     store,
+		newObject: func() interface{} { return &chainid.Settings{} },
+		marshal: func(object interface{}, cipher chainid.EncryptionService) ([]byte, error) {
+			return secrets.MarshalSettings(object.(*chainid.Settings), cipher)
+		},
+		unmarshal: func(data []byte, object interface{}, cipher chainid.EncryptionService) error {
+			return secrets.UnmarshalSettings(data, object.(*chainid.Settings), cipher)
+		},
+	}
+}
+
+func newDockerHubCodec(store *Store) *secretCodec {
+	return &secretCodec{
+		store:     store,
+		newObject: func() interface{} { return &chainid.DockerHub{} },
+		marshal: func(object interface{}, cipher chainid.EncryptionService) ([]byte, error) {
+			return secrets.MarshalDockerHub(object.(*chainid.DockerHub), cipher)
+		},
+		unmarshal: func(data []byte, object interface{}, cipher chainid.EncryptionService) error {
+			return secrets.UnmarshalDockerHub(data, object.(*chainid.DockerHub), cipher)
+		},
+	}
+}
@@ -0,0 +1,38 @@
+package kv
+
+import (
+	"github.com/chainid-io/dashboard"
+)
+
+// SettingsService represents a service to manage application settings.
+type SettingsService struct {
+	backend Backend
+	codec   *secretCodec
+}
+
+// Settings retrieve the settings object.
+func (service *SettingsService) Settings() (*chainid.Settings, error) {
+	data, err := service.backend.Get(settingsKey)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, chainid.ErrSettingsNotFound
+	}
+
+	var settings chainid.Settings
+	err = service.codec.decode(data, &settings)
+	if err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// StoreSettings persists a Settings object.
+func (service *SettingsService) StoreSettings(settings *chainid.Settings) error {
+	data, err := service.codec.encode(settings)
+	if err != nil {
+		return err
+	}
+	return service.backend.Put(settingsKey, data)
+}
@@ -0,0 +1,69 @@
+package kv
+
+import (
+	"github.com/chainid-io/dashboard"
+)
+
+// StackService represents a service for managing stacks.
+type StackService struct {
+	stacks *collection
+}
+
+// Stack returns a stack object by ID.
+func (service *StackService) Stack(ID chainid.StackID) (*chainid.Stack, error) {
+	var stack chainid.Stack
+	err := service.stacks.get(string(ID), &stack, chainid.ErrStackNotFound)
+	if err != nil {
+		return nil, err
+	}
+	return &stack, nil
+}
+
+// Stacks returns an array containing all the stacks.
+func (service *StackService) Stacks() ([]chainid.Stack, error) {
+	var stacks = make([]chainid.Stack, 0)
+	err := service.stacks.forEach(func(data []byte) error {
+		var stack chainid.Stack
+		err := service.stacks.decode(data, &stack)
+		if err != nil {
+			return err
+		}
+		stacks = append(stacks, stack)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stacks, nil
+}
+
+// StacksBySwarmID return an array containing all the stacks related to the specified Swarm ID.
+func (service *StackService) StacksBySwarmID(id string) ([]chainid.Stack, error) {
+	stacks, err := service.Stacks()
+	if err != nil {
+		return nil, err
+	}
+
+	var filteredStacks = make([]chainid.Stack, 0)
+	for _, stack := range stacks {
+		if stack.SwarmID == id {
+			filteredStacks = append(filteredStacks, stack)
+		}
+	}
+	return filteredStacks, nil
+}
+
+// CreateStack creates a new stack.
+func (service *StackService) CreateStack(stack *chainid.Stack) error {
+	return service.stacks.put(string(stack.ID), stack)
+}
+
+// UpdateStack updates an stack.
+func (service *StackService) UpdateStack(ID chainid.StackID, stack *chainid.Stack) error {
+	return service.stacks.put(string(ID), stack)
+}
+
+// DeleteStack deletes an stack.
+func (service *StackService) DeleteStack(ID chainid.StackID) error {
+	return service.stacks.delete(string(ID))
+}
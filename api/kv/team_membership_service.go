@@ -0,0 +1,104 @@
+package kv
+
+import (
+	"encoding/json"
+
+	"github.com/chainid-io/dashboard"
+)
+
+// TeamMembershipService represents a service for managing TeamMembership objects.
+type TeamMembershipService struct {
+	memberships *collection
+}
+
+// TeamMembership returns a TeamMembership object by ID
+func (service *TeamMembershipService) TeamMembership(ID chainid.TeamMembershipID) (*chainid.TeamMembership, error) {
+	var membership chainid.TeamMembership
+	err := service.memberships.get(formatID(int(ID)), &membership, chainid.ErrTeamMembershipNotFound)
+	if err != nil {
+		return nil, err
+	}
+	return &membership, nil
+}
+
+// TeamMemberships return an array containing all the TeamMembership objects.
+func (service *TeamMembershipService) TeamMemberships() ([]chainid.TeamMembership, error) {
+	return service.filter(func(membership *chainid.TeamMembership) bool { return true })
+}
+
+// TeamMembershipsByUserID return an array containing all the TeamMembership objects where the specified userID is present.
+func (service *TeamMembershipService) TeamMembershipsByUserID(userID chainid.UserID) ([]chainid.TeamMembership, error) {
+	return service.filter(func(membership *chainid.TeamMembership) bool { return membership.UserID == userID })
+}
+
+// TeamMembershipsByTeamID return an array containing all the TeamMembership objects where the specified teamID is present.
+func (service *TeamMembershipService) TeamMembershipsByTeamID(teamID chainid.TeamID) ([]chainid.TeamMembership, error) {
+	return service.filter(func(membership *chainid.TeamMembership) bool { return membership.TeamID == teamID })
+}
+
+// UpdateTeamMembership saves a TeamMembership object.
+func (service *TeamMembershipService) UpdateTeamMembership(ID chainid.TeamMembershipID, membership *chainid.TeamMembership) error {
+	return service.memberships.put(formatID(int(ID)), membership)
+}
+
+// CreateTeamMembership creates a new TeamMembership object.
+func (service *TeamMembershipService) CreateTeamMembership(membership *chainid.TeamMembership) error {
+	id, err := service.memberships.nextID()
+	if err != nil {
+		return err
+	}
+	membership.ID = chainid.TeamMembershipID(id)
+	return service.memberships.put(formatID(id), membership)
+}
+
+// DeleteTeamMembership deletes a TeamMembership object.
+func (service *TeamMembershipService) DeleteTeamMembership(ID chainid.TeamMembershipID) error {
+	return service.memberships.delete(formatID(int(ID)))
+}
+
+// DeleteTeamMembershipByUserID deletes all the TeamMembership object associated to a UserID.
+func (service *TeamMembershipService) DeleteTeamMembershipByUserID(userID chainid.UserID) error {
+	memberships, err := service.TeamMembershipsByUserID(userID)
+	if err != nil {
+		return err
+	}
+	return service.deleteMemberships(memberships)
+}
+
+// DeleteTeamMembershipByTeamID deletes all the TeamMembership object associated to a TeamID.
+func (service *TeamMembershipService) DeleteTeamMembershipByTeamID(teamID chainid.TeamID) error {
+	memberships, err := service.TeamMembershipsByTeamID(teamID)
+	if err != nil {
+		return err
+	}
+	return service.deleteMemberships(memberships)
+}
+
+func (service *TeamMembershipService) filter(match func(membership *chainid.TeamMembership) bool) ([]chainid.TeamMembership, error) {
+	var memberships = make([]chainid.TeamMembership, 0)
+	err := service.memberships.forEach(func(data []byte) error {
+		var membership chainid.TeamMembership
+		err := json.Unmarshal(data, &membership)
+		if err != nil {
+			return err
+		}
+		if match(&membership) {
+			memberships = append(memberships, membership)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return memberships, nil
+}
+
+func (service *TeamMembershipService) deleteMemberships(memberships []chainid.TeamMembership) error {
+	for _, membership := range memberships {
+		err := service.DeleteTeamMembership(membership.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package kv
+
+import (
+	"encoding/json"
+
+	"github.com/chainid-io/dashboard"
+)
+
+// TeamService represents a service for managing teams.
+type TeamService struct {
+	teams *collection
+}
+
+// Team returns a Team by ID
+func (service *TeamService) Team(ID chainid.TeamID) (*chainid.Team, error) {
+	var team chainid.Team
+	err := service.teams.get(formatID(int(ID)), &team, chainid.ErrTeamNotFound)
+	if err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// TeamByName returns a team by name.
+func (service *TeamService) TeamByName(name string) (*chainid.Team, error) {
+	teams, err := service.Teams()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, team := range teams {
+		if team.Name == name {
+			return &team, nil
+		}
+	}
+	return nil, chainid.ErrTeamNotFound
+}
+
+// Teams return an array containing all the teams.
+func (service *TeamService) Teams() ([]chainid.Team, error) {
+	var teams = make([]chainid.Team, 0)
+	err := service.teams.forEach(func(data []byte) error {
+		var team chainid.Team
+		err := json.Unmarshal(data, &team)
+		if err != nil {
+			return err
+		}
+		teams = append(teams, team)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
+
+// UpdateTeam saves a Team.
+func (service *TeamService) UpdateTeam(ID chainid.TeamID, team *chainid.Team) error {
+	return service.teams.put(formatID(int(ID)), team)
+}
+
+// CreateTeam creates a new Team.
+func (service *TeamService) CreateTeam(team *chainid.Team) error {
+	id, err := service.teams.nextID()
+	if err != nil {
+		return err
+	}
+	team.ID = chainid.TeamID(id)
+	return service.teams.put(formatID(id), team)
+}
+
+// DeleteTeam deletes a Team.
+func (service *TeamService) DeleteTeam(ID chainid.TeamID) error {
+	return service.teams.delete(formatID(int(ID)))
+}
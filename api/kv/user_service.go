@@ -0,0 +1,103 @@
+package kv
+
+import (
+	"github.com/chainid-io/dashboard"
+)
+
+// UserService represents a service for managing users.
+type UserService struct {
+	users *collection
+}
+
+// User returns a user by ID
+func (service *UserService) User(ID chainid.UserID) (*chainid.User, error) {
+	var user chainid.User
+	err := service.users.get(formatID(int(ID)), &user, chainid.ErrUserNotFound)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// UserByUsername returns a user by username.
+func (service *UserService) UserByUsername(username string) (*chainid.User, error) {
+	users, err := service.Users()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range users {
+		if user.Username == username {
+			return &user, nil
+		}
+	}
+	return nil, chainid.ErrUserNotFound
+}
+
+// Users return an array containing all the users.
+func (service *UserService) Users() ([]chainid.User, error) {
+	var users = make([]chainid.User, 0)
+	err := service.users.forEach(func(data []byte) error {
+		var user chainid.User
+		err := service.users.decode(data, &user)
+		if err != nil {
+			return err
+		}
+		users = append(users, user)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// UsersByRole return an array containing all the users with the specified role.
+func (service *UserService) UsersByRole(role chainid.UserRole) ([]chainid.User, error) {
+	users, err := service.Users()
+	if err != nil {
+		return nil, err
+	}
+
+	var filteredUsers = make([]chainid.User, 0)
+	for _, user := range users {
+		if user.Role == role {
+			filteredUsers = append(filteredUsers, user)
+		}
+	}
+	return filteredUsers, nil
+}
+
+// UpdateUser saves a user.
+func (service *UserService) UpdateUser(ID chainid.UserID, user *chainid.User) error {
+	return service.users.put(formatID(int(ID)), user)
+}
+
+// UpdateUserFunc applies updateFunc to a user and saves it with a compare-and-swap operation,
+// so that the changes made concurrently to the user are not overwritten. updateFunc is called
+// again when the user is changed in the meantime, the user is not saved when it returns an error.
+func (service *UserService) UpdateUserFunc(ID chainid.UserID, updateFunc func(user *chainid.User) error) error {
+	return service.users.update(formatID(int(ID)), chainid.ErrUserNotFound, func(data []byte) (interface{}, error) {
+		var user chainid.User
+		err := service.users.decode(data, &user)
+		if err != nil {
+			return nil, err
+		}
+		return &user, updateFunc(&user)
+	})
+}
+
+// CreateUser creates a new user.
+func (service *UserService) CreateUser(user *chainid.User) error {
+	id, err := service.users.nextID()
+	if err != nil {
+		return err
+	}
+	user.ID = chainid.UserID(id)
+	return service.users.put(formatID(id), user)
+}
+
+// DeleteUser deletes a user.
+func (service *UserService) DeleteUser(ID chainid.UserID) error {
+	return service.users.delete(formatID(int(ID)))
+}
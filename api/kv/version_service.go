@@ -0,0 +1,29 @@
+package kv
+
+import (
+	"strconv"
+
+	"github.com/chainid-io/dashboard"
+)
+
+// VersionService represents a service to manage stored versions.
+type VersionService struct {
+	backend Backend
+}
+
+// DBVersion retrieves the stored database version.
+func (service *VersionService) DBVersion() (int, error) {
+	data, err := service.backend.Get(versionKey)
+	if err != nil {
+		return 0, err
+	}
+	if data == nil {
+		return 0, chainid.ErrDBVersionNotFound
+	}
+	return strconv.Atoi(string(data))
+}
+
+// StoreDBVersion store the database version.
+func (service *VersionService) StoreDBVersion(version int) error {
+	return service.backend.Put(versionKey, []byte(strconv.Itoa(version)))
+}
@@ -0,0 +1,176 @@
+package search
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+)
+
+const (
+	defaultRefreshInterval = time.Minute
+
+	serviceIdentifierLabel = "com.docker.swarm.service.id"
+	stackIdentifierLabel   = "com.docker.stack.namespace"
+)
+
+type (
+	// Index keeps in memory the endpoints along with the Docker resources recorded in their
+	// last snapshot, so that the resources can be searched without querying the Docker API.
+	// It must be registered as an observer of the EndpointService, it is also reloaded when
+	// it is older than the refresh interval to include the changes made by the other instances
+	// sharing the datastore.
+	Index struct {
+		mu              sync.Mutex
+		endpointService chainid.EndpointService
+		refreshInterval time.Duration
+		refreshedAt     time.Time
+		endpoints       map[chainid.EndpointID]*indexedEndpoint
+	}
+
+	indexedEndpoint struct {
+		endpoint  chainid.Endpoint
+		resources []chainid.SnapshotResource
+	}
+)
+
+// NewIndex initializes a new index, the endpoints are loaded on the first search.
+func NewIndex(endpointService chainid.EndpointService) *Index {
+	return &Index{
+		endpointService: endpointService,
+		refreshInterval: defaultRefreshInterval,
+		endpoints:       make(map[chainid.EndpointID]*indexedEndpoint),
+	}
+}
+
+// EndpointChanged indexes the resources recorded in the last snapshot of an endpoint.
+func (index *Index) EndpointChanged(endpoint *chainid.Endpoint) {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	index.endpoints[endpoint.ID] = newIndexedEndpoint(endpoint)
+}
+
+// EndpointDeleted removes an endpoint and its resources from the index.
+func (index *Index) EndpointDeleted(ID chainid.EndpointID) {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	delete(index.endpoints, ID)
+}
+
+// Endpoints returns the indexed endpoints sorted by identifier, the resources are removed from their snapshots.
+func (index *Index) Endpoints() ([]chainid.Endpoint, error) {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	err := index.refresh()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := make([]chainid.Endpoint, 0, len(index.endpoints))
+	for _, indexed := range index.endpoints {
+		endpoints = append(endpoints, indexed.endpoint)
+	}
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].ID < endpoints[j].ID })
+	return endpoints, nil
+}
+
+// Search returns the Docker resources of the specified endpoints matching a query. The resources
+// with one of the blacklisted labels are ignored. The authorized function is called with the
+// identifiers used by the resource controls of each matching resource and the resources it
+// rejects are not returned.
+func (index *Index) Search(query string, endpointIDs []chainid.EndpointID, labelBlackList []chainid.Pair, authorized func(resourceIDs []string) bool) ([]chainid.SearchResult, error) {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	err := index.refresh()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]chainid.SearchResult, 0)
+	for _, endpointID := range endpointIDs {
+		indexed := index.endpoints[endpointID]
+		if indexed == nil {
+			continue
+		}
+
+		for _, resource := range indexed.resources {
+			if hasBlackListedLabel(&resource, labelBlackList) {
+				continue
+			}
+
+			result := chainid.SearchResult{
+				Type:         resource.Type,
+				ID:           resource.ID,
+				Name:         resource.Name,
+				EndpointID:   indexed.endpoint.ID,
+				EndpointName: indexed.endpoint.Name,
+				Image:        resource.Image,
+				Labels:       resource.Labels,
+			}
+
+			result.Score = Score(query, &result)
+			if result.Score > 0 && authorized(resourceIdentifiers(&resource)) {
+				results = append(results, result)
+			}
+		}
+	}
+	return results, nil
+}
+
+// refresh reloads the endpoints when the index is older than the refresh interval.
+func (index *Index) refresh() error {
+	if time.Since(index.refreshedAt) < index.refreshInterval {
+		return nil
+	}
+
+	endpoints, err := index.endpointService.Endpoints()
+	if err != nil {
+		return err
+	}
+
+	index.endpoints = make(map[chainid.EndpointID]*indexedEndpoint)
+	for i := range endpoints {
+		index.endpoints[endpoints[i].ID] = newIndexedEndpoint(&endpoints[i])
+	}
+	index.refreshedAt = time.Now()
+	return nil
+}
+
+func newIndexedEndpoint(endpoint *chainid.Endpoint) *indexedEndpoint {
+	indexed := &indexedEndpoint{endpoint: *endpoint}
+
+	indexed.endpoint.Snapshots = make([]chainid.Snapshot, len(endpoint.Snapshots))
+	for i, snapshot := range endpoint.Snapshots {
+		indexed.resources = append(indexed.resources, snapshot.Resources...)
+		snapshot.Resources = nil
+		indexed.endpoint.Snapshots[i] = snapshot
+	}
+	return indexed
+}
+
+// resourceIdentifiers returns the identifiers used by the resource controls of a Docker resource,
+// the identifier of the resource and the identifiers of the service and stack it belongs to.
+func resourceIdentifiers(resource *chainid.SnapshotResource) []string {
+	resourceIDs := []string{resource.ID}
+	for _, label := range []string{serviceIdentifierLabel, stackIdentifierLabel} {
+		if resource.Labels[label] != "" {
+			resourceIDs = append(resourceIDs, resource.Labels[label])
+		}
+	}
+	return resourceIDs
+}
+
+func hasBlackListedLabel(resource *chainid.SnapshotResource, labelBlackList []chainid.Pair) bool {
+	for _, blackListedLabel := range labelBlackList {
+		value, ok := resource.Labels[blackListedLabel.Name]
+		if ok && value == blackListedLabel.Value {
+			return true
+		}
+	}
+	return false
+}
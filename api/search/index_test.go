@@ -0,0 +1,115 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/chainid-io/dashboard/kv"
+)
+
+func TestScore(t *testing.T) {
+	result := &chainid.SearchResult{
+		ID:     "4a1b2c3d",
+		Name:   "web-frontend",
+		Image:  "nginx:1.15",
+		Labels: map[string]string{"com.docker.stack.namespace": "shop"},
+	}
+
+	tests := []struct {
+		query string
+		score int
+	}{
+		{"web-frontend", exactMatchScore},
+		{"WEB", prefixMatchScore},
+		{"front", substringMatchScore},
+		{"4a1b2c3d", exactMatchScore},
+		{"4a1b", prefixMatchScore},
+		{"nginx", referenceMatchScore},
+		{"namespace=shop", labelMatchScore},
+		{"postgres", 0},
+		{" ", 0},
+	}
+	for _, test := range tests {
+		if score := Score(test.query, result); score != test.score {
+			t.Errorf("expected a score of %d for %q, got %d", test.score, test.query, score)
+		}
+	}
+}
+
+func TestIndexSearch(t *testing.T) {
+	store := kv.NewStore(kv.NewMemoryBackend())
+	index := NewIndex(store.EndpointService)
+	store.EndpointService.RegisterObserver(index)
+
+	endpoint := &chainid.Endpoint{
+		Name: "production",
+		Snapshots: []chainid.Snapshot{{
+			Resources: []chainid.SnapshotResource{
+				{Type: chainid.ContainerSearchResult, ID: "c1", Name: "web.1", Image: "nginx", Labels: map[string]string{serviceIdentifierLabel: "s1"}},
+				{Type: chainid.ContainerSearchResult, ID: "c2", Name: "db", Image: "postgres"},
+				{Type: chainid.VolumeSearchResult, ID: "web-data", Name: "web-data"},
+			},
+		}},
+	}
+	err := store.EndpointService.CreateEndpoint(endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	endpoints, err := index.Endpoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(endpoints) != 1 || endpoints[0].Snapshots[0].Resources != nil {
+		t.Fatalf("expected the endpoint to be indexed without its resources, got %+v", endpoints)
+	}
+
+	var checked [][]string
+	results, err := index.Search("web", []chainid.EndpointID{endpoint.ID}, nil, func(resourceIDs []string) bool {
+		checked = append(checked, resourceIDs)
+		return resourceIDs[0] != "web-data"
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].ID != "c1" || results[0].EndpointName != "production" || results[0].Score != prefixMatchScore {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if !reflect.DeepEqual(checked, [][]string{{"c1", "s1"}, {"web-data"}}) {
+		t.Errorf("unexpected resource identifiers %v", checked)
+	}
+
+	labelBlackList := []chainid.Pair{{Name: serviceIdentifierLabel, Value: "s1"}}
+	results, _ = index.Search("web", []chainid.EndpointID{endpoint.ID}, labelBlackList, func([]string) bool { return true })
+	if len(results) != 1 || results[0].ID != "web-data" {
+		t.Fatalf("expected the resources with a blacklisted label to be ignored, got %+v", results)
+	}
+
+	// The snapshots stored through the service are indexed without reloading the endpoints.
+	endpoint.Snapshots[0].Resources = []chainid.SnapshotResource{{Type: chainid.NetworkSearchResult, ID: "n1", Name: "web"}}
+	err = store.EndpointService.UpdateEndpoint(endpoint.ID, endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, _ = index.Search("web", []chainid.EndpointID{endpoint.ID}, nil, func([]string) bool { return true })
+	if len(results) != 1 || results[0].ID != "n1" {
+		t.Fatalf("expected the updated snapshot to be searched, got %+v", results)
+	}
+
+	results, _ = index.Search("web", []chainid.EndpointID{endpoint.ID + 1}, nil, func([]string) bool { return true })
+	if len(results) != 0 {
+		t.Errorf("expected the resources of the other endpoints to be ignored, got %+v", results)
+	}
+
+	err = store.EndpointService.DeleteEndpoint(endpoint.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, _ = index.Search("web", []chainid.EndpointID{endpoint.ID}, nil, func([]string) bool { return true })
+	if len(results) != 0 {
+		t.Errorf("expected the resources of a deleted endpoint to be removed, got %+v", results)
+	}
+}
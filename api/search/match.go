@@ -0,0 +1,67 @@
+package search
+
+import (
+	"strings"
+
+	"github.com/chainid-io/dashboard"
+)
+
+const (
+	exactMatchScore     = 100
+	prefixMatchScore    = 75
+	substringMatchScore = 50
+	referenceMatchScore = 40
+	labelMatchScore     = 25
+)
+
+// Score returns how well a result matches a query, it returns 0 when the result does not match.
+// The comparison is case insensitive. The name is matched exactly, as a prefix or as a substring,
+// the identifier exactly or as a prefix, the URL, the image and the labels (key, value or key=value)
+// as substrings.
+func Score(query string, result *chainid.SearchResult) int {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return 0
+	}
+
+	score := 0
+	name := strings.ToLower(result.Name)
+	switch {
+	case name == query:
+		score = exactMatchScore
+	case strings.HasPrefix(name, query):
+		score = prefixMatchScore
+	case strings.Contains(name, query):
+		score = substringMatchScore
+	}
+
+	ID := strings.ToLower(result.ID)
+	if ID == query {
+		return exactMatchScore
+	} else if strings.HasPrefix(ID, query) {
+		score = max(score, prefixMatchScore)
+	}
+
+	for _, reference := range []string{result.URL, result.Image} {
+		if reference != "" && strings.Contains(strings.ToLower(reference), query) {
+			score = max(score, referenceMatchScore)
+		}
+	}
+
+	for key, value := range result.Labels {
+		label := strings.ToLower(key + "=" + value)
+		if strings.Contains(label, query) {
+			score = max(score, labelMatchScore)
+			break
+		}
+	}
+
+	return score
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
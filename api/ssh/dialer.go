@@ -0,0 +1,286 @@
+package ssh
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	defaultPort       = "22"
+	defaultSocketPath = "/var/run/docker.sock"
+	dialTimeout       = 10 * time.Second
+	// defaultIdleTimeout is the duration after which the SSH connection is closed when no
+	// connection to the Docker socket is open.
+	defaultIdleTimeout = 30 * time.Second
+)
+
+type (
+	// Dialer opens connections to the Docker socket of a remote host over SSH. The connections
+	// share a single SSH connection, opened on demand and closed once it is idle.
+	Dialer struct {
+		address     string
+		socketPath  string
+		config      *ssh.ClientConfig
+		idleTimeout time.Duration
+
+		mu        sync.Mutex
+		client    *ssh.Client
+		open      int
+		idleTimer *time.Timer
+	}
+
+	// conn releases the SSH connection of the dialer when it is closed.
+	conn struct {
+		net.Conn
+		dialer *Dialer
+		client *ssh.Client
+		once   sync.Once
+	}
+)
+
+// ValidateEndpointURL checks that the URL of an endpoint is a valid ssh://user@host[:port] URL.
+// The path of the URL, if any, is the path of the Docker socket on the remote host.
+func ValidateEndpointURL(endpointURL string) error {
+	_, _, _, err := parseEndpointURL(endpointURL)
+	return err
+}
+
+// NewDialer creates a dialer for an endpoint using the key and known hosts files of its SSH configuration.
+func NewDialer(endpointURL string, config *chainid.SSHConfiguration) (*Dialer, error) {
+	key, err := ioutil.ReadFile(config.SSHKeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var knownHosts []byte
+	if config.SSHKnownHostsPath != "" {
+		knownHosts, err = ioutil.ReadFile(config.SSHKnownHostsPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return NewDialerFromContent(endpointURL, key, knownHosts, config.SSHSkipVerify)
+}
+
+// NewDialerFromContent creates a dialer for an endpoint using the content of a private key and
+// of a known hosts file. The known hosts file can be omitted when skipVerify is set.
+func NewDialerFromContent(endpointURL string, key, knownHosts []byte, skipVerify bool) (*Dialer, error) {
+	user, address, socketPath, err := parseEndpointURL(endpointURL)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		return nil, chainid.ErrInvalidSSHKey
+	}
+
+	hostKeyCallback := ssh.InsecureIgnoreHostKey()
+	if !skipVerify {
+		hostKeyCallback, err = newHostKeyCallback(knownHosts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &Dialer{
+		address:     address,
+		socketPath:  socketPath,
+		idleTimeout: defaultIdleTimeout,
+		config: &ssh.ClientConfig{
+			User:            user,
+			Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+			HostKeyCallback: hostKeyCallback,
+			Timeout:         dialTimeout,
+		},
+	}, nil
+}
+
+// Dial opens a single connection to the Docker socket of an endpoint, the SSH connection is
+// closed along with it.
+func Dial(endpointURL string, config *chainid.SSHConfiguration) (net.Conn, error) {
+	dialer, err := NewDialer(endpointURL, config)
+	if err != nil {
+		return nil, err
+	}
+	dialer.idleTimeout = 0
+
+	return dialer.Dial()
+}
+
+// Dial opens a connection to the Docker socket of the remote host.
+func (dialer *Dialer) Dial() (net.Conn, error) {
+	client, err := dialer.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := client.Dial("unix", dialer.socketPath)
+	if err != nil {
+		// The SSH connection is dropped so that the next call opens a new one, it may have
+		// been closed by the remote host.
+		dialer.release(client, true)
+		return nil, err
+	}
+
+	return &conn{Conn: c, dialer: dialer, client: client}, nil
+}
+
+// Close closes the SSH connection of the dialer. The connections to the Docker socket
+// are closed along with it.
+func (dialer *Dialer) Close() error {
+	dialer.mu.Lock()
+	defer dialer.mu.Unlock()
+
+	if dialer.idleTimer != nil {
+		dialer.idleTimer.Stop()
+		dialer.idleTimer = nil
+	}
+
+	if dialer.client == nil {
+		return nil
+	}
+
+	err := dialer.client.Close()
+	dialer.client = nil
+	dialer.open = 0
+	return err
+}
+
+func (dialer *Dialer) acquire() (*ssh.Client, error) {
+	dialer.mu.Lock()
+	defer dialer.mu.Unlock()
+
+	if dialer.idleTimer != nil {
+		dialer.idleTimer.Stop()
+		dialer.idleTimer = nil
+	}
+
+	if dialer.client == nil {
+		client, err := ssh.Dial("tcp", dialer.address, dialer.config)
+		if err != nil {
+			return nil, err
+		}
+		dialer.client = client
+		dialer.open = 0
+	}
+
+	dialer.open++
+	return dialer.client, nil
+}
+
+func (dialer *Dialer) release(client *ssh.Client, broken bool) {
+	dialer.mu.Lock()
+	defer dialer.mu.Unlock()
+
+	if dialer.client != client {
+		return
+	}
+
+	dialer.open--
+	if broken {
+		client.Close()
+		dialer.client = nil
+		dialer.open = 0
+		return
+	}
+
+	if dialer.open == 0 && dialer.idleTimeout == 0 {
+		client.Close()
+		dialer.client = nil
+	} else if dialer.open == 0 {
+		dialer.idleTimer = time.AfterFunc(dialer.idleTimeout, func() {
+			dialer.closeIfIdle(client)
+		})
+	}
+}
+
+func (dialer *Dialer) closeIfIdle(client *ssh.Client) {
+	dialer.mu.Lock()
+	defer dialer.mu.Unlock()
+
+	if dialer.client == client && dialer.open == 0 {
+		client.Close()
+		dialer.client = nil
+		dialer.idleTimer = nil
+	}
+}
+
+func (c *conn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(func() {
+		c.dialer.release(c.client, false)
+	})
+	return err
+}
+
+func parseEndpointURL(endpointURL string) (string, string, string, error) {
+	u, err := url.Parse(endpointURL)
+	if err != nil || u.Scheme != "ssh" || u.User == nil || u.User.Username() == "" || u.Hostname() == "" {
+		return "", "", "", chainid.ErrInvalidSSHEndpointURL
+	}
+
+	if _, hasPassword := u.User.Password(); hasPassword {
+		return "", "", "", chainid.ErrInvalidSSHEndpointURL
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = defaultPort
+	}
+
+	socketPath := u.Path
+	if socketPath == "" || socketPath == "/" {
+		socketPath = defaultSocketPath
+	}
+
+	return u.User.Username(), net.JoinHostPort(u.Hostname(), port), socketPath, nil
+}
+
+// newHostKeyCallback returns a callback accepting the host keys listed in a known hosts file.
+// The known hosts file of an endpoint only lists the keys of the endpoint, the host patterns
+// of the entries are not checked.
+func newHostKeyCallback(knownHosts []byte) (ssh.HostKeyCallback, error) {
+	if len(bytes.TrimSpace(knownHosts)) == 0 {
+		return nil, chainid.ErrSSHHostKeyRequired
+	}
+
+	var keys [][]byte
+	rest := knownHosts
+	for {
+		marker, _, key, _, next, err := ssh.ParseKnownHosts(rest)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, chainid.ErrInvalidSSHKnownHosts
+		}
+		if marker != "revoked" {
+			keys = append(keys, key.Marshal())
+		}
+		rest = next
+	}
+
+	if len(keys) == 0 {
+		return nil, chainid.ErrInvalidSSHKnownHosts
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		marshaled := key.Marshal()
+		for _, known := range keys {
+			if bytes.Equal(known, marshaled) {
+				return nil
+			}
+		}
+		return chainid.ErrSSHHostKeyMismatch
+	}, nil
+}
@@ -0,0 +1,104 @@
+package ssh
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// Forwarder exposes the Docker socket of a remote host on a local address, it is used to run
+// the Docker CLI against an SSH endpoint.
+type Forwarder struct {
+	listener  net.Listener
+	directory string
+	dialer    *Dialer
+	wg        sync.WaitGroup
+}
+
+// Forward starts a forwarder listening on a unix socket created in a private temporary folder,
+// or on the loopback interface on Windows.
+func (dialer *Dialer) Forward() (*Forwarder, error) {
+	forwarder := &Forwarder{dialer: dialer}
+
+	var err error
+	if runtime.GOOS == "windows" {
+		forwarder.listener, err = net.Listen("tcp", "127.0.0.1:0")
+	} else {
+		forwarder.directory, err = ioutil.TempDir("", "chainid-ssh")
+		if err != nil {
+			return nil, err
+		}
+		forwarder.listener, err = net.Listen("unix", filepath.Join(forwarder.directory, "docker.sock"))
+	}
+	if err != nil {
+		forwarder.removeDirectory()
+		return nil, err
+	}
+
+	forwarder.wg.Add(1)
+	go forwarder.serve()
+
+	return forwarder, nil
+}
+
+// Host returns the value of the -H flag of the Docker CLI used to reach the forwarded socket.
+func (forwarder *Forwarder) Host() string {
+	addr := forwarder.listener.Addr()
+	if addr.Network() == "unix" {
+		return "unix://" + addr.String()
+	}
+	return "tcp://" + addr.String()
+}
+
+// Close stops the forwarder and closes the SSH connection of its dialer.
+func (forwarder *Forwarder) Close() error {
+	err := forwarder.listener.Close()
+	forwarder.wg.Wait()
+	forwarder.dialer.Close()
+	forwarder.removeDirectory()
+	return err
+}
+
+func (forwarder *Forwarder) serve() {
+	defer forwarder.wg.Done()
+
+	for {
+		local, err := forwarder.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go forwarder.forward(local)
+	}
+}
+
+func (forwarder *Forwarder) forward(local net.Conn) {
+	defer local.Close()
+
+	remote, err := forwarder.dialer.Dial()
+	if err != nil {
+		return
+	}
+	defer remote.Close()
+
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(remote, local)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(local, remote)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+func (forwarder *Forwarder) removeDirectory() {
+	if forwarder.directory != "" {
+		os.RemoveAll(forwarder.directory)
+	}
+}
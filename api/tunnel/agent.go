@@ -0,0 +1,79 @@
+package tunnel
+
+import (
+	"io"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/gorilla/websocket"
+)
+
+// Agent represents the edge agent side of a tunnel. It opens the tunnel of an endpoint
+// and forwards the streams opened by the dashboard to the Docker API.
+type Agent struct {
+	// URL is the websocket URL of the tunnel endpoint of the dashboard API
+	// (e.g. wss://dashboard.example.com/api/tunnel).
+	URL       string
+	JoinToken string
+	// DialDocker opens a connection to the Docker API.
+	DialDocker func() (net.Conn, error)
+	Dialer     *websocket.Dialer
+	Logger     *log.Logger
+}
+
+// Connect opens the tunnel and forwards the streams until the tunnel is closed.
+func (agent *Agent) Connect() error {
+	header := http.Header{}
+	header.Set(chainid.EdgeJoinTokenHeader, agent.JoinToken)
+
+	dialer := agent.Dialer
+	if dialer == nil {
+		dialer = websocket.DefaultDialer
+	}
+
+	conn, response, err := dialer.Dial(agent.URL, header)
+	if err != nil {
+		if response != nil && response.StatusCode == http.StatusForbidden {
+			return chainid.ErrInvalidEdgeJoinToken
+		}
+		return err
+	}
+
+	session := NewSession(conn, false)
+	defer session.Close()
+
+	for {
+		stream, err := session.Accept()
+		if err != nil {
+			return err
+		}
+
+		go agent.forward(stream)
+	}
+}
+
+func (agent *Agent) forward(stream net.Conn) {
+	defer stream.Close()
+
+	dockerConn, err := agent.DialDocker()
+	if err != nil {
+		if agent.Logger != nil {
+			agent.Logger.Printf("Unable to connect to the Docker API: %s", err)
+		}
+		return
+	}
+	defer dockerConn.Close()
+
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(dockerConn, stream)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(stream, dockerConn)
+		done <- struct{}{}
+	}()
+	<-done
+}
@@ -0,0 +1,110 @@
+package tunnel
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/gorilla/websocket"
+)
+
+type (
+	// Service manages the reverse tunnels opened by the edge agents. A tunnel is only
+	// available on the instance the agent is connected to.
+	Service struct {
+		mu      sync.Mutex
+		tunnels map[chainid.EndpointID]*tunnel
+	}
+
+	tunnel struct {
+		session *Session
+		status  chainid.TunnelStatus
+	}
+)
+
+// NewService initializes a new service.
+func NewService() *Service {
+	return &Service{
+		tunnels: make(map[chainid.EndpointID]*tunnel),
+	}
+}
+
+// GenerateJoinToken returns a random token used by an edge agent to open the tunnel of
+// an endpoint.
+func GenerateJoinToken() (string, error) {
+	token := make([]byte, 32)
+	_, err := rand.Read(token)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// Serve registers the websocket connection of an edge agent as the tunnel of the endpoint
+// and blocks until the tunnel is closed. The previous tunnel of the endpoint is closed.
+func (service *Service) Serve(endpointID chainid.EndpointID, conn *websocket.Conn) {
+	t := &tunnel{
+		session: NewSession(conn, true),
+		status: chainid.TunnelStatus{
+			Active:        true,
+			ConnectedAt:   time.Now().Unix(),
+			RemoteAddress: conn.RemoteAddr().String(),
+		},
+	}
+
+	service.mu.Lock()
+	previous := service.tunnels[endpointID]
+	service.tunnels[endpointID] = t
+	service.mu.Unlock()
+
+	if previous != nil {
+		previous.session.Close()
+	}
+
+	<-t.session.Done()
+
+	service.mu.Lock()
+	if service.tunnels[endpointID] == t {
+		delete(service.tunnels, endpointID)
+	}
+	service.mu.Unlock()
+}
+
+// Dial opens a connection to the Docker API of an endpoint through its tunnel.
+func (service *Service) Dial(endpointID chainid.EndpointID) (net.Conn, error) {
+	service.mu.Lock()
+	t := service.tunnels[endpointID]
+	service.mu.Unlock()
+
+	if t == nil {
+		return nil, chainid.ErrTunnelNotConnected
+	}
+	return t.session.Open()
+}
+
+// TunnelStatus returns the status of the tunnel of an endpoint.
+func (service *Service) TunnelStatus(endpointID chainid.EndpointID) *chainid.TunnelStatus {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	t := service.tunnels[endpointID]
+	if t == nil {
+		return &chainid.TunnelStatus{}
+	}
+	status := t.status
+	return &status
+}
+
+// CloseTunnel closes the tunnel of an endpoint, the agent must join again.
+func (service *Service) CloseTunnel(endpointID chainid.EndpointID) {
+	service.mu.Lock()
+	t := service.tunnels[endpointID]
+	service.mu.Unlock()
+
+	if t != nil {
+		t.session.Close()
+	}
+}
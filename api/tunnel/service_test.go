@@ -0,0 +1,139 @@
+package tunnel
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/gorilla/websocket"
+)
+
+const testEndpointID = chainid.EndpointID(1)
+
+// newTestTunnel starts a Docker API stub and a tunnel server, then connects an agent
+// forwarding the streams to the stub. It returns once the tunnel is active.
+func newTestTunnel(t *testing.T, service *Service) func() {
+	docker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("docker " + r.URL.Path))
+	}))
+
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(chainid.EdgeJoinTokenHeader) != "valid" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		service.Serve(testEndpointID, conn)
+	}))
+
+	agent := &Agent{
+		URL:       "ws" + strings.TrimPrefix(server.URL, "http"),
+		JoinToken: "valid",
+		DialDocker: func() (net.Conn, error) {
+			return net.Dial("tcp", strings.TrimPrefix(docker.URL, "http://"))
+		},
+	}
+	errs := make(chan error, 1)
+	go func() { errs <- agent.Connect() }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !service.TunnelStatus(testEndpointID).Active {
+		select {
+		case err := <-errs:
+			server.Close()
+			docker.Close()
+			t.Fatalf("agent.Connect() = %v", err)
+		default:
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("tunnel not connected")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return func() {
+		service.CloseTunnel(testEndpointID)
+		server.Close()
+		docker.Close()
+	}
+}
+
+func TestServiceRoundTrip(t *testing.T) {
+	service := NewService()
+	cleanup := newTestTunnel(t, service)
+	defer cleanup()
+
+	status := service.TunnelStatus(testEndpointID)
+	if status.ConnectedAt == 0 || status.RemoteAddress == "" {
+		t.Errorf("unexpected tunnel status %+v", status)
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			Dial: func(proto, addr string) (net.Conn, error) {
+				return service.Dial(testEndpointID)
+			},
+		},
+	}
+
+	for _, path := range []string{"/_ping", "/version", "/info"} {
+		response, err := client.Get("http://edge" + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		body, err := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(body) != "docker "+path {
+			t.Errorf("GET %s = %q", path, body)
+		}
+	}
+}
+
+func TestServiceCloseTunnel(t *testing.T) {
+	service := NewService()
+	cleanup := newTestTunnel(t, service)
+	defer cleanup()
+
+	service.CloseTunnel(testEndpointID)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for service.TunnelStatus(testEndpointID).Active {
+		if time.Now().After(deadline) {
+			t.Fatal("tunnel not closed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	_, err := service.Dial(testEndpointID)
+	if err != chainid.ErrTunnelNotConnected {
+		t.Errorf("Dial() error = %v, want %v", err, chainid.ErrTunnelNotConnected)
+	}
+}
+
+func TestAgentInvalidJoinToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	agent := &Agent{
+		URL:       "ws" + strings.TrimPrefix(server.URL, "http"),
+		JoinToken: "invalid",
+	}
+	err := agent.Connect()
+	if err != chainid.ErrInvalidEdgeJoinToken {
+		t.Errorf("Connect() error = %v, want %v", err, chainid.ErrInvalidEdgeJoinToken)
+	}
+}
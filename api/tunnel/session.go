@@ -0,0 +1,479 @@
+package tunnel
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/chainid-io/dashboard"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	frameOpen byte = iota + 1
+	frameData
+	frameClose
+	frameWindowUpdate
+)
+
+const (
+	frameHeaderSize   = 5
+	maxFramePayload   = 32 * 1024
+	streamWindowSize  = 256 * 1024
+	keepAliveInterval = 30 * time.Second
+	keepAliveTimeout  = 3 * keepAliveInterval
+	acceptBacklog     = 16
+)
+
+var errStreamClosed = errors.New("tunnel stream closed")
+
+// timeoutError is returned by the streams when a deadline is exceeded.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "tunnel stream deadline exceeded" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// Session multiplexes streams over a websocket connection. Each stream is a bidirectional
+// byte stream implementing net.Conn. Every message exchanged on the connection is a frame
+// made of the stream identifier, the frame type and the payload. Only the client side of
+// the session accepts streams.
+//
+// A stream cannot send more than streamWindowSize bytes that the peer has not read yet, the
+// peer grants more with window update frames as the data is read. A slow reader therefore
+// blocks the writer of its stream without blocking the other streams of the session.
+type Session struct {
+	conn       *websocket.Conn
+	server     bool
+	writeMutex sync.Mutex
+	mu         sync.Mutex
+	streams    map[uint32]*stream
+	nextID     uint32
+	accept     chan *stream
+	done       chan struct{}
+	closeOnce  sync.Once
+}
+
+// NewSession creates a session on a websocket connection and starts reading the frames.
+// The session is closed when the peer does not answer the keepalive pings.
+func NewSession(conn *websocket.Conn, server bool) *Session {
+	session := &Session{
+		conn:    conn,
+		server:  server,
+		streams: make(map[uint32]*stream),
+		nextID:  1,
+		accept:  make(chan *stream, acceptBacklog),
+		done:    make(chan struct{}),
+	}
+
+	conn.SetReadDeadline(time.Now().Add(keepAliveTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(keepAliveTimeout))
+	})
+	conn.SetPingHandler(func(data string) error {
+		conn.SetReadDeadline(time.Now().Add(keepAliveTimeout))
+		err := conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(keepAliveInterval))
+		if err == websocket.ErrCloseSent {
+			return nil
+		}
+		return err
+	})
+
+	go session.readFrames()
+	go session.keepAlive()
+	return session
+}
+
+// Open opens a new stream, the peer receives it with Accept.
+func (session *Session) Open() (net.Conn, error) {
+	session.mu.Lock()
+	select {
+	case <-session.done:
+		session.mu.Unlock()
+		return nil, chainid.ErrTunnelNotConnected
+	default:
+	}
+	ID := session.nextID
+	session.nextID += 2
+	s := newStream(ID, session)
+	session.streams[ID] = s
+	session.mu.Unlock()
+
+	err := session.writeFrame(ID, frameOpen, nil)
+	if err != nil {
+		session.removeStream(ID)
+		return nil, err
+	}
+	return s, nil
+}
+
+// Accept waits for a stream opened by the peer.
+func (session *Session) Accept() (net.Conn, error) {
+	select {
+	case s := <-session.accept:
+		return s, nil
+	case <-session.done:
+		return nil, chainid.ErrTunnelNotConnected
+	}
+}
+
+// Done returns a channel closed when the session is closed.
+func (session *Session) Done() <-chan struct{} {
+	return session.done
+}
+
+// Close closes the websocket connection and every stream of the session.
+func (session *Session) Close() error {
+	var err error
+	session.closeOnce.Do(func() {
+		close(session.done)
+		err = session.conn.Close()
+
+		session.mu.Lock()
+		streams := session.streams
+		session.streams = make(map[uint32]*stream)
+		session.mu.Unlock()
+
+		for _, s := range streams {
+			s.closeRemote()
+		}
+	})
+	return err
+}
+
+func (session *Session) readFrames() {
+	defer session.Close()
+
+	for {
+		messageType, data, err := session.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if messageType != websocket.BinaryMessage || len(data) < frameHeaderSize {
+			continue
+		}
+
+		ID := binary.BigEndian.Uint32(data[:4])
+		payload := data[frameHeaderSize:]
+
+		switch data[4] {
+		case frameOpen:
+			if session.server {
+				session.writeFrame(ID, frameClose, nil)
+				continue
+			}
+
+			s := newStream(ID, session)
+			session.mu.Lock()
+			session.streams[ID] = s
+			session.mu.Unlock()
+
+			select {
+			case session.accept <- s:
+			case <-session.done:
+				return
+			}
+		case frameData:
+			session.mu.Lock()
+			s := session.streams[ID]
+			session.mu.Unlock()
+			if s != nil && !s.receive(payload) {
+				// The peer sent more data than the window of the stream allows.
+				session.removeStream(ID)
+				s.closeRemote()
+				session.writeFrame(ID, frameClose, nil)
+			}
+		case frameWindowUpdate:
+			session.mu.Lock()
+			s := session.streams[ID]
+			session.mu.Unlock()
+			if s != nil && len(payload) == 4 {
+				s.increaseSendWindow(int(binary.BigEndian.Uint32(payload)))
+			}
+		case frameClose:
+			s := session.removeStream(ID)
+			if s != nil {
+				s.closeRemote()
+			}
+		}
+	}
+}
+
+func (session *Session) keepAlive() {
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := session.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(keepAliveInterval))
+			if err != nil {
+				session.Close()
+				return
+			}
+		case <-session.done:
+			return
+		}
+	}
+}
+
+func (session *Session) writeFrame(ID uint32, frameType byte, payload []byte) error {
+	frame := make([]byte, frameHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(frame[:4], ID)
+	frame[4] = frameType
+	copy(frame[frameHeaderSize:], payload)
+
+	session.writeMutex.Lock()
+	defer session.writeMutex.Unlock()
+	return session.conn.WriteMessage(websocket.BinaryMessage, frame)
+}
+
+func (session *Session) writeWindowUpdate(ID uint32, increment int) error {
+	payload := make([]byte, 4)
+	binary.BigEndian.PutUint32(payload, uint32(increment))
+	return session.writeFrame(ID, frameWindowUpdate, payload)
+}
+
+func (session *Session) removeStream(ID uint32) *stream {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	s := session.streams[ID]
+	delete(session.streams, ID)
+	return s
+}
+
+// stream is a net.Conn backed by the frames of a session. The data received is buffered
+// until it is read, the buffer is bounded by the window of the stream. The deadlines
+// interrupt the reads and the writes waiting for the window of the stream.
+type stream struct {
+	ID            uint32
+	session       *Session
+	mu            sync.Mutex
+	cond          *sync.Cond
+	buffer        bytes.Buffer
+	consumed      int
+	sendWindow    int
+	readDeadline  time.Time
+	writeDeadline time.Time
+	readTimer     *time.Timer
+	writeTimer    *time.Timer
+	remoteClosed  bool
+	localClosed   bool
+}
+
+func newStream(ID uint32, session *Session) *stream {
+	s := &stream{
+		ID:         ID,
+		session:    session,
+		sendWindow: streamWindowSize,
+	}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+// receive buffers the data received by the stream. It returns false if the data exceeds
+// the window of the stream.
+func (s *stream) receive(data []byte) bool {
+	s.mu.Lock()
+	if s.buffer.Len()+len(data) > streamWindowSize {
+		s.mu.Unlock()
+		return false
+	}
+	s.buffer.Write(data)
+	s.mu.Unlock()
+	s.cond.Broadcast()
+	return true
+}
+
+func (s *stream) increaseSendWindow(increment int) {
+	s.mu.Lock()
+	s.sendWindow += increment
+	s.mu.Unlock()
+	s.cond.Broadcast()
+}
+
+func (s *stream) closeRemote() {
+	s.mu.Lock()
+	s.remoteClosed = true
+	s.mu.Unlock()
+	s.cond.Broadcast()
+}
+
+func deadlineExceeded(deadline time.Time) bool {
+	return !deadline.IsZero() && !time.Now().Before(deadline)
+}
+
+// Read reads the data received by the stream. The window of the stream is extended once
+// half of it has been read.
+func (s *stream) Read(b []byte) (int, error) {
+	s.mu.Lock()
+	for s.buffer.Len() == 0 && !s.remoteClosed && !s.localClosed && !deadlineExceeded(s.readDeadline) {
+		s.cond.Wait()
+	}
+
+	if s.localClosed {
+		s.mu.Unlock()
+		return 0, errStreamClosed
+	}
+	if s.buffer.Len() == 0 {
+		remoteClosed := s.remoteClosed
+		s.mu.Unlock()
+		if remoteClosed {
+			return 0, io.EOF
+		}
+		return 0, timeoutError{}
+	}
+
+	n, _ := s.buffer.Read(b)
+	s.consumed += n
+	increment := 0
+	if s.consumed >= streamWindowSize/2 && !s.remoteClosed {
+		increment = s.consumed
+		s.consumed = 0
+	}
+	s.mu.Unlock()
+
+	if increment > 0 {
+		s.session.writeWindowUpdate(s.ID, increment)
+	}
+	return n, nil
+}
+
+// reserveSendWindow waits until the peer can receive data and reserves up to size bytes
+// of the window of the stream.
+func (s *stream) reserveSendWindow(size int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for s.sendWindow == 0 && !s.localClosed && !s.remoteClosed && !deadlineExceeded(s.writeDeadline) {
+		s.cond.Wait()
+	}
+
+	if s.localClosed || s.remoteClosed {
+		return 0, errStreamClosed
+	}
+	if deadlineExceeded(s.writeDeadline) {
+		return 0, timeoutError{}
+	}
+
+	if size > maxFramePayload {
+		size = maxFramePayload
+	}
+	if size > s.sendWindow {
+		size = s.sendWindow
+	}
+	s.sendWindow -= size
+	return size, nil
+}
+
+func (s *stream) Write(b []byte) (int, error) {
+	written := 0
+	for written < len(b) {
+		size, err := s.reserveSendWindow(len(b) - written)
+		if err != nil {
+			return written, err
+		}
+
+		err = s.session.writeFrame(s.ID, frameData, b[written:written+size])
+		if err != nil {
+			return written, err
+		}
+		written += size
+	}
+	return written, nil
+}
+
+func (s *stream) Close() error {
+	s.mu.Lock()
+	if s.localClosed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.localClosed = true
+	remoteClosed := s.remoteClosed
+	s.stopTimers()
+	s.mu.Unlock()
+	s.cond.Broadcast()
+
+	s.session.removeStream(s.ID)
+	if remoteClosed {
+		return nil
+	}
+
+	err := s.session.writeFrame(s.ID, frameClose, nil)
+	select {
+	case <-s.session.done:
+		return nil
+	default:
+		return err
+	}
+}
+
+func (s *stream) LocalAddr() net.Addr {
+	return s.session.conn.LocalAddr()
+}
+
+func (s *stream) RemoteAddr() net.Addr {
+	return s.session.conn.RemoteAddr()
+}
+
+func (s *stream) SetDeadline(t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.readDeadline = t
+	s.readTimer = s.resetTimer(s.readTimer, t)
+	s.writeDeadline = t
+	s.writeTimer = s.resetTimer(s.writeTimer, t)
+	return nil
+}
+
+func (s *stream) SetReadDeadline(t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.readDeadline = t
+	s.readTimer = s.resetTimer(s.readTimer, t)
+	return nil
+}
+
+func (s *stream) SetWriteDeadline(t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.writeDeadline = t
+	s.writeTimer = s.resetTimer(s.writeTimer, t)
+	return nil
+}
+
+// resetTimer replaces timer by a timer waking up the reads and the writes of the stream
+// once the deadline is exceeded. It must be called with the mutex of the stream held.
+func (s *stream) resetTimer(timer *time.Timer, deadline time.Time) *time.Timer {
+	if timer != nil {
+		timer.Stop()
+	}
+	s.cond.Broadcast()
+
+	if deadline.IsZero() {
+		return nil
+	}
+	return time.AfterFunc(time.Until(deadline), func() {
+		s.mu.Lock()
+		s.mu.Unlock()
+		s.cond.Broadcast()
+	})
+}
+
+func (s *stream) stopTimers() {
+	if s.readTimer != nil {
+		s.readTimer.Stop()
+	}
+	if s.writeTimer != nil {
+		s.writeTimer.Stop()
+	}
+}
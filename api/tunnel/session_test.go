@@ -0,0 +1,109 @@
+package tunnel
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newTestSessions connects a server session to a client session over a websocket connection.
+func newTestSessions(t *testing.T) (*Session, *Session, func()) {
+	upgrader := websocket.Upgrader{}
+	sessions := make(chan *Session, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		sessions <- NewSession(conn, true)
+	}))
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	client := NewSession(conn, false)
+	serverSession := <-sessions
+
+	return serverSession, client, func() {
+		serverSession.Close()
+		client.Close()
+		server.Close()
+	}
+}
+
+func openTestStream(t *testing.T, server, client *Session) (net.Conn, net.Conn) {
+	local, err := server.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote, err := client.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return local, remote
+}
+
+func TestStreamFlowControl(t *testing.T) {
+	server, client, cleanup := newTestSessions(t)
+	defer cleanup()
+	local, remote := openTestStream(t, server, client)
+
+	// The writer is blocked once the window of the stream is full.
+	data := make([]byte, 2*streamWindowSize)
+	local.SetWriteDeadline(time.Now().Add(200 * time.Millisecond))
+	written, err := local.Write(data)
+	if netErr, ok := err.(net.Error); !ok || !netErr.Timeout() {
+		t.Fatalf("expected a timeout while the peer does not read, got %v", err)
+	}
+	if written != streamWindowSize {
+		t.Fatalf("expected %d bytes to be written before the timeout, got %d", streamWindowSize, written)
+	}
+
+	// The writer resumes once the peer reads the data.
+	local.SetWriteDeadline(time.Time{})
+	errs := make(chan error, 1)
+	go func() {
+		_, err := local.Write(data[written:])
+		errs <- err
+	}()
+
+	_, err = io.ReadFull(remote, make([]byte, len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = <-errs; err != nil {
+		t.Fatalf("expected the write to complete once the data is read, got %v", err)
+	}
+}
+
+func TestStreamReadDeadline(t *testing.T) {
+	server, client, cleanup := newTestSessions(t)
+	defer cleanup()
+	local, remote := openTestStream(t, server, client)
+
+	remote.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+	_, err := remote.Read(make([]byte, 1))
+	if netErr, ok := err.(net.Error); !ok || !netErr.Timeout() {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+
+	remote.SetReadDeadline(time.Time{})
+	_, err = local.Write([]byte("ping"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buffer := make([]byte, 4)
+	_, err = io.ReadFull(remote, buffer)
+	if err != nil || string(buffer) != "ping" {
+		t.Fatalf("expected the data to be read once the deadline is cleared, got %q and %v", buffer, err)
+	}
+}
@@ -75,17 +75,14 @@ angular.module('chainid.app')
   service.updateUserPassword = function(id, currentPassword, newPassword) {
     var deferred = $q.defer();
 
-    Users.checkPassword({id: id}, {password: currentPassword}).$promise
+    Users.checkPassword({id: id}, {password: currentPassword, newPassword: newPassword}).$promise
     .then(function success(data) {
       if (!data.valid) {
         deferred.reject({invalidPassword: true});
       } else {
-        return service.updateUser(id, newPassword, undefined);
+        deferred.resolve();
       }
     })
-    .then(function success(data) {
-      deferred.resolve();
-    })
     .catch(function error(err) {
       deferred.reject({msg: 'Unable to update user password', err: err});
     });